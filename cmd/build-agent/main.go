@@ -57,15 +57,18 @@ type Config struct {
 	// New multi-server config
 	Servers []ServerConfig `toml:"servers"`
 	Worker  struct {
-		ID      string `toml:"id"`
-		MaxJobs int    `toml:"max_jobs"`
+		ID      string   `toml:"id"`
+		MaxJobs int      `toml:"max_jobs"`
+		Tags    []string `toml:"tags"` // Capabilities jobs can require, e.g. "gpu"
 	} `toml:"worker"`
 	Storage struct {
-		GitCacheDir string `toml:"git_cache_dir"`
-		WorktreeDir string `toml:"worktree_dir"`
+		GitCacheDir   string `toml:"git_cache_dir"`
+		WorktreeDir   string `toml:"worktree_dir"`
+		WarmWorktrees int    `toml:"warm_worktrees"` // Worktrees kept checked out per recently built commit
 	} `toml:"storage"`
 	Nix struct {
 		PrewarmPackages []string `toml:"prewarm_packages"`
+		ReuseSessions   bool     `toml:"reuse_sessions"` // Capture each repo's dev shell once instead of entering nix develop per job
 	} `toml:"nix"`
 }
 
@@ -172,13 +175,16 @@ func run(cmd *cobra.Command, args []string) error {
 
 	// Create multi-client (works with single or multiple servers)
 	client, err := buildworker.NewMultiClient(buildworker.MultiClientConfig{
-		Servers:     bwServers,
-		WorkerID:    cfg.Worker.ID,
-		MaxJobs:     cfg.Worker.MaxJobs,
-		GitCacheDir: cfg.Storage.GitCacheDir,
-		WorktreeDir: cfg.Storage.WorktreeDir,
-		UseNixShell: true,
-		Debug:       debug,
+		Servers:          bwServers,
+		WorkerID:         cfg.Worker.ID,
+		MaxJobs:          cfg.Worker.MaxJobs,
+		Tags:             cfg.Worker.Tags,
+		GitCacheDir:      cfg.Storage.GitCacheDir,
+		WorktreeDir:      cfg.Storage.WorktreeDir,
+		UseNixShell:      true,
+		Debug:            debug,
+		ReuseNixSessions: cfg.Nix.ReuseSessions,
+		WarmWorktrees:    cfg.Storage.WarmWorktrees,
 	})
 	if err != nil {
 		return fmt.Errorf("creating client: %w", err)
@@ -5,24 +5,47 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/netutil"
 )
 
 var coordinatorURL = "http://localhost:8081"
 var gitDaemonURL = "" // Constructed from coordinator URL
 
+// agentTaskID is the task of the agent running this server, sent with every
+// job so the coordinator can correlate builds to agents (empty if unknown)
+var agentTaskID = ""
+
+// Job submission retries. Retries reuse the idempotency key, so the
+// coordinator returns the original job's result instead of building twice.
+const submitAttempts = 3
+
+var submitRetryDelay = 2 * time.Second
+
 func main() {
-	// Check for coordinator URL override
+	// Check for coordinator URL override, then for a coordinator that
+	// published its URL in the discovery file
 	if url := os.Getenv("BUILD_POOL_URL"); url != "" {
 		coordinatorURL = url
+	} else if url := buildprotocol.DiscoverURL(); url != "" {
+		coordinatorURL = url
 	}
+	agentTaskID = os.Getenv(buildprotocol.TaskIDEnv)
 
 	// Construct git daemon URL from coordinator URL
 	// e.g., "http://host:8081" -> "git://host:9418/"
@@ -121,6 +144,48 @@ func handleRequest(req map[string]interface{}) map[string]interface{} {
 	}
 }
 
+// Job timeouts in seconds. Each job tool has its own default, which a call
+// can override with timeout_secs up to maxJobTimeoutSecs.
+const (
+	defaultJobTimeoutSecs = 300
+	maxJobTimeoutSecs     = 3600
+)
+
+// buildSystem is the build system the job tools run (see buildCommand)
+const buildSystem = "cargo"
+
+// toolTimeoutSecs holds the default timeout of each job tool
+var toolTimeoutSecs = map[string]int{
+	"build":  600,  // Cold builds of the full workspace
+	"test":   1200, // Integration tests can run long
+	"clippy": 300,
+}
+
+// jobTimeout returns the timeout for a job tool call: the per-call
+// timeout_secs if given, else the tool's default, clamped to maxJobTimeoutSecs
+func jobTimeout(tool string, args map[string]interface{}) int {
+	timeout, ok := toolTimeoutSecs[tool]
+	if !ok {
+		timeout = defaultJobTimeoutSecs
+	}
+	if t, ok := args["timeout_secs"].(float64); ok && t > 0 {
+		timeout = int(t)
+	}
+	if timeout > maxJobTimeoutSecs {
+		timeout = maxJobTimeoutSecs
+	}
+	return timeout
+}
+
+// timeoutSchema defines the timeout_secs parameter for a job tool's schema
+func timeoutSchema(tool string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "integer",
+		"description": fmt.Sprintf("Job timeout in seconds (max %d)", maxJobTimeoutSecs),
+		"default":     jobTimeout(tool, nil),
+	}
+}
+
 // verbositySchema defines the verbosity parameter for MCP tool schemas
 var verbositySchema = map[string]interface{}{
 	"type":        "string",
@@ -129,6 +194,31 @@ var verbositySchema = map[string]interface{}{
 	"default":     "normal",
 }
 
+// envSchema defines the env parameter for MCP tool schemas
+var envSchema = map[string]interface{}{
+	"type":                 "object",
+	"description":          "Extra environment variables for the command (allow-listed, e.g. RUSTFLAGS, RUST_BACKTRACE)",
+	"additionalProperties": map[string]interface{}{"type": "string"},
+}
+
+// useNixSchema defines the use_nix parameter for MCP tool schemas
+var useNixSchema = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Run the command in nix develop (default: the worker's setting); false skips nix startup for commands that don't need it",
+}
+
+// prioritySchema defines the priority parameter for MCP tool schemas
+var prioritySchema = map[string]interface{}{
+	"type":        "integer",
+	"description": "Queue priority: higher runs before queued jobs of lower priority (default 0)",
+}
+
+// noCacheSchema defines the no_cache parameter for MCP tool schemas
+var noCacheSchema = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Run the command even if a successful result for this commit is cached",
+}
+
 func listTools() []map[string]interface{} {
 	return []map[string]interface{}{
 		{
@@ -137,10 +227,15 @@ func listTools() []map[string]interface{} {
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"release":   map[string]interface{}{"type": "boolean", "description": "Build in release mode"},
-					"package":   map[string]interface{}{"type": "string", "description": "Specific package to build"},
-					"features":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
-					"verbosity": verbositySchema,
+					"release":      map[string]interface{}{"type": "boolean", "description": "Build in release mode"},
+					"package":      map[string]interface{}{"type": "string", "description": "Specific package to build"},
+					"features":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"env":          envSchema,
+					"use_nix":      useNixSchema,
+					"priority":     prioritySchema,
+					"no_cache":     noCacheSchema,
+					"verbosity":    verbositySchema,
+					"timeout_secs": timeoutSchema("build"),
 				},
 			},
 		},
@@ -150,10 +245,15 @@ func listTools() []map[string]interface{} {
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"filter":    map[string]interface{}{"type": "string", "description": "Test name filter"},
-					"package":   map[string]interface{}{"type": "string", "description": "Specific package to test"},
-					"nocapture": map[string]interface{}{"type": "boolean", "description": "Show stdout/stderr"},
-					"verbosity": verbositySchema,
+					"filter":       map[string]interface{}{"type": "string", "description": "Test name filter"},
+					"package":      map[string]interface{}{"type": "string", "description": "Specific package to test"},
+					"nocapture":    map[string]interface{}{"type": "boolean", "description": "Show stdout/stderr"},
+					"env":          envSchema,
+					"use_nix":      useNixSchema,
+					"priority":     prioritySchema,
+					"no_cache":     noCacheSchema,
+					"verbosity":    verbositySchema,
+					"timeout_secs": timeoutSchema("test"),
 				},
 			},
 		},
@@ -163,8 +263,13 @@ func listTools() []map[string]interface{} {
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"fix":       map[string]interface{}{"type": "boolean", "description": "Apply suggested fixes"},
-					"verbosity": verbositySchema,
+					"fix":          map[string]interface{}{"type": "boolean", "description": "Apply suggested fixes"},
+					"env":          envSchema,
+					"use_nix":      useNixSchema,
+					"priority":     prioritySchema,
+					"no_cache":     noCacheSchema,
+					"verbosity":    verbositySchema,
+					"timeout_secs": timeoutSchema("clippy"),
 				},
 			},
 		},
@@ -175,6 +280,13 @@ func listTools() []map[string]interface{} {
 				"type": "object",
 			},
 		},
+		{
+			"name":        "pool_info",
+			"description": "Get the build pool's configuration: build system, available tools and default job timeouts",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+			},
+		},
 		{
 			"name":        "get_job_logs",
 			"description": "Retrieve complete logs for a completed job from retention buffer",
@@ -195,6 +307,20 @@ func listTools() []map[string]interface{} {
 				"required": []string{"job_id"},
 			},
 		},
+		{
+			"name":        "cancel_job",
+			"description": "Cancel a queued or running job, killing its process on the worker. Reports the final status: cancelled, or completed if the job finished first",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The job ID to cancel",
+					},
+				},
+				"required": []string{"job_id"},
+			},
+		},
 	}
 }
 
@@ -253,14 +379,49 @@ func callTool(name string, args map[string]interface{}) (string, error) {
 	case "build", "test", "clippy":
 		command := buildCommand(name, args)
 		verbosity, _ := args["verbosity"].(string)
-		return submitJob(command, verbosity)
+		env, _ := args["env"].(map[string]interface{})
+		var useNix *bool
+		if v, ok := args["use_nix"].(bool); ok {
+			useNix = &v
+		}
+		priority, _ := args["priority"].(float64)
+		return submitJob(command, verbosity, env, useNix, int(priority), cacheableTool(name, args), jobTimeout(name, args))
 	case "get_job_logs":
 		return getJobLogs(args)
+	case "cancel_job":
+		return cancelJob(args)
+	case "pool_info":
+		return poolInfo()
 	default:
 		return "", fmt.Errorf("unknown tool: %s", name)
 	}
 }
 
+// poolInfo describes what the pool offers, so agents can pick the right
+// tools and timeouts
+func poolInfo() (string, error) {
+	var tools []string
+	for _, tool := range listTools() {
+		tools = append(tools, tool["name"].(string))
+	}
+
+	timeouts := make(map[string]int, len(toolTimeoutSecs))
+	for tool := range toolTimeoutSecs {
+		timeouts[tool] = jobTimeout(tool, nil)
+	}
+
+	info := map[string]interface{}{
+		"build_system":         buildSystem,
+		"tools":                tools,
+		"default_timeout_secs": timeouts,
+		"max_timeout_secs":     maxJobTimeoutSecs,
+		"coordinator_url":      coordinatorURL,
+	}
+
+	pretty, _ := json.MarshalIndent(info, "", "  ")
+	return string(pretty), nil
+}
+
 func getWorkerStatus() (string, error) {
 	resp, err := http.Get(coordinatorURL + "/status")
 	if err != nil {
@@ -280,7 +441,18 @@ func getWorkerStatus() (string, error) {
 	return string(pretty), nil
 }
 
-func submitJob(command, verbosity string) (string, error) {
+// cacheableTool reports whether the coordinator may answer a tool call from
+// its result cache: unless the caller passes no_cache, except for clippy --fix,
+// which changes files
+func cacheableTool(tool string, args map[string]interface{}) bool {
+	if noCache, _ := args["no_cache"].(bool); noCache {
+		return false
+	}
+	fix, _ := args["fix"].(bool)
+	return !(tool == "clippy" && fix)
+}
+
+func submitJob(command, verbosity string, env map[string]interface{}, useNix *bool, priority int, cacheable bool, timeoutSecs int) (string, error) {
 	// Auto-commit any uncommitted changes before building
 	if err := autoCommitIfNeeded(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: auto-commit failed: %v\n", err)
@@ -289,37 +461,28 @@ func submitJob(command, verbosity string) (string, error) {
 	// Get repo info from git
 	repo, commit := getGitInfo()
 
-	reqBody := map[string]interface{}{
-		"command": command,
-		"repo":    repo,
-		"commit":  commit,
-		"timeout": 300, // 5 minute default
-	}
-	if verbosity != "" {
-		reqBody["verbosity"] = verbosity
-	}
+	reqBody := newJobRequest(command, repo, commit, verbosity, env, useNix, priority, cacheable, timeoutSecs)
 
 	jsonBody, _ := json.Marshal(reqBody)
-	resp, err := http.Post(coordinatorURL+"/job", "application/json", bytes.NewReader(jsonBody))
+	resp, err := postJobWithRetry(jsonBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to build pool: %v", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("build pool error (%d): %s", resp.StatusCode, string(body))
-	}
-
 	var result struct {
 		JobID    string `json:"job_id"`
 		ExitCode int    `json:"exit_code"`
 		Output   string `json:"output"`
 		Error    string `json:"error"`
+		Cached   bool   `json:"cached"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", fmt.Errorf("failed to parse response: %v", err)
 	}
+	if result.Cached {
+		result.Output = "(cached result of an earlier run at this commit; pass no_cache to run again)\n" + result.Output
+	}
 
 	if result.Error != "" {
 		return fmt.Sprintf("Error: %s\n\nOutput:\n%s", result.Error, result.Output), nil
@@ -332,6 +495,112 @@ func submitJob(command, verbosity string) (string, error) {
 	return result.Output, nil
 }
 
+// newJobRequest builds the coordinator request body for a job. A nil useNix
+// leaves the choice of running in nix develop to the worker.
+func newJobRequest(command, repo, commit, verbosity string, env map[string]interface{}, useNix *bool, priority int, cacheable bool, timeoutSecs int) map[string]interface{} {
+	reqBody := map[string]interface{}{
+		"command": command,
+		"repo":    repo,
+		"commit":  commit,
+		"timeout": timeoutSecs,
+	}
+	if verbosity != "" {
+		reqBody["verbosity"] = verbosity
+	}
+	if len(env) > 0 {
+		reqBody["env"] = env // Validated against the allow-list by the coordinator
+	}
+	if useNix != nil {
+		reqBody["use_nix"] = *useNix
+	}
+	if priority != 0 {
+		reqBody["priority"] = priority
+	}
+	if cacheable {
+		reqBody["cacheable"] = true
+	}
+	if agentTaskID != "" {
+		reqBody["task_id"] = agentTaskID
+	}
+
+	reqBody["idempotency_key"] = idempotencyKey(command, repo, commit, verbosity, env, useNix, cacheable)
+	return reqBody
+}
+
+// idempotencyKey derives a deterministic key from everything that affects a
+// job's result, so resubmitting the same build at the same commit is deduped.
+// A no_cache submission gets a key of its own, so it runs again instead of
+// being answered by an earlier job; only postJobWithRetry's retries of the
+// same call share it.
+func idempotencyKey(command, repo, commit, verbosity string, env map[string]interface{}, useNix *bool, cacheable bool) string {
+	nix := "default"
+	if useNix != nil {
+		nix = strconv.FormatBool(*useNix)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{command, repo, commit, verbosity, nix, strconv.FormatBool(cacheable)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%v", k, env[k])
+		h.Write([]byte{0})
+	}
+
+	if !cacheable {
+		nonce := make([]byte, 16)
+		rand.Read(nonce)
+		h.Write(nonce)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// postJobWithRetry posts a job to the coordinator, retrying on connection
+// errors and gateway timeouts. The caller closes the returned body.
+func postJobWithRetry(jsonBody []byte) (*http.Response, error) {
+	var lastErr error
+	for attempt := 1; attempt <= submitAttempts; attempt++ {
+		if attempt > 1 {
+			fmt.Fprintf(os.Stderr, "retrying job submission (attempt %d/%d): %v\n", attempt, submitAttempts, lastErr)
+			time.Sleep(submitRetryDelay)
+		}
+
+		resp, err := http.Post(coordinatorURL+"/job", "application/json", bytes.NewReader(jsonBody))
+		if err != nil {
+			lastErr = fmt.Errorf("failed to connect to build pool: %v", err)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("build pool error (%d): %s", resp.StatusCode, string(body))
+			if isRetryableStatus(resp.StatusCode) {
+				continue
+			}
+			return nil, lastErr
+		}
+
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// isRetryableStatus reports whether a coordinator response is worth retrying
+func isRetryableStatus(code int) bool {
+	return code == http.StatusBadGateway ||
+		code == http.StatusServiceUnavailable ||
+		code == http.StatusGatewayTimeout
+}
+
 func getJobLogs(args map[string]interface{}) (string, error) {
 	jobID, _ := args["job_id"].(string)
 	if jobID == "" {
@@ -374,6 +643,52 @@ func getJobLogs(args map[string]interface{}) (string, error) {
 	return string(pretty), nil
 }
 
+// cancelJob asks the coordinator to cancel a job of the agent's task
+// (DELETE /job/{job_id}) and reports the job's final status
+func cancelJob(args map[string]interface{}) (string, error) {
+	jobID, _ := args["job_id"].(string)
+	if jobID == "" {
+		return "", fmt.Errorf("job_id is required")
+	}
+
+	// The coordinator only cancels jobs for the task that submitted them
+	cancelURL := coordinatorURL + "/job/" + url.PathEscape(jobID) + "?task_id=" + url.QueryEscape(agentTaskID)
+	req, err := http.NewRequest(http.MethodDelete, cancelURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to build pool: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Status   string `json:"status"`
+		ExitCode int    `json:"exit_code"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("build pool error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return fmt.Sprintf("Job %s %s", jobID, result.Status), nil
+	case http.StatusConflict:
+		return fmt.Sprintf("Job %s already %s with exit code %d, nothing to cancel", jobID, result.Status, result.ExitCode), nil
+	case http.StatusNotFound:
+		return fmt.Sprintf("Job %s not found: %s", jobID, result.Error), nil
+	default:
+		return "", fmt.Errorf("build pool error (%d): %s", resp.StatusCode, result.Error)
+	}
+}
+
 // autoCommitIfNeeded checks for uncommitted changes and creates a WIP commit if needed.
 // This ensures the build worker receives the latest code changes.
 func autoCommitIfNeeded() error {
@@ -451,31 +766,12 @@ func constructGitDaemonURL(coordURL string) string {
 
 	// If localhost, get external host for remote worker accessibility
 	if hostPart == "localhost" || hostPart == "127.0.0.1" {
-		hostPart = getExternalHost()
+		hostPart = netutil.ExternalHost()
 	}
 
 	return fmt.Sprintf("git://%s:9418/", hostPart)
 }
 
-// getExternalHost tries to get a network-accessible address for this machine
-// Prefers Tailscale IP, falls back to hostname
-func getExternalHost() string {
-	// Try Tailscale IP first (most reliable for remote access)
-	if out, err := exec.Command("tailscale", "ip", "-4").Output(); err == nil {
-		if ip := strings.TrimSpace(string(out)); ip != "" {
-			return ip
-		}
-	}
-
-	// Try hostname
-	if hostname, err := os.Hostname(); err == nil && hostname != "" {
-		return hostname
-	}
-
-	// Last resort - return localhost (will likely fail for remote workers)
-	return "localhost"
-}
-
 // httpClient with reasonable timeout
 var httpClient = &http.Client{
 	Timeout: 10 * time.Minute,
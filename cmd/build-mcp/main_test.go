@@ -2,8 +2,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildpool"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 )
 
 func TestConstructGitDaemonURL(t *testing.T) {
@@ -68,7 +76,7 @@ func TestConstructGitDaemonURL(t *testing.T) {
 					t.Errorf("constructGitDaemonURL(%q) = %q, want suffix :9418/", tt.coordURL, got)
 				}
 				// Verify localhost was substituted (should not contain localhost or 127.0.0.1)
-				// Note: getExternalHost() may return "localhost" as last resort if no network
+				// Note: netutil.ExternalHost() may return "localhost" as last resort if no network
 				// So we just verify the structure is correct
 				host := strings.TrimPrefix(got, "git://")
 				host = strings.TrimSuffix(host, ":9418/")
@@ -79,3 +87,323 @@ func TestConstructGitDaemonURL(t *testing.T) {
 		})
 	}
 }
+
+func TestIdempotencyKey(t *testing.T) {
+	env1 := map[string]interface{}{"RUST_LOG": "debug", "RUST_BACKTRACE": "1"}
+	env2 := map[string]interface{}{"RUST_BACKTRACE": "1", "RUST_LOG": "debug"}
+
+	key := idempotencyKey("cargo build", "git://host/repo", "abc123", "", env1, nil, true)
+	if key != idempotencyKey("cargo build", "git://host/repo", "abc123", "", env2, nil, true) {
+		t.Error("key should not depend on env map order")
+	}
+	if key == idempotencyKey("cargo build", "git://host/repo", "def456", "", env1, nil, true) {
+		t.Error("key should change with the commit")
+	}
+	if key == idempotencyKey("cargo test", "git://host/repo", "abc123", "", env1, nil, true) {
+		t.Error("key should change with the command")
+	}
+	noCache := idempotencyKey("cargo build", "git://host/repo", "abc123", "", env1, nil, false)
+	if noCache == idempotencyKey("cargo build", "git://host/repo", "abc123", "", env1, nil, false) {
+		t.Error("no_cache calls should get a key of their own")
+	}
+}
+
+func TestSubmitNoCacheRunsAgain(t *testing.T) {
+	var mu sync.Mutex
+	runs := 0
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		return &buildprotocol.JobResult{JobID: job.JobID, Output: "built"}
+	}
+	registry := buildpool.NewRegistry()
+	coord := buildpool.NewCoordinator(buildpool.CoordinatorConfig{}, registry, buildpool.NewDispatcher(registry, embedded))
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleJobSubmit))
+	defer server.Close()
+
+	origURL := coordinatorURL
+	coordinatorURL = server.URL
+	defer func() { coordinatorURL = origURL }()
+
+	for i := 0; i < 2; i++ {
+		body, _ := json.Marshal(newJobRequest("cargo test", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs))
+		resp, err := postJobWithRetry(body)
+		if err != nil {
+			t.Fatalf("submission %d: %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 2 {
+		t.Errorf("no_cache job ran %d times, want 2", runs)
+	}
+}
+
+func TestPostJobWithRetry(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			IdempotencyKey string `json:"idempotency_key"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		keys = append(keys, req.IdempotencyKey)
+
+		if len(keys) == 1 {
+			http.Error(w, "job timed out", http.StatusGatewayTimeout)
+			return
+		}
+		w.Write([]byte(`{"job_id":"http-1","output":"ok"}`))
+	}))
+	defer server.Close()
+
+	origURL, origDelay := coordinatorURL, submitRetryDelay
+	coordinatorURL, submitRetryDelay = server.URL, 0
+	defer func() { coordinatorURL, submitRetryDelay = origURL, origDelay }()
+
+	resp, err := postJobWithRetry([]byte(`{"command":"cargo build","idempotency_key":"k1"}`))
+	if err != nil {
+		t.Fatalf("postJobWithRetry: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(keys) != 2 || keys[0] != "k1" || keys[1] != "k1" {
+		t.Errorf("submissions = %v, want two with the same idempotency key", keys)
+	}
+}
+
+func TestPostJobWithRetry_NoRetryOnBadRequest(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "command is required", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	origURL, origDelay := coordinatorURL, submitRetryDelay
+	coordinatorURL, submitRetryDelay = server.URL, 0
+	defer func() { coordinatorURL, submitRetryDelay = origURL, origDelay }()
+
+	if _, err := postJobWithRetry([]byte(`{}`)); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Query().Get("task_id") != "billing/E02" {
+			http.Error(w, "job was not submitted by this task", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/job/running":
+			w.Write([]byte(`{"job_id":"running","status":"cancelled","exit_code":-2}`))
+		case "/job/done":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"job_id":"done","status":"completed","exit_code":0}`))
+		case "/job/unreachable":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"job_id":"unreachable","status":"cancelled","exit_code":-2,"error":"worker w1 not found"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"job_id":"x","exit_code":0,"error":"job not found: x"}`))
+		}
+	}))
+	defer server.Close()
+
+	origURL, origTask := coordinatorURL, agentTaskID
+	coordinatorURL, agentTaskID = server.URL, "billing/E02"
+	defer func() { coordinatorURL, agentTaskID = origURL, origTask }()
+
+	tests := []struct {
+		jobID   string
+		want    string
+		wantErr bool
+	}{
+		{"running", "Job running cancelled", false},
+		{"done", "Job done already completed with exit code 0, nothing to cancel", false},
+		{"gone", "Job gone not found: job not found: x", false},
+		{"unreachable", "worker w1 not found", true},
+	}
+	for _, tt := range tests {
+		got, err := callTool("cancel_job", map[string]interface{}{"job_id": tt.jobID})
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s: err = %v, want one containing %q", tt.jobID, err, tt.want)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.jobID, got, err, tt.want)
+		}
+	}
+
+	if _, err := callTool("cancel_job", nil); err == nil {
+		t.Error("expected an error without job_id")
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want int
+	}{
+		{"build uses its default", "build", nil, toolTimeoutSecs["build"]},
+		{"test uses its default", "test", map[string]interface{}{"filter": "it_"}, toolTimeoutSecs["test"]},
+		{"per-call override", "build", map[string]interface{}{"timeout_secs": float64(45)}, 45},
+		{"override clamped to max", "test", map[string]interface{}{"timeout_secs": float64(99999)}, maxJobTimeoutSecs},
+		{"non-positive override ignored", "clippy", map[string]interface{}{"timeout_secs": float64(0)}, toolTimeoutSecs["clippy"]},
+		{"unknown tool falls back", "fmt", nil, defaultJobTimeoutSecs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jobTimeout(tt.tool, tt.args); got != tt.want {
+				t.Errorf("jobTimeout(%q) = %d, want %d", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJobRequest_Timeout(t *testing.T) {
+	args := map[string]interface{}{"release": true}
+	req := newJobRequest(buildCommand("build", args), "git://host/repo", "abc123", "", nil, nil, 0, false, jobTimeout("build", args))
+
+	if req["timeout"] != toolTimeoutSecs["build"] {
+		t.Errorf("timeout = %v, want %d", req["timeout"], toolTimeoutSecs["build"])
+	}
+
+	args["timeout_secs"] = float64(120)
+	req = newJobRequest(buildCommand("build", args), "git://host/repo", "abc123", "", nil, nil, 0, false, jobTimeout("build", args))
+	if req["timeout"] != 120 {
+		t.Errorf("timeout with override = %v, want 120", req["timeout"])
+	}
+}
+
+func TestNewJobRequest_TaskID(t *testing.T) {
+	req := newJobRequest("cargo build", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs)
+	if _, ok := req["task_id"]; ok {
+		t.Errorf("task_id = %v, want unset without an agent task", req["task_id"])
+	}
+
+	agentTaskID = "billing/E02"
+	defer func() { agentTaskID = "" }()
+
+	req = newJobRequest("cargo build", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs)
+	if req["task_id"] != "billing/E02" {
+		t.Errorf("task_id = %v, want billing/E02", req["task_id"])
+	}
+}
+
+func TestNewJobRequest_UseNix(t *testing.T) {
+	req := newJobRequest("echo hi", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs)
+	if _, ok := req["use_nix"]; ok {
+		t.Errorf("use_nix = %v, want unset to use the worker's default", req["use_nix"])
+	}
+
+	useNix := false
+	skip := newJobRequest("echo hi", "git://host/repo", "abc123", "", nil, &useNix, 0, false, defaultJobTimeoutSecs)
+	if skip["use_nix"] != false {
+		t.Errorf("use_nix = %v, want false", skip["use_nix"])
+	}
+	if skip["idempotency_key"] == req["idempotency_key"] {
+		t.Error("idempotency key should change when the job overrides use_nix")
+	}
+}
+
+func TestNewJobRequest_Priority(t *testing.T) {
+	req := newJobRequest("cargo test", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs)
+	if _, ok := req["priority"]; ok {
+		t.Errorf("priority = %v, want unset for the default priority", req["priority"])
+	}
+
+	req = newJobRequest("cargo test", "git://host/repo", "abc123", "", nil, nil, 10, false, defaultJobTimeoutSecs)
+	if req["priority"] != 10 {
+		t.Errorf("priority = %v, want 10", req["priority"])
+	}
+}
+
+func TestNewJobRequest_Cacheable(t *testing.T) {
+	if !cacheableTool("clippy", map[string]interface{}{}) {
+		t.Error("clippy should be cacheable by default")
+	}
+	for _, args := range []map[string]interface{}{{"no_cache": true}, {"fix": true}} {
+		if cacheableTool("clippy", args) {
+			t.Errorf("clippy %v should not be cacheable", args)
+		}
+	}
+
+	req := newJobRequest("cargo clippy", "git://host/repo", "abc123", "", nil, nil, 0, true, defaultJobTimeoutSecs)
+	if req["cacheable"] != true {
+		t.Errorf("cacheable = %v, want true", req["cacheable"])
+	}
+	noCache := newJobRequest("cargo clippy", "git://host/repo", "abc123", "", nil, nil, 0, false, defaultJobTimeoutSecs)
+	if _, ok := noCache["cacheable"]; ok {
+		t.Errorf("cacheable = %v, want unset with no_cache", noCache["cacheable"])
+	}
+	if noCache["idempotency_key"] == req["idempotency_key"] {
+		t.Error("idempotency key should change with no_cache, or a retry would get the cached response")
+	}
+}
+
+func TestListTools_AdvertisesTimeoutDefaults(t *testing.T) {
+	for _, tool := range listTools() {
+		name := tool["name"].(string)
+		want, isJob := toolTimeoutSecs[name]
+		props, _ := tool["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})
+
+		schema, ok := props["timeout_secs"].(map[string]interface{})
+		if ok != isJob {
+			t.Errorf("%s: has timeout_secs = %v, want %v", name, ok, isJob)
+			continue
+		}
+		if isJob && schema["default"] != want {
+			t.Errorf("%s: timeout_secs default = %v, want %d", name, schema["default"], want)
+		}
+	}
+}
+
+func TestPoolInfo(t *testing.T) {
+	// Answered locally, without contacting the coordinator
+	out, err := callTool("pool_info", nil)
+	if err != nil {
+		t.Fatalf("pool_info: %v", err)
+	}
+
+	var info struct {
+		BuildSystem    string         `json:"build_system"`
+		Tools          []string       `json:"tools"`
+		DefaultTimeout map[string]int `json:"default_timeout_secs"`
+		MaxTimeout     int            `json:"max_timeout_secs"`
+	}
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatalf("output should be valid JSON: %v\n%s", err, out)
+	}
+
+	if info.BuildSystem != "cargo" {
+		t.Errorf("build_system = %q, want cargo", info.BuildSystem)
+	}
+	want := []string{"build", "test", "clippy", "worker_status", "pool_info", "get_job_logs", "cancel_job"}
+	if strings.Join(info.Tools, ",") != strings.Join(want, ",") {
+		t.Errorf("tools = %v, want %v", info.Tools, want)
+	}
+	for tool, secs := range toolTimeoutSecs {
+		if info.DefaultTimeout[tool] != secs {
+			t.Errorf("default timeout of %s = %d, want %d", tool, info.DefaultTimeout[tool], secs)
+		}
+	}
+	if info.MaxTimeout != maxJobTimeoutSecs {
+		t.Errorf("max_timeout_secs = %d, want %d", info.MaxTimeout, maxJobTimeoutSecs)
+	}
+}
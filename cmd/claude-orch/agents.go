@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentsActive bool
+	agentsRecent int
+	agentsSearch string
+	agentsJSON   bool
+)
+
+var agentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "List active and recent agent runs",
+	RunE:  runAgents,
+}
+
+func init() {
+	agentsCmd.Flags().BoolVar(&agentsActive, "active", false, "only list running agents")
+	agentsCmd.Flags().IntVar(&agentsRecent, "recent", 10, "number of finished runs to list (alone: only list those)")
+	agentsCmd.Flags().StringVar(&agentsSearch, "search", "", "only list finished runs whose notes, task ID or error contain this text")
+	agentsCmd.Flags().BoolVar(&agentsJSON, "json", false, "print the runs as JSON")
+	agentsCmd.MarkFlagsMutuallyExclusive("active", "recent")
+	agentsCmd.MarkFlagsMutuallyExclusive("active", "search")
+	rootCmd.AddCommand(agentsCmd)
+}
+
+// agentRunsReport holds the runs printed by the agents command
+type agentRunsReport struct {
+	Active []agentRunJSON `json:"active"`
+	Recent []agentRunJSON `json:"recent"`
+}
+
+// agentRunJSON is the JSON form of an agent run
+type agentRunJSON struct {
+	ID           string     `json:"id"`
+	TaskID       string     `json:"task_id"`
+	Status       string     `json:"status"`
+	PID          int        `json:"pid,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	DurationSecs float64    `json:"duration_secs"`
+	TokensInput  int        `json:"tokens_input"`
+	TokensOutput int        `json:"tokens_output"`
+	CostUSD      float64    `json:"cost_usd"`
+	Error        string     `json:"error,omitempty"`
+	LogPath      string     `json:"log_path,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+func runAgents(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// --recent or --search alone lists only finished runs, --active only running ones
+	showActive := !cmd.Flags().Changed("recent") && agentsSearch == ""
+	recent := agentsRecent
+	if agentsActive {
+		recent = 0
+	}
+
+	report, err := loadAgentRuns(store, showActive, recent, agentsSearch, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if agentsJSON {
+		return writeAgentRunsJSON(os.Stdout, report)
+	}
+	writeAgentRuns(os.Stdout, report, showActive, recent > 0)
+	return nil
+}
+
+// loadAgentRuns reads the running agents (if showActive) and the last recent
+// finished runs, those matching search if it is set, from the store, with
+// durations measured up to now for running agents
+func loadAgentRuns(store *taskstore.Store, showActive bool, recent int, search string, now time.Time) (*agentRunsReport, error) {
+	report := &agentRunsReport{Active: []agentRunJSON{}, Recent: []agentRunJSON{}}
+
+	if showActive {
+		runs, err := store.ListActiveAgentRuns()
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			report.Active = append(report.Active, newAgentRunJSON(run, now))
+		}
+	}
+
+	if recent > 0 {
+		list := store.ListRecentAgentRuns
+		if search != "" {
+			list = func(limit int) ([]*taskstore.AgentRun, error) { return store.SearchAgentRuns(search, limit) }
+		}
+		runs, err := list(recent)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			report.Recent = append(report.Recent, newAgentRunJSON(run, now))
+		}
+	}
+
+	return report, nil
+}
+
+func newAgentRunJSON(run *taskstore.AgentRun, now time.Time) agentRunJSON {
+	end := now
+	if run.FinishedAt != nil {
+		end = *run.FinishedAt
+	}
+	return agentRunJSON{
+		ID:           run.ID,
+		TaskID:       run.TaskID,
+		Status:       run.Status,
+		PID:          run.PID,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		DurationSecs: end.Sub(run.StartedAt).Round(time.Second).Seconds(),
+		TokensInput:  run.TokensInput,
+		TokensOutput: run.TokensOutput,
+		CostUSD:      run.CostUSD,
+		Error:        run.ErrorMessage,
+		LogPath:      run.LogPath,
+		Notes:        run.Notes,
+	}
+}
+
+// writeAgentRuns prints the selected sections of the report as tables
+func writeAgentRuns(out io.Writer, report *agentRunsReport, showActive, showRecent bool) {
+	if showActive {
+		if len(report.Active) == 0 {
+			fmt.Fprintln(out, "No active agents")
+		} else {
+			fmt.Fprintf(out, "Active agents (%d):\n", len(report.Active))
+			writeAgentRunTable(out, report.Active)
+		}
+	}
+
+	if showRecent {
+		if showActive {
+			fmt.Fprintln(out)
+		}
+		if len(report.Recent) == 0 {
+			fmt.Fprintln(out, "No finished agent runs")
+		} else {
+			fmt.Fprintf(out, "Recent runs (%d):\n", len(report.Recent))
+			writeAgentRunTable(out, report.Recent)
+		}
+	}
+}
+
+func writeAgentRunTable(out io.Writer, runs []agentRunJSON) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSTATUS\tSTARTED\tDURATION\tCOST\tERROR\tNOTES")
+	for _, run := range runs {
+		duration := time.Duration(run.DurationSecs) * time.Second
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t$%.2f\t%s\t%s\n",
+			run.TaskID, run.Status, run.StartedAt.Local().Format("2006-01-02 15:04"),
+			duration, run.CostUSD, orDash(truncate(strings.ReplaceAll(run.Error, "\n", " "), 50)),
+			orDash(truncate(strings.ReplaceAll(run.Notes, "\n", "; "), 50)))
+	}
+	w.Flush()
+}
+
+// writeAgentRunsJSON prints the report as indented JSON
+func writeAgentRunsJSON(out io.Writer, report *agentRunsReport) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+// seedAgentRuns stores a running agent and two finished runs
+func seedAgentRuns(t *testing.T) (*taskstore.Store, time.Time) {
+	t.Helper()
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	finished := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	runs := []*taskstore.AgentRun{
+		{ID: "run-1", TaskID: "billing/E01", Status: "completed", PID: 101, StartedAt: now.Add(-3 * time.Hour), FinishedAt: finished(2 * time.Hour)},
+		{ID: "run-2", TaskID: "zoning/E02", Status: "failed", PID: 102, StartedAt: now.Add(-90 * time.Minute), FinishedAt: finished(time.Hour), ErrorMessage: "exit status 1\nsee log"},
+		{ID: "run-3", TaskID: "billing/E02", Status: "running", PID: 103, StartedAt: now.Add(-5 * time.Minute)},
+	}
+	for _, run := range runs {
+		if err := store.SaveAgentRun(run); err != nil {
+			t.Fatalf("SaveAgentRun: %v", err)
+		}
+	}
+	store.UpdateAgentRunUsage("run-1", 1000, 200, 1.25)
+	store.UpdateAgentRunUsage("run-3", 500, 50, 0.4)
+	return store, now
+}
+
+func TestAgentsOutput_Table(t *testing.T) {
+	store, now := seedAgentRuns(t)
+
+	report, err := loadAgentRuns(store, true, 10, "", now)
+	if err != nil {
+		t.Fatalf("loadAgentRuns: %v", err)
+	}
+	var out bytes.Buffer
+	writeAgentRuns(&out, report, true, true)
+	text := out.String()
+
+	active, recent, ok := strings.Cut(text, "Recent runs (2):")
+	if !ok || !strings.HasPrefix(active, "Active agents (1):") {
+		t.Fatalf("expected an active and a recent section, got:\n%s", text)
+	}
+	for _, want := range []string{"billing/E02", "running", "5m0s", "$0.40"} {
+		if !strings.Contains(active, want) {
+			t.Errorf("active section missing %q:\n%s", want, active)
+		}
+	}
+	for _, want := range []string{"billing/E01", "completed", "1h0m0s", "$1.25", "zoning/E02", "failed", "30m0s", "exit status 1 see log"} {
+		if !strings.Contains(recent, want) {
+			t.Errorf("recent section missing %q:\n%s", want, recent)
+		}
+	}
+	if strings.Contains(recent, "billing/E02") {
+		t.Errorf("running agent listed among recent runs:\n%s", recent)
+	}
+
+	// Oldest finished run first
+	if strings.Index(recent, "billing/E01") > strings.Index(recent, "zoning/E02") {
+		t.Errorf("recent runs should be in chronological order:\n%s", recent)
+	}
+}
+
+func TestAgentsOutput_Sections(t *testing.T) {
+	store, now := seedAgentRuns(t)
+
+	// --active
+	report, _ := loadAgentRuns(store, true, 0, "", now)
+	var out bytes.Buffer
+	writeAgentRuns(&out, report, true, false)
+	if text := out.String(); strings.Contains(text, "Recent") || !strings.Contains(text, "billing/E02") {
+		t.Errorf("--active output:\n%s", text)
+	}
+
+	// --recent 1
+	report, _ = loadAgentRuns(store, false, 1, "", now)
+	out.Reset()
+	writeAgentRuns(&out, report, false, true)
+	if text := out.String(); strings.Contains(text, "Active") || !strings.Contains(text, "zoning/E02") || strings.Contains(text, "billing/E01") {
+		t.Errorf("--recent 1 output should list only the latest run:\n%s", text)
+	}
+
+	empty, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	report, _ = loadAgentRuns(empty, true, 10, "", now)
+	out.Reset()
+	writeAgentRuns(&out, report, true, true)
+	if text := out.String(); text != "No active agents\n\nNo finished agent runs\n" {
+		t.Errorf("empty output = %q", text)
+	}
+}
+
+func TestAgentsOutput_JSON(t *testing.T) {
+	store, now := seedAgentRuns(t)
+
+	report, err := loadAgentRuns(store, true, 10, "", now)
+	if err != nil {
+		t.Fatalf("loadAgentRuns: %v", err)
+	}
+	var out bytes.Buffer
+	if err := writeAgentRunsJSON(&out, report); err != nil {
+		t.Fatalf("writeAgentRunsJSON: %v", err)
+	}
+
+	var decoded agentRunsReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(decoded.Active) != 1 || decoded.Active[0].TaskID != "billing/E02" || decoded.Active[0].DurationSecs != 300 {
+		t.Errorf("active = %+v", decoded.Active)
+	}
+	if len(decoded.Recent) != 2 || decoded.Recent[0].CostUSD != 1.25 || decoded.Recent[1].Error != "exit status 1\nsee log" {
+		t.Errorf("recent = %+v", decoded.Recent)
+	}
+	if decoded.Recent[0].FinishedAt == nil || decoded.Active[0].FinishedAt != nil {
+		t.Error("finished_at should be set only for finished runs")
+	}
+}
+
+func TestAgentsOutput_Search(t *testing.T) {
+	store, now := seedAgentRuns(t)
+	if err := store.AnnotateAgentRun("run-2", "Flaky test, passed on retry"); err != nil {
+		t.Fatalf("AnnotateAgentRun: %v", err)
+	}
+
+	report, err := loadAgentRuns(store, false, 10, "flaky", now)
+	if err != nil {
+		t.Fatalf("loadAgentRuns: %v", err)
+	}
+	if len(report.Recent) != 1 || report.Recent[0].TaskID != "zoning/E02" || report.Recent[0].Notes != "Flaky test, passed on retry" {
+		t.Fatalf("recent = %+v, want only the annotated run", report.Recent)
+	}
+
+	var out bytes.Buffer
+	writeAgentRuns(&out, report, false, true)
+	if text := out.String(); !strings.Contains(text, "NOTES") || !strings.Contains(text, "Flaky test, passed on retry") {
+		t.Errorf("table should show the notes:\n%s", text)
+	}
+}
@@ -2,20 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildpool"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/config"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/issues"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/observer"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/prbot"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/scheduler"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/skills"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
@@ -28,16 +35,28 @@ import (
 var (
 	startCount       int
 	startModule      string
+	startLastBatch   bool
+	startOnly        []string
 	listStatus       string
 	listModule       string
 	listPriority     int
 	servePort        int
 	syncSkipIssues    bool
 	syncIssuesOnly    bool
+	syncDryRun        bool
+	syncResolve       string
+	syncHistoryLimit  int
 	cleanupDryRun     bool
 	cleanupAll        bool
+	worktreeFix       bool
 	tuiExecutor       string
+	tuiClaudeModel    string
 	tuiOpenCodeModel  string
+	tuiGeminiModel    string
+	tuiForce          bool
+	tuiExistingBranch string
+	spendBy           string
+	spendSince        string
 )
 
 func init() {
@@ -49,6 +68,8 @@ func init() {
 	}
 	startCmd.Flags().IntVar(&startCount, "count", 3, "number of tasks to start")
 	startCmd.Flags().StringVar(&startModule, "module", "", "filter by module")
+	startCmd.Flags().BoolVar(&startLastBatch, "last-batch", false, "replay the tasks of the last batch, regardless of readiness")
+	startCmd.Flags().StringSliceVar(&startOnly, "only", nil, "start exactly these tasks (comma-separated IDs), regardless of scheduling order")
 	rootCmd.AddCommand(startCmd)
 
 	// status command
@@ -72,14 +93,55 @@ func init() {
 
 	// sync command
 	syncCmd := &cobra.Command{
-		Use:   "sync",
+		Use:   "sync [TASK]",
 		Short: "Sync tasks from markdown files",
-		RunE:  runSync,
+		Long: `Syncs the tasks of all epic files with the database and analyzes GitHub
+issues.
+
+With a TASK (e.g. technical/E05), only that task's epic file is parsed and
+synced, and issues are not analyzed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runSync,
 	}
 	syncCmd.Flags().BoolVar(&syncSkipIssues, "skip-issues", false, "Skip GitHub issue analysis")
 	syncCmd.Flags().BoolVar(&syncIssuesOnly, "issues-only", false, "Only analyze issues, skip markdown sync")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Report what the markdown sync would change without changing anything")
+	syncCmd.Flags().StringVar(&syncResolve, "resolve", "", "Resolve status conflicts without asking: newest, db or markdown")
+	syncCmd.MarkFlagsMutuallyExclusive("dry-run", "issues-only")
+	syncCmd.MarkFlagsMutuallyExclusive("dry-run", "resolve")
+	syncCmd.MarkFlagsMutuallyExclusive("issues-only", "resolve")
+
+	syncStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report where markdown and database disagree, without changing either",
+		Long: `Compares every task in the database with its epic file and prints whether
+they agree, listing status conflicts, epics edited since the last sync, new
+epics and tasks whose epic file is gone. Nothing is written.`,
+		RunE: runSyncStatus,
+	}
+
+	syncHistoryCmd := &cobra.Command{
+		Use:   "history [TASK]",
+		Short: "List how syncs reconciled task statuses",
+		Long: `Lists the most recent status reconciliations of sync, oldest first: tasks
+sync added and conflicts resolved in favor of the database or markdown, with
+the side that won and the status before and after.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runSyncHistory,
+	}
+	syncHistoryCmd.Flags().IntVar(&syncHistoryLimit, "limit", 50, "number of entries to list")
+	syncCmd.AddCommand(syncStatusCmd, syncHistoryCmd)
 	rootCmd.AddCommand(syncCmd)
 
+	// reopen command
+	reopenCmd := &cobra.Command{
+		Use:   "reopen TASK",
+		Short: "Set a completed task back to not started so it is scheduled again",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runReopen,
+	}
+	rootCmd.AddCommand(reopenCmd)
+
 	// logs command
 	logsCmd := &cobra.Command{
 		Use:   "logs TASK",
@@ -95,8 +157,12 @@ func init() {
 		Short: "Launch TUI dashboard",
 		RunE:  runTUI,
 	}
-	tuiCmd.Flags().StringVar(&tuiExecutor, "executor", "", "executor type: claude-code (default) or opencode")
+	tuiCmd.Flags().StringVar(&tuiExecutor, "executor", "", "executor type: claude-code (default), opencode or gemini")
+	tuiCmd.Flags().StringVar(&tuiClaudeModel, "claude-model", "", "model for Claude Code (e.g., sonnet; default: the account default)")
 	tuiCmd.Flags().StringVar(&tuiOpenCodeModel, "opencode-model", "", "model for OpenCode (e.g., zai-coding-plan/glm-4.7)")
+	tuiCmd.Flags().StringVar(&tuiGeminiModel, "gemini-model", "", "model for Gemini CLI (e.g., gemini-2.5-pro)")
+	tuiCmd.Flags().BoolVar(&tuiForce, "force", false, "start even if another TUI is using the database")
+	tuiCmd.Flags().StringVar(&tuiExistingBranch, "existing-branch", "", "when a task's branch already exists: recreate, reuse or error (default: existing_branch from the config)")
 	rootCmd.AddCommand(tuiCmd)
 
 	// serve command
@@ -145,9 +211,33 @@ Use --quick for a faster HTTP-only test without spawning a Claude agent.`,
 	buildPoolTestCmd.Flags().Bool("quick", false, "Run quick HTTP test without Claude agent")
 	buildPoolTestCmd.Flags().Bool("verbose", false, "Show verbose output")
 
-	buildPoolCmd.AddCommand(buildPoolStartCmd, buildPoolStatusCmd, buildPoolStopCmd, buildPoolTestCmd)
+	buildPoolReplayCmd := &cobra.Command{
+		Use:   "replay JOB_ID",
+		Short: "Re-run a retained job locally for debugging",
+		Long: `Fetches the command, repo and commit of a finished job from the running
+coordinator's retained logs and runs it again on a local embedded worker,
+showing the full output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBuildPoolReplay,
+	}
+	buildPoolReplayCmd.Flags().Bool("nix", true, "Run the command inside nix develop")
+
+	buildPoolCmd.AddCommand(buildPoolStartCmd, buildPoolStatusCmd, buildPoolStopCmd, buildPoolTestCmd, buildPoolReplayCmd)
 	rootCmd.AddCommand(buildPoolCmd)
 
+	// spend command
+	spendCmd := &cobra.Command{
+		Use:   "spend",
+		Short: "Show agent token usage and cost",
+		Long: `Aggregates token usage and cost of agent runs, grouped by module or by day.
+
+--since accepts a date (2006-01-02) or a lookback such as 7d or 12h.`,
+		RunE: runSpend,
+	}
+	spendCmd.Flags().StringVar(&spendBy, "by", taskstore.SpendByModule, "group by \"module\" or \"day\"")
+	spendCmd.Flags().StringVar(&spendSince, "since", "", "only include runs started since a date or lookback (e.g. 2026-01-01, 7d)")
+	rootCmd.AddCommand(spendCmd)
+
 	// cleanup command group
 	cleanupCmd := &cobra.Command{
 		Use:   "cleanup",
@@ -164,10 +254,39 @@ Use --quick for a faster HTTP-only test without spawning a Claude agent.`,
 
 	cleanupCmd.AddCommand(cleanupWorktreesCmd)
 	rootCmd.AddCommand(cleanupCmd)
+
+	// worktree command group
+	worktreeCmd := &cobra.Command{
+		Use:   "worktree",
+		Short: "Worktree commands",
+	}
+
+	worktreeCheckCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check worktrees for branch inconsistencies",
+		Long: `Lists worktrees with a detached HEAD, a deleted or unexpected branch, or
+uncommitted changes while their task is idle.
+
+With --fix, stale entries are pruned, worktrees are switched back to their task
+branch, and worktrees whose branch was deleted are removed. Uncommitted changes
+are never discarded and worktrees of running tasks are left alone.`,
+		RunE: runWorktreeCheck,
+	}
+	worktreeCheckCmd.Flags().BoolVar(&worktreeFix, "fix", false, "Repair or remove inconsistent worktrees")
+
+	worktreeCmd.AddCommand(worktreeCheckCmd)
+	rootCmd.AddCommand(worktreeCmd)
 }
 
 func loadConfig() (*config.Config, error) {
-	return config.LoadWithLocalFallback(configPath)
+	cfg, err := config.LoadWithLocalFallback(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.SetStatusVocabulary(cfg.StatusVocabulary); err != nil {
+		return nil, fmt.Errorf("status_vocabulary: %w", err)
+	}
+	return cfg, nil
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
@@ -182,6 +301,24 @@ func runStart(cmd *cobra.Command, args []string) error {
 	}
 	defer store.Close()
 
+	// Replay the last batch: its tasks restart fresh, bypassing the scheduler
+	if startLastBatch {
+		taskIDs, err := store.GetLastBatch()
+		if err != nil {
+			return err
+		}
+		if len(taskIDs) == 0 {
+			fmt.Println("No previous batch to replay")
+			return nil
+		}
+
+		fmt.Printf("Replaying last batch (%d tasks):\n", len(taskIDs))
+		for _, taskID := range taskIDs {
+			fmt.Printf("  - %s\n", taskID)
+		}
+		return nil
+	}
+
 	// If specific tasks provided, start those
 	if len(args) > 0 {
 		for _, taskID := range args {
@@ -202,7 +339,36 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	overrides, err := store.GetTaskPriorityOverrides()
+	if err != nil {
+		return err
+	}
+
+	weights, err := store.GetGroupWeights()
+	if err != nil {
+		return err
+	}
+
 	sched := scheduler.New(tasks, completed)
+	sched.SetTaskOverrides(overrides)
+	sched.SetGroupWeights(weights)
+	sched.SetMaxPerModule(cfg.General.MaxAgentsPerModule)
+
+	// Start exactly the given tasks; refuse if any of them cannot start yet
+	if len(startOnly) > 0 {
+		sel := sched.Only(startOnly, nil)
+		printSelectionWarnings(sel)
+		if len(sel.Blocked) > 0 || len(sel.Unknown) > 0 {
+			return fmt.Errorf("refusing to start: %d task(s) have unmet dependencies, %d unknown", len(sel.Blocked), len(sel.Unknown))
+		}
+		if len(sel.Ready) == 0 {
+			fmt.Println("No tasks ready to start")
+			return nil
+		}
+		printStartedTasks(sel.Ready)
+		return nil
+	}
+
 	ready := sched.GetReadyTasks(startCount)
 
 	if len(ready) == 0 {
@@ -210,12 +376,29 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Starting %d tasks:\n", len(ready))
-	for _, task := range ready {
+	printStartedTasks(ready)
+	return nil
+}
+
+// printStartedTasks lists the tasks a start command picked
+func printStartedTasks(tasks []*domain.Task) {
+	fmt.Printf("Starting %d tasks:\n", len(tasks))
+	for _, task := range tasks {
 		fmt.Printf("  - %s: %s\n", task.ID.String(), task.Title)
 	}
+}
 
-	return nil
+// printSelectionWarnings reports the requested tasks that cannot be started
+func printSelectionWarnings(sel scheduler.Selection) {
+	for _, id := range sel.Unknown {
+		fmt.Printf("Unknown task: %s\n", id)
+	}
+	for _, id := range sel.Skipped {
+		fmt.Printf("Skipping %s: already complete or in progress\n", id)
+	}
+	for _, line := range sel.BlockedSummary() {
+		fmt.Printf("Unmet dependencies: %s\n", line)
+	}
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -307,6 +490,103 @@ func runList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runSpend(cmd *cobra.Command, args []string) error {
+	since, err := parseSince(spendSince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	groups, err := store.GetSpend(spendBy, since)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No agent runs found")
+		return nil
+	}
+
+	var total taskstore.SpendGroup
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tRUNS\tTOKENS IN\tTOKENS OUT\tCOST\n", strings.ToUpper(spendBy))
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t$%.2f\n", g.Key, g.Runs, g.TokensInput, g.TokensOutput, g.CostUSD)
+		total.Runs += g.Runs
+		total.TokensInput += g.TokensInput
+		total.TokensOutput += g.TokensOutput
+		total.CostUSD += g.CostUSD
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t$%.2f\n", total.Runs, total.TokensInput, total.TokensOutput, total.CostUSD)
+	w.Flush()
+
+	return nil
+}
+
+// parseSince parses a --since value: empty (no limit), a date (2006-01-02),
+// a number of days (7d) or a Go duration (12h)
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date (2006-01-02) or a lookback like 7d or 12h", s)
+}
+
+func runReopen(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.General.ProjectRoot == "" {
+		return fmt.Errorf("project_root not configured")
+	}
+
+	plansDir := cfg.General.ProjectRoot + "/docs/plans"
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	syncer, err := newSyncer(cfg, plansDir)
+	if err != nil {
+		return err
+	}
+
+	task, err := syncer.ReopenTask(store, args[0])
+	if task == nil {
+		return err
+	}
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	fmt.Printf("Reopened %s; it will be scheduled again once its dependencies are complete\n", task.ID)
+	return nil
+}
+
 func runSync(cmd *cobra.Command, args []string) error {
 	cfg, err := loadConfig()
 	if err != nil {
@@ -318,6 +598,15 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	plansDir := cfg.General.ProjectRoot + "/docs/plans"
+	if len(args) == 1 && syncIssuesOnly {
+		return fmt.Errorf("--issues-only cannot be combined with a task")
+	}
+	var strategy sync.ConflictStrategy
+	if syncResolve != "" {
+		if strategy, err = sync.ParseConflictStrategy(syncResolve); err != nil {
+			return err
+		}
+	}
 
 	store, err := taskstore.New(cfg.General.DatabasePath)
 	if err != nil {
@@ -327,44 +616,223 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	// Markdown sync (unless --issues-only)
 	if !syncIssuesOnly {
-		syncer := sync.New(plansDir)
-		result, err := syncer.TwoWaySync(store)
+		syncer, err := newSyncer(cfg, plansDir)
+		if err != nil {
+			return err
+		}
+		syncer.SetDryRun(syncDryRun)
+		var result *sync.SyncResult
+		if len(args) == 1 {
+			result, err = syncer.SyncOne(store, args[0])
+		} else {
+			result, err = syncer.TwoWaySync(store)
+		}
 		if err != nil {
 			return fmt.Errorf("sync failed: %w", err)
 		}
 
-		// Report results
-		if result.MarkdownToDBCount > 0 {
-			fmt.Printf("Synced %d tasks from markdown to database\n", result.MarkdownToDBCount)
+		// Conflicts the strategy cannot decide stay in the result
+		var resolved []sync.SyncChange
+		if strategy != "" && len(result.Conflicts) > 0 {
+			resolved, result.Conflicts, err = syncer.ResolveConflictsByStrategy(store, result.Conflicts, strategy)
+			if err != nil {
+				return fmt.Errorf("resolving conflicts: %w", err)
+			}
 		}
-		if result.DBToMarkdownCount > 0 {
-			fmt.Printf("Synced %d tasks from database to markdown\n", result.DBToMarkdownCount)
+
+		action, _ := sync.ParseRemovedTaskAction(cfg.General.RemovedEpicAction)
+		writeSyncResult(os.Stdout, result, resolved, action)
+		if result.DryRun {
+			fmt.Println("\nDry run: nothing was changed. Run 'claude-orch sync' to apply.")
+			return nil // Cycles and issues are checked against the synced plan
 		}
 
-		// Report conflicts
-		if len(result.Conflicts) > 0 {
-			fmt.Printf("\n%d conflicts detected:\n", len(result.Conflicts))
-			for _, c := range result.Conflicts {
-				fmt.Printf("  %s: DB=%s, Markdown=%s\n", c.TaskID, c.DBStatus, c.MarkdownStatus)
-			}
-			fmt.Println("\nUse 'claude-orch tui' to resolve conflicts interactively.")
-		} else {
-			fmt.Println("No conflicts found.")
+		if err := checkDependencyCycles(store); err != nil {
+			return err
 		}
 	}
 
-	// Issue analysis (unless --skip-issues or disabled)
-	if !syncSkipIssues && cfg.GitHubIssues.Enabled {
+	// Issue analysis (unless --skip-issues, a single task or disabled)
+	if !syncSkipIssues && len(args) == 0 && cfg.GitHubIssues.Enabled {
 		analyzer := issues.NewAnalyzer(store, &cfg.GitHubIssues, plansDir)
-		if err := analyzer.AnalyzeCandidates(cmd.Context(), cfg.General.MaxParallelAgents); err != nil {
+		analyzer.SetClaudeBinaryPath(cfg.General.ClaudeBinaryPath)
+		err := analyzer.AnalyzeCandidates(cmd.Context(), cfg.General.MaxParallelAgents)
+		var unavailable *issues.ProviderUnavailableError
+		switch {
+		case errors.As(err, &unavailable):
+			fmt.Printf("Warning: issue analysis %v\n", unavailable)
+		case err != nil:
 			return fmt.Errorf("issue analysis: %w", err)
+		default:
+			fmt.Println("Issue analysis complete")
+		}
+	}
+
+	return nil
+}
+
+// writeSyncResult prints the task counts, changes, resolved and remaining
+// conflicts of a sync, as what it would do for a dry run
+func writeSyncResult(out io.Writer, result *sync.SyncResult, resolved []sync.SyncChange, action sync.RemovedTaskAction) {
+	synced, removed := "Synced", "Archived"
+	switch {
+	case result.DryRun && action == sync.DeleteRemovedTasks:
+		synced, removed = "Would sync", "Would delete"
+	case result.DryRun:
+		synced, removed = "Would sync", "Would archive"
+	case action == sync.DeleteRemovedTasks:
+		removed = "Deleted"
+	}
+
+	if result.MarkdownToDBCount > 0 {
+		fmt.Fprintf(out, "%s %d tasks from markdown to database\n", synced, result.MarkdownToDBCount)
+	}
+	if result.DBToMarkdownCount > 0 {
+		fmt.Fprintf(out, "%s %d tasks from database to markdown\n", synced, result.DBToMarkdownCount)
+	}
+	if len(result.Removed) > 0 {
+		fmt.Fprintf(out, "%s %d tasks whose epic file was removed: %s\n", removed, len(result.Removed), strings.Join(result.Removed, ", "))
+	}
+	for _, c := range result.Changes {
+		fmt.Fprintf(out, "  %s (%s): %s\n", c.TaskID, c.Direction, strings.Join(c.Fields, ", "))
+	}
+
+	if len(resolved) > 0 {
+		fmt.Fprintf(out, "\nResolved %d conflicts:\n", len(resolved))
+		for _, c := range resolved {
+			fmt.Fprintf(out, "  %s (%s): %s\n", c.TaskID, c.Direction, strings.Join(c.Fields, ", "))
 		}
-		fmt.Println("Issue analysis complete")
 	}
+	if len(result.Conflicts) > 0 {
+		fmt.Fprintf(out, "\n%d conflicts detected:\n", len(result.Conflicts))
+		for _, c := range result.Conflicts {
+			fmt.Fprintf(out, "  %s: DB=%s, Markdown=%s\n", c.TaskID, c.DBStatus, c.MarkdownStatus)
+		}
+		fmt.Fprintln(out, "\nUse 'claude-orch tui' to resolve conflicts interactively.")
+	} else {
+		fmt.Fprintln(out, "No conflicts found.")
+	}
+}
+
+// checkDependencyCycles fails if unfinished tasks depend on each other in a
+// cycle, as the plan can then never finish
+func checkDependencyCycles(store *taskstore.Store) error {
+	tasks, err := store.ListTasks(taskstore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	completed, err := store.GetCompletedTaskIDs()
+	if err != nil {
+		return err
+	}
+
+	cycles := scheduler.New(tasks, completed).Cycles()
+	if len(cycles) == 0 {
+		return nil
+	}
+	lines := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		lines[i] = "  " + cycle.Error()
+	}
+	return fmt.Errorf("the plan can never finish, tasks depend on each other:\n%s\nRemove one of the dependencies of each cycle", strings.Join(lines, "\n"))
+}
+
+func runSyncStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.General.ProjectRoot == "" {
+		return fmt.Errorf("project_root not configured")
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := sync.New(cfg.General.ProjectRoot + "/docs/plans").SyncStatus(store)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[sync.Agreement]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID	STATE	DB	MARKDOWN	DETAILS")
+	for _, c := range report {
+		counts[c.Agreement]++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			c.TaskID, c.Agreement, orDash(string(c.DBStatus)), orDash(string(c.MarkdownStatus)), orDash(strings.Join(c.Fields, ", ")))
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d tasks: %d in sync, %d conflicts, %d changed in markdown, %d only in markdown, %d only in db\n",
+		len(report), counts[sync.InSync], counts[sync.StatusConflict], counts[sync.MarkdownChanged],
+		counts[sync.OnlyInMarkdown], counts[sync.OnlyInDB])
+	if len(report) > counts[sync.InSync] {
+		fmt.Println("Run 'claude-orch sync' to apply markdown changes; resolve conflicts in 'claude-orch tui'.")
+	}
+	return nil
+}
+
+func runSyncHistory(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
 
+	var taskID string
+	if len(args) == 1 {
+		id, err := domain.ParseTaskID(args[0])
+		if err != nil {
+			return err
+		}
+		taskID = id.String()
+	}
+	entries, err := store.ListSyncAudit(taskID, syncHistoryLimit)
+	if err != nil {
+		return err
+	}
+	writeSyncHistory(os.Stdout, entries)
 	return nil
 }
 
+// writeSyncHistory prints sync audit entries as a table
+func writeSyncHistory(out io.Writer, entries []taskstore.SyncAuditEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No sync history")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTASK\tDIRECTION\tSTATUS\tSOURCE")
+	for _, e := range entries {
+		source := "sync"
+		if e.Resolution {
+			source = "conflict resolution"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s → %s\t%s\n",
+			e.CreatedAt.Local().Format("2006-01-02 15:04:05"), e.TaskID, e.Direction, orDash(string(e.OldStatus)), e.NewStatus, source)
+	}
+	w.Flush()
+}
+
+// orDash returns s, or "-" if it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func runLogs(cmd *cobra.Command, args []string) error {
 	taskID := args[0]
 	fmt.Printf("Logs for task: %s\n", taskID)
@@ -384,12 +852,45 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	outputFilter, err := tui.ParseOutputFilter(cfg.General.AgentOutputFilter)
+	if err != nil {
+		return err
+	}
+	if tuiExistingBranch != "" {
+		cfg.General.ExistingBranch = tuiExistingBranch
+	}
+	if _, err := cfg.General.CompletedTodayStart(time.Now()); err != nil {
+		return err
+	}
+	completedSince := func(now time.Time) time.Time {
+		start, _ := cfg.General.CompletedTodayStart(now)
+		return start
+	}
 
 	// Open database
 	store, err := taskstore.New(cfg.General.DatabasePath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
+	store.SetDefaultGroupPriority(cfg.General.DefaultGroupTier)
+
+	// Refuse to share the database with another TUI, whose writes would conflict
+	dbLock, err := taskstore.AcquireLock(cfg.General.DatabasePath, tuiForce)
+	if err != nil {
+		store.Close()
+		return err
+	}
+	defer dbLock.Release()
+	if dbLock.TookOverPID != 0 {
+		fmt.Printf("Warning: took over the database from another TUI (pid %d)\n", dbLock.TookOverPID)
+	}
+
+	// A crash between updating an agent run and its task leaves them disagreeing
+	if mismatches, err := store.CheckStatusConsistency(); err != nil {
+		fmt.Printf("Warning: failed to check task status: %v\n", err)
+	} else if len(mismatches) > 0 {
+		fmt.Printf("Warning: %d task(s) disagree with their latest agent run; run 'claude-orch repair --status' to review and fix them\n", len(mismatches))
+	}
 
 	// Load all tasks
 	allTasks, err := store.ListTasks(taskstore.ListOptions{})
@@ -402,6 +903,8 @@ func runTUI(cmd *cobra.Command, args []string) error {
 
 	// Create agent manager with persistence
 	agentMgr := executor.NewAgentManager(cfg.General.MaxParallelAgents)
+	agentMgr.SetDBWriteQueueSize(cfg.General.DBWriteQueueSize)
+	agentMgr.SetMaxMaintenance(cfg.General.MaxMaintenanceAgents)
 	agentStoreAdp := &agentStoreAdapter{store: store}
 	agentMgr.SetStore(agentStoreAdp)
 
@@ -416,11 +919,18 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 	// Validate executor type
 	if !config.IsValidExecutor(executorType) {
-		return fmt.Errorf("invalid executor '%s': must be '%s' or '%s'",
-			executorType, config.ExecutorClaudeCode, config.ExecutorOpenCode)
+		return fmt.Errorf("invalid executor '%s': must be '%s', '%s' or '%s'",
+			executorType, config.ExecutorClaudeCode, config.ExecutorOpenCode, config.ExecutorGemini)
 	}
 	agentMgr.SetExecutorType(executor.ExecutorType(executorType))
 
+	// Set Claude Code model (CLI flag takes precedence over config)
+	claudeModel := cfg.General.ClaudeModel
+	if tuiClaudeModel != "" {
+		claudeModel = tuiClaudeModel
+	}
+	agentMgr.SetClaudeModel(claudeModel)
+
 	// Set OpenCode model (CLI flag takes precedence over config)
 	openCodeModel := cfg.General.OpenCodeModel
 	if tuiOpenCodeModel != "" {
@@ -428,7 +938,33 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 	agentMgr.SetOpenCodeModel(openCodeModel)
 
+	// Set Gemini CLI model (CLI flag takes precedence over config)
+	geminiModel := cfg.General.GeminiModel
+	if tuiGeminiModel != "" {
+		geminiModel = tuiGeminiModel
+	}
+	agentMgr.SetGeminiModel(geminiModel)
+
+	if err := configureAgents(cfg, agentMgr, executorType); err != nil {
+		return err
+	}
+
+	// Resume agents that fail for a transient reason, if enabled
+	if cfg.AgentRetry.MaxAttempts > 0 {
+		agentMgr.SetRetryPolicy(&executor.RetryPolicy{
+			MaxAttempts: cfg.AgentRetry.MaxAttempts,
+			BaseDelay:   time.Duration(cfg.AgentRetry.BaseDelaySecs) * time.Second,
+		})
+	}
+
+	if cfg.Sandbox.Enabled {
+		fmt.Printf("Running agents in %s container: %s\n", cfg.Sandbox.Runtime, cfg.Sandbox.Image)
+	}
+
 	// Log executor configuration
+	if executorType == config.ExecutorClaudeCode && claudeModel != "" {
+		fmt.Printf("Using executor: %s with model: %s\n", executorType, claudeModel)
+	}
 	if executorType == config.ExecutorOpenCode {
 		if openCodeModel != "" {
 			fmt.Printf("Using executor: %s with model: %s\n", executorType, openCodeModel)
@@ -439,12 +975,44 @@ func runTUI(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  Example: --opencode-model zai-coding-plan/glm-4.7\n")
 		}
 	}
+	if executorType == config.ExecutorGemini {
+		if geminiModel != "" {
+			fmt.Printf("Using executor: %s with model: %s\n", executorType, geminiModel)
+		} else {
+			fmt.Printf("Using executor: %s with the Gemini CLI's default model\n", executorType)
+		}
+	}
 
 	// Create syncer for updating README and epic status on completion
 	plansDir := cfg.General.ProjectRoot + "/docs/plans"
-	syncer := sync.New(plansDir)
+	syncer, err := newSyncer(cfg, plansDir)
+	if err != nil {
+		return err
+	}
 	agentMgr.SetSyncer(syncer)
 
+	// Agents merge their own PR, so the post-merge hook runs when one completes
+	// and GitHub confirms the merge
+	if cfg.General.PostMergeHook != "" {
+		pr := prbot.NewPRBot(cfg.General.ProjectRoot, nil)
+		pr.SetPostMergeHook(cfg.General.PostMergeHook)
+		agentMgr.SetMergeHandler(func(agent *executor.Agent, prNumber int) {
+			merged, err := pr.PRMerged(prNumber)
+			if err != nil {
+				agent.AppendOutput(fmt.Sprintf("[orchestrator] Skipping the post-merge hook: %v", err))
+				return
+			}
+			if !merged {
+				agent.AppendOutput(fmt.Sprintf("[orchestrator] Skipping the post-merge hook: PR #%d is not merged", prNumber))
+				return
+			}
+			task, _ := store.GetTask(agent.TaskID.String()) // nil for maintenance agents
+			if err := pr.OnMerge(task, prNumber); err != nil {
+				fmt.Printf("Warning: %s merged, but %v\n", agent.TaskID.String(), err)
+			}
+		})
+	}
+
 	// Recover any agents that were running before
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -454,58 +1022,22 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Warning: failed to recover agents: %v\n", err)
 	}
 
-	// Start build pool coordinator if enabled OR if local fallback is enabled
-	// This ensures agents get build MCP tools even when only using embedded worker
-	var buildPoolCoord *buildpool.Coordinator
-	var buildPoolGitDaemon *buildpool.GitDaemon
-	startCoordinator := cfg.BuildPool.Enabled || cfg.BuildPool.LocalFallback.Enabled
-	if startCoordinator {
-		// Create registry
-		registry := buildpool.NewRegistry()
-
-		// Set up embedded worker if enabled
-		var embeddedFunc buildpool.EmbeddedWorkerFunc
-		if cfg.BuildPool.LocalFallback.Enabled {
-			embedded := buildpool.NewEmbeddedWorker(buildpool.EmbeddedConfig{
-				RepoDir:     cfg.General.ProjectRoot,
-				WorktreeDir: cfg.BuildPool.LocalFallback.WorktreeDir,
-				MaxJobs:     cfg.BuildPool.LocalFallback.MaxJobs,
-				UseNixShell: true,
-			})
-			embeddedFunc = embedded.Run
-		}
-
-		// Create dispatcher with embedded worker
-		dispatcher := buildpool.NewDispatcher(registry, embeddedFunc)
-		dispatcher.SetLocalRepoPath(cfg.General.ProjectRoot)
-
-		// Create coordinator
-		buildPoolCoord = buildpool.NewCoordinator(buildpool.CoordinatorConfig{
-			WebSocketPort:     cfg.BuildPool.WebSocketPort,
-			HeartbeatInterval: time.Duration(cfg.BuildPool.Timeouts.HeartbeatIntervalSecs) * time.Second,
-			HeartbeatTimeout:  time.Duration(cfg.BuildPool.Timeouts.HeartbeatTimeoutSecs) * time.Second,
-			Debug:             cfg.BuildPool.Debug,
-		}, registry, dispatcher)
+	// Flag agents that stop producing output as stuck
+	if stuckAfter := cfg.AgentTimeouts.StuckAfter(); stuckAfter > 0 {
+		go agentMgr.WatchStuck(ctx, executor.StuckCheckInterval, stuckAfter)
+	}
 
-		// Start git daemon only if full build pool is enabled (needed for remote workers)
-		if cfg.BuildPool.Enabled {
-			buildPoolGitDaemon = buildpool.NewGitDaemon(buildpool.GitDaemonConfig{
-				Port:       cfg.BuildPool.GitDaemonPort,
-				BaseDir:    cfg.General.ProjectRoot,
-				ListenAddr: cfg.BuildPool.GitDaemonListenAddr,
-				Debug:      cfg.BuildPool.Debug,
-			})
-			if err := buildPoolGitDaemon.Start(ctx); err != nil {
-				fmt.Printf("Warning: failed to start git daemon: %v\n", err)
+	// Set up the build pool coordinator if enabled OR if local fallback is enabled
+	// This ensures agents get build MCP tools even when only using embedded worker.
+	// It starts with the TUI unless auto_start is off and can be toggled at runtime.
+	var buildPoolSvc *buildpool.Service
+	if cfg.BuildPool.Enabled || cfg.BuildPool.LocalFallback.Enabled {
+		buildPoolSvc = newBuildPoolService(cfg)
+		if cfg.BuildPool.AutoStart {
+			if err := buildPoolSvc.Start(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
 			}
 		}
-
-		// Run coordinator in goroutine (always, even if git daemon failed or wasn't started)
-		go func() {
-			if err := buildPoolCoord.Start(ctx); err != nil && ctx.Err() == nil {
-				fmt.Printf("Build pool coordinator error: %v\n", err)
-			}
-		}()
 	}
 
 	// Convert recovered agents to AgentViews for TUI
@@ -520,7 +1052,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 			WorktreePath: agent.WorktreePath,
 			Output:       agent.GetOutput(),
 		})
-		if agent.Status == executor.AgentRunning {
+		if agent.Status.Active() {
 			stillRunning++
 		} else {
 			completed++
@@ -568,31 +1100,76 @@ func runTUI(cmd *cobra.Command, args []string) error {
 				planWatcher.AddWorktree(agent.WorktreePath)
 			}
 		}
+
+		// Edits of epics in the project are passed on to their running agents
+		if cfg.General.SyncPlanChanges {
+			if err := planWatcher.AddWorktree(cfg.General.ProjectRoot); err != nil {
+				fmt.Printf("Warning: failed to watch plans in %s: %v\n", cfg.General.ProjectRoot, err)
+			}
+		}
 	}
 
 	// Build pool URL for TUI to fetch worker status and for agents to use MCP tools
-	// Set when either full build pool or local fallback is enabled
+	// Set while the build pool is running, or when a coordinator started with
+	// `build-pool start` published its URL
 	var buildPoolURL string
-	if cfg.BuildPool.Enabled || cfg.BuildPool.LocalFallback.Enabled {
-		buildPoolURL = fmt.Sprintf("http://localhost:%d", cfg.BuildPool.WebSocketPort)
+	if buildPoolSvc != nil && buildPoolSvc.Running() {
+		buildPoolURL = buildPoolSvc.URL()
+	} else if url := buildprotocol.DiscoverURL(); url != "" {
+		buildPoolURL = url
+	}
+
+	// Further coordinators whose workers the TUI shows alongside its own
+	var coordinators []tui.CoordinatorSource
+	for _, c := range cfg.BuildPool.Coordinators {
+		if c.URL == "" {
+			continue
+		}
+		name := c.Name
+		if name == "" {
+			name = c.URL
+		}
+		coordinators = append(coordinators, tui.CoordinatorSource{Name: name, URL: strings.TrimSuffix(c.URL, "/")})
+	}
+
+	var wtMgr *executor.WorktreeManager
+	if cfg.General.ProjectRoot != "" {
+		wtMgr, err = newWorktreeManager(cfg)
+		if err != nil {
+			return err
+		}
 	}
 
 	model := tui.NewModel(tui.ModelConfig{
-		MaxActive:       cfg.General.MaxParallelAgents,
-		AllTasks:        allTasks,
-		Queued:          queued,
-		ProjectRoot:     cfg.General.ProjectRoot,
-		WorktreeDir:     cfg.General.WorktreeDir,
-		PlansDir:        cfg.General.ProjectRoot + "/docs/plans",
-		BuildPoolURL:    buildPoolURL,
-		GitDaemonPort:   cfg.BuildPool.GitDaemonPort,
-		AgentManager:    agentMgr,
-		RecoveredAgents: recoveredViews,
-		PlanWatcher:     planWatcher,
-		PlanChangeChan:  planChangeChan,
-		Store:           store,
-		Syncer:          syncer,
-		CurrentVersion:  GetVersion(),
+		MaxActive:         cfg.General.MaxParallelAgents,
+		AllTasks:          allTasks,
+		Queued:            queued,
+		ProjectRoot:       cfg.General.ProjectRoot,
+		WorktreeDir:       cfg.General.WorktreeDir,
+		WorktreeManager:   wtMgr,
+		PlansDir:          cfg.General.ProjectRoot + "/docs/plans",
+		BuildPoolURL:      buildPoolURL,
+		GitDaemonPort:     cfg.BuildPool.GitDaemonPort,
+		BuildPool:         buildPoolSvc,
+		AgentManager:      agentMgr,
+		RecoveredAgents:   recoveredViews,
+		PlanWatcher:       planWatcher,
+		PlanChangeChan:    planChangeChan,
+		Store:             store,
+		Syncer:            syncer,
+		CurrentVersion:    GetVersion(),
+		SampleResources:   cfg.General.SampleResources,
+		MaxStartsPerTick:  cfg.General.MaxStartsPerTick,
+		DefaultGroupTier:  cfg.General.DefaultGroupTier,
+		MaxPerModule:      cfg.General.MaxAgentsPerModule,
+		ModuleTestTimeout: time.Duration(cfg.General.ModuleTestTimeoutSecs) * time.Second,
+		TestChangedOnly:   cfg.General.ModuleTestChangedOnly,
+		HistoryLogLines:   cfg.General.HistoryLogLines,
+		MaxFailuresInARow: cfg.General.MaxConsecutiveFailures,
+		Coordinators:      coordinators,
+		OutputFilter:      outputFilter,
+		CompletedSince:    completedSince,
+		SyncPlanChanges:   cfg.General.SyncPlanChanges,
 	})
 
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
@@ -602,12 +1179,9 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	// Stop the database write queue to flush pending writes
 	agentMgr.StopDBWriter()
 
-	// Stop build pool coordinator if it was started
-	if buildPoolCoord != nil {
-		buildPoolCoord.Stop()
-	}
-	if buildPoolGitDaemon != nil {
-		buildPoolGitDaemon.Stop()
+	// Stop build pool coordinator if it is running
+	if buildPoolSvc != nil {
+		buildPoolSvc.Close()
 	}
 
 	if err != nil {
@@ -670,6 +1244,10 @@ func (a *storeAdapter) RemoveGroupPriority(group string) error {
 	return a.store.RemoveGroupPriority(group)
 }
 
+func (a *storeAdapter) Ping() error {
+	return a.store.Ping()
+}
+
 func (a *storeAdapter) GetGroupsWithTaskCounts() ([]api.GroupStats, error) {
 	stats, err := a.store.GetGroupsWithTaskCounts()
 	if err != nil {
@@ -693,7 +1271,12 @@ type agentStoreAdapter struct {
 }
 
 func (a *agentStoreAdapter) SaveAgentRun(run *executor.AgentRunRecord) error {
-	return a.store.SaveAgentRun(&taskstore.AgentRun{
+	return a.store.SaveAgentRun(toAgentRun(run))
+}
+
+// toAgentRun converts an executor record to the taskstore's agent run
+func toAgentRun(run *executor.AgentRunRecord) *taskstore.AgentRun {
+	return &taskstore.AgentRun{
 		ID:           run.ID,
 		TaskID:       run.TaskID,
 		WorktreePath: run.WorktreePath,
@@ -704,7 +1287,7 @@ func (a *agentStoreAdapter) SaveAgentRun(run *executor.AgentRunRecord) error {
 		FinishedAt:   run.FinishedAt,
 		ErrorMessage: run.ErrorMessage,
 		SessionID:    run.SessionID,
-	})
+	}
 }
 
 func (a *agentStoreAdapter) UpdateAgentRunStatus(id string, status string, errorMessage string) error {
@@ -772,6 +1355,37 @@ func (a *agentStoreAdapter) UpdateTaskStatus(id string, status domain.TaskStatus
 	return a.store.UpdateTaskStatus(id, status)
 }
 
+func (a *agentStoreAdapter) WriteBatch(fn func(w executor.AgentStoreWriter) error) error {
+	return a.store.WriteAgentRuns(func(tx *taskstore.AgentRunTx) error {
+		return fn(&agentRunTxAdapter{tx: tx})
+	})
+}
+
+// agentRunTxAdapter wraps taskstore.AgentRunTx to implement executor.AgentStoreWriter
+type agentRunTxAdapter struct {
+	tx *taskstore.AgentRunTx
+}
+
+func (a *agentRunTxAdapter) SaveAgentRun(run *executor.AgentRunRecord) error {
+	return a.tx.SaveAgentRun(toAgentRun(run))
+}
+
+func (a *agentRunTxAdapter) UpdateAgentRunStatus(id string, status string, errorMessage string) error {
+	return a.tx.UpdateAgentRunStatus(id, status, errorMessage)
+}
+
+func (a *agentRunTxAdapter) UpdateAgentRunUsage(id string, tokensInput, tokensOutput int, costUSD float64) error {
+	return a.tx.UpdateAgentRunUsage(id, tokensInput, tokensOutput, costUSD)
+}
+
+func (a *agentRunTxAdapter) DeleteAgentRun(id string) error {
+	return a.tx.DeleteAgentRun(id)
+}
+
+func (a *agentRunTxAdapter) UpdateTaskStatus(id string, status domain.TaskStatus) error {
+	return a.tx.UpdateTaskStatus(id, status)
+}
+
 func runServe(cmd *cobra.Command, args []string) error {
 	cfg, err := loadConfig()
 	if err != nil {
@@ -782,6 +1396,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	store.SetDefaultGroupPriority(cfg.General.DefaultGroupTier)
 
 	port := servePort
 	if port == 0 {
@@ -791,6 +1406,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	addr := fmt.Sprintf("%s:%d", cfg.Web.Host, port)
 	adapter := &storeAdapter{store: store}
 	server := api.NewServer(adapter, nil, nil, nil, addr)
+	if cfg.BuildPool.Enabled {
+		server.SetBuildPoolURL(fmt.Sprintf("http://localhost:%d", cfg.BuildPool.WebSocketPort))
+	}
 
 	fmt.Printf("Starting web UI at http://%s\n", addr)
 	return server.Start()
@@ -821,17 +1439,32 @@ func runBuildPoolStart(cmd *cobra.Command, args []string) error {
 	var embeddedFunc buildpool.EmbeddedWorkerFunc
 	if cfg.BuildPool.LocalFallback.Enabled {
 		embedded := buildpool.NewEmbeddedWorker(buildpool.EmbeddedConfig{
-			RepoDir:     cfg.General.ProjectRoot,
-			WorktreeDir: cfg.BuildPool.LocalFallback.WorktreeDir,
-			MaxJobs:     cfg.BuildPool.LocalFallback.MaxJobs,
-			UseNixShell: true,
+			RepoDir:       cfg.General.ProjectRoot,
+			WorktreeDir:   cfg.BuildPool.LocalFallback.WorktreeDir,
+			MaxJobs:       cfg.BuildPool.LocalFallback.MaxJobs,
+			UseNixShell:   true,
+			WarmWorktrees: cfg.BuildPool.LocalFallback.WarmWorktrees,
 		})
+		defer embedded.Close()
 		embeddedFunc = embedded.Run
 	}
 
+	serialization, err := buildpool.ParseJobSerialization(cfg.BuildPool.SerializeJobs)
+	if err != nil {
+		return fmt.Errorf("build_pool.serialize_jobs: %w", err)
+	}
+
+	events, err := buildpool.OpenEventLog(cfg.BuildPool.EventLog)
+	if err != nil {
+		return fmt.Errorf("build_pool.event_log: %w", err)
+	}
+	defer events.Close()
+
 	// Create dispatcher with embedded worker
 	dispatcher := buildpool.NewDispatcher(registry, embeddedFunc)
 	dispatcher.SetLocalRepoPath(cfg.General.ProjectRoot)
+	dispatcher.SetSerialization(serialization)
+	dispatcher.SetResultCacheSize(cfg.BuildPool.ResultCacheSize)
 
 	// Create coordinator
 	coord := buildpool.NewCoordinator(buildpool.CoordinatorConfig{
@@ -839,6 +1472,10 @@ func runBuildPoolStart(cmd *cobra.Command, args []string) error {
 		HeartbeatInterval: time.Duration(cfg.BuildPool.Timeouts.HeartbeatIntervalSecs) * time.Second,
 		HeartbeatTimeout:  time.Duration(cfg.BuildPool.Timeouts.HeartbeatTimeoutSecs) * time.Second,
 		Debug:             cfg.BuildPool.Debug,
+		AdminToken:        cfg.BuildPool.AdminToken,
+		DiscoveryFile:     buildprotocol.DefaultDiscoveryPath(),
+		Events:            events,
+		Metrics:           cfg.BuildPool.Metrics,
 	}, registry, dispatcher)
 
 	// Start git daemon
@@ -855,6 +1492,7 @@ func runBuildPoolStart(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Build pool coordinator starting...\n")
 	fmt.Printf("  WebSocket: :%d\n", cfg.BuildPool.WebSocketPort)
 	fmt.Printf("  Git daemon: :%d\n", cfg.BuildPool.GitDaemonPort)
+	fmt.Printf("  Discovery file: %s\n", buildprotocol.DefaultDiscoveryPath())
 
 	// Run coordinator in goroutine
 	errCh := make(chan error, 1)
@@ -902,7 +1540,7 @@ func runBuildPoolTest(cmd *cobra.Command, args []string) error {
 	quick, _ := cmd.Flags().GetBool("quick")
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
-	buildPoolURL := fmt.Sprintf("http://localhost:%d", cfg.BuildPool.WebSocketPort)
+	buildPoolURL := coordinatorURL(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
@@ -929,6 +1567,7 @@ func runBuildPoolTest(cmd *cobra.Command, args []string) error {
 		BuildPoolURL: buildPoolURL,
 		ProjectRoot:  cfg.General.ProjectRoot,
 		Verbose:      verbose,
+		BinaryPath:   cfg.General.BinaryPath(config.ExecutorClaudeCode),
 	}, func(line string) {
 		// Print each line of output as it comes
 		fmt.Print(line)
@@ -948,13 +1587,230 @@ func runBuildPoolTest(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runCleanupWorktrees(cmd *cobra.Command, args []string) error {
+func runBuildPoolReplay(cmd *cobra.Command, args []string) error {
 	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
+	jobID := args[0]
+	useNix, _ := cmd.Flags().GetBool("nix")
+	buildPoolURL := coordinatorURL(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := buildpool.FetchRetainedJob(ctx, buildPoolURL, jobID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replaying job %s\n", jobID)
+	fmt.Printf("  Command: %s\n", job.Command)
+	if job.Repo != "" {
+		fmt.Printf("  Repo:    %s\n", job.Repo)
+		fmt.Printf("  Commit:  %s\n", job.Commit)
+	}
+	fmt.Println()
+
+	result := buildpool.ReplayJob(job, cfg.General.ProjectRoot, buildpool.EmbeddedConfig{
+		RepoDir:     cfg.General.ProjectRoot,
+		WorktreeDir: cfg.BuildPool.LocalFallback.WorktreeDir,
+		UseNixShell: useNix,
+	})
+
+	if result.Stdout != "" || result.Stderr != "" {
+		fmt.Print(result.Stdout)
+		fmt.Fprint(os.Stderr, result.Stderr)
+	} else {
+		fmt.Print(result.Output)
+	}
+
+	fmt.Printf("\nExit code: %d (%.1fs)\n", result.ExitCode, result.DurationSecs)
+	return nil
+}
+
+// coordinatorURL returns the URL of a running `build-pool start` coordinator:
+// the one published in its discovery file, else the configured port on localhost
+func coordinatorURL(cfg *config.Config) string {
+	if url := buildprotocol.DiscoverURL(); url != "" {
+		return url
+	}
+	return fmt.Sprintf("http://localhost:%d", cfg.BuildPool.WebSocketPort)
+}
+
+// newBuildPoolService creates the in-process build pool for the TUI: a
+// coordinator with the embedded worker if local fallback is enabled, plus a
+// git daemon for remote workers if the full build pool is enabled
+func newBuildPoolService(cfg *config.Config) *buildpool.Service {
+	serialization, err := buildpool.ParseJobSerialization(cfg.BuildPool.SerializeJobs)
+	if err != nil {
+		fmt.Printf("Warning: build_pool.serialize_jobs: %v\n", err)
+	}
+
+	// Set up embedded worker if enabled, shared by the coordinators so its
+	// warm worktrees survive restarts
+	var embeddedFunc buildpool.EmbeddedWorkerFunc
+	var cleanup func()
+	if cfg.BuildPool.LocalFallback.Enabled {
+		embedded := buildpool.NewEmbeddedWorker(buildpool.EmbeddedConfig{
+			RepoDir:       cfg.General.ProjectRoot,
+			WorktreeDir:   cfg.BuildPool.LocalFallback.WorktreeDir,
+			MaxJobs:       cfg.BuildPool.LocalFallback.MaxJobs,
+			UseNixShell:   true,
+			WarmWorktrees: cfg.BuildPool.LocalFallback.WarmWorktrees,
+		})
+		embeddedFunc = embedded.Run
+		cleanup = embedded.Close
+	}
+
+	// The event log is shared by the coordinators like the embedded worker.
+	// Events on stderr would be drawn over the TUI, so only a file is used here.
+	eventLog := cfg.BuildPool.EventLog
+	if eventLog == "stderr" {
+		fmt.Println("Warning: build_pool.event_log = \"stderr\" is not supported in the TUI; set a file to log events")
+		eventLog = ""
+	}
+	events, err := buildpool.OpenEventLog(eventLog)
+	if err != nil {
+		fmt.Printf("Warning: build_pool.event_log: %v\n", err)
+	}
+	if events != nil {
+		closeEmbedded := cleanup
+		cleanup = func() {
+			if closeEmbedded != nil {
+				closeEmbedded()
+			}
+			events.Close()
+		}
+	}
+
+	newCoordinator := func() *buildpool.Coordinator {
+		registry := buildpool.NewRegistry()
+
+		// Create dispatcher with embedded worker
+		dispatcher := buildpool.NewDispatcher(registry, embeddedFunc)
+		dispatcher.SetLocalRepoPath(cfg.General.ProjectRoot)
+		dispatcher.SetSerialization(serialization)
+		dispatcher.SetResultCacheSize(cfg.BuildPool.ResultCacheSize)
+
+		return buildpool.NewCoordinator(buildpool.CoordinatorConfig{
+			WebSocketPort:     cfg.BuildPool.WebSocketPort,
+			HeartbeatInterval: time.Duration(cfg.BuildPool.Timeouts.HeartbeatIntervalSecs) * time.Second,
+			HeartbeatTimeout:  time.Duration(cfg.BuildPool.Timeouts.HeartbeatTimeoutSecs) * time.Second,
+			Debug:             cfg.BuildPool.Debug,
+			AdminToken:        cfg.BuildPool.AdminToken,
+			Events:            events,
+			Metrics:           cfg.BuildPool.Metrics,
+		}, registry, dispatcher)
+	}
+
+	// Git daemon only if full build pool is enabled (needed for remote workers)
+	var newGitDaemon func() *buildpool.GitDaemon
+	if cfg.BuildPool.Enabled {
+		newGitDaemon = func() *buildpool.GitDaemon {
+			return buildpool.NewGitDaemon(buildpool.GitDaemonConfig{
+				Port:       cfg.BuildPool.GitDaemonPort,
+				BaseDir:    cfg.General.ProjectRoot,
+				ListenAddr: cfg.BuildPool.GitDaemonListenAddr,
+				Debug:      cfg.BuildPool.Debug,
+			})
+		}
+	}
+
+	return buildpool.NewService(buildpool.ServiceConfig{
+		URL:            fmt.Sprintf("http://localhost:%d", cfg.BuildPool.WebSocketPort),
+		NewCoordinator: newCoordinator,
+		NewGitDaemon:   newGitDaemon,
+		Cleanup:        cleanup,
+	})
+}
+
+// configureAgents applies the configured output limit, timeouts, epic lookup
+// and sandbox to agentMgr and resolves the executor binaries, failing early
+// rather than on every agent start
+func configureAgents(cfg *config.Config, agentMgr *executor.AgentManager, executorType string) error {
+	// Bound the output running agents keep in memory
+	agentMgr.SetMaxOutputLines(cfg.General.AgentOutputLines)
+
+	// Kill agents that run longer than their executor's timeout
+	for _, t := range []string{config.ExecutorClaudeCode, config.ExecutorOpenCode, config.ExecutorGemini} {
+		agentMgr.SetAgentTimeout(executor.ExecutorType(t), cfg.AgentTimeouts.For(t))
+	}
+
+	// Give stopped agents time to clean up before they are killed
+	agentMgr.SetStopGrace(cfg.AgentTimeouts.StopGrace())
+
+	// Tell agents where their epic file is for the status checks on resume
+	if err := agentMgr.SetPlansDir(cfg.General.PlansDir); err != nil {
+		return fmt.Errorf("plans_dir: %w", err)
+	}
+	if err := agentMgr.SetEpicFilePattern(cfg.General.EpicFilePattern); err != nil {
+		return fmt.Errorf("epic_file_pattern: %w", err)
+	}
+
+	// Run agents in a container if configured
+	if err := cfg.ValidateSandbox(); err != nil {
+		return err
+	}
+	if cfg.Sandbox.Enabled {
+		agentMgr.SetSandbox(&executor.Sandbox{
+			Runtime: cfg.Sandbox.Runtime,
+			Image:   cfg.Sandbox.Image,
+			Flags:   cfg.Sandbox.Flags,
+		})
+		return nil // Sandboxed agents run the binary of their image
+	}
+
+	for _, t := range []string{config.ExecutorClaudeCode, config.ExecutorOpenCode, config.ExecutorGemini} {
+		path := cfg.General.BinaryPath(t)
+		if path == "" && t != executorType {
+			continue // Not configured and not used by default
+		}
+		if err := agentMgr.SetBinaryPath(executor.ExecutorType(t), path); err != nil {
+			return fmt.Errorf("executor %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// newWorktreeManager creates a worktree manager honoring the configured
+// candidate directories, free space minimum and existing branch policy
+func newWorktreeManager(cfg *config.Config) (*executor.WorktreeManager, error) {
+	policy, err := executor.ParseBranchPolicy(cfg.General.ExistingBranch)
+	if err != nil {
+		return nil, err
+	}
 	wtMgr := executor.NewWorktreeManager(cfg.General.ProjectRoot, cfg.General.WorktreeDir)
+	wtMgr.SetWorktreeDirs(cfg.General.WorktreeDirs)
+	wtMgr.SetMinFreeSpace(uint64(cfg.General.MinWorktreeFreeMB) * 1024 * 1024)
+	wtMgr.SetBranchPolicy(policy)
+	return wtMgr, nil
+}
+
+// newSyncer creates a syncer for plansDir honoring the configured handling of
+// tasks whose epic file was deleted and whether status changes are committed
+func newSyncer(cfg *config.Config, plansDir string) (*sync.Syncer, error) {
+	action, err := sync.ParseRemovedTaskAction(cfg.General.RemovedEpicAction)
+	if err != nil {
+		return nil, err
+	}
+	syncer := sync.New(plansDir)
+	syncer.SetRemovedTaskAction(action)
+	syncer.SetAutoCommit(cfg.General.AutoCommitStatus)
+	return syncer, nil
+}
+
+func runCleanupWorktrees(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wtMgr, err := newWorktreeManager(cfg)
+	if err != nil {
+		return err
+	}
 
 	paths, err := wtMgr.List()
 	if err != nil {
@@ -1028,3 +1884,92 @@ func runCleanupWorktrees(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runWorktreeCheck(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	runs, err := store.ListActiveAgentRuns()
+	if err != nil {
+		return fmt.Errorf("list active runs: %w", err)
+	}
+	activeWorktrees := make(map[string]bool)
+	for _, run := range runs {
+		if run.WorktreePath != "" {
+			activeWorktrees[run.WorktreePath] = true
+		}
+	}
+
+	wtMgr, err := newWorktreeManager(cfg)
+	if err != nil {
+		return err
+	}
+	checks, err := wtMgr.Check(activeWorktrees)
+	if err != nil {
+		return fmt.Errorf("check worktrees: %w", err)
+	}
+
+	if len(checks) == 0 {
+		fmt.Println("No worktrees found.")
+		return nil
+	}
+
+	var problems []executor.WorktreeCheck
+	for _, check := range checks {
+		if !check.OK() {
+			problems = append(problems, check)
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Printf("All %d worktree(s) are consistent.\n", len(checks))
+		return nil
+	}
+
+	fmt.Printf("Found %d of %d worktree(s) with problems:\n", len(problems), len(checks))
+	var fixed, failed int
+	for _, check := range problems {
+		names := make([]string, len(check.Problems))
+		for i, p := range check.Problems {
+			names[i] = string(p)
+		}
+		branch := check.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		fmt.Printf("  - %s [%s]: %s\n", check.Path, branch, strings.Join(names, ", "))
+
+		if !worktreeFix {
+			continue
+		}
+		action, err := wtMgr.Repair(check)
+		if err != nil {
+			fmt.Printf("      not fixed: %v\n", err)
+			failed++
+		} else {
+			fmt.Printf("      fixed: %s\n", action)
+			fixed++
+		}
+	}
+
+	if !worktreeFix {
+		fmt.Println("\nRun with --fix to repair them.")
+		return nil
+	}
+
+	fmt.Printf("\nFixed %d worktree(s)", fixed)
+	if failed > 0 {
+		fmt.Printf(", %d not fixed", failed)
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+func TestWriteSyncResult(t *testing.T) {
+	result := &sync.SyncResult{
+		MarkdownToDBCount: 3,
+		Removed:           []string{"billing/E01"},
+		Changes: []sync.SyncChange{
+			{TaskID: "billing/E01", Direction: sync.MarkdownToDB, Fields: []string{"deleted (epic file removed)"}},
+			{TaskID: "billing/E02", Direction: sync.MarkdownToDB, Fields: []string{"title", "priority"}},
+		},
+		Conflicts: []sync.SyncConflict{{TaskID: "billing/E03", DBStatus: "complete", MarkdownStatus: "not_started"}},
+	}
+
+	var out bytes.Buffer
+	writeSyncResult(&out, result, nil, sync.DeleteRemovedTasks)
+	applied := out.String()
+	for _, want := range []string{
+		"Synced 3 tasks from markdown to database\n",
+		"Deleted 1 tasks whose epic file was removed: billing/E01\n",
+		"  billing/E02 (markdown → db): title, priority\n",
+		"  billing/E03: DB=complete, Markdown=not_started\n",
+	} {
+		if !strings.Contains(applied, want) {
+			t.Errorf("report lacks %q:\n%s", want, applied)
+		}
+	}
+
+	// A dry run reports the same, as what the sync would do
+	result.DryRun = true
+	out.Reset()
+	writeSyncResult(&out, result, nil, sync.DeleteRemovedTasks)
+	want := strings.NewReplacer("Synced", "Would sync", "Deleted", "Would delete").Replace(applied)
+	if got := out.String(); got != want {
+		t.Errorf("dry-run report = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	writeSyncResult(&out, &sync.SyncResult{DryRun: true, Removed: []string{"billing/E01"}}, nil, sync.ArchiveRemovedTasks)
+	if got := out.String(); !strings.HasPrefix(got, "Would archive 1 tasks") || !strings.Contains(got, "No conflicts found.") {
+		t.Errorf("dry-run archive report = %q", got)
+	}
+
+	// Conflicts resolved by --resolve are listed apart from those left
+	out.Reset()
+	resolved := []sync.SyncChange{{TaskID: "billing/E04", Direction: sync.DBToMarkdown, Fields: []string{"status: not_started → complete"}}}
+	writeSyncResult(&out, &sync.SyncResult{}, resolved, sync.ArchiveRemovedTasks)
+	if got := out.String(); !strings.Contains(got, "Resolved 1 conflicts:\n  billing/E04 (db → markdown): status: not_started → complete\n") ||
+		!strings.Contains(got, "No conflicts found.") {
+		t.Errorf("resolved report = %q", got)
+	}
+}
+
+func TestWriteSyncHistory(t *testing.T) {
+	var out bytes.Buffer
+	writeSyncHistory(&out, nil)
+	if got := out.String(); got != "No sync history\n" {
+		t.Errorf("empty history = %q", got)
+	}
+
+	out.Reset()
+	writeSyncHistory(&out, []taskstore.SyncAuditEntry{
+		{TaskID: "billing/E01", Direction: string(sync.MarkdownToDB), NewStatus: domain.StatusNotStarted, CreatedAt: time.Now()},
+		{TaskID: "billing/E01", Direction: string(sync.DBToMarkdown), OldStatus: domain.StatusComplete, NewStatus: domain.StatusInProgress, Resolution: true, CreatedAt: time.Now()},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 entries:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{
+		"billing/E01 markdown → db - → not_started sync",
+		"billing/E01 db → markdown complete → in_progress conflict resolution",
+	} {
+		// Skip the date and time columns
+		if got := strings.Join(strings.Fields(lines[i+1])[2:], " "); got != want {
+			t.Errorf("line %d = %q, want %q", i+1, got, want)
+		}
+	}
+}
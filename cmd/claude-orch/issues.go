@@ -102,6 +102,7 @@ func runIssuesAnalyze(cmd *cobra.Command, args []string) error {
 
 	analyzer := issues.NewAnalyzer(store, &cfg.GitHubIssues,
 		filepath.Join(cfg.General.ProjectRoot, "docs", "plans"))
+	analyzer.SetClaudeBinaryPath(cfg.General.ClaudeBinaryPath)
 
 	fmt.Printf("Analyzing issue #%d...\n", issueNum)
 	if err := analyzer.AnalyzeOne(cmd.Context(), issue); err != nil {
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/spf13/cobra"
+)
+
+var modulesJSON bool
+
+var modulesCmd = &cobra.Command{
+	Use:   "modules",
+	Short: "Show per-module epic and test statistics",
+	RunE:  runModules,
+}
+
+func init() {
+	modulesCmd.Flags().BoolVar(&modulesJSON, "json", false, "print the summaries as JSON")
+	rootCmd.AddCommand(modulesCmd)
+}
+
+func runModules(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	summaries, err := loadModuleSummaries(store)
+	if err != nil {
+		return err
+	}
+
+	if modulesJSON {
+		return writeModulesJSON(os.Stdout, summaries)
+	}
+	writeModules(os.Stdout, summaries)
+	return nil
+}
+
+// loadModuleSummaries summarizes the stored tasks per module. The database
+// does not keep test results, so they are read from each epic's Test Summary
+// section, as the TUI does when plan files change.
+func loadModuleSummaries(store *taskstore.Store) ([]*domain.ModuleSummary, error) {
+	tasks, err := store.ListTasks(taskstore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		if task.TestSummary != nil || task.FilePath == "" {
+			continue
+		}
+		content, err := os.ReadFile(task.FilePath)
+		if err != nil {
+			continue // Epic file moved or not synced yet
+		}
+		task.TestSummary = parser.ExtractTestSummary(content)
+	}
+
+	return domain.SummarizeModules(tasks), nil
+}
+
+// writeModules prints the module summaries as a table, the same columns as
+// the TUI's Modules tab, followed by the test totals
+func writeModules(out io.Writer, summaries []*domain.ModuleSummary) {
+	if len(summaries) == 0 {
+		fmt.Fprintln(out, "No modules")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tEPICS\tDONE\tIN PROGRESS\tTESTS\tPASSED\tFAILED\tCOVERAGE")
+	var totalTests, totalPassed, totalFailed int
+	for _, mod := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			mod.Name, mod.TotalEpics, mod.CompletedEpics, mod.InProgressEpics,
+			mod.TotalTests, mod.PassedTests, mod.FailedTests, orDash(mod.Coverage))
+		totalTests += mod.TotalTests
+		totalPassed += mod.PassedTests
+		totalFailed += mod.FailedTests
+	}
+	w.Flush()
+
+	if totalTests > 0 {
+		passRate := float64(totalPassed) / float64(totalTests) * 100
+		fmt.Fprintf(out, "\nTotal: %d tests, %d passed, %d failed (%.0f%% pass rate)\n",
+			totalTests, totalPassed, totalFailed, passRate)
+	}
+}
+
+// writeModulesJSON prints the module summaries as indented JSON
+func writeModulesJSON(out io.Writer, summaries []*domain.ModuleSummary) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summaries)
+}
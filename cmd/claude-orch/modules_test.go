@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+// seedModules stores epics of two modules; billing/E01's epic file has a
+// Test Summary section
+func seedModules(t *testing.T) *taskstore.Store {
+	t.Helper()
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	epicPath := filepath.Join(t.TempDir(), "epic-01-invoices.md")
+	epic := "# E01: Invoices\n\n## Test Summary\n\n| Metric | Value |\n|--------|-------|\n| Tests | 12 |\n| Passed | 9 |\n| Failed | 3 |\n"
+	if err := os.WriteFile(epicPath, []byte(epic), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "Invoices", Status: domain.StatusComplete, FilePath: epicPath},
+		{ID: domain.TaskID{Module: "billing", EpicNum: 2}, Title: "Dunning", Status: domain.StatusInProgress},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 1}, Title: "Parcels", Status: domain.StatusNotStarted, FilePath: filepath.Join(t.TempDir(), "missing.md")},
+	}
+	for _, task := range tasks {
+		if err := store.UpsertTask(task); err != nil {
+			t.Fatalf("UpsertTask: %v", err)
+		}
+	}
+	return store
+}
+
+func TestModulesOutput_MatchesSummaries(t *testing.T) {
+	store := seedModules(t)
+
+	summaries, err := loadModuleSummaries(store)
+	if err != nil {
+		t.Fatalf("loadModuleSummaries: %v", err)
+	}
+
+	// Same numbers as summarizing the tasks with their parsed test results
+	tasks, _ := store.ListTasks(taskstore.ListOptions{})
+	for _, task := range tasks {
+		if task.ID.String() == "billing/E01" {
+			task.TestSummary = &domain.TestSummary{Tests: 12, Passed: 9, Failed: 3}
+		}
+	}
+	want := domain.SummarizeModules(tasks)
+	if len(summaries) != len(want) {
+		t.Fatalf("got %d summaries, want %d", len(summaries), len(want))
+	}
+	for i := range want {
+		if *summaries[i] != *want[i] {
+			t.Errorf("summary %d = %+v, want %+v", i, *summaries[i], *want[i])
+		}
+	}
+
+	var out bytes.Buffer
+	writeModules(&out, summaries)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "MODULE") {
+		t.Fatalf("expected a header, two modules and a total, got:\n%s", out.String())
+	}
+	for i, mod := range want {
+		fields := strings.Fields(lines[i+1])
+		wantFields := []string{mod.Name,
+			fmt.Sprint(mod.TotalEpics), fmt.Sprint(mod.CompletedEpics), fmt.Sprint(mod.InProgressEpics),
+			fmt.Sprint(mod.TotalTests), fmt.Sprint(mod.PassedTests), fmt.Sprint(mod.FailedTests), orDash(mod.Coverage)}
+		if strings.Join(fields, " ") != strings.Join(wantFields, " ") {
+			t.Errorf("row %q, want fields %q", lines[i+1], wantFields)
+		}
+	}
+	if lines[4] != "Total: 12 tests, 9 passed, 3 failed (75% pass rate)" {
+		t.Errorf("total = %q", lines[4])
+	}
+}
+
+func TestModulesOutput_JSON(t *testing.T) {
+	store := seedModules(t)
+	summaries, err := loadModuleSummaries(store)
+	if err != nil {
+		t.Fatalf("loadModuleSummaries: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeModulesJSON(&out, summaries); err != nil {
+		t.Fatalf("writeModulesJSON: %v", err)
+	}
+	var got []domain.ModuleSummary
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != len(summaries) {
+		t.Fatalf("got %d modules, want %d", len(got), len(summaries))
+	}
+	for i := range got {
+		if got[i] != *summaries[i] {
+			t.Errorf("module %d = %+v, want %+v", i, got[i], *summaries[i])
+		}
+	}
+	if !strings.Contains(out.String(), `"coverage": "75%"`) {
+		t.Errorf("billing coverage missing from JSON:\n%s", out.String())
+	}
+
+	// No modules is an empty list, not null
+	out.Reset()
+	writeModulesJSON(&out, domain.SummarizeModules(nil))
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("empty summaries = %q, want []", out.String())
+	}
+}
@@ -171,10 +171,6 @@ worktree_dir = %q
 max_parallel_agents = %d
 database_path = %q
 
-[claude]
-model = "claude-opus-4-5-20251101"
-max_tokens = 16000
-
 [notifications]
 desktop = %t
 slack_webhook = %q
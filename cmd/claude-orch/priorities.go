@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/spf13/cobra"
+)
+
+var prioritiesJSON bool
+
+var prioritiesCmd = &cobra.Command{
+	Use:   "priorities",
+	Short: "Show and set group priority tiers",
+}
+
+var prioritiesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups with their priority tier",
+	Args:  cobra.NoArgs,
+	RunE:  runPrioritiesList,
+}
+
+var prioritiesSetCmd = &cobra.Command{
+	Use:   "set <module> <tier>",
+	Short: "Assign a group to a priority tier",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPrioritiesSet,
+}
+
+var prioritiesUnsetCmd = &cobra.Command{
+	Use:   "unset <module>",
+	Short: "Remove a group's tier so it runs with the default tier",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPrioritiesUnset,
+}
+
+func init() {
+	prioritiesCmd.PersistentFlags().BoolVar(&prioritiesJSON, "json", false, "print the groups as JSON")
+	prioritiesCmd.AddCommand(prioritiesListCmd, prioritiesSetCmd, prioritiesUnsetCmd)
+	rootCmd.AddCommand(prioritiesCmd)
+}
+
+// groupPriorityJSON is the JSON form of a group's priority
+type groupPriorityJSON struct {
+	Module    string `json:"module"`
+	Tier      int    `json:"tier"`
+	Assigned  bool   `json:"assigned"` // false = runs with the default tier
+	Weight    int    `json:"weight"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+}
+
+// openPriorityStore opens the task store and returns the configured tier
+// of groups without an explicit one
+func openPriorityStore() (*taskstore.Store, int, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	store.SetDefaultGroupPriority(cfg.General.DefaultGroupTier)
+	return store, cfg.General.DefaultGroupTier, nil
+}
+
+func runPrioritiesList(cmd *cobra.Command, args []string) error {
+	store, defaultTier, err := openPriorityStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	groups, err := loadGroupPriorities(store, defaultTier)
+	if err != nil {
+		return err
+	}
+
+	if prioritiesJSON {
+		return writeJSON(os.Stdout, groups)
+	}
+	writeGroupPriorities(os.Stdout, groups)
+	return nil
+}
+
+func runPrioritiesSet(cmd *cobra.Command, args []string) error {
+	tier, err := strconv.Atoi(args[1])
+	if err != nil || tier < 0 {
+		return fmt.Errorf("invalid tier %q: must be a number >= 0", args[1])
+	}
+
+	store, defaultTier, err := openPriorityStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.SetGroupPriority(args[0], tier); err != nil {
+		return err
+	}
+	return reportGroupPriority(os.Stdout, store, defaultTier, args[0])
+}
+
+func runPrioritiesUnset(cmd *cobra.Command, args []string) error {
+	store, defaultTier, err := openPriorityStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.RemoveGroupPriority(args[0]); err != nil {
+		return err
+	}
+	return reportGroupPriority(os.Stdout, store, defaultTier, args[0])
+}
+
+// loadGroupPriorities returns every group with tasks or an assigned tier,
+// ordered as the scheduler runs them: by tier, heavier groups first. Groups
+// without an explicit tier report defaultTier.
+func loadGroupPriorities(store *taskstore.Store, defaultTier int) ([]groupPriorityJSON, error) {
+	stats, err := store.GetGroupsWithTaskCounts()
+	if err != nil {
+		return nil, err
+	}
+	priorities, err := store.GetGroupPriorities()
+	if err != nil {
+		return nil, err
+	}
+	weights, err := store.GetGroupWeights()
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []groupPriorityJSON
+	seen := make(map[string]bool)
+	for _, g := range stats {
+		tier, assigned := g.Priority, g.Priority >= 0
+		if !assigned {
+			tier = defaultTier
+		}
+		groups = append(groups, groupPriorityJSON{
+			Module:    g.Name,
+			Tier:      tier,
+			Assigned:  assigned,
+			Weight:    g.Weight,
+			Total:     g.Total,
+			Completed: g.Completed,
+		})
+		seen[g.Name] = true
+	}
+	// Tiers assigned ahead of a module's first sync
+	for name, tier := range priorities {
+		if !seen[name] {
+			groups = append(groups, groupPriorityJSON{Module: name, Tier: tier, Assigned: true, Weight: weights[name]})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Tier != groups[j].Tier {
+			return groups[i].Tier < groups[j].Tier
+		}
+		if groups[i].Weight != groups[j].Weight {
+			return groups[i].Weight > groups[j].Weight
+		}
+		return groups[i].Module < groups[j].Module
+	})
+	return groups, nil
+}
+
+// reportGroupPriority prints the priority of module after a change
+func reportGroupPriority(out io.Writer, store *taskstore.Store, defaultTier int, module string) error {
+	groups, err := loadGroupPriorities(store, defaultTier)
+	if err != nil {
+		return err
+	}
+
+	group := groupPriorityJSON{Module: module, Tier: defaultTier}
+	for _, g := range groups {
+		if g.Module == module {
+			group = g
+		}
+	}
+
+	if prioritiesJSON {
+		return writeJSON(out, group)
+	}
+	if group.Assigned {
+		fmt.Fprintf(out, "%s: tier %d\n", module, group.Tier)
+	} else {
+		fmt.Fprintf(out, "%s: default tier %d\n", module, group.Tier)
+	}
+	if group.Total == 0 {
+		fmt.Fprintf(out, "Note: no tasks of %s are synced yet\n", module)
+	}
+	return nil
+}
+
+// writeGroupPriorities prints the groups as a table, the same columns as the
+// TUI's group priorities view
+func writeGroupPriorities(out io.Writer, groups []groupPriorityJSON) {
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "No groups")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tTIER\tWEIGHT\tDONE")
+	for _, g := range groups {
+		tier := strconv.Itoa(g.Tier)
+		if !g.Assigned {
+			tier += " (default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d/%d\n", g.Module, tier, g.Weight, g.Completed, g.Total)
+	}
+	w.Flush()
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
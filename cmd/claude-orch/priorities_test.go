@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+// seedPriorityGroups stores tasks of three modules, one of them done
+func seedPriorityGroups(t *testing.T) *taskstore.Store {
+	t.Helper()
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "auth", EpicNum: 1}, Title: "Login", Status: domain.StatusComplete},
+		{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "Invoices", Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "billing", EpicNum: 2}, Title: "Dunning", Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 1}, Title: "Parcels", Status: domain.StatusNotStarted},
+	}
+	for _, task := range tasks {
+		if err := store.UpsertTask(task); err != nil {
+			t.Fatalf("UpsertTask: %v", err)
+		}
+	}
+	return store
+}
+
+func TestGroupPriorities_SetUnsetList(t *testing.T) {
+	store := seedPriorityGroups(t)
+
+	if err := store.SetGroupPriority("billing", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetGroupPriority("reporting", 1); err != nil { // Not synced yet
+		t.Fatal(err)
+	}
+	if err := store.SetGroupWeight("zoning", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := loadGroupPriorities(store, 1)
+	if err != nil {
+		t.Fatalf("loadGroupPriorities: %v", err)
+	}
+	want := []groupPriorityJSON{
+		{Module: "zoning", Tier: 1, Weight: 3, Total: 1},
+		{Module: "auth", Tier: 1, Total: 1, Completed: 1},
+		{Module: "reporting", Tier: 1, Assigned: true},
+		{Module: "billing", Tier: 2, Assigned: true, Total: 2},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %+v\nwant %+v", groups, want)
+	}
+
+	var out bytes.Buffer
+	writeGroupPriorities(&out, groups)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || strings.Join(strings.Fields(lines[1]), " ") != "zoning 1 (default) 3 0/1" ||
+		strings.Join(strings.Fields(lines[4]), " ") != "billing 2 0 0/2" {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+
+	// Unsetting returns billing to the default tier
+	if err := store.RemoveGroupPriority("billing"); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := reportGroupPriority(&out, store, 1, "billing"); err != nil {
+		t.Fatalf("reportGroupPriority: %v", err)
+	}
+	if got := out.String(); got != "billing: default tier 1\n" {
+		t.Errorf("report = %q, want the default tier", got)
+	}
+}
+
+func TestGroupPriorities_JSON(t *testing.T) {
+	store := seedPriorityGroups(t)
+	prioritiesJSON = true
+	defer func() { prioritiesJSON = false }()
+
+	if err := store.SetGroupPriority("reporting", 4); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := reportGroupPriority(&out, store, 0, "reporting"); err != nil {
+		t.Fatalf("reportGroupPriority: %v", err)
+	}
+	var group groupPriorityJSON
+	if err := json.Unmarshal(out.Bytes(), &group); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, out.String())
+	}
+	if want := (groupPriorityJSON{Module: "reporting", Tier: 4, Assigned: true}); group != want {
+		t.Errorf("report = %+v, want %+v", group, want)
+	}
+
+	groups, _ := loadGroupPriorities(store, 0)
+	out.Reset()
+	if err := writeJSON(&out, groups); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []groupPriorityJSON
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || !reflect.DeepEqual(decoded, groups) {
+		t.Errorf("list JSON round trip = %+v (%v), want %+v", decoded, err, groups)
+	}
+}
+
+func TestGroupPriorities_SetRejectsInvalidTier(t *testing.T) {
+	for _, tier := range []string{"-1", "high", ""} {
+		if err := runPrioritiesSet(prioritiesSetCmd, []string{"billing", tier}); err == nil ||
+			!strings.Contains(err.Error(), "invalid tier") {
+			t.Errorf("set billing %q: err = %v, want an invalid tier error", tier, err)
+		}
+	}
+}
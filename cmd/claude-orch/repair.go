@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repairStatus bool
+	repairFix    bool
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Check the database for inconsistencies",
+	Long: `Checks the database for inconsistencies left behind when the orchestrator
+stopped in the middle of an update, and fixes them with --fix.
+
+--status compares each task's status with its latest agent run: a completed
+run makes the task complete, a running or stuck run makes a not started task
+in progress, and a cancelled run makes an in-progress task not started. A run
+still marked running for a complete task is marked completed. Fixed task
+statuses are recorded as repaired events.
+
+Without a check flag, all checks run.`,
+	Args: cobra.NoArgs,
+	RunE: runRepair,
+}
+
+func init() {
+	repairCmd.Flags().BoolVar(&repairStatus, "status", false, "Check task status against the latest agent run of each task")
+	repairCmd.Flags().BoolVar(&repairFix, "fix", false, "Fix the inconsistencies found")
+	rootCmd.AddCommand(repairCmd)
+}
+
+func runRepair(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if repairFix {
+		// A running TUI would overwrite the fixes with its own view
+		lock, err := taskstore.AcquireLock(cfg.General.DatabasePath, false)
+		var locked *taskstore.LockedError
+		if errors.As(err, &locked) {
+			return fmt.Errorf("the database is in use by the TUI (pid %d); quit it before fixing", locked.PID)
+		}
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	// --status is the only check so far, so it also runs without flags
+	return repairStatusMismatches(os.Stdout, store, repairFix)
+}
+
+// repairStatusMismatches reports the tasks whose status disagrees with their
+// latest agent run and, if fix is set, repairs them
+func repairStatusMismatches(out io.Writer, store *taskstore.Store, fix bool) error {
+	mismatches, err := store.CheckStatusConsistency()
+	if err != nil {
+		return fmt.Errorf("check task status: %w", err)
+	}
+
+	if len(mismatches) == 0 {
+		fmt.Fprintln(out, "All task statuses agree with their latest agent run.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Found %d task(s) whose status disagrees with their latest agent run:\n", len(mismatches))
+	var fixed, failed int
+	for _, m := range mismatches {
+		fmt.Fprintf(out, "  - %s\n", m)
+		if !fix {
+			fmt.Fprintf(out, "      fix: %s\n", m.Fix())
+			continue
+		}
+		if err := store.RepairStatusMismatch(m); err != nil {
+			fmt.Fprintf(out, "      not fixed: %v\n", err)
+			failed++
+		} else {
+			fmt.Fprintf(out, "      fixed: %s\n", m.Fix())
+			fixed++
+		}
+	}
+
+	if !fix {
+		fmt.Fprintln(out, "\nRun with --fix to repair them.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "\nFixed %d task(s)", fixed)
+	if failed > 0 {
+		fmt.Fprintf(out, ", %d not fixed", failed)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+func TestRepairStatusMismatches(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	store.UpsertTask(&domain.Task{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "Invoices", Status: domain.StatusInProgress})
+	store.SaveAgentRun(&taskstore.AgentRun{ID: "run-1", TaskID: "billing/E01", Status: "completed", StartedAt: time.Now()})
+
+	var out bytes.Buffer
+	if err := repairStatusMismatches(&out, store, false); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+	for _, want := range []string{
+		"Found 1 task(s)",
+		"  - billing/E01 is in_progress, but its latest agent run run-1 is completed\n      fix: set task to complete\n",
+		"Run with --fix",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if task, _ := store.GetTask("billing/E01"); task.Status != domain.StatusInProgress {
+		t.Errorf("check without --fix changed the task to %s", task.Status)
+	}
+
+	out.Reset()
+	if err := repairStatusMismatches(&out, store, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "fixed: set task to complete") || !strings.HasSuffix(got, "Fixed 1 task(s)\n") {
+		t.Errorf("fix report = %q", got)
+	}
+
+	out.Reset()
+	repairStatusMismatches(&out, store, false)
+	if got := out.String(); got != "All task statuses agree with their latest agent run.\n" {
+		t.Errorf("report after fix = %q", got)
+	}
+}
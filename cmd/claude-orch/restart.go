@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/config"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/spf13/cobra"
+)
+
+var restartPrompt string
+
+var restartCmd = &cobra.Command{
+	Use:   "restart TASK",
+	Short: "Restart a task's agent from scratch",
+	Long: `Discards the previous attempt of a task and starts its agent from scratch,
+like the restart action of the TUI: the worktree and session file of the
+task's latest agent run are removed, and a new agent starts in a fresh
+worktree with a new session.
+
+The agent runs in the foreground until it finishes; interrupting the command
+stops it. The TUI must not be running, since it manages the agents itself.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestart,
+}
+
+func init() {
+	restartCmd.Flags().StringVar(&restartPrompt, "prompt", "", "prompt for the new agent (default: built from the task)")
+	rootCmd.AddCommand(restartCmd)
+}
+
+func runRestart(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := domain.ParseTaskID(args[0])
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.New(cfg.General.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// A running TUI would not know about the new agent
+	lock, err := taskstore.AcquireLock(cfg.General.DatabasePath, false)
+	var locked *taskstore.LockedError
+	if errors.As(err, &locked) {
+		return fmt.Errorf("the database is in use by the TUI (pid %d); restart the agent from there", locked.PID)
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	task, err := store.GetTask(taskID.String())
+	if err != nil {
+		return fmt.Errorf("task %s: %w", taskID, err)
+	}
+	old, err := previousAgent(store, taskID)
+	if err != nil {
+		return err
+	}
+
+	executorType := cfg.General.Executor
+	if executorType == "" {
+		executorType = config.ExecutorClaudeCode
+	}
+	agentMgr := executor.NewAgentManager(cfg.General.MaxParallelAgents)
+	agentMgr.SetStore(&agentStoreAdapter{store: store})
+	agentMgr.SetExecutorType(executor.ExecutorType(executorType))
+	agentMgr.SetClaudeModel(cfg.General.ClaudeModel)
+	agentMgr.SetOpenCodeModel(cfg.General.OpenCodeModel)
+	agentMgr.SetGeminiModel(cfg.General.GeminiModel)
+	if err := configureAgents(cfg, agentMgr, executorType); err != nil {
+		return err
+	}
+	syncer, err := newSyncer(cfg, cfg.General.ProjectRoot+"/docs/plans")
+	if err != nil {
+		return err
+	}
+	agentMgr.SetSyncer(syncer)
+	if url := buildprotocol.DiscoverURL(); url != "" {
+		agentMgr.SetBuildPoolURL(url)
+	}
+	defer agentMgr.StopDBWriter() // Flush the new run's status before exiting
+
+	wtMgr, err := newWorktreeManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	prompt := restartPrompt
+	if prompt == "" {
+		prompt = executor.BuildPrompt(task, task.Description, "", nil, executor.ExecutorType(executorType))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	agentMgr.Add(old)
+	agent, err := agentMgr.Restart(ctx, taskID.String(), wtMgr, prompt)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restarted %s from scratch in %s\n", taskID, agent.WorktreePath)
+	fmt.Printf("Log: %s\n", agent.LogPath)
+
+	if err := agent.Wait(ctx); err != nil {
+		// The agent is killed with the context; wait for its final status
+		agent.Wait(context.Background())
+	}
+	if agent.GetStatus() == executor.AgentFailed {
+		return fmt.Errorf("agent for %s failed: %v", taskID, agent.GetError())
+	}
+	fmt.Printf("Agent for %s %s\n", taskID, agent.GetStatus())
+	return nil
+}
+
+// previousAgent returns the agent of a task's latest run, for Restart to
+// discard. A run still marked running is refused: it belongs to a TUI that
+// recovers it when started again.
+func previousAgent(store *taskstore.Store, taskID domain.TaskID) (*executor.Agent, error) {
+	run, err := store.GetLatestAgentRun(taskID.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s has no agent run to restart; start it instead", taskID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	status := executor.AgentStatus(run.Status)
+	if status.Active() {
+		return nil, fmt.Errorf("the latest agent run of %s is still %s; stop it in the TUI first", taskID, run.Status)
+	}
+	// No ID, so adding it to the manager does not save the run again
+	return &executor.Agent{
+		TaskID:       taskID,
+		WorktreePath: run.WorktreePath,
+		LogPath:      run.LogPath,
+		SessionID:    run.SessionID,
+		Status:       status,
+	}, nil
+}
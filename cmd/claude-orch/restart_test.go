@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+func TestPreviousAgent(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	taskID := domain.TaskID{Module: "billing", EpicNum: 1}
+	if _, err := previousAgent(store, taskID); err == nil || !strings.Contains(err.Error(), "no agent run") {
+		t.Errorf("previousAgent() error = %v, want a task without runs refused", err)
+	}
+
+	started := time.Now().Add(-time.Hour)
+	store.SaveAgentRun(&taskstore.AgentRun{ID: "run-1", TaskID: "billing/E01", Status: "completed", StartedAt: started.Add(-time.Hour)})
+	store.SaveAgentRun(&taskstore.AgentRun{
+		ID:           "run-2",
+		TaskID:       "billing/E01",
+		Status:       "failed",
+		StartedAt:    started,
+		WorktreePath: "/worktrees/billing-E01",
+		SessionID:    "session-2",
+	})
+
+	old, err := previousAgent(store, taskID)
+	if err != nil {
+		t.Fatalf("previousAgent() error = %v", err)
+	}
+	// Restart removes the worktree and session file of the latest run
+	if old.WorktreePath != "/worktrees/billing-E01" || old.SessionID != "session-2" || old.Status != executor.AgentFailed {
+		t.Errorf("previous agent = %s in %q (session %q), want the latest run", old.Status, old.WorktreePath, old.SessionID)
+	}
+	if old.ID != "" {
+		t.Errorf("ID = %q, want none so the run is not saved again", old.ID)
+	}
+
+	store.SaveAgentRun(&taskstore.AgentRun{ID: "run-3", TaskID: "billing/E01", Status: "running", StartedAt: time.Now()})
+	if _, err := previousAgent(store, taskID); err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Errorf("previousAgent() error = %v, want a running agent refused", err)
+	}
+}
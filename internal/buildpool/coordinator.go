@@ -3,10 +3,14 @@ package buildpool
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -21,7 +25,12 @@ type CoordinatorConfig struct {
 	GitDaemonPort     int
 	HeartbeatInterval time.Duration
 	HeartbeatTimeout  time.Duration
-	Debug             bool // Enable verbose logging for heartbeat diagnostics
+	Debug             bool          // Enable verbose logging for heartbeat diagnostics
+	AdminToken        string        // Bearer token for the /jobs admin endpoints (empty = disabled)
+	IdempotencyWindow time.Duration // How long completed keyed jobs are deduped (0 = DefaultIdempotencyWindow)
+	DiscoveryFile     string        // Where to publish the bound URL while serving (empty = none)
+	Events            *EventLog     // Sink for operational events as JSON lines (nil = disabled)
+	Metrics           bool          // Serve Prometheus metrics on GET /metrics
 }
 
 // Coordinator manages workers and dispatches jobs
@@ -31,8 +40,9 @@ type Coordinator struct {
 	dispatcher *Dispatcher
 	upgrader   websocket.Upgrader
 
-	server *http.Server
-	mu     sync.Mutex
+	server  *http.Server
+	stopped bool // Stop was called; Start must not serve anymore
+	mu      sync.Mutex
 
 	// Output accumulator for streaming output from workers
 	outputMu     sync.Mutex
@@ -42,19 +52,32 @@ type Coordinator struct {
 	retainedLogs [50]*completedLog
 	retainIndex  int
 	retainByID   map[string]*completedLog
+
+	// Dedupe of HTTP submissions carrying an idempotency key
+	idempotency *idempotencyCache
+
+	// Finished job counts and durations for /metrics
+	metrics *jobMetrics
 }
 
 // jobOutput holds separate stdout and stderr buffers
 type jobOutput struct {
 	stdout strings.Builder
 	stderr strings.Builder
+
+	// Highest numbered chunk received and how many were received, for
+	// numbered output (see AccumulateChunk)
+	lastSeq int
+	chunks  int
 }
 
 // completedLog holds logs for a completed job
 type completedLog struct {
-	jobID  string
-	stdout string
-	stderr string
+	jobID    string
+	stdout   string
+	stderr   string
+	job      *buildprotocol.JobMessage // Job metadata for replay (nil if not known)
+	exitCode int
 }
 
 // NewCoordinator creates a new coordinator
@@ -75,10 +98,14 @@ func NewCoordinator(config CoordinatorConfig, registry *Registry, dispatcher *Di
 		},
 		outputBuffer: make(map[string]*jobOutput),
 		retainByID:   make(map[string]*completedLog),
+		idempotency:  newIdempotencyCache(config.IdempotencyWindow),
+		metrics:      newJobMetrics(),
 	}
 
 	c.dispatcher.SetSendFunc(c.sendJobToWorker)
 	c.dispatcher.SetCancelFunc(c.sendCancelToWorker)
+	c.dispatcher.SetCompleteFunc(c.jobCompleted)
+	c.dispatcher.SetDispatchFunc(c.jobDispatched)
 
 	return c
 }
@@ -98,6 +125,7 @@ func (c *Coordinator) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := c.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("upgrade failed: %v", err)
+		c.config.Events.Emit(EventError, map[string]any{"message": "upgrade failed: " + err.Error()})
 		return
 	}
 
@@ -111,9 +139,13 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 		conn.Close()
 		if workerID != "" {
 			c.registry.Unregister(workerID)
-			c.dispatcher.RequeueWorkerJobs(workerID)
+			requeued := c.dispatcher.RequeueWorkerJobs(workerID)
 			c.dispatcher.TryDispatch()
 			log.Printf("worker %s disconnected", workerID)
+			c.config.Events.Emit(EventWorkerDisconnected, map[string]any{
+				"worker_id":     workerID,
+				"requeued_jobs": requeued,
+			})
 		}
 	}()
 
@@ -135,6 +167,7 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("read error for worker %s: %v", workerID, err)
+				c.emitError(workerID, "read error: "+err.Error())
 			} else {
 				log.Printf("connection closed for worker %s: %v", workerID, err)
 			}
@@ -147,6 +180,7 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 		var env buildprotocol.EnvelopeRaw
 		if err := json.Unmarshal(message, &env); err != nil {
 			log.Printf("invalid message: %v", err)
+			c.emitError(workerID, "invalid message: "+err.Error())
 			continue
 		}
 
@@ -155,6 +189,7 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 			var reg buildprotocol.RegisterMessage
 			if err := json.Unmarshal(env.Payload, &reg); err != nil {
 				log.Printf("invalid register: %v", err)
+				c.emitError(workerID, "invalid register: "+err.Error())
 				continue
 			}
 			workerID = reg.WorkerID
@@ -162,9 +197,15 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 				ID:      reg.WorkerID,
 				MaxJobs: reg.MaxJobs,
 				Slots:   reg.MaxJobs,
+				Tags:    reg.Tags,
 				Conn:    conn,
 			})
-			log.Printf("worker %s registered (max_jobs=%d)", reg.WorkerID, reg.MaxJobs)
+			log.Printf("worker %s registered (max_jobs=%d, tags=%v)", reg.WorkerID, reg.MaxJobs, reg.Tags)
+			c.config.Events.Emit(EventWorkerConnected, map[string]any{
+				"worker_id": reg.WorkerID,
+				"max_jobs":  reg.MaxJobs,
+				"tags":      reg.Tags,
+			})
 
 		case buildprotocol.TypeReady:
 			var ready buildprotocol.ReadyMessage
@@ -183,7 +224,11 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 				log.Printf("failed to unmarshal %s message: %v", env.Type, err)
 				continue
 			}
-			c.AccumulateOutput(output.JobID, output.Stream, output.Data)
+			if output.Seq > 0 {
+				c.AccumulateChunk(output)
+			} else {
+				c.AccumulateOutput(output.JobID, output.Stream, output.Data)
+			}
 
 		case buildprotocol.TypeComplete:
 			var complete buildprotocol.CompleteMessage
@@ -191,6 +236,11 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 				log.Printf("failed to unmarshal %s message: %v", env.Type, err)
 				continue
 			}
+			if lost := c.LostChunks(complete.JobID, complete.OutputChunks); lost > 0 {
+				log.Printf("job %s: %d of %d output chunks lost", complete.JobID, lost, complete.OutputChunks)
+				c.AccumulateOutput(complete.JobID, "stderr",
+					fmt.Sprintf("[build pool: %d of %d output chunks lost, output is incomplete]\n", lost, complete.OutputChunks))
+			}
 			output := c.GetAndClearOutput(complete.JobID)
 			c.dispatcher.Complete(complete.JobID, &buildprotocol.JobResult{
 				JobID:        complete.JobID,
@@ -222,10 +272,45 @@ func (c *Coordinator) handleWorkerConnection(conn *websocket.Conn) {
 
 func (c *Coordinator) sendJobToWorker(w *ConnectedWorker, job *buildprotocol.JobMessage) error {
 	data, err := buildprotocol.MarshalEnvelope(buildprotocol.TypeJob, job)
+	if err == nil {
+		err = w.WriteMessage(websocket.TextMessage, data)
+	}
 	if err != nil {
-		return err
+		c.emitError(w.ID, fmt.Sprintf("sending job %s: %v", job.JobID, err))
 	}
-	return w.WriteMessage(websocket.TextMessage, data)
+	return err
+}
+
+// jobDispatched records a job started on a worker in the event log
+func (c *Coordinator) jobDispatched(job *buildprotocol.JobMessage, workerID string) {
+	c.config.Events.Emit(EventJobDispatched, map[string]any{
+		"job_id":    job.JobID,
+		"worker_id": workerID,
+		"repo":      job.Repo,
+		"commit":    job.Commit,
+		"command":   job.Command,
+	})
+}
+
+// jobCompleted retains the log of a finished job and records it in the
+// event log
+func (c *Coordinator) jobCompleted(job *buildprotocol.JobMessage, result *buildprotocol.JobResult) {
+	c.RetainJob(job, result)
+	c.metrics.observe(result)
+	c.config.Events.Emit(EventJobCompleted, map[string]any{
+		"job_id":        job.JobID,
+		"exit_code":     result.ExitCode,
+		"duration_secs": result.DurationSecs,
+	})
+}
+
+// emitError records an error in the event log, with the worker if known
+func (c *Coordinator) emitError(workerID, message string) {
+	fields := map[string]any{"message": message}
+	if workerID != "" {
+		fields["worker_id"] = workerID
+	}
+	c.config.Events.Emit(EventError, fields)
 }
 
 func (c *Coordinator) sendCancelToWorker(workerID, jobID string) error {
@@ -249,14 +334,50 @@ func (c *Coordinator) Start(ctx context.Context) error {
 	mux.HandleFunc("/ws", c.HandleWebSocket)
 	mux.HandleFunc("/status", c.HandleStatus)
 	mux.HandleFunc("/job", c.HandleJobSubmit)
+	mux.HandleFunc("/job/", c.HandleCancelJob) // Agents cancel their own jobs; others need /jobs/{id}/kill
 	mux.HandleFunc("/logs/", c.HandleGetLogs)
+	mux.HandleFunc("/jobs", c.requireAdmin(c.HandleListJobs))
+	mux.HandleFunc("/jobs/", c.requireAdmin(c.HandleKillJob))
+	if c.config.Metrics {
+		mux.HandleFunc("/metrics", c.HandleMetrics)
+	}
 
 	addr := fmt.Sprintf(":%d", c.config.WebSocketPort)
-	c.server = &http.Server{
+	server := &http.Server{
 		Addr:    addr,
 		Handler: mux,
 	}
 
+	// Stop may race with Start when the coordinator is toggled at runtime
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	c.server = server
+	c.mu.Unlock()
+
+	// Bind first so the actual port is known when WebSocketPort is 0
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	addr = ln.Addr().String()
+
+	if c.config.DiscoveryFile != "" {
+		port := ln.Addr().(*net.TCPAddr).Port
+		err := buildprotocol.WriteDiscovery(c.config.DiscoveryFile, buildprotocol.Discovery{
+			URL:       fmt.Sprintf("http://localhost:%d", port),
+			PID:       os.Getpid(),
+			StartedAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("writing discovery file: %v", err)
+		} else {
+			defer os.Remove(c.config.DiscoveryFile)
+		}
+	}
+
 	go c.heartbeatLoop(ctx)
 
 	if c.config.Debug {
@@ -265,7 +386,7 @@ func (c *Coordinator) Start(ctx context.Context) error {
 	} else {
 		log.Printf("coordinator listening on %s", addr)
 	}
-	return c.server.ListenAndServe()
+	return server.Serve(ln)
 }
 
 // HandleStatus returns the current status of workers and jobs
@@ -273,18 +394,33 @@ func (c *Coordinator) HandleStatus(w http.ResponseWriter, r *http.Request) {
 	workers := []map[string]interface{}{}
 	for _, worker := range c.registry.All() {
 		maxJobs, slots, connectedAt := worker.GetStatus()
+		tags := worker.Tags
+		if tags == nil {
+			tags = []string{}
+		}
 		workers = append(workers, map[string]interface{}{
 			"id":              worker.ID,
 			"max_jobs":        maxJobs,
 			"active_jobs":     maxJobs - slots,
 			"connected_since": connectedAt.Format(time.RFC3339),
+			"tags":            tags,
 		})
 	}
 
+	// Queued and running jobs per submitting agent's task
+	jobsByTask := map[string]int{}
+	for _, job := range c.dispatcher.Jobs() {
+		if job.TaskID != "" {
+			jobsByTask[job.TaskID]++
+		}
+	}
+
 	status := map[string]interface{}{
 		"workers":               workers,
 		"queued_jobs":           c.dispatcher.QueuedCount(),
 		"local_fallback_active": c.dispatcher.LocalFallbackActive(),
+		"jobs_by_task":          jobsByTask,
+		"result_cache":          c.dispatcher.CacheStats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -293,11 +429,27 @@ func (c *Coordinator) HandleStatus(w http.ResponseWriter, r *http.Request) {
 
 // JobRequest represents an HTTP job submission request
 type JobRequest struct {
-	Command   string `json:"command"`
-	Repo      string `json:"repo"`
-	Commit    string `json:"commit"`
-	Timeout   int    `json:"timeout,omitempty"`
-	Verbosity string `json:"verbosity,omitempty"`
+	Command   string            `json:"command"`
+	Repo      string            `json:"repo"`
+	Commit    string            `json:"commit"`
+	Env       map[string]string `json:"env,omitempty"`
+	Timeout   int               `json:"timeout,omitempty"`
+	Verbosity string            `json:"verbosity,omitempty"`
+	TaskID    string            `json:"task_id,omitempty"`  // Task of the submitting agent, for correlation
+	UseNix    *bool             `json:"use_nix,omitempty"`  // Run in nix develop (nil = worker's default)
+	Priority  int               `json:"priority,omitempty"` // Higher priority jobs are dispatched first (default 0)
+
+	// RequiredTags restricts the job to workers having all of these tags
+	RequiredTags []string `json:"required_tags,omitempty"`
+
+	// Cacheable lets the result of an earlier successful job with the same
+	// repo, commit, command and environment answer the request
+	Cacheable bool `json:"cacheable,omitempty"`
+
+	// IdempotencyKey dedupes retried submissions: while a job with the same
+	// key is in flight or completed within the idempotency window, its
+	// result is returned instead of running the command again
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // JobResponse represents an HTTP job submission response
@@ -306,6 +458,7 @@ type JobResponse struct {
 	ExitCode int    `json:"exit_code"`
 	Output   string `json:"output"`
 	Error    string `json:"error,omitempty"`
+	Cached   bool   `json:"cached,omitempty"` // Result taken from the result cache
 }
 
 // HandleJobSubmit handles HTTP job submissions (POST /job)
@@ -326,48 +479,188 @@ func (c *Coordinator) HandleJobSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate job ID
-	jobID := fmt.Sprintf("http-%d", time.Now().UnixNano())
-
-	// Create job
-	job := &buildprotocol.JobMessage{
-		JobID:   jobID,
-		Repo:    req.Repo,
-		Commit:  req.Commit,
-		Command: req.Command,
-		Timeout: req.Timeout,
+	if err := buildprotocol.ValidateJobEnv(req.Env); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Submit to dispatcher with verbosity
-	resultCh := c.dispatcher.SubmitWithVerbosity(job, req.Verbosity)
-	c.dispatcher.TryDispatch()
+	// Generate job ID, embedding the submitting agent's task if known
+	jobID := NewJobID(JobSourceHTTP, req.TaskID)
 
-	// Wait for result (with timeout)
 	timeout := time.Duration(req.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 5 * time.Minute
 	}
 
-	select {
-	case result := <-resultCh:
-		resp := JobResponse{
-			JobID:    result.JobID,
-			ExitCode: result.ExitCode,
-			Output:   result.Output,
+	if req.IdempotencyKey == "" {
+		resultCh := c.submitHTTPJob(jobID, req)
+
+		select {
+		case result := <-resultCh:
+			writeJobResponse(w, jobResponse(result))
+		case <-time.After(timeout):
+			http.Error(w, "job timed out", http.StatusGatewayTimeout)
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	entry, existing := c.idempotency.acquire(req.IdempotencyKey, jobID)
+	if existing {
+		log.Printf("job submission with idempotency key %s reuses job %s", req.IdempotencyKey, entry.jobID)
+	} else {
+		// Collect the result independently of this request so a client that
+		// times out and retries still gets it
+		resultCh := c.submitHTTPJob(jobID, req)
+		go func() {
+			resp := jobResponse(<-resultCh)
+			if resp.ExitCode == cancelledExitCode {
+				c.idempotency.forget(req.IdempotencyKey, entry)
+			}
+			c.idempotency.complete(req.IdempotencyKey, entry, resp)
+		}()
+	}
+
+	select {
+	case <-entry.done:
+		writeJobResponse(w, entry.resp)
 	case <-time.After(timeout):
 		http.Error(w, "job timed out", http.StatusGatewayTimeout)
 	}
 }
 
+// cancelledExitCode is the exit code the dispatcher reports for cancelled jobs
+const cancelledExitCode = -2
+
+// submitHTTPJob queues an HTTP job request under jobID and returns its result channel
+func (c *Coordinator) submitHTTPJob(jobID string, req JobRequest) chan *buildprotocol.JobResult {
+	job := &buildprotocol.JobMessage{
+		JobID:        jobID,
+		Repo:         req.Repo,
+		Commit:       req.Commit,
+		Command:      req.Command,
+		Env:          req.Env,
+		Timeout:      req.Timeout,
+		TaskID:       req.TaskID,
+		UseNix:       req.UseNix,
+		Priority:     req.Priority,
+		RequiredTags: req.RequiredTags,
+		Cacheable:    req.Cacheable,
+	}
+
+	// Submit to dispatcher with verbosity
+	resultCh := c.dispatcher.SubmitWithVerbosity(job, req.Verbosity)
+	c.dispatcher.TryDispatch()
+	return resultCh
+}
+
+// jobResponse converts a job result into the HTTP response
+func jobResponse(result *buildprotocol.JobResult) JobResponse {
+	if result == nil {
+		return JobResponse{ExitCode: cancelledExitCode, Error: "job ended without a result"}
+	}
+	return JobResponse{
+		JobID:    result.JobID,
+		ExitCode: result.ExitCode,
+		Output:   result.Output,
+		Cached:   result.Cached,
+	}
+}
+
+func writeJobResponse(w http.ResponseWriter, resp JobResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Final job states reported by CancelJob
+const (
+	JobStatusCancelled = "cancelled"
+	JobStatusCompleted = "completed" // Finished before it could be cancelled
+)
+
+// CancelResponse represents an HTTP job cancellation response
+type CancelResponse struct {
+	JobID    string `json:"job_id"`
+	Status   string `json:"status,omitempty"` // JobStatusCancelled or JobStatusCompleted
+	ExitCode int    `json:"exit_code"`        // cancelledExitCode once cancelled
+	Error    string `json:"error,omitempty"`
+}
+
+// CancelJob cancels a queued or running job and reports its final state. A
+// job that already finished is left alone and reported as completed with its
+// exit code, as long as it is retained. Returns ErrJobNotFound for unknown
+// jobs, or the error telling the worker, in which case the job is cancelled
+// on the coordinator but may still run on the worker.
+func (c *Coordinator) CancelJob(jobID string) (CancelResponse, error) {
+	resp := CancelResponse{JobID: jobID, Status: JobStatusCancelled, ExitCode: cancelledExitCode}
+	err := c.dispatcher.Cancel(jobID)
+	if err == nil {
+		log.Printf("job %s cancelled", jobID)
+		return resp, nil
+	}
+	if !errors.Is(err, ErrJobNotFound) {
+		c.emitError("", fmt.Sprintf("cancelling job %s: %v", jobID, err))
+		resp.Error = err.Error()
+		return resp, err
+	}
+
+	_, exitCode, ok := c.GetRetainedJob(jobID)
+	if !ok {
+		return CancelResponse{JobID: jobID, Error: err.Error()}, err
+	}
+	resp.ExitCode = exitCode
+	if exitCode != cancelledExitCode {
+		resp.Status = JobStatusCompleted
+	}
+	return resp, nil
+}
+
+// HandleCancelJob cancels a queued or running job for the task that submitted
+// it (DELETE /job/{job_id}?task_id={task_id}). Jobs of other tasks, or of no
+// task, are refused with 403 Forbidden; POST /jobs/{job_id}/kill cancels those.
+// Responds 409 Conflict if the job completed before it could be cancelled.
+func (c *Coordinator) HandleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract job_id from path: /job/{job_id}
+	jobID := strings.TrimPrefix(r.URL.Path, "/job/")
+	if jobID == "" || strings.Contains(jobID, "/") {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if owner, ok := c.dispatcher.JobTaskID(jobID); ok && (owner == "" || owner != r.URL.Query().Get("task_id")) {
+		http.Error(w, "job was not submitted by this task", http.StatusForbidden)
+		return
+	}
+
+	resp, err := c.CancelJob(jobID)
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		status = http.StatusNotFound
+	case err != nil:
+		// The job was removed from the dispatcher, but the worker could not be told
+		status = http.StatusBadGateway
+	case resp.Status == JobStatusCompleted:
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
 // LogsResponse represents an HTTP log retrieval response
 type LogsResponse struct {
-	JobID  string `json:"job_id"`
-	Stdout string `json:"stdout"`
-	Stderr string `json:"stderr"`
-	Error  string `json:"error,omitempty"`
+	JobID    string                    `json:"job_id"`
+	Stdout   string                    `json:"stdout"`
+	Stderr   string                    `json:"stderr"`
+	Job      *buildprotocol.JobMessage `json:"job,omitempty"`       // Original job, for replay
+	ExitCode int                       `json:"exit_code,omitempty"` // Exit code of the original run
+	Error    string                    `json:"error,omitempty"`
 }
 
 // HandleGetLogs handles HTTP log retrieval (GET /logs/{job_id})
@@ -399,6 +692,10 @@ func (c *Coordinator) HandleGetLogs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := LogsResponse{JobID: jobID}
+	if job, exitCode, ok := c.GetRetainedJob(jobID); ok {
+		resp.Job = job
+		resp.ExitCode = exitCode
+	}
 	switch stream {
 	case "stdout":
 		resp.Stdout = stdout
@@ -413,8 +710,88 @@ func (c *Coordinator) HandleGetLogs(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// requireAdmin rejects requests that do not carry the configured admin token
+func (c *Coordinator) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c.config.AdminToken == "" {
+			http.Error(w, "admin endpoints disabled: no admin token configured", http.StatusForbidden)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(c.config.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// JobsResponse represents an HTTP job listing response
+type JobsResponse struct {
+	Jobs []JobInfo `json:"jobs"`
+}
+
+// HandleListJobs lists queued and in-flight jobs (GET /jobs)
+func (c *Coordinator) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JobsResponse{Jobs: c.dispatcher.Jobs()})
+}
+
+// KillResponse represents an HTTP job kill response
+type KillResponse struct {
+	JobID  string `json:"job_id"`
+	Killed bool   `json:"killed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HandleKillJob cancels a queued or in-flight job (POST /jobs/{job_id}/kill)
+// like CancelJob. Responds 409 Conflict if the job completed before it could
+// be killed.
+func (c *Coordinator) HandleKillJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract job_id from path: /jobs/{job_id}/kill
+	jobID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/kill")
+	if !ok || jobID == "" || strings.Contains(jobID, "/") {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	cancelled, err := c.CancelJob(jobID)
+	resp := KillResponse{JobID: jobID, Killed: cancelled.Status == JobStatusCancelled, Error: cancelled.Error}
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		resp.Killed = false
+		status = http.StatusNotFound
+	case err != nil:
+		// The job was removed from the dispatcher, but the worker could not be told
+		status = http.StatusBadGateway
+	case cancelled.Status == JobStatusCompleted:
+		status = http.StatusConflict
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
 // Stop stops the coordinator server
 func (c *Coordinator) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
 	if c.server != nil {
 		return c.server.Close()
 	}
@@ -472,6 +849,45 @@ func (c *Coordinator) AccumulateOutput(jobID, stream, data string) {
 	}
 }
 
+// AccumulateChunk appends a numbered output chunk for a job. Chunks already
+// received are ignored, so resent chunks are not duplicated. A first chunk
+// for a job that already has output means the job is running again (e.g.
+// requeued after its worker disconnected), so the earlier partial output is
+// dropped rather than mixed into the new run's.
+func (c *Coordinator) AccumulateChunk(msg buildprotocol.OutputMessage) {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	buf := c.outputBuffer[msg.JobID]
+	if buf == nil || (msg.Seq == 1 && buf.lastSeq > 0) {
+		buf = &jobOutput{}
+		c.outputBuffer[msg.JobID] = buf
+	}
+	if msg.Seq <= buf.lastSeq {
+		return
+	}
+	buf.lastSeq = msg.Seq
+	buf.chunks++
+	if msg.Stream == "stderr" {
+		buf.stderr.WriteString(msg.Data)
+	} else {
+		buf.stdout.WriteString(msg.Data)
+	}
+}
+
+// LostChunks returns how many of the sent numbered output chunks of a job
+// never arrived
+func (c *Coordinator) LostChunks(jobID string, sent int) int {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	received := 0
+	if buf, ok := c.outputBuffer[jobID]; ok {
+		received = buf.chunks
+	}
+	return max(sent-received, 0)
+}
+
 // GetAndClearOutput returns accumulated output and clears the buffer (backwards compat)
 func (c *Coordinator) GetAndClearOutput(jobID string) string {
 	c.outputMu.Lock()
@@ -508,23 +924,67 @@ func (c *Coordinator) RetainLogs(jobID string) {
 		return
 	}
 
+	c.retainLocked(&completedLog{
+		jobID:  jobID,
+		stdout: buf.stdout.String(),
+		stderr: buf.stderr.String(),
+	})
+
+	// Clear from active buffer
+	delete(c.outputBuffer, jobID)
+}
+
+// RetainJob stores a finished job's metadata and output in the retention ring buffer,
+// so its logs can be retrieved and the job replayed later
+func (c *Coordinator) RetainJob(job *buildprotocol.JobMessage, result *buildprotocol.JobResult) {
+	if job == nil || result == nil {
+		return
+	}
+
+	stdout, stderr := result.Stdout, result.Stderr
+	if stdout == "" && stderr == "" {
+		// Results from remote workers only carry the combined output
+		stdout = result.Output
+	}
+
+	jobCopy := *job
+
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+	c.retainLocked(&completedLog{
+		jobID:    job.JobID,
+		stdout:   stdout,
+		stderr:   stderr,
+		job:      &jobCopy,
+		exitCode: result.ExitCode,
+	})
+}
+
+// retainLocked stores an entry in the retention ring buffer, evicting the oldest one.
+// Must be called with outputMu held.
+func (c *Coordinator) retainLocked(entry *completedLog) {
 	// Evict old entry at this index if present
 	if old := c.retainedLogs[c.retainIndex]; old != nil {
 		delete(c.retainByID, old.jobID)
 	}
 
-	// Store new entry
-	entry := &completedLog{
-		jobID:  jobID,
-		stdout: buf.stdout.String(),
-		stderr: buf.stderr.String(),
-	}
 	c.retainedLogs[c.retainIndex] = entry
-	c.retainByID[jobID] = entry
+	c.retainByID[entry.jobID] = entry
 	c.retainIndex = (c.retainIndex + 1) % 50
+}
 
-	// Clear from active buffer
-	delete(c.outputBuffer, jobID)
+// GetRetainedJob retrieves the metadata and exit code of a retained job.
+// Returns false if the job was not retained or its metadata is unknown.
+func (c *Coordinator) GetRetainedJob(jobID string) (*buildprotocol.JobMessage, int, bool) {
+	c.outputMu.Lock()
+	defer c.outputMu.Unlock()
+
+	entry, ok := c.retainByID[jobID]
+	if !ok || entry.job == nil {
+		return nil, 0, false
+	}
+	jobCopy := *entry.job
+	return &jobCopy, entry.exitCode, true
 }
 
 // GetRetainedLogs retrieves logs from retention buffer
@@ -562,17 +1022,12 @@ func (c *Coordinator) CompleteJob(jobID string, exitCode int, durationMs int64,
 
 	// Always retain full logs before filtering
 	c.outputMu.Lock()
-	if old := c.retainedLogs[c.retainIndex]; old != nil {
-		delete(c.retainByID, old.jobID)
-	}
-	entry := &completedLog{
-		jobID:  jobID,
-		stdout: stdout,
-		stderr: stderr,
-	}
-	c.retainedLogs[c.retainIndex] = entry
-	c.retainByID[jobID] = entry
-	c.retainIndex = (c.retainIndex + 1) % 50
+	c.retainLocked(&completedLog{
+		jobID:    jobID,
+		stdout:   stdout,
+		stderr:   stderr,
+		exitCode: exitCode,
+	})
 	c.outputMu.Unlock()
 
 	// Apply verbosity filtering
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -353,6 +356,59 @@ func TestCoordinatorStartStop(t *testing.T) {
 	}
 }
 
+func TestCoordinator_WritesDiscoveryFile(t *testing.T) {
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, nil)
+	discoveryFile := filepath.Join(t.TempDir(), "build-pool.json")
+	coord := NewCoordinator(CoordinatorConfig{
+		WebSocketPort:     0, // Let OS pick available port
+		HeartbeatInterval: time.Second,
+		DiscoveryFile:     discoveryFile,
+	}, registry, dispatcher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- coord.Start(ctx)
+	}()
+
+	// The file appears once the coordinator is listening
+	var discovery *buildprotocol.Discovery
+	deadline := time.Now().Add(2 * time.Second)
+	for discovery == nil && time.Now().Before(deadline) {
+		discovery, _ = buildprotocol.ReadDiscovery(discoveryFile)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if discovery == nil {
+		t.Fatal("discovery file was not written")
+	}
+	if strings.HasSuffix(discovery.URL, ":0") || discovery.PID != os.Getpid() {
+		t.Errorf("discovery = %+v, want the bound port and this process", discovery)
+	}
+
+	// The published URL reaches the coordinator
+	resp, err := http.Get(discovery.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET %s/status: %v", discovery.URL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	coord.Stop()
+	select {
+	case <-errCh:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Stop was called")
+	}
+	if _, err := os.Stat(discoveryFile); !os.IsNotExist(err) {
+		t.Errorf("discovery file should be removed on stop, stat err = %v", err)
+	}
+}
+
 func TestCoordinator_OutputAccumulation(t *testing.T) {
 	registry := NewRegistry()
 	dispatcher := NewDispatcher(registry, nil)
@@ -377,6 +433,88 @@ func TestCoordinator_OutputAccumulation(t *testing.T) {
 	}
 }
 
+func TestCoordinator_ChunkedOutputReassembles(t *testing.T) {
+	coord := newTestCoordinator(CoordinatorConfig{})
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	send := func(msgType string, payload interface{}) {
+		t.Helper()
+		data, err := buildprotocol.MarshalEnvelope(msgType, payload)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	send(buildprotocol.TypeRegister, buildprotocol.RegisterMessage{WorkerID: "chunk-test", MaxJobs: 1})
+	time.Sleep(50 * time.Millisecond)
+
+	resultCh := coord.dispatcher.Submit(&buildprotocol.JobMessage{JobID: "big-job", Command: "cargo test"})
+	coord.dispatcher.TryDispatch()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var env buildprotocol.EnvelopeRaw
+	if err := conn.ReadJSON(&env); err != nil || env.Type != buildprotocol.TypeJob {
+		t.Fatalf("worker got %q (err %v), want the job", env.Type, err)
+	}
+
+	// About 2 MB of output, including a single line far larger than a chunk
+	stdout := strings.Repeat("test result: ok. ünïcode line\n", 50000) + strings.Repeat("x", 300*1024) + "\n"
+	stderr := strings.Repeat("warning: unused variable\n", 1000)
+	seq := 0
+	for _, stream := range []struct{ name, data string }{{"stdout", stdout}, {"stderr", stderr}} {
+		for _, chunk := range buildprotocol.SplitOutput(stream.data, buildprotocol.MaxOutputChunk) {
+			seq++
+			msg := buildprotocol.OutputMessage{JobID: "big-job", Stream: stream.name, Data: chunk, Seq: seq}
+			send(buildprotocol.TypeOutput, msg)
+			if seq == 3 {
+				send(buildprotocol.TypeOutput, msg) // A resent chunk is not duplicated
+			}
+		}
+	}
+	if seq < 50 {
+		t.Fatalf("output went out in %d chunks, want it split into many", seq)
+	}
+	send(buildprotocol.TypeComplete, buildprotocol.CompleteMessage{JobID: "big-job", OutputChunks: seq})
+
+	select {
+	case result := <-resultCh:
+		if result.Output != stdout+stderr {
+			t.Errorf("reassembled output is %d bytes, want the %d bytes sent", len(result.Output), len(stdout+stderr))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not complete")
+	}
+}
+
+func TestCoordinator_AccumulateChunk(t *testing.T) {
+	coord := newTestCoordinator(CoordinatorConfig{})
+	chunk := func(seq int, data string) {
+		coord.AccumulateChunk(buildprotocol.OutputMessage{JobID: "job", Stream: "stdout", Data: data, Seq: seq})
+	}
+
+	// A rerun of the job starts numbering again and replaces the partial output
+	chunk(1, "first run ")
+	chunk(2, "interrupted")
+	chunk(1, "second ")
+	chunk(3, "run")
+	if lost := coord.LostChunks("job", 3); lost != 1 {
+		t.Errorf("LostChunks() = %d, want the missing chunk 2", lost)
+	}
+	if got := coord.GetAndClearOutput("job"); got != "second run" {
+		t.Errorf("output = %q, want only the second run", got)
+	}
+}
+
 func TestCoordinator_SeparateStreamAccumulation(t *testing.T) {
 	registry := NewRegistry()
 	dispatcher := NewDispatcher(registry, nil)
@@ -623,7 +761,7 @@ func TestCoordinator_HTTPJobVerbosity(t *testing.T) {
 	const knownStdout = "STDOUT_MARKER_12345"
 	const knownStderr = "STDERR_MARKER_67890"
 
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: 0,
@@ -698,6 +836,196 @@ func TestCoordinator_HTTPJobVerbosity(t *testing.T) {
 	}
 }
 
+func TestCoordinator_HTTPJobEnv(t *testing.T) {
+	registry := NewRegistry()
+
+	var gotEnv map[string]string
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		gotEnv = job.Env
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleJobSubmit))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json",
+		strings.NewReader(`{"command":"cargo build","env":{"RUST_BACKTRACE":"1"}}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status OK, got %d", resp.StatusCode)
+	}
+	if gotEnv["RUST_BACKTRACE"] != "1" {
+		t.Errorf("job env = %v, want RUST_BACKTRACE=1", gotEnv)
+	}
+
+	// Variables outside the allow-list are rejected before dispatch
+	resp, err = http.Post(server.URL, "application/json",
+		strings.NewReader(`{"command":"cargo build","env":{"LD_PRELOAD":"/tmp/evil.so"}}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for disallowed env, got %d", resp.StatusCode)
+	}
+}
+
+func TestCoordinator_HTTPJobPriority(t *testing.T) {
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, nil)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	var sent []string
+	dispatcher.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error {
+		sent = append(sent, job.Command)
+		go dispatcher.Complete(job.JobID, &buildprotocol.JobResult{JobID: job.JobID})
+		return nil
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleJobSubmit))
+	defer server.Close()
+
+	// Both jobs queue while no worker is connected
+	var wg sync.WaitGroup
+	post := func(body string) {
+		defer wg.Done()
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Errorf("POST: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}
+	wg.Add(1)
+	go post(`{"command":"cargo build --release","timeout":5}`)
+	waitFor(t, func() bool { return dispatcher.QueueLength() == 1 })
+	wg.Add(1)
+	go post(`{"command":"cargo test","timeout":5,"priority":10}`)
+	waitFor(t, func() bool { return dispatcher.QueueLength() == 2 })
+
+	worker := &ConnectedWorker{ID: "worker-1", MaxJobs: 1, Slots: 1}
+	registry.Register(worker)
+	dispatcher.TryDispatch()
+	worker.UpdateSlots(1)
+	dispatcher.TryDispatch()
+	wg.Wait()
+
+	want := []string{"cargo test", "cargo build --release"}
+	if len(sent) != 2 || sent[0] != want[0] || sent[1] != want[1] {
+		t.Errorf("dispatch order = %q, want %q", sent, want)
+	}
+}
+
+func TestCoordinator_StatusReportsWorkerTags(t *testing.T) {
+	coord := newTestCoordinator(CoordinatorConfig{HeartbeatTimeout: 5 * time.Second})
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	registerMsg := `{"type":"register","payload":{"worker_id":"gpu-box","max_jobs":2,"tags":["gpu","highmem"]}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(registerMsg)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	waitFor(t, func() bool { return coord.Registry().Get("gpu-box") != nil })
+
+	rec := httptest.NewRecorder()
+	coord.HandleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		Workers []struct {
+			ID   string   `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"workers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if len(status.Workers) != 1 || strings.Join(status.Workers[0].Tags, ",") != "gpu,highmem" {
+		t.Errorf("workers = %+v, want gpu-box with tags gpu, highmem", status.Workers)
+	}
+}
+
+func TestCoordinator_HTTPJobCorrelatesTask(t *testing.T) {
+	registry := NewRegistry()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		close(started)
+		<-release
+		return &buildprotocol.JobResult{JobID: job.JobID, Stdout: "ok\n"}
+	}
+
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/job", coord.HandleJobSubmit)
+	mux.HandleFunc("/status", coord.HandleStatus)
+	mux.HandleFunc("/logs/", coord.HandleGetLogs)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	respCh := make(chan JobResponse, 1)
+	go func() {
+		resp, err := http.Post(server.URL+"/job", "application/json",
+			strings.NewReader(`{"command":"cargo build","task_id":"billing/E02"}`))
+		if err != nil {
+			t.Errorf("POST: %v", err)
+			respCh <- JobResponse{}
+			return
+		}
+		defer resp.Body.Close()
+		var jobResp JobResponse
+		json.NewDecoder(resp.Body).Decode(&jobResp)
+		respCh <- jobResp
+	}()
+
+	// While the job runs, /status counts it for the submitting task
+	<-started
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	var status struct {
+		JobsByTask map[string]int `json:"jobs_by_task"`
+	}
+	json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if status.JobsByTask["billing/E02"] != 1 {
+		t.Errorf("jobs_by_task = %v, want billing/E02: 1", status.JobsByTask)
+	}
+
+	close(release)
+	jobResp := <-respCh
+	if !strings.HasPrefix(jobResp.JobID, "http-billing.E02-") {
+		t.Errorf("JobID = %q, want prefix http-billing.E02-", jobResp.JobID)
+	}
+
+	// The retained job metadata keeps the task for later correlation
+	retained, err := FetchRetainedJob(context.Background(), server.URL, jobResp.JobID)
+	if err != nil {
+		t.Fatalf("FetchRetainedJob: %v", err)
+	}
+	if retained.TaskID != "billing/E02" {
+		t.Errorf("retained TaskID = %q, want billing/E02", retained.TaskID)
+	}
+}
+
 func TestCoordinator_VerbosityFiltering(t *testing.T) {
 	registry := NewRegistry()
 	dispatcher := NewDispatcher(registry, nil)
@@ -834,3 +1162,339 @@ func TestCoordinator_HandleGetLogs(t *testing.T) {
 		}
 	})
 }
+
+// newAdminTestCoordinator creates a coordinator with one single-slot worker,
+// one dispatched job and one queued job
+func newAdminTestCoordinator(t *testing.T) (*Coordinator, *[]string) {
+	t.Helper()
+
+	registry := NewRegistry()
+	registry.Register(&ConnectedWorker{ID: "worker-1", MaxJobs: 1, Slots: 1})
+
+	dispatcher := NewDispatcher(registry, nil)
+	coord := NewCoordinator(CoordinatorConfig{AdminToken: "s3cret"}, registry, dispatcher)
+
+	var cancelled []string
+	dispatcher.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error { return nil })
+	dispatcher.SetCancelFunc(func(workerID, jobID string) error {
+		cancelled = append(cancelled, workerID+"/"+jobID)
+		return nil
+	})
+
+	dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-1", Command: "cargo build", TaskID: "billing/E02"})
+	dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-2", Command: "cargo test", TaskID: "billing/E02"})
+	dispatcher.TryDispatch()
+
+	return coord, &cancelled
+}
+
+func TestCoordinator_AdminAuth(t *testing.T) {
+	coord, _ := newAdminTestCoordinator(t)
+	server := httptest.NewServer(coord.requireAdmin(coord.HandleListJobs))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "Bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	// Without a configured token the endpoints stay disabled
+	disabled := newTestCoordinator(CoordinatorConfig{})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	disabled.requireAdmin(disabled.HandleListJobs)(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status without admin token = %d, want 403", rec.Code)
+	}
+}
+
+func TestCoordinator_HandleListJobs(t *testing.T) {
+	coord, _ := newAdminTestCoordinator(t)
+
+	rec := httptest.NewRecorder()
+	coord.HandleListJobs(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var resp JobsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Jobs) != 2 {
+		t.Fatalf("got %d jobs, want 2: %+v", len(resp.Jobs), resp.Jobs)
+	}
+
+	byID := map[string]JobInfo{}
+	for _, j := range resp.Jobs {
+		byID[j.JobID] = j
+	}
+	if j := byID["job-1"]; j.State != JobStateRunning || j.WorkerID != "worker-1" || j.DispatchedAt == nil {
+		t.Errorf("job-1 = %+v, want running on worker-1", j)
+	}
+	if j := byID["job-2"]; j.State != JobStateQueued || j.WorkerID != "" {
+		t.Errorf("job-2 = %+v, want queued without a worker", j)
+	}
+	if byID["job-1"].Command != "cargo build" {
+		t.Errorf("job-1 command = %q, want cargo build", byID["job-1"].Command)
+	}
+}
+
+func TestCoordinator_HandleKillJob(t *testing.T) {
+	coord, cancelled := newAdminTestCoordinator(t)
+
+	kill := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		coord.HandleKillJob(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+
+	rec := kill("/jobs/job-1/kill")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if len(*cancelled) != 1 || (*cancelled)[0] != "worker-1/job-1" {
+		t.Errorf("cancelled = %v, want [worker-1/job-1]", *cancelled)
+	}
+
+	jobs := coord.Dispatcher().Jobs()
+	if len(jobs) != 1 || jobs[0].JobID != "job-2" {
+		t.Errorf("remaining jobs = %+v, want only job-2", jobs)
+	}
+
+	// Killing a queued job removes it without contacting a worker
+	if rec := kill("/jobs/job-2/kill"); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if coord.Dispatcher().QueueLength() != 0 || len(*cancelled) != 1 {
+		t.Errorf("queued kill should empty the queue without a worker cancel")
+	}
+
+	// Killing again reports the cancelled job without contacting the worker
+	if rec := kill("/jobs/job-1/kill"); rec.Code != http.StatusOK || len(*cancelled) != 1 {
+		t.Errorf("repeated kill: status = %d, cancelled = %v; want 200 without a worker cancel", rec.Code, *cancelled)
+	}
+	if rec := kill("/jobs/unknown/kill"); rec.Code != http.StatusNotFound {
+		t.Errorf("status for unknown job = %d, want 404", rec.Code)
+	}
+	if rec := kill("/jobs/job-1"); rec.Code != http.StatusNotFound {
+		t.Errorf("status without /kill = %d, want 404", rec.Code)
+	}
+}
+
+func TestCoordinator_HandleCancelJob(t *testing.T) {
+	coord, cancelled := newAdminTestCoordinator(t)
+
+	cancel := func(method, path string) (*httptest.ResponseRecorder, CancelResponse) {
+		rec := httptest.NewRecorder()
+		coord.HandleCancelJob(rec, httptest.NewRequest(method, path, nil))
+		var resp CancelResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	// Only the submitting task may cancel a job here
+	for _, path := range []string{"/job/job-1", "/job/job-1?task_id=pricing/E01"} {
+		if rec, _ := cancel(http.MethodDelete, path); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want 403", path, rec.Code)
+		}
+	}
+	if len(*cancelled) != 0 {
+		t.Fatalf("cancelled = %v, want the job of another task left alone", *cancelled)
+	}
+
+	rec, resp := cancel(http.MethodDelete, "/job/job-1?task_id=billing/E02")
+	if rec.Code != http.StatusOK || resp.Status != JobStatusCancelled || resp.ExitCode != cancelledExitCode {
+		t.Fatalf("cancel running job: %d %+v, want 200 cancelled", rec.Code, resp)
+	}
+	if len(*cancelled) != 1 || (*cancelled)[0] != "worker-1/job-1" {
+		t.Errorf("cancelled = %v, want the worker told to cancel job-1", *cancelled)
+	}
+
+	// Cancelling again reports the cancelled job without contacting the worker
+	if rec, resp := cancel(http.MethodDelete, "/job/job-1?task_id=billing/E02"); rec.Code != http.StatusOK || resp.Status != JobStatusCancelled {
+		t.Errorf("repeated cancel: %d %+v, want 200 cancelled", rec.Code, resp)
+	}
+	if len(*cancelled) != 1 {
+		t.Errorf("repeated cancel contacted the worker: %v", *cancelled)
+	}
+
+	// A job that finished first is left alone
+	coord.Dispatcher().Complete("job-2", &buildprotocol.JobResult{JobID: "job-2", ExitCode: 101})
+	rec, resp = cancel(http.MethodDelete, "/job/job-2")
+	if rec.Code != http.StatusConflict || resp.Status != JobStatusCompleted || resp.ExitCode != 101 {
+		t.Errorf("cancel completed job: %d %+v, want 409 completed with exit code 101", rec.Code, resp)
+	}
+
+	if rec, _ := cancel(http.MethodDelete, "/job/unknown"); rec.Code != http.StatusNotFound {
+		t.Errorf("status for unknown job = %d, want 404", rec.Code)
+	}
+	if rec, _ := cancel(http.MethodGet, "/job/job-1"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status for GET = %d, want 405", rec.Code)
+	}
+}
+
+func TestCoordinator_HTTPJobIdempotencyKey(t *testing.T) {
+	registry := NewRegistry()
+
+	var mu sync.Mutex
+	runs := 0
+	release := make(chan struct{})
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+		return &buildprotocol.JobResult{JobID: job.JobID, Output: "built " + job.JobID}
+	}
+
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleJobSubmit))
+	defer server.Close()
+
+	submit := func(key string) JobResponse {
+		t.Helper()
+		body := fmt.Sprintf(`{"command":"cargo build","commit":"abc123","idempotency_key":%q}`, key)
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Errorf("POST: %v", err)
+			return JobResponse{}
+		}
+		defer resp.Body.Close()
+
+		var result JobResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Errorf("decode response: %v", err)
+		}
+		return result
+	}
+
+	// Two submissions while the first job is still in flight
+	results := make(chan JobResponse, 2)
+	go func() { results <- submit("key-1") }()
+	time.Sleep(50 * time.Millisecond)
+	go func() { results <- submit("key-1") }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	first, second := <-results, <-results
+	if first.JobID == "" || first.JobID != second.JobID {
+		t.Errorf("in-flight duplicate got job %q, want %q", second.JobID, first.JobID)
+	}
+
+	// A retry after completion returns the first result without running again
+	third := submit("key-1")
+	if third.JobID != first.JobID || third.Output != first.Output {
+		t.Errorf("completed duplicate = %+v, want %+v", third, first)
+	}
+
+	mu.Lock()
+	if runs != 1 {
+		t.Errorf("job ran %d times, want 1", runs)
+	}
+	mu.Unlock()
+
+	// A different key runs a new job
+	if other := submit("key-2"); other.JobID == first.JobID {
+		t.Errorf("different key reused job %s", other.JobID)
+	}
+}
+
+func TestCoordinator_HTTPJobIdempotencyWindowExpires(t *testing.T) {
+	registry := NewRegistry()
+
+	runs := 0
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		runs++
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0, IdempotencyWindow: time.Millisecond}, registry, dispatcher)
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleJobSubmit))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(server.URL, "application/json",
+			strings.NewReader(`{"command":"cargo build","idempotency_key":"key-1"}`))
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		resp.Body.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if runs != 2 {
+		t.Errorf("job ran %d times, want 2 after the window expired", runs)
+	}
+}
+
+func TestCoordinator_HTTPJobResultCache(t *testing.T) {
+	registry := NewRegistry()
+	runs := 0
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		runs++
+		return &buildprotocol.JobResult{JobID: job.JobID, Output: "Finished\n"}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	dispatcher.SetResultCacheSize(10)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	submit := func() JobResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		body := `{"command":"cargo clippy","repo":"git://host/repo","commit":"0123456789abcdef0123456789abcdef01234567","cacheable":true}`
+		coord.HandleJobSubmit(rec, httptest.NewRequest(http.MethodPost, "/job", strings.NewReader(body)))
+		var resp JobResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return resp
+	}
+
+	if first := submit(); first.Cached {
+		t.Error("first submission reported as cached")
+	}
+	second := submit()
+	if !second.Cached || second.Output != "Finished\n" || runs != 1 {
+		t.Errorf("second submission = %+v after %d runs, want the cached result", second, runs)
+	}
+
+	rec := httptest.NewRecorder()
+	coord.HandleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		ResultCache CacheStats `json:"result_cache"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if want := (CacheStats{Hits: 1, Misses: 1, Entries: 1, Capacity: 10}); status.ResultCache != want {
+		t.Errorf("result_cache = %+v, want %+v", status.ResultCache, want)
+	}
+}
@@ -2,39 +2,120 @@
 package buildpool
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 )
 
 // PendingJob tracks a job waiting for dispatch or completion
 type PendingJob struct {
-	Job       *buildprotocol.JobMessage
-	ResultCh  chan *buildprotocol.JobResult
-	WorkerID  string // Assigned worker (empty if queued)
-	Verbosity string // Output verbosity level
+	Job          *buildprotocol.JobMessage
+	ResultCh     chan *buildprotocol.JobResult
+	WorkerID     string    // Assigned worker (empty if queued)
+	Verbosity    string    // Output verbosity level
+	SubmittedAt  time.Time // When the job entered the queue
+	DispatchedAt time.Time // When the job was last handed to a worker (zero if queued)
+
+	cancel context.CancelFunc // Stops the job on the embedded worker (nil elsewhere)
 }
 
+// JobInfo is a point-in-time view of a queued or in-flight job
+type JobInfo struct {
+	JobID        string     `json:"job_id"`
+	TaskID       string     `json:"task_id,omitempty"` // Task of the submitting agent
+	Command      string     `json:"command"`
+	State        string     `json:"state"`               // "queued" or "running"
+	WorkerID     string     `json:"worker_id,omitempty"` // "embedded" for local fallback
+	Priority     int        `json:"priority,omitempty"`
+	SubmittedAt  time.Time  `json:"submitted_at"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	AgeSecs      float64    `json:"age_secs"` // Time since submission
+}
+
+// Job states reported in JobInfo
+const (
+	JobStateQueued  = "queued"
+	JobStateRunning = "running"
+)
+
+// JobSerialization selects which jobs the dispatcher runs one at a time, so
+// builds of the same checkout cannot clobber each other's working directory
+type JobSerialization string
+
+const (
+	SerializeNone     JobSerialization = ""       // Jobs run concurrently
+	SerializeByRepo   JobSerialization = "repo"   // At most one job per repo runs
+	SerializeByCommit JobSerialization = "commit" // At most one job per repo and commit runs
+)
+
+// ParseJobSerialization validates a serialize_jobs setting
+func ParseJobSerialization(s string) (JobSerialization, error) {
+	switch mode := JobSerialization(s); mode {
+	case SerializeNone, SerializeByRepo, SerializeByCommit:
+		return mode, nil
+	}
+	return SerializeNone, fmt.Errorf("invalid job serialization %q: must be %q, %q or empty", s, SerializeByRepo, SerializeByCommit)
+}
+
+// key returns the key that jobs must not share while running ("" = none)
+func (s JobSerialization) key(job *buildprotocol.JobMessage) string {
+	switch s {
+	case SerializeByRepo:
+		return job.Repo
+	case SerializeByCommit:
+		return job.Repo + "@" + job.Commit
+	}
+	return ""
+}
+
+// embeddedWorkerID identifies jobs running on the embedded worker in JobInfo
+const embeddedWorkerID = "embedded"
+
+// ErrJobNotFound is returned when a job ID is neither queued nor in flight
+var ErrJobNotFound = errors.New("job not found")
+
 // SendFunc sends a job to a worker
 type SendFunc func(w *ConnectedWorker, job *buildprotocol.JobMessage) error
 
-// EmbeddedWorkerFunc runs a job on the embedded worker
-type EmbeddedWorkerFunc func(job *buildprotocol.JobMessage) *buildprotocol.JobResult
+// EmbeddedWorkerFunc runs a job on the embedded worker, stopping it when ctx
+// is cancelled
+type EmbeddedWorkerFunc func(ctx context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult
 
 // CancelFunc sends a cancel message to a worker
 type CancelFunc func(workerID, jobID string) error
 
+// CompleteFunc is notified of every finished job with its unfiltered result,
+// cancelled jobs included
+type CompleteFunc func(job *buildprotocol.JobMessage, result *buildprotocol.JobResult)
+
+// DispatchFunc is notified of every job started on a worker, with the ID of
+// the worker (embeddedWorkerID for the embedded worker)
+type DispatchFunc func(job *buildprotocol.JobMessage, workerID string)
+
 // Dispatcher manages job queue and assignment
 type Dispatcher struct {
-	registry   *Registry
-	embedded   EmbeddedWorkerFunc
-	sendFunc   SendFunc
-	cancelFunc CancelFunc
+	registry     *Registry
+	embedded     EmbeddedWorkerFunc
+	sendFunc     SendFunc
+	cancelFunc   CancelFunc
+	completeFunc CompleteFunc
+	dispatchFunc DispatchFunc
 
 	// Local repo path for embedded worker (avoids fetch for unpushed commits)
 	localRepoPath string
 
+	serialization JobSerialization
+	runningKeys   map[string]string // jobID -> serialization key, for running serialized jobs
+
+	cache     *resultCache  // Results of cacheable jobs (nil = caching disabled)
+	submitted atomic.Uint64 // Jobs submitted so far, for metrics
+
 	queue   []*PendingJob
 	pending map[string]*PendingJob // jobID -> pending job
 	mu      sync.Mutex
@@ -43,9 +124,10 @@ type Dispatcher struct {
 // NewDispatcher creates a new job dispatcher
 func NewDispatcher(registry *Registry, embedded EmbeddedWorkerFunc) *Dispatcher {
 	return &Dispatcher{
-		registry: registry,
-		embedded: embedded,
-		pending:  make(map[string]*PendingJob),
+		registry:    registry,
+		embedded:    embedded,
+		runningKeys: make(map[string]string),
+		pending:     make(map[string]*PendingJob),
 	}
 }
 
@@ -59,6 +141,16 @@ func (d *Dispatcher) SetCancelFunc(fn CancelFunc) {
 	d.cancelFunc = fn
 }
 
+// SetCompleteFunc sets the function notified when a job completes
+func (d *Dispatcher) SetCompleteFunc(fn CompleteFunc) {
+	d.completeFunc = fn
+}
+
+// SetDispatchFunc sets the function notified when a job is dispatched
+func (d *Dispatcher) SetDispatchFunc(fn DispatchFunc) {
+	d.dispatchFunc = fn
+}
+
 // SetLocalRepoPath sets the local repo path for embedded worker
 // This allows embedded worker to use local path instead of remote URL
 // for unpushed commits
@@ -66,29 +158,102 @@ func (d *Dispatcher) SetLocalRepoPath(path string) {
 	d.localRepoPath = path
 }
 
+// SetSerialization sets which jobs run one at a time. Jobs whose key is
+// already running stay queued until it finishes.
+func (d *Dispatcher) SetSerialization(mode JobSerialization) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.serialization = mode
+}
+
+// SetResultCacheSize keeps the results of up to size successful cacheable
+// jobs, evicting the least recently used (0 disables caching)
+func (d *Dispatcher) SetResultCacheSize(size int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if size <= 0 {
+		d.cache = nil
+		return
+	}
+	d.cache = newResultCache(size)
+}
+
+// CacheStats reports the result cache's hits, misses and size
+func (d *Dispatcher) CacheStats() CacheStats {
+	d.mu.Lock()
+	cache := d.cache
+	d.mu.Unlock()
+	if cache == nil {
+		return CacheStats{}
+	}
+	return cache.stats()
+}
+
 // Submit adds a job to the queue and returns a channel for the result
 func (d *Dispatcher) Submit(job *buildprotocol.JobMessage) chan *buildprotocol.JobResult {
 	return d.SubmitWithVerbosity(job, "")
 }
 
-// SubmitWithVerbosity adds a job to the queue with specified verbosity level
+// SubmitWithVerbosity adds a job to the queue with specified verbosity level.
+// A cacheable job whose result is cached completes at once without running.
 func (d *Dispatcher) SubmitWithVerbosity(job *buildprotocol.JobMessage, verbosity string) chan *buildprotocol.JobResult {
+	resultCh := make(chan *buildprotocol.JobResult, 1)
+	d.submitted.Add(1)
+
+	d.mu.Lock()
+	cache := d.cache
+	d.mu.Unlock()
+	if cache != nil {
+		if result, ok := cache.get(job); ok {
+			result.JobID = job.JobID
+			result.Cached = true
+			if d.completeFunc != nil {
+				d.completeFunc(job, result)
+			}
+			if verbosity != "" {
+				result = applyVerbosityFilter(result, verbosity)
+			}
+			resultCh <- result
+			close(resultCh)
+			return resultCh
+		}
+	}
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	resultCh := make(chan *buildprotocol.JobResult, 1)
 	pending := &PendingJob{
-		Job:       job,
-		ResultCh:  resultCh,
-		Verbosity: verbosity,
+		Job:         job,
+		ResultCh:    resultCh,
+		Verbosity:   verbosity,
+		SubmittedAt: time.Now(),
 	}
 
-	d.queue = append(d.queue, pending)
+	d.enqueueLocked(pending)
 	d.pending[job.JobID] = pending
 
 	return resultCh
 }
 
+// enqueueLocked inserts a job into the queue, which is ordered by descending
+// priority and, within a priority, by submission time
+func (d *Dispatcher) enqueueLocked(pj *PendingJob) {
+	i := sort.Search(len(d.queue), func(i int) bool {
+		return runsBefore(pj, d.queue[i])
+	})
+	d.queue = append(d.queue, nil)
+	copy(d.queue[i+1:], d.queue[i:])
+	d.queue[i] = pj
+}
+
+// runsBefore reports whether job a is dispatched before job b
+func runsBefore(a, b *PendingJob) bool {
+	if a.Job.Priority != b.Job.Priority {
+		return a.Job.Priority > b.Job.Priority
+	}
+	return a.SubmittedAt.Before(b.SubmittedAt)
+}
+
 // GetVerbosity retrieves the verbosity setting for a job
 func (d *Dispatcher) GetVerbosity(jobID string) string {
 	d.mu.Lock()
@@ -103,26 +268,36 @@ func (d *Dispatcher) GetVerbosity(jobID string) string {
 // TryDispatch attempts to dispatch queued jobs to available workers
 func (d *Dispatcher) TryDispatch() {
 	d.mu.Lock()
-	defer d.mu.Unlock()
 
 	var remaining []*PendingJob
+	var dispatched []dispatchedJob
 
 	for _, pj := range d.queue {
-		// Try to find a ready worker
-		worker := d.registry.FindReady()
+		key := d.serialization.key(pj.Job)
+		if key != "" && d.keyRunningLocked(key) {
+			// A job with the same key is running, keep in queue
+			remaining = append(remaining, pj)
+			continue
+		}
+
+		// Try to find a ready worker with the tags the job requires
+		worker := d.registry.FindReadyWithTags(pj.Job.RequiredTags)
 
 		if worker != nil && d.sendFunc != nil {
 			// Dispatch to worker
 			worker.DecrementSlots()
 			pj.WorkerID = worker.ID
+			pj.DispatchedAt = time.Now()
 
 			if err := d.sendFunc(worker, pj.Job); err != nil {
 				// Send failed, keep in queue
 				remaining = append(remaining, pj)
 				continue
 			}
-		} else if d.embedded != nil && d.registry.Count() == 0 {
-			// No workers, use embedded
+			d.holdKeyLocked(pj.Job.JobID, key)
+			dispatched = append(dispatched, dispatchedJob{pj.Job, worker.ID})
+		} else if d.embedded != nil && !d.registry.AnyWithTags(pj.Job.RequiredTags) {
+			// No workers (with the required tags), use embedded
 			// Substitute local repo path if available (avoids fetch for unpushed commits)
 			job := pj.Job
 			if d.localRepoPath != "" {
@@ -130,8 +305,14 @@ func (d *Dispatcher) TryDispatch() {
 				jobCopy.Repo = d.localRepoPath
 				job = &jobCopy
 			}
+			ctx, cancel := context.WithCancel(context.Background())
+			pj.DispatchedAt = time.Now()
+			pj.cancel = cancel
+			d.holdKeyLocked(pj.Job.JobID, key)
+			dispatched = append(dispatched, dispatchedJob{pj.Job, embeddedWorkerID})
 			go func(pj *PendingJob, job *buildprotocol.JobMessage) {
-				result := d.embedded(job)
+				defer cancel()
+				result := d.embedded(ctx, job)
 				d.Complete(pj.Job.JobID, result)
 			}(pj, job)
 		} else {
@@ -141,6 +322,48 @@ func (d *Dispatcher) TryDispatch() {
 	}
 
 	d.queue = remaining
+	d.mu.Unlock()
+
+	// Notify outside the lock, like completions, so a slow sink does not
+	// hold up the dispatcher
+	if d.dispatchFunc != nil {
+		for _, dj := range dispatched {
+			d.dispatchFunc(dj.job, dj.workerID)
+		}
+	}
+}
+
+// dispatchedJob is a job TryDispatch started, for the DispatchFunc
+type dispatchedJob struct {
+	job      *buildprotocol.JobMessage
+	workerID string
+}
+
+// keyRunningLocked reports whether a job with the serialization key is running
+func (d *Dispatcher) keyRunningLocked(key string) bool {
+	for _, k := range d.runningKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// holdKeyLocked records that a dispatched job runs with the serialization key
+func (d *Dispatcher) holdKeyLocked(jobID, key string) {
+	if key != "" {
+		d.runningKeys[jobID] = key
+	}
+}
+
+// releaseKeyLocked frees the serialization key held by a job and reports
+// whether it held one, i.e. whether queued jobs may now be dispatchable
+func (d *Dispatcher) releaseKeyLocked(jobID string) bool {
+	if _, ok := d.runningKeys[jobID]; !ok {
+		return false
+	}
+	delete(d.runningKeys, jobID)
+	return true
 }
 
 // Complete marks a job as complete and sends the result
@@ -150,8 +373,23 @@ func (d *Dispatcher) Complete(jobID string, result *buildprotocol.JobResult) {
 	if ok {
 		delete(d.pending, jobID)
 	}
+	released := d.releaseKeyLocked(jobID)
+	cache := d.cache
 	d.mu.Unlock()
 
+	if released {
+		// Jobs waiting for the key may run now
+		defer d.TryDispatch()
+	}
+
+	if ok && cache != nil {
+		cache.put(pj.Job, result)
+	}
+
+	if ok && d.completeFunc != nil {
+		d.completeFunc(pj.Job, result)
+	}
+
 	if ok && pj.ResultCh != nil {
 		// Apply verbosity filtering if set
 		if pj.Verbosity != "" {
@@ -169,6 +407,7 @@ func applyVerbosityFilter(result *buildprotocol.JobResult, verbosity string) *bu
 		ExitCode:     result.ExitCode,
 		DurationSecs: result.DurationSecs,
 		Stderr:       result.Stderr,
+		Cached:       result.Cached,
 	}
 
 	switch verbosity {
@@ -192,19 +431,22 @@ func applyVerbosityFilter(result *buildprotocol.JobResult, verbosity string) *bu
 	return filtered
 }
 
-// Cancel cancels a job
+// Cancel cancels a queued or running job. The job finishes with
+// cancelledExitCode; a job running on the embedded worker is stopped through
+// its context, one on a remote worker through the cancel func.
 func (d *Dispatcher) Cancel(jobID string) error {
 	d.mu.Lock()
 	pj, ok := d.pending[jobID]
 	if !ok {
 		d.mu.Unlock()
-		return fmt.Errorf("job %s not found", jobID)
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
 	}
+	delete(d.pending, jobID)
 
 	workerID := pj.WorkerID
-
-	// If still queued (not assigned), remove from queue and pending
-	if workerID == "" {
+	queued := workerID == "" && pj.cancel == nil
+	if queued {
+		// Still queued (not assigned), remove from queue
 		var remaining []*PendingJob
 		for _, q := range d.queue {
 			if q.Job.JobID != jobID {
@@ -212,31 +454,90 @@ func (d *Dispatcher) Cancel(jobID string) error {
 			}
 		}
 		d.queue = remaining
-		// Notify result channel before removing from pending
-		if pj.ResultCh != nil {
-			pj.ResultCh <- &buildprotocol.JobResult{JobID: jobID, ExitCode: -2, Output: "Job cancelled"}
-			close(pj.ResultCh)
-		}
-		delete(d.pending, jobID)
-		d.mu.Unlock()
-		return nil
+	} else if d.releaseKeyLocked(jobID) {
+		defer d.TryDispatch()
 	}
 
-	// Job is assigned to a worker - notify result channel and remove from pending
+	result := &buildprotocol.JobResult{JobID: jobID, ExitCode: cancelledExitCode, Output: "Job cancelled"}
 	if pj.ResultCh != nil {
-		pj.ResultCh <- &buildprotocol.JobResult{JobID: jobID, ExitCode: -2, Output: "Job cancelled"}
+		pj.ResultCh <- result
 		close(pj.ResultCh)
 	}
-	delete(d.pending, jobID)
 	d.mu.Unlock()
 
-	// Send cancel message to worker
-	if d.cancelFunc == nil {
+	if d.completeFunc != nil {
+		d.completeFunc(pj.Job, result)
+	}
+
+	switch {
+	case queued:
+		return nil
+	case pj.cancel != nil:
+		// Running on the embedded worker; its late result is dropped by Complete
+		pj.cancel()
+		return nil
+	case d.cancelFunc == nil:
 		return fmt.Errorf("job %s assigned to worker but no cancelFunc configured", jobID)
 	}
+	// Send cancel message to worker
 	return d.cancelFunc(workerID, jobID)
 }
 
+// JobTaskID returns the task that submitted a queued or in-flight job, and
+// whether the job is known
+func (d *Dispatcher) JobTaskID(jobID string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pj, ok := d.pending[jobID]
+	if !ok {
+		return "", false
+	}
+	return pj.Job.TaskID, true
+}
+
+// Jobs returns a snapshot of all queued and in-flight jobs, oldest first
+func (d *Dispatcher) Jobs() []JobInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queued := make(map[string]bool, len(d.queue))
+	for _, pj := range d.queue {
+		queued[pj.Job.JobID] = true
+	}
+
+	now := time.Now()
+	jobs := make([]JobInfo, 0, len(d.pending))
+	for id, pj := range d.pending {
+		info := JobInfo{
+			JobID:       id,
+			TaskID:      pj.Job.TaskID,
+			Command:     pj.Job.Command,
+			Priority:    pj.Job.Priority,
+			State:       JobStateQueued,
+			SubmittedAt: pj.SubmittedAt,
+			AgeSecs:     now.Sub(pj.SubmittedAt).Seconds(),
+		}
+		if !queued[id] {
+			info.State = JobStateRunning
+			info.WorkerID = pj.WorkerID
+			if info.WorkerID == "" {
+				info.WorkerID = embeddedWorkerID
+			}
+			if !pj.DispatchedAt.IsZero() {
+				dispatchedAt := pj.DispatchedAt
+				info.DispatchedAt = &dispatchedAt
+			}
+		}
+		jobs = append(jobs, info)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].SubmittedAt.Before(jobs[j].SubmittedAt)
+	})
+	return jobs
+}
+
 // QueueLength returns the number of queued jobs
 func (d *Dispatcher) QueueLength() int {
 	d.mu.Lock()
@@ -251,17 +552,27 @@ func (d *Dispatcher) PendingCount() int {
 	return len(d.pending)
 }
 
-// RequeueWorkerJobs requeues all in-progress jobs assigned to a worker
-func (d *Dispatcher) RequeueWorkerJobs(workerID string) {
+// RequeueWorkerJobs requeues all in-progress jobs assigned to a worker and
+// returns how many were requeued
+func (d *Dispatcher) RequeueWorkerJobs(workerID string) int {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	requeued := 0
 	for _, pj := range d.pending {
 		if pj.WorkerID == workerID {
 			pj.WorkerID = ""
-			d.queue = append(d.queue, pj)
+			d.releaseKeyLocked(pj.Job.JobID)
+			d.enqueueLocked(pj)
+			requeued++
 		}
 	}
+	return requeued
+}
+
+// SubmittedCount returns how many jobs were submitted, cache hits included
+func (d *Dispatcher) SubmittedCount() uint64 {
+	return d.submitted.Load()
 }
 
 // QueuedCount returns the number of queued jobs (alias for QueueLength)
@@ -2,7 +2,10 @@
 package buildpool
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 )
@@ -73,12 +76,41 @@ func TestDispatcher_DispatchToWorker(t *testing.T) {
 	}
 }
 
+func TestDispatcher_DispatchFuncRunsOutsideLock(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(&ConnectedWorker{ID: "worker-1", MaxJobs: 4, Slots: 2})
+
+	disp := NewDispatcher(reg, nil)
+	disp.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error { return nil })
+	// The callback reads the dispatcher, which deadlocks while it holds its lock
+	var queued int
+	disp.SetDispatchFunc(func(job *buildprotocol.JobMessage, workerID string) {
+		queued = disp.QueueLength()
+	})
+
+	disp.Submit(&buildprotocol.JobMessage{JobID: "job-1", Repo: "git://localhost/repo", Commit: "abc123", Command: "cargo test"})
+
+	done := make(chan struct{})
+	go func() {
+		disp.TryDispatch()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("TryDispatch deadlocked calling the dispatch func")
+	}
+	if queued != 0 {
+		t.Errorf("queue length in dispatch func = %d, want 0", queued)
+	}
+}
+
 func TestDispatcher_EmbeddedWorkerUsesLocalRepoPath(t *testing.T) {
 	reg := NewRegistry()
 
 	// Track what repo was passed to embedded worker
 	var receivedRepo string
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		receivedRepo = job.Repo
 		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: 0}
 	}
@@ -114,7 +146,7 @@ func TestDispatcher_EmbeddedWorkerFallsBackToRemoteURL(t *testing.T) {
 
 	// Track what repo was passed to embedded worker
 	var receivedRepo string
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		receivedRepo = job.Repo
 		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: 0}
 	}
@@ -173,6 +205,52 @@ func TestDispatcher_Cancel(t *testing.T) {
 	}
 }
 
+func TestDispatcher_CancelEmbeddedJob(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	embedded := func(ctx context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: -1, Output: "killed"}
+	}
+	dispatcher := NewDispatcher(NewRegistry(), embedded)
+
+	var completed []*buildprotocol.JobResult
+	dispatcher.SetCompleteFunc(func(job *buildprotocol.JobMessage, result *buildprotocol.JobResult) {
+		completed = append(completed, result)
+	})
+
+	resultCh := dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-1", Command: "cargo build"})
+	dispatcher.TryDispatch()
+	<-started
+
+	if err := dispatcher.Cancel("job-1"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if result := <-resultCh; result.ExitCode != cancelledExitCode {
+		t.Errorf("exit code = %d, want %d", result.ExitCode, cancelledExitCode)
+	}
+
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("job context error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("embedded job was not stopped")
+	}
+
+	// The killed job's late result does not complete it a second time
+	dispatcher.Complete("job-1", &buildprotocol.JobResult{JobID: "job-1", ExitCode: -1})
+	if len(completed) != 1 || completed[0].ExitCode != cancelledExitCode {
+		t.Errorf("completed = %+v, want only the cancelled result", completed)
+	}
+	if dispatcher.PendingCount() != 0 {
+		t.Errorf("pending = %d, want 0", dispatcher.PendingCount())
+	}
+}
+
 func TestDispatcher_VerbosityFilterPreservesErrorMessages(t *testing.T) {
 	// Test that verbosity filtering preserves error messages
 	tests := []struct {
@@ -256,7 +334,7 @@ func TestDispatcher_VerbosityFilterWithEmbeddedWorker(t *testing.T) {
 	reg := NewRegistry()
 
 	// Mock embedded worker that returns an error result
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: -1,
@@ -295,3 +373,338 @@ func TestDispatcher_VerbosityFilterWithEmbeddedWorker(t *testing.T) {
 		t.Errorf("Stderr is empty, want error message")
 	}
 }
+
+// gatedEmbedded returns an embedded worker that reports each job it starts and
+// blocks until the job's release channel is closed
+func gatedEmbedded(release map[string]chan struct{}) (EmbeddedWorkerFunc, chan string) {
+	started := make(chan string, len(release))
+	return func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		started <- job.JobID
+		<-release[job.JobID]
+		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: 0}
+	}, started
+}
+
+// expectStarted waits for the given jobs to start, in any order
+func expectStarted(t *testing.T, started chan string, jobIDs ...string) {
+	t.Helper()
+	want := make(map[string]bool)
+	for _, id := range jobIDs {
+		want[id] = true
+	}
+	for range jobIDs {
+		select {
+		case id := <-started:
+			if !want[id] {
+				t.Fatalf("job %s started, want one of %v", id, jobIDs)
+			}
+			delete(want, id)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("jobs %v did not start", want)
+		}
+	}
+}
+
+// expectNotStarted fails if any job starts within a short grace period
+func expectNotStarted(t *testing.T, started chan string) {
+	t.Helper()
+	select {
+	case id := <-started:
+		t.Fatalf("job %s started while its key was running", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDispatcher_SerializeByRepo(t *testing.T) {
+	release := map[string]chan struct{}{
+		"a1": make(chan struct{}),
+		"a2": make(chan struct{}),
+		"b1": make(chan struct{}),
+	}
+	embedded, started := gatedEmbedded(release)
+
+	disp := NewDispatcher(NewRegistry(), embedded)
+	disp.SetSerialization(SerializeByRepo)
+
+	results := map[string]chan *buildprotocol.JobResult{
+		"a1": disp.Submit(&buildprotocol.JobMessage{JobID: "a1", Repo: "repo-a", Commit: "c1"}),
+		"a2": disp.Submit(&buildprotocol.JobMessage{JobID: "a2", Repo: "repo-a", Commit: "c2"}),
+		"b1": disp.Submit(&buildprotocol.JobMessage{JobID: "b1", Repo: "repo-b", Commit: "c1"}),
+	}
+	disp.TryDispatch()
+
+	// Different repos run concurrently, the second job of repo-a waits
+	expectStarted(t, started, "a1", "b1")
+	expectNotStarted(t, started)
+	if disp.QueueLength() != 1 {
+		t.Errorf("QueueLength = %d, want 1", disp.QueueLength())
+	}
+
+	// Finishing the running repo-a job dispatches the queued one
+	close(release["a1"])
+	<-results["a1"]
+	expectStarted(t, started, "a2")
+
+	close(release["a2"])
+	close(release["b1"])
+	for id, ch := range results {
+		if id == "a1" {
+			continue
+		}
+		if result := <-ch; result.ExitCode != 0 {
+			t.Errorf("job %s exit code = %d, want 0", id, result.ExitCode)
+		}
+	}
+}
+
+func TestDispatcher_SerializeByCommit(t *testing.T) {
+	release := map[string]chan struct{}{
+		"c1":       make(chan struct{}),
+		"c1-again": make(chan struct{}),
+		"c2":       make(chan struct{}),
+	}
+	embedded, started := gatedEmbedded(release)
+
+	disp := NewDispatcher(NewRegistry(), embedded)
+	disp.SetSerialization(SerializeByCommit)
+
+	disp.Submit(&buildprotocol.JobMessage{JobID: "c1", Repo: "repo", Commit: "c1"})
+	again := disp.Submit(&buildprotocol.JobMessage{JobID: "c1-again", Repo: "repo", Commit: "c1"})
+	disp.Submit(&buildprotocol.JobMessage{JobID: "c2", Repo: "repo", Commit: "c2"})
+	disp.TryDispatch()
+
+	// Other commits of the same repo run concurrently
+	expectStarted(t, started, "c1", "c2")
+	expectNotStarted(t, started)
+
+	close(release["c1"])
+	expectStarted(t, started, "c1-again")
+
+	close(release["c1-again"])
+	close(release["c2"])
+	<-again
+}
+
+func TestDispatcher_NoSerializationRunsConcurrently(t *testing.T) {
+	release := map[string]chan struct{}{
+		"a1": make(chan struct{}),
+		"a2": make(chan struct{}),
+	}
+	embedded, started := gatedEmbedded(release)
+
+	disp := NewDispatcher(NewRegistry(), embedded)
+	disp.Submit(&buildprotocol.JobMessage{JobID: "a1", Repo: "repo-a", Commit: "c1"})
+	disp.Submit(&buildprotocol.JobMessage{JobID: "a2", Repo: "repo-a", Commit: "c1"})
+	disp.TryDispatch()
+
+	expectStarted(t, started, "a1", "a2")
+	close(release["a1"])
+	close(release["a2"])
+}
+
+func TestParseJobSerialization(t *testing.T) {
+	for _, s := range []string{"", "repo", "commit"} {
+		if mode, err := ParseJobSerialization(s); err != nil || string(mode) != s {
+			t.Errorf("ParseJobSerialization(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseJobSerialization("worktree"); err == nil {
+		t.Error("expected error for unknown serialization mode")
+	}
+}
+
+func TestDispatcher_PriorityOrder(t *testing.T) {
+	reg := NewRegistry()
+	disp := NewDispatcher(reg, nil)
+
+	var sent []string
+	disp.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error {
+		sent = append(sent, job.JobID)
+		return nil
+	})
+
+	// No slot is free while the jobs queue up
+	for _, job := range []*buildprotocol.JobMessage{
+		{JobID: "release-build", Command: "cargo build --release"},
+		{JobID: "lint", Command: "cargo clippy", Priority: -1},
+		{JobID: "interactive-test", Command: "cargo test", Priority: 10},
+		{JobID: "build", Command: "cargo build"},
+		{JobID: "second-test", Command: "cargo test", Priority: 10},
+	} {
+		disp.Submit(job)
+	}
+
+	worker := &ConnectedWorker{ID: "worker-1", MaxJobs: 1, Slots: 0}
+	reg.Register(worker)
+	want := []string{"interactive-test", "second-test", "release-build", "build", "lint"}
+	for i := range want {
+		worker.UpdateSlots(1)
+		disp.TryDispatch()
+		if len(sent) != i+1 {
+			t.Fatalf("after slot %d: sent %v", i+1, sent)
+		}
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Fatalf("dispatch order = %v, want %v", sent, want)
+		}
+	}
+}
+
+func TestDispatcher_RequeueKeepsPriority(t *testing.T) {
+	reg := NewRegistry()
+	disp := NewDispatcher(reg, nil)
+	disp.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error { return nil })
+
+	reg.Register(&ConnectedWorker{ID: "worker-1", MaxJobs: 1, Slots: 1})
+	disp.Submit(&buildprotocol.JobMessage{JobID: "urgent", Command: "cargo test", Priority: 5})
+	disp.TryDispatch()
+	disp.Submit(&buildprotocol.JobMessage{JobID: "normal", Command: "cargo build"})
+
+	// The worker drops the running job, which goes back ahead of the normal one
+	reg.Unregister("worker-1")
+	if n := disp.RequeueWorkerJobs("worker-1"); n != 1 {
+		t.Fatalf("requeued %d jobs, want 1", n)
+	}
+
+	var sent []string
+	disp.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error {
+		sent = append(sent, job.JobID)
+		return nil
+	})
+	reg.Register(&ConnectedWorker{ID: "worker-2", MaxJobs: 1, Slots: 1})
+	disp.TryDispatch()
+	if len(sent) != 1 || sent[0] != "urgent" {
+		t.Errorf("dispatched %v, want [urgent]", sent)
+	}
+}
+
+func TestDispatcher_RoutesByRequiredTags(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(&ConnectedWorker{ID: "cpu", MaxJobs: 4, Slots: 4})
+	gpu := &ConnectedWorker{ID: "gpu", MaxJobs: 1, Slots: 0, Tags: []string{"gpu"}}
+	reg.Register(gpu)
+
+	var embeddedJobs []string
+	done := make(chan struct{}, 1)
+	disp := NewDispatcher(reg, func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		embeddedJobs = append(embeddedJobs, job.JobID)
+		done <- struct{}{}
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	})
+	sent := map[string]string{}
+	disp.SetSendFunc(func(w *ConnectedWorker, job *buildprotocol.JobMessage) error {
+		sent[job.JobID] = w.ID
+		return nil
+	})
+
+	disp.Submit(&buildprotocol.JobMessage{JobID: "train", Command: "make train", RequiredTags: []string{"gpu"}})
+	disp.Submit(&buildprotocol.JobMessage{JobID: "build", Command: "cargo build"})
+	disp.Submit(&buildprotocol.JobMessage{JobID: "arm", Command: "cargo build", RequiredTags: []string{"arm64"}})
+	disp.TryDispatch()
+	<-done
+
+	// The busy gpu worker is the only match, so the job waits for it
+	if sent["build"] != "cpu" {
+		t.Errorf("untagged job went to %q, want cpu", sent["build"])
+	}
+	if _, ok := sent["train"]; ok || disp.QueueLength() != 1 {
+		t.Errorf("gpu job should wait for the gpu worker, sent=%v queued=%d", sent, disp.QueueLength())
+	}
+	// No worker has arm64, so the job falls back to the embedded worker
+	if len(embeddedJobs) != 1 || embeddedJobs[0] != "arm" {
+		t.Errorf("embedded worker ran %v, want [arm]", embeddedJobs)
+	}
+
+	gpu.UpdateSlots(1)
+	disp.TryDispatch()
+	if sent["train"] != "gpu" {
+		t.Errorf("gpu job went to %q, want gpu", sent["train"])
+	}
+}
+
+func TestDispatcher_ResultCache(t *testing.T) {
+	runs := 0
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		runs++
+		exitCode := 0
+		if job.Command == "cargo test" {
+			exitCode = 101
+		}
+		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: exitCode, Stdout: "ran " + job.Command}
+	}
+	disp := NewDispatcher(NewRegistry(), embedded)
+	disp.SetResultCacheSize(2)
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	submit := func(id, commit, command string, cacheable bool) *buildprotocol.JobResult {
+		t.Helper()
+		ch := disp.Submit(&buildprotocol.JobMessage{
+			JobID: id, Repo: "git://host/repo", Commit: commit, Command: command, Cacheable: cacheable,
+		})
+		disp.TryDispatch()
+		select {
+		case result := <-ch:
+			return result
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: no result", id)
+			return nil
+		}
+	}
+
+	first := submit("job-1", sha, "cargo clippy", true)
+	if first.Cached {
+		t.Error("first run reported as cached")
+	}
+	second := submit("job-2", sha, "cargo clippy", true)
+	if runs != 1 || !second.Cached || second.JobID != "job-2" || second.Stdout != "ran cargo clippy" {
+		t.Errorf("second submission: runs = %d, result = %+v; want the cached result under job-2", runs, second)
+	}
+
+	// Opting out, refs and failures bypass the cache
+	submit("job-3", sha, "cargo clippy", false)
+	submit("job-4", "HEAD", "cargo clippy", true)
+	submit("job-5", "HEAD", "cargo clippy", true)
+	submit("job-6", sha, "cargo test", true)
+	submit("job-7", sha, "cargo test", true)
+	if runs != 6 {
+		t.Errorf("runs = %d, want 6", runs)
+	}
+
+	// The least recently used result is evicted once the cache is full
+	submit("job-8", sha, "cargo build", true)
+	submit("job-9", sha, "cargo doc", true)
+	submit("job-10", sha, "cargo clippy", true)
+	if runs != 9 {
+		t.Errorf("runs = %d, want clippy to be evicted and run again", runs)
+	}
+
+	stats := disp.CacheStats()
+	want := CacheStats{Hits: 1, Misses: 6, Entries: 2, Capacity: 2}
+	if stats != want {
+		t.Errorf("CacheStats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestDispatcher_ResultCacheDisabled(t *testing.T) {
+	runs := 0
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		runs++
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+	disp := NewDispatcher(NewRegistry(), embedded)
+
+	for _, id := range []string{"job-1", "job-2"} {
+		ch := disp.Submit(&buildprotocol.JobMessage{
+			JobID: id, Commit: "0123456789abcdef0123456789abcdef01234567", Command: "cargo build", Cacheable: true,
+		})
+		disp.TryDispatch()
+		<-ch
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2 without a cache", runs)
+	}
+	if stats := disp.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("CacheStats() = %+v, want zero", stats)
+	}
+}
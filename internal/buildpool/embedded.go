@@ -15,6 +15,8 @@ type EmbeddedConfig struct {
 	WorktreeDir string
 	MaxJobs     int
 	UseNixShell bool
+
+	WarmWorktrees int // Worktrees kept checked out per recently built commit (0 = off)
 }
 
 // EmbeddedWorker runs jobs locally as fallback
@@ -27,16 +29,22 @@ type EmbeddedWorker struct {
 func NewEmbeddedWorker(config EmbeddedConfig) *EmbeddedWorker {
 	return &EmbeddedWorker{
 		executor: buildworker.NewExecutor(buildworker.ExecutorConfig{
-			GitCacheDir: config.RepoDir,
-			WorktreeDir: config.WorktreeDir,
-			UseNixShell: config.UseNixShell,
+			GitCacheDir:   config.RepoDir,
+			WorktreeDir:   config.WorktreeDir,
+			UseNixShell:   config.UseNixShell,
+			WarmWorktrees: config.WarmWorktrees,
 		}),
 		pool: buildworker.NewPool(config.MaxJobs),
 	}
 }
 
-// Run executes a job and returns the result
-func (e *EmbeddedWorker) Run(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+// Close removes the worker's warm worktrees
+func (e *EmbeddedWorker) Close() {
+	e.executor.Close()
+}
+
+// Run executes a job and returns the result; cancelling ctx kills the job
+func (e *EmbeddedWorker) Run(ctx context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 	if !e.pool.Acquire() {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
@@ -51,7 +59,7 @@ func (e *EmbeddedWorker) Run(job *buildprotocol.JobMessage) *buildprotocol.JobRe
 		timeout = 5 * time.Minute
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	result, err := e.executor.RunJob(ctx, buildworker.Job{
@@ -61,6 +69,7 @@ func (e *EmbeddedWorker) Run(job *buildprotocol.JobMessage) *buildprotocol.JobRe
 		Command: job.Command,
 		Env:     job.Env,
 		Timeout: timeout,
+		UseNix:  job.UseNix,
 	}, nil) // No streaming for embedded worker
 
 	if err != nil {
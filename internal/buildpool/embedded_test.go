@@ -2,6 +2,7 @@
 package buildpool
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -66,7 +67,7 @@ func TestEmbeddedWorker_RunJob(t *testing.T) {
 		Command: "echo embedded-test",
 	}
 
-	result := embedded.Run(job)
+	result := embedded.Run(context.Background(), job)
 
 	if result.ExitCode != 0 {
 		t.Errorf("got exit code %d, want 0", result.ExitCode)
@@ -130,7 +131,7 @@ func TestEmbeddedWorker_PoolExhaustion(t *testing.T) {
 	done := make(chan *buildprotocol.JobResult)
 	go func() {
 		close(started)
-		result := embedded.Run(&buildprotocol.JobMessage{
+		result := embedded.Run(context.Background(), &buildprotocol.JobMessage{
 			JobID:   "long-job",
 			Repo:    repoDir,
 			Commit:  commit,
@@ -145,7 +146,7 @@ func TestEmbeddedWorker_PoolExhaustion(t *testing.T) {
 	// (the job will block on git worktree setup anyway)
 
 	// Try to run another job - should fail with no slots available
-	result := embedded.Run(&buildprotocol.JobMessage{
+	result := embedded.Run(context.Background(), &buildprotocol.JobMessage{
 		JobID:   "exhaustion-test",
 		Repo:    repoDir,
 		Commit:  commit,
@@ -218,7 +219,7 @@ func TestEmbeddedWorker_ErrorHandling(t *testing.T) {
 				Timeout: 30,
 			}
 
-			result := worker.Run(job)
+			result := worker.Run(context.Background(), job)
 
 			t.Logf("Result: ExitCode=%d, Output=%q, Stdout=%q, Stderr=%q",
 				result.ExitCode, result.Output, result.Stdout, result.Stderr)
@@ -263,7 +264,7 @@ func TestEmbeddedWorker_ExecutorError(t *testing.T) {
 		Timeout: 5, // Short timeout
 	}
 
-	result := worker.Run(job)
+	result := worker.Run(context.Background(), job)
 
 	t.Logf("Executor error result: ExitCode=%d, Output=%q, Stderr=%q",
 		result.ExitCode, result.Output, result.Stderr)
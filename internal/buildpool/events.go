@@ -0,0 +1,91 @@
+// internal/buildpool/events.go
+package buildpool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Operational events of the coordinator written to an EventLog
+const (
+	EventWorkerConnected    = "worker_connected"    // worker_id, max_jobs
+	EventWorkerDisconnected = "worker_disconnected" // worker_id, requeued_jobs
+	EventJobDispatched      = "job_dispatched"      // job_id, worker_id, repo, commit, command
+	EventJobCompleted       = "job_completed"       // job_id, exit_code, duration_secs
+	EventError              = "error"               // message, worker_id if known
+)
+
+// Event is one record of an EventLog
+type Event struct {
+	Type   string         `json:"type"`
+	Time   time.Time      `json:"time"`
+	Fields map[string]any `json:"fields"`
+}
+
+// EventLog writes the coordinator's operational events as newline-delimited
+// JSON, one Event per line, for ingestion into a log pipeline. A nil
+// *EventLog discards events.
+type EventLog struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer // Sink opened by OpenEventLog (nil = not owned)
+	now    func() time.Time
+}
+
+// NewEventLog returns an EventLog writing to w
+func NewEventLog(w io.Writer) *EventLog {
+	return &EventLog{w: w, now: time.Now}
+}
+
+// OpenEventLog opens the event log sink of the build_pool.event_log setting:
+// "stderr", or a file that events are appended to. It returns nil for an
+// empty sink.
+func OpenEventLog(sink string) (*EventLog, error) {
+	switch sink {
+	case "":
+		return nil, nil
+	case "stderr":
+		return NewEventLog(os.Stderr), nil
+	}
+	f, err := os.OpenFile(sink, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	l := NewEventLog(f)
+	l.closer = f
+	return l, nil
+}
+
+// Emit writes an event of the given type. Fields must marshal to JSON.
+func (l *EventLog) Emit(eventType string, fields map[string]any) {
+	if l == nil {
+		return
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	data, err := json.Marshal(Event{Type: eventType, Time: l.now().UTC(), Fields: fields})
+	if err != nil {
+		data, _ = json.Marshal(Event{Type: EventError, Time: l.now().UTC(), Fields: map[string]any{
+			"message": fmt.Sprintf("encoding %s event: %v", eventType, err),
+		}})
+	}
+	l.w.Write(append(data, '\n'))
+}
+
+// Close closes a file sink opened by OpenEventLog
+func (l *EventLog) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closer.Close()
+}
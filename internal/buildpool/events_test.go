@@ -0,0 +1,231 @@
+// internal/buildpool/events_test.go
+package buildpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+// lockedBuffer is a bytes.Buffer safe to read while the coordinator writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// decodeEvents parses every line of an event log, failing on malformed ones
+func decodeEvents(t *testing.T, data string) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			t.Fatalf("malformed event %q: %v", line, err)
+		}
+		for _, key := range []string{"type", "time", "fields"} {
+			if _, ok := raw[key]; !ok {
+				t.Fatalf("event %q has no %q", line, key)
+			}
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("malformed event %q: %v", line, err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %q has no timestamp", line)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestEventLog_Emit(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewEventLog(&buf)
+	l.now = func() time.Time { return time.Date(2026, 1, 5, 10, 4, 12, 0, time.UTC) }
+
+	l.Emit(EventWorkerConnected, map[string]any{"worker_id": "w1", "max_jobs": 4})
+	l.Emit(EventError, nil)
+
+	want := `{"type":"worker_connected","time":"2026-01-05T10:04:12Z","fields":{"max_jobs":4,"worker_id":"w1"}}` + "\n" +
+		`{"type":"error","time":"2026-01-05T10:04:12Z","fields":{}}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestEventLog_Nil(t *testing.T) {
+	var l *EventLog
+	l.Emit(EventError, map[string]any{"message": "dropped"})
+	if err := l.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	l, err := OpenEventLog("")
+	if err != nil || l != nil {
+		t.Errorf("OpenEventLog(\"\") = %v, %v; want nil, nil", l, err)
+	}
+}
+
+func TestOpenEventLog_AppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(`{"type":"error","time":"2026-01-05T10:04:12Z","fields":{}}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := OpenEventLog(path)
+	if err != nil {
+		t.Fatalf("OpenEventLog: %v", err)
+	}
+	l.Emit(EventJobCompleted, map[string]any{"job_id": "j1", "exit_code": 0})
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := decodeEvents(t, string(data))
+	if len(events) != 2 || events[1].Type != EventJobCompleted {
+		t.Errorf("got %+v, want the existing event followed by job_completed", events)
+	}
+}
+
+func TestCoordinator_EmitsLifecycleEvents(t *testing.T) {
+	var buf lockedBuffer
+	coord := newTestCoordinator(CoordinatorConfig{
+		HeartbeatTimeout: 5 * time.Second,
+		Events:           NewEventLog(&buf),
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleWebSocket))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	registerMsg := `{"type":"register","payload":{"worker_id":"events-worker","max_jobs":2}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(registerMsg)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	waitFor(t, func() bool { return coord.Registry().Get("events-worker") != nil })
+
+	resultCh := coord.Dispatcher().Submit(&buildprotocol.JobMessage{
+		JobID:   "events-job",
+		Repo:    "git://localhost/repo",
+		Commit:  "abc123",
+		Command: "go test ./...",
+	})
+	coord.Dispatcher().TryDispatch()
+
+	// The worker receives the job and reports its completion
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("reading job: %v", err)
+	}
+	completeMsg := `{"type":"complete","payload":{"job_id":"events-job","exit_code":1,"duration_ms":1500}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(completeMsg)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	select {
+	case <-resultCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not complete")
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	conn.Close()
+	waitFor(t, func() bool { return strings.Contains(buf.String(), EventWorkerDisconnected) })
+
+	events := decodeEvents(t, buf.String())
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	want := []string{EventWorkerConnected, EventJobDispatched, EventJobCompleted, EventError, EventWorkerDisconnected}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+
+	checks := []map[string]any{
+		{"worker_id": "events-worker", "max_jobs": float64(2)},
+		{"job_id": "events-job", "worker_id": "events-worker", "repo": "git://localhost/repo", "commit": "abc123", "command": "go test ./..."},
+		{"job_id": "events-job", "exit_code": float64(1), "duration_secs": 1.5},
+		{"worker_id": "events-worker"},
+		{"worker_id": "events-worker", "requeued_jobs": float64(0)},
+	}
+	for i, fields := range checks {
+		for k, v := range fields {
+			if got := events[i].Fields[k]; got != v {
+				t.Errorf("%s: %s = %v, want %v", events[i].Type, k, got, v)
+			}
+		}
+	}
+	if msg, _ := events[3].Fields["message"].(string); !strings.HasPrefix(msg, "invalid message") {
+		t.Errorf("error message = %q", msg)
+	}
+}
+
+func TestCoordinator_EmitsEmbeddedDispatch(t *testing.T) {
+	var buf lockedBuffer
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		return &buildprotocol.JobResult{JobID: job.JobID, DurationSecs: 0.25}
+	})
+	NewCoordinator(CoordinatorConfig{Events: NewEventLog(&buf)}, registry, dispatcher)
+
+	resultCh := dispatcher.Submit(&buildprotocol.JobMessage{JobID: "local-job", Command: "make"})
+	dispatcher.TryDispatch()
+	<-resultCh
+
+	events := decodeEvents(t, buf.String())
+	if len(events) != 2 || events[0].Type != EventJobDispatched || events[1].Type != EventJobCompleted {
+		t.Fatalf("got %+v, want job_dispatched and job_completed", events)
+	}
+	if got := events[0].Fields["worker_id"]; got != embeddedWorkerID {
+		t.Errorf("worker_id = %v, want %s", got, embeddedWorkerID)
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,84 @@
+// internal/buildpool/idempotency.go
+package buildpool
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyWindow is how long a completed job's response is reused
+// for a resubmission carrying the same idempotency key
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// idempotentJob tracks a job submitted with an idempotency key
+type idempotentJob struct {
+	jobID       string
+	done        chan struct{} // Closed once resp is set
+	resp        JobResponse
+	completedAt time.Time
+}
+
+// idempotencyCache dedupes in-flight and recently completed HTTP job
+// submissions by idempotency key
+type idempotencyCache struct {
+	mu     sync.Mutex
+	window time.Duration
+	jobs   map[string]*idempotentJob
+}
+
+func newIdempotencyCache(window time.Duration) *idempotencyCache {
+	if window == 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &idempotencyCache{
+		window: window,
+		jobs:   make(map[string]*idempotentJob),
+	}
+}
+
+// acquire returns the entry for key, creating one for jobID if there is no
+// in-flight or unexpired entry. existing reports whether the entry was
+// already present, in which case the caller must not submit a new job.
+func (c *idempotencyCache) acquire(key, jobID string) (entry *idempotentJob, existing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneLocked(time.Now())
+
+	if entry, ok := c.jobs[key]; ok {
+		return entry, true
+	}
+
+	entry = &idempotentJob{jobID: jobID, done: make(chan struct{})}
+	c.jobs[key] = entry
+	return entry, false
+}
+
+// complete records the response for an entry and wakes up all waiters
+func (c *idempotencyCache) complete(key string, entry *idempotentJob, resp JobResponse) {
+	c.mu.Lock()
+	entry.resp = resp
+	entry.completedAt = time.Now()
+	c.mu.Unlock()
+
+	close(entry.done)
+}
+
+// forget drops an entry so the next submission with its key runs again
+func (c *idempotencyCache) forget(key string, entry *idempotentJob) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jobs[key] == entry {
+		delete(c.jobs, key)
+	}
+}
+
+// pruneLocked removes completed entries older than the window
+func (c *idempotencyCache) pruneLocked(now time.Time) {
+	for key, entry := range c.jobs {
+		if !entry.completedAt.IsZero() && now.Sub(entry.completedAt) > c.window {
+			delete(c.jobs, key)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package buildpool
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,7 +15,7 @@ func TestVerbosityIntegration(t *testing.T) {
 	registry := NewRegistry()
 
 	// Create mock embedded worker that returns 60 lines
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		var lines []string
 		for i := 1; i <= 60; i++ {
 			lines = append(lines, "stdout line")
@@ -0,0 +1,22 @@
+// internal/buildpool/jobid.go
+package buildpool
+
+import "strings"
+
+// Job ID sources, the first segment of every generated job ID
+const (
+	JobSourceHTTP = "http" // Submitted via POST /job (e.g. by build-mcp)
+	JobSourceMCP  = "mcp"  // Submitted by the in-process MCP server
+)
+
+// NewJobID returns a job ID of the form <source>-<task>-<random>, e.g.
+// "http-billing.E02-1f2e3d4c5b6a7980", or <source>-<random> if the job was
+// not submitted by an agent. The "/" in the task ID becomes "." so the job ID
+// can be used in URL paths such as /logs/{job_id}; the job's TaskID field
+// holds the task ID unchanged.
+func NewJobID(source, taskID string) string {
+	if taskID == "" {
+		return source + "-" + randomJobSuffix()
+	}
+	return source + "-" + strings.ReplaceAll(taskID, "/", ".") + "-" + randomJobSuffix()
+}
@@ -0,0 +1,28 @@
+// internal/buildpool/jobid_test.go
+package buildpool
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewJobID(t *testing.T) {
+	tests := []struct {
+		source, taskID string
+		pattern        string
+	}{
+		{JobSourceHTTP, "", `^http-[0-9a-f]{16}$`},
+		{JobSourceHTTP, "billing/E02", `^http-billing\.E02-[0-9a-f]{16}$`},
+		{JobSourceMCP, "cli/CLI03", `^mcp-cli\.CLI03-[0-9a-f]{16}$`},
+	}
+	for _, tt := range tests {
+		got := NewJobID(tt.source, tt.taskID)
+		if !regexp.MustCompile(tt.pattern).MatchString(got) {
+			t.Errorf("NewJobID(%q, %q) = %q, want match for %s", tt.source, tt.taskID, got, tt.pattern)
+		}
+	}
+
+	if NewJobID(JobSourceHTTP, "billing/E02") == NewJobID(JobSourceHTTP, "billing/E02") {
+		t.Error("NewJobID should return distinct IDs for the same task")
+	}
+}
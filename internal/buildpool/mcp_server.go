@@ -20,6 +20,7 @@ import (
 type MCPServerConfig struct {
 	WorktreePath string
 	GitDaemonURL string // Git daemon URL for remote workers (e.g., "git://host:9418/")
+	TaskID       string // Task of the agent using the server (defaults to $CLAUDE_ORCH_TASK_ID)
 }
 
 // MCPServer implements the MCP protocol for build tools
@@ -48,8 +49,44 @@ var verbositySchema = map[string]interface{}{
 	"default":     "normal",
 }
 
+// envSchema defines the env parameter for MCP tool schemas
+var envSchema = map[string]interface{}{
+	"type":                 "object",
+	"description":          "Extra environment variables for the command (allow-listed, e.g. RUSTFLAGS, RUST_BACKTRACE)",
+	"additionalProperties": map[string]interface{}{"type": "string"},
+}
+
+// useNixSchema defines the use_nix parameter for MCP tool schemas
+var useNixSchema = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Run the command in nix develop (default: the worker's setting); false skips nix startup for commands that don't need it",
+}
+
+// prioritySchema defines the priority parameter for MCP tool schemas
+var prioritySchema = map[string]interface{}{
+	"type":        "integer",
+	"description": "Queue priority: higher runs before queued jobs of lower priority (default 0)",
+}
+
+// noCacheSchema defines the no_cache parameter for MCP tool schemas
+var noCacheSchema = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Run the command even if a successful result for this commit is cached",
+}
+
+// mcpBuildSystem is the build system the job tools run (see buildCommand)
+const mcpBuildSystem = "cargo"
+
+// mcpDefaultJobTimeoutSecs is the timeout workers apply to jobs submitted
+// without one
+const mcpDefaultJobTimeoutSecs = 300
+
 // NewMCPServer creates a new MCP server
 func NewMCPServer(config MCPServerConfig, dispatcher *Dispatcher, registry *Registry) *MCPServer {
+	if config.TaskID == "" {
+		config.TaskID = os.Getenv(buildprotocol.TaskIDEnv)
+	}
+
 	s := &MCPServer{
 		config:     config,
 		dispatcher: dispatcher,
@@ -110,6 +147,10 @@ func (s *MCPServer) ListTools() []MCPTool {
 					"release":   map[string]interface{}{"type": "boolean", "description": "Build in release mode"},
 					"features":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
 					"package":   map[string]interface{}{"type": "string", "description": "Specific package to build"},
+					"env":       envSchema,
+					"use_nix":   useNixSchema,
+					"priority":  prioritySchema,
+					"no_cache":  noCacheSchema,
 					"verbosity": verbositySchema,
 				},
 			},
@@ -122,6 +163,10 @@ func (s *MCPServer) ListTools() []MCPTool {
 				"properties": map[string]interface{}{
 					"fix":       map[string]interface{}{"type": "boolean", "description": "Apply suggested fixes"},
 					"features":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"env":       envSchema,
+					"use_nix":   useNixSchema,
+					"priority":  prioritySchema,
+					"no_cache":  noCacheSchema,
 					"verbosity": verbositySchema,
 				},
 			},
@@ -136,6 +181,10 @@ func (s *MCPServer) ListTools() []MCPTool {
 					"package":   map[string]interface{}{"type": "string", "description": "Specific package to test"},
 					"features":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
 					"nocapture": map[string]interface{}{"type": "boolean", "description": "Show stdout/stderr"},
+					"env":       envSchema,
+					"use_nix":   useNixSchema,
+					"priority":  prioritySchema,
+					"no_cache":  noCacheSchema,
 					"verbosity": verbositySchema,
 				},
 			},
@@ -148,6 +197,9 @@ func (s *MCPServer) ListTools() []MCPTool {
 				"properties": map[string]interface{}{
 					"command":      map[string]interface{}{"type": "string", "description": "Command to run"},
 					"timeout_secs": map[string]interface{}{"type": "integer", "description": "Timeout in seconds"},
+					"env":          envSchema,
+					"use_nix":      useNixSchema,
+					"priority":     prioritySchema,
 					"verbosity":    verbositySchema,
 				},
 				"required": []string{"command"},
@@ -160,6 +212,13 @@ func (s *MCPServer) ListTools() []MCPTool {
 				"type": "object",
 			},
 		},
+		{
+			Name:        "pool_info",
+			Description: "Get the build pool's configuration: build system, available tools and default job timeouts",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+			},
+		},
 		{
 			Name:        "get_job_logs",
 			Description: "Retrieve complete logs for a completed job from retention buffer",
@@ -180,6 +239,20 @@ func (s *MCPServer) ListTools() []MCPTool {
 				"required": []string{"job_id"},
 			},
 		},
+		{
+			Name:        "cancel_job",
+			Description: "Cancel a queued or running job, killing its process on the worker. Reports the final status: cancelled, or completed if the job finished first",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"job_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The job ID to cancel",
+					},
+				},
+				"required": []string{"job_id"},
+			},
+		},
 	}
 }
 
@@ -280,10 +353,20 @@ func (s *MCPServer) CallTool(name string, args map[string]interface{}) (*buildpr
 	case "get_job_logs":
 		// Retrieve logs from retention buffer
 		return s.getJobLogs(args)
+	case "cancel_job":
+		return s.cancelJob(args)
+	case "pool_info":
+		// Describe the pool without dispatching a job
+		return s.poolInfo()
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
 
+	env, err := parseEnvArg(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract verbosity from args, default to minimal
 	verbosity := buildprotocol.VerbosityMinimal
 	if v, ok := args["verbosity"].(string); ok && v != "" {
@@ -299,13 +382,18 @@ func (s *MCPServer) CallTool(name string, args map[string]interface{}) (*buildpr
 		repoURL = s.config.GitDaemonURL
 	}
 
-	jobID := fmt.Sprintf("mcp-%s", randomJobSuffix())
+	jobID := NewJobID(JobSourceMCP, s.config.TaskID)
 	job := &buildprotocol.JobMessage{
-		JobID:   jobID,
-		Repo:    repoURL,
-		Commit:  s.commit,
-		Command: command,
-		Timeout: timeout,
+		JobID:     jobID,
+		Repo:      repoURL,
+		Commit:    s.commit,
+		Command:   command,
+		Env:       env,
+		Timeout:   timeout,
+		TaskID:    s.config.TaskID,
+		UseNix:    parseUseNixArg(args),
+		Priority:  parsePriorityArg(args),
+		Cacheable: cacheableTool(name, args),
 	}
 
 	// Submit to dispatcher with verbosity
@@ -327,6 +415,61 @@ func (s *MCPServer) CallTool(name string, args map[string]interface{}) (*buildpr
 	return result, nil
 }
 
+// parseUseNixArg returns the optional use_nix argument, or nil to leave the
+// choice to the worker
+func parseUseNixArg(args map[string]interface{}) *bool {
+	useNix, ok := args["use_nix"].(bool)
+	if !ok {
+		return nil
+	}
+	return &useNix
+}
+
+// cacheableTool reports whether the result of a tool call may come from the
+// result cache: build, clippy and test are unless the caller passes no_cache.
+// clippy --fix and arbitrary commands may change files, so they always run.
+func cacheableTool(tool string, args map[string]interface{}) bool {
+	if noCache, _ := args["no_cache"].(bool); noCache {
+		return false
+	}
+	switch tool {
+	case "build", "test":
+		return true
+	case "clippy":
+		fix, _ := args["fix"].(bool)
+		return !fix
+	}
+	return false
+}
+
+// parsePriorityArg returns the optional priority argument (default 0)
+func parsePriorityArg(args map[string]interface{}) int {
+	priority, _ := args["priority"].(float64)
+	return int(priority)
+}
+
+// parseEnvArg extracts the optional env argument and checks it against the allow-list
+func parseEnvArg(args map[string]interface{}) (map[string]string, error) {
+	raw, ok := args["env"].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, nil
+	}
+
+	env := make(map[string]string, len(raw))
+	for name, v := range raw {
+		value, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("env %s: value must be a string", name)
+		}
+		env[name] = value
+	}
+
+	if err := buildprotocol.ValidateJobEnv(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
 func (s *MCPServer) workerStatus() (*buildprotocol.JobResult, error) {
 	workers := []map[string]interface{}{}
 
@@ -343,14 +486,17 @@ func (s *MCPServer) workerStatus() (*buildprotocol.JobResult, error) {
 	}
 
 	queuedJobs := 0
+	var cacheStats CacheStats
 	if s.dispatcher != nil {
 		queuedJobs = s.dispatcher.QueueLength()
+		cacheStats = s.dispatcher.CacheStats()
 	}
 
 	status := map[string]interface{}{
 		"workers":               workers,
 		"queued_jobs":           queuedJobs,
 		"local_fallback_active": s.registry == nil || s.registry.Count() == 0,
+		"result_cache":          cacheStats,
 	}
 
 	output, _ := json.MarshalIndent(status, "", "  ")
@@ -362,6 +508,34 @@ func (s *MCPServer) workerStatus() (*buildprotocol.JobResult, error) {
 	}, nil
 }
 
+// poolInfo describes what the pool offers, so agents can pick the right tools
+func (s *MCPServer) poolInfo() (*buildprotocol.JobResult, error) {
+	var tools []string
+	for _, tool := range s.ListTools() {
+		tools = append(tools, tool.Name)
+	}
+
+	// Jobs are submitted without a timeout, so workers apply their default
+	timeouts := map[string]int{}
+	for _, tool := range []string{"build", "clippy", "test", "run_command"} {
+		timeouts[tool] = mcpDefaultJobTimeoutSecs
+	}
+
+	info := map[string]interface{}{
+		"build_system":         mcpBuildSystem,
+		"tools":                tools,
+		"default_timeout_secs": timeouts,
+	}
+
+	output, _ := json.MarshalIndent(info, "", "  ")
+
+	return &buildprotocol.JobResult{
+		JobID:    "pool_info",
+		ExitCode: 0,
+		Output:   string(output),
+	}, nil
+}
+
 func (s *MCPServer) getJobLogs(args map[string]interface{}) (*buildprotocol.JobResult, error) {
 	jobID, _ := args["job_id"].(string)
 	if jobID == "" {
@@ -414,6 +588,37 @@ func (s *MCPServer) getJobLogs(args map[string]interface{}) (*buildprotocol.JobR
 	}, nil
 }
 
+// cancelJob cancels a job through the coordinator and reports its final status
+func (s *MCPServer) cancelJob(args map[string]interface{}) (*buildprotocol.JobResult, error) {
+	jobID, _ := args["job_id"].(string)
+	if jobID == "" {
+		return &buildprotocol.JobResult{
+			ExitCode: 1,
+			Output:   "job_id is required",
+		}, nil
+	}
+
+	if s.coordinator == nil {
+		return &buildprotocol.JobResult{
+			ExitCode: 1,
+			Output:   "no coordinator configured for job cancellation",
+		}, nil
+	}
+
+	resp, err := s.coordinator.CancelJob(jobID)
+	exitCode := 0
+	if err != nil {
+		exitCode = 1
+	}
+	output, _ := json.MarshalIndent(resp, "", "  ")
+
+	return &buildprotocol.JobResult{
+		JobID:    jobID,
+		ExitCode: exitCode,
+		Output:   string(output),
+	}, nil
+}
+
 // Run starts the MCP server on stdin/stdout
 func (s *MCPServer) Run() error {
 	reader := bufio.NewReader(os.Stdin)
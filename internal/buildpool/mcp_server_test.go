@@ -2,7 +2,10 @@
 package buildpool
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,7 +22,7 @@ func TestMCPServer_ToolsList(t *testing.T) {
 
 	tools := server.ListTools()
 
-	expectedTools := []string{"build", "clippy", "test", "run_command", "worker_status", "get_job_logs"}
+	expectedTools := []string{"build", "clippy", "test", "run_command", "worker_status", "pool_info", "get_job_logs", "cancel_job"}
 
 	if len(tools) != len(expectedTools) {
 		t.Errorf("got %d tools, want %d", len(tools), len(expectedTools))
@@ -225,8 +228,8 @@ func TestMCPServer_HandleRequest_ToolsList(t *testing.T) {
 		t.Fatalf("expected tools to be []MCPTool")
 	}
 
-	if len(tools) != 6 {
-		t.Errorf("expected 6 tools, got %d", len(tools))
+	if len(tools) != 8 {
+		t.Errorf("expected 8 tools, got %d", len(tools))
 	}
 }
 
@@ -278,6 +281,41 @@ func TestMCPServer_WorkerStatus(t *testing.T) {
 	}
 }
 
+func TestMCPServer_PoolInfo(t *testing.T) {
+	server := NewMCPServer(MCPServerConfig{
+		WorktreePath: "/tmp/test-worktree",
+	}, nil, nil)
+
+	// Answered without a dispatcher, as no job runs
+	result, err := server.CallTool("pool_info", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var info struct {
+		BuildSystem    string         `json:"build_system"`
+		Tools          []string       `json:"tools"`
+		DefaultTimeout map[string]int `json:"default_timeout_secs"`
+	}
+	if err := json.Unmarshal([]byte(result.Output), &info); err != nil {
+		t.Fatalf("output should be valid JSON: %v", err)
+	}
+
+	if info.BuildSystem != "cargo" {
+		t.Errorf("build_system = %q, want cargo", info.BuildSystem)
+	}
+	var names []string
+	for _, tool := range server.ListTools() {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(info.Tools, ",") != strings.Join(names, ",") {
+		t.Errorf("tools = %v, want %v", info.Tools, names)
+	}
+	if info.DefaultTimeout["test"] != 300 {
+		t.Errorf("default test timeout = %d, want 300", info.DefaultTimeout["test"])
+	}
+}
+
 func TestMCPServer_CallTool_NoDispatcher(t *testing.T) {
 	server := NewMCPServer(MCPServerConfig{
 		WorktreePath: "/tmp/test-worktree",
@@ -496,11 +534,56 @@ func TestMCPServer_GetJobLogsNotFound(t *testing.T) {
 	}
 }
 
+func TestMCPServer_CancelJob(t *testing.T) {
+	registry := NewRegistry()
+	started := make(chan struct{})
+	embedded := func(ctx context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		close(started)
+		<-ctx.Done()
+		return &buildprotocol.JobResult{JobID: job.JobID, ExitCode: -1}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "."}, dispatcher, registry)
+
+	// Without a coordinator there is nothing to cancel through
+	result, err := server.CallTool("cancel_job", map[string]interface{}{"job_id": "job-1"})
+	if err != nil || result.ExitCode != 1 {
+		t.Fatalf("cancel without coordinator = %+v, %v; want exit code 1", result, err)
+	}
+	server.SetCoordinator(coord)
+
+	resultCh := dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-1", Command: "cargo build"})
+	dispatcher.TryDispatch()
+	<-started
+
+	result, err = server.CallTool("cancel_job", map[string]interface{}{"job_id": "job-1"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	var resp CancelResponse
+	if err := json.Unmarshal([]byte(result.Output), &resp); err != nil {
+		t.Fatalf("output should be JSON: %v\n%s", err, result.Output)
+	}
+	if result.ExitCode != 0 || resp.Status != JobStatusCancelled {
+		t.Errorf("cancel_job = %+v (%s), want cancelled", resp, result.Output)
+	}
+	if r := <-resultCh; r.ExitCode != cancelledExitCode {
+		t.Errorf("job exit code = %d, want %d", r.ExitCode, cancelledExitCode)
+	}
+
+	result, _ = server.CallTool("cancel_job", map[string]interface{}{"job_id": "unknown"})
+	if result.ExitCode != 1 {
+		t.Errorf("exit code for unknown job = %d, want 1", result.ExitCode)
+	}
+}
+
 func TestMCPServer_VerbosityPassthrough(t *testing.T) {
 	registry := NewRegistry()
 
 	// Create embedded worker that returns predictable output
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: 0,
@@ -531,11 +614,69 @@ func TestMCPServer_VerbosityPassthrough(t *testing.T) {
 	}
 }
 
+func TestMCPServer_JobsCarryTaskIDFromEnv(t *testing.T) {
+	t.Setenv(buildprotocol.TaskIDEnv, "billing/E02")
+
+	registry := NewRegistry()
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		return &buildprotocol.JobResult{JobID: job.JobID, Stdout: "ok\n"}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "."}, dispatcher, registry)
+	server.SetCoordinator(coord)
+
+	result, err := server.CallTool("run_command", map[string]interface{}{"command": "echo test"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if !strings.HasPrefix(result.JobID, "mcp-billing.E02-") {
+		t.Errorf("JobID = %q, want prefix mcp-billing.E02-", result.JobID)
+	}
+
+	logs := httptest.NewServer(http.HandlerFunc(coord.HandleGetLogs))
+	defer logs.Close()
+
+	retained, err := FetchRetainedJob(context.Background(), logs.URL, result.JobID)
+	if err != nil {
+		t.Fatalf("FetchRetainedJob: %v", err)
+	}
+	if retained.TaskID != "billing/E02" {
+		t.Errorf("retained TaskID = %q, want billing/E02", retained.TaskID)
+	}
+}
+
+func TestMCPServer_PriorityPassthrough(t *testing.T) {
+	registry := NewRegistry()
+	var gotPriority int
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		gotPriority = job.Priority
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "."}, dispatcher, registry)
+
+	// JSON numbers arrive as float64
+	if _, err := server.CallTool("run_command", map[string]interface{}{"command": "echo test", "priority": float64(10)}); err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if gotPriority != 10 {
+		t.Errorf("job priority = %d, want 10", gotPriority)
+	}
+
+	for _, tool := range server.ListTools() {
+		props, _ := tool.InputSchema["properties"].(map[string]interface{})
+		if _, isJob := props["verbosity"]; isJob && props["priority"] == nil {
+			t.Errorf("tool %s has no priority parameter", tool.Name)
+		}
+	}
+}
+
 func TestMCPServer_VerbosityPassthrough_Normal(t *testing.T) {
 	registry := NewRegistry()
 
 	// Create embedded worker that returns predictable output
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: 0,
@@ -570,7 +711,7 @@ func TestMCPServer_VerbosityPassthrough_Full(t *testing.T) {
 	registry := NewRegistry()
 
 	// Create embedded worker that returns predictable output
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: 0,
@@ -605,7 +746,7 @@ func TestMCPServer_VerbosityPassthrough_Default(t *testing.T) {
 	registry := NewRegistry()
 
 	// Create embedded worker that returns predictable output
-	embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 		return &buildprotocol.JobResult{
 			JobID:    job.JobID,
 			ExitCode: 0,
@@ -907,6 +1048,92 @@ func TestMCPServer_LocalWorkerWithUnpushedCommits(t *testing.T) {
 	}
 }
 
+func TestMCPServer_EnvReachesEmbeddedWorker(t *testing.T) {
+	repoDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	cmds := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("setup command %v failed: %s: %v", args, out, err)
+		}
+	}
+
+	embedded := NewEmbeddedWorker(EmbeddedConfig{
+		RepoDir:     repoDir,
+		WorktreeDir: worktreeDir,
+		MaxJobs:     1,
+		UseNixShell: false,
+	})
+
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, embedded.Run)
+	server := NewMCPServer(MCPServerConfig{WorktreePath: repoDir}, dispatcher, registry)
+
+	result, err := server.CallTool("run_command", map[string]interface{}{
+		"command":   `echo "flags=$RUSTFLAGS"`,
+		"env":       map[string]interface{}{"RUSTFLAGS": "--cfg feature_x"},
+		"verbosity": "full",
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+
+	if result.ExitCode != 0 {
+		t.Fatalf("exit code = %d, want 0. Output: %s", result.ExitCode, result.Output)
+	}
+	if !strings.Contains(result.Stdout, "flags=--cfg feature_x") {
+		t.Errorf("stdout = %q, want it to contain the RUSTFLAGS value", result.Stdout)
+	}
+}
+
+func TestMCPServer_EnvRejectsDisallowedVariable(t *testing.T) {
+	called := false
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		called = true
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, embedded)
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "."}, dispatcher, registry)
+
+	_, err := server.CallTool("build", map[string]interface{}{
+		"env": map[string]interface{}{"LD_PRELOAD": "/tmp/evil.so"},
+	})
+	if err == nil {
+		t.Fatal("expected error for disallowed env variable")
+	}
+	if !strings.Contains(err.Error(), "LD_PRELOAD") {
+		t.Errorf("error = %v, want it to name LD_PRELOAD", err)
+	}
+	if called {
+		t.Error("job should not be dispatched when env is rejected")
+	}
+}
+
+func TestMCPServer_ToolsHaveEnvParam(t *testing.T) {
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "/tmp/test-worktree"}, nil, nil)
+
+	withEnv := map[string]bool{"build": true, "clippy": true, "test": true, "run_command": true}
+	for _, tool := range server.ListTools() {
+		if !withEnv[tool.Name] {
+			continue
+		}
+		props, _ := tool.InputSchema["properties"].(map[string]interface{})
+		if _, ok := props["env"]; !ok {
+			t.Errorf("tool %s: missing env property", tool.Name)
+		}
+	}
+}
+
 func TestMCPServer_ErrorOutputFormatting(t *testing.T) {
 	// Test that MCP server properly formats error messages for non-zero exit codes
 	// This verifies the "[Exit code: N]" prefix and "(no output captured)" fallback
@@ -950,7 +1177,7 @@ func TestMCPServer_ErrorOutputFormatting(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create embedded worker that returns the test result
-			embedded := func(job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+			embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
 				return &buildprotocol.JobResult{
 					JobID:    job.JobID,
 					ExitCode: tt.exitCode,
@@ -1102,3 +1329,42 @@ func TestMCPServer_LocalWorkerFromWorktree(t *testing.T) {
 		t.Errorf("output should contain README content, got: %s", result.Output)
 	}
 }
+
+func TestMCPServer_CacheableTools(t *testing.T) {
+	registry := NewRegistry()
+	var jobs []*buildprotocol.JobMessage
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		jobs = append(jobs, job)
+		return &buildprotocol.JobResult{JobID: job.JobID}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	server := NewMCPServer(MCPServerConfig{WorktreePath: "."}, dispatcher, registry)
+
+	calls := []struct {
+		tool string
+		args map[string]interface{}
+		want bool
+	}{
+		{"clippy", map[string]interface{}{}, true},
+		{"clippy", map[string]interface{}{"no_cache": true}, false},
+		{"clippy", map[string]interface{}{"fix": true}, false},
+		{"test", map[string]interface{}{}, true},
+		{"run_command", map[string]interface{}{"command": "echo test"}, false},
+	}
+	for i, c := range calls {
+		if _, err := server.CallTool(c.tool, c.args); err != nil {
+			t.Fatalf("CallTool(%s): %v", c.tool, err)
+		}
+		if got := jobs[i].Cacheable; got != c.want {
+			t.Errorf("%s %v: Cacheable = %v, want %v", c.tool, c.args, got, c.want)
+		}
+	}
+
+	for _, tool := range server.ListTools() {
+		props, _ := tool.InputSchema["properties"].(map[string]interface{})
+		_, hasNoCache := props["no_cache"]
+		if cacheable := tool.Name == "build" || tool.Name == "clippy" || tool.Name == "test"; hasNoCache != cacheable {
+			t.Errorf("tool %s: has no_cache = %v, want %v", tool.Name, hasNoCache, cacheable)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// internal/buildpool/metrics.go
+package buildpool
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+// jobDurationBuckets are the upper bounds in seconds of the job duration
+// histogram, from quick checks to full release builds
+var jobDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600}
+
+// jobMetrics counts finished jobs for the /metrics endpoint. Everything else
+// exported there is read from the registry and dispatcher when scraped.
+type jobMetrics struct {
+	mu        sync.Mutex
+	completed uint64   // Exit code 0
+	failed    uint64   // Any other exit code, cancellations excluded
+	cancelled uint64   // Cancelled while queued or running
+	buckets   []uint64 // Per jobDurationBuckets entry, not cumulative
+	count     uint64   // Jobs in the duration histogram
+	sum       float64  // Their total duration in seconds
+}
+
+func newJobMetrics() *jobMetrics {
+	return &jobMetrics{buckets: make([]uint64, len(jobDurationBuckets))}
+}
+
+// observe records a finished job. Only jobs that ran enter the duration
+// histogram: cancelled jobs are cut short and cached results took no time.
+func (m *jobMetrics) observe(result *buildprotocol.JobResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch result.ExitCode {
+	case 0:
+		m.completed++
+	case cancelledExitCode:
+		m.cancelled++
+		return
+	default:
+		m.failed++
+	}
+	if result.Cached {
+		return
+	}
+
+	m.count++
+	m.sum += result.DurationSecs
+	for i, bound := range jobDurationBuckets {
+		if result.DurationSecs <= bound {
+			m.buckets[i]++
+			break
+		}
+	}
+}
+
+// HandleMetrics serves build pool metrics in the Prometheus text exposition
+// format (GET /metrics). Responds 404 unless CoordinatorConfig.Metrics is set.
+func (c *Coordinator) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !c.config.Metrics {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.writeMetrics(w)
+}
+
+// writeMetrics writes the current metrics in the Prometheus text format
+func (c *Coordinator) writeMetrics(w io.Writer) {
+	workers := c.registry.All()
+	totalSlots, busySlots := 0, 0
+	for _, worker := range workers {
+		maxJobs, slots, _ := worker.GetStatus()
+		totalSlots += maxJobs
+		busySlots += maxJobs - slots
+	}
+	running := 0
+	for _, job := range c.dispatcher.Jobs() {
+		if job.State == JobStateRunning {
+			running++
+		}
+	}
+
+	writeMetric(w, "buildpool_workers_connected", "gauge", "Build workers connected to the coordinator.", uint64(len(workers)))
+	writeMetric(w, "buildpool_worker_slots", "gauge", "Job slots of the connected workers.", uint64(totalSlots))
+	writeMetric(w, "buildpool_worker_slots_busy", "gauge", "Job slots of the connected workers running a job.", uint64(busySlots))
+	writeMetric(w, "buildpool_queue_depth", "gauge", "Jobs waiting for a worker.", uint64(c.dispatcher.QueuedCount()))
+	writeMetric(w, "buildpool_jobs_running", "gauge", "Jobs running on a worker or the local fallback.", uint64(running))
+	writeMetric(w, "buildpool_jobs_submitted_total", "counter", "Jobs submitted to the coordinator.", c.dispatcher.SubmittedCount())
+
+	m := c.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeMetric(w, "buildpool_jobs_completed_total", "counter", "Jobs that finished with exit code 0.", m.completed)
+	writeMetric(w, "buildpool_jobs_failed_total", "counter", "Jobs that finished with a nonzero exit code.", m.failed)
+	writeMetric(w, "buildpool_jobs_cancelled_total", "counter", "Jobs cancelled while queued or running.", m.cancelled)
+
+	const name = "buildpool_job_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of jobs that ran to completion, cached results excluded.\n", name)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	var cumulative uint64
+	for i, bound := range jobDurationBuckets {
+		cumulative += m.buckets[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, m.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(m.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, m.count)
+}
+
+// writeMetric writes a single unlabelled gauge or counter
+func writeMetric(w io.Writer, name, kind, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
@@ -0,0 +1,109 @@
+// internal/buildpool/metrics_test.go
+package buildpool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+func TestCoordinator_HandleMetrics(t *testing.T) {
+	registry := NewRegistry()
+	worker1 := &ConnectedWorker{ID: "worker-1", MaxJobs: 4, Slots: 4}
+	worker2 := &ConnectedWorker{ID: "worker-2", MaxJobs: 2, Slots: 2}
+	registry.Register(worker1)
+	registry.Register(worker2)
+
+	dispatcher := NewDispatcher(registry, nil)
+	coord := NewCoordinator(CoordinatorConfig{Metrics: true}, registry, dispatcher)
+	dispatcher.SetSendFunc(func(*ConnectedWorker, *buildprotocol.JobMessage) error { return nil })
+
+	// Three jobs finish, one is cancelled and one waits for a tagged worker
+	for i, result := range []buildprotocol.JobResult{
+		{ExitCode: 0, DurationSecs: 3},
+		{ExitCode: 101, DurationSecs: 45},
+		{ExitCode: 0, DurationSecs: 45, Cached: true},
+	} {
+		job := &buildprotocol.JobMessage{JobID: fmt.Sprintf("job-%d", i), Command: "cargo test"}
+		dispatcher.Submit(job)
+		dispatcher.TryDispatch()
+		result.JobID = job.JobID
+		dispatcher.Complete(job.JobID, &result)
+	}
+	dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-cancel", Command: "cargo build", RequiredTags: []string{"gpu"}})
+	dispatcher.Cancel("job-cancel")
+	dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-queued", Command: "cargo build", RequiredTags: []string{"gpu"}})
+
+	// Workers report their free slots in heartbeats
+	worker1.UpdateSlots(1)
+	worker2.UpdateSlots(2)
+
+	rec := httptest.NewRecorder()
+	coord.HandleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE buildpool_workers_connected gauge\nbuildpool_workers_connected 2\n",
+		"buildpool_worker_slots 6\n",
+		"buildpool_worker_slots_busy 3\n",
+		"buildpool_queue_depth 1\n",
+		"buildpool_jobs_running 0\n",
+		"# TYPE buildpool_jobs_submitted_total counter\nbuildpool_jobs_submitted_total 5\n",
+		"buildpool_jobs_completed_total 2\n",
+		"buildpool_jobs_failed_total 1\n",
+		"buildpool_jobs_cancelled_total 1\n",
+		"# TYPE buildpool_job_duration_seconds histogram\n",
+		`buildpool_job_duration_seconds_bucket{le="1"} 0` + "\n",
+		`buildpool_job_duration_seconds_bucket{le="5"} 1` + "\n",
+		`buildpool_job_duration_seconds_bucket{le="30"} 1` + "\n",
+		`buildpool_job_duration_seconds_bucket{le="60"} 2` + "\n",
+		`buildpool_job_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"buildpool_job_duration_seconds_sum 48\n",
+		"buildpool_job_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestCoordinator_MetricsDisabledByDefault(t *testing.T) {
+	registry := NewRegistry()
+	coord := NewCoordinator(CoordinatorConfig{}, registry, NewDispatcher(registry, nil))
+
+	rec := httptest.NewRecorder()
+	coord.HandleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without the metrics flag", rec.Code)
+	}
+}
+
+func TestCoordinator_MetricsCountEmbeddedJobs(t *testing.T) {
+	registry := NewRegistry()
+	embedded := func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		return &buildprotocol.JobResult{JobID: job.JobID, DurationSecs: 2}
+	}
+	dispatcher := NewDispatcher(registry, embedded)
+	coord := NewCoordinator(CoordinatorConfig{Metrics: true}, registry, dispatcher)
+
+	ch := dispatcher.Submit(&buildprotocol.JobMessage{JobID: "job-1", Command: "cargo build"})
+	dispatcher.TryDispatch()
+	<-ch
+
+	rec := httptest.NewRecorder()
+	coord.HandleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "buildpool_jobs_completed_total 1\n") {
+		t.Errorf("embedded job not counted:\n%s", body)
+	}
+}
@@ -4,6 +4,7 @@
 package buildpool
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ type ConnectedWorker struct {
 	ID            string
 	MaxJobs       int
 	Slots         int
+	Tags          []string // Capabilities from the worker's registration (immutable)
 	Conn          *websocket.Conn
 	ConnectedAt   time.Time
 	LastHeartbeat time.Time
@@ -52,6 +54,16 @@ func (w *ConnectedWorker) SetLastHeartbeat(t time.Time) {
 	w.LastHeartbeat = t
 }
 
+// HasTags reports whether the worker has all required tags
+func (w *ConnectedWorker) HasTags(required []string) bool {
+	for _, tag := range required {
+		if !slices.Contains(w.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetStatus returns a snapshot of worker status fields (thread-safe)
 func (w *ConnectedWorker) GetStatus() (maxJobs, slots int, connectedAt time.Time) {
 	w.mu.Lock()
@@ -111,12 +123,21 @@ func (r *Registry) Count() int {
 
 // FindReady returns a worker with available slots, preferring workers with more slots
 func (r *Registry) FindReady() *ConnectedWorker {
+	return r.FindReadyWithTags(nil)
+}
+
+// FindReadyWithTags returns a worker having all required tags with available
+// slots, preferring workers with more slots
+func (r *Registry) FindReadyWithTags(required []string) *ConnectedWorker {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var best *ConnectedWorker
 	var bestSlots int
 	for _, w := range r.workers {
+		if !w.HasTags(required) {
+			continue
+		}
 		w.mu.Lock()
 		slots := w.Slots
 		w.mu.Unlock()
@@ -129,6 +150,20 @@ func (r *Registry) FindReady() *ConnectedWorker {
 	return best
 }
 
+// AnyWithTags reports whether a connected worker, busy or not, has all
+// required tags
+func (r *Registry) AnyWithTags(required []string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, w := range r.workers {
+		if w.HasTags(required) {
+			return true
+		}
+	}
+	return false
+}
+
 // All returns all connected workers
 func (r *Registry) All() []*ConnectedWorker {
 	r.mu.RLock()
@@ -53,3 +53,35 @@ func TestRegistry_FindReady(t *testing.T) {
 		t.Errorf("got worker %s, want worker-3", ready.ID)
 	}
 }
+
+func TestRegistry_FindReadyWithTags(t *testing.T) {
+	reg := NewRegistry()
+
+	reg.Register(&ConnectedWorker{ID: "cpu", MaxJobs: 8, Slots: 8})
+	reg.Register(&ConnectedWorker{ID: "gpu-busy", MaxJobs: 2, Slots: 0, Tags: []string{"gpu", "highmem"}})
+	reg.Register(&ConnectedWorker{ID: "gpu", MaxJobs: 2, Slots: 1, Tags: []string{"gpu"}})
+
+	tests := []struct {
+		required  []string
+		wantReady string // "" = none
+		wantAny   bool
+	}{
+		{nil, "cpu", true},
+		{[]string{"gpu"}, "gpu", true},
+		{[]string{"gpu", "highmem"}, "", true},
+		{[]string{"tpu"}, "", false},
+	}
+	for _, tt := range tests {
+		ready := reg.FindReadyWithTags(tt.required)
+		got := ""
+		if ready != nil {
+			got = ready.ID
+		}
+		if got != tt.wantReady {
+			t.Errorf("FindReadyWithTags(%v) = %q, want %q", tt.required, got, tt.wantReady)
+		}
+		if any := reg.AnyWithTags(tt.required); any != tt.wantAny {
+			t.Errorf("AnyWithTags(%v) = %v, want %v", tt.required, any, tt.wantAny)
+		}
+	}
+}
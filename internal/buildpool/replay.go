@@ -0,0 +1,56 @@
+// internal/buildpool/replay.go
+package buildpool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+// FetchRetainedJob retrieves a completed job's metadata from the coordinator's retained logs
+func FetchRetainedJob(ctx context.Context, buildPoolURL, jobID string) (*buildprotocol.JobMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, buildPoolURL+"/logs/"+jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to coordinator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var logs LogsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("job %s: %s", jobID, logs.Error)
+	}
+	if logs.Job == nil {
+		return nil, fmt.Errorf("job %s: no job metadata retained", jobID)
+	}
+
+	return logs.Job, nil
+}
+
+// ReplayJob runs a previously executed job again on a local embedded worker.
+// If localRepo is set, it replaces the job's repo (which may point at the git daemon)
+// so the commit is checked out from the local repository.
+func ReplayJob(job *buildprotocol.JobMessage, localRepo string, config EmbeddedConfig) *buildprotocol.JobResult {
+	replay := *job
+	replay.JobID = "replay-" + job.JobID
+	if localRepo != "" && replay.Repo != "" {
+		replay.Repo = localRepo
+	}
+
+	if config.MaxJobs == 0 {
+		config.MaxJobs = 1
+	}
+
+	return NewEmbeddedWorker(config).Run(context.Background(), &replay)
+}
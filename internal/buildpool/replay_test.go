@@ -0,0 +1,93 @@
+package buildpool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+func TestReplay_ReconstructsJobFromRetainedLogs(t *testing.T) {
+	registry := NewRegistry()
+	dispatcher := NewDispatcher(registry, func(_ context.Context, job *buildprotocol.JobMessage) *buildprotocol.JobResult {
+		return &buildprotocol.JobResult{
+			JobID:    job.JobID,
+			ExitCode: 1,
+			Stdout:   "building...\n",
+			Stderr:   "build failed\n",
+		}
+	})
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, dispatcher)
+
+	job := &buildprotocol.JobMessage{
+		JobID:   "job-failed-1",
+		Command: `echo "replayed $REPLAY_VAR"`,
+		Env:     map[string]string{"REPLAY_VAR": "from-env"},
+		Timeout: 30,
+	}
+	resultCh := dispatcher.SubmitWithVerbosity(job, buildprotocol.VerbosityMinimal)
+	dispatcher.TryDispatch()
+	<-resultCh
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleGetLogs))
+	defer server.Close()
+
+	retained, err := FetchRetainedJob(context.Background(), server.URL, "job-failed-1")
+	if err != nil {
+		t.Fatalf("FetchRetainedJob() error = %v", err)
+	}
+	if retained.Command != job.Command {
+		t.Errorf("Command = %q, want %q", retained.Command, job.Command)
+	}
+	if retained.Env["REPLAY_VAR"] != "from-env" {
+		t.Errorf("Env[REPLAY_VAR] = %q, want from-env", retained.Env["REPLAY_VAR"])
+	}
+
+	// Full output is retained even though the job was submitted with minimal verbosity
+	stdout, _, _ := coord.GetRetainedLogs("job-failed-1")
+	if stdout != "building...\n" {
+		t.Errorf("retained stdout = %q, want unfiltered output", stdout)
+	}
+
+	result := ReplayJob(retained, "", EmbeddedConfig{WorktreeDir: t.TempDir()})
+	if result.ExitCode != 0 {
+		t.Fatalf("replay exit code = %d, output: %s", result.ExitCode, result.Output)
+	}
+	if !strings.Contains(result.Stdout, "replayed from-env") {
+		t.Errorf("replay stdout = %q, want it to contain %q", result.Stdout, "replayed from-env")
+	}
+	if result.JobID != "replay-job-failed-1" {
+		t.Errorf("replay JobID = %q, want replay-job-failed-1", result.JobID)
+	}
+}
+
+func TestReplay_UnknownJob(t *testing.T) {
+	registry := NewRegistry()
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, NewDispatcher(registry, nil))
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleGetLogs))
+	defer server.Close()
+
+	if _, err := FetchRetainedJob(context.Background(), server.URL, "does-not-exist"); err == nil {
+		t.Error("expected error for unknown job")
+	}
+}
+
+func TestReplay_LogsWithoutMetadata(t *testing.T) {
+	registry := NewRegistry()
+	coord := NewCoordinator(CoordinatorConfig{WebSocketPort: 0}, registry, NewDispatcher(registry, nil))
+
+	// Logs retained without job metadata cannot be replayed
+	coord.AccumulateOutput("logs-only", "stdout", "output")
+	coord.RetainLogs("logs-only")
+
+	server := httptest.NewServer(http.HandlerFunc(coord.HandleGetLogs))
+	defer server.Close()
+
+	if _, err := FetchRetainedJob(context.Background(), server.URL, "logs-only"); err == nil {
+		t.Error("expected error when job metadata was not retained")
+	}
+}
@@ -0,0 +1,147 @@
+// internal/buildpool/resultcache.go
+package buildpool
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
+)
+
+// DefaultResultCacheSize is how many job results the dispatcher keeps for
+// cacheable jobs
+const DefaultResultCacheSize = 256
+
+// commitSHARegex matches commit hashes; refs like "HEAD" or branch names move,
+// so results of jobs submitted with them are not cached
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// CacheStats reports the use of the dispatcher's result cache
+type CacheStats struct {
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Entries  int    `json:"entries"`
+	Capacity int    `json:"capacity"` // 0 = caching disabled
+}
+
+// cachedResult is a result cache entry
+type cachedResult struct {
+	key    string
+	result buildprotocol.JobResult
+}
+
+// resultCache keeps the results of successful cacheable jobs, evicting the
+// least recently used once full
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // Front = most recently used
+	entries  map[string]*list.Element // key -> element holding a *cachedResult
+	hits     uint64
+	misses   uint64
+}
+
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// resultCacheKey derives the cache key of a job from everything that affects
+// its result: repo, commit, command, environment and nix setting. Returns ""
+// for jobs whose result may not be cached.
+func resultCacheKey(job *buildprotocol.JobMessage) string {
+	if !job.Cacheable || !commitSHARegex.MatchString(job.Commit) {
+		return ""
+	}
+
+	nix := "default"
+	if job.UseNix != nil {
+		nix = strconv.FormatBool(*job.UseNix)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{job.Repo, job.Commit, job.Command, nix} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	names := make([]string, 0, len(job.Env))
+	for name := range job.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name + "=" + job.Env[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns a copy of the cached result of a job, counting a hit or miss
+// for cacheable jobs
+func (c *resultCache) get(job *buildprotocol.JobMessage) (*buildprotocol.JobResult, bool) {
+	key := resultCacheKey(job)
+	if key == "" {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	result := elem.Value.(*cachedResult).result
+	return &result, true
+}
+
+// put stores the result of a job if both are cacheable: the job opted in at a
+// fixed commit and the command succeeded
+func (c *resultCache) put(job *buildprotocol.JobMessage, result *buildprotocol.JobResult) {
+	if result == nil || result.ExitCode != 0 || result.Cached {
+		return
+	}
+	key := resultCacheKey(job)
+	if key == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cachedResult).result = *result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cachedResult{key: key, result: *result})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResult).key)
+	}
+}
+
+// stats returns the hit and miss counts and the number of cached results
+func (c *resultCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:     c.hits,
+		Misses:   c.misses,
+		Entries:  c.order.Len(),
+		Capacity: c.capacity,
+	}
+}
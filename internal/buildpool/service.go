@@ -0,0 +1,132 @@
+// internal/buildpool/service.go
+package buildpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// ServiceConfig configures an in-process build pool
+type ServiceConfig struct {
+	URL            string              // URL agents and the TUI use to reach the coordinator
+	NewCoordinator func() *Coordinator // Builds a fresh coordinator for each start
+	NewGitDaemon   func() *GitDaemon   // Builds the git daemon for remote workers (nil = none)
+	Cleanup        func()              // Releases what the coordinators share, called by Close (nil = nothing)
+}
+
+// Service runs the coordinator and optional git daemon in-process and can
+// be started and stopped repeatedly, e.g. when toggled from the TUI
+type Service struct {
+	config ServiceConfig
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	coord  *Coordinator
+	daemon *GitDaemon
+	done   chan struct{} // Closed when the coordinator stops serving
+}
+
+// NewService creates a stopped build pool service
+func NewService(config ServiceConfig) *Service {
+	return &Service{config: config}
+}
+
+// URL returns the coordinator URL
+func (s *Service) URL() string {
+	return s.config.URL
+}
+
+// Start starts the git daemon and the coordinator. It does nothing if the
+// service is already running. A git daemon failure is returned, but the
+// coordinator is started anyway so local builds keep working.
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runningLocked() {
+		return nil
+	}
+	s.stopLocked() // Clean up after a coordinator that exited on its own
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	var daemonErr error
+	if s.config.NewGitDaemon != nil {
+		daemon := s.config.NewGitDaemon()
+		if err := daemon.Start(ctx); err != nil {
+			daemonErr = fmt.Errorf("starting git daemon: %w", err)
+		} else {
+			s.daemon = daemon
+		}
+	}
+
+	coord := s.config.NewCoordinator()
+	done := make(chan struct{})
+	s.coord = coord
+	s.done = done
+	go func() {
+		defer close(done)
+		if err := coord.Start(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("build pool coordinator error: %v", err)
+		}
+	}()
+
+	return daemonErr
+}
+
+// Stop stops the coordinator and git daemon and waits for the coordinator
+// to shut down. It does nothing if the service is not running.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+}
+
+// Close stops the service for good and releases what its coordinators share
+func (s *Service) Close() {
+	s.Stop()
+	if s.config.Cleanup != nil {
+		s.config.Cleanup()
+	}
+}
+
+// Running reports whether the coordinator is serving
+func (s *Service) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.runningLocked()
+}
+
+func (s *Service) runningLocked() bool {
+	if s.done == nil {
+		return false
+	}
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Service) stopLocked() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.coord.Stop()
+	if s.daemon != nil {
+		s.daemon.Stop()
+	}
+	<-s.done
+
+	s.cancel = nil
+	s.coord = nil
+	s.daemon = nil
+	s.done = nil
+}
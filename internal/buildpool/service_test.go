@@ -0,0 +1,146 @@
+// internal/buildpool/service_test.go
+package buildpool
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// freePort returns a TCP port that was free a moment ago
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// waitForStatus polls the coordinator's /status endpoint until it answers
+// (want=true) or stops answering (want=false)
+func waitForStatus(t *testing.T, url string, want bool) {
+	t.Helper()
+	client := &http.Client{Timeout: 200 * time.Millisecond}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(url + "/status")
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) == want {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("%s/status reachable = %v, want %v", url, !want, want)
+}
+
+func TestService_StartStopRestart(t *testing.T) {
+	port := freePort(t)
+	starts, cleanups := 0, 0
+	svc := NewService(ServiceConfig{
+		URL: fmt.Sprintf("http://127.0.0.1:%d", port),
+		NewCoordinator: func() *Coordinator {
+			starts++
+			return newTestCoordinator(CoordinatorConfig{WebSocketPort: port})
+		},
+		Cleanup: func() { cleanups++ },
+	})
+
+	if svc.Running() {
+		t.Fatal("new service should not be running")
+	}
+	svc.Stop() // Stopping a stopped service is a no-op
+
+	for round := 1; round <= 2; round++ {
+		if err := svc.Start(); err != nil {
+			t.Fatalf("round %d: Start: %v", round, err)
+		}
+		if err := svc.Start(); err != nil {
+			t.Fatalf("round %d: second Start: %v", round, err)
+		}
+		waitForStatus(t, svc.URL(), true)
+		if !svc.Running() {
+			t.Fatalf("round %d: service should be running", round)
+		}
+
+		svc.Stop()
+		if svc.Running() {
+			t.Fatalf("round %d: service should be stopped", round)
+		}
+		waitForStatus(t, svc.URL(), false)
+	}
+
+	if starts != 2 {
+		t.Errorf("coordinators created = %d, want 2 (one per start)", starts)
+	}
+	if cleanups != 0 {
+		t.Errorf("cleanups after Stop = %d, want 0", cleanups)
+	}
+
+	if err := svc.Start(); err != nil {
+		t.Fatalf("Start before Close: %v", err)
+	}
+	svc.Close()
+	if svc.Running() {
+		t.Error("service should be stopped after Close")
+	}
+	if cleanups != 1 {
+		t.Errorf("cleanups after Close = %d, want 1", cleanups)
+	}
+}
+
+func TestService_StopImmediatelyAfterStart(t *testing.T) {
+	svc := NewService(ServiceConfig{
+		NewCoordinator: func() *Coordinator {
+			return newTestCoordinator(CoordinatorConfig{WebSocketPort: 0})
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Start()
+		svc.Stop()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Stop right after Start did not return")
+	}
+	if svc.Running() {
+		t.Error("service should be stopped")
+	}
+}
+
+func TestService_NotRunningWhenPortTaken(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	svc := NewService(ServiceConfig{
+		NewCoordinator: func() *Coordinator {
+			return newTestCoordinator(CoordinatorConfig{WebSocketPort: port})
+		},
+	})
+	if err := svc.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer svc.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for svc.Running() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if svc.Running() {
+		t.Error("service should report not running when the coordinator cannot listen")
+	}
+}
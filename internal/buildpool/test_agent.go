@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +23,7 @@ type TestAgentConfig struct {
 	MCPBinary    string // Path to the build-mcp binary (optional, will search PATH)
 	Verbose      bool   // Show verbose output
 	ExecutorType string // "claude-code" (default) or "opencode"
+	BinaryPath   string // Executor binary to run (empty = "claude" or "opencode" from PATH)
 }
 
 // TestAgentResult contains the results of the test
@@ -66,8 +68,12 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 	var cmd *exec.Cmd
 	var cleanup func()
 
+	binary := config.BinaryPath
 	if config.ExecutorType == "opencode" {
 		// Build OpenCode command
+		if binary == "" {
+			binary = "opencode"
+		}
 		mcpConfigPath, cleanupFn, err := createTestMCPConfigOpenCode(config)
 		if err != nil {
 			return nil, fmt.Errorf("creating OpenCode MCP config: %w", err)
@@ -80,11 +86,14 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 		}
 		args = append(args, TestPrompt)
 
-		cmd = exec.CommandContext(ctx, "opencode", args...)
+		cmd = exec.CommandContext(ctx, binary, args...)
 		cmd.Dir = config.ProjectRoot
 		cmd.Env = append(os.Environ(), "OPENCODE_CONFIG="+mcpConfigPath)
 	} else {
 		// Build Claude Code command (default)
+		if binary == "" {
+			binary = "claude"
+		}
 		mcpConfig, cleanupFn, err := createTestMCPConfig(config)
 		if err != nil {
 			return nil, fmt.Errorf("creating MCP config: %w", err)
@@ -103,7 +112,7 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 
 		args = append(args, "-p", TestPrompt)
 
-		cmd = exec.CommandContext(ctx, "claude", args...)
+		cmd = exec.CommandContext(ctx, binary, args...)
 		cmd.Dir = config.ProjectRoot
 	}
 
@@ -121,16 +130,19 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 
 	// Start the process
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("starting claude: %w", err)
+		return nil, fmt.Errorf("starting %s: %w", binary, err)
 	}
 
 	// Collect output
 	var outputLines []string
 	outputCh := make(chan string, 100)
 	doneCh := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(2)
 
 	// Stream stdout
 	go func() {
+		defer readers.Done()
 		scanner := bufio.NewScanner(stdout)
 		buf := make([]byte, 0, 64*1024)
 		scanner.Buffer(buf, 1024*1024)
@@ -142,6 +154,7 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 
 	// Stream stderr
 	go func() {
+		defer readers.Done()
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -162,7 +175,9 @@ func RunTestAgent(ctx context.Context, config TestAgentConfig, onOutput TestAgen
 		close(doneCh)
 	}()
 
-	// Wait for completion
+	// Wait for completion. The pipes must be fully read before cmd.Wait
+	// closes them, and outputCh closed only once no reader can send on it.
+	readers.Wait()
 	err = cmd.Wait()
 	close(outputCh)
 	<-doneCh
@@ -339,7 +354,7 @@ func findBuildMCPBinary(config TestAgentConfig) (string, error) {
 // RunTestAgentWithEmbeddedCoordinator starts a temporary coordinator with embedded worker,
 // runs the agent test, then shuts down the coordinator. Use this when no external coordinator
 // is available.
-func RunTestAgentWithEmbeddedCoordinator(ctx context.Context, projectRoot string, verbose bool, executorType string, onOutput TestAgentOutputCallback) (*TestAgentResult, error) {
+func RunTestAgentWithEmbeddedCoordinator(ctx context.Context, projectRoot string, verbose bool, executorType, binaryPath string, onOutput TestAgentOutputCallback) (*TestAgentResult, error) {
 	// Create worktree directory for embedded worker
 	worktreeDir, err := os.MkdirTemp("", "agent-test-worktrees-")
 	if err != nil {
@@ -380,6 +395,7 @@ func RunTestAgentWithEmbeddedCoordinator(ctx context.Context, projectRoot string
 		ProjectRoot:  projectRoot,
 		Verbose:      verbose,
 		ExecutorType: executorType,
+		BinaryPath:   binaryPath,
 	}
 
 	return RunTestAgent(ctx, config, onOutput)
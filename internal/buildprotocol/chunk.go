@@ -0,0 +1,34 @@
+package buildprotocol
+
+import "unicode/utf8"
+
+// MaxOutputChunk is the most output bytes a worker puts in one OutputMessage,
+// so large outputs (e.g. a huge single line) stay well within WebSocket frame
+// limits
+const MaxOutputChunk = 32 * 1024
+
+// SplitOutput splits data into chunks of at most size bytes, without
+// splitting UTF-8 characters. Concatenating the chunks gives data back.
+func SplitOutput(data string, size int) []string {
+	if len(data) <= size {
+		return []string{data}
+	}
+
+	var chunks []string
+	for len(data) > size {
+		cut := size
+		// Back up to the start of a character, unless that leaves nothing
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = size
+		}
+		chunks = append(chunks, data[:cut])
+		data = data[cut:]
+	}
+	if data != "" {
+		chunks = append(chunks, data)
+	}
+	return chunks
+}
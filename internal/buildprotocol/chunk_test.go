@@ -0,0 +1,31 @@
+package buildprotocol
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitOutput(t *testing.T) {
+	if got := SplitOutput("short", 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("SplitOutput(short) = %q, want it unsplit", got)
+	}
+
+	// Multi-byte characters straddle every chunk boundary
+	data := strings.Repeat("aé€", 1000)
+	chunks := SplitOutput(data, 10)
+	if len(chunks) < len(data)/10 {
+		t.Fatalf("got %d chunks, want at least %d", len(chunks), len(data)/10)
+	}
+	for i, c := range chunks {
+		if len(c) > 10 || c == "" {
+			t.Fatalf("chunk %d is %d bytes, want 1-10", i, len(c))
+		}
+		if !utf8.ValidString(c) {
+			t.Fatalf("chunk %d %q splits a character", i, c)
+		}
+	}
+	if strings.Join(chunks, "") != data {
+		t.Error("chunks do not reassemble into the data")
+	}
+}
@@ -0,0 +1,86 @@
+// internal/buildprotocol/discovery.go
+package buildprotocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiscoveryFileEnv overrides the path of the coordinator discovery file
+const DiscoveryFileEnv = "BUILD_POOL_DISCOVERY_FILE"
+
+// Discovery is written by a running coordinator so clients can find it
+// without knowing its port, e.g. when it listens on an OS-assigned port
+type Discovery struct {
+	URL       string    `json:"url"`        // Coordinator URL with the bound port
+	PID       int       `json:"pid"`        // Process serving the coordinator
+	StartedAt time.Time `json:"started_at"` // When the coordinator started listening
+}
+
+// DefaultDiscoveryPath returns the well-known discovery file location,
+// honoring BUILD_POOL_DISCOVERY_FILE
+func DefaultDiscoveryPath() string {
+	if path := os.Getenv(DiscoveryFileEnv); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude-orchestrator", "build-pool.json")
+}
+
+// WriteDiscovery atomically writes the discovery file, creating its directory
+func WriteDiscovery(path string, d Discovery) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadDiscovery reads the discovery file. A missing file is returned as an
+// error satisfying errors.Is(err, fs.ErrNotExist).
+func ReadDiscovery(path string) (*Discovery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var d Discovery
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parsing discovery file %s: %w", path, err)
+	}
+	if d.URL == "" {
+		return nil, fmt.Errorf("discovery file %s has no url", path)
+	}
+	return &d, nil
+}
+
+// DiscoverURL returns the coordinator URL from the default discovery file,
+// or "" if no coordinator has published one. A file left behind by a
+// coordinator whose process is gone, e.g. after a crash, is ignored and
+// removed.
+func DiscoverURL() string {
+	path := DefaultDiscoveryPath()
+	d, err := ReadDiscovery(path)
+	if err != nil {
+		return ""
+	}
+	if d.PID > 0 && !pidAlive(d.PID) {
+		// Unless a new coordinator replaced the file in the meantime
+		if again, err := ReadDiscovery(path); err == nil && again.PID == d.PID {
+			os.Remove(path)
+		}
+		return ""
+	}
+	return d.URL
+}
@@ -0,0 +1,87 @@
+// internal/buildprotocol/discovery_test.go
+package buildprotocol
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiscovery_WriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "build-pool.json")
+	want := Discovery{URL: "http://localhost:40123", PID: 4242, StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	if err := WriteDiscovery(path, want); err != nil {
+		t.Fatalf("WriteDiscovery: %v", err)
+	}
+	got, err := ReadDiscovery(path)
+	if err != nil {
+		t.Fatalf("ReadDiscovery: %v", err)
+	}
+	if *got != want {
+		t.Errorf("ReadDiscovery = %+v, want %+v", *got, want)
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestDiscovery_ReadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ReadDiscovery(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want fs.ErrNotExist", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte("not json"), 0644)
+	if _, err := ReadDiscovery(invalid); err == nil {
+		t.Error("invalid file: expected an error")
+	}
+
+	noURL := filepath.Join(dir, "no-url.json")
+	os.WriteFile(noURL, []byte(`{"pid": 1}`), 0644)
+	if _, err := ReadDiscovery(noURL); err == nil {
+		t.Error("file without url: expected an error")
+	}
+}
+
+func TestDiscoverURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build-pool.json")
+	t.Setenv(DiscoveryFileEnv, path)
+
+	if got := DefaultDiscoveryPath(); got != path {
+		t.Errorf("DefaultDiscoveryPath = %q, want %q", got, path)
+	}
+	if got := DiscoverURL(); got != "" {
+		t.Errorf("DiscoverURL without file = %q, want empty", got)
+	}
+
+	WriteDiscovery(path, Discovery{URL: "http://localhost:40123", PID: os.Getpid()})
+	if got := DiscoverURL(); got != "http://localhost:40123" {
+		t.Errorf("DiscoverURL = %q, want http://localhost:40123", got)
+	}
+}
+
+func TestDiscoverURL_StaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build-pool.json")
+	t.Setenv(DiscoveryFileEnv, path)
+
+	// A process that has exited, like a crashed coordinator
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("no process to exit: %v", err)
+	}
+	WriteDiscovery(path, Discovery{URL: "http://localhost:40123", PID: cmd.Process.Pid})
+
+	if got := DiscoverURL(); got != "" {
+		t.Errorf("DiscoverURL = %q, want the URL of the dead coordinator ignored", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("stale discovery file not removed: %v", err)
+	}
+}
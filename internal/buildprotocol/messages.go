@@ -4,8 +4,10 @@ package buildprotocol
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // Envelope wraps all messages with a type discriminator.
@@ -32,8 +34,9 @@ func MarshalEnvelope(msgType string, payload interface{}) ([]byte, error) {
 
 // RegisterMessage sent when worker first connects
 type RegisterMessage struct {
-	WorkerID string `json:"worker_id"`
-	MaxJobs  int    `json:"max_jobs"`
+	WorkerID string   `json:"worker_id"`
+	MaxJobs  int      `json:"max_jobs"`
+	Tags     []string `json:"tags,omitempty"` // Capabilities jobs can require, e.g. "gpu"
 }
 
 // ReadyMessage sent when worker has available job slots
@@ -41,18 +44,21 @@ type ReadyMessage struct {
 	Slots int `json:"slots"`
 }
 
-// OutputMessage sent for streaming command output
+// OutputMessage sent for streaming command output. Workers send output in
+// chunks of at most MaxOutputChunk bytes, numbered by Seq.
 type OutputMessage struct {
 	JobID  string `json:"job_id"`
 	Stream string `json:"stream"` // "stdout" or "stderr"
 	Data   string `json:"data"`
+	Seq    int    `json:"seq,omitempty"` // Chunk number within the job run, from 1 (0 = unnumbered)
 }
 
 // CompleteMessage sent when job finishes
 type CompleteMessage struct {
-	JobID      string `json:"job_id"`
-	ExitCode   int    `json:"exit_code"`
-	DurationMs int64  `json:"duration_ms"`
+	JobID        string `json:"job_id"`
+	ExitCode     int    `json:"exit_code"`
+	DurationMs   int64  `json:"duration_ms"`
+	OutputChunks int    `json:"output_chunks,omitempty"` // Number of output chunks sent, to detect lost ones
 }
 
 // ErrorMessage sent when job fails before completion
@@ -71,6 +77,63 @@ type JobMessage struct {
 	Command string            `json:"command"`
 	Env     map[string]string `json:"env,omitempty"`
 	Timeout int               `json:"timeout_secs,omitempty"`
+	TaskID  string            `json:"task_id,omitempty"` // Task of the agent that submitted the job (empty if not from an agent)
+	UseNix  *bool             `json:"use_nix,omitempty"` // Run the command in nix develop (nil = worker's default)
+
+	// RequiredTags restricts the job to workers having all of these tags
+	// (empty = any worker)
+	RequiredTags []string `json:"required_tags,omitempty"`
+
+	// Priority orders queued jobs: higher runs first, equal priorities in
+	// submission order (default 0)
+	Priority int `json:"priority,omitempty"`
+
+	// Cacheable lets the coordinator answer the job with the result of an
+	// earlier successful job with the same repo, commit, command and
+	// environment instead of running it again
+	Cacheable bool `json:"cacheable,omitempty"`
+}
+
+// TaskIDEnv is the environment variable through which agents pass their task
+// ID to the build MCP servers, so submitted jobs can be correlated to agents
+const TaskIDEnv = "CLAUDE_ORCH_TASK_ID"
+
+// AllowedJobEnv lists environment variables callers may set on a job.
+// Entries ending in "*" match any variable with that prefix.
+var AllowedJobEnv = []string{
+	"RUSTFLAGS",
+	"RUSTDOCFLAGS",
+	"RUST_BACKTRACE",
+	"RUST_LOG",
+	"RUST_MIN_STACK",
+	"RUST_TEST_THREADS",
+	"CARGO_INCREMENTAL",
+	"CARGO_BUILD_JOBS",
+	"CARGO_TERM_COLOR",
+	"CARGO_PROFILE_*",
+}
+
+// ValidateJobEnv returns an error if env sets a variable not in AllowedJobEnv
+func ValidateJobEnv(env map[string]string) error {
+	for name := range env {
+		if !isAllowedJobEnv(name) {
+			return fmt.Errorf("environment variable %q is not allowed", name)
+		}
+	}
+	return nil
+}
+
+func isAllowedJobEnv(name string) bool {
+	for _, allowed := range AllowedJobEnv {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				return true
+			}
+		} else if name == allowed {
+			return true
+		}
+	}
+	return false
 }
 
 // CancelMessage requests job cancellation
@@ -115,6 +178,8 @@ type JobResult struct {
 	// Parsed from clippy output (optional)
 	ClippyWarnings int `json:"clippy_warnings,omitempty"`
 	ClippyErrors   int `json:"clippy_errors,omitempty"`
+
+	Cached bool `json:"cached,omitempty"` // Taken from the result cache rather than run
 }
 
 var testResultRegex = regexp.MustCompile(`(\d+) passed; (\d+) failed; (\d+) ignored`)
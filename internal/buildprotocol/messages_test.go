@@ -101,3 +101,27 @@ func TestJobResultSeparateStreams(t *testing.T) {
 		t.Errorf("Stderr = %q, want %q", result.Stderr, "warnings here")
 	}
 }
+
+func TestValidateJobEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"nil env", nil, false},
+		{"exact match", map[string]string{"RUSTFLAGS": "-C target-cpu=native"}, false},
+		{"prefix match", map[string]string{"CARGO_PROFILE_DEV_DEBUG": "0"}, false},
+		{"bare prefix", map[string]string{"CARGO_PROFILE_": "x"}, true},
+		{"not allowed", map[string]string{"LD_PRELOAD": "/tmp/evil.so"}, true},
+		{"one bad among good", map[string]string{"RUST_LOG": "debug", "PATH": "/tmp"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJobEnv(tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJobEnv(%v) error = %v, wantErr %v", tt.env, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -37,10 +37,15 @@ type WorkerConfig struct {
 	ServerURL   string
 	WorkerID    string
 	MaxJobs     int
+	Tags        []string // Capabilities announced to the coordinator, e.g. "gpu"
 	GitCacheDir string
 	WorktreeDir string
 	UseNixShell bool
 	Debug       bool // Enable verbose logging for heartbeat diagnostics
+
+	ReuseNixSessions bool // Reuse a repo's captured nix develop environment across jobs
+
+	WarmWorktrees int // Worktrees kept checked out per recently built commit (0 = off)
 }
 
 // Validate checks the config is valid
@@ -70,6 +75,9 @@ type Worker struct {
 	// Job tracking for cancellation
 	jobsMu sync.Mutex
 	jobs   map[string]context.CancelFunc
+
+	// Whether Stop closes the executor; a shared one is closed by its owner
+	ownsExecutor bool
 }
 
 // NewWorker creates a new worker client
@@ -84,15 +92,18 @@ func NewWorker(config WorkerConfig) (*Worker, error) {
 		config: config,
 		pool:   NewPool(config.MaxJobs),
 		executor: NewExecutor(ExecutorConfig{
-			GitCacheDir: config.GitCacheDir,
-			WorktreeDir: config.WorktreeDir,
-			UseNixShell: config.UseNixShell,
-			Debug:       config.Debug,
+			GitCacheDir:      config.GitCacheDir,
+			WorktreeDir:      config.WorktreeDir,
+			UseNixShell:      config.UseNixShell,
+			Debug:            config.Debug,
+			ReuseNixSessions: config.ReuseNixSessions,
+			WarmWorktrees:    config.WarmWorktrees,
 		}),
 		orchestratorName: config.ServerURL,
 		ctx:              ctx,
 		cancel:           cancel,
 		jobs:             make(map[string]context.CancelFunc),
+		ownsExecutor:     true,
 	}, nil
 }
 
@@ -155,6 +166,7 @@ func (w *Worker) Connect() error {
 	return w.send(buildprotocol.TypeRegister, buildprotocol.RegisterMessage{
 		WorkerID: w.config.WorkerID,
 		MaxJobs:  w.config.MaxJobs,
+		Tags:     w.config.Tags,
 	})
 }
 
@@ -255,15 +267,11 @@ func (w *Worker) handleJob(jobMsg buildprotocol.JobMessage) {
 		Command: jobMsg.Command,
 		Env:     jobMsg.Env,
 		Timeout: timeout,
+		UseNix:  jobMsg.UseNix,
 	}
 
-	result, err := w.executor.RunJob(ctx, job, func(stream, data string) {
-		w.send(buildprotocol.TypeOutput, buildprotocol.OutputMessage{
-			JobID:  jobMsg.JobID,
-			Stream: stream,
-			Data:   data,
-		})
-	})
+	output := &outputSender{jobID: jobMsg.JobID, send: w.send}
+	result, err := w.executor.RunJob(ctx, job, output.Send)
 
 	if err != nil {
 		if w.config.Debug {
@@ -281,12 +289,47 @@ func (w *Worker) handleJob(jobMsg buildprotocol.JobMessage) {
 			jobMsg.JobID, result.ExitCode, result.DurationSecs)
 	}
 	w.send(buildprotocol.TypeComplete, buildprotocol.CompleteMessage{
-		JobID:      jobMsg.JobID,
-		ExitCode:   result.ExitCode,
-		DurationMs: int64(result.DurationSecs * 1000),
+		JobID:        jobMsg.JobID,
+		ExitCode:     result.ExitCode,
+		DurationMs:   int64(result.DurationSecs * 1000),
+		OutputChunks: output.Chunks(),
 	})
 }
 
+// outputSender sends a job's output as numbered chunks of at most
+// buildprotocol.MaxOutputChunk bytes, so the coordinator can reassemble
+// large outputs and tell when chunks were lost
+type outputSender struct {
+	jobID string
+	send  func(msgType string, payload interface{}) error
+
+	mu  sync.Mutex // Held while sending, so chunks go out in Seq order
+	seq int
+}
+
+// Send sends data of a stream; it is safe to call from several goroutines
+func (s *outputSender) Send(stream, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, chunk := range buildprotocol.SplitOutput(data, buildprotocol.MaxOutputChunk) {
+		s.seq++
+		s.send(buildprotocol.TypeOutput, buildprotocol.OutputMessage{
+			JobID:  s.jobID,
+			Stream: stream,
+			Data:   chunk,
+			Seq:    s.seq,
+		})
+	}
+}
+
+// Chunks returns how many chunks were sent
+func (s *outputSender) Chunks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
 func (w *Worker) sendReady() error {
 	return w.send(buildprotocol.TypeReady, buildprotocol.ReadyMessage{
 		Slots: w.pool.Available(),
@@ -338,6 +381,10 @@ func (w *Worker) Stop() {
 		w.conn = nil
 	}
 	w.mu.Unlock()
+
+	if w.ownsExecutor {
+		w.executor.Close()
+	}
 }
 
 // RunWithReconnect runs the worker with automatic reconnection
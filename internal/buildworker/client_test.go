@@ -3,8 +3,12 @@ package buildworker
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 )
 
 func TestWorkerConfig_Validate(t *testing.T) {
@@ -112,3 +116,42 @@ func TestWorker_ReconnectBackoff(t *testing.T) {
 		t.Errorf("backoff(10) = %v, want <= 60s (capped)", delays[4])
 	}
 }
+
+func TestOutputSender_SendsNumberedChunks(t *testing.T) {
+	var sent []buildprotocol.OutputMessage
+	sender := &outputSender{jobID: "job-1", send: func(msgType string, payload interface{}) error {
+		if msgType != buildprotocol.TypeOutput {
+			t.Errorf("sent %q, want output", msgType)
+		}
+		sent = append(sent, payload.(buildprotocol.OutputMessage))
+		return nil
+	}}
+
+	big := strings.Repeat("y", 3*buildprotocol.MaxOutputChunk+10)
+	var wg sync.WaitGroup
+	for _, stream := range []string{"stdout", "stderr"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sender.Send(stream, big)
+		}()
+	}
+	wg.Wait()
+
+	if len(sent) != 8 || sender.Chunks() != 8 {
+		t.Fatalf("sent %d chunks, Chunks() = %d; want 4 per stream", len(sent), sender.Chunks())
+	}
+	streams := map[string]string{}
+	for i, msg := range sent {
+		if msg.Seq != i+1 || msg.JobID != "job-1" {
+			t.Errorf("chunk %d has seq %d for job %q, want seq %d for job-1", i, msg.Seq, msg.JobID, i+1)
+		}
+		if len(msg.Data) > buildprotocol.MaxOutputChunk {
+			t.Errorf("chunk %d is %d bytes, over the limit", i, len(msg.Data))
+		}
+		streams[msg.Stream] += msg.Data
+	}
+	if streams["stdout"] != big || streams["stderr"] != big {
+		t.Error("chunks of a stream should reassemble into its output")
+	}
+}
@@ -27,6 +27,7 @@ type Job struct {
 	Command string
 	Env     map[string]string
 	Timeout time.Duration
+	UseNix  *bool // Overrides ExecutorConfig.UseNixShell for this job (nil = use the config)
 }
 
 // OutputCallback is called for each line of output
@@ -38,16 +39,43 @@ type ExecutorConfig struct {
 	WorktreeDir string
 	UseNixShell bool
 	Debug       bool
+
+	// ReuseNixSessions captures a repo's nix develop environment once and
+	// runs later jobs of the repo directly in it, until the dev shell
+	// definition changes. Each job still gets its own clean worktree.
+	ReuseNixSessions bool
+
+	// WarmWorktrees keeps this many worktrees checked out at each of the
+	// most recently built commits, so their jobs start without a checkout
+	// (0 = check out a worktree per job)
+	WarmWorktrees int
 }
 
 // Executor runs jobs in isolated worktrees
 type Executor struct {
-	config ExecutorConfig
+	config   ExecutorConfig
+	sessions *nixSessionCache // nil unless ReuseNixSessions
+	warm     *warmPool        // nil unless WarmWorktrees
 }
 
 // NewExecutor creates a new job executor
 func NewExecutor(config ExecutorConfig) *Executor {
-	return &Executor{config: config}
+	e := &Executor{config: config}
+	if config.ReuseNixSessions {
+		e.sessions = newNixSessionCache()
+	}
+	if config.WarmWorktrees > 0 {
+		e.warm = newWarmPool(config.WarmWorktrees, e.createWarmWorktree, e.removeWarmWorktree)
+	}
+	return e
+}
+
+// Close removes the executor's warm worktrees. Jobs must not be started
+// after Close.
+func (e *Executor) Close() {
+	if e.warm != nil {
+		e.warm.close()
+	}
 }
 
 // RunJob executes a job and returns the result
@@ -92,25 +120,7 @@ func (e *Executor) RunJob(ctx context.Context, job Job, onOutput OutputCallback)
 	}
 
 	// Build command
-	var cmd *exec.Cmd
-	if e.config.UseNixShell {
-		if e.config.Debug {
-			log.Printf("[executor] running with nix develop: nix develop --command sh -c %q", job.Command)
-		}
-		cmd = exec.CommandContext(ctx, "nix", "develop", "--command", "sh", "-c", job.Command)
-	} else {
-		if e.config.Debug {
-			log.Printf("[executor] running directly: sh -c %q", job.Command)
-		}
-		cmd = exec.CommandContext(ctx, "sh", "-c", job.Command)
-	}
-	cmd.Dir = wtPath
-
-	// Set environment
-	cmd.Env = os.Environ()
-	for k, v := range job.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
-	}
+	cmd := e.command(ctx, job, wtPath)
 
 	// Capture output - track stdout and stderr separately for verbosity filtering
 	stdout, _ := cmd.StdoutPipe()
@@ -178,6 +188,51 @@ func (e *Executor) RunJob(ctx context.Context, job Job, onOutput OutputCallback)
 	}, nil
 }
 
+// command builds the shell command for a job running in dir, wrapped in nix
+// develop unless the job or the executor config opts out. With session reuse,
+// the command runs directly in the repo's captured dev shell environment.
+func (e *Executor) command(ctx context.Context, job Job, dir string) *exec.Cmd {
+	useNix := e.config.UseNixShell
+	if job.UseNix != nil {
+		useNix = *job.UseNix
+	}
+
+	var cmd *exec.Cmd
+	env := os.Environ()
+	switch {
+	case useNix && e.sessions != nil && job.Repo != "":
+		sessionEnv, reused, err := e.sessions.Env(ctx, job.Repo, dir)
+		if err != nil {
+			log.Printf("[executor] nix session for %s unavailable, running nix develop: %v", job.Repo, err)
+			cmd = exec.CommandContext(ctx, "nix", "develop", "--command", "sh", "-c", job.Command)
+			break
+		}
+		if e.config.Debug {
+			log.Printf("[executor] running in nix session (reused=%v): sh -c %q", reused, job.Command)
+		}
+		cmd = exec.CommandContext(ctx, "sh", "-c", job.Command)
+		env = sessionEnv
+	case useNix:
+		if e.config.Debug {
+			log.Printf("[executor] running with nix develop: nix develop --command sh -c %q", job.Command)
+		}
+		cmd = exec.CommandContext(ctx, "nix", "develop", "--command", "sh", "-c", job.Command)
+	default:
+		if e.config.Debug {
+			log.Printf("[executor] running directly: sh -c %q", job.Command)
+		}
+		cmd = exec.CommandContext(ctx, "sh", "-c", job.Command)
+	}
+
+	cmd.Dir = dir
+	cmd.Env = env
+	for k, v := range job.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	killProcessGroupOnCancel(cmd)
+	return cmd
+}
+
 func (e *Executor) createWorktree(jobID, repo, commit string) (string, error) {
 	// Ensure worktree directory exists
 	if err := os.MkdirAll(e.config.WorktreeDir, 0755); err != nil {
@@ -202,17 +257,41 @@ func (e *Executor) createWorktree(jobID, repo, commit string) (string, error) {
 		}
 
 		// Use HEAD instead of passed commit (which may be stale)
+		rev := "HEAD"
+		var key warmKey
+		if e.warm != nil {
+			cmd := exec.Command("git", "rev-parse", "HEAD")
+			cmd.Dir = repo
+			if out, err := cmd.Output(); err == nil {
+				key = warmKey{repo: repo, commit: strings.TrimSpace(string(out))}
+				if path, ok := e.warm.take(key); ok {
+					return path, nil
+				}
+				rev = key.commit // Check out what the pool will keep warm
+			}
+		}
 		if e.config.Debug {
-			log.Printf("[executor] creating worktree from HEAD at %s", repo)
+			log.Printf("[executor] creating worktree from %s at %s", rev, repo)
 		}
-		cmd := exec.Command("git", "worktree", "add", "--detach", wtPath, "HEAD")
+		cmd := exec.Command("git", "worktree", "add", "--detach", wtPath, rev)
 		cmd.Dir = repo
 		if out, err := cmd.CombinedOutput(); err != nil {
 			return "", fmt.Errorf("git worktree add: %s: %w", out, err)
 		}
+		if key.commit != "" {
+			e.warm.keep(key)
+		}
 		return wtPath, nil
 	}
 
+	key := warmKey{repo: repo, commit: commit}
+	pooled := e.warm != nil && commitSHAPattern.MatchString(commit)
+	if pooled {
+		if path, ok := e.warm.take(key); ok {
+			return path, nil
+		}
+	}
+
 	// For remote repos, fetch into git cache directory
 	// First ensure git cache dir exists and is a git repo
 	if e.config.Debug {
@@ -245,10 +324,37 @@ func (e *Executor) createWorktree(jobID, repo, commit string) (string, error) {
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("git worktree add: %s: %w", out, err)
 	}
+	if pooled {
+		e.warm.keep(key) // The commit is in the cache now
+	}
 
 	return wtPath, nil
 }
 
+// createWarmWorktree checks out a worktree for the warm pool. The commit
+// must already be in the local repo or the git cache.
+func (e *Executor) createWarmWorktree(key warmKey) (string, error) {
+	gitDir := key.repo
+	if strings.HasPrefix(key.repo, "git://") || strings.HasPrefix(key.repo, "https://") {
+		gitDir = e.config.GitCacheDir
+	}
+
+	wtPath := filepath.Join(e.config.WorktreeDir, "warm-"+randomSuffix())
+	cmd := exec.Command("git", "worktree", "add", "--detach", wtPath, key.commit)
+	cmd.Dir = gitDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git worktree add: %s: %w", out, err)
+	}
+	if e.config.Debug {
+		log.Printf("[executor] warm worktree for %s@%s created at %s", key.repo, key.commit, wtPath)
+	}
+	return wtPath, nil
+}
+
+func (e *Executor) removeWarmWorktree(key warmKey, wtPath string) {
+	e.removeWorktree(key.repo, wtPath)
+}
+
 func (e *Executor) removeWorktree(repo, wtPath string) error {
 	// Determine the git directory to use for worktree removal
 	gitDir := repo
@@ -263,12 +369,20 @@ func (e *Executor) removeWorktree(repo, wtPath string) error {
 }
 
 func (e *Executor) streamOutput(r io.Reader, stream string, output *strings.Builder, callback OutputCallback) {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := scanner.Text() + "\n"
-		output.WriteString(line)
-		if callback != nil {
-			callback(stream, line)
+	// Read whole lines however long they are; a bufio.Scanner would stop at
+	// the first line over its token limit and drop the rest of the output
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r") + "\n"
+			output.WriteString(line)
+			if callback != nil {
+				callback(stream, line)
+			}
+		}
+		if err != nil {
+			return
 		}
 	}
 }
@@ -6,7 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestRepo(t *testing.T) string {
@@ -252,6 +255,37 @@ func TestExecutor_RunJob_StderrOutput(t *testing.T) {
 	}
 }
 
+func TestExecutor_RunJob_LongLine(t *testing.T) {
+	repoDir := setupTestRepo(t)
+
+	executor := NewExecutor(ExecutorConfig{
+		GitCacheDir: repoDir,
+		WorktreeDir: t.TempDir(),
+		UseNixShell: false,
+	})
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoDir
+	commitBytes, _ := cmd.Output()
+	commit := string(commitBytes[:len(commitBytes)-1])
+
+	// A single line far beyond bufio.Scanner's 64 KB limit, then more output
+	result, err := executor.RunJob(context.Background(), Job{
+		ID:      "test-job-long-line",
+		Repo:    repoDir,
+		Commit:  commit,
+		Command: "head -c 200000 /dev/zero | tr '\\0' x; echo; echo after",
+	}, nil)
+	if err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	want := strings.Repeat("x", 200000) + "\nafter\n"
+	if result.Output != want {
+		t.Errorf("got %d bytes of output, want the long line and the line after it (%d bytes)", len(result.Output), len(want))
+	}
+}
+
 func TestExecutor_RunJob_WorktreeCleanup(t *testing.T) {
 	repoDir := setupTestRepo(t)
 	worktreeDir := t.TempDir()
@@ -286,6 +320,30 @@ func TestExecutor_RunJob_WorktreeCleanup(t *testing.T) {
 	}
 }
 
+func TestExecutor_RunJob_CancelKillsChildProcesses(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{
+		WorktreeDir: t.TempDir(),
+		UseNixShell: false,
+	})
+
+	// The shell forks sleep, which inherits the output pipes; killing only the
+	// shell would leave RunJob waiting for sleep to exit
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := executor.RunJob(ctx, Job{
+		ID:      "test-job-cancel",
+		Command: "sleep 30; echo done",
+	}, nil)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("RunJob returned after %v, want the job killed on cancel", elapsed)
+	}
+	if err == nil && (result.ExitCode == 0 || strings.Contains(result.Output, "done")) {
+		t.Errorf("cancelled job finished normally: %+v", result)
+	}
+}
+
 func TestExecutor_RunJob_WorksWithRepoFiles(t *testing.T) {
 	repoDir := setupTestRepo(t)
 	worktreeDir := t.TempDir()
@@ -381,3 +439,45 @@ func TestExecutor_RunJob_JobIDPreserved(t *testing.T) {
 		t.Errorf("got job ID %q, want %q", result.JobID, "my-unique-job-id")
 	}
 }
+
+func TestExecutor_Command_UseNixPerJob(t *testing.T) {
+	yes, no := true, false
+	nixArgs := []string{"nix", "develop", "--command", "sh", "-c", "echo hi"}
+	shArgs := []string{"sh", "-c", "echo hi"}
+
+	tests := []struct {
+		name      string
+		configNix bool
+		jobNix    *bool
+		want      []string
+	}{
+		{"config nix, no override", true, nil, nixArgs},
+		{"config nix, job skips nix", true, &no, shArgs},
+		{"config plain, no override", false, nil, shArgs},
+		{"config plain, job wants nix", false, &yes, nixArgs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewExecutor(ExecutorConfig{UseNixShell: tt.configNix})
+			cmd := executor.command(context.Background(), Job{ID: "job", Command: "echo hi", UseNix: tt.jobNix}, t.TempDir())
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutor_RunJob_SkipsNixPerJob(t *testing.T) {
+	// nix is not needed: the job opts out of the executor's nix default
+	executor := NewExecutor(ExecutorConfig{WorktreeDir: t.TempDir(), UseNixShell: true})
+	useNix := false
+
+	result, err := executor.RunJob(context.Background(), Job{ID: "no-nix", Command: "echo plain", UseNix: &useNix}, nil)
+	if err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+	if result.ExitCode != 0 || result.Output != "plain\n" {
+		t.Errorf("got exit code %d output %q, want 0 and %q", result.ExitCode, result.Output, "plain\n")
+	}
+}
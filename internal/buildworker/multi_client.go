@@ -19,10 +19,15 @@ type MultiClientConfig struct {
 	Servers     []ServerConfig
 	WorkerID    string
 	MaxJobs     int
+	Tags        []string // Capabilities announced to every coordinator
 	GitCacheDir string
 	WorktreeDir string
 	UseNixShell bool
 	Debug       bool
+
+	ReuseNixSessions bool // Reuse a repo's captured nix develop environment across jobs
+
+	WarmWorktrees int // Worktrees kept checked out per recently built commit (0 = off)
 }
 
 // Validate checks the config is valid
@@ -64,10 +69,12 @@ func NewMultiClient(config MultiClientConfig) (*MultiClient, error) {
 	// Create shared pool and executor
 	pool := NewPool(config.MaxJobs)
 	executor := NewExecutor(ExecutorConfig{
-		GitCacheDir: config.GitCacheDir,
-		WorktreeDir: config.WorktreeDir,
-		UseNixShell: config.UseNixShell,
-		Debug:       config.Debug,
+		GitCacheDir:      config.GitCacheDir,
+		WorktreeDir:      config.WorktreeDir,
+		UseNixShell:      config.UseNixShell,
+		Debug:            config.Debug,
+		ReuseNixSessions: config.ReuseNixSessions,
+		WarmWorktrees:    config.WarmWorktrees,
 	})
 
 	mc := &MultiClient{
@@ -87,13 +94,16 @@ func NewMultiClient(config MultiClientConfig) (*MultiClient, error) {
 		}
 
 		worker, err := NewWorkerWithSharedResources(WorkerConfig{
-			ServerURL:   srv.URL,
-			WorkerID:    config.WorkerID,
-			MaxJobs:     config.MaxJobs,
-			GitCacheDir: config.GitCacheDir,
-			WorktreeDir: config.WorktreeDir,
-			UseNixShell: config.UseNixShell,
-			Debug:       config.Debug,
+			ServerURL:        srv.URL,
+			WorkerID:         config.WorkerID,
+			MaxJobs:          config.MaxJobs,
+			Tags:             config.Tags,
+			GitCacheDir:      config.GitCacheDir,
+			WorktreeDir:      config.WorktreeDir,
+			UseNixShell:      config.UseNixShell,
+			Debug:            config.Debug,
+			ReuseNixSessions: config.ReuseNixSessions,
+			WarmWorktrees:    config.WarmWorktrees,
 		}, pool, executor, name)
 		if err != nil {
 			cancel()
@@ -200,6 +210,7 @@ func (mc *MultiClient) Stop() {
 	for _, w := range mc.workers {
 		w.Stop()
 	}
+	mc.executor.Close()
 }
 
 // ServerCount returns the number of configured servers
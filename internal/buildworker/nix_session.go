@@ -0,0 +1,158 @@
+package buildworker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// nixSessionMaxAge bounds how long a captured dev shell environment is
+// reused, so store paths removed by garbage collection are picked up again
+const nixSessionMaxAge = time.Hour
+
+// nixSession is the environment of a repo's dev shell, captured once with
+// nix develop and reused for the jobs of that repo
+type nixSession struct {
+	mu         sync.Mutex // Held while capturing, so concurrent jobs capture once
+	shellHash  string     // Hash of the files defining the dev shell
+	captureDir string     // Worktree the environment was captured in
+	env        []string
+	capturedAt time.Time
+}
+
+// envCaptureFunc returns the environment nix develop sets up in dir
+type envCaptureFunc func(ctx context.Context, dir string) ([]string, error)
+
+// nixSessionCache keeps one dev shell session per repo. A session is
+// replaced when the repo's dev shell definition changes or it gets too old.
+type nixSessionCache struct {
+	mu       sync.Mutex
+	sessions map[string]*nixSession // By repo
+	capture  envCaptureFunc
+	now      func() time.Time
+}
+
+func newNixSessionCache() *nixSessionCache {
+	return &nixSessionCache{
+		sessions: make(map[string]*nixSession),
+		capture:  captureNixEnv,
+		now:      time.Now,
+	}
+}
+
+// Env returns the dev shell environment for a job of repo checked out in
+// dir, reusing the repo's session if its dev shell is unchanged. reused
+// reports whether an existing session was used.
+func (c *nixSessionCache) Env(ctx context.Context, repo, dir string) (env []string, reused bool, err error) {
+	shellHash, err := devShellHash(dir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	s, ok := c.sessions[repo]
+	if !ok {
+		s = &nixSession{}
+		c.sessions[repo] = s
+	}
+	c.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reused = s.env != nil && s.shellHash == shellHash && c.now().Sub(s.capturedAt) <= nixSessionMaxAge
+	if !reused {
+		captured, err := c.capture(ctx, dir)
+		if err != nil {
+			return nil, false, err
+		}
+		s.shellHash = shellHash
+		s.captureDir = dir
+		s.env = captured
+		s.capturedAt = c.now()
+	}
+
+	return relocateEnv(s.env, s.captureDir, dir), reused, nil
+}
+
+// devShellHash hashes the files that define a repo's dev shell: flake.lock
+// and the .nix files at the root of the checkout
+func devShellHash(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (name == "flake.lock" || strings.HasSuffix(name, ".nix")) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// relocateEnv points paths into the worktree the environment was captured in
+// at the job's worktree, since shell hooks often export the checkout path
+func relocateEnv(env []string, from, to string) []string {
+	result := make([]string, len(env))
+	for i, kv := range env {
+		if from != to {
+			kv = strings.ReplaceAll(kv, from, to)
+		}
+		result[i] = kv
+	}
+	return result
+}
+
+// sessionLocalEnv lists variables describing the capturing shell rather than
+// the dev shell; they are left out of captured environments
+var sessionLocalEnv = map[string]bool{"PWD": true, "OLDPWD": true, "SHLVL": true, "_": true}
+
+// captureNixEnv runs nix develop once in dir and returns the environment it
+// sets up
+func captureNixEnv(ctx context.Context, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "nix", "develop", "--command", "env", "-0")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("capturing nix develop environment: %w\n%s", err, stderr.String())
+	}
+	return parseEnv0(out), nil
+}
+
+// parseEnv0 parses the NUL-separated output of env -0
+func parseEnv0(out []byte) []string {
+	var env []string
+	for _, kv := range strings.Split(string(out), "\x00") {
+		name, _, ok := strings.Cut(kv, "=")
+		if !ok || name == "" || sessionLocalEnv[name] {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
@@ -0,0 +1,181 @@
+// internal/buildworker/nix_session_test.go
+package buildworker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeCapture records capture calls and returns an environment that refers
+// to the directory it was captured in
+type fakeCapture struct {
+	dirs []string
+	err  error
+}
+
+func (f *fakeCapture) capture(ctx context.Context, dir string) ([]string, error) {
+	f.dirs = append(f.dirs, dir)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []string{"PATH=/nix/store/abc-cargo/bin", "PROJECT_ROOT=" + dir}, nil
+}
+
+// newCheckout creates a worktree directory with the given dev shell files
+func newCheckout(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNixSessionCache_ReusesSessionPerRepo(t *testing.T) {
+	fake := &fakeCapture{}
+	cache := newNixSessionCache()
+	cache.capture = fake.capture
+	flake := map[string]string{"flake.nix": "{ devShells = 1; }", "flake.lock": "{}"}
+
+	first := newCheckout(t, flake)
+	env, reused, err := cache.Env(context.Background(), "git://host/repo", first)
+	if err != nil || reused {
+		t.Fatalf("first job: reused=%v err=%v, want a new session", reused, err)
+	}
+	if !slices.Contains(env, "PROJECT_ROOT="+first) {
+		t.Errorf("env = %q, want PROJECT_ROOT of the first worktree", env)
+	}
+
+	// Same dev shell in another worktree: reused, with paths relocated
+	second := newCheckout(t, flake)
+	env, reused, err = cache.Env(context.Background(), "git://host/repo", second)
+	if err != nil || !reused {
+		t.Fatalf("second job: reused=%v err=%v, want the session reused", reused, err)
+	}
+	want := []string{"PATH=/nix/store/abc-cargo/bin", "PROJECT_ROOT=" + second}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("env = %q, want %q", env, want)
+	}
+
+	// Another repo gets its own session
+	if _, reused, _ := cache.Env(context.Background(), "git://host/other", newCheckout(t, flake)); reused {
+		t.Error("a different repo should not reuse the session")
+	}
+
+	if len(fake.dirs) != 2 {
+		t.Errorf("captured %d times, want once per repo", len(fake.dirs))
+	}
+}
+
+func TestNixSessionCache_InvalidatesOnDevShellChange(t *testing.T) {
+	fake := &fakeCapture{}
+	cache := newNixSessionCache()
+	cache.capture = fake.capture
+	repo := "git://host/repo"
+
+	cache.Env(context.Background(), repo, newCheckout(t, map[string]string{"flake.nix": "v1", "flake.lock": "lock1"}))
+
+	changes := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"flake.lock updated", map[string]string{"flake.nix": "v1", "flake.lock": "lock2"}},
+		{"flake.nix edited", map[string]string{"flake.nix": "v2", "flake.lock": "lock2"}},
+		{"nix file added", map[string]string{"flake.nix": "v2", "flake.lock": "lock2", "shell.nix": "{}"}},
+	}
+	for _, change := range changes {
+		if _, reused, _ := cache.Env(context.Background(), repo, newCheckout(t, change.files)); reused {
+			t.Errorf("%s: session reused, want a new capture", change.name)
+		}
+	}
+
+	// Files outside the dev shell definition don't invalidate the session
+	unchanged := map[string]string{"flake.nix": "v2", "flake.lock": "lock2", "shell.nix": "{}", "main.rs": "fn main() {}"}
+	if _, reused, _ := cache.Env(context.Background(), repo, newCheckout(t, unchanged)); !reused {
+		t.Error("changing source files should keep the session")
+	}
+
+	if len(fake.dirs) != 4 {
+		t.Errorf("captured %d times, want 4", len(fake.dirs))
+	}
+}
+
+func TestNixSessionCache_ExpiresAndRetriesFailedCapture(t *testing.T) {
+	fake := &fakeCapture{err: errors.New("nix develop failed")}
+	cache := newNixSessionCache()
+	cache.capture = fake.capture
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	repo := "git://host/repo"
+	flake := map[string]string{"flake.nix": "v1"}
+
+	// A failed capture is not cached
+	if _, _, err := cache.Env(context.Background(), repo, newCheckout(t, flake)); err == nil {
+		t.Fatal("expected the capture error")
+	}
+	fake.err = nil
+	if _, reused, err := cache.Env(context.Background(), repo, newCheckout(t, flake)); err != nil || reused {
+		t.Fatalf("after a failed capture: reused=%v err=%v, want a new session", reused, err)
+	}
+
+	now = now.Add(nixSessionMaxAge)
+	if _, reused, _ := cache.Env(context.Background(), repo, newCheckout(t, flake)); !reused {
+		t.Error("session should be reused up to its maximum age")
+	}
+	now = now.Add(time.Second)
+	if _, reused, _ := cache.Env(context.Background(), repo, newCheckout(t, flake)); reused {
+		t.Error("an expired session should be captured again")
+	}
+}
+
+func TestParseEnv0(t *testing.T) {
+	out := []byte("PATH=/nix/store/x/bin\x00PWD=/tmp/job-1\x00SHLVL=2\x00MULTI=a\nb\x00EMPTY=\x00_=/usr/bin/env\x00")
+	want := []string{"PATH=/nix/store/x/bin", "MULTI=a\nb", "EMPTY="}
+	if got := parseEnv0(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEnv0 = %q, want %q", got, want)
+	}
+}
+
+func TestExecutor_RunJob_ReusesNixSession(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	executor := NewExecutor(ExecutorConfig{
+		GitCacheDir:      repoDir,
+		WorktreeDir:      t.TempDir(),
+		UseNixShell:      true,
+		ReuseNixSessions: true,
+	})
+	fake := &fakeCapture{}
+	executor.sessions.capture = fake.capture
+
+	// The fake PATH has no binaries, so the command sticks to shell builtins.
+	// The second job must not see the artifact the first one left behind.
+	for i, id := range []string{"job-1", "job-2"} {
+		result, err := executor.RunJob(context.Background(), Job{
+			ID:      id,
+			Repo:    repoDir,
+			Command: `echo "$PATH"; test "$PROJECT_ROOT" = "$(pwd)" && echo root-ok; for f in *; do echo "$f"; done; echo built > artifact`,
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: RunJob failed: %v", id, err)
+		}
+		if result.ExitCode != 0 {
+			t.Fatalf("%s: exit code %d, output %q", id, result.ExitCode, result.Output)
+		}
+		want := "/nix/store/abc-cargo/bin\nroot-ok\nREADME.md\n"
+		if result.Output != want {
+			t.Errorf("job %d output = %q, want %q (session env in a clean worktree)", i+1, result.Output, want)
+		}
+	}
+
+	if len(fake.dirs) != 1 {
+		t.Errorf("captured %d times, want the session reused by the second job", len(fake.dirs))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package buildworker
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves cmd's default cancellation on this
+// platform: only the shell is killed, not the processes it started
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
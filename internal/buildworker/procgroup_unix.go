@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package buildworker
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel starts cmd in its own process group and kills the
+// whole group when its context is done, so the processes the shell started
+// (cargo, rustc, ...) die with it instead of keeping the output pipes open
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,147 @@
+package buildworker
+
+import (
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// warmPoolCommits is how many recently built commits the warm pool keeps
+// worktrees for; the worktrees of older commits are removed
+const warmPoolCommits = 2
+
+// commitSHAPattern matches full SHA-1 and SHA-256 commit hashes. Only
+// commits named by hash are pooled, since branch names move.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// warmKey identifies the checkout of a warm worktree
+type warmKey struct {
+	repo   string
+	commit string // Full commit hash
+}
+
+// warmPool keeps worktrees checked out at recently built commits ahead of
+// the jobs for them, so such a job starts without waiting for a checkout.
+// Worktrees handed out are replaced in the background.
+type warmPool struct {
+	size   int // Ready worktrees kept per commit
+	create func(key warmKey) (string, error)
+	remove func(key warmKey, path string)
+
+	mu      sync.Mutex
+	ready   map[warmKey][]string
+	pending map[warmKey]int // Worktrees being created
+	recent  []warmKey       // Most recently used first
+	closed  bool
+	wg      sync.WaitGroup // Background creations and removals
+}
+
+func newWarmPool(size int, create func(key warmKey) (string, error), remove func(key warmKey, path string)) *warmPool {
+	return &warmPool{
+		size:    size,
+		create:  create,
+		remove:  remove,
+		ready:   make(map[warmKey][]string),
+		pending: make(map[warmKey]int),
+	}
+}
+
+// take hands out a ready worktree checked out at key and starts creating
+// its replacement. ok is false if none is ready; the caller then checks out
+// its own and calls keep once the commit is available.
+func (p *warmPool) take(key warmKey) (path string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	paths := p.ready[key]
+	if p.closed || len(paths) == 0 {
+		return "", false
+	}
+	path = paths[len(paths)-1]
+	p.ready[key] = paths[:len(paths)-1]
+	p.useLocked(key)
+	return path, true
+}
+
+// keep marks key as recently built and starts creating worktrees for it
+func (p *warmPool) keep(key warmKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed {
+		p.useLocked(key)
+	}
+}
+
+// useLocked moves key to the front of the recent commits, evicting the
+// commits that drop out, and tops up its ready worktrees
+func (p *warmPool) useLocked(key warmKey) {
+	if i := slices.Index(p.recent, key); i >= 0 {
+		p.recent = slices.Delete(p.recent, i, i+1)
+	}
+	p.recent = slices.Insert(p.recent, 0, key)
+	for len(p.recent) > warmPoolCommits {
+		p.evictLocked(p.recent[len(p.recent)-1])
+		p.recent = p.recent[:len(p.recent)-1]
+	}
+
+	for len(p.ready[key])+p.pending[key] < p.size {
+		p.pending[key]++
+		p.wg.Add(1)
+		go p.add(key)
+	}
+}
+
+// evictLocked removes the ready worktrees of key in the background.
+// Worktrees still being created are removed once they are done.
+func (p *warmPool) evictLocked(key warmKey) {
+	for _, path := range p.ready[key] {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.remove(key, path)
+		}()
+	}
+	delete(p.ready, key)
+}
+
+// add creates a worktree for key and adds it to the ready ones, unless the
+// pool no longer wants it
+func (p *warmPool) add(key warmKey) {
+	defer p.wg.Done()
+
+	path, err := p.create(key)
+
+	p.mu.Lock()
+	p.pending[key]--
+	if p.pending[key] == 0 {
+		delete(p.pending, key)
+	}
+	wanted := err == nil && !p.closed && slices.Contains(p.recent, key)
+	if wanted {
+		p.ready[key] = append(p.ready[key], path)
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		log.Printf("[executor] warm worktree for %s@%s: %v", key.repo, key.commit, err)
+		return
+	}
+	if !wanted {
+		p.remove(key, path)
+	}
+}
+
+// close removes all ready worktrees and waits for background work to finish
+func (p *warmPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	for key := range p.ready {
+		p.evictLocked(key)
+	}
+	p.recent = nil
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
@@ -0,0 +1,237 @@
+// internal/buildworker/warm_pool_test.go
+package buildworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWorktrees records the worktrees a warm pool creates and removes
+type fakeWorktrees struct {
+	mu      sync.Mutex
+	n       int
+	fail    bool
+	live    map[string]warmKey
+	removed []string
+}
+
+func newFakeWorktrees() *fakeWorktrees {
+	return &fakeWorktrees{live: make(map[string]warmKey)}
+}
+
+func (f *fakeWorktrees) create(key warmKey) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return "", errors.New("checkout failed")
+	}
+	f.n++
+	path := fmt.Sprintf("%s-%d", key.commit, f.n)
+	f.live[path] = key
+	return path, nil
+}
+
+func (f *fakeWorktrees) remove(key warmKey, path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.live, path)
+	f.removed = append(f.removed, path)
+}
+
+func (f *fakeWorktrees) liveFor(key warmKey) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, k := range f.live {
+		if k == key {
+			n++
+		}
+	}
+	return n
+}
+
+// waitReady waits until the pool has n ready worktrees for key
+func waitReady(t *testing.T, p *warmPool, key warmKey, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mu.Lock()
+		got := len(p.ready[key])
+		busy := p.pending[key]
+		p.mu.Unlock()
+		if got == n && busy == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d ready worktrees for %v (%d pending), want %d", got, key, busy, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWarmPool_Assignment(t *testing.T) {
+	fake := newFakeWorktrees()
+	p := newWarmPool(2, fake.create, fake.remove)
+	defer p.close()
+
+	key := warmKey{repo: "/repo", commit: "aaa"}
+
+	// Nothing is warm before the commit was built
+	if _, ok := p.take(key); ok {
+		t.Fatal("take before keep returned a worktree")
+	}
+
+	p.keep(key)
+	waitReady(t, p, key, 2)
+
+	path, ok := p.take(key)
+	if !ok {
+		t.Fatal("take after keep returned no worktree")
+	}
+	if !strings.HasPrefix(path, "aaa-") {
+		t.Errorf("got worktree %q, want one at commit aaa", path)
+	}
+
+	// The taken worktree is replaced in the background
+	waitReady(t, p, key, 2)
+	if n := fake.liveFor(key); n != 3 {
+		t.Errorf("got %d worktrees at aaa, want 2 ready plus the taken one", n)
+	}
+
+	// Other commits are not served from aaa's worktrees
+	if _, ok := p.take(warmKey{repo: "/repo", commit: "bbb"}); ok {
+		t.Error("take for another commit returned a worktree")
+	}
+}
+
+func TestWarmPool_EvictsOldCommits(t *testing.T) {
+	fake := newFakeWorktrees()
+	p := newWarmPool(1, fake.create, fake.remove)
+	defer p.close()
+
+	keys := []warmKey{
+		{repo: "/repo", commit: "aaa"},
+		{repo: "/repo", commit: "bbb"},
+		{repo: "/repo", commit: "ccc"},
+	}
+	for _, key := range keys[:2] {
+		p.keep(key)
+		waitReady(t, p, key, 1)
+	}
+
+	// Using aaa again makes bbb the oldest commit, so ccc evicts bbb
+	if _, ok := p.take(keys[0]); !ok {
+		t.Fatal("take for aaa returned no worktree")
+	}
+	waitReady(t, p, keys[0], 1)
+	p.keep(keys[2])
+	waitReady(t, p, keys[2], 1)
+
+	p.wg.Wait()
+	if n := fake.liveFor(keys[1]); n != 0 {
+		t.Errorf("got %d worktrees at evicted commit bbb, want 0", n)
+	}
+	if _, ok := p.take(keys[1]); ok {
+		t.Error("take for evicted commit bbb returned a worktree")
+	}
+	if _, ok := p.take(keys[2]); !ok {
+		t.Error("take for ccc returned no worktree")
+	}
+}
+
+func TestWarmPool_CreateFailure(t *testing.T) {
+	fake := newFakeWorktrees()
+	fake.fail = true
+	p := newWarmPool(1, fake.create, fake.remove)
+	defer p.close()
+
+	key := warmKey{repo: "/repo", commit: "aaa"}
+	p.keep(key)
+	waitReady(t, p, key, 0)
+	if _, ok := p.take(key); ok {
+		t.Fatal("take returned a worktree although checkouts fail")
+	}
+
+	// A later build retries
+	fake.mu.Lock()
+	fake.fail = false
+	fake.mu.Unlock()
+	p.keep(key)
+	waitReady(t, p, key, 1)
+}
+
+func TestWarmPool_Close(t *testing.T) {
+	fake := newFakeWorktrees()
+	p := newWarmPool(2, fake.create, fake.remove)
+
+	key := warmKey{repo: "/repo", commit: "aaa"}
+	p.keep(key)
+	waitReady(t, p, key, 2)
+	taken, _ := p.take(key) // Started a refill
+
+	p.close()
+	fake.mu.Lock()
+	_, kept := fake.live[taken]
+	live := len(fake.live)
+	fake.mu.Unlock()
+	if live != 1 || !kept {
+		t.Errorf("got %d worktrees after close (taken one kept: %v), want only the taken one", live, kept)
+	}
+
+	p.keep(key)
+	if _, ok := p.take(key); ok {
+		t.Error("take after close returned a worktree")
+	}
+}
+
+func TestExecutor_RunJob_WarmWorktrees(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	worktreeDir := t.TempDir()
+
+	executor := NewExecutor(ExecutorConfig{
+		WorktreeDir:   worktreeDir,
+		WarmWorktrees: 1,
+	})
+
+	run := func(id string) string {
+		t.Helper()
+		result, err := executor.RunJob(context.Background(), Job{
+			ID:      id,
+			Repo:    repoDir,
+			Command: "pwd && cat README.md",
+		}, nil)
+		if err != nil {
+			t.Fatalf("RunJob failed: %v", err)
+		}
+		if result.ExitCode != 0 || !strings.Contains(result.Output, "# Test") {
+			t.Fatalf("got exit code %d, output %q", result.ExitCode, result.Output)
+		}
+		return filepath.Base(strings.SplitN(result.Output, "\n", 2)[0])
+	}
+
+	// The first job checks out its own worktree; the pool warms one for HEAD
+	if dir := run("job-1"); !strings.HasPrefix(dir, "job-") {
+		t.Errorf("first job ran in %s, want its own worktree", dir)
+	}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoDir
+	head, _ := cmd.Output()
+	waitReady(t, executor.warm, warmKey{repo: repoDir, commit: strings.TrimSpace(string(head))}, 1)
+
+	if dir := run("job-2"); !strings.HasPrefix(dir, "warm-") {
+		t.Errorf("second job ran in %s, want a warm worktree", dir)
+	}
+
+	executor.Close()
+	if entries, _ := os.ReadDir(worktreeDir); len(entries) != 0 {
+		t.Errorf("got %d worktrees left after Close, want 0", len(entries))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -13,17 +14,24 @@ import (
 const (
 	ExecutorClaudeCode = "claude-code"
 	ExecutorOpenCode   = "opencode"
+	ExecutorGemini     = "gemini"
 )
 
 // Config holds all application configuration
 type Config struct {
 	General       GeneralConfig       `toml:"general"`
-	Claude        ClaudeConfig        `toml:"claude"`
 	Notifications NotificationsConfig `toml:"notifications"`
 	Web           WebConfig           `toml:"web"`
 	BuildPool     BuildPoolConfig     `toml:"build_pool"`
 	Prompts       PromptsConfig       `toml:"prompts"`
 	GitHubIssues  GitHubIssuesConfig  `toml:"github_issues"`
+	Sandbox       SandboxConfig       `toml:"sandbox"`
+	AgentTimeouts AgentTimeoutConfig  `toml:"agent_timeouts"`
+	AgentRetry    AgentRetryConfig    `toml:"agent_retry"`
+
+	// StatusVocabulary maps custom epic frontmatter status words to
+	// not_started, in_progress or complete (e.g. wip = "in_progress")
+	StatusVocabulary map[string]string `toml:"status_vocabulary"`
 }
 
 // GitHubIssuesConfig holds GitHub issues integration settings
@@ -38,6 +46,55 @@ type GitHubIssuesConfig struct {
 	PriorityLabels   map[string]string `toml:"priority_labels"`
 }
 
+// SandboxConfig holds settings for running agents inside a container
+type SandboxConfig struct {
+	Enabled bool     `toml:"enabled"`
+	Runtime string   `toml:"runtime"` // Container runtime: "docker" (default) or "podman"
+	Image   string   `toml:"image"`   // Image that provides the executor binary (claude, opencode or gemini)
+	Flags   []string `toml:"flags"`   // Extra flags for "<runtime> run" (e.g. ["--network=host"])
+}
+
+// AgentTimeoutConfig limits how long an agent may run, by executor, before it
+// is killed and fails as timed out (0 = no limit)
+type AgentTimeoutConfig struct {
+	ClaudeCodeSecs int `toml:"claude_code_secs"`
+	OpenCodeSecs   int `toml:"opencode_secs"`
+	GeminiSecs     int `toml:"gemini_secs"`
+	StuckAfterSecs int `toml:"stuck_after_secs"` // Flag running agents as stuck after this long without output (0 = never)
+	StopGraceSecs  int `toml:"stop_grace_secs"`  // Time stopped agents get to exit after SIGTERM before they are killed (0 = kill right away)
+}
+
+// StopGrace returns how long a stopped agent gets to exit after SIGTERM
+// before it is killed
+func (c AgentTimeoutConfig) StopGrace() time.Duration {
+	return time.Duration(c.StopGraceSecs) * time.Second
+}
+
+// StuckAfter returns how long a running agent may go without output before
+// it is flagged as stuck
+func (c AgentTimeoutConfig) StuckAfter() time.Duration {
+	return time.Duration(c.StuckAfterSecs) * time.Second
+}
+
+// For returns the timeout of agents run with the given executor type
+func (c AgentTimeoutConfig) For(executorType string) time.Duration {
+	secs := c.ClaudeCodeSecs
+	switch executorType {
+	case ExecutorOpenCode:
+		secs = c.OpenCodeSecs
+	case ExecutorGemini:
+		secs = c.GeminiSecs
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// AgentRetryConfig makes agents that fail for a transient reason (API
+// overload, network errors) resume automatically with exponential backoff
+type AgentRetryConfig struct {
+	MaxAttempts   int `toml:"max_attempts"`    // Resumes per agent (0 = no automatic retries)
+	BaseDelaySecs int `toml:"base_delay_secs"` // Wait before the first resume, doubling for each further one
+}
+
 // PromptsConfig holds prompt template settings
 type PromptsConfig struct {
 	OverrideDir string `toml:"override_dir"` // Directory for custom prompt overrides
@@ -45,18 +102,56 @@ type PromptsConfig struct {
 
 // GeneralConfig holds general settings
 type GeneralConfig struct {
-	ProjectRoot       string `toml:"project_root"`
-	WorktreeDir       string `toml:"worktree_dir"`
-	MaxParallelAgents int    `toml:"max_parallel_agents"`
-	DatabasePath      string `toml:"database_path"`
-	Executor          string `toml:"executor"`       // "claude-code" (default) or "opencode"
-	OpenCodeModel     string `toml:"opencode_model"` // Model for OpenCode (e.g., "zai-coding-plan/glm-4.7")
+	ProjectRoot            string `toml:"project_root"`
+	WorktreeDir            string `toml:"worktree_dir"`
+	MaxParallelAgents      int    `toml:"max_parallel_agents"`
+	DatabasePath           string `toml:"database_path"`
+	Executor               string `toml:"executor"`                 // "claude-code" (default), "opencode" or "gemini"
+	ClaudeModel            string `toml:"claude_model"`             // Model for Claude Code (e.g., "sonnet"; empty = the account default)
+	OpenCodeModel          string `toml:"opencode_model"`           // Model for OpenCode (e.g., "zai-coding-plan/glm-4.7")
+	GeminiModel            string `toml:"gemini_model"`             // Model for Gemini CLI (e.g., "gemini-2.5-pro")
+	ClaudeBinaryPath       string `toml:"claude_binary_path"`       // Claude Code binary to run (empty = "claude" from PATH)
+	OpenCodeBinaryPath     string `toml:"opencode_binary_path"`     // OpenCode binary to run (empty = "opencode" from PATH)
+	GeminiBinaryPath       string `toml:"gemini_binary_path"`       // Gemini CLI binary to run (empty = "gemini" from PATH)
+	SampleResources        bool   `toml:"sample_resources"`         // Sample CPU/memory of running agents in the TUI
+	MaxStartsPerTick       int    `toml:"max_starts_per_tick"`      // Max agents auto mode starts per tick (0 = unlimited)
+	DefaultGroupTier       int    `toml:"default_group_tier"`       // Priority tier for modules without an explicit one
+	MaxAgentsPerModule     int    `toml:"max_agents_per_module"`    // Agents running tasks of one module at once (0 = unlimited)
+	MaxMaintenanceAgents   int    `toml:"max_maintenance_agents"`   // Maintenance agents running at once (0 = only limited by max_parallel_agents)
+	ModuleTestTimeoutSecs  int    `toml:"module_test_timeout_secs"` // How long the TUI waits for a module test run
+	ModuleTestChangedOnly  bool   `toml:"module_test_changed_only"` // [x] runs only tests of code changed since the module's tests last passed
+	RemovedEpicAction      string `toml:"removed_epic_action"`      // "archive" (default) or "delete" tasks whose epic file was deleted
+	HistoryLogLines        int    `toml:"history_log_lines"`        // Lines of an agent's log shown in the TUI history detail
+	AgentOutputLines       int    `toml:"agent_output_lines"`       // Lines of a running agent's output kept in memory (the log has all)
+	DBWriteQueueSize       int    `toml:"db_write_queue_size"`      // Agent run updates queued for the database before callers wait
+	MaxConsecutiveFailures int    `toml:"max_consecutive_failures"` // Agent failures in a row that pause auto mode (0 = never pause)
+	AutoCommitStatus       bool   `toml:"auto_commit_status"`       // Commit and push task status changes to epic files and README
+	AgentOutputFilter      string `toml:"agent_output_filter"`      // Agent output shown in the TUI: "all" (default), "hide_tool_results" or "tool_results_only"
+	CompletedTodayReset    string `toml:"completed_today_reset"`    // When "Completed today" resets: "HH:MM" with an optional time zone, or "rolling" for the last 24h
+	SyncPlanChanges        bool   `toml:"sync_plan_changes"`        // Give running agents edits of their epic in the project as a follow-up
+	PlansDir               string `toml:"plans_dir"`                // Worktree directory agents search for their epic file (empty = "docs/plans")
+	EpicFilePattern        string `toml:"epic_file_pattern"`        // Regexp epic file names match; {epic} is the epic number, {module} the module (empty = "epic-{epic}-.*\.md$")
+	PostMergeHook          string `toml:"post_merge_hook"`          // Shell command run after a PR is merged, with the task in CLAUDE_ORCH_* variables (empty = none)
+
+	// WorktreeDirs lists candidate worktree directories (e.g. on different
+	// mounts); each new worktree goes to the one with the most free space.
+	// Overrides WorktreeDir when set.
+	WorktreeDirs      []string `toml:"worktree_dirs"`
+	MinWorktreeFreeMB int      `toml:"min_worktree_free_mb"` // Refuse to create worktrees below this free space (0 = no check)
+	ExistingBranch    string   `toml:"existing_branch"`      // Task branch left over from an earlier run: "recreate" (default), "reuse" or "error"
 }
 
-// ClaudeConfig holds Claude API settings
-type ClaudeConfig struct {
-	Model     string `toml:"model"`
-	MaxTokens int    `toml:"max_tokens"`
+// BinaryPath returns the configured binary of an executor type (empty = look
+// up the executor's name in PATH)
+func (c GeneralConfig) BinaryPath(executorType string) string {
+	switch executorType {
+	case ExecutorOpenCode:
+		return c.OpenCodeBinaryPath
+	case ExecutorGemini:
+		return c.GeminiBinaryPath
+	default:
+		return c.ClaudeBinaryPath
+	}
 }
 
 // NotificationsConfig holds notification settings
@@ -73,20 +168,35 @@ type WebConfig struct {
 
 // BuildPoolConfig holds build pool settings
 type BuildPoolConfig struct {
-	Enabled             bool                   `toml:"enabled"`
-	WebSocketPort       int                    `toml:"websocket_port"`
-	GitDaemonPort       int                    `toml:"git_daemon_port"`
-	GitDaemonListenAddr string                 `toml:"git_daemon_listen_addr"` // e.g., "127.0.0.1" for local only
-	LocalFallback       LocalFallbackConfig    `toml:"local_fallback"`
-	Timeouts            BuildPoolTimeoutConfig `toml:"timeouts"`
-	Debug               bool                   `toml:"debug"` // Enable verbose heartbeat logging
+	Enabled             bool                      `toml:"enabled"`
+	AutoStart           bool                      `toml:"auto_start"` // Start the coordinator when the TUI launches (toggle with [b])
+	WebSocketPort       int                       `toml:"websocket_port"`
+	GitDaemonPort       int                       `toml:"git_daemon_port"`
+	GitDaemonListenAddr string                    `toml:"git_daemon_listen_addr"` // e.g., "127.0.0.1" for local only
+	LocalFallback       LocalFallbackConfig       `toml:"local_fallback"`
+	Timeouts            BuildPoolTimeoutConfig    `toml:"timeouts"`
+	Debug               bool                      `toml:"debug"`             // Enable verbose heartbeat logging
+	AdminToken          string                    `toml:"admin_token"`       // Bearer token for coordinator /jobs admin endpoints
+	SerializeJobs       string                    `toml:"serialize_jobs"`    // "repo" or "commit": run one job per repo (and commit) at a time; empty = no limit
+	ResultCacheSize     int                       `toml:"result_cache_size"` // Results of successful build, test and clippy jobs kept for reuse at the same commit (0 = no caching)
+	Coordinators        []RemoteCoordinatorConfig `toml:"coordinators"`      // Further coordinators whose workers the TUI shows
+	EventLog            string                    `toml:"event_log"`         // "stderr" (not in the TUI) or a file to write coordinator events to as JSON lines; empty = off
+	Metrics             bool                      `toml:"metrics"`           // Serve Prometheus metrics on the coordinator's GET /metrics
+}
+
+// RemoteCoordinatorConfig names another build pool coordinator, e.g. of a
+// second orchestrator sharing the same build agents
+type RemoteCoordinatorConfig struct {
+	URL  string `toml:"url"`  // HTTP URL of the coordinator, e.g. "http://build-host:8081"
+	Name string `toml:"name"` // Optional label in the TUI, defaults to the URL
 }
 
 // LocalFallbackConfig configures local job execution
 type LocalFallbackConfig struct {
-	Enabled     bool   `toml:"enabled"`
-	MaxJobs     int    `toml:"max_jobs"`
-	WorktreeDir string `toml:"worktree_dir"`
+	Enabled       bool   `toml:"enabled"`
+	MaxJobs       int    `toml:"max_jobs"`
+	WorktreeDir   string `toml:"worktree_dir"`
+	WarmWorktrees int    `toml:"warm_worktrees"` // Worktrees kept checked out per recently built commit (0 = off)
 }
 
 // BuildPoolTimeoutConfig configures timeouts
@@ -101,15 +211,21 @@ func Default() *Config {
 	home, _ := os.UserHomeDir()
 	return &Config{
 		General: GeneralConfig{
-			ProjectRoot:       "",
-			WorktreeDir:       filepath.Join(home, ".claude-orchestrator", "worktrees"),
-			MaxParallelAgents: 3,
-			DatabasePath:      filepath.Join(home, ".claude-orchestrator", "orchestrator.db"),
-			Executor:          ExecutorClaudeCode, // Default to Claude Code
-		},
-		Claude: ClaudeConfig{
-			Model:     "claude-opus-4-5-20251101",
-			MaxTokens: 16000,
+			ProjectRoot:            "",
+			WorktreeDir:            filepath.Join(home, ".claude-orchestrator", "worktrees"),
+			MaxParallelAgents:      3,
+			DatabasePath:           filepath.Join(home, ".claude-orchestrator", "orchestrator.db"),
+			Executor:               ExecutorClaudeCode, // Default to Claude Code
+			ModuleTestTimeoutSecs:  120,
+			HistoryLogLines:        500,
+			AgentOutputLines:       5000,
+			DBWriteQueueSize:       1000,
+			MaxConsecutiveFailures: 5,
+			AutoCommitStatus:       true,
+			AgentOutputFilter:      "all",
+			CompletedTodayReset:    "00:00",
+			MinWorktreeFreeMB:      1024,
+			ExistingBranch:         "recreate",
 		},
 		Notifications: NotificationsConfig{
 			Desktop: true,
@@ -119,9 +235,11 @@ func Default() *Config {
 			Host: "127.0.0.1",
 		},
 		BuildPool: BuildPoolConfig{
-			Enabled:       false,
-			WebSocketPort: 8081,
-			GitDaemonPort: 9418,
+			Enabled:         false,
+			AutoStart:       true,
+			WebSocketPort:   8081,
+			GitDaemonPort:   9418,
+			ResultCacheSize: 256,
 			LocalFallback: LocalFallbackConfig{
 				Enabled:     true,
 				MaxJobs:     2,
@@ -133,6 +251,19 @@ func Default() *Config {
 				HeartbeatTimeoutSecs:  90, // Allow missing 2 heartbeats before disconnect
 			},
 		},
+		Sandbox: SandboxConfig{
+			Runtime: "docker",
+		},
+		AgentTimeouts: AgentTimeoutConfig{
+			ClaudeCodeSecs: 1800,
+			OpenCodeSecs:   1800,
+			GeminiSecs:     1800,
+			StuckAfterSecs: 300,
+			StopGraceSecs:  10,
+		},
+		AgentRetry: AgentRetryConfig{
+			BaseDelaySecs: 30,
+		},
 		GitHubIssues: GitHubIssuesConfig{
 			Enabled:          false,
 			CandidateLabel:   "orchestrator-candidate",
@@ -164,7 +295,13 @@ func Load(path string) (*Config, error) {
 	// Expand paths
 	cfg.General.ProjectRoot = ExpandPath(cfg.General.ProjectRoot)
 	cfg.General.WorktreeDir = ExpandPath(cfg.General.WorktreeDir)
+	for i, dir := range cfg.General.WorktreeDirs {
+		cfg.General.WorktreeDirs[i] = ExpandPath(dir)
+	}
 	cfg.General.DatabasePath = ExpandPath(cfg.General.DatabasePath)
+	cfg.General.ClaudeBinaryPath = ExpandPath(cfg.General.ClaudeBinaryPath)
+	cfg.General.OpenCodeBinaryPath = ExpandPath(cfg.General.OpenCodeBinaryPath)
+	cfg.General.GeminiBinaryPath = ExpandPath(cfg.General.GeminiBinaryPath)
 	cfg.BuildPool.LocalFallback.WorktreeDir = ExpandPath(cfg.BuildPool.LocalFallback.WorktreeDir)
 	cfg.Prompts.OverrideDir = ExpandPath(cfg.Prompts.OverrideDir)
 
@@ -260,9 +397,59 @@ func (c *Config) ValidateGitHubIssues() error {
 	return nil
 }
 
+// ValidateSandbox validates the sandbox configuration
+func (c *Config) ValidateSandbox() error {
+	if !c.Sandbox.Enabled {
+		return nil
+	}
+	if c.Sandbox.Image == "" {
+		return fmt.Errorf("sandbox.image is required when enabled")
+	}
+	if c.Sandbox.Runtime != "" && c.Sandbox.Runtime != "docker" && c.Sandbox.Runtime != "podman" {
+		return fmt.Errorf("invalid sandbox.runtime '%s': must be 'docker' or 'podman'", c.Sandbox.Runtime)
+	}
+	return nil
+}
+
+// CompletedTodayStart returns when the TUI's "Completed today" window began
+// at now, according to CompletedTodayReset: the last time the clock passed
+// the reset time ("06:00", "00:00 UTC" or "09:30 America/New_York"; local
+// time without a zone), or 24 hours before now for "rolling"
+func (g GeneralConfig) CompletedTodayStart(now time.Time) (time.Time, error) {
+	reset := strings.TrimSpace(g.CompletedTodayReset)
+	if reset == "rolling" {
+		return now.Add(-24 * time.Hour), nil
+	}
+	if reset == "" {
+		reset = "00:00"
+	}
+
+	fields := strings.Fields(reset)
+	if len(fields) > 2 {
+		return time.Time{}, fmt.Errorf("invalid completed_today_reset %q: want \"HH:MM [zone]\" or \"rolling\"", reset)
+	}
+	clock, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid completed_today_reset %q: want \"HH:MM [zone]\" or \"rolling\"", reset)
+	}
+	loc := now.Location()
+	if len(fields) == 2 {
+		if loc, err = time.LoadLocation(fields[1]); err != nil {
+			return time.Time{}, fmt.Errorf("invalid completed_today_reset time zone %q: %w", fields[1], err)
+		}
+	}
+
+	local := now.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start, nil
+}
+
 // IsValidExecutor checks if an executor type is valid
 func IsValidExecutor(executor string) bool {
-	return executor == ExecutorClaudeCode || executor == ExecutorOpenCode
+	return executor == ExecutorClaudeCode || executor == ExecutorOpenCode || executor == ExecutorGemini
 }
 
 // ValidateExecutor validates the executor configuration
@@ -271,8 +458,8 @@ func (c *Config) ValidateExecutor() error {
 		return nil // Will use default
 	}
 	if !IsValidExecutor(c.General.Executor) {
-		return fmt.Errorf("invalid executor '%s': must be '%s' or '%s'",
-			c.General.Executor, ExecutorClaudeCode, ExecutorOpenCode)
+		return fmt.Errorf("invalid executor '%s': must be '%s', '%s' or '%s'",
+			c.General.Executor, ExecutorClaudeCode, ExecutorOpenCode, ExecutorGemini)
 	}
 	return nil
 }
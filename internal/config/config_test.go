@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -296,3 +297,156 @@ func TestConfig_ValidateGitHubIssues(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ValidateSandbox(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SandboxConfig
+		wantErr bool
+	}{
+		{
+			name:    "disabled is valid",
+			cfg:     SandboxConfig{Enabled: false},
+			wantErr: false,
+		},
+		{
+			name:    "enabled without image is invalid",
+			cfg:     SandboxConfig{Enabled: true, Runtime: "docker"},
+			wantErr: true,
+		},
+		{
+			name:    "enabled with unknown runtime is invalid",
+			cfg:     SandboxConfig{Enabled: true, Runtime: "lxc", Image: "agent:latest"},
+			wantErr: true,
+		},
+		{
+			name:    "podman with image is valid",
+			cfg:     SandboxConfig{Enabled: true, Runtime: "podman", Image: "agent:latest"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sandbox: tt.cfg}
+			err := cfg.ValidateSandbox()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSandbox() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_StatusVocabulary(t *testing.T) {
+	tmpFile := writeTempConfig(t, `
+[status_vocabulary]
+wip = "in_progress"
+blocked = "not_started"
+`)
+	cfg, err := Load(tmpFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.StatusVocabulary["wip"] != "in_progress" {
+		t.Errorf("StatusVocabulary[wip] = %q, want in_progress", cfg.StatusVocabulary["wip"])
+	}
+	if cfg.StatusVocabulary["blocked"] != "not_started" {
+		t.Errorf("StatusVocabulary[blocked] = %q, want not_started", cfg.StatusVocabulary["blocked"])
+	}
+}
+
+func TestConfig_AgentTimeouts(t *testing.T) {
+	cfg := Default()
+	if got := cfg.AgentTimeouts.For(ExecutorClaudeCode); got != 30*time.Minute {
+		t.Errorf("default claude-code timeout = %s, want 30m", got)
+	}
+
+	tmpFile := writeTempConfig(t, `
+[agent_timeouts]
+opencode_secs = 600
+gemini_secs = 900
+stop_grace_secs = 0
+`)
+	cfg, err := Load(tmpFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.AgentTimeouts.For(ExecutorOpenCode); got != 10*time.Minute {
+		t.Errorf("opencode timeout = %s, want 10m", got)
+	}
+	if got := cfg.AgentTimeouts.For(ExecutorGemini); got != 15*time.Minute {
+		t.Errorf("gemini timeout = %s, want 15m", got)
+	}
+	if got := cfg.AgentTimeouts.For(ExecutorClaudeCode); got != 30*time.Minute {
+		t.Errorf("claude-code timeout = %s, want the default kept", got)
+	}
+	if got := cfg.AgentTimeouts.StuckAfter(); got != 5*time.Minute {
+		t.Errorf("stuck after = %s, want the 5m default kept", got)
+	}
+	if got := cfg.AgentTimeouts.StopGrace(); got != 0 {
+		t.Errorf("stop grace = %s, want 0 as configured", got)
+	}
+	if got := Default().AgentTimeouts.StopGrace(); got != 10*time.Second {
+		t.Errorf("default stop grace = %s, want 10s", got)
+	}
+}
+
+func TestGeneralConfig_BinaryPath(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tmpFile := writeTempConfig(t, `
+[general]
+claude_binary_path = "~/bin/claude"
+gemini_binary_path = "/opt/gemini/bin/gemini"
+`)
+	cfg, err := Load(tmpFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := cfg.General.BinaryPath(ExecutorClaudeCode), filepath.Join(home, "bin/claude"); got != want {
+		t.Errorf("claude-code binary = %q, want %q", got, want)
+	}
+	if got := cfg.General.BinaryPath(ExecutorGemini); got != "/opt/gemini/bin/gemini" {
+		t.Errorf("gemini binary = %q, want the configured path", got)
+	}
+	if got := cfg.General.BinaryPath(ExecutorOpenCode); got != "" {
+		t.Errorf("opencode binary = %q, want none (looked up in PATH)", got)
+	}
+}
+
+func TestGeneralConfig_CompletedTodayStart(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 2, 5, 30, 0, 0, berlin)
+
+	tests := []struct {
+		reset string
+		want  time.Time
+	}{
+		{"", time.Date(2026, 3, 2, 0, 0, 0, 0, berlin)},
+		{"00:00", time.Date(2026, 3, 2, 0, 0, 0, 0, berlin)},
+		{"05:30", now}, // The boundary itself starts a new day
+		{"06:00", time.Date(2026, 3, 1, 6, 0, 0, 0, berlin)}, // Not reached yet today
+		{"04:00 UTC", time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC)},
+		{"06:00 UTC", time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)}, // 04:30 UTC now
+		{"rolling", now.Add(-24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := GeneralConfig{CompletedTodayReset: tt.reset}.CompletedTodayStart(now)
+		if err != nil {
+			t.Errorf("CompletedTodayStart(%q) error = %v", tt.reset, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("CompletedTodayStart(%q) = %s, want %s", tt.reset, got, tt.want)
+		}
+	}
+
+	for _, reset := range []string{"6am", "25:00", "06:00 Mars/Olympus", "06:00 UTC extra"} {
+		if _, err := (GeneralConfig{CompletedTodayReset: reset}).CompletedTodayStart(now); err == nil {
+			t.Errorf("CompletedTodayStart(%q) should fail", reset)
+		}
+	}
+}
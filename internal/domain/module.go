@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ModuleSummary holds aggregated data for a module
+type ModuleSummary struct {
+	Name            string `json:"name"`
+	TotalEpics      int    `json:"total_epics"`
+	CompletedEpics  int    `json:"completed_epics"`
+	InProgressEpics int    `json:"in_progress_epics"`
+	TotalTests      int    `json:"total_tests"`
+	PassedTests     int    `json:"passed_tests"`
+	FailedTests     int    `json:"failed_tests"`
+	Coverage        string `json:"coverage,omitempty"`
+}
+
+// SummarizeModules aggregates task data by module, sorted by module name
+func SummarizeModules(tasks []*Task) []*ModuleSummary {
+	moduleMap := make(map[string]*ModuleSummary)
+
+	for _, task := range tasks {
+		mod := task.ID.Module
+		if _, exists := moduleMap[mod]; !exists {
+			moduleMap[mod] = &ModuleSummary{Name: mod}
+		}
+		ms := moduleMap[mod]
+		ms.TotalEpics++
+
+		switch task.Status {
+		case StatusComplete:
+			ms.CompletedEpics++
+		case StatusInProgress:
+			ms.InProgressEpics++
+		}
+
+		// Aggregate test summary if available
+		if task.TestSummary != nil {
+			ms.TotalTests += task.TestSummary.Tests
+			ms.PassedTests += task.TestSummary.Passed
+			ms.FailedTests += task.TestSummary.Failed
+		}
+	}
+
+	result := make([]*ModuleSummary, 0, len(moduleMap))
+	for _, ms := range moduleMap {
+		// Calculate coverage (simplified - just take average if we had multiple)
+		if ms.TotalTests > 0 && ms.PassedTests > 0 {
+			pct := float64(ms.PassedTests) / float64(ms.TotalTests) * 100
+			ms.Coverage = fmt.Sprintf("%.0f%%", pct)
+		}
+		result = append(result, ms)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "testing"
+
+func TestSummarizeModules(t *testing.T) {
+	tasks := []*Task{
+		{ID: TaskID{Module: "alpha", EpicNum: 0}, Status: StatusComplete},
+		{ID: TaskID{Module: "alpha", EpicNum: 1}, Status: StatusInProgress},
+		{ID: TaskID{Module: "alpha", EpicNum: 2}, Status: StatusNotStarted},
+		{ID: TaskID{Module: "beta", EpicNum: 0}, Status: StatusComplete,
+			TestSummary: &TestSummary{Tests: 10, Passed: 8, Failed: 2}},
+	}
+
+	summaries := SummarizeModules(tasks)
+
+	if len(summaries) != 2 {
+		t.Fatalf("summaries count = %d, want 2", len(summaries))
+	}
+
+	// Find alpha module
+	var alpha, beta *ModuleSummary
+	for _, s := range summaries {
+		if s.Name == "alpha" {
+			alpha = s
+		} else if s.Name == "beta" {
+			beta = s
+		}
+	}
+
+	if alpha == nil {
+		t.Fatal("alpha module not found")
+	}
+	if alpha.TotalEpics != 3 {
+		t.Errorf("alpha.TotalEpics = %d, want 3", alpha.TotalEpics)
+	}
+	if alpha.CompletedEpics != 1 {
+		t.Errorf("alpha.CompletedEpics = %d, want 1", alpha.CompletedEpics)
+	}
+	if alpha.InProgressEpics != 1 {
+		t.Errorf("alpha.InProgressEpics = %d, want 1", alpha.InProgressEpics)
+	}
+
+	if beta == nil {
+		t.Fatal("beta module not found")
+	}
+	if beta.TotalTests != 10 {
+		t.Errorf("beta.TotalTests = %d, want 10", beta.TotalTests)
+	}
+	if beta.PassedTests != 8 {
+		t.Errorf("beta.PassedTests = %d, want 8", beta.PassedTests)
+	}
+	if beta.FailedTests != 2 {
+		t.Errorf("beta.FailedTests = %d, want 2", beta.FailedTests)
+	}
+}
@@ -4,18 +4,24 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
 	isync "github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
 )
 
@@ -29,8 +35,31 @@ type ExecutorType string
 const (
 	ExecutorClaudeCode ExecutorType = "claude-code"
 	ExecutorOpenCode   ExecutorType = "opencode"
+	ExecutorGemini     ExecutorType = "gemini"
 )
 
+// binary returns the name of the executable that runs the executor
+func (t ExecutorType) binary() string {
+	switch t {
+	case ExecutorOpenCode:
+		return "opencode"
+	case ExecutorGemini:
+		return "gemini"
+	default:
+		return "claude"
+	}
+}
+
+// executable returns the program the agent runs: its BinaryPath, or the
+// executor's name looked up in PATH. In a sandbox it is always the name, as
+// host paths mean nothing inside the container.
+func (a *Agent) executable() string {
+	if a.BinaryPath != "" && a.Sandbox == nil {
+		return a.BinaryPath
+	}
+	return a.ExecutorType.binary()
+}
+
 // AgentStatus represents the status of an agent
 type AgentStatus string
 
@@ -40,8 +69,22 @@ const (
 	AgentCompleted AgentStatus = "completed"
 	AgentFailed    AgentStatus = "failed"
 	AgentStuck     AgentStatus = "stuck"
+	AgentCancelled AgentStatus = "cancelled"
 )
 
+// Active reports whether an agent with this status still has a live process.
+// A stuck agent is still running, it has just gone quiet.
+func (s AgentStatus) Active() bool {
+	return s == AgentRunning || s == AgentStuck
+}
+
+// agentStopTimeout bounds how long Cancel waits for a stopped agent's
+// process to exit before giving up on removing its worktree
+const agentStopTimeout = 30 * time.Second
+
+// errAgentCancelled is the error of agents stopped with Stop
+var errAgentCancelled = fmt.Errorf("cancelled by user")
+
 // StatusChangeCallback is called when an agent's status changes
 type StatusChangeCallback func(agent *Agent, newStatus AgentStatus, errMsg string)
 
@@ -57,12 +100,44 @@ type Agent struct {
 	StartedAt     *time.Time
 	FinishedAt    *time.Time
 	Prompt        string
-	Output        []string
 	Error         error
 	SessionID     string       // Claude Code session ID for resume capability
 	BuildPoolURL  string       // URL for build pool coordinator (if configured)
-	ExecutorType  ExecutorType // Which AI coding agent to use (claude-code or opencode)
+	ExecutorType  ExecutorType // Which AI coding agent to use (claude-code, opencode or gemini)
+	ClaudeModel   string       // Model to use for Claude Code (e.g., "sonnet"; empty = the account default)
 	OpenCodeModel string       // Model to use for OpenCode (e.g., "zai-coding-plan/glm-4.7")
+	GeminiModel   string       // Model to use for Gemini CLI (e.g., "gemini-2.5-pro")
+	BinaryPath    string       // Executor binary to run (empty = the executor's name, looked up in PATH)
+	Sandbox       *Sandbox     // Container to run the executor in (nil = run directly in the worktree)
+	RateLimited   bool         // Failed because the API throttled requests
+	RetryAttempts int          // Automatic resumes after transient failures (see RetryPolicy)
+
+	// MaxDuration is how long the agent may run before it is killed and
+	// fails as timed out (0 = no limit)
+	MaxDuration time.Duration
+
+	// MaxOutputLines is how many lines of output are kept in memory, the
+	// oldest being dropped first (0 = DefaultMaxOutputLines). The log file
+	// has all of them.
+	MaxOutputLines int
+
+	// Runner starts the executor process (nil = ExecRunner)
+	Runner CommandRunner
+
+	// StopGrace is how long Stop and the watchdog wait for the process to
+	// exit after SIGTERM before killing it (0 = kill it right away)
+	StopGrace time.Duration
+
+	// PlansDir is the worktree subdirectory searched for the agent's epic
+	// file (empty = DefaultPlansDir), and EpicFilePattern the pattern its
+	// name matches (empty = DefaultEpicFilePattern)
+	PlansDir        string
+	EpicFilePattern string
+
+	// Completion is the outcome the agent reported with a CompletionSentinel
+	// line in its final message, with the reason it gave for a failure
+	Completion       CompletionSignal
+	CompletionReason string
 
 	// Token usage from Claude session
 	TokensInput  int
@@ -71,23 +146,68 @@ type Agent struct {
 
 	OnStatusChange StatusChangeCallback // Called when status changes
 
-	cmd     *exec.Cmd
+	proc    Process
+	output  outputRing
 	cancel  context.CancelFunc
 	logFile *os.File
 	mu      sync.Mutex
+
+	// Set by Stop and the watchdog, so the process exiting is reported as a
+	// cancellation or timeout. done is closed once streamOutput has recorded
+	// the final status.
+	cancelled bool
+	timedOut  bool
+	done      chan struct{}
+
+	// Instructions queued for the agent (see QueueFollowUp), and the message
+	// the next resume command sends (empty = just continue)
+	followUps    []string
+	resumePrompt string
+
+	// When the last output line was appended, and how many tool calls are
+	// waiting for their result, for detecting stuck agents (see MarkStuck)
+	lastOutputAt     time.Time
+	pendingToolCalls int
+
+	// When an automatic resume is scheduled (zero if none, see RetryPolicy),
+	// and the timer that starts it
+	retryAt    time.Time
+	retryTimer *time.Timer
+
+	// Channels receiving newly appended output lines (see SubscribeOutput)
+	outputSubs []chan string
+
+	// Previous resource sample, for computing CPU usage between samples
+	lastCPUTicks uint64
+	lastSampleAt time.Time
 }
 
 // AgentStore defines the interface for persisting agent runs
 type AgentStore interface {
+	AgentStoreWriter
+	ListActiveAgentRuns() ([]*AgentRunRecord, error)
+	ListRecentAgentRuns(limit int) ([]*AgentRunRecord, error)
+}
+
+// AgentStoreWriter holds the writes of an AgentStore, which the manager
+// queues (see queueDBOp)
+type AgentStoreWriter interface {
 	SaveAgentRun(run *AgentRunRecord) error
 	UpdateAgentRunStatus(id string, status string, errorMessage string) error
 	UpdateAgentRunUsage(id string, tokensInput, tokensOutput int, costUSD float64) error
-	ListActiveAgentRuns() ([]*AgentRunRecord, error)
-	ListRecentAgentRuns(limit int) ([]*AgentRunRecord, error)
 	DeleteAgentRun(id string) error
 	UpdateTaskStatus(id string, status domain.TaskStatus) error
 }
 
+// BatchAgentStore is an AgentStore that can apply several writes in one
+// transaction. The write queue uses it to drain bursts of operations.
+type BatchAgentStore interface {
+	AgentStore
+	// WriteBatch calls fn with a writer whose writes are committed together
+	// if fn returns nil
+	WriteBatch(fn func(w AgentStoreWriter) error) error
+}
+
 // AgentRunRecord represents a persisted agent run (matches taskstore.AgentRun)
 type AgentRunRecord struct {
 	ID           string
@@ -127,51 +247,156 @@ type AgentManager struct {
 	syncer        *isync.Syncer
 	buildPoolURL  string
 	executorType  ExecutorType // Default executor for new agents
+	claudeModel   string       // Model to use for Claude Code (empty = the account default)
 	openCodeModel string       // Model to use for OpenCode (e.g., "zai-coding-plan/glm-4.7")
+	geminiModel   string       // Model to use for Gemini CLI (e.g., "gemini-2.5-pro")
+	sandbox       *Sandbox     // Container for new agents (nil = run directly)
+	retryPolicy   *RetryPolicy // Resumes agents failing for a transient reason (nil = never)
+	lastRateLimit time.Time    // When an agent last failed on an API rate limit
 	mu            sync.RWMutex
 
+	// Held while checking for a free slot and starting an agent in it, so
+	// concurrent starts can't both take the last slot
+	startMu sync.Mutex
+
+	// Called in the background when an agent completed, i.e. merged its PR
+	// (nil = nothing to do)
+	onMerged func(agent *Agent, prNumber int)
+
+	// How long new agents may run, by executor (missing = no limit)
+	agentTimeouts map[ExecutorType]time.Duration
+
+	// Resolved executor binaries of new agents, by executor (missing = look
+	// up the executor's name in PATH when an agent starts)
+	binaryPaths map[ExecutorType]string
+
+	// Lines of output new agents keep in memory (0 = DefaultMaxOutputLines)
+	maxOutputLines int
+
+	// Maintenance agents allowed to run at once, so they can't take all
+	// slots from task agents (0 = only limited by maxConcurrent)
+	maxMaintenance int
+
+	// How long new agents get to exit after SIGTERM (0 = killed right away)
+	stopGrace time.Duration
+
+	// Where new agents look for their epic file (empty = the defaults)
+	plansDir        string
+	epicFilePattern string
+
 	// Database write queue for serializing DB operations
 	dbWriteChan chan dbOp
 	dbWriteDone chan struct{}
+
+	// Operations that found the write queue full and had to wait for room
+	dbWriteStalls atomic.Int64
 }
 
+// DefaultDBWriteQueueSize is how many database operations the write queue
+// holds before callers have to wait for the writer
+const DefaultDBWriteQueueSize = 1000
+
+// dbWriteBatchSize caps how many queued operations are written in one
+// transaction
+const dbWriteBatchSize = 100
+
 // NewAgentManager creates a new AgentManager
 func NewAgentManager(maxConcurrent int) *AgentManager {
 	m := &AgentManager{
 		maxConcurrent: maxConcurrent,
 		agents:        make(map[string]*Agent),
-		dbWriteChan:   make(chan dbOp, 100), // Buffer for async writes
-		dbWriteDone:   make(chan struct{}),
 	}
-	// Start the database write goroutine
-	go m.dbWriter()
+	m.startDBWriter(DefaultDBWriteQueueSize)
 	return m
 }
 
-// dbWriter processes database operations sequentially to avoid lock contention
+// startDBWriter creates the write queue and starts the goroutine draining it
+func (m *AgentManager) startDBWriter(size int) {
+	m.dbWriteChan = make(chan dbOp, size)
+	m.dbWriteDone = make(chan struct{})
+	go m.dbWriter()
+}
+
+// SetDBWriteQueueSize replaces the write queue with one holding size
+// operations (0 = DefaultDBWriteQueueSize). Operations queued so far are
+// written first. Call it before the manager is used.
+func (m *AgentManager) SetDBWriteQueueSize(size int) {
+	if size <= 0 {
+		size = DefaultDBWriteQueueSize
+	}
+	m.StopDBWriter()
+	m.startDBWriter(size)
+}
+
+// dbWriter processes database operations sequentially to avoid lock
+// contention. It takes whatever has queued up, up to dbWriteBatchSize
+// operations, and writes it as one batch, in queue order.
 func (m *AgentManager) dbWriter() {
+	batch := make([]dbOp, 0, dbWriteBatchSize)
 	for op := range m.dbWriteChan {
-		if m.store == nil {
-			continue
-		}
-		switch op.opType {
-		case "save":
-			m.store.SaveAgentRun(op.record)
-		case "delete":
-			m.store.DeleteAgentRun(op.agentRunID)
-		case "updateStatus":
-			m.store.UpdateAgentRunStatus(op.agentRunID, op.status, op.errorMessage)
-		case "updateUsage":
-			m.store.UpdateAgentRunUsage(op.agentRunID, op.tokensInput, op.tokensOutput, op.costUSD)
-		case "updateTaskStatus":
-			if err := m.store.UpdateTaskStatus(op.taskID, op.taskStatus); err != nil {
-				fmt.Printf("Warning: failed to update task status in DB for %s: %v\n", op.taskID, err)
+		batch = append(batch[:0], op)
+		open := true
+	fill:
+		for open && len(batch) < dbWriteBatchSize {
+			select {
+			case op, open = <-m.dbWriteChan:
+				if open {
+					batch = append(batch, op)
+				}
+			default:
+				break fill
 			}
 		}
+		m.applyDBOps(batch)
+		if !open {
+			break
+		}
 	}
 	close(m.dbWriteDone)
 }
 
+// applyDBOps writes a batch of queued operations, in one transaction if the
+// store is a BatchAgentStore. If the transaction fails, the operations are
+// written one by one.
+func (m *AgentManager) applyDBOps(ops []dbOp) {
+	if m.store == nil {
+		return
+	}
+	if store, ok := m.store.(BatchAgentStore); ok && len(ops) > 1 {
+		err := store.WriteBatch(func(w AgentStoreWriter) error {
+			for _, op := range ops {
+				applyDBOp(w, op)
+			}
+			return nil
+		})
+		if err == nil {
+			return
+		}
+		fmt.Printf("Warning: failed to write %d DB operations in one transaction, writing them one by one: %v\n", len(ops), err)
+	}
+	for _, op := range ops {
+		applyDBOp(m.store, op)
+	}
+}
+
+// applyDBOp writes a single queued operation
+func applyDBOp(w AgentStoreWriter, op dbOp) {
+	switch op.opType {
+	case "save":
+		w.SaveAgentRun(op.record)
+	case "delete":
+		w.DeleteAgentRun(op.agentRunID)
+	case "updateStatus":
+		w.UpdateAgentRunStatus(op.agentRunID, op.status, op.errorMessage)
+	case "updateUsage":
+		w.UpdateAgentRunUsage(op.agentRunID, op.tokensInput, op.tokensOutput, op.costUSD)
+	case "updateTaskStatus":
+		if err := w.UpdateTaskStatus(op.taskID, op.taskStatus); err != nil {
+			fmt.Printf("Warning: failed to update task status in DB for %s: %v\n", op.taskID, err)
+		}
+	}
+}
+
 // StopDBWriter stops the database writer goroutine and waits for it to finish
 func (m *AgentManager) StopDBWriter() {
 	if m.dbWriteChan != nil {
@@ -180,29 +405,15 @@ func (m *AgentManager) StopDBWriter() {
 	}
 }
 
-// queueDBOp queues a database operation for async execution
+// queueDBOp queues a database operation for async execution. When the queue
+// is full it waits for room rather than writing the operation itself, which
+// could apply it before operations on the same agent run queued earlier.
 func (m *AgentManager) queueDBOp(op dbOp) {
 	select {
 	case m.dbWriteChan <- op:
 	default:
-		// Channel full, execute synchronously as fallback
-		if m.store == nil {
-			return
-		}
-		switch op.opType {
-		case "save":
-			m.store.SaveAgentRun(op.record)
-		case "delete":
-			m.store.DeleteAgentRun(op.agentRunID)
-		case "updateStatus":
-			m.store.UpdateAgentRunStatus(op.agentRunID, op.status, op.errorMessage)
-		case "updateUsage":
-			m.store.UpdateAgentRunUsage(op.agentRunID, op.tokensInput, op.tokensOutput, op.costUSD)
-		case "updateTaskStatus":
-			if err := m.store.UpdateTaskStatus(op.taskID, op.taskStatus); err != nil {
-				fmt.Printf("Warning: failed to update task status in DB for %s: %v\n", op.taskID, err)
-			}
-		}
+		m.dbWriteStalls.Add(1)
+		m.dbWriteChan <- op
 	}
 }
 
@@ -216,6 +427,15 @@ func (m *AgentManager) SetSyncer(syncer *isync.Syncer) {
 	m.syncer = syncer
 }
 
+// SetMergeHandler sets a function called in the background when an agent
+// completes naming a PR in its output, with the number of that PR. Agents
+// merge their PR before completing, but the handler should confirm the merge.
+func (m *AgentManager) SetMergeHandler(handler func(agent *Agent, prNumber int)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onMerged = handler
+}
+
 // SetBuildPoolURL sets the URL for the build pool coordinator
 func (m *AgentManager) SetBuildPoolURL(url string) {
 	m.mu.Lock()
@@ -261,6 +481,199 @@ func (m *AgentManager) GetOpenCodeModel() string {
 	return m.openCodeModel
 }
 
+// SetClaudeModel sets the model to use for Claude Code (empty = the account default)
+func (m *AgentManager) SetClaudeModel(model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.claudeModel = model
+}
+
+// GetClaudeModel returns the model to use for Claude Code
+func (m *AgentManager) GetClaudeModel() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.claudeModel
+}
+
+// SetGeminiModel sets the model to use for Gemini CLI
+func (m *AgentManager) SetGeminiModel(model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.geminiModel = model
+}
+
+// GetGeminiModel returns the model to use for Gemini CLI
+func (m *AgentManager) GetGeminiModel() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.geminiModel
+}
+
+// SetSandbox sets the container new agents run in (nil = run directly)
+func (m *AgentManager) SetSandbox(sandbox *Sandbox) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sandbox = sandbox
+}
+
+// GetSandbox returns the container new agents run in (nil if none)
+func (m *AgentManager) GetSandbox() *Sandbox {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sandbox
+}
+
+// SetAgentTimeout sets how long new agents of an executor type may run before
+// they are killed (0 = no limit)
+func (m *AgentManager) SetAgentTimeout(executorType ExecutorType, timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.agentTimeouts == nil {
+		m.agentTimeouts = make(map[ExecutorType]time.Duration)
+	}
+	m.agentTimeouts[executorType] = timeout
+}
+
+// GetAgentTimeout returns how long new agents of an executor type may run (0 = no limit)
+func (m *AgentManager) GetAgentTimeout(executorType ExecutorType) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.agentTimeouts[executorType]
+}
+
+// SetBinaryPath sets the binary new agents of an executor type run. It is
+// resolved to an absolute path right away, so a missing or non-executable
+// binary fails here with a clear message rather than on every agent start.
+// An empty path looks the executor's name up in PATH.
+func (m *AgentManager) SetBinaryPath(executorType ExecutorType, path string) error {
+	name := path
+	if name == "" {
+		name = executorType.binary()
+	}
+	resolved, err := exec.LookPath(name)
+	if err != nil {
+		if path != "" {
+			return fmt.Errorf("%s binary %s is not usable: %w", executorType, path, err)
+		}
+		msg := fmt.Sprintf("%s CLI not found in PATH; install it or configure the path of its binary", name)
+		if executorType == ExecutorGemini {
+			msg += " (install it with: npm install -g @google/gemini-cli)"
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	if abs, err := filepath.Abs(resolved); err == nil {
+		resolved = abs
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.binaryPaths == nil {
+		m.binaryPaths = make(map[ExecutorType]string)
+	}
+	m.binaryPaths[executorType] = resolved
+	return nil
+}
+
+// GetBinaryPath returns the resolved binary new agents of an executor type
+// run (empty = the executor's name, looked up in PATH)
+func (m *AgentManager) GetBinaryPath(executorType ExecutorType) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.binaryPaths[executorType]
+}
+
+// SetMaxOutputLines sets how many lines of output new agents keep in memory
+// (0 = DefaultMaxOutputLines)
+func (m *AgentManager) SetMaxOutputLines(lines int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxOutputLines = lines
+}
+
+// GetMaxOutputLines returns how many lines of output new agents keep in memory
+func (m *AgentManager) GetMaxOutputLines() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.maxOutputLines
+}
+
+// SetStopGrace sets how long new agents get to exit after SIGTERM before
+// they are killed (0 = kill them right away)
+func (m *AgentManager) SetStopGrace(grace time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopGrace = grace
+}
+
+// GetStopGrace returns how long new agents get to exit after SIGTERM
+func (m *AgentManager) GetStopGrace() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stopGrace
+}
+
+// SetPlansDir sets the worktree subdirectory new agents search for their
+// epic file (empty = DefaultPlansDir)
+func (m *AgentManager) SetPlansDir(dir string) error {
+	if dir != "" && !filepath.IsLocal(dir) {
+		return fmt.Errorf("plans directory %q must be relative to the worktree", dir)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.plansDir = dir
+	return nil
+}
+
+// GetPlansDir returns the worktree subdirectory new agents search for their
+// epic file
+func (m *AgentManager) GetPlansDir() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.plansDir
+}
+
+// SetEpicFilePattern sets the pattern the epic file names of new agents
+// match (empty = DefaultEpicFilePattern), see ValidateEpicFilePattern
+func (m *AgentManager) SetEpicFilePattern(pattern string) error {
+	if pattern != "" {
+		if err := ValidateEpicFilePattern(pattern); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.epicFilePattern = pattern
+	return nil
+}
+
+// GetEpicFilePattern returns the pattern the epic file names of new agents match
+func (m *AgentManager) GetEpicFilePattern() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.epicFilePattern
+}
+
+// RateLimitCooldown is how long the API counts as throttled after an agent
+// failed on a rate limit
+const RateLimitCooldown = 5 * time.Minute
+
+// RecordRateLimit notes that an agent failed on an API rate limit at the given time
+func (m *AgentManager) RecordRateLimit(at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if at.After(m.lastRateLimit) {
+		m.lastRateLimit = at
+	}
+}
+
+// Throttled reports whether an agent failed on an API rate limit within the
+// last RateLimitCooldown, in which case new starts should be slowed down
+func (m *AgentManager) Throttled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.lastRateLimit.IsZero() && time.Since(m.lastRateLimit) < RateLimitCooldown
+}
+
 // SetMaxConcurrent updates the maximum number of concurrent agents
 func (m *AgentManager) SetMaxConcurrent(max int) {
 	m.mu.Lock()
@@ -275,6 +688,21 @@ func (m *AgentManager) GetMaxConcurrent() int {
 	return m.maxConcurrent
 }
 
+// SetMaxMaintenance caps how many maintenance agents run at once (0 = no
+// cap beyond the maximum of concurrent agents)
+func (m *AgentManager) SetMaxMaintenance(max int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxMaintenance = max
+}
+
+// GetMaxMaintenance returns how many maintenance agents may run at once
+func (m *AgentManager) GetMaxMaintenance() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.maxMaintenance
+}
+
 // Add adds an agent to the manager and persists it
 func (m *AgentManager) Add(agent *Agent) {
 	m.mu.Lock()
@@ -361,7 +789,7 @@ func (m *AgentManager) RunningCount() int {
 	defer m.mu.RUnlock()
 	count := 0
 	for _, a := range m.agents {
-		if a.Status == AgentRunning {
+		if a.GetStatus().Active() {
 			count++
 		}
 	}
@@ -374,7 +802,7 @@ func (m *AgentManager) QueuedCount() int {
 	defer m.mu.RUnlock()
 	count := 0
 	for _, a := range m.agents {
-		if a.Status == AgentQueued {
+		if a.GetStatus() == AgentQueued {
 			count++
 		}
 	}
@@ -386,7 +814,79 @@ func (m *AgentManager) CanStart() bool {
 	return m.RunningCount() < m.maxConcurrent
 }
 
-// Start starts an agent with the configured executor (Claude Code or OpenCode)
+// MaintenanceRunningCount returns the number of running maintenance agents
+func (m *AgentManager) MaintenanceRunningCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, a := range m.agents {
+		if a.TaskID.Module == MaintenanceModule && a.GetStatus().Active() {
+			count++
+		}
+	}
+	return count
+}
+
+// MaintenanceLimitReached reports whether as many maintenance agents run as
+// SetMaxMaintenance allows
+func (m *AgentManager) MaintenanceLimitReached() bool {
+	max := m.GetMaxMaintenance()
+	return max > 0 && m.MaintenanceRunningCount() >= max
+}
+
+// CanStartMaintenance returns true if another maintenance agent can be
+// started: a slot is free and the maintenance cap is not reached
+func (m *AgentManager) CanStartMaintenance() bool {
+	return m.CanStart() && !m.MaintenanceLimitReached()
+}
+
+// ErrNoSlot is returned when starting or resuming an agent would exceed
+// max_parallel_agents or, for a maintenance agent, max_maintenance_agents
+var ErrNoSlot = errors.New("no free agent slot")
+
+// checkSlot returns an ErrNoSlot error if agent may not start now. Must be
+// called with m.startMu held.
+func (m *AgentManager) checkSlot(agent *Agent) error {
+	if !m.CanStart() {
+		return fmt.Errorf("%w: %d of %d agents running (max_parallel_agents)",
+			ErrNoSlot, m.RunningCount(), m.GetMaxConcurrent())
+	}
+	if agent.TaskID.Module == MaintenanceModule && m.MaintenanceLimitReached() {
+		return fmt.Errorf("%w: %d of %d maintenance agents already running (max_maintenance_agents)",
+			ErrNoSlot, m.MaintenanceRunningCount(), m.GetMaxMaintenance())
+	}
+	return nil
+}
+
+// StartAgent starts a queued agent and adds it to the manager if a slot is
+// free. Otherwise it returns an ErrNoSlot error and leaves the agent alone.
+func (m *AgentManager) StartAgent(ctx context.Context, agent *Agent) error {
+	m.startMu.Lock()
+	defer m.startMu.Unlock()
+	if err := m.checkSlot(agent); err != nil {
+		return err
+	}
+	if err := agent.Start(ctx); err != nil {
+		return err
+	}
+	// Add after starting, so PID and LogPath are persisted
+	m.Add(agent)
+	return nil
+}
+
+// ResumeAgent resumes the session of a finished agent like
+// Agent.ResumeWithPrompt if a slot is free, and returns an ErrNoSlot error
+// otherwise
+func (m *AgentManager) ResumeAgent(ctx context.Context, agent *Agent, prompt string) error {
+	m.startMu.Lock()
+	defer m.startMu.Unlock()
+	if err := m.checkSlot(agent); err != nil {
+		return err
+	}
+	return agent.ResumeWithPrompt(ctx, prompt)
+}
+
+// Start starts an agent with the configured executor (Claude Code, OpenCode or Gemini CLI)
 func (a *Agent) Start(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -410,6 +910,10 @@ func (a *Agent) Start(ctx context.Context) error {
 		a.SessionID = uuid.NewSHA1(orchestratorNamespace, []byte(a.TaskID.String())).String()
 	}
 
+	if err := a.checkExecutor(); err != nil {
+		return err
+	}
+
 	// Create log file in worktree
 	a.LogPath = filepath.Join(a.WorktreePath, ".claude-agent.log")
 	logFile, err := os.Create(a.LogPath)
@@ -422,36 +926,18 @@ func (a *Agent) Start(ctx context.Context) error {
 	a.cancel = cancel
 
 	// Build command based on executor type
-	a.cmd = a.buildCommand(ctx)
-
-	// Capture output
-	stdout, err := a.cmd.StdoutPipe()
-	if err != nil {
-		a.logFile.Close()
-		return err
-	}
-	stderr, err := a.cmd.StderrPipe()
-	if err != nil {
-		a.logFile.Close()
-		return err
-	}
+	cmd := a.buildCommand(ctx)
 
 	// Start the process
-	execName := "claude"
-	if a.ExecutorType == ExecutorOpenCode {
-		execName = "opencode"
-	}
-	if err := a.cmd.Start(); err != nil {
+	if err := a.startProcess(ctx, cmd); err != nil {
 		a.logFile.Close()
-		return fmt.Errorf("starting %s: %w", execName, err)
+		return err
 	}
 
-	// Capture PID
-	a.PID = a.cmd.Process.Pid
-
 	now := time.Now()
 	a.StartedAt = &now
 	a.Status = AgentRunning
+	a.RateLimited = false
 
 	// Call status change callback for running status (triggers sync to in_progress)
 	callback := a.OnStatusChange
@@ -460,7 +946,15 @@ func (a *Agent) Start(ctx context.Context) error {
 	}
 
 	// Stream output in background
-	go a.streamOutput(stdout, stderr)
+	a.cancelled = false
+	a.timedOut = false
+	a.lastOutputAt = time.Time{}
+	a.pendingToolCalls = 0
+	a.done = make(chan struct{})
+	if a.MaxDuration > 0 {
+		go a.watchdog(a.MaxDuration, a.done)
+	}
+	go a.streamOutput(a.proc.Stdout(), a.proc.Stderr())
 
 	return nil
 }
@@ -469,10 +963,27 @@ func (a *Agent) Start(ctx context.Context) error {
 func (a *Agent) buildCommand(ctx context.Context) *exec.Cmd {
 	switch a.ExecutorType {
 	case ExecutorOpenCode:
-		return a.buildOpenCodeCommand(ctx)
+		return a.sandboxed(ctx, a.buildOpenCodeCommand(ctx))
+	case ExecutorGemini:
+		return a.sandboxed(ctx, a.buildGeminiCommand(ctx))
 	default:
-		return a.buildClaudeCodeCommand(ctx)
+		return a.sandboxed(ctx, a.buildClaudeCodeCommand(ctx))
+	}
+}
+
+// sandboxed wraps cmd in the agent's sandbox container, if one is configured
+func (a *Agent) sandboxed(ctx context.Context, cmd *exec.Cmd) *exec.Cmd {
+	if a.Sandbox == nil {
+		return cmd
+	}
+	// The MCP config points at the host's build-mcp binary
+	var mounts []string
+	if a.BuildPoolURL != "" {
+		if buildMCPPath := findBuildMCP(); buildMCPPath != "" {
+			mounts = append(mounts, buildMCPPath)
+		}
 	}
+	return a.Sandbox.wrap(ctx, cmd, containerName(a.ID), mounts...)
 }
 
 // buildClaudeCodeCommand builds the command for Claude Code
@@ -485,6 +996,11 @@ func (a *Agent) buildClaudeCodeCommand(ctx context.Context) *exec.Cmd {
 		"--session-id", a.SessionID,      // Named session for resume capability
 	}
 
+	// Add model if specified (e.g., "sonnet" or "claude-sonnet-4-5")
+	if a.ClaudeModel != "" {
+		args = append(args, "--model", a.ClaudeModel)
+	}
+
 	// Add MCP config if available (from project's .mcp.json + orchestrator MCPs)
 	if mcpConfig := a.generateMCPConfig(); mcpConfig != "" {
 		args = append(args, "--mcp-config", mcpConfig)
@@ -493,7 +1009,7 @@ func (a *Agent) buildClaudeCodeCommand(ctx context.Context) *exec.Cmd {
 	// Add prompt
 	args = append(args, "-p", a.Prompt)
 
-	cmd := exec.CommandContext(ctx, "claude", args...)
+	cmd := exec.CommandContext(ctx, a.executable(), args...)
 	cmd.Dir = a.WorktreePath
 	return cmd
 }
@@ -515,7 +1031,7 @@ func (a *Agent) buildOpenCodeCommand(ctx context.Context) *exec.Cmd {
 	// Add prompt as final argument
 	args = append(args, a.Prompt)
 
-	cmd := exec.CommandContext(ctx, "opencode", args...)
+	cmd := exec.CommandContext(ctx, a.executable(), args...)
 	cmd.Dir = a.WorktreePath
 
 	// Log the command being executed (without the full prompt for brevity)
@@ -524,7 +1040,7 @@ func (a *Agent) buildOpenCodeCommand(ctx context.Context) *exec.Cmd {
 	if a.OpenCodeModel == "" {
 		cmdLog += " (WARNING: no model specified, will use opencode default which requires billing)"
 	}
-	a.Output = append(a.Output, cmdLog)
+	a.appendOutputLocked(cmdLog)
 	if a.logFile != nil {
 		a.logFile.WriteString(cmdLog + "\n")
 		a.logFile.Sync()
@@ -539,37 +1055,123 @@ func (a *Agent) buildOpenCodeCommand(ctx context.Context) *exec.Cmd {
 	return cmd
 }
 
-func (a *Agent) streamOutput(stdout, stderr io.ReadCloser) {
-	var wg sync.WaitGroup
-	wg.Add(2)
+// checkExecutor verifies that the Gemini CLI is installed before an agent
+// runs it directly, so a missing binary gives a clear error. Sandboxed agents
+// get the binary from their image, and agents with their own Runner may not
+// need it at all.
+func (a *Agent) checkExecutor() error {
+	if a.ExecutorType != ExecutorGemini || a.Sandbox != nil || a.Runner != nil {
+		return nil
+	}
+	if _, err := exec.LookPath(a.executable()); err != nil {
+		return fmt.Errorf("gemini CLI not found in PATH (install it with: npm install -g @google/gemini-cli)")
+	}
+	return nil
+}
+
+// buildGeminiCommand builds the command for Gemini CLI
+func (a *Agent) buildGeminiCommand(ctx context.Context) *exec.Cmd {
+	// Note: Gemini CLI keeps its own sessions per project directory;
+	// resume picks up the latest one
+	args := []string{
+		"--yolo",                         // Skip permission prompts
+		"--output-format", "stream-json", // Stream output as JSON for realtime updates
+	}
+
+	// Add model if specified (e.g., "gemini-2.5-pro")
+	if a.GeminiModel != "" {
+		args = append(args, "-m", a.GeminiModel)
+	}
+
+	// Add prompt
+	args = append(args, "--prompt", a.Prompt)
+
+	cmd := exec.CommandContext(ctx, a.executable(), args...)
+	cmd.Dir = a.WorktreePath
+	a.setGeminiEnv(cmd)
+	return cmd
+}
+
+// buildGeminiResumeCommand builds the resume command for Gemini CLI
+func (a *Agent) buildGeminiResumeCommand(ctx context.Context) *exec.Cmd {
+	args := []string{
+		"--yolo",
+		"--output-format", "stream-json",
+		"--resume", "latest", // Continue last session in the worktree
+	}
+
+	if a.GeminiModel != "" {
+		args = append(args, "-m", a.GeminiModel)
+	}
+
+	prompt := a.resumePrompt
+	if prompt == "" {
+		prompt = "Continue with the task."
+	}
+	args = append(args, "--prompt", prompt)
+
+	cmd := exec.CommandContext(ctx, a.executable(), args...)
+	cmd.Dir = a.WorktreePath
+	a.setGeminiEnv(cmd)
+	return cmd
+}
+
+// setGeminiEnv points Gemini CLI at the generated MCP settings, if any
+func (a *Agent) setGeminiEnv(cmd *exec.Cmd) {
+	cmd.Env = os.Environ()
+	if settingsPath := a.generateGeminiMCPConfig(); settingsPath != "" {
+		cmd.Env = append(cmd.Env, "GEMINI_CLI_SYSTEM_SETTINGS_PATH="+settingsPath)
+	}
+}
 
-	readLines := func(r io.Reader) {
+// StderrPrefix tags output lines that the agent process wrote to stderr,
+// in both Agent.Output and the log file
+const StderrPrefix = "[stderr] "
+
+// IsStderrLine reports whether an output line came from the agent's stderr
+func IsStderrLine(line string) bool {
+	return strings.HasPrefix(line, StderrPrefix)
+}
+
+func (a *Agent) streamOutput(stdout, stderr io.Reader) {
+	var wg sync.WaitGroup
+	readLines := func(r io.Reader, prefix string) {
 		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		// Increase buffer size for long JSON lines
-		buf := make([]byte, 0, 64*1024)
-		scanner.Buffer(buf, 1024*1024)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Try to parse token usage from result messages
+		readOutputLines(r, func(line string) {
+			// Try to parse token usage and the reported outcome from result messages
 			a.parseUsageFromLine(line)
+			if prefix == "" {
+				a.parseCompletionFromLine(line)
+			}
+			line = prefix + line
 			a.mu.Lock()
-			a.Output = append(a.Output, line)
+			a.appendOutputLocked(line)
 			// Write to log file
 			if a.logFile != nil {
 				a.logFile.WriteString(line + "\n")
 				a.logFile.Sync() // Flush to disk for tail -f
 			}
 			a.mu.Unlock()
-		}
+		})
 	}
 
-	go readLines(stdout)
-	go readLines(stderr)
-	wg.Wait()
+	// Wait for the process to finish. An agent that exits cleanly with
+	// queued follow-ups is resumed with them instead of completing.
+	wait := func(stdout, stderr io.Reader) error {
+		wg.Add(2)
+		go readLines(stdout, "")
+		go readLines(stderr, StderrPrefix)
+		wg.Wait()
+		return a.proc.Wait()
+	}
+	err := wait(stdout, stderr)
+	for err == nil && a.continueWithFollowUps() {
+		err = wait(a.proc.Stdout(), a.proc.Stderr())
+	}
 
-	// Wait for process to finish
-	err := a.cmd.Wait()
+	if a.ExecutorType == ExecutorOpenCode {
+		a.loadOpenCodeUsage()
+	}
 
 	a.mu.Lock()
 	now := time.Now()
@@ -577,17 +1179,37 @@ func (a *Agent) streamOutput(stdout, stderr io.ReadCloser) {
 
 	var newStatus AgentStatus
 	var errMsg string
-	if err != nil {
+	if a.timedOut {
+		// Stopped by the watchdog, e.g. hanging on a prompt nobody answers.
+		// An executor that shuts down cleanly on SIGTERM exits with 0.
+		a.Status = AgentFailed
+		a.Error = fmt.Errorf("timed out after %s", a.MaxDuration)
+		errMsg = a.Error.Error()
+		newStatus = AgentFailed
+	} else if a.cancelled {
+		// Stopped by Stop, however the process exited
+		a.Status = AgentCancelled
+		a.Error = errAgentCancelled
+		errMsg = a.Error.Error()
+		newStatus = AgentCancelled
+	} else if err != nil {
 		a.Status = AgentFailed
 		// Try to extract meaningful error from output (e.g., OpenCode API errors)
 		if extractedErr := a.extractErrorFromOutput(); extractedErr != "" {
 			a.Error = fmt.Errorf("%s: %s", err.Error(), extractedErr)
 			errMsg = a.Error.Error()
+			a.RateLimited = IsRateLimitError(extractedErr)
 		} else {
 			a.Error = err
 			errMsg = err.Error()
 		}
 		newStatus = AgentFailed
+	} else if reported := a.reportedFailureLocked(); reported != nil {
+		// Exited cleanly, but the agent said it could not finish the epic
+		a.Status = AgentFailed
+		a.Error = reported
+		errMsg = reported.Error()
+		newStatus = AgentFailed
 	} else {
 		a.Status = AgentCompleted
 		newStatus = AgentCompleted
@@ -600,22 +1222,64 @@ func (a *Agent) streamOutput(stdout, stderr io.ReadCloser) {
 	}
 
 	callback := a.OnStatusChange
+	done := a.done
 	a.mu.Unlock()
 
 	// Call status change callback outside of lock
 	if callback != nil {
 		callback(a, newStatus, errMsg)
 	}
+	if done != nil {
+		close(done)
+	}
+}
+
+// watchdog kills the agent's process once it has run for maxDuration. It
+// returns without doing anything once done is closed.
+func (a *Agent) watchdog(maxDuration time.Duration, done <-chan struct{}) {
+	timer := time.NewTimer(maxDuration)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		a.mu.Lock()
+		if a.Status.Active() && !a.cancelled && a.cancel != nil {
+			a.timedOut = true
+			a.terminateLocked()
+		}
+		a.mu.Unlock()
+	}
+}
+
+// rateLimitPattern matches API errors caused by throttling (HTTP 429, Anthropic's
+// rate_limit_error and overloaded_error, exhausted quotas)
+var rateLimitPattern = regexp.MustCompile(`(?i)rate[ _-]?limit|too many requests|(error|status|code)[: ]+429|overloaded|quota exceeded`)
+
+// IsRateLimitError reports whether an error message says the API throttled requests
+func IsRateLimitError(msg string) bool {
+	return rateLimitPattern.MatchString(msg)
 }
 
 // extractErrorFromOutput scans output lines for error messages from executors
 // (e.g., OpenCode API errors, Claude Code errors) and returns a human-readable message.
+// Rate limit errors, also in plain-text lines, are prefixed with "API rate limit: ".
+// Plain-text stderr lines with a transient error, like Node's "Error: read
+// ECONNRESET", are returned as well, so that the failure is retried.
 // Must be called with a.mu held (or after output is finalized).
 func (a *Agent) extractErrorFromOutput() string {
 	// Scan output in reverse (errors usually at the end)
-	for i := len(a.Output) - 1; i >= 0 && i >= len(a.Output)-20; i-- {
-		line := a.Output[i]
+	lines := a.output.tail(20)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimPrefix(lines[i], StderrPrefix)
 		if !strings.HasPrefix(line, "{") {
+			if IsRateLimitError(line) {
+				return "API rate limit: " + strings.TrimSpace(line)
+			}
+			// Only stderr, as stdout may be the agent talking about such errors
+			if IsStderrLine(lines[i]) && IsTransientError(line) {
+				return strings.TrimSpace(line)
+			}
 			continue
 		}
 
@@ -634,6 +1298,9 @@ func (a *Agent) extractErrorFromOutput() string {
 			if msg == "" {
 				msg = openCodeErr.Error.Name
 			}
+			if IsRateLimitError(msg) {
+				return "API rate limit: " + msg
+			}
 			// Extract the core message from nested JSON if present
 			if strings.Contains(msg, "CreditsError") || strings.Contains(msg, "No payment method") {
 				return "OpenCode billing error: No payment method configured"
@@ -651,12 +1318,25 @@ func (a *Agent) extractErrorFromOutput() string {
 			Type    string `json:"type"`
 			Subtype string `json:"subtype"`
 			Error   string `json:"error"`
+			IsError bool   `json:"is_error"`
+			Result  string `json:"result"`
 		}
 		if err := json.Unmarshal([]byte(line), &claudeErr); err == nil && claudeErr.Type == "error" {
+			if IsRateLimitError(claudeErr.Error) {
+				return "API rate limit: " + claudeErr.Error
+			}
 			if claudeErr.Error != "" {
 				return claudeErr.Error
 			}
 		}
+
+		// Claude Code reports API errors (e.g. "API Error: 429 ...") in a failed result message
+		if claudeErr.Type == "result" && claudeErr.IsError && IsRateLimitError(claudeErr.Result) {
+			return "API rate limit: " + claudeErr.Result
+		}
+		if claudeErr.Type == "result" && claudeErr.IsError && strings.HasPrefix(claudeErr.Result, "API Error") {
+			return claudeErr.Result
+		}
 	}
 	return ""
 }
@@ -683,30 +1363,68 @@ func (a *Agent) CheckEpicStatus() (domain.TaskStatus, error) {
 	return toStatus(fm.Status), nil
 }
 
+// DefaultPlansDir is the worktree subdirectory agents search for their epic
+// file unless their PlansDir says otherwise
+const DefaultPlansDir = "docs/plans"
+
+// DefaultEpicFilePattern matches epic files named like epic-03-title.md
+const DefaultEpicFilePattern = `epic-{epic}-.*\.md$`
+
+// ValidateEpicFilePattern checks an epic file name pattern: a regular
+// expression in which {epic} stands for the epic number, with any leading
+// zeros, and {module} for the module name. A pattern without {module}
+// matches files in a directory named after the module anywhere below the
+// plans directory; one with it matches files anywhere, e.g. in a flat
+// layout like billing-epic-03.md.
+func ValidateEpicFilePattern(pattern string) error {
+	if !strings.Contains(pattern, "{epic}") {
+		return fmt.Errorf("epic file pattern %q must contain {epic}", pattern)
+	}
+	if _, err := epicFileRegexp(pattern, domain.TaskID{Module: "module", EpicNum: 1}); err != nil {
+		return fmt.Errorf("epic file pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// epicFileRegexp compiles pattern into the expression matching the epic file
+// name of taskID
+func epicFileRegexp(pattern string, taskID domain.TaskID) (*regexp.Regexp, error) {
+	expr := strings.ReplaceAll(pattern, "{epic}", fmt.Sprintf("0*%d", taskID.EpicNum))
+	expr = strings.ReplaceAll(expr, "{module}", regexp.QuoteMeta(taskID.Module))
+	return regexp.Compile(expr)
+}
+
 // findEpicFile locates the epic markdown file in the worktree
 func (a *Agent) findEpicFile() (string, error) {
 	if a.WorktreePath == "" {
 		return "", fmt.Errorf("no worktree path set")
 	}
 
-	// Look in docs/plans directories for epic files matching this task
-	plansDir := filepath.Join(a.WorktreePath, "docs", "plans")
-	epicPattern := regexp.MustCompile(fmt.Sprintf(`epic-0*%d-.*\.md$`, a.TaskID.EpicNum))
+	plansDir := a.PlansDir
+	if plansDir == "" {
+		plansDir = DefaultPlansDir
+	}
+	plansDir = filepath.Join(a.WorktreePath, plansDir)
+	pattern := a.EpicFilePattern
+	if pattern == "" {
+		pattern = DefaultEpicFilePattern
+	}
+	epicPattern, err := epicFileRegexp(pattern, a.TaskID)
+	if err != nil {
+		return "", fmt.Errorf("epic file pattern %q: %w", pattern, err)
+	}
+	// A pattern naming the module needs no module directory
+	moduleInName := strings.Contains(pattern, "{module}")
 
 	var foundPath string
 	filepath.Walk(plansDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
-		base := filepath.Base(path)
 		// Check if this is our epic file (matching module and epic number)
-		if epicPattern.MatchString(base) {
-			// Check if directory contains our module
-			dir := filepath.Base(filepath.Dir(path))
-			if strings.Contains(dir, a.TaskID.Module) || strings.HasPrefix(dir, a.TaskID.Module) {
-				foundPath = path
-				return filepath.SkipAll
-			}
+		if epicPattern.MatchString(filepath.Base(path)) && (moduleInName || a.inModuleDir(plansDir, path)) {
+			foundPath = path
+			return filepath.SkipAll
 		}
 		return nil
 	})
@@ -717,6 +1435,21 @@ func (a *Agent) findEpicFile() (string, error) {
 	return foundPath, nil
 }
 
+// inModuleDir reports whether a directory between plansDir and the file at
+// path is named after the agent's module
+func (a *Agent) inModuleDir(plansDir, path string) bool {
+	rel, err := filepath.Rel(plansDir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, dir := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.Contains(dir, a.TaskID.Module) {
+			return true
+		}
+	}
+	return false
+}
+
 // simpleFrontmatter is a minimal struct to extract just the status field
 type simpleFrontmatter struct {
 	Status string `yaml:"status"`
@@ -750,23 +1483,24 @@ func parseSimpleFrontmatter(content []byte) (*simpleFrontmatter, []byte, error)
 	return &fm, remaining, nil
 }
 
-// toStatus converts a string to a TaskStatus
+// toStatus converts a string to a TaskStatus, honouring the configured
+// status vocabulary
 func toStatus(s string) domain.TaskStatus {
-	switch s {
-	case "in_progress", "inprogress", "in-progress", "running":
-		return domain.StatusInProgress
-	case "complete", "completed", "done":
-		return domain.StatusComplete
-	default:
-		return domain.StatusNotStarted
-	}
+	return parser.ToStatus(s)
 }
 
 // Resume restarts the agent by resuming its session
 // This continues from where the previous session left off
 func (a *Agent) Resume(ctx context.Context) error {
+	return a.ResumeWithPrompt(ctx, "")
+}
+
+// ResumeWithPrompt resumes the agent's session like Resume, sending prompt
+// and any queued follow-up instructions as the next message
+func (a *Agent) ResumeWithPrompt(ctx context.Context, prompt string) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.cancelRetryLocked() // Resumed by hand, or this is the scheduled resume
 
 	// Can only resume completed or failed agents
 	if a.Status != AgentCompleted && a.Status != AgentFailed {
@@ -787,9 +1521,13 @@ func (a *Agent) Resume(ctx context.Context) error {
 		a.SessionID = uuid.NewSHA1(orchestratorNamespace, []byte(a.TaskID.String())).String()
 	}
 
+	if err := a.checkExecutor(); err != nil {
+		return err
+	}
+
 	// Clear previous output to avoid mixing formats
 	// (session file uses [assistant] format, stream uses raw JSON)
-	a.Output = nil
+	a.setOutputLocked(nil)
 
 	// Re-open or create log file (append mode)
 	logPath := filepath.Join(a.WorktreePath, ".claude-agent.log")
@@ -807,37 +1545,25 @@ func (a *Agent) Resume(ctx context.Context) error {
 	a.cancel = cancel
 
 	// Build resume command based on executor type
-	a.cmd = a.buildResumeCommand(ctx)
-
-	// Capture output
-	stdout, err := a.cmd.StdoutPipe()
-	if err != nil {
-		a.logFile.Close()
-		return err
-	}
-	stderr, err := a.cmd.StderrPipe()
-	if err != nil {
-		a.logFile.Close()
-		return err
-	}
+	a.resumePrompt = a.followUpPromptLocked(prompt)
+	cmd := a.buildResumeCommand(ctx)
 
 	// Start the process
-	execName := "claude"
-	if a.ExecutorType == ExecutorOpenCode {
-		execName = "opencode"
-	}
-	if err := a.cmd.Start(); err != nil {
+	if err := a.startProcess(ctx, cmd); err != nil {
 		a.logFile.Close()
-		return fmt.Errorf("starting %s: %w", execName, err)
+		return err
 	}
+	a.followUps = nil
 
 	// Update state
-	a.PID = a.cmd.Process.Pid
 	now := time.Now()
 	a.StartedAt = &now
 	a.FinishedAt = nil
 	a.Status = AgentRunning
 	a.Error = nil
+	a.RateLimited = false            // Only the outcome of this session counts towards throttling
+	a.Completion = CompletionUnknown // Judge the resumed session on its own outcome
+	a.CompletionReason = ""
 
 	// Call status change callback for running status (triggers sync to in_progress)
 	callback := a.OnStatusChange
@@ -846,30 +1572,178 @@ func (a *Agent) Resume(ctx context.Context) error {
 	}
 
 	// Stream output in background
-	go a.streamOutput(stdout, stderr)
+	a.cancelled = false
+	a.timedOut = false
+	a.lastOutputAt = time.Time{}
+	a.pendingToolCalls = 0
+	a.done = make(chan struct{})
+	if a.MaxDuration > 0 {
+		go a.watchdog(a.MaxDuration, a.done)
+	}
+	go a.streamOutput(a.proc.Stdout(), a.proc.Stderr())
 
 	return nil
 }
 
+// Restart discards the previous attempt of a task and starts it from scratch.
+// The old worktree and Claude session file are removed, a fresh worktree is created
+// and a new agent is started with a new session ID.
+// If prompt is empty, the original prompt of the previous agent is used.
+func (m *AgentManager) Restart(ctx context.Context, taskID string, wtMgr *WorktreeManager, prompt string) (*Agent, error) {
+	old := m.Get(taskID)
+	if old == nil {
+		return nil, fmt.Errorf("agent not found: %s", taskID)
+	}
+	old.cancelRetry()
+
+	old.mu.Lock()
+	status := old.Status
+	if prompt == "" {
+		prompt = old.Prompt
+	}
+	old.mu.Unlock()
+
+	if status.Active() {
+		return nil, fmt.Errorf("cannot restart a running agent")
+	}
+	if prompt == "" {
+		return nil, fmt.Errorf("agent has no prompt")
+	}
+
+	// Fail before the old attempt is thrown away; StartAgent checks again
+	m.startMu.Lock()
+	err := m.checkSlot(old)
+	m.startMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the old session file so nothing from the previous attempt is carried over
+	if sessionFile := old.GetClaudeSessionFilePath(); sessionFile != "" {
+		if err := os.Remove(sessionFile); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("removing session file: %w", err)
+		}
+	}
+
+	// Replace the worktree (Create also cleans up the old branch)
+	wtPath := old.WorktreePath
+	if wtMgr != nil {
+		if old.WorktreePath != "" {
+			wtMgr.Remove(old.WorktreePath) // Ignore error - worktree might already be gone
+		}
+		var err error
+		wtPath, err = wtMgr.Create(old.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("creating worktree: %w", err)
+		}
+	}
+
+	executorType := old.ExecutorType
+	if executorType == "" {
+		executorType = m.GetExecutorType()
+	}
+	claudeModel := old.ClaudeModel
+	if claudeModel == "" {
+		claudeModel = m.GetClaudeModel()
+	}
+	openCodeModel := old.OpenCodeModel
+	if openCodeModel == "" {
+		openCodeModel = m.GetOpenCodeModel()
+	}
+	geminiModel := old.GeminiModel
+	if geminiModel == "" {
+		geminiModel = m.GetGeminiModel()
+	}
+
+	agent := &Agent{
+		TaskID:         old.TaskID,
+		WorktreePath:   wtPath,
+		EpicFilePath:   old.EpicFilePath,
+		Status:         AgentQueued,
+		Prompt:         prompt,
+		SessionID:      uuid.NewString(), // Random, so the deterministic ID of the old session is not reused
+		BuildPoolURL:   m.GetBuildPoolURL(),
+		ExecutorType:   executorType,
+		ClaudeModel:    claudeModel,
+		OpenCodeModel:  openCodeModel,
+		GeminiModel:    geminiModel,
+		BinaryPath:     m.GetBinaryPath(executorType),
+		Sandbox:        m.GetSandbox(),
+		Runner:         old.Runner,
+		MaxDuration:    m.GetAgentTimeout(executorType),
+		MaxOutputLines: m.GetMaxOutputLines(),
+		StopGrace:      m.GetStopGrace(),
+		OnStatusChange: m.CreateStatusCallback(),
+	}
+	agent.PlansDir = m.GetPlansDir()
+	agent.EpicFilePattern = m.GetEpicFilePattern()
+
+	// Replaces the old agent in the manager; the previous run stays in the history
+	if err := m.StartAgent(ctx, agent); err != nil {
+		if wtMgr != nil {
+			wtMgr.Remove(wtPath)
+		}
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// Cancel stops a running agent and removes its worktree once its process has
+// exited. It reports whether the agent was running; cancelling an agent that
+// already finished only drops its scheduled automatic resume, if any.
+func (m *AgentManager) Cancel(ctx context.Context, taskID string, wtMgr *WorktreeManager) (bool, error) {
+	agent := m.Get(taskID)
+	if agent == nil {
+		return false, fmt.Errorf("agent not found: %s", taskID)
+	}
+	agent.cancelRetry()
+
+	if !agent.Stop() {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, agentStopTimeout)
+	defer cancel()
+	if err := agent.Wait(ctx); err != nil {
+		return true, fmt.Errorf("waiting for agent to exit: %w", err)
+	}
+
+	if wtMgr != nil && agent.WorktreePath != "" {
+		if err := wtMgr.Remove(agent.WorktreePath); err != nil {
+			return true, fmt.Errorf("removing worktree: %w", err)
+		}
+	}
+	return true, nil
+}
+
 // buildResumeCommand creates the appropriate resume command based on executor type
 func (a *Agent) buildResumeCommand(ctx context.Context) *exec.Cmd {
 	switch a.ExecutorType {
 	case ExecutorOpenCode:
-		return a.buildOpenCodeResumeCommand(ctx)
+		return a.sandboxed(ctx, a.buildOpenCodeResumeCommand(ctx))
+	case ExecutorGemini:
+		return a.sandboxed(ctx, a.buildGeminiResumeCommand(ctx))
 	default:
-		return a.buildClaudeCodeResumeCommand(ctx)
+		return a.sandboxed(ctx, a.buildClaudeCodeResumeCommand(ctx))
 	}
 }
 
 // buildClaudeCodeResumeCommand builds the resume command for Claude Code
 func (a *Agent) buildClaudeCodeResumeCommand(ctx context.Context) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, "claude",
+	cmd := exec.CommandContext(ctx, a.executable(),
 		"--print",                        // Non-interactive mode
 		"--verbose",                      // Required for stream-json output
 		"--dangerously-skip-permissions", // Skip permission prompts
 		"--output-format", "stream-json", // Stream output as JSON for realtime updates
 		"--resume", a.SessionID,          // Resume the named session
 	)
+	if a.ClaudeModel != "" {
+		cmd.Args = append(cmd.Args, "--model", a.ClaudeModel)
+	}
+	if a.resumePrompt != "" {
+		cmd.Args = append(cmd.Args, "-p", a.resumePrompt)
+	}
 	cmd.Dir = a.WorktreePath
 	return cmd
 }
@@ -887,7 +1761,12 @@ func (a *Agent) buildOpenCodeResumeCommand(ctx context.Context) *exec.Cmd {
 		args = append(args, "-m", a.OpenCodeModel)
 	}
 
-	cmd := exec.CommandContext(ctx, "opencode", args...)
+	// Send the follow-up message, if any
+	if a.resumePrompt != "" {
+		args = append(args, a.resumePrompt)
+	}
+
+	cmd := exec.CommandContext(ctx, a.executable(), args...)
 	cmd.Dir = a.WorktreePath
 
 	// Set up environment for MCP config
@@ -899,23 +1778,148 @@ func (a *Agent) buildOpenCodeResumeCommand(ctx context.Context) *exec.Cmd {
 	return cmd
 }
 
-// Stop gracefully stops the agent
-func (a *Agent) Stop() {
+// Stop terminates the agent's process (see StopGrace), leaving the agent
+// cancelled. It reports whether the agent was running; stopping an agent
+// whose process already exited does nothing. It does not wait for the
+// process to exit.
+func (a *Agent) Stop() bool {
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	if !a.Status.Active() {
+		a.mu.Unlock()
+		return false
+	}
+	a.cancelled = true
 
 	if a.cancel != nil {
-		a.cancel()
+		// streamOutput reports the cancellation once the process has exited
+		a.terminateLocked()
+		a.mu.Unlock()
+		return true
+	}
+
+	// Recovered agents run a process started by a previous session
+	if a.PID != 0 {
+		if process, err := os.FindProcess(a.PID); err == nil {
+			a.terminatePID(process)
+		}
+	}
+	now := time.Now()
+	a.FinishedAt = &now
+	a.Status = AgentCancelled
+	a.Error = errAgentCancelled
+	callback := a.OnStatusChange
+	a.mu.Unlock()
+
+	if callback != nil {
+		callback(a, AgentCancelled, errAgentCancelled.Error())
+	}
+	return true
+}
+
+// terminateLocked asks the agent's process to exit with SIGTERM, so the
+// executor can flush its output and clean up its temporary files, and kills
+// it by cancelling its context if it is still running after StopGrace.
+// Without a grace period, or if the signal cannot be sent, the process is
+// killed right away. The escalation runs in the background. Must be called
+// with a.mu held.
+func (a *Agent) terminateLocked() {
+	cancel, done, grace := a.cancel, a.done, a.StopGrace
+	if grace <= 0 || a.proc == nil || done == nil || a.proc.Signal(syscall.SIGTERM) != nil {
+		cancel()
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			cancel() // Ignored SIGTERM; the context kills it
+		}
+	}()
+}
+
+// terminatePID does what terminateLocked does for a recovered agent's
+// process, which only its PID is known of
+func (a *Agent) terminatePID(process *os.Process) {
+	grace := a.StopGrace
+	if grace <= 0 || process.Signal(syscall.SIGTERM) != nil {
+		process.Kill() // Ignore error - the process may have exited meanwhile
+		return
+	}
+
+	go func() {
+		time.Sleep(grace)
+		if processRunning(process.Pid) {
+			process.Kill()
+		}
+	}()
+}
+
+// Wait blocks until the agent's process has exited and its final status is
+// recorded, or ctx is done
+func (a *Agent) Wait(ctx context.Context) error {
+	a.mu.Lock()
+	done := a.done
+	a.mu.Unlock()
+
+	if done == nil {
+		// Not started by this session; Stop already recorded the status
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// GetOutput returns a copy of the output lines
+// GetStatus returns the agent's current status
+func (a *Agent) GetStatus() AgentStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.Status
+}
+
+// IsRateLimited reports whether the agent failed because the API throttled requests
+func (a *Agent) IsRateLimited() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.RateLimited
+}
+
+// GetError returns the error the agent failed with (nil if none)
+func (a *Agent) GetError() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.Error
+}
+
+// GetOutput returns a copy of the output lines kept in memory: the last
+// MaxOutputLines of them
 func (a *Agent) GetOutput() []string {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	result := make([]string, len(a.Output))
-	copy(result, a.Output)
-	return result
+	return a.output.tail(0)
+}
+
+// prURLPattern matches the URL of a GitHub pull request, which agents name in
+// their final summary
+var prURLPattern = regexp.MustCompile(`github\.com/[\w.-]+/[\w.-]+/pull/(\d+)`)
+
+// PRNumber returns the number of the last pull request URL in the output kept
+// in memory, 0 if there is none
+func (a *Agent) PRNumber() int {
+	output := a.GetOutput()
+	for i := len(output) - 1; i >= 0; i-- {
+		if matches := prURLPattern.FindAllStringSubmatch(output[i], -1); len(matches) > 0 {
+			number, _ := strconv.Atoi(matches[len(matches)-1][1])
+			return number
+		}
+	}
+	return 0
 }
 
 // claudeResultMessage represents the final result message from Claude Code
@@ -923,6 +1927,7 @@ type claudeResultMessage struct {
 	Type      string `json:"type"`
 	Subtype   string `json:"subtype,omitempty"`
 	SessionID string `json:"session_id,omitempty"`
+	Result    string `json:"result,omitempty"` // Text of the agent's final message
 	Usage     struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
@@ -930,12 +1935,17 @@ type claudeResultMessage struct {
 	CostUSD float64 `json:"cost_usd,omitempty"`
 }
 
-// parseUsageFromLine tries to parse token usage from a stream-json line
+// parseUsageFromLine tries to parse token usage from a stream-json line, or
+// from a JSON event of OpenCode
 func (a *Agent) parseUsageFromLine(line string) {
 	var msg claudeResultMessage
 	if err := json.Unmarshal([]byte(line), &msg); err != nil {
 		return
 	}
+	if msg.Type == "step_finish" {
+		a.parseOpenCodeUsageLine(line)
+		return
+	}
 
 	// Only process result messages
 	if msg.Type == "result" {
@@ -947,6 +1957,77 @@ func (a *Agent) parseUsageFromLine(line string) {
 	}
 }
 
+// CompletionSignal is the outcome an agent reports at the end of its run
+type CompletionSignal string
+
+const (
+	CompletionUnknown CompletionSignal = ""        // No sentinel line seen
+	CompletionSuccess CompletionSignal = "success" // The agent finished the epic
+	CompletionFailure CompletionSignal = "failure" // The agent gave up on the epic
+)
+
+// CompletionSentinel starts the line the completion prompts ask agents to end
+// their final message with: "ORCHESTRATOR_RESULT: success" or
+// "ORCHESTRATOR_RESULT: failure <reason>"
+const CompletionSentinel = "ORCHESTRATOR_RESULT:"
+
+// ParseCompletionSignal returns the outcome reported by the last sentinel
+// line in text, with the reason given for a failure
+func ParseCompletionSignal(text string) (CompletionSignal, string) {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.Trim(strings.TrimSpace(lines[i]), "`*")
+		rest, ok := strings.CutPrefix(line, CompletionSentinel)
+		if !ok {
+			continue
+		}
+		word, reason, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		switch CompletionSignal(strings.ToLower(word)) {
+		case CompletionSuccess:
+			return CompletionSuccess, ""
+		case CompletionFailure:
+			return CompletionFailure, strings.TrimSpace(reason)
+		}
+	}
+	return CompletionUnknown, ""
+}
+
+// reportedFailureLocked returns an error if the agent reported that it could
+// not finish the epic, nil otherwise. Must be called with a.mu held.
+func (a *Agent) reportedFailureLocked() error {
+	if a.Completion != CompletionFailure {
+		return nil
+	}
+	reason := a.CompletionReason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	return fmt.Errorf("agent reported failure: %s", reason)
+}
+
+// parseCompletionFromLine records the outcome reported in an output line:
+// the result message of Claude Code's stream-json, or a plain-text sentinel
+// line from executors without structured output
+func (a *Agent) parseCompletionFromLine(line string) {
+	text := line
+	if strings.HasPrefix(line, "{") {
+		var msg claudeResultMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Type != "result" {
+			return
+		}
+		text = msg.Result
+	}
+
+	signal, reason := ParseCompletionSignal(text)
+	if signal == CompletionUnknown {
+		return
+	}
+	a.mu.Lock()
+	a.Completion = signal
+	a.CompletionReason = reason
+	a.mu.Unlock()
+}
+
 // GetUsage returns token usage (input, output, cost)
 func (a *Agent) GetUsage() (int, int, float64) {
 	a.mu.Lock()
@@ -979,7 +2060,8 @@ func (a *Agent) generateMCPConfig() string {
 			"command": buildMCPPath,
 			"args":    []string{},
 			"env": map[string]string{
-				"BUILD_POOL_URL": a.BuildPoolURL,
+				"BUILD_POOL_URL":        a.BuildPoolURL,
+				buildprotocol.TaskIDEnv: a.TaskID.String(),
 			},
 		}
 	} else if buildMCPPath != "" && a.BuildPoolURL == "" {
@@ -1103,7 +2185,8 @@ func (a *Agent) generateOpenCodeMCPConfig() string {
 			"command": []string{buildMCPPath},
 			"enabled": true,
 			"environment": map[string]string{
-				"BUILD_POOL_URL": a.BuildPoolURL,
+				"BUILD_POOL_URL":        a.BuildPoolURL,
+				buildprotocol.TaskIDEnv: a.TaskID.String(),
 			},
 		}
 	}
@@ -1136,6 +2219,80 @@ func (a *Agent) generateOpenCodeMCPConfig() string {
 	return configPath
 }
 
+// generateGeminiMCPConfig creates a settings file in Gemini CLI's format with
+// the project's and orchestrator's MCP servers. It is passed as system
+// settings, so the project's own .gemini/settings.json stays untouched.
+// Returns the path to the generated file, or empty string if no MCPs are configured.
+func (a *Agent) generateGeminiMCPConfig() string {
+	mcpServers := make(map[string]interface{})
+
+	// 1. Load project's .mcp.json if it exists (convert from Claude Code format)
+	projectConfigPath := filepath.Join(a.WorktreePath, ".mcp.json")
+	if data, err := os.ReadFile(projectConfigPath); err == nil {
+		var projectConfig struct {
+			MCPServers map[string]interface{} `json:"mcpServers"`
+		}
+		if err := json.Unmarshal(data, &projectConfig); err == nil {
+			for name, configRaw := range projectConfig.MCPServers {
+				configMap, ok := configRaw.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				// Gemini CLI takes command, args and env like Claude Code, but
+				// names the URL of HTTP servers httpUrl and has no type field
+				geminiServer := make(map[string]interface{})
+				for _, key := range []string{"command", "args", "env", "cwd", "headers", "timeout"} {
+					if v, ok := configMap[key]; ok {
+						geminiServer[key] = v
+					}
+				}
+				if url, ok := configMap["url"].(string); ok {
+					if configMap["type"] == "sse" {
+						geminiServer["url"] = url
+					} else {
+						geminiServer["httpUrl"] = url
+					}
+				}
+				geminiServer["trust"] = true // Don't prompt for tool confirmations
+				mcpServers[name] = geminiServer
+			}
+		}
+	}
+
+	// 2. Add build-mcp if available
+	buildMCPPath := findBuildMCP()
+	if buildMCPPath != "" && a.BuildPoolURL != "" {
+		mcpServers["build-pool"] = map[string]interface{}{
+			"command": buildMCPPath,
+			"args":    []string{},
+			"env": map[string]string{
+				"BUILD_POOL_URL":        a.BuildPoolURL,
+				buildprotocol.TaskIDEnv: a.TaskID.String(),
+			},
+			"trust": true,
+		}
+	}
+
+	if len(mcpServers) == 0 {
+		return ""
+	}
+
+	configJSON, err := json.MarshalIndent(map[string]interface{}{
+		"mcpServers": mcpServers,
+	}, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	// Write to temp file in worktree
+	configPath := filepath.Join(a.WorktreePath, ".gemini-mcp.json")
+	if err := os.WriteFile(configPath, configJSON, 0644); err != nil {
+		return ""
+	}
+
+	return configPath
+}
+
 // Duration returns how long the agent has been running
 func (a *Agent) Duration() time.Duration {
 	a.mu.Lock()
@@ -1152,17 +2309,22 @@ func (a *Agent) Duration() time.Duration {
 
 // IsProcessRunning checks if the agent's process is still running
 func (a *Agent) IsProcessRunning() bool {
-	if a.PID == 0 {
+	return processRunning(a.PID)
+}
+
+// processRunning checks if the process with the given PID is still running
+func processRunning(pid int) bool {
+	if pid == 0 {
 		return false
 	}
 	// On Unix, sending signal 0 checks if process exists
-	process, err := os.FindProcess(a.PID)
+	process, err := os.FindProcess(pid)
 	if err != nil {
 		return false
 	}
 	// Try to send signal 0 - this doesn't actually send a signal,
 	// but returns an error if the process doesn't exist
-	err = process.Signal(os.Signal(nil))
+	err = process.Signal(syscall.Signal(0))
 	return err == nil
 }
 
@@ -1172,35 +2334,19 @@ func (a *Agent) LoadOutputFromLog(maxLines int) error {
 		return nil
 	}
 
-	file, err := os.Open(a.LogPath)
+	// Read only the last maxLines, however large the log is
+	lines, err := TailFile(a.LogPath, maxLines)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil // Log file doesn't exist yet
 		}
 		return err
 	}
-	defer file.Close()
-
-	// Read all lines (we could optimize with ring buffer for large files)
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
-
-	// Keep only last maxLines
-	if len(lines) > maxLines {
-		a.Output = lines[len(lines)-maxLines:]
-	} else {
-		a.Output = lines
-	}
-
-	return scanner.Err()
+	a.setOutputLocked(lines)
+	return nil
 }
 
 // LoadOutput loads agent output, preferring Claude session file for resumed agents
@@ -1298,9 +2444,9 @@ func (a *Agent) LoadOutputFromClaudeSession(maxMessages int) error {
 
 	// Keep only last maxMessages
 	if len(messages) > maxMessages {
-		a.Output = messages[len(messages)-maxMessages:]
+		a.setOutputLocked(messages[len(messages)-maxMessages:])
 	} else {
-		a.Output = messages
+		a.setOutputLocked(messages)
 	}
 
 	return scanner.Err()
@@ -1332,9 +2478,9 @@ func (a *Agent) TailLogFile(ctx context.Context) error {
 				return
 			default:
 				if scanner.Scan() {
-					line := scanner.Text()
+					line := SanitizeOutputLine(scanner.Text())
 					a.mu.Lock()
-					a.Output = append(a.Output, line)
+					a.appendOutputLocked(line)
 					a.mu.Unlock()
 				} else {
 					// No new data, wait a bit
@@ -1395,7 +2541,14 @@ func (m *AgentManager) RecoverAgents(ctx context.Context) ([]*Agent, error) {
 			PID:          run.PID,
 			StartedAt:    &run.StartedAt,
 			SessionID:    run.SessionID,
+			Sandbox:      m.GetSandbox(), // Used when the agent is resumed
+			BinaryPath:   m.GetBinaryPath(ExecutorClaudeCode),
+			ClaudeModel:  m.GetClaudeModel(),
+			MaxDuration:  m.GetAgentTimeout(m.GetExecutorType()),
+			StopGrace:    m.GetStopGrace(),
 		}
+		agent.PlansDir = m.GetPlansDir() // Used to check the epic before a resume
+		agent.EpicFilePattern = m.GetEpicFilePattern()
 
 		// Check if process is still running
 		if agent.IsProcessRunning() {
@@ -1405,19 +2558,31 @@ func (m *AgentManager) RecoverAgents(ctx context.Context) ([]*Agent, error) {
 			// Start tailing the log file
 			agent.TailLogFile(ctx)
 		} else {
-			// Process is no longer running - check if it completed or failed
-			// We'll mark it as completed since we can't know for sure
+			// Process is no longer running - check if it completed or failed.
+			// Without the exit code, trust the outcome the agent reported in its
+			// output and otherwise assume it completed.
 			agent.Status = AgentCompleted
 			now := time.Now()
 			agent.FinishedAt = &now
 			// Load output (prefers Claude session file for resumed agents)
 			agent.LoadOutput(100)
+			for _, line := range agent.GetOutput() {
+				if !IsStderrLine(line) {
+					agent.parseCompletionFromLine(line)
+				}
+			}
+			status, errMsg := "completed", ""
+			if reported := agent.reportedFailureLocked(); reported != nil {
+				agent.Status = AgentFailed
+				agent.Error = reported
+				status, errMsg = "failed", reported.Error()
+			}
 			// Update database via write queue
 			m.queueDBOp(dbOp{
 				opType:       "updateStatus",
 				agentRunID:   run.ID,
-				status:       "completed",
-				errorMessage: "",
+				status:       status,
+				errorMessage: errMsg,
 			})
 		}
 
@@ -1435,6 +2600,28 @@ func (m *AgentManager) RecoverAgents(ctx context.Context) ([]*Agent, error) {
 // CreateStatusCallback returns a callback that updates the manager's store and syncs status
 func (m *AgentManager) CreateStatusCallback() StatusChangeCallback {
 	return func(agent *Agent, newStatus AgentStatus, errMsg string) {
+		// Slow down new starts while the API is throttling
+		if newStatus == AgentFailed && agent.IsRateLimited() {
+			m.RecordRateLimit(time.Now())
+		}
+
+		// Resume agents that failed for a transient reason, if enabled
+		if newStatus == AgentFailed {
+			m.scheduleRetry(agent, errMsg)
+		}
+
+		// A completed agent merged its PR, if it names one
+		if newStatus == AgentCompleted {
+			m.mu.RLock()
+			onMerged := m.onMerged
+			m.mu.RUnlock()
+			if prNumber := agent.PRNumber(); onMerged != nil && prNumber > 0 {
+				go onMerged(agent, prNumber)
+			} else if onMerged != nil {
+				agent.AppendOutput("[orchestrator] No PR named in the output; skipping the merge handler")
+			}
+		}
+
 		// Update agent_runs table in database via write queue
 		if agent.ID != "" {
 			m.queueDBOp(dbOp{
@@ -1444,7 +2631,7 @@ func (m *AgentManager) CreateStatusCallback() StatusChangeCallback {
 				errorMessage: errMsg,
 			})
 			// Save token usage when agent completes
-			if newStatus == AgentCompleted || newStatus == AgentFailed {
+			if newStatus == AgentCompleted || newStatus == AgentFailed || newStatus == AgentCancelled {
 				tokensIn, tokensOut, cost := agent.GetUsage()
 				if tokensIn > 0 || tokensOut > 0 {
 					m.queueDBOp(dbOp{
@@ -1465,6 +2652,9 @@ func (m *AgentManager) CreateStatusCallback() StatusChangeCallback {
 			taskStatus = domain.StatusComplete
 		case AgentRunning:
 			taskStatus = domain.StatusInProgress
+		case AgentCancelled:
+			// The worktree is gone, so the task starts over
+			taskStatus = domain.StatusNotStarted
 		default:
 			// Don't sync for other statuses (failed, stuck, queued)
 			return
@@ -3,11 +3,19 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildprotocol"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 )
 
@@ -21,7 +29,7 @@ func TestBuildPrompt(t *testing.T) {
 	epicContent := "# Epic 05: Validators\n\nImplement validators."
 	completedDeps := []string{"technical/E04"}
 
-	prompt := BuildPrompt(task, epicContent, "", completedDeps)
+	prompt := BuildPrompt(task, epicContent, "", completedDeps, ExecutorClaudeCode)
 
 	if !containsString(prompt, "Validators") {
 		t.Error("Prompt should contain task title")
@@ -31,6 +39,46 @@ func TestBuildPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildPrompt_CompletionInstructions(t *testing.T) {
+	task := &domain.Task{
+		ID:       domain.TaskID{Module: "technical", EpicNum: 5},
+		Title:    "Validators",
+		FilePath: "docs/plans/technical/epic-05-validators.md",
+	}
+
+	claude := BuildPrompt(task, "", "", nil, ExecutorClaudeCode)
+	opencode := BuildPrompt(task, "", "", nil, ExecutorOpenCode)
+
+	for name, prompt := range map[string]string{"claude-code": claude, "opencode": opencode} {
+		if !strings.Contains(prompt, "Marking completion:") {
+			t.Errorf("%s prompt should contain completion instructions", name)
+		}
+		if !strings.Contains(prompt, task.FilePath+" complete when") {
+			t.Errorf("%s prompt should name the epic file in the completion instructions", name)
+		}
+		if !strings.Contains(prompt, "`"+CompletionSentinel+" success`") || !strings.Contains(prompt, "`"+CompletionSentinel+" failure <reason>`") {
+			t.Errorf("%s prompt should ask for the %s line", name, CompletionSentinel)
+		}
+	}
+
+	if !strings.Contains(claude, "this session exits successfully") {
+		t.Error("claude-code prompt should explain that exiting the session marks completion")
+	}
+	if strings.Contains(claude, "opencode run") {
+		t.Error("claude-code prompt should not contain opencode instructions")
+	}
+	if !strings.Contains(opencode, "`opencode run` exits successfully") {
+		t.Error("opencode prompt should explain that exiting opencode run marks completion")
+	}
+	if !strings.Contains(opencode, "keep working until the PR is merged") {
+		t.Error("opencode prompt should tell the agent not to stop early")
+	}
+
+	if got := BuildPrompt(task, "", "", nil, ""); got != claude {
+		t.Error("empty executor type should get the claude-code completion instructions")
+	}
+}
+
 func TestAgent_StatusTransitions(t *testing.T) {
 	agent := &Agent{
 		TaskID: domain.TaskID{Module: "tech", EpicNum: 0},
@@ -49,6 +97,689 @@ func TestAgent_StatusTransitions(t *testing.T) {
 	}
 }
 
+func TestAgent_StreamOutputTagsStderr(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "agent.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		t.Fatalf("create log: %v", err)
+	}
+
+	agent := &Agent{Status: AgentRunning, logFile: logFile}
+	proc, err := ExecRunner{}.Start(context.Background(), exec.Command("sh", "-c", "echo to-stdout; echo to-stderr >&2"))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	agent.proc = proc
+	agent.streamOutput(proc.Stdout(), proc.Stderr())
+
+	var gotStdout, gotStderr bool
+	for _, line := range agent.GetOutput() {
+		switch line {
+		case "to-stdout":
+			gotStdout = true
+		case StderrPrefix + "to-stderr":
+			gotStderr = true
+		case "to-stderr", StderrPrefix + "to-stdout":
+			t.Errorf("line tagged with the wrong stream: %q", line)
+		}
+	}
+	if !gotStdout || !gotStderr {
+		t.Errorf("output = %q, want untagged stdout and tagged stderr", agent.GetOutput())
+	}
+	if !IsStderrLine(StderrPrefix+"to-stderr") || IsStderrLine("to-stdout") {
+		t.Error("IsStderrLine should only match tagged lines")
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if !strings.Contains(string(data), StderrPrefix+"to-stderr\n") {
+		t.Errorf("log file should contain the tagged stderr line, got %q", data)
+	}
+}
+
+func TestAgent_ExtractErrorFromOutput_RateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      []string
+		wantErr     string
+		rateLimited bool
+	}{
+		{
+			name:        "claude-code failed result",
+			output:      []string{`{"type":"result","subtype":"success","is_error":true,"result":"API Error: 429 {\"type\":\"error\",\"error\":{\"type\":\"rate_limit_error\"}}"}`},
+			wantErr:     `API rate limit: API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`,
+			rateLimited: true,
+		},
+		{
+			name:        "opencode error",
+			output:      []string{`{"type":"error","error":{"name":"APIError","data":{"message":"Too Many Requests"}}}`},
+			wantErr:     "API rate limit: Too Many Requests",
+			rateLimited: true,
+		},
+		{
+			name:        "plain-text stderr",
+			output:      []string{"working...", StderrPrefix + "Error: 529 overloaded_error, retry later "},
+			wantErr:     "API rate limit: Error: 529 overloaded_error, retry later",
+			rateLimited: true,
+		},
+		{
+			name:    "other error",
+			output:  []string{`{"type":"error","error":{"name":"APIError","data":{"message":"Unauthorized"}}}`},
+			wantErr: "OpenCode authentication error: Unauthorized",
+		},
+		{
+			name:   "plain text without rate limit",
+			output: []string{"exit status 1"},
+		},
+		{
+			name:    "plain-text stderr network error",
+			output:  []string{StderrPrefix + "Error: read ECONNRESET ", StderrPrefix + "    at TLSWrap.onStreamRead"},
+			wantErr: "Error: read ECONNRESET",
+		},
+		{
+			name:   "network error on stdout",
+			output: []string{"Retrying after ECONNRESET in the test client"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &Agent{}
+			agent.setOutputLocked(tt.output)
+			got := agent.extractErrorFromOutput()
+			if got != tt.wantErr {
+				t.Errorf("extractErrorFromOutput() = %q, want %q", got, tt.wantErr)
+			}
+			if IsRateLimitError(got) != tt.rateLimited {
+				t.Errorf("IsRateLimitError(%q) = %v, want %v", got, !tt.rateLimited, tt.rateLimited)
+			}
+		})
+	}
+}
+
+func TestParseCompletionSignal(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantSignal CompletionSignal
+		wantReason string
+	}{
+		{
+			name:       "success after summary",
+			text:       "Implemented the validators.\nPR: https://github.com/org/repo/pull/7\n\nORCHESTRATOR_RESULT: success",
+			wantSignal: CompletionSuccess,
+		},
+		{
+			name:       "failure with reason",
+			text:       "Could not finish.\nORCHESTRATOR_RESULT: failure integration tests need a database that is not available",
+			wantSignal: CompletionFailure,
+			wantReason: "integration tests need a database that is not available",
+		},
+		{
+			name:       "markdown formatting around the line",
+			text:       "Done.\n`ORCHESTRATOR_RESULT: SUCCESS`",
+			wantSignal: CompletionSuccess,
+		},
+		{
+			name:       "last sentinel wins",
+			text:       "ORCHESTRATOR_RESULT: failure flaky test\nRetried, all green.\nORCHESTRATOR_RESULT: success",
+			wantSignal: CompletionSuccess,
+		},
+		{
+			name: "no sentinel",
+			text: "Implemented the validators and merged the PR.",
+		},
+		{
+			name: "unknown outcome",
+			text: "ORCHESTRATOR_RESULT: maybe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal, reason := ParseCompletionSignal(tt.text)
+			if signal != tt.wantSignal || reason != tt.wantReason {
+				t.Errorf("ParseCompletionSignal() = (%q, %q), want (%q, %q)", signal, reason, tt.wantSignal, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestAgent_StreamOutputCorroboratesCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantStatus AgentStatus
+		wantErr    string
+	}{
+		{
+			name:       "claude-code result reports success",
+			script:     `printf '%s\n' '{"type":"result","subtype":"success","result":"Merged the PR.\nORCHESTRATOR_RESULT: success"}'`,
+			wantStatus: AgentCompleted,
+		},
+		{
+			name:       "claude-code result reports failure",
+			script:     `printf '%s\n' '{"type":"result","subtype":"success","result":"Stopping.\nORCHESTRATOR_RESULT: failure epic depends on an unmerged API change"}'`,
+			wantStatus: AgentFailed,
+			wantErr:    "agent reported failure: epic depends on an unmerged API change",
+		},
+		{
+			name:       "plain-text failure line",
+			script:     `echo 'Giving up.'; echo 'ORCHESTRATOR_RESULT: failure'`,
+			wantStatus: AgentFailed,
+			wantErr:    "agent reported failure: no reason given",
+		},
+		{
+			name:       "no signal falls back to the exit code",
+			script:     `echo 'done'`,
+			wantStatus: AgentCompleted,
+		},
+		{
+			name:       "sentinel on stderr is ignored",
+			script:     `echo 'ORCHESTRATOR_RESULT: failure from a tool' >&2`,
+			wantStatus: AgentCompleted,
+		},
+		{
+			name:       "non-zero exit fails despite success",
+			script:     `echo 'ORCHESTRATOR_RESULT: success'; exit 3`,
+			wantStatus: AgentFailed,
+			wantErr:    "exit status 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &Agent{Status: AgentRunning}
+			proc, err := ExecRunner{}.Start(context.Background(), exec.Command("sh", "-c", tt.script))
+			if err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			agent.proc = proc
+			agent.streamOutput(proc.Stdout(), proc.Stderr())
+
+			if got := agent.GetStatus(); got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			gotErr := ""
+			if err := agent.GetError(); err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("error = %q, want %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAgentManager_ThrottledAfterRateLimitFailure(t *testing.T) {
+	mgr := NewAgentManager(2)
+	defer mgr.StopDBWriter()
+
+	if mgr.Throttled() {
+		t.Fatal("a new manager should not be throttled")
+	}
+
+	agent := &Agent{
+		TaskID:         domain.TaskID{Module: "tech", EpicNum: 1},
+		Status:         AgentRunning,
+		OnStatusChange: mgr.CreateStatusCallback(),
+	}
+	proc, err := ExecRunner{}.Start(context.Background(), exec.Command("sh", "-c", `echo '{"type":"result","is_error":true,"result":"API Error: 429 rate_limit_error"}'; exit 1`))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	agent.proc = proc
+	agent.streamOutput(proc.Stdout(), proc.Stderr())
+
+	if agent.GetStatus() != AgentFailed || !agent.IsRateLimited() {
+		t.Fatalf("status = %s, rate limited = %v, want a rate-limited failure", agent.GetStatus(), agent.IsRateLimited())
+	}
+	if !mgr.Throttled() {
+		t.Error("manager should be throttled after a rate-limited failure")
+	}
+
+	// The throttle wears off after the cooldown
+	mgr = NewAgentManager(2)
+	defer mgr.StopDBWriter()
+	mgr.RecordRateLimit(time.Now().Add(-RateLimitCooldown - time.Second))
+	if mgr.Throttled() {
+		t.Error("manager should not be throttled once the cooldown has passed")
+	}
+}
+
+func TestAgentManager_MergeHandlerOnCompletion(t *testing.T) {
+	mgr := NewAgentManager(2)
+	defer mgr.StopDBWriter()
+
+	merged := make(chan int, 1)
+	mgr.SetMergeHandler(func(agent *Agent, prNumber int) {
+		merged <- prNumber
+	})
+	callback := mgr.CreateStatusCallback()
+
+	agent := &Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 1}}
+	agent.AppendOutput("Opened https://github.com/acme/erp/pull/41 for review")
+	agent.AppendOutput(`{"type":"result","result":"Merged https://github.com/acme/erp/pull/42"}`)
+
+	// Failures don't call the handler
+	callback(agent, AgentFailed, "exit status 1")
+	select {
+	case pr := <-merged:
+		t.Fatalf("handler called with PR #%d for a failed agent", pr)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	callback(agent, AgentCompleted, "")
+	select {
+	case pr := <-merged:
+		if pr != 42 {
+			t.Errorf("handler got PR #%d, want the last PR in the output, #42", pr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called for a completed agent")
+	}
+
+	// Without a PR there is no merge to handle
+	callback(&Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 2}}, AgentCompleted, "")
+	select {
+	case pr := <-merged:
+		t.Fatalf("handler called with PR #%d for an agent naming no PR", pr)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// recordingStore is a BatchAgentStore that records the status updates it
+// writes, by agent run, and how many batches it wrote. Status updates wait
+// until gate is closed.
+type recordingStore struct {
+	gate chan struct{}
+
+	mu       sync.Mutex
+	statuses map[string][]string
+	batches  int
+}
+
+func (s *recordingStore) SaveAgentRun(*AgentRunRecord) error                  { return nil }
+func (s *recordingStore) UpdateAgentRunUsage(string, int, int, float64) error { return nil }
+func (s *recordingStore) DeleteAgentRun(string) error                         { return nil }
+func (s *recordingStore) UpdateTaskStatus(string, domain.TaskStatus) error    { return nil }
+func (s *recordingStore) ListActiveAgentRuns() ([]*AgentRunRecord, error)     { return nil, nil }
+func (s *recordingStore) ListRecentAgentRuns(int) ([]*AgentRunRecord, error)  { return nil, nil }
+
+func (s *recordingStore) UpdateAgentRunStatus(id string, status string, errorMessage string) error {
+	<-s.gate
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.statuses == nil {
+		s.statuses = make(map[string][]string)
+	}
+	s.statuses[id] = append(s.statuses[id], status)
+	return nil
+}
+
+func (s *recordingStore) WriteBatch(fn func(w AgentStoreWriter) error) error {
+	s.mu.Lock()
+	s.batches++
+	s.mu.Unlock()
+	return fn(s)
+}
+
+func TestAgentManager_DBWriteQueueDrainsInBatches(t *testing.T) {
+	mgr := NewAgentManager(1)
+	mgr.SetDBWriteQueueSize(2500)
+	store := &recordingStore{gate: make(chan struct{})}
+	mgr.SetStore(store)
+
+	// The writer is held up by the first write, so the rest queues up
+	runIDs := []string{"run-a", "run-b", "run-c", "run-d"}
+	var want []string
+	for i := range 500 {
+		want = append(want, fmt.Sprint(i))
+		for _, id := range runIDs {
+			mgr.queueDBOp(dbOp{opType: "updateStatus", agentRunID: id, status: fmt.Sprint(i)})
+		}
+	}
+	close(store.gate)
+	mgr.StopDBWriter()
+
+	if stalls := mgr.dbWriteStalls.Load(); stalls != 0 {
+		t.Errorf("%d operations waited for room in the queue, want none", stalls)
+	}
+	for _, id := range runIDs {
+		if got := store.statuses[id]; !slices.Equal(got, want) {
+			t.Errorf("statuses of %s = %d updates, want all 500 in queue order", id, len(got))
+		}
+	}
+	// The first batch is whatever had queued up when the writer started,
+	// the rest are full
+	if store.batches < 19 || store.batches > 21 {
+		t.Errorf("wrote %d batches, want the 2000 operations in batches of %d", store.batches, dbWriteBatchSize)
+	}
+}
+
+func TestAgentManager_FullDBWriteQueueKeepsOrder(t *testing.T) {
+	mgr := NewAgentManager(1)
+	mgr.SetDBWriteQueueSize(1)
+	store := &recordingStore{gate: make(chan struct{})}
+	mgr.SetStore(store)
+
+	queued := make(chan struct{})
+	go func() {
+		defer close(queued)
+		for i := range 5 {
+			mgr.queueDBOp(dbOp{opType: "updateStatus", agentRunID: "run-a", status: fmt.Sprint(i)})
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for mgr.dbWriteStalls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no operation waited for room in the full queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(store.gate)
+	<-queued
+	mgr.StopDBWriter()
+
+	if got := store.statuses["run-a"]; !slices.Equal(got, []string{"0", "1", "2", "3", "4"}) {
+		t.Errorf("statuses = %v, want all updates in queue order", got)
+	}
+}
+
+// recoveryStore is a recordingStore with agent runs left active by an
+// earlier session.
+type recoveryStore struct {
+	recordingStore
+	runs []*AgentRunRecord
+}
+
+func (s *recoveryStore) ListActiveAgentRuns() ([]*AgentRunRecord, error) { return s.runs, nil }
+
+func TestAgentManager_RecoverAgentsUsesConfiguredModel(t *testing.T) {
+	mgr := NewAgentManager(1)
+	store := &recoveryStore{
+		recordingStore: recordingStore{gate: make(chan struct{})},
+		runs: []*AgentRunRecord{{
+			ID:        "run-a",
+			TaskID:    "tech/E01",
+			LogPath:   filepath.Join(t.TempDir(), "agent.log"),
+			StartedAt: time.Now(),
+			SessionID: "session-a",
+		}},
+	}
+	close(store.gate)
+	mgr.SetStore(store)
+	mgr.SetClaudeModel("sonnet")
+
+	recovered, err := mgr.RecoverAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 1 {
+		t.Fatalf("recovered %d agents, want 1", len(recovered))
+	}
+	// A resume of the recovered agent runs with the same model as new agents
+	if got := recovered[0].ClaudeModel; got != "sonnet" {
+		t.Errorf("ClaudeModel = %q, want the configured model", got)
+	}
+}
+
+func TestAgent_BuildCommand_Sandbox(t *testing.T) {
+	wtPath := t.TempDir()
+	buildMCP := filepath.Join(t.TempDir(), "build-mcp")
+	if err := os.WriteFile(buildMCP, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BUILD_MCP_PATH", buildMCP)
+
+	newAgent := func(executorType ExecutorType, sandbox *Sandbox) *Agent {
+		return &Agent{
+			ID:           "technical/E05-1",
+			TaskID:       domain.TaskID{Module: "technical", EpicNum: 5},
+			WorktreePath: wtPath,
+			Prompt:       "do it",
+			SessionID:    "session-1",
+			BuildPoolURL: "http://localhost:8081",
+			ExecutorType: executorType,
+			Sandbox:      sandbox,
+		}
+	}
+	sandbox := &Sandbox{Runtime: "podman", Image: "agents:latest", Flags: []string{"--network=host"}}
+
+	t.Run("direct by default", func(t *testing.T) {
+		cmd := newAgent(ExecutorClaudeCode, nil).buildCommand(context.Background())
+		if cmd.Args[0] != "claude" {
+			t.Errorf("Args[0] = %q, want claude", cmd.Args[0])
+		}
+		if cmd.Dir != wtPath {
+			t.Errorf("Dir = %q, want %q", cmd.Dir, wtPath)
+		}
+	})
+
+	t.Run("claude-code in container", func(t *testing.T) {
+		cmd := newAgent(ExecutorClaudeCode, sandbox).buildCommand(context.Background())
+		got := strings.Join(cmd.Args, " ")
+
+		wantPrefix := "podman run --rm --name claude-orch-technical-E05-1" +
+			" -v " + wtPath + ":" + wtPath + " -w " + wtPath +
+			" -v " + buildMCP + ":" + buildMCP + ":ro" +
+			" --network=host agents:latest claude --print"
+		if !strings.HasPrefix(got, wantPrefix) {
+			t.Errorf("command = %q\nwant prefix %q", got, wantPrefix)
+		}
+		if !strings.HasSuffix(got, "-p do it") {
+			t.Errorf("command = %q, want the prompt passed to claude", got)
+		}
+		if cmd.Cancel == nil {
+			t.Error("Cancel should kill the container")
+		}
+	})
+
+	t.Run("opencode environment passed into container", func(t *testing.T) {
+		cmd := newAgent(ExecutorOpenCode, sandbox).buildCommand(context.Background())
+
+		configEnv := "OPENCODE_CONFIG=" + filepath.Join(wtPath, ".opencode-mcp.json")
+		var envFlags []string
+		for i, arg := range cmd.Args {
+			if arg == "-e" && i+1 < len(cmd.Args) {
+				envFlags = append(envFlags, cmd.Args[i+1])
+			}
+		}
+		if len(envFlags) != 1 || envFlags[0] != configEnv {
+			t.Errorf("-e flags = %v, want only %q", envFlags, configEnv)
+		}
+
+		got := strings.Join(cmd.Args, " ")
+		if !strings.Contains(got, "agents:latest opencode run") {
+			t.Errorf("command = %q, want opencode run after the image", got)
+		}
+	})
+
+	t.Run("resume in container", func(t *testing.T) {
+		cmd := newAgent(ExecutorClaudeCode, sandbox).buildResumeCommand(context.Background())
+		got := strings.Join(cmd.Args, " ")
+		if cmd.Args[0] != "podman" || !strings.Contains(got, "agents:latest claude") || !strings.Contains(got, "--resume session-1") {
+			t.Errorf("resume command = %q, want claude --resume inside the container", got)
+		}
+	})
+}
+
+func TestAgent_ClaudeModel(t *testing.T) {
+	t.Setenv("BUILD_MCP_PATH", "")
+	agent := &Agent{
+		TaskID:       domain.TaskID{Module: "technical", EpicNum: 5},
+		WorktreePath: t.TempDir(),
+		Prompt:       "do it",
+		SessionID:    "session-5",
+	}
+
+	// Without a model the flag is omitted, leaving the account default
+	for _, cmd := range []*exec.Cmd{agent.buildCommand(context.Background()), agent.buildResumeCommand(context.Background())} {
+		if slices.Contains(cmd.Args, "--model") {
+			t.Errorf("Args = %q, want no --model", cmd.Args)
+		}
+	}
+
+	agent.ClaudeModel = "sonnet"
+	for _, cmd := range []*exec.Cmd{agent.buildCommand(context.Background()), agent.buildResumeCommand(context.Background())} {
+		i := slices.Index(cmd.Args, "--model")
+		if i < 0 || i+1 >= len(cmd.Args) || cmd.Args[i+1] != "sonnet" {
+			t.Errorf("Args = %q, want --model sonnet", cmd.Args)
+		}
+	}
+}
+
+func TestAgent_GeminiCommand(t *testing.T) {
+	wtPath := t.TempDir()
+	buildMCP := filepath.Join(t.TempDir(), "build-mcp")
+	if err := os.WriteFile(buildMCP, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BUILD_MCP_PATH", buildMCP)
+
+	projectMCP := `{"mcpServers": {
+		"db": {"command": "db-mcp", "args": ["--ro"], "env": {"DSN": "x"}},
+		"docs": {"type": "http", "url": "https://docs.example.com/mcp"}
+	}}`
+	if err := os.WriteFile(filepath.Join(wtPath, ".mcp.json"), []byte(projectMCP), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &Agent{
+		TaskID:       domain.TaskID{Module: "technical", EpicNum: 5},
+		WorktreePath: wtPath,
+		Prompt:       "do it",
+		BuildPoolURL: "http://localhost:8081",
+		ExecutorType: ExecutorGemini,
+		GeminiModel:  "gemini-2.5-pro",
+	}
+
+	cmd := agent.buildCommand(context.Background())
+	want := []string{"gemini", "--yolo", "--output-format", "stream-json", "-m", "gemini-2.5-pro", "--prompt", "do it"}
+	if strings.Join(cmd.Args, " ") != strings.Join(want, " ") {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+	settingsPath := filepath.Join(wtPath, ".gemini-mcp.json")
+	if !slices.Contains(cmd.Env, "GEMINI_CLI_SYSTEM_SETTINGS_PATH="+settingsPath) {
+		t.Errorf("Env does not point Gemini CLI at %s", settingsPath)
+	}
+
+	var settings struct {
+		MCPServers map[string]map[string]any `json:"mcpServers"`
+	}
+	if err := readJSONFile(settingsPath, &settings); err != nil {
+		t.Fatalf("reading settings: %v", err)
+	}
+	if got := settings.MCPServers["db"]["command"]; got != "db-mcp" {
+		t.Errorf("db command = %v, want db-mcp", got)
+	}
+	if got := settings.MCPServers["docs"]["httpUrl"]; got != "https://docs.example.com/mcp" {
+		t.Errorf("docs httpUrl = %v, want the project's url", got)
+	}
+	if _, ok := settings.MCPServers["docs"]["type"]; ok {
+		t.Error("docs should not keep Claude Code's type field")
+	}
+	if got := settings.MCPServers["build-pool"]["command"]; got != buildMCP {
+		t.Errorf("build-pool command = %v, want %s", got, buildMCP)
+	}
+
+	resume := strings.Join(agent.buildResumeCommand(context.Background()).Args, " ")
+	if !strings.HasPrefix(resume, "gemini --yolo --output-format stream-json --resume latest -m gemini-2.5-pro") {
+		t.Errorf("resume command = %q, want gemini --resume latest", resume)
+	}
+}
+
+func TestAgent_StartGeminiNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	agent := &Agent{
+		TaskID:       domain.TaskID{Module: "technical", EpicNum: 5},
+		WorktreePath: t.TempDir(),
+		Status:       AgentQueued,
+		Prompt:       "do it",
+		ExecutorType: ExecutorGemini,
+	}
+	err := agent.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "gemini CLI not found") {
+		t.Fatalf("Start() error = %v, want gemini CLI not found", err)
+	}
+	if agent.Status != AgentQueued {
+		t.Errorf("Status = %s, want the agent left queued", agent.Status)
+	}
+
+	installFakeExecutable(t, "gemini")
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() with gemini installed: %v", err)
+	}
+	agent.Wait(context.Background())
+}
+
+func TestAgentManager_SetBinaryPath(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	mgr := NewAgentManager(1)
+	defer mgr.StopDBWriter()
+
+	if err := mgr.SetBinaryPath(ExecutorClaudeCode, ""); err == nil || !strings.Contains(err.Error(), "claude CLI not found in PATH") {
+		t.Errorf("SetBinaryPath() error = %v, want claude not found", err)
+	}
+	missing := filepath.Join(t.TempDir(), "claude")
+	if err := mgr.SetBinaryPath(ExecutorClaudeCode, missing); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("SetBinaryPath() error = %v, want the missing binary named", err)
+	}
+	notExecutable := filepath.Join(t.TempDir(), "opencode")
+	if err := os.WriteFile(notExecutable, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.SetBinaryPath(ExecutorOpenCode, notExecutable); err == nil {
+		t.Error("SetBinaryPath() error = nil, want a binary that is not executable refused")
+	}
+
+	installFakeExecutable(t, "claude")
+	if err := mgr.SetBinaryPath(ExecutorClaudeCode, ""); err != nil {
+		t.Fatalf("SetBinaryPath() error = %v", err)
+	}
+	if got := mgr.GetBinaryPath(ExecutorClaudeCode); !filepath.IsAbs(got) || filepath.Base(got) != "claude" {
+		t.Errorf("GetBinaryPath() = %q, want claude resolved from PATH", got)
+	}
+	if got := mgr.GetBinaryPath(ExecutorGemini); got != "" {
+		t.Errorf("GetBinaryPath(gemini) = %q, want none set", got)
+	}
+}
+
+func TestAgent_BinaryPath(t *testing.T) {
+	t.Setenv("BUILD_MCP_PATH", "")
+	newAgent := func(executorType ExecutorType) *Agent {
+		return &Agent{
+			TaskID:       domain.TaskID{Module: "tech", EpicNum: 7},
+			WorktreePath: t.TempDir(),
+			Prompt:       "implement it",
+			SessionID:    "session-7",
+			ExecutorType: executorType,
+			BinaryPath:   "/opt/agents/bin/" + executorType.binary(),
+		}
+	}
+
+	for _, executorType := range []ExecutorType{ExecutorClaudeCode, ExecutorOpenCode, ExecutorGemini} {
+		agent := newAgent(executorType)
+		for _, cmd := range []*exec.Cmd{agent.buildCommand(context.Background()), agent.buildResumeCommand(context.Background())} {
+			if cmd.Path != agent.BinaryPath || cmd.Args[0] != agent.BinaryPath {
+				t.Errorf("%s command runs %q (%q), want the configured binary", executorType, cmd.Path, cmd.Args[0])
+			}
+		}
+	}
+
+	// The container runs its own binary
+	agent := newAgent(ExecutorClaudeCode)
+	agent.Sandbox = &Sandbox{Image: "agents:latest"}
+	cmd := agent.buildCommand(context.Background())
+	if i := slices.Index(cmd.Args, "agents:latest"); i < 0 || cmd.Args[i+1] != "claude" {
+		t.Errorf("sandboxed command = %q, want claude run in the image", cmd.Args)
+	}
+}
+
 func TestAgentManager_MaxConcurrency(t *testing.T) {
 	mgr := NewAgentManager(2)
 
@@ -72,6 +803,106 @@ func TestAgentManager_MaxConcurrency(t *testing.T) {
 	}
 }
 
+func TestAgentManager_MaintenanceCap(t *testing.T) {
+	mgr := NewAgentManager(4)
+	mgr.Add(&Agent{TaskID: domain.TaskID{Module: MaintenanceModule, EpicNum: 1}, Status: AgentRunning})
+	mgr.Add(&Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 1}, Status: AgentRunning})
+	mgr.Add(&Agent{TaskID: domain.TaskID{Module: MaintenanceModule, EpicNum: 2}, Status: AgentCompleted})
+
+	// Without a cap, maintenance agents may take every free slot
+	if !mgr.CanStartMaintenance() {
+		t.Error("CanStartMaintenance() = false without a cap, want true")
+	}
+
+	mgr.SetMaxMaintenance(1)
+	if got := mgr.MaintenanceRunningCount(); got != 1 {
+		t.Errorf("MaintenanceRunningCount() = %d, want 1", got)
+	}
+	if !mgr.MaintenanceLimitReached() || mgr.CanStartMaintenance() {
+		t.Error("a second maintenance agent may start despite max 1")
+	}
+	if !mgr.CanStart() {
+		t.Error("CanStart() = false, want the free slots left to task agents")
+	}
+
+	mgr.SetMaxMaintenance(2)
+	if !mgr.CanStartMaintenance() {
+		t.Error("CanStartMaintenance() = false with 1 of 2 maintenance agents running")
+	}
+
+	// The cap never exceeds the free slots
+	mgr.Add(&Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 2}, Status: AgentRunning})
+	mgr.Add(&Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 3}, Status: AgentRunning})
+	if mgr.CanStartMaintenance() {
+		t.Error("CanStartMaintenance() = true with all slots taken")
+	}
+}
+
+func TestAgentManager_StartAndResumeEnforceCaps(t *testing.T) {
+	t.Setenv("BUILD_MCP_PATH", "")
+	mgr := NewAgentManager(3)
+	defer mgr.StopDBWriter()
+	mgr.SetMaxMaintenance(1)
+	runner := &fakeRunner{block: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	maintenanceAgent := func(num int) *Agent {
+		return &Agent{
+			TaskID:       domain.TaskID{Module: MaintenanceModule, EpicNum: num},
+			WorktreePath: t.TempDir(),
+			Status:       AgentQueued,
+			Prompt:       "tidy up",
+			Runner:       runner,
+		}
+	}
+
+	// Concurrent starts can't both take the single maintenance slot
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for num := 1; num <= 2; num++ {
+		agent := maintenanceAgent(num)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- mgr.StartAgent(ctx, agent)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	var refused int
+	for err := range errs {
+		if errors.Is(err, ErrNoSlot) {
+			refused++
+		} else if err != nil {
+			t.Fatalf("StartAgent() error = %v", err)
+		}
+	}
+	if refused != 1 || mgr.MaintenanceRunningCount() != 1 {
+		t.Fatalf("%d starts refused, %d maintenance agents running; want 1 and 1", refused, mgr.MaintenanceRunningCount())
+	}
+
+	// A finished maintenance agent can't be resumed past the cap either
+	finished := maintenanceAgent(3)
+	finished.Status = AgentFailed
+	mgr.Add(finished)
+	if err := mgr.ResumeAgent(ctx, finished, ""); !errors.Is(err, ErrNoSlot) {
+		t.Errorf("ResumeAgent() error = %v, want ErrNoSlot at the maintenance cap", err)
+	}
+
+	// Task agents still get the free slots, up to max_parallel_agents
+	for num := 1; num <= 3; num++ {
+		agent := maintenanceAgent(num)
+		agent.TaskID.Module = "tech"
+		err := mgr.StartAgent(ctx, agent)
+		if num < 3 && err != nil {
+			t.Errorf("StartAgent(tech/E%02d) error = %v, want started", num, err)
+		}
+		if num == 3 && !errors.Is(err, ErrNoSlot) {
+			t.Errorf("StartAgent(tech/E03) error = %v, want ErrNoSlot with all slots taken", err)
+		}
+	}
+}
+
 func containsString(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
@@ -169,4 +1000,450 @@ func TestAgent_generateMCPConfig(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("passes task ID to build-mcp", func(t *testing.T) {
+		buildMCP := filepath.Join(t.TempDir(), "build-mcp")
+		os.WriteFile(buildMCP, []byte("#!/bin/sh\n"), 0755)
+		t.Setenv("BUILD_MCP_PATH", buildMCP)
+
+		agent := &Agent{
+			TaskID:       domain.TaskID{Module: "billing", EpicNum: 2},
+			WorktreePath: t.TempDir(),
+			BuildPoolURL: "http://localhost:8081",
+		}
+
+		var parsed struct {
+			MCPServers map[string]struct {
+				Env map[string]string `json:"env"`
+			} `json:"mcpServers"`
+		}
+		if err := json.Unmarshal([]byte(agent.generateMCPConfig()), &parsed); err != nil {
+			t.Fatalf("Failed to parse config JSON: %v", err)
+		}
+		if got := parsed.MCPServers["build-pool"].Env[buildprotocol.TaskIDEnv]; got != "billing/E02" {
+			t.Errorf("%s = %q, want billing/E02", buildprotocol.TaskIDEnv, got)
+		}
+	})
+}
+
+// installFakeExecutable puts a no-op script with the given name first in PATH
+func installFakeExecutable(t *testing.T, name string) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho '{\"type\":\"result\"}'\n"
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestAgentManager_Restart(t *testing.T) {
+	installFakeExecutable(t, "claude")
+	t.Setenv("HOME", t.TempDir())
+
+	repoDir := setupGitRepo(t)
+	wtMgr := NewWorktreeManager(repoDir, t.TempDir())
+	taskID := domain.TaskID{Module: "tech", EpicNum: 3}
+
+	oldPath, err := wtMgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewAgentManager(2)
+	old := &Agent{
+		ID:           "tech/E03-old",
+		TaskID:       taskID,
+		WorktreePath: oldPath,
+		Status:       AgentFailed,
+		Prompt:       "implement the epic",
+		SessionID:    uuid.NewSHA1(orchestratorNamespace, []byte(taskID.String())).String(),
+	}
+	mgr.Add(old)
+
+	// Simulate a session file left behind by the previous attempt
+	sessionFile := old.GetClaudeSessionFilePath()
+	if err := os.MkdirAll(filepath.Dir(sessionFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sessionFile, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := mgr.Restart(context.Background(), taskID.String(), wtMgr, "")
+	if err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+
+	if _, err := os.Stat(sessionFile); !os.IsNotExist(err) {
+		t.Error("old session file should be removed")
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("old worktree should be removed")
+	}
+	if agent.WorktreePath == oldPath {
+		t.Error("restart should use a fresh worktree")
+	}
+	if agent.SessionID == "" || agent.SessionID == old.SessionID {
+		t.Errorf("SessionID = %q, want a new session ID", agent.SessionID)
+	}
+	if agent.ID == "" || agent.ID == old.ID {
+		t.Errorf("ID = %q, want a new run ID", agent.ID)
+	}
+	if agent.Prompt != old.Prompt {
+		t.Errorf("Prompt = %q, want original prompt %q", agent.Prompt, old.Prompt)
+	}
+	if mgr.Get(taskID.String()) != agent {
+		t.Error("manager should track the restarted agent")
+	}
+
+	// Wait for the fake claude process to finish
+	var status AgentStatus
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		agent.mu.Lock()
+		status = agent.Status
+		agent.mu.Unlock()
+		if status == AgentCompleted {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status != AgentCompleted {
+		t.Errorf("Status = %s, want completed", status)
+	}
+}
+
+func TestAgentManager_Restart_RejectsRunningAgent(t *testing.T) {
+	mgr := NewAgentManager(1)
+	taskID := domain.TaskID{Module: "tech", EpicNum: 1}
+	mgr.Add(&Agent{
+		TaskID: taskID,
+		Status: AgentRunning,
+		Prompt: "implement the epic",
+	})
+
+	if _, err := mgr.Restart(context.Background(), taskID.String(), nil, ""); err == nil {
+		t.Error("expected error when restarting a running agent")
+	}
+}
+
+func TestParseProcStatCPUTicks(t *testing.T) {
+	// Command name contains spaces and parentheses; utime=1500, stime=250
+	stat := "4242 (claude (node) x) S 1 4242 4242 0 -1 4194560 12345 0 3 0 1500 250 0 0 20 0 11 0 98765 1234567 8910"
+
+	ticks, err := parseProcStatCPUTicks(stat)
+	if err != nil {
+		t.Fatalf("parseProcStatCPUTicks() error = %v", err)
+	}
+	if ticks != 1750 {
+		t.Errorf("ticks = %d, want 1750", ticks)
+	}
+
+	if _, err := parseProcStatCPUTicks("4242 claude S 1"); err == nil {
+		t.Error("expected error for malformed stat")
+	}
+}
+
+func TestParseProcStatmRSS(t *testing.T) {
+	rss, err := parseProcStatmRSS("250000 65536 1200 300 0 70000 0\n", 4096)
+	if err != nil {
+		t.Fatalf("parseProcStatmRSS() error = %v", err)
+	}
+	if rss != 65536*4096 {
+		t.Errorf("rss = %d, want %d", rss, 65536*4096)
+	}
+}
+
+func TestResourceUsage_String(t *testing.T) {
+	tests := []struct {
+		usage ResourceUsage
+		want  string
+	}{
+		{ResourceUsage{CPUPercent: 12.34, RSSBytes: 256 * 1024 * 1024}, "CPU 12.3% | RSS 256.0 MB"},
+		{ResourceUsage{CPUPercent: 150, RSSBytes: 1536}, "CPU 150.0% | RSS 1.5 KB"},
+		{ResourceUsage{RSSBytes: 512}, "CPU 0.0% | RSS 512 B"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.usage.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+
+	// 250 ticks (2.5s of CPU) over 5s of wall time is 50%
+	if got := cpuPercent(250, 5*time.Second); got != 50 {
+		t.Errorf("cpuPercent() = %v, want 50", got)
+	}
+}
+
+func TestAgent_ResourceUsage_CurrentProcess(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	agent := &Agent{PID: os.Getpid(), StartedAt: timePtr(time.Now().Add(-time.Second))}
+	usage, err := agent.ResourceUsage()
+	if err != nil {
+		t.Fatalf("ResourceUsage() error = %v", err)
+	}
+	if usage.RSSBytes <= 0 {
+		t.Errorf("RSSBytes = %d, want > 0", usage.RSSBytes)
+	}
+}
+
+// startLongRunningAgent starts a process for agent that runs until it is
+// killed, as Start does for the executor
+func startLongRunningAgent(t *testing.T, agent *Agent) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	agent.Status = AgentRunning
+	agent.cancel = cancel
+	proc, err := ExecRunner{}.Start(ctx, exec.CommandContext(ctx, "sleep", "60"))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	agent.done = make(chan struct{})
+	if agent.MaxDuration > 0 {
+		go agent.watchdog(agent.MaxDuration, agent.done)
+	}
+	agent.proc = proc
+	go agent.streamOutput(proc.Stdout(), proc.Stderr())
+}
+
+func TestAgent_StopCancelsRunningAgent(t *testing.T) {
+	var reported []AgentStatus
+	var mu sync.Mutex
+	agent := &Agent{OnStatusChange: func(_ *Agent, status AgentStatus, _ string) {
+		mu.Lock()
+		reported = append(reported, status)
+		mu.Unlock()
+	}}
+	startLongRunningAgent(t, agent)
+
+	if !agent.Stop() {
+		t.Fatal("Stop() = false, want true for a running agent")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := agent.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	if got := agent.GetStatus(); got != AgentCancelled {
+		t.Errorf("status = %s, want cancelled", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || reported[0] != AgentCancelled {
+		t.Errorf("reported statuses = %v, want the cancellation", reported)
+	}
+}
+
+func TestAgent_StopAfterExitIsNoop(t *testing.T) {
+	agent := &Agent{Status: AgentRunning}
+	proc, err := ExecRunner{}.Start(context.Background(), exec.Command("true"))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	agent.proc = proc
+	agent.streamOutput(proc.Stdout(), proc.Stderr())
+
+	if agent.Stop() {
+		t.Error("Stop() = true, want false once the process has exited")
+	}
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Errorf("status = %s, want the agent to stay completed", got)
+	}
+}
+
+func TestAgentManager_Cancel(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	wtMgr := NewWorktreeManager(repoDir, t.TempDir())
+	taskID := domain.TaskID{Module: "tech", EpicNum: 4}
+	wtPath, err := wtMgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewAgentManager(1)
+	defer mgr.StopDBWriter()
+	agent := &Agent{TaskID: taskID, WorktreePath: wtPath}
+	startLongRunningAgent(t, agent)
+	mgr.Add(agent)
+
+	cancelled, err := mgr.Cancel(context.Background(), taskID.String(), wtMgr)
+	if err != nil || !cancelled {
+		t.Fatalf("Cancel() = %v, %v, want the running agent cancelled", cancelled, err)
+	}
+	if got := agent.GetStatus(); got != AgentCancelled {
+		t.Errorf("status = %s, want cancelled", got)
+	}
+	if _, err := os.Stat(wtPath); !os.IsNotExist(err) {
+		t.Error("worktree should be removed")
+	}
+
+	// Cancelling again finds nothing running
+	if cancelled, err := mgr.Cancel(context.Background(), taskID.String(), wtMgr); err != nil || cancelled {
+		t.Errorf("second Cancel() = %v, %v, want a no-op", cancelled, err)
+	}
+	if _, err := mgr.Cancel(context.Background(), "tech/E99", wtMgr); err == nil {
+		t.Error("expected an error for an unknown agent")
+	}
+}
+
+func TestAgent_WatchdogTimesOutHangingAgent(t *testing.T) {
+	agent := &Agent{MaxDuration: 50 * time.Millisecond}
+	startLongRunningAgent(t, agent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := agent.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v, want the watchdog to kill the agent", err)
+	}
+
+	if got := agent.GetStatus(); got != AgentFailed {
+		t.Errorf("status = %s, want failed", got)
+	}
+	if err := agent.GetError(); err == nil || err.Error() != "timed out after 50ms" {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+}
+
+func TestAgent_WatchdogStopsWhenAgentFinishes(t *testing.T) {
+	agent := &Agent{Status: AgentRunning}
+	done := make(chan struct{})
+	close(done)
+
+	// Returns right away instead of waiting out the hour
+	returned := make(chan struct{})
+	go func() {
+		agent.watchdog(time.Hour, done)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchdog kept running after the agent finished")
+	}
+}
+
+func TestAgent_CheckEpicStatus(t *testing.T) {
+	write := func(t *testing.T, root, path, status string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("---\nstatus: "+status+"\n---\n# Epic\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string // Path in the worktree -> status
+		plansDir string
+		pattern  string
+		want     domain.TaskStatus
+	}{
+		{
+			name: "default layout",
+			files: map[string]string{
+				"docs/plans/billing/epic-02-invoices.md": "complete",
+				"docs/plans/billing/epic-03-refunds.md":  "in_progress",
+				"docs/plans/tech/epic-03-cache.md":       "complete",
+			},
+			want: domain.StatusInProgress,
+		},
+		{
+			name: "module directory higher up",
+			files: map[string]string{
+				"docs/plans/billing/phase-1/epic-3-refunds.md": "complete",
+			},
+			want: domain.StatusComplete,
+		},
+		{
+			name: "custom directory and pattern",
+			files: map[string]string{
+				"plans/billing/03-refunds.epic.md": "complete",
+				"docs/plans/billing/epic-03-x.md":  "in_progress",
+			},
+			plansDir: "plans",
+			pattern:  `^{epic}-.*\.epic\.md$`,
+			want:     domain.StatusComplete,
+		},
+		{
+			name: "flat layout naming the module",
+			files: map[string]string{
+				"specs/tech-epic-03.md":    "in_progress",
+				"specs/billing-epic-03.md": "complete",
+			},
+			plansDir: "specs",
+			pattern:  `^{module}-epic-{epic}\.md$`,
+			want:     domain.StatusComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worktree := t.TempDir()
+			for path, status := range tt.files {
+				write(t, worktree, path, status)
+			}
+			agent := &Agent{
+				TaskID:          domain.TaskID{Module: "billing", EpicNum: 3},
+				WorktreePath:    worktree,
+				PlansDir:        tt.plansDir,
+				EpicFilePattern: tt.pattern,
+			}
+			got, err := agent.CheckEpicStatus()
+			if err != nil {
+				t.Fatalf("CheckEpicStatus() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckEpicStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		worktree := t.TempDir()
+		write(t, worktree, "docs/plans/epic-03-refunds.md", "complete") // Not in a billing directory
+		agent := &Agent{TaskID: domain.TaskID{Module: "billing", EpicNum: 3}, WorktreePath: worktree}
+		if _, err := agent.CheckEpicStatus(); err == nil || !strings.Contains(err.Error(), "epic file not found") {
+			t.Errorf("CheckEpicStatus() error = %v, want the epic file not found", err)
+		}
+	})
+}
+
+func TestAgentManager_SetEpicDiscovery(t *testing.T) {
+	m := NewAgentManager(1)
+	if err := m.SetPlansDir("plans/epics"); err != nil {
+		t.Errorf("SetPlansDir() error = %v", err)
+	}
+	if err := m.SetEpicFilePattern(`^{module}-{epic}\.md$`); err != nil {
+		t.Errorf("SetEpicFilePattern() error = %v", err)
+	}
+	if m.GetPlansDir() != "plans/epics" || m.GetEpicFilePattern() != `^{module}-{epic}\.md$` {
+		t.Errorf("got %q and %q, want the settings", m.GetPlansDir(), m.GetEpicFilePattern())
+	}
+
+	for _, dir := range []string{"/srv/plans", "../plans"} {
+		if err := m.SetPlansDir(dir); err == nil {
+			t.Errorf("SetPlansDir(%q) succeeded, want it rejected as outside the worktree", dir)
+		}
+	}
+	for pattern, want := range map[string]string{
+		`epic-\d+\.md$`: "must contain {epic}",
+		`epic-{epic}-(`: "missing closing )",
+	} {
+		if err := m.SetEpicFilePattern(pattern); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("SetEpicFilePattern(%q) error = %v, want %q", pattern, err, want)
+		}
+	}
+	if m.GetPlansDir() != "plans/epics" || m.GetEpicFilePattern() != `^{module}-{epic}\.md$` {
+		t.Error("rejected settings replaced the previous ones")
+	}
 }
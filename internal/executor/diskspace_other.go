@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package executor
+
+import "errors"
+
+// diskFreeBytes is not supported on this platform; worktree directories are
+// then chosen without a free space check
+func diskFreeBytes(path string) (uint64, error) {
+	return 0, errors.New("disk space check not supported on this platform")
+}
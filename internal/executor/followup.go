@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// PlanChangedFollowUp is the instruction that tells an agent the epic it
+// works on was edited, with the epic's updated body
+func PlanChangedFollowUp(taskID domain.TaskID, epicPath, body string) string {
+	return fmt.Sprintf("The epic file of %s (%s) was changed while you were working on it. "+
+		"Its updated content is below. Check your work against it and address any new or changed "+
+		"requirements before you finish.\n\n%s", taskID, epicPath, strings.TrimSpace(body))
+}
+
+// QueueFollowUp queues an instruction for the agent. A running agent is not
+// interrupted: it gets the instruction when its process exits cleanly, by
+// resuming its session with it instead of completing. An agent that is not
+// running gets it with its next Resume.
+func (a *Agent) QueueFollowUp(instruction string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.followUps = append(a.followUps, instruction)
+}
+
+// PendingFollowUps returns how many queued instructions the agent has not
+// received yet
+func (a *Agent) PendingFollowUps() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.followUps)
+}
+
+// followUpPromptLocked returns prompt followed by the queued instructions.
+// Must be called with a.mu held.
+func (a *Agent) followUpPromptLocked(prompt string) string {
+	parts := a.followUps
+	if prompt != "" {
+		parts = append([]string{prompt}, parts...)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// continueWithFollowUps resumes the agent's session with its queued
+// instructions after its process exited cleanly, so the agent stays running
+// instead of completing, and reports whether it did
+func (a *Agent) continueWithFollowUps() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.followUps) == 0 || a.cancelled || a.timedOut {
+		return false
+	}
+
+	if a.cancel != nil {
+		a.cancel() // The previous process has exited; release its context
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	a.resumePrompt = a.followUpPromptLocked("")
+	if a.logFile != nil {
+		a.logFile.WriteString(fmt.Sprintf("\n=== Session continued with %d follow-up(s) at %s ===\n", len(a.followUps), time.Now().Format(time.RFC3339)))
+	}
+	if err := a.startProcess(ctx, a.buildResumeCommand(ctx)); err != nil {
+		cancel()
+		a.appendOutputLocked(fmt.Sprintf("[orchestrator] Could not deliver follow-up: %v", err))
+		return false
+	}
+
+	a.followUps = nil
+	a.Completion = CompletionUnknown
+	a.CompletionReason = ""
+	a.lastOutputAt = time.Now()
+	a.pendingToolCalls = 0
+	return true
+}
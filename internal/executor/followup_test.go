@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+func TestAgent_FollowUpDeliveredWhenRunEnds(t *testing.T) {
+	release := make(chan struct{})
+	runner := &fakeRunner{release: release}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	instruction := PlanChangedFollowUp(agent.TaskID, "docs/plans/tech/epic-07.md", "# Epic\n\nAlso handle refunds.")
+	agent.QueueFollowUp(instruction)
+	if agent.PendingFollowUps() != 1 {
+		t.Fatalf("PendingFollowUps() = %d, want 1", agent.PendingFollowUps())
+	}
+
+	// The running process is not interrupted by the follow-up
+	if cmds := runner.commands(); len(cmds) != 1 {
+		t.Fatalf("started %d commands before the run ended, want 1", len(cmds))
+	}
+
+	runner.mu.Lock()
+	runner.release = nil // The continuation exits right away
+	runner.mu.Unlock()
+	close(release)
+	waitForAgent(t, agent)
+
+	cmds := runner.commands()
+	if len(cmds) != 2 {
+		t.Fatalf("started %d commands, want the run and its continuation", len(cmds))
+	}
+	continuation := strings.Join(cmds[1], " ")
+	if !strings.Contains(continuation, "--resume session-7") || !strings.Contains(continuation, "Also handle refunds.") {
+		t.Errorf("continuation = %q, want a resume with the updated epic", continuation)
+	}
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Errorf("status = %s, want completed after the continuation", got)
+	}
+	if agent.PendingFollowUps() != 0 {
+		t.Errorf("PendingFollowUps() = %d, want the follow-up delivered", agent.PendingFollowUps())
+	}
+}
+
+func TestAgent_FollowUpNotDeliveredToFailedRun(t *testing.T) {
+	runner := &fakeRunner{exitErr: errors.New("exit status 1"), release: make(chan struct{})}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	agent.QueueFollowUp("Also handle refunds.")
+	close(runner.release)
+	waitForAgent(t, agent)
+
+	if got := agent.GetStatus(); got != AgentFailed {
+		t.Fatalf("status = %s, want failed", got)
+	}
+	if agent.PendingFollowUps() != 1 {
+		t.Fatalf("PendingFollowUps() = %d, want the follow-up kept for the next resume", agent.PendingFollowUps())
+	}
+
+	// The next resume sends the prompt and the queued follow-up
+	runner.mu.Lock()
+	runner.exitErr = nil
+	runner.release = nil
+	runner.mu.Unlock()
+	if err := agent.ResumeWithPrompt(context.Background(), "Fix the failing test."); err != nil {
+		t.Fatalf("ResumeWithPrompt() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	cmds := runner.commands()
+	resume := cmds[len(cmds)-1]
+	if got := resume[len(resume)-1]; got != "Fix the failing test.\n\nAlso handle refunds." {
+		t.Errorf("resume prompt = %q, want the prompt followed by the follow-up", got)
+	}
+	if agent.PendingFollowUps() != 0 {
+		t.Errorf("PendingFollowUps() = %d, want 0 after the resume", agent.PendingFollowUps())
+	}
+}
+
+func TestPlanChangedFollowUp(t *testing.T) {
+	got := PlanChangedFollowUp(domain.TaskID{Module: "billing", EpicNum: 2}, "docs/plans/billing/epic-02.md", "\n# Invoices\n\nAdd credit notes.\n")
+	for _, want := range []string{"billing/E02", "docs/plans/billing/epic-02.md", "# Invoices\n\nAdd credit notes."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("follow-up %q does not contain %q", got, want)
+		}
+	}
+}
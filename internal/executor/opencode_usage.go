@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// openCodeTokens is the token usage OpenCode records for a step or message
+type openCodeTokens struct {
+	Input     int `json:"input"`
+	Output    int `json:"output"`
+	Reasoning int `json:"reasoning"`
+}
+
+// openCodeStepFinish is the event OpenCode's JSON output emits after each
+// step of the agent, with the usage of that step
+type openCodeStepFinish struct {
+	Type string `json:"type"`
+	Part struct {
+		Tokens openCodeTokens `json:"tokens"`
+		Cost   float64        `json:"cost"`
+	} `json:"part"`
+}
+
+// parseOpenCodeUsageLine adds the usage of an OpenCode step_finish event to
+// the agent's totals. OpenCode reports usage per step rather than once per
+// run like Claude Code's result message.
+func (a *Agent) parseOpenCodeUsageLine(line string) {
+	var msg openCodeStepFinish
+	if err := json.Unmarshal([]byte(line), &msg); err != nil || msg.Type != "step_finish" {
+		return
+	}
+	a.mu.Lock()
+	a.TokensInput += msg.Part.Tokens.Input
+	a.TokensOutput += msg.Part.Tokens.Output + msg.Part.Tokens.Reasoning
+	a.CostUSD += msg.Part.Cost
+	a.mu.Unlock()
+}
+
+// loadOpenCodeUsage sets the usage of an OpenCode agent from the session
+// OpenCode stored for its worktree. OpenCode's default output format, which
+// agents run with, does not report usage.
+func (a *Agent) loadOpenCodeUsage() {
+	a.mu.Lock()
+	reported := a.TokensInput > 0 || a.TokensOutput > 0
+	worktree := a.WorktreePath
+	a.mu.Unlock()
+	if reported || worktree == "" {
+		return
+	}
+
+	in, out, cost, err := openCodeSessionUsage(openCodeDataDir(), worktree)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	a.TokensInput = in
+	a.TokensOutput = out
+	a.CostUSD = cost
+	a.mu.Unlock()
+}
+
+// openCodeDataDir returns the directory OpenCode keeps its data in
+func openCodeDataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "opencode")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "opencode")
+}
+
+// openCodeSessionUsage sums the usage of the assistant messages in the most
+// recently updated OpenCode session for directory. OpenCode stores sessions
+// as storage/session/<project>/<session>.json and their messages as
+// storage/message/<session>/<message>.json under its data directory.
+func openCodeSessionUsage(dataDir, directory string) (in, out int, cost float64, err error) {
+	sessionFiles, err := filepath.Glob(filepath.Join(dataDir, "storage", "session", "*", "*.json"))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var sessionID string
+	var updated int64
+	for _, path := range sessionFiles {
+		var session struct {
+			ID        string `json:"id"`
+			Directory string `json:"directory"`
+			Time      struct {
+				Updated int64 `json:"updated"`
+			} `json:"time"`
+		}
+		if readJSONFile(path, &session) != nil || session.Directory != directory {
+			continue
+		}
+		if sessionID == "" || session.Time.Updated > updated {
+			sessionID = session.ID
+			updated = session.Time.Updated
+		}
+	}
+	if sessionID == "" {
+		return 0, 0, 0, os.ErrNotExist
+	}
+
+	messageFiles, err := filepath.Glob(filepath.Join(dataDir, "storage", "message", sessionID, "*.json"))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, path := range messageFiles {
+		var message struct {
+			Role   string         `json:"role"`
+			Tokens openCodeTokens `json:"tokens"`
+			Cost   float64        `json:"cost"`
+		}
+		if readJSONFile(path, &message) != nil || message.Role != "assistant" {
+			continue
+		}
+		in += message.Tokens.Input
+		out += message.Tokens.Output + message.Tokens.Reasoning
+		cost += message.Cost
+	}
+	return in, out, cost, nil
+}
+
+// readJSONFile unmarshals the JSON file at path into v
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
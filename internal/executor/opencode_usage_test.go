@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeOpenCodeFile writes a file of OpenCode's storage below dataDir
+func writeOpenCodeFile(t *testing.T, dataDir, path, content string) {
+	t.Helper()
+	full := filepath.Join(dataDir, "storage", path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAgent_LoadOpenCodeUsage(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	dataDir := filepath.Join(dataHome, "opencode")
+	worktree := "/worktrees/tech-E01"
+
+	// An older session of the worktree, the latest one and another project's
+	writeOpenCodeFile(t, dataDir, "session/proj/ses_old.json",
+		`{"id":"ses_old","directory":"/worktrees/tech-E01","time":{"updated":100}}`)
+	writeOpenCodeFile(t, dataDir, "session/proj/ses_new.json",
+		`{"id":"ses_new","directory":"/worktrees/tech-E01","time":{"updated":200}}`)
+	writeOpenCodeFile(t, dataDir, "session/other/ses_other.json",
+		`{"id":"ses_other","directory":"/elsewhere","time":{"updated":300}}`)
+
+	writeOpenCodeFile(t, dataDir, "message/ses_old/msg_1.json",
+		`{"role":"assistant","tokens":{"input":999,"output":999},"cost":9}`)
+	writeOpenCodeFile(t, dataDir, "message/ses_new/msg_1.json",
+		`{"role":"user"}`)
+	writeOpenCodeFile(t, dataDir, "message/ses_new/msg_2.json",
+		`{"role":"assistant","tokens":{"input":1200,"output":300,"reasoning":50,"cache":{"read":4000}},"cost":0.02}`)
+	writeOpenCodeFile(t, dataDir, "message/ses_new/msg_3.json",
+		`{"role":"assistant","tokens":{"input":800,"output":150},"cost":0.01}`)
+	writeOpenCodeFile(t, dataDir, "message/ses_other/msg_1.json",
+		`{"role":"assistant","tokens":{"input":999,"output":999},"cost":9}`)
+
+	agent := &Agent{ExecutorType: ExecutorOpenCode, WorktreePath: worktree}
+	agent.loadOpenCodeUsage()
+
+	in, out, cost := agent.GetUsage()
+	if in != 2000 || out != 500 {
+		t.Errorf("usage = %d in / %d out, want 2000 / 500 from the latest session", in, out)
+	}
+	if cost < 0.0299 || cost > 0.0301 {
+		t.Errorf("cost = %f, want 0.03", cost)
+	}
+
+	// Usage already reported in the output is kept
+	reported := &Agent{ExecutorType: ExecutorOpenCode, WorktreePath: worktree, TokensInput: 10, TokensOutput: 5}
+	reported.loadOpenCodeUsage()
+	if in, out, _ := reported.GetUsage(); in != 10 || out != 5 {
+		t.Errorf("usage = %d / %d, want the reported 10 / 5 kept", in, out)
+	}
+}
+
+func TestAgent_ParseOpenCodeStepUsage(t *testing.T) {
+	agent := &Agent{ExecutorType: ExecutorOpenCode}
+	for _, line := range []string{
+		`{"type":"step_start","part":{"type":"step-start"}}`,
+		`{"type":"step_finish","part":{"type":"step-finish","tokens":{"input":100,"output":20,"reasoning":5},"cost":0.5}}`,
+		`{"type":"text","part":{"text":"done"}}`,
+		`{"type":"step_finish","part":{"type":"step-finish","tokens":{"input":50,"output":10},"cost":0.25}}`,
+	} {
+		agent.parseUsageFromLine(line)
+	}
+
+	if in, out, cost := agent.GetUsage(); in != 150 || out != 35 || cost != 0.75 {
+		t.Errorf("usage = %d in / %d out / $%.2f, want the steps summed to 150 / 35 / $0.75", in, out, cost)
+	}
+}
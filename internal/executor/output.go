@@ -0,0 +1,270 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxOutputLines is how many lines of output an agent keeps in memory
+// unless its MaxOutputLines says otherwise. The log file has all of them.
+const DefaultMaxOutputLines = 5000
+
+// outputRing holds the last lines of an agent's output, overwriting the
+// oldest line once it holds limit lines
+type outputRing struct {
+	lines []string
+	start int // Index of the oldest line
+}
+
+// len returns the number of lines held
+func (r *outputRing) len() int {
+	return len(r.lines)
+}
+
+// add appends a line, dropping the oldest one if the ring holds limit lines
+func (r *outputRing) add(line string, limit int) {
+	if len(r.lines) < limit {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % len(r.lines)
+}
+
+// tail returns a copy of the last n lines, oldest first (all lines if n is 0)
+func (r *outputRing) tail(n int) []string {
+	if n <= 0 || n > len(r.lines) {
+		n = len(r.lines)
+	}
+	result := make([]string, n)
+	from := (r.start + len(r.lines) - n) % max(len(r.lines), 1)
+	copied := copy(result, r.lines[from:])
+	copy(result[copied:], r.lines[:n-copied])
+	return result
+}
+
+// set replaces the lines with the last limit of lines
+func (r *outputRing) set(lines []string, limit int) {
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	r.lines = append([]string(nil), lines...)
+	r.start = 0
+}
+
+// outputLimit returns how many lines of output the agent keeps in memory
+func (a *Agent) outputLimit() int {
+	if a.MaxOutputLines > 0 {
+		return a.MaxOutputLines
+	}
+	return DefaultMaxOutputLines
+}
+
+// outputSubscriberBuffer is how many lines an output subscriber may fall
+// behind before its channel is closed and it has to resubscribe
+const outputSubscriberBuffer = 1024
+
+// SubscribeOutput returns the last tail lines of output (all retained lines
+// if tail is 0) and a channel that receives every line appended afterwards,
+// so callers only handle new lines instead of copying the output on every
+// poll.
+//
+// The agent's output stays the source of truth: the channel is closed when the output
+// is replaced (e.g. on resume or when loaded from the log) or when the
+// subscriber falls too far behind, and the caller should then resubscribe
+// to resync from a fresh snapshot.
+func (a *Agent) SubscribeOutput(tail int) ([]string, <-chan string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snapshot := a.output.tail(tail)
+
+	ch := make(chan string, outputSubscriberBuffer)
+	a.outputSubs = append(a.outputSubs, ch)
+	return snapshot, ch
+}
+
+// AppendOutput appends a line to the agent's output and delivers it to subscribers
+func (a *Agent) AppendOutput(line string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.appendOutputLocked(line)
+}
+
+// appendOutputLocked appends a line to the output and delivers it to
+// subscribers. Must be called with a.mu held.
+func (a *Agent) appendOutputLocked(line string) {
+	a.output.add(line, a.outputLimit())
+	a.trackOutputLocked(line)
+
+	live := a.outputSubs[:0]
+	for _, ch := range a.outputSubs {
+		select {
+		case ch <- line:
+			live = append(live, ch)
+		default:
+			close(ch) // Subscriber fell behind; it resyncs by resubscribing
+		}
+	}
+	a.outputSubs = live
+}
+
+// setOutputLocked replaces the output and closes all subscriptions so that
+// subscribers resync. Must be called with a.mu held.
+func (a *Agent) setOutputLocked(lines []string) {
+	a.output.set(lines, a.outputLimit())
+	for _, ch := range a.outputSubs {
+		close(ch)
+	}
+	a.outputSubs = nil
+}
+
+// maxOutputLineBytes is how long a single line of agent output may get;
+// the rest of a longer line is dropped. Result messages with large tool
+// output stay well below it, a binary dumped to stdout need not.
+const maxOutputLineBytes = 1024 * 1024
+
+// readOutputLines calls fn with every line read from r until EOF, sanitized
+// for display. Unlike a bufio.Scanner it does not give up on lines longer
+// than maxOutputLineBytes but truncates them, so a process dumping binary
+// output is still drained and never blocks on a full pipe.
+func readOutputLines(r io.Reader, fn func(line string)) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	dropped := 0
+	for {
+		chunk, more, err := reader.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		keep := min(len(chunk), maxOutputLineBytes-len(line))
+		line = append(line, chunk[:keep]...)
+		dropped += len(chunk) - keep
+		if more {
+			continue
+		}
+
+		if dropped > 0 {
+			// Cut at a character boundary and drop the partial character too
+			kept := trimPartialRune(line)
+			dropped += len(line) - len(kept)
+			fn(fmt.Sprintf("%s... [truncated %d bytes]", SanitizeOutputLine(string(kept)), dropped))
+		} else {
+			fn(SanitizeOutputLine(string(line)))
+		}
+		line, dropped = line[:0], 0
+	}
+}
+
+// trimPartialRune strips an incomplete UTF-8 sequence from the end of b
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// SanitizeOutputLine makes a line of process output safe to store and
+// display: bytes that are not valid UTF-8 and control characters other than
+// tabs, which would garble the terminal, are replaced by \xNN escapes.
+// Lines without either are returned unchanged.
+func SanitizeOutputLine(line string) string {
+	if utf8.ValidString(line) && strings.IndexFunc(line, isUnsafeRune) < 0 {
+		return line
+	}
+
+	var b strings.Builder
+	b.Grow(len(line) + 16)
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			fmt.Fprintf(&b, "\\x%02x", line[i])
+		case isUnsafeRune(r):
+			fmt.Fprintf(&b, "\\x%02x", r)
+		default:
+			b.WriteString(line[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// isUnsafeRune reports whether r is a control character other than a tab
+func isUnsafeRune(r rune) bool {
+	return r != '\t' && unicode.IsControl(r)
+}
+
+// tailChunkSize is how many bytes TailFile reads at a time, from the end
+const tailChunkSize = 64 * 1024
+
+// TailFile returns the last n lines of the file at path (all lines if n is 0)
+// without a trailing newline, sanitized like live output. It reads backwards from the end of the file,
+// so only the tail is read, however large the file is.
+func TailFile(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return tailLines(file, info.Size(), n)
+}
+
+// tailLines returns the last n lines of the size bytes in r, reading backwards
+func tailLines(r io.ReaderAt, size int64, n int) ([]string, error) {
+	// Read chunks backwards until the data holds n line breaks besides a
+	// trailing one; the n-th from the end precedes the first line of the tail
+	var data []byte
+	for offset := size; offset > 0; {
+		chunkSize := int64(tailChunkSize)
+		if offset < chunkSize {
+			chunkSize = offset
+		}
+		offset -= chunkSize
+
+		chunk := make([]byte, chunkSize)
+		if _, err := r.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+
+		if n > 0 && bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) >= n {
+			break
+		}
+	}
+
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = SanitizeOutputLine(string(line))
+	}
+	return result, nil
+}
@@ -0,0 +1,331 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAgent_SubscribeOutput(t *testing.T) {
+	agent := &Agent{}
+	for i := 0; i < 5; i++ {
+		agent.AppendOutput(fmt.Sprintf("old %d", i))
+	}
+
+	snapshot, ch := agent.SubscribeOutput(2)
+	if len(snapshot) != 2 || snapshot[1] != "old 4" {
+		t.Errorf("snapshot = %v, want last 2 lines", snapshot)
+	}
+
+	agent.AppendOutput("new 1")
+	agent.AppendOutput("new 2")
+
+	if len(ch) != 2 {
+		t.Fatalf("channel holds %d lines, want only the 2 new ones", len(ch))
+	}
+	if line := <-ch; line != "new 1" {
+		t.Errorf("first delivered line = %q, want %q", line, "new 1")
+	}
+	<-ch
+
+	// Replacing the output closes the subscription so subscribers resync
+	agent.mu.Lock()
+	agent.setOutputLocked(nil)
+	agent.mu.Unlock()
+
+	if _, ok := <-ch; ok {
+		t.Error("channel should be closed after output is replaced")
+	}
+	if output := agent.GetOutput(); len(output) != 0 {
+		t.Errorf("output = %v, want empty", output)
+	}
+}
+
+func TestAgent_SubscribeOutputClosesSlowSubscriber(t *testing.T) {
+	agent := &Agent{}
+	_, ch := agent.SubscribeOutput(0)
+
+	for i := 0; i <= outputSubscriberBuffer; i++ {
+		agent.AppendOutput(fmt.Sprintf("line %d", i))
+	}
+
+	// The buffered lines drain, then the channel reports closed
+	received := 0
+	for range ch {
+		received++
+	}
+	if received != outputSubscriberBuffer {
+		t.Errorf("received %d lines, want %d", received, outputSubscriberBuffer)
+	}
+	if n := len(agent.GetOutput()); n != outputSubscriberBuffer+1 {
+		t.Errorf("output has %d lines, want %d (it stays the source of truth)", n, outputSubscriberBuffer+1)
+	}
+}
+
+func TestOutputRing(t *testing.T) {
+	var r outputRing
+	for i := 1; i <= 5; i++ {
+		r.add(fmt.Sprintf("line %d", i), 3)
+	}
+	if got := strings.Join(r.tail(0), ","); got != "line 3,line 4,line 5" {
+		t.Errorf("tail(0) = %q, want the last 3 lines oldest first", got)
+	}
+	if got := strings.Join(r.tail(2), ","); got != "line 4,line 5" {
+		t.Errorf("tail(2) = %q", got)
+	}
+	if got := strings.Join(r.tail(10), ","); got != "line 3,line 4,line 5" {
+		t.Errorf("tail(10) = %q, want all lines", got)
+	}
+
+	r.set([]string{"a", "b", "c", "d"}, 3)
+	r.add("e", 3)
+	if got := strings.Join(r.tail(0), ","); got != "c,d,e" {
+		t.Errorf("after set and add = %q, want c,d,e", got)
+	}
+
+	r.set(nil, 3)
+	if got := r.tail(0); len(got) != 0 {
+		t.Errorf("tail of empty ring = %v", got)
+	}
+}
+
+func TestAgent_OutputKeepsLastLines(t *testing.T) {
+	runner := &fakeRunner{output: []string{"one", "two", "three", "four"}}
+	agent := newFakeAgent(t, runner)
+	agent.MaxOutputLines = 2
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	if got := strings.Join(agent.GetOutput(), ","); got != "three,four" {
+		t.Errorf("GetOutput() = %q, want only the last 2 lines", got)
+	}
+	snapshot, _ := agent.SubscribeOutput(100)
+	if len(snapshot) != 2 {
+		t.Errorf("SubscribeOutput(100) = %q, want the 2 retained lines", snapshot)
+	}
+
+	// The log file still has every line
+	log, err := os.ReadFile(agent.LogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range runner.output {
+		if !strings.Contains(string(log), line+"\n") {
+			t.Errorf("log misses %q", line)
+		}
+	}
+}
+
+func TestSanitizeOutputLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"plain text\twith tab", "plain text\twith tab"},
+		{"ünïcödé ✓", "ünïcödé ✓"},
+		{"bin\xff\xfeary", `bin\xff\xfeary`},
+		{"\x1b[31mred\x1b[0m", `\x1b[31mred\x1b[0m`},
+		{"nul\x00byte\r", `nul\x00byte\x0d`},
+		{"cut \xe2\x9c", `cut \xe2\x9c`},
+	}
+	for _, tt := range tests {
+		if got := SanitizeOutputLine(tt.line); got != tt.want {
+			t.Errorf("SanitizeOutputLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestReadOutputLines(t *testing.T) {
+	long := strings.Repeat("a", maxOutputLineBytes-1) + "✓✓" // The limit splits the first ✓
+	input := "first\r\n" + long + "\n\xff\x00\nlast without newline"
+
+	var lines []string
+	if err := readOutputLines(strings.NewReader(input), func(line string) {
+		lines = append(lines, line)
+	}); err != nil {
+		t.Fatalf("readOutputLines() error = %v", err)
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("read %d lines, want 4", len(lines))
+	}
+	if lines[0] != "first" {
+		t.Errorf("line 1 = %q, want the CRLF stripped", lines[0])
+	}
+	want := strings.Repeat("a", maxOutputLineBytes-1) + "... [truncated 6 bytes]"
+	if lines[1] != want {
+		t.Errorf("line 2 has %d bytes ending in %q, want it truncated at the last whole character",
+			len(lines[1]), lines[1][max(len(lines[1])-30, 0):])
+	}
+	if lines[2] != `\xff\x00` {
+		t.Errorf("line 3 = %q, want the binary escaped", lines[2])
+	}
+	if lines[3] != "last without newline" {
+		t.Errorf("line 4 = %q, want the unterminated last line", lines[3])
+	}
+}
+
+func TestAgent_BinaryOutput(t *testing.T) {
+	runner := &fakeRunner{output: []string{
+		"\x7fELF\x02\x01\x01\x00\x00",
+		strings.Repeat("\x00\xff", maxOutputLineBytes),
+		`{"type":"result","usage":{"input_tokens":100,"output_tokens":20}}`,
+	}}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	output := agent.GetOutput()
+	if len(output) != 3 {
+		t.Fatalf("output has %d lines, want 3", len(output))
+	}
+	if output[0] != `\x7fELF\x02\x01\x01\x00\x00` {
+		t.Errorf("output[0] = %q, want the binary escaped", output[0])
+	}
+	if !strings.HasSuffix(output[1], "... [truncated 1048576 bytes]") {
+		t.Errorf("output[1] ends in %q, want the overlong line truncated", output[1][max(len(output[1])-40, 0):])
+	}
+	for i, line := range output {
+		if !utf8.ValidString(line) {
+			t.Errorf("output[%d] is not valid UTF-8", i)
+		}
+	}
+	if agent.TokensInput != 100 {
+		t.Errorf("TokensInput = %d, want the usage after the binary parsed", agent.TokensInput)
+	}
+
+	log, err := os.ReadFile(agent.LogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.Valid(log) {
+		t.Error("log file is not valid UTF-8")
+	}
+}
+
+// benchmarkOutputLines is the output size for the polling benchmarks,
+// typical of a long-running agent
+const benchmarkOutputLines = 50000
+
+// BenchmarkOutputPolling copies the full output every tick, as the TUI did
+// before output subscriptions
+func BenchmarkOutputPolling(b *testing.B) {
+	agent := &Agent{MaxOutputLines: benchmarkOutputLines}
+	for i := 0; i < benchmarkOutputLines; i++ {
+		agent.AppendOutput("line")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agent.AppendOutput("tick")
+		output := agent.GetOutput()
+		_ = output[len(output)-1]
+	}
+}
+
+// BenchmarkOutputSubscription receives only the lines added since the last tick
+func BenchmarkOutputSubscription(b *testing.B) {
+	agent := &Agent{MaxOutputLines: benchmarkOutputLines}
+	for i := 0; i < benchmarkOutputLines; i++ {
+		agent.AppendOutput("line")
+	}
+	_, ch := agent.SubscribeOutput(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agent.AppendOutput("tick")
+		<-ch
+	}
+}
+
+// countingReaderAt counts the bytes read through it
+type countingReaderAt struct {
+	r    *strings.Reader
+	read int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += int64(n)
+	return n, err
+}
+
+func TestTailFile(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&b, "line %06d of a long agent log\n", i)
+	}
+	content := b.String()
+
+	path := filepath.Join(t.TempDir(), "agent.log")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := TailFile(path, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 500 {
+		t.Fatalf("got %d lines, want 500", len(lines))
+	}
+	if lines[0] != "line 199500 of a long agent log" || lines[499] != "line 199999 of a long agent log" {
+		t.Errorf("tail = %q ... %q, want lines 199500 to 199999", lines[0], lines[499])
+	}
+
+	// Only the end of the file is read
+	r := &countingReaderAt{r: strings.NewReader(content)}
+	if _, err := tailLines(r, int64(len(content)), 500); err != nil {
+		t.Fatal(err)
+	}
+	if r.read > tailChunkSize {
+		t.Errorf("read %d of %d bytes, want at most one %d byte chunk", r.read, len(content), tailChunkSize)
+	}
+}
+
+func TestTailFile_ShortFiles(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    []string
+	}{
+		{"empty", "", 10, nil},
+		{"fewer lines than requested", "a\nb\n", 10, []string{"a", "b"}},
+		{"no trailing newline", "a\nb\nc", 2, []string{"b", "c"}},
+		{"all lines", "a\nb\nc\n", 0, []string{"a", "b", "c"}},
+		{"empty lines kept", "a\n\nb\n", 2, []string{"", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-"))
+			os.WriteFile(path, []byte(tt.content), 0644)
+
+			got, err := TailFile(path, tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("TailFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := TailFile(filepath.Join(dir, "missing"), 10); !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error for a missing file, got %v", err)
+	}
+}
@@ -17,8 +17,9 @@ func SetPromptLoader(loader *prompts.Loader) {
 	promptLoader = loader
 }
 
-// BuildPrompt constructs the task prompt for Claude Code
-func BuildPrompt(task *domain.Task, epicContent, moduleOverview string, completedDeps []string) string {
+// BuildPrompt constructs the task prompt, ending with the instructions for
+// marking completion that match the executor (claude-code if empty)
+func BuildPrompt(task *domain.Task, epicContent, moduleOverview string, completedDeps []string, executorType ExecutorType) string {
 	depsStr := "None"
 	if len(completedDeps) > 0 {
 		depsStr = strings.Join(completedDeps, ", ")
@@ -40,9 +41,18 @@ func BuildPrompt(task *domain.Task, epicContent, moduleOverview string, complete
 	result, err := promptLoader.BuildEpicPrompt(data)
 	if err != nil {
 		// Fallback to a basic prompt if template fails
-		return fmt.Sprintf("Implement: %s\n\nEpic: %s\n\n%s", task.Title, epicFilePath, epicContent)
+		result = fmt.Sprintf("Implement: %s\n\nEpic: %s\n\n%s", task.Title, epicFilePath, epicContent)
 	}
-	return result
+
+	if executorType == "" {
+		executorType = ExecutorClaudeCode
+	}
+	completion, err := promptLoader.BuildCompletionPrompt(string(executorType), data)
+	if err != nil {
+		// Unknown executor or broken override: the epic prompt still works without it
+		return result
+	}
+	return strings.TrimRight(result, "\n") + "\n\n" + completion
 }
 
 // BuildCommitMessage creates the commit message format
@@ -55,6 +65,9 @@ func BuildCommitMessage(task *domain.Task, summary string) string {
 	)
 }
 
+// MaintenanceModule is the module of the task IDs maintenance agents run under
+const MaintenanceModule = "maint"
+
 // BuildMaintenancePrompt constructs the prompt for a maintenance task
 func BuildMaintenancePrompt(templatePrompt, scope, targetModule string) string {
 	// Replace {scope} placeholder with the actual scope description
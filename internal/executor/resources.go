@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is the kernel's USER_HZ, which is 100 on all common Linux platforms
+const clockTicksPerSecond = 100
+
+// ResourceUsage is a point-in-time sample of an agent process's CPU and memory usage
+type ResourceUsage struct {
+	CPUPercent float64 // CPU usage since the previous sample (100 = one full core)
+	RSSBytes   int64   // Resident set size
+}
+
+// String formats the sample for display (e.g., "CPU 12.5% | RSS 256.0 MB")
+func (u ResourceUsage) String() string {
+	return fmt.Sprintf("CPU %.1f%% | RSS %s", u.CPUPercent, formatBytes(u.RSSBytes))
+}
+
+// ResourceUsage samples the CPU and memory usage of the agent's process.
+// CPU usage is averaged over the time since the previous call; the first
+// sample reports the average since the process started.
+// Only the agent process itself is measured, not its children.
+// Sampling reads /proc and is only supported on Linux.
+func (a *Agent) ResourceUsage() (ResourceUsage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.PID <= 0 {
+		return ResourceUsage{}, fmt.Errorf("agent has no process")
+	}
+
+	statData, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", a.PID))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("reading process stat: %w", err)
+	}
+	cpuTicks, err := parseProcStatCPUTicks(string(statData))
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	statmData, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", a.PID))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("reading process statm: %w", err)
+	}
+	rss, err := parseProcStatmRSS(string(statmData), os.Getpagesize())
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	now := time.Now()
+	since := a.lastSampleAt
+	prevTicks := a.lastCPUTicks
+	if since.IsZero() && a.StartedAt != nil {
+		since = *a.StartedAt
+		prevTicks = 0
+	}
+	a.lastSampleAt = now
+	a.lastCPUTicks = cpuTicks
+
+	return ResourceUsage{
+		CPUPercent: cpuPercent(cpuTicks-prevTicks, now.Sub(since)),
+		RSSBytes:   rss,
+	}, nil
+}
+
+// parseProcStatCPUTicks returns utime+stime (in clock ticks) from /proc/<pid>/stat
+func parseProcStatCPUTicks(data string) (uint64, error) {
+	// The command name (field 2) is in parentheses and may contain spaces,
+	// so start parsing after the last closing parenthesis
+	end := strings.LastIndex(data, ")")
+	if end == -1 {
+		return 0, fmt.Errorf("malformed stat: missing command name")
+	}
+	// Fields after the command name start at field 3 (state)
+	fields := strings.Fields(data[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed stat: too few fields")
+	}
+	// utime and stime are fields 14 and 15
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing utime: %w", err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing stime: %w", err)
+	}
+	return utime + stime, nil
+}
+
+// parseProcStatmRSS returns the resident set size in bytes from /proc/<pid>/statm
+func parseProcStatmRSS(data string, pageSize int) (int64, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed statm: too few fields")
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing rss: %w", err)
+	}
+	return pages * int64(pageSize), nil
+}
+
+// cpuPercent converts CPU ticks consumed over a wall-clock interval to a percentage
+func cpuPercent(ticks uint64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	cpuSeconds := float64(ticks) / clockTicksPerSecond
+	return cpuSeconds / elapsed.Seconds() * 100
+}
+
+// formatBytes formats a byte count using binary units (e.g., "256.0 MB")
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// RetryPolicy makes AgentManager resume agents that failed for a transient
+// reason (see IsTransientError), waiting BaseDelay before the first attempt
+// and twice as long before each further one
+type RetryPolicy struct {
+	MaxAttempts int           // Resumes per agent before it is left failed
+	BaseDelay   time.Duration // Wait before the first resume
+}
+
+// maxRetryDelay caps the backoff between retries
+const maxRetryDelay = 30 * time.Minute
+
+// Delay returns how long to wait before the given attempt (1 = first retry)
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// transientErrorPattern matches failures that may go away when the agent is
+// resumed: API server errors, network problems and lost connections, like a
+// disconnected MCP server or an executor killed by SIGHUP or SIGPIPE
+var transientErrorPattern = regexp.MustCompile(`(?i)API Error: 5\d\d|(error|status|code)[: ]+5\d\d|internal server error|bad gateway|service unavailable|gateway timeout|connection (reset|refused|closed)|ECONNRESET|ECONNREFUSED|ETIMEDOUT|EPIPE|EAI_AGAIN|socket hang up|fetch failed|network error|temporary failure|(server|worker)( \S+)? disconnected|signal: hangup|broken pipe`)
+
+// hardErrorPattern matches failures that resuming cannot fix, even if the
+// message also mentions something transient
+var hardErrorPattern = regexp.MustCompile(`(?i)already complete|agent reported failure|billing|payment|unauthorized|authentication|invalid api key|timed out after|cancelled by user`)
+
+// IsTransientError reports whether an agent's error message says it failed
+// for a reason worth retrying, such as API throttling (HTTP 429 or 529) or a
+// network blip. The message is the exit status, which shows a process killed
+// by a signal, followed by the error extractErrorFromOutput found. Unknown
+// failures, like a bare "exit status 1", are not transient.
+func IsTransientError(msg string) bool {
+	if msg == "" || hardErrorPattern.MatchString(msg) {
+		return false
+	}
+	return IsRateLimitError(msg) || transientErrorPattern.MatchString(msg)
+}
+
+// SetRetryPolicy sets how agents that fail for a transient reason are
+// resumed automatically (nil = never)
+func (m *AgentManager) SetRetryPolicy(policy *RetryPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryPolicy = policy
+}
+
+// GetRetryPolicy returns how failed agents are retried (nil if they are not)
+func (m *AgentManager) GetRetryPolicy() *RetryPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retryPolicy
+}
+
+// scheduleRetry resumes a failed agent after the policy's backoff if its
+// failure was transient and it has attempts left, and reports whether it did
+func (m *AgentManager) scheduleRetry(agent *Agent, errMsg string) bool {
+	policy := m.GetRetryPolicy()
+	if policy == nil || !IsTransientError(errMsg) {
+		return false
+	}
+
+	agent.mu.Lock()
+	if agent.RetryAttempts >= policy.MaxAttempts {
+		agent.mu.Unlock()
+		return false
+	}
+	agent.RetryAttempts++
+	m.armRetryLocked(agent, policy.Delay(agent.RetryAttempts))
+	agent.mu.Unlock()
+	return true
+}
+
+// armRetryLocked schedules the automatic resume of a failed agent after
+// delay. Without a free slot at that time, the resume is pushed back by the
+// policy's base delay without using up an attempt. The caller must hold
+// agent.mu.
+func (m *AgentManager) armRetryLocked(agent *Agent, delay time.Duration) {
+	agent.retryAt = time.Now().Add(delay)
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		agent.mu.Lock()
+		if agent.retryTimer != timer {
+			agent.mu.Unlock()
+			return // Cancelled by a manual resume, restart or cancel
+		}
+		agent.retryTimer = nil
+		agent.retryAt = time.Time{}
+		status := agent.Status
+		agent.mu.Unlock()
+
+		// A restart replaces the agent in the manager; leave the old one alone
+		if status != AgentFailed || m.Get(agent.TaskID.String()) != agent {
+			return
+		}
+		err := m.ResumeAgent(context.Background(), agent, "")
+		if errors.Is(err, ErrNoSlot) {
+			if policy := m.GetRetryPolicy(); policy != nil {
+				agent.mu.Lock()
+				if agent.retryTimer == nil && agent.Status == AgentFailed {
+					m.armRetryLocked(agent, policy.BaseDelay)
+				}
+				agent.mu.Unlock()
+				return
+			}
+		}
+		if err != nil {
+			agent.mu.Lock()
+			if agent.Status == AgentFailed {
+				agent.Error = fmt.Errorf("retry %d failed: %w", agent.RetryAttempts, err)
+			}
+			agent.mu.Unlock()
+		}
+	})
+	agent.retryTimer = timer
+}
+
+// cancelRetryLocked stops a scheduled automatic resume. The caller must hold
+// a.mu.
+func (a *Agent) cancelRetryLocked() {
+	if a.retryTimer != nil {
+		a.retryTimer.Stop()
+		a.retryTimer = nil
+	}
+	a.retryAt = time.Time{}
+}
+
+// cancelRetry stops a scheduled automatic resume
+func (a *Agent) cancelRetry() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cancelRetryLocked()
+}
+
+// PendingRetry returns the attempt number and time of an automatic resume
+// scheduled for the agent (a zero time if none is)
+func (a *Agent) PendingRetry() (attempt int, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.retryAt.IsZero() {
+		return 0, time.Time{}
+	}
+	return a.RetryAttempts, a.retryAt
+}
@@ -0,0 +1,215 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"exit status 1: API rate limit: API Error: 529 Overloaded", true},
+		{"exit status 1: API Error: 500 Internal server error", true},
+		{"exit status 1: read ECONNRESET", true},
+		{"exit status 1: fetch failed", true},
+		{"signal: hangup", true},
+		{"exit status 1: MCP server build-pool disconnected", true},
+		{"exit status 1: Error: getaddrinfo EAI_AGAIN api.anthropic.com", true},
+		{"signal: killed", false},
+		{"exit status 1", false},
+		{"", false},
+		{"task already complete (epic status: complete)", false},
+		{"timed out after 30m0s", false},
+		{"agent reported failure: network tests need credentials", false},
+		{"exit status 1: OpenCode billing error: No payment method configured", false},
+	}
+	for _, tt := range tests {
+		if got := IsTransientError(tt.msg); got != tt.want {
+			t.Errorf("IsTransientError(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 20, BaseDelay: 30 * time.Second}
+	if got := policy.Delay(1); got != 30*time.Second {
+		t.Errorf("Delay(1) = %s, want the base delay", got)
+	}
+	if got := policy.Delay(3); got != 2*time.Minute {
+		t.Errorf("Delay(3) = %s, want 2m", got)
+	}
+	if got := policy.Delay(20); got != maxRetryDelay {
+		t.Errorf("Delay(20) = %s, want it capped at %s", got, maxRetryDelay)
+	}
+}
+
+func TestAgentManager_RetriesTransientFailures(t *testing.T) {
+	installFakeExecutable(t, "claude")
+	t.Setenv("HOME", t.TempDir())
+
+	mgr := NewAgentManager(2)
+	mgr.SetRetryPolicy(&RetryPolicy{MaxAttempts: 1, BaseDelay: 10 * time.Millisecond})
+	callback := mgr.CreateStatusCallback()
+
+	agent := &Agent{
+		TaskID:         domain.TaskID{Module: "tech", EpicNum: 1},
+		WorktreePath:   t.TempDir(),
+		Status:         AgentFailed,
+		OnStatusChange: callback,
+	}
+	mgr.Add(agent)
+	callback(agent, AgentFailed, "exit status 1: API rate limit: API Error: 529 Overloaded")
+	if attempt, at := agent.PendingRetry(); attempt != 1 || at.IsZero() {
+		t.Fatalf("PendingRetry() = %d, %v; want attempt 1 scheduled", attempt, at)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for agent.GetStatus() != AgentCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %s, want the agent resumed and completed", agent.GetStatus())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Attempts are exhausted, so the next failure is left alone
+	callback(agent, AgentFailed, "exit status 1: API rate limit: API Error: 529 Overloaded")
+	if attempt, at := agent.PendingRetry(); !at.IsZero() || agent.RetryAttempts != 1 {
+		t.Errorf("PendingRetry() = %d, %v after %d attempts; want no retry once exhausted", attempt, at, agent.RetryAttempts)
+	}
+
+	// Hard failures are never retried
+	hard := &Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 2}, Status: AgentFailed}
+	callback(hard, AgentFailed, "task already complete (epic status: complete)")
+	if _, at := hard.PendingRetry(); !at.IsZero() || hard.RetryAttempts != 0 {
+		t.Error("a hard failure should not be retried")
+	}
+}
+
+func TestAgentManager_ManualActionsCancelRetry(t *testing.T) {
+	installFakeExecutable(t, "claude")
+	t.Setenv("HOME", t.TempDir())
+
+	mgr := NewAgentManager(2)
+	mgr.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: 20 * time.Millisecond})
+	callback := mgr.CreateStatusCallback()
+	failed := func(num int) *Agent {
+		agent := &Agent{
+			TaskID:         domain.TaskID{Module: "tech", EpicNum: num},
+			WorktreePath:   t.TempDir(),
+			Status:         AgentFailed,
+			OnStatusChange: callback,
+		}
+		mgr.Add(agent)
+		callback(agent, AgentFailed, "exit status 1: read ECONNRESET")
+		if _, at := agent.PendingRetry(); at.IsZero() {
+			t.Fatalf("%s: want a resume scheduled", agent.TaskID)
+		}
+		return agent
+	}
+
+	// Cancelling drops the scheduled resume
+	cancelled := failed(1)
+	if running, err := mgr.Cancel(context.Background(), "tech/E01", nil); running || err != nil {
+		t.Fatalf("Cancel() = %v, %v; want a no-op for a failed agent", running, err)
+	}
+	if _, at := cancelled.PendingRetry(); !at.IsZero() {
+		t.Error("Cancel should drop the scheduled resume")
+	}
+
+	// An agent replaced by a restart is not resumed any more
+	replaced := failed(2)
+	mgr.Add(&Agent{TaskID: replaced.TaskID, Status: AgentRunning})
+
+	time.Sleep(200 * time.Millisecond)
+	for _, agent := range []*Agent{cancelled, replaced} {
+		if status := agent.GetStatus(); status != AgentFailed {
+			t.Errorf("%s: status = %s, want it left failed", agent.TaskID, status)
+		}
+	}
+}
+
+func TestAgentManager_RetriesTransientProcessFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		retry  bool
+	}{
+		{"network error on stderr", `echo 'Error: read ECONNRESET' >&2; exit 1`, true},
+		{"lost terminal", `kill -HUP $$`, true},
+		{"genuine error on stderr", `echo 'SyntaxError: Unexpected token' >&2; exit 1`, false},
+		{"bare exit status", `exit 1`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := NewAgentManager(2)
+			defer mgr.StopDBWriter()
+			// Long enough that the resume never starts during the test
+			mgr.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour})
+
+			agent := &Agent{
+				TaskID:         domain.TaskID{Module: "tech", EpicNum: 1},
+				Status:         AgentRunning,
+				OnStatusChange: mgr.CreateStatusCallback(),
+			}
+			proc, err := ExecRunner{}.Start(context.Background(), exec.Command("sh", "-c", tt.script))
+			if err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			agent.proc = proc
+			agent.streamOutput(proc.Stdout(), proc.Stderr())
+
+			if agent.GetStatus() != AgentFailed {
+				t.Fatalf("status = %s, want failed", agent.GetStatus())
+			}
+			if _, at := agent.PendingRetry(); !at.IsZero() != tt.retry {
+				t.Errorf("failure %q: resume scheduled = %v, want %v", agent.Error, !at.IsZero(), tt.retry)
+			}
+		})
+	}
+}
+
+func TestAgentManager_RetryWaitsForFreeSlot(t *testing.T) {
+	installFakeExecutable(t, "claude")
+	t.Setenv("HOME", t.TempDir())
+
+	mgr := NewAgentManager(1)
+	mgr.SetRetryPolicy(&RetryPolicy{MaxAttempts: 1, BaseDelay: 10 * time.Millisecond})
+	callback := mgr.CreateStatusCallback()
+
+	// The only slot is taken
+	busy := &Agent{TaskID: domain.TaskID{Module: "tech", EpicNum: 1}, Status: AgentRunning}
+	mgr.Add(busy)
+
+	agent := &Agent{
+		TaskID:         domain.TaskID{Module: "tech", EpicNum: 2},
+		WorktreePath:   t.TempDir(),
+		Status:         AgentFailed,
+		OnStatusChange: callback,
+	}
+	mgr.Add(agent)
+	callback(agent, AgentFailed, "exit status 1: read ECONNRESET")
+
+	time.Sleep(100 * time.Millisecond)
+	if status := agent.GetStatus(); status != AgentFailed {
+		t.Fatalf("status = %s, want the resume held back without a free slot", status)
+	}
+	if attempt, at := agent.PendingRetry(); attempt != 1 || at.IsZero() {
+		t.Fatalf("PendingRetry() = %d, %v; want attempt 1 rescheduled", attempt, at)
+	}
+
+	// Once the slot frees up, the rescheduled resume runs
+	mgr.UpdateAgentStatus(busy.TaskID.String(), AgentCompleted, "")
+	deadline := time.Now().Add(5 * time.Second)
+	for agent.GetStatus() != AgentCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %s, want the agent resumed once a slot was free", agent.GetStatus())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// CommandRunner starts the executor processes of agents. Agents build their
+// commands as *exec.Cmd (arguments, directory and environment) and hand them
+// to their runner, so tests can substitute a fake that records the commands
+// and simulates output instead of spawning claude or opencode.
+type CommandRunner interface {
+	// Start starts cmd. The process must end when ctx is cancelled.
+	Start(ctx context.Context, cmd *exec.Cmd) (Process, error)
+}
+
+// Process is an executor process started by a CommandRunner
+type Process interface {
+	Stdout() io.Reader
+	Stderr() io.Reader
+	Pid() int
+	// Signal sends sig to the process
+	Signal(sig os.Signal) error
+	// Wait waits for the process to exit after its output was read, like
+	// exec.Cmd.Wait
+	Wait() error
+}
+
+// ExecRunner runs commands with os/exec. It is the runner of agents without one.
+type ExecRunner struct{}
+
+// Start starts cmd, which was built with exec.CommandContext(ctx, ...)
+func (ExecRunner) Start(ctx context.Context, cmd *exec.Cmd) (Process, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &execProcess{cmd: cmd, stdout: stdout, stderr: stderr}, nil
+}
+
+// execProcess is a process started by ExecRunner
+type execProcess struct {
+	cmd    *exec.Cmd
+	stdout io.Reader
+	stderr io.Reader
+}
+
+func (p *execProcess) Stdout() io.Reader { return p.stdout }
+func (p *execProcess) Stderr() io.Reader { return p.stderr }
+func (p *execProcess) Pid() int          { return p.cmd.Process.Pid }
+func (p *execProcess) Wait() error       { return p.cmd.Wait() }
+
+func (p *execProcess) Signal(sig os.Signal) error { return p.cmd.Process.Signal(sig) }
+
+// runner returns the agent's command runner
+func (a *Agent) runner() CommandRunner {
+	if a.Runner != nil {
+		return a.Runner
+	}
+	return ExecRunner{}
+}
+
+// startProcess starts cmd with the agent's runner and records its PID. Must
+// be called with a.mu held.
+func (a *Agent) startProcess(ctx context.Context, cmd *exec.Cmd) error {
+	execName := a.executable()
+	if a.Sandbox != nil {
+		execName = cmd.Args[0]
+	}
+	proc, err := a.runner().Start(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("starting %s: %w", execName, err)
+	}
+	a.proc = proc
+	a.PID = proc.Pid()
+	return nil
+}
@@ -0,0 +1,412 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// fakeRunner records the commands agents start and simulates their
+// processes: each prints output and exits with exitErr, once release is
+// closed if it is set, or, with block set, runs until its context is
+// cancelled or, with exitOnTerm set too, it gets SIGTERM (exiting with 0
+// if termExitsCleanly is set)
+type fakeRunner struct {
+	output           []string
+	exitErr          error
+	startErr         error
+	block            bool
+	exitOnTerm       bool
+	termExitsCleanly bool
+	release          chan struct{}
+
+	mu   sync.Mutex
+	cmds []*exec.Cmd
+}
+
+func (r *fakeRunner) Start(ctx context.Context, cmd *exec.Cmd) (Process, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cmds = append(r.cmds, cmd)
+	if r.startErr != nil {
+		return nil, r.startErr
+	}
+
+	stdout := strings.NewReader(strings.Join(r.output, "\n") + "\n")
+	exitErr, release := r.exitErr, r.release
+	proc := &fakeProcess{stdout: stdout, pid: 4000 + len(r.cmds), terminated: make(chan struct{}), wait: func() error {
+		if release != nil {
+			<-release
+		}
+		return exitErr
+	}}
+	if r.block {
+		terminated, termErr := proc.terminated, errors.New("signal: terminated")
+		if !r.exitOnTerm {
+			terminated = nil // Ignores SIGTERM
+		}
+		if r.termExitsCleanly {
+			termErr = nil
+		}
+		proc.wait = func() error {
+			select {
+			case <-ctx.Done():
+				return errors.New("signal: killed")
+			case <-terminated:
+				return termErr
+			}
+		}
+	}
+	return proc, nil
+}
+
+// commands returns the arguments of the commands started so far
+func (r *fakeRunner) commands() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var args [][]string
+	for _, cmd := range r.cmds {
+		args = append(args, cmd.Args)
+	}
+	return args
+}
+
+type fakeProcess struct {
+	stdout io.Reader
+	pid    int
+	wait   func() error
+
+	mu         sync.Mutex
+	signals    []os.Signal
+	terminated chan struct{} // Closed by the first SIGTERM
+}
+
+func (p *fakeProcess) Stdout() io.Reader { return p.stdout }
+func (p *fakeProcess) Stderr() io.Reader { return strings.NewReader("") }
+func (p *fakeProcess) Pid() int          { return p.pid }
+func (p *fakeProcess) Wait() error       { return p.wait() }
+
+func (p *fakeProcess) Signal(sig os.Signal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sig == syscall.SIGTERM && !slices.Contains(p.signals, sig) {
+		close(p.terminated)
+	}
+	p.signals = append(p.signals, sig)
+	return nil
+}
+
+// received returns the signals sent to the process so far
+func (p *fakeProcess) received() []os.Signal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.signals)
+}
+
+func newFakeAgent(t *testing.T, runner *fakeRunner) *Agent {
+	t.Helper()
+	t.Setenv("BUILD_MCP_PATH", "")
+	return &Agent{
+		TaskID:       domain.TaskID{Module: "tech", EpicNum: 7},
+		WorktreePath: t.TempDir(),
+		Status:       AgentQueued,
+		Prompt:       "implement it",
+		SessionID:    "session-7",
+		Runner:       runner,
+	}
+}
+
+func waitForAgent(t *testing.T, agent *Agent) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := agent.Wait(ctx); err != nil {
+		t.Fatalf("agent did not finish: %v", err)
+	}
+}
+
+func TestAgent_StartWithRunner(t *testing.T) {
+	runner := &fakeRunner{output: []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"working"}]}}`,
+		`{"type":"result","usage":{"input_tokens":100,"output_tokens":20},"total_cost_usd":0.5}`,
+	}}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	cmds := runner.commands()
+	if len(cmds) != 1 {
+		t.Fatalf("started %d commands, want 1", len(cmds))
+	}
+	if cmds[0][0] != "claude" || !slices.Contains(cmds[0], "--session-id") || cmds[0][len(cmds[0])-1] != "implement it" {
+		t.Errorf("command = %q, want claude with the session and prompt", cmds[0])
+	}
+	if dir := runner.cmds[0].Dir; dir != agent.WorktreePath {
+		t.Errorf("Dir = %q, want the worktree", dir)
+	}
+	if agent.PID != 4001 {
+		t.Errorf("PID = %d, want the fake process's", agent.PID)
+	}
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Errorf("status = %s, want completed", got)
+	}
+	if agent.TokensInput != 100 || agent.TokensOutput != 20 {
+		t.Errorf("tokens = %d/%d, want usage parsed from the output", agent.TokensInput, agent.TokensOutput)
+	}
+}
+
+func TestAgent_ResumeWithRunner(t *testing.T) {
+	runner := &fakeRunner{exitErr: errors.New("exit status 1")}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+	if got := agent.GetStatus(); got != AgentFailed {
+		t.Fatalf("status = %s, want failed after a non-zero exit", got)
+	}
+
+	runner.mu.Lock()
+	runner.exitErr = nil
+	runner.mu.Unlock()
+	if err := agent.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	cmds := runner.commands()
+	if len(cmds) != 2 || !strings.Contains(strings.Join(cmds[1], " "), "--resume session-7") {
+		t.Fatalf("commands = %q, want a resume of session-7", cmds)
+	}
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Errorf("status = %s, want completed after the resume", got)
+	}
+	if agent.GetError() != nil {
+		t.Errorf("error = %v, want it cleared by the resume", agent.GetError())
+	}
+}
+
+func TestAgent_ResumeAfterReportedFailure(t *testing.T) {
+	runner := &fakeRunner{output: []string{
+		`{"type":"result","subtype":"success","result":"Stopping.\nORCHESTRATOR_RESULT: failure flaky test"}`,
+	}}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+	if got := agent.GetStatus(); got != AgentFailed {
+		t.Fatalf("status = %s, want failed after a reported failure", got)
+	}
+
+	// The resumed session exits cleanly without reporting an outcome
+	runner.mu.Lock()
+	runner.output = []string{`{"type":"result","subtype":"success","result":"Fixed the test."}`}
+	runner.mu.Unlock()
+	if err := agent.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Errorf("status = %s, want completed; the earlier failure report must not carry over", got)
+	}
+	if agent.GetError() != nil {
+		t.Errorf("error = %v, want none after the resume succeeded", agent.GetError())
+	}
+}
+
+func TestAgent_ResumeClearsRateLimit(t *testing.T) {
+	runner := &fakeRunner{
+		output:  []string{`{"type":"result","is_error":true,"result":"API Error: 429 rate_limit_error"}`},
+		exitErr: errors.New("exit status 1"),
+	}
+	agent := newFakeAgent(t, runner)
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForAgent(t, agent)
+	if !agent.IsRateLimited() {
+		t.Fatal("agent should be rate limited after failing on a 429")
+	}
+
+	runner.mu.Lock()
+	runner.output, runner.exitErr = nil, nil
+	runner.mu.Unlock()
+	if err := agent.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	waitForAgent(t, agent)
+
+	if got := agent.GetStatus(); got != AgentCompleted {
+		t.Fatalf("status = %s, want completed after the resume", got)
+	}
+	if agent.IsRateLimited() {
+		t.Error("a resumed agent that succeeded should no longer count as rate limited")
+	}
+}
+
+func TestAgent_StartWithRunnerFailures(t *testing.T) {
+	t.Run("process does not start", func(t *testing.T) {
+		agent := newFakeAgent(t, &fakeRunner{startErr: errors.New("executable file not found")})
+		err := agent.Start(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "starting claude: executable file not found") {
+			t.Fatalf("Start() error = %v, want the start failure", err)
+		}
+		if got := agent.GetStatus(); got != AgentQueued {
+			t.Errorf("status = %s, want the agent left queued", got)
+		}
+	})
+
+	t.Run("process reports an error", func(t *testing.T) {
+		agent := newFakeAgent(t, &fakeRunner{
+			output:  []string{`{"type":"result","is_error":true,"result":"API Error: 500 Internal server error"}`},
+			exitErr: errors.New("exit status 1"),
+		})
+		if err := agent.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		waitForAgent(t, agent)
+		if got := agent.GetStatus(); got != AgentFailed {
+			t.Errorf("status = %s, want failed", got)
+		}
+		if err := agent.GetError(); err == nil || !strings.Contains(err.Error(), "API Error: 500") {
+			t.Errorf("error = %v, want the error from the output", err)
+		}
+	})
+
+	t.Run("process is stopped", func(t *testing.T) {
+		agent := newFakeAgent(t, &fakeRunner{block: true})
+		if err := agent.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		if !agent.Stop() {
+			t.Fatal("Stop() = false, want the running agent stopped")
+		}
+		waitForAgent(t, agent)
+		if got := agent.GetStatus(); got != AgentCancelled {
+			t.Errorf("status = %s, want cancelled", got)
+		}
+	})
+}
+
+func TestAgent_StopGracefully(t *testing.T) {
+	start := func(t *testing.T, runner *fakeRunner, grace time.Duration) (*Agent, *fakeProcess) {
+		t.Helper()
+		agent := newFakeAgent(t, runner)
+		agent.StopGrace = grace
+		if err := agent.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		agent.mu.Lock()
+		defer agent.mu.Unlock()
+		return agent, agent.proc.(*fakeProcess)
+	}
+
+	t.Run("process exits on SIGTERM", func(t *testing.T) {
+		agent, proc := start(t, &fakeRunner{block: true, exitOnTerm: true}, time.Minute)
+		if !agent.Stop() {
+			t.Fatal("Stop() = false, want the running agent stopped")
+		}
+		waitForAgent(t, agent) // Well before the grace period ends
+		if got := proc.received(); !slices.Equal(got, []os.Signal{syscall.SIGTERM}) {
+			t.Errorf("signals = %v, want SIGTERM", got)
+		}
+		if got := agent.GetStatus(); got != AgentCancelled {
+			t.Errorf("status = %s, want cancelled", got)
+		}
+	})
+
+	t.Run("process exits cleanly on SIGTERM", func(t *testing.T) {
+		agent, _ := start(t, &fakeRunner{block: true, exitOnTerm: true, termExitsCleanly: true}, time.Minute)
+		if !agent.Stop() {
+			t.Fatal("Stop() = false, want the running agent stopped")
+		}
+		waitForAgent(t, agent)
+		if got := agent.GetStatus(); got != AgentCancelled {
+			t.Errorf("status = %s, want cancelled despite exit status 0", got)
+		}
+	})
+
+	t.Run("process ignoring SIGTERM is killed", func(t *testing.T) {
+		agent, proc := start(t, &fakeRunner{block: true}, 50*time.Millisecond)
+		stopped := time.Now()
+		if !agent.Stop() {
+			t.Fatal("Stop() = false, want the running agent stopped")
+		}
+		if time.Since(stopped) >= 50*time.Millisecond {
+			t.Error("Stop() waited for the grace period, want it to return right away")
+		}
+		waitForAgent(t, agent)
+		if time.Since(stopped) < 50*time.Millisecond {
+			t.Error("process killed before the grace period ended")
+		}
+		if got := proc.received(); !slices.Equal(got, []os.Signal{syscall.SIGTERM}) {
+			t.Errorf("signals = %v, want SIGTERM", got)
+		}
+		if got := agent.GetStatus(); got != AgentCancelled {
+			t.Errorf("status = %s, want cancelled", got)
+		}
+	})
+
+	t.Run("no grace period", func(t *testing.T) {
+		agent, proc := start(t, &fakeRunner{block: true}, 0)
+		agent.Stop()
+		waitForAgent(t, agent)
+		if got := proc.received(); len(got) != 0 {
+			t.Errorf("signals = %v, want the process killed without SIGTERM", got)
+		}
+	})
+
+	t.Run("watchdog", func(t *testing.T) {
+		runner := &fakeRunner{block: true, exitOnTerm: true}
+		agent := newFakeAgent(t, runner)
+		agent.StopGrace = time.Minute
+		agent.MaxDuration = 10 * time.Millisecond
+		if err := agent.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		waitForAgent(t, agent)
+		agent.mu.Lock()
+		proc := agent.proc.(*fakeProcess)
+		agent.mu.Unlock()
+		if got := proc.received(); !slices.Equal(got, []os.Signal{syscall.SIGTERM}) {
+			t.Errorf("signals = %v, want SIGTERM", got)
+		}
+		if got := agent.GetStatus(); got != AgentFailed {
+			t.Errorf("status = %s, want failed as timed out", got)
+		}
+	})
+
+	t.Run("watchdog with a clean exit on SIGTERM", func(t *testing.T) {
+		agent := newFakeAgent(t, &fakeRunner{block: true, exitOnTerm: true, termExitsCleanly: true})
+		agent.StopGrace = time.Minute
+		agent.MaxDuration = 10 * time.Millisecond
+		if err := agent.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		waitForAgent(t, agent)
+		if got := agent.GetStatus(); got != AgentFailed {
+			t.Errorf("status = %s, want failed as timed out", got)
+		}
+		if err := agent.GetError(); err == nil || !strings.HasPrefix(err.Error(), "timed out after") {
+			t.Errorf("error = %v, want the timeout", err)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Sandbox runs agents inside a container instead of directly on the host.
+// The worktree is mounted at its host path, so paths in prompts and
+// generated configs stay valid inside the container. So is the repository's
+// .git directory, which the worktree's .git file points into.
+type Sandbox struct {
+	Runtime string   // Container runtime binary: "docker" (default) or "podman"
+	Image   string   // Image that provides the executor binary
+	Flags   []string // Extra flags for "<runtime> run"
+}
+
+// invalidContainerNameChars matches characters docker and podman reject in container names
+var invalidContainerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// containerName derives a container name from an agent ID
+func containerName(agentID string) string {
+	return "claude-orch-" + invalidContainerNameChars.ReplaceAllString(agentID, "-")
+}
+
+// wrap rewrites cmd to run in a container named name. The worktree (cmd.Dir),
+// its git directory and any extra read-only mounts are mounted at their host
+// paths, and
+// environment variables cmd sets on top of the host environment are passed in.
+// Cancelling ctx kills the container, not just the runtime client.
+func (s *Sandbox) wrap(ctx context.Context, cmd *exec.Cmd, name string, readOnlyMounts ...string) *exec.Cmd {
+	runtime := s.Runtime
+	if runtime == "" {
+		runtime = "docker"
+	}
+
+	args := []string{"run", "--rm", "--name", name}
+	if cmd.Dir != "" {
+		args = append(args, "-v", cmd.Dir+":"+cmd.Dir, "-w", cmd.Dir)
+		// Agents commit and push, so git needs the objects and refs too
+		if gitDir := gitCommonDir(cmd.Dir); gitDir != "" && !within(gitDir, cmd.Dir) {
+			args = append(args, "-v", gitDir+":"+gitDir)
+		}
+	}
+	for _, path := range readOnlyMounts {
+		args = append(args, "-v", path+":"+path+":ro")
+	}
+	for _, kv := range addedEnv(cmd.Env) {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, s.Flags...)
+	args = append(args, s.Image)
+	args = append(args, cmd.Args...)
+
+	wrapped := exec.CommandContext(ctx, runtime, args...)
+	wrapped.Cancel = func() error {
+		exec.Command(runtime, "kill", name).Run() // Ignore error - container may already be gone
+		return wrapped.Process.Kill()
+	}
+	return wrapped
+}
+
+// gitCommonDir returns the absolute path of the .git directory shared by all
+// worktrees of the repository dir belongs to, "" if dir is not in one
+func gitCommonDir(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// addedEnv returns the entries of env that are not part of the host environment
+func addedEnv(env []string) []string {
+	host := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		host[kv] = true
+	}
+	var added []string
+	for _, kv := range env {
+		if !host[kv] {
+			added = append(added, kv)
+		}
+	}
+	return added
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// fakeRuntime is a container runtime that runs the command after the image on
+// the host. Like a container, it fails git unless the repository's .git
+// directory is mounted writable.
+const fakeRuntime = `#!/bin/sh
+shift # run
+writable="" workdir=""
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-v) case "$2" in *:ro) ;; *) writable="$writable ${2%%:*}" ;; esac; shift 2 ;;
+	-w) workdir="$2"; shift 2 ;;
+	-e) export "$2"; shift 2 ;;
+	--name) shift 2 ;;
+	agents:latest) shift; break ;;
+	*) shift ;;
+	esac
+done
+cd "$workdir" || exit 1
+common=$(git rev-parse --git-common-dir) || exit 1
+case "$common" in /*) ;; *) common="$workdir/$common" ;; esac
+for dir in $writable; do
+	case "$common/" in "$dir"/*) exec "$@" ;; esac
+done
+echo "fatal: $common is not mounted" >&2
+exit 128
+`
+
+func TestSandbox_GitInWorktree(t *testing.T) {
+	runtime := filepath.Join(t.TempDir(), "docker")
+	if err := os.WriteFile(runtime, []byte(fakeRuntime), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repoDir := setupGitRepo(t)
+	wtPath, err := NewWorktreeManager(repoDir, t.TempDir()).Create(domain.TaskID{Module: "tech", EpicNum: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", "from the sandbox")
+	cmd.Dir = wtPath
+	sandbox := &Sandbox{Runtime: runtime, Image: "agents:latest"}
+	wrapped := sandbox.wrap(context.Background(), cmd, "test")
+	if out, err := wrapped.CombinedOutput(); err != nil {
+		t.Fatalf("git commit in the container: %v: %s", err, out)
+	}
+
+	log := exec.Command("git", "log", "-1", "--format=%s", "feat/tech-E01")
+	log.Dir = repoDir
+	out, err := log.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "from the sandbox" {
+		t.Errorf("task branch head = %q, want the commit made in the container", got)
+	}
+}
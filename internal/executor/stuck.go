@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// StuckCheckInterval is how often WatchStuck should look for stuck agents
+const StuckCheckInterval = 30 * time.Second
+
+// toolCallMessage is the part of a stream-json line that tells tool calls
+// and their results apart
+type toolCallMessage struct {
+	Message struct {
+		Content []struct {
+			Type string `json:"type"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// trackOutputLocked records that a line of output arrived. An agent flagged
+// as stuck goes back to running. Must be called with a.mu held.
+func (a *Agent) trackOutputLocked(line string) {
+	a.lastOutputAt = time.Now()
+	if a.Status == AgentStuck {
+		a.Status = AgentRunning
+	}
+
+	// Cheap check first: most lines are neither tool calls nor results
+	if !strings.HasPrefix(line, "{") || !strings.Contains(line, `"tool_`) {
+		return
+	}
+	var msg toolCallMessage
+	if err := json.Unmarshal([]byte(line), &msg); err != nil {
+		return
+	}
+	for _, c := range msg.Message.Content {
+		switch c.Type {
+		case "tool_use":
+			a.pendingToolCalls++
+		case "tool_result":
+			if a.pendingToolCalls > 0 {
+				a.pendingToolCalls--
+			}
+		}
+	}
+}
+
+// IdleFor returns how long a running agent has gone without output at now,
+// counting from its start until the first line. It is 0 for agents that are
+// not running.
+func (a *Agent) IdleFor(now time.Time) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.idleForLocked(now)
+}
+
+// idleForLocked is IdleFor with a.mu held
+func (a *Agent) idleForLocked(now time.Time) time.Duration {
+	if !a.Status.Active() {
+		return 0
+	}
+	last := a.lastOutputAt
+	if last.IsZero() {
+		if a.StartedAt == nil {
+			return 0
+		}
+		last = *a.StartedAt
+	}
+	if idle := now.Sub(last); idle > 0 {
+		return idle
+	}
+	return 0
+}
+
+// toolCallIdleFactor stretches the idle limit of agents waiting for a tool
+// call to return, such as a long build, which are silent while it runs
+const toolCallIdleFactor = 6
+
+// markStuck flags the agent as stuck if it is running and has been silent
+// for longer than idleLimit at now, and reports whether it did. Agents
+// waiting for a tool call get toolCallIdleFactor times as long, so a hung
+// tool call is still caught.
+func (a *Agent) markStuck(now time.Time, idleLimit time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Status != AgentRunning {
+		return false
+	}
+	if a.pendingToolCalls > 0 {
+		idleLimit *= toolCallIdleFactor
+	}
+	if a.idleForLocked(now) <= idleLimit {
+		return false
+	}
+	a.Status = AgentStuck
+	return true
+}
+
+// MarkStuck flags running agents without output for longer than idleLimit
+// as stuck and returns them. The stuck status is not persisted: a stuck
+// agent is still running, and goes back to running with its next line of
+// output.
+func (m *AgentManager) MarkStuck(now time.Time, idleLimit time.Duration) []*Agent {
+	var stuck []*Agent
+	for _, agent := range m.GetAll() {
+		if agent.markStuck(now, idleLimit) {
+			stuck = append(stuck, agent)
+		}
+	}
+	return stuck
+}
+
+// WatchStuck calls MarkStuck every interval until ctx is done
+func (m *AgentManager) WatchStuck(ctx context.Context, interval, idleLimit time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.MarkStuck(now, idleLimit)
+		}
+	}
+}
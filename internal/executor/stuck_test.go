@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+func TestAgentManager_MarkStuck(t *testing.T) {
+	started := time.Now().Add(-time.Hour)
+	silent := &Agent{TaskID: domain.TaskID{Module: "mod", EpicNum: 1}, Status: AgentRunning, StartedAt: &started}
+	building := &Agent{TaskID: domain.TaskID{Module: "mod", EpicNum: 2}, Status: AgentRunning, StartedAt: &started}
+	done := &Agent{TaskID: domain.TaskID{Module: "mod", EpicNum: 3}, Status: AgentCompleted, StartedAt: &started}
+
+	// A tool call without a result yet: the agent is waiting on a long build
+	building.AppendOutput(`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash"}]}}`)
+
+	mgr := NewAgentManager(3)
+	for _, a := range []*Agent{silent, building, done} {
+		mgr.Add(a)
+	}
+
+	now := time.Now().Add(10 * time.Minute)
+	stuck := mgr.MarkStuck(now, 5*time.Minute)
+	if len(stuck) != 1 || stuck[0] != silent {
+		t.Fatalf("MarkStuck() = %v, want only the silent agent", stuck)
+	}
+	if silent.GetStatus() != AgentStuck {
+		t.Errorf("silent agent status = %s, want stuck", silent.GetStatus())
+	}
+	if building.GetStatus() != AgentRunning || done.GetStatus() != AgentCompleted {
+		t.Errorf("statuses = %s, %s; want running and completed kept", building.GetStatus(), done.GetStatus())
+	}
+	if got := silent.IdleFor(started.Add(time.Hour)); got != time.Hour {
+		t.Errorf("IdleFor() = %s, want the hour since start", got)
+	}
+	if mgr.RunningCount() != 2 {
+		t.Errorf("RunningCount() = %d, want the stuck agent counted", mgr.RunningCount())
+	}
+
+	// A tool call that hangs is flagged once the longer limit is over
+	if stuck := mgr.MarkStuck(time.Now().Add(toolCallIdleFactor*5*time.Minute+time.Minute), 5*time.Minute); len(stuck) != 1 || stuck[0] != building {
+		t.Fatalf("MarkStuck() with a hung tool call = %v, want the building agent", stuck)
+	}
+	building.AppendOutput(`{"type":"assistant","message":{"content":[{"type":"text","text":"still building"}]}}`)
+
+	// Once the tool returns, silence counts again
+	building.AppendOutput(`{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}`)
+	if stuck := mgr.MarkStuck(time.Now().Add(10*time.Minute), 5*time.Minute); len(stuck) != 1 || stuck[0] != building {
+		t.Errorf("MarkStuck() after tool result = %v, want the building agent", stuck)
+	}
+
+	// New output brings a stuck agent back
+	silent.AppendOutput("still here")
+	if silent.GetStatus() != AgentRunning {
+		t.Errorf("status after output = %s, want running", silent.GetStatus())
+	}
+	if got := silent.IdleFor(time.Now()); got > time.Minute {
+		t.Errorf("IdleFor() after output = %s, want it reset", got)
+	}
+}
@@ -3,58 +3,175 @@ package executor
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 )
 
+// ErrLowDiskSpace is returned by Create when no worktree directory has the
+// configured minimum of free space
+var ErrLowDiskSpace = errors.New("not enough free disk space for worktree")
+
+// ErrBranchExists is returned by Create under BranchPolicyError when the
+// task's branch is left over from an earlier run
+var ErrBranchExists = errors.New("task branch already exists")
+
+// BranchPolicy decides what Create does when the task's branch already exists
+type BranchPolicy string
+
+const (
+	// BranchPolicyRecreate deletes the branch and any worktree on it and
+	// starts over from the base branch. Commits on the old branch are lost.
+	BranchPolicyRecreate BranchPolicy = "recreate"
+	// BranchPolicyReuse continues on the existing branch, in its worktree
+	// if one is still checked out
+	BranchPolicyReuse BranchPolicy = "reuse"
+	// BranchPolicyError fails with ErrBranchExists
+	BranchPolicyError BranchPolicy = "error"
+)
+
+// ParseBranchPolicy parses a policy name; empty means BranchPolicyRecreate
+func ParseBranchPolicy(s string) (BranchPolicy, error) {
+	switch p := BranchPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return BranchPolicyRecreate, nil
+	case BranchPolicyRecreate, BranchPolicyReuse, BranchPolicyError:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid existing branch policy %q (use recreate, reuse or error)", s)
+}
+
 // WorktreeManager handles git worktree operations
 type WorktreeManager struct {
-	repoDir     string
-	worktreeDir string
+	repoDir      string
+	worktreeDir  string
+	worktreeDirs []string                          // Candidate directories, the one with most free space is used
+	minFree      uint64                            // Minimum free bytes required to create a worktree (0 = no check)
+	freeSpace    func(path string) (uint64, error) // Reports free bytes on path's filesystem
+	branchPolicy BranchPolicy                      // What Create does with an existing task branch
+	mu           sync.Mutex                        // Serializes Create so attempt numbers are unique
+	attempts     map[string]int                    // Last attempt number handed out per task
 }
 
 // NewWorktreeManager creates a new WorktreeManager
 func NewWorktreeManager(repoDir, worktreeDir string) *WorktreeManager {
 	return &WorktreeManager{
-		repoDir:     repoDir,
-		worktreeDir: worktreeDir,
+		repoDir:      repoDir,
+		worktreeDir:  worktreeDir,
+		freeSpace:    dirFreeBytes,
+		branchPolicy: BranchPolicyRecreate,
+		attempts:     make(map[string]int),
 	}
 }
 
+// SetWorktreeDirs sets candidate worktree directories. Create places each
+// worktree in the candidate with the most free space instead of the
+// directory passed to NewWorktreeManager.
+func (m *WorktreeManager) SetWorktreeDirs(dirs []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.worktreeDirs = dirs
+}
+
+// SetMinFreeSpace makes Create fail with ErrLowDiskSpace when the chosen
+// directory has less than minBytes free (0 disables the check)
+func (m *WorktreeManager) SetMinFreeSpace(minBytes uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.minFree = minBytes
+}
+
+// SetBranchPolicy sets what Create does when the task's branch already
+// exists, e.g. after a crash or restart
+func (m *WorktreeManager) SetBranchPolicy(policy BranchPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.branchPolicy = policy
+}
+
 // Create creates a new worktree for a task
-// If an existing worktree or branch exists for this task, it will be cleaned up first
+// If the task's branch already exists, the branch policy decides: recreate
+// cleans up the branch and its worktree first, reuse continues on the branch,
+// error fails with ErrBranchExists.
+// Each call gets its own directory named <module>-<epic>-r<attempt>-<random>, so
+// a retry never reuses the path of a previous attempt that is still being cleaned up.
 func (m *WorktreeManager) Create(taskID domain.TaskID) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Pick the worktree directory and fail early if its disk is nearly full
+	worktreeDir, err := m.selectWorktreeDir()
+	if err != nil {
+		return "", err
+	}
+
 	// Ensure worktree directory exists
-	if err := os.MkdirAll(m.worktreeDir, 0755); err != nil {
+	if err := os.MkdirAll(worktreeDir, 0755); err != nil {
 		return "", fmt.Errorf("creating worktree dir: %w", err)
 	}
 
-	// Branch name
-	branch := BranchName(taskID)
+	// Number this attempt before cleanup removes the previous attempt's directory
+	base := worktreeBaseName(taskID)
+	attempt := m.nextAttempt(base)
+
+	// Branch name, and whether it is left over from an earlier run
+	branch, exists := m.TaskBranch(taskID)
 
-	// Check if branch already exists (either as worktree or just branch)
-	// If so, clean it up first
-	if err := m.cleanupExistingBranch(branch); err != nil {
-		return "", fmt.Errorf("cleaning up existing branch: %w", err)
+	reuse := false
+	switch m.branchPolicy {
+	case BranchPolicyError:
+		if exists {
+			return "", fmt.Errorf("%s: %w (set existing_branch to reuse or recreate)", branch, ErrBranchExists)
+		}
+	case BranchPolicyReuse:
+		if exists {
+			// Drop entries for deleted worktrees, which would otherwise
+			// keep the branch checked out and make worktree add fail
+			pruneCmd := exec.Command("git", "worktree", "prune")
+			pruneCmd.Dir = m.repoDir
+			pruneCmd.Run()
+
+			// Keep working in a worktree that still has the branch checked out
+			if existing := m.worktreeForBranch(branch); existing != "" {
+				if _, err := os.Stat(existing); err == nil {
+					m.recordBranchTask(branch, taskID)
+					return existing, nil
+				}
+			}
+			reuse = true
+		}
+	default:
+		if err := m.cleanupExistingBranch(branch); err != nil {
+			return "", fmt.Errorf("cleaning up existing branch: %w", err)
+		}
 	}
 
-	// Generate unique suffix
-	suffix := randomSuffix()
+	// Worktree path - attempt number plus random suffix keeps retries apart
+	var wtPath string
+	for {
+		dirName := fmt.Sprintf("%s-r%d-%s", base, attempt, randomSuffix())
+		wtPath = filepath.Join(worktreeDir, dirName)
+		if _, err := os.Stat(wtPath); os.IsNotExist(err) {
+			break
+		}
+	}
 
-	// Worktree path - include prefix if present (e.g., CLI02 vs E02)
-	var epicPart string
-	if taskID.Prefix != "" {
-		epicPart = fmt.Sprintf("%s%02d", taskID.Prefix, taskID.EpicNum)
-	} else {
-		epicPart = fmt.Sprintf("E%02d", taskID.EpicNum)
+	if reuse {
+		cmd := exec.Command("git", "worktree", "add", wtPath, branch)
+		cmd.Dir = m.repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git worktree add: %s: %w", out, err)
+		}
+		m.recordBranchTask(branch, taskID)
+		return wtPath, nil
 	}
-	dirName := fmt.Sprintf("%s-%s-%s", taskID.Module, epicPart, suffix)
-	wtPath := filepath.Join(m.worktreeDir, dirName)
 
 	// Fetch latest from origin first (if remote exists)
 	fetchCmd := exec.Command("git", "fetch", "origin", "main")
@@ -75,10 +192,105 @@ func (m *WorktreeManager) Create(taskID domain.TaskID) (string, error) {
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("git worktree add: %s: %w", out, err)
 	}
+	m.recordBranchTask(branch, taskID)
 
 	return wtPath, nil
 }
 
+// dirs returns the directories worktrees may be placed in
+func (m *WorktreeManager) dirs() []string {
+	if len(m.worktreeDirs) > 0 {
+		return m.worktreeDirs
+	}
+	return []string{m.worktreeDir}
+}
+
+// selectWorktreeDir returns the directory for a new worktree and checks that
+// it has at least the minimum free space. Must be called with mu held.
+func (m *WorktreeManager) selectWorktreeDir() (string, error) {
+	dir, free, known := selectByFreeSpace(m.dirs(), m.freeSpace)
+	if known && m.minFree > 0 && free < m.minFree {
+		return "", fmt.Errorf("%w: %s has %s free, need %s",
+			ErrLowDiskSpace, dir, formatBytes(int64(free)), formatBytes(int64(m.minFree)))
+	}
+	return dir, nil
+}
+
+// selectByFreeSpace returns the directory with the most free space.
+// Directories whose free space cannot be determined are skipped; if none
+// can be measured, the first directory is returned with known = false.
+func selectByFreeSpace(dirs []string, freeSpace func(string) (uint64, error)) (dir string, free uint64, known bool) {
+	if len(dirs) == 0 {
+		return "", 0, false
+	}
+
+	dir = dirs[0]
+	for _, candidate := range dirs {
+		f, err := freeSpace(candidate)
+		if err != nil {
+			continue
+		}
+		if !known || f > free {
+			dir, free, known = candidate, f, true
+		}
+	}
+	return dir, free, known
+}
+
+// dirFreeBytes reports the free space for dir, measured on its closest
+// existing parent so it works before the directory is created
+func dirFreeBytes(dir string) (uint64, error) {
+	return diskFreeBytes(existingAncestor(dir))
+}
+
+// existingAncestor returns path or its closest existing parent
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// worktreeBaseName returns the task part of a worktree directory name,
+// including the prefix if present (e.g., module-CLI02 vs module-E02)
+func worktreeBaseName(taskID domain.TaskID) string {
+	if taskID.Prefix != "" {
+		return fmt.Sprintf("%s-%s%02d", taskID.Module, taskID.Prefix, taskID.EpicNum)
+	}
+	return fmt.Sprintf("%s-E%02d", taskID.Module, taskID.EpicNum)
+}
+
+// nextAttempt returns one more than the highest attempt number seen for the
+// given base name, either on disk or handed out earlier by this manager.
+// Must be called with mu held.
+func (m *WorktreeManager) nextAttempt(base string) int {
+	highest := m.attempts[base]
+
+	prefix := base + "-r"
+	for _, dir := range m.dirs() {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			rest, ok := strings.CutPrefix(e.Name(), prefix)
+			if !ok {
+				continue
+			}
+			numStr, _, _ := strings.Cut(rest, "-")
+			if n, err := strconv.Atoi(numStr); err == nil && n > highest {
+				highest = n
+			}
+		}
+	}
+
+	m.attempts[base] = highest + 1
+	return highest + 1
+}
+
 // cleanupExistingBranch removes any existing worktree and branch for the given branch name
 func (m *WorktreeManager) cleanupExistingBranch(branch string) error {
 	// Prune any stale worktree entries first
@@ -86,8 +298,26 @@ func (m *WorktreeManager) cleanupExistingBranch(branch string) error {
 	cmd.Dir = m.repoDir
 	cmd.Run()
 
-	// Check if there's a worktree using this branch
-	cmd = exec.Command("git", "worktree", "list", "--porcelain")
+	// Remove the worktree using this branch, if any
+	if wtPath := m.worktreeForBranch(branch); wtPath != "" {
+		rmCmd := exec.Command("git", "worktree", "remove", "--force", wtPath)
+		rmCmd.Dir = m.repoDir
+		rmCmd.Run() // Ignore error
+	}
+
+	// Always try to delete the branch (even if no worktree found)
+	// This handles orphan branches from previous runs
+	cmd = exec.Command("git", "branch", "-D", branch)
+	cmd.Dir = m.repoDir
+	cmd.Run() // Ignore error - branch might not exist
+
+	return nil
+}
+
+// worktreeForBranch returns the path of the worktree that has branch
+// checked out, or "" if there is none
+func (m *WorktreeManager) worktreeForBranch(branch string) string {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
 	cmd.Dir = m.repoDir
 	out, _ := cmd.Output()
 
@@ -95,27 +325,15 @@ func (m *WorktreeManager) cleanupExistingBranch(branch string) error {
 	lines := strings.Split(string(out), "\n")
 	for i, line := range lines {
 		if strings.HasPrefix(line, "worktree ") {
-			wtPath := strings.TrimPrefix(line, "worktree ")
 			// Check if this worktree has our branch (branch line is 2 lines after worktree)
 			for j := i + 1; j < len(lines) && j < i+4; j++ {
 				if strings.TrimSpace(lines[j]) == "branch refs/heads/"+branch {
-					// Found worktree with our branch - remove it
-					rmCmd := exec.Command("git", "worktree", "remove", "--force", wtPath)
-					rmCmd.Dir = m.repoDir
-					rmCmd.Run() // Ignore error
-					break
+					return strings.TrimPrefix(line, "worktree ")
 				}
 			}
 		}
 	}
-
-	// Always try to delete the branch (even if no worktree found)
-	// This handles orphan branches from previous runs
-	cmd = exec.Command("git", "branch", "-D", branch)
-	cmd.Dir = m.repoDir
-	cmd.Run() // Ignore error - branch might not exist
-
-	return nil
+	return ""
 }
 
 // Remove removes a worktree
@@ -156,9 +374,12 @@ func (m *WorktreeManager) List() ([]string, error) {
 	for _, line := range strings.Split(string(out), "\n") {
 		if strings.HasPrefix(line, "worktree ") {
 			path := strings.TrimPrefix(line, "worktree ")
-			// Only include worktrees in our worktree directory
-			if strings.HasPrefix(path, m.worktreeDir) {
-				paths = append(paths, path)
+			// Only include worktrees in our worktree directories
+			for _, dir := range m.dirs() {
+				if strings.HasPrefix(path, dir) {
+					paths = append(paths, path)
+					break
+				}
 			}
 		}
 	}
@@ -166,13 +387,62 @@ func (m *WorktreeManager) List() ([]string, error) {
 	return paths, nil
 }
 
+// branchPrefix starts the name of every task branch
+const branchPrefix = "feat/"
+
+// branchTaskKey is the git config variable, under branch.<name>, recording the
+// task a branch was created for. git branch -D removes it with the branch.
+const branchTaskKey = "orchestrator-task"
+
 // BranchName returns the branch name for a task
 func BranchName(taskID domain.TaskID) string {
 	// Include prefix if present (e.g., feat/module-CLI02 vs feat/module-E02)
 	if taskID.Prefix != "" {
-		return fmt.Sprintf("feat/%s-%s%02d", taskID.Module, taskID.Prefix, taskID.EpicNum)
+		return fmt.Sprintf("%s%s-%s%02d", branchPrefix, taskID.Module, taskID.Prefix, taskID.EpicNum)
+	}
+	return fmt.Sprintf("%s%s-E%02d", branchPrefix, taskID.Module, taskID.EpicNum)
+}
+
+// TaskForBranch returns the task whose BranchName is branch
+func TaskForBranch(branch string) (domain.TaskID, error) {
+	name, ok := strings.CutPrefix(branch, branchPrefix)
+	i := strings.LastIndex(name, "-")
+	if !ok || i <= 0 {
+		return domain.TaskID{}, fmt.Errorf("%q is not a task branch", branch)
 	}
-	return fmt.Sprintf("feat/%s-E%02d", taskID.Module, taskID.EpicNum)
+	taskID, err := domain.ParseTaskID(name[:i] + "/" + name[i+1:])
+	if err != nil || BranchName(taskID) != branch {
+		return domain.TaskID{}, fmt.Errorf("%q is not a task branch", branch)
+	}
+	return taskID, nil
+}
+
+// recordBranchTask records in the repo's git config which task branch was
+// created for. Failures are ignored since the branch name alone still
+// identifies the task.
+func (m *WorktreeManager) recordBranchTask(branch string, taskID domain.TaskID) {
+	cmd := exec.Command("git", "config", "branch."+branch+"."+branchTaskKey, taskID.String())
+	cmd.Dir = m.repoDir
+	cmd.Run()
+}
+
+// BranchTask returns the task a branch of the repo belongs to: the task
+// recorded when the branch was created, or else the task it is named after
+func (m *WorktreeManager) BranchTask(branch string) (domain.TaskID, error) {
+	cmd := exec.Command("git", "config", "--get", "branch."+branch+"."+branchTaskKey)
+	cmd.Dir = m.repoDir
+	if out, err := cmd.Output(); err == nil {
+		if taskID, err := domain.ParseTaskID(strings.TrimSpace(string(out))); err == nil {
+			return taskID, nil
+		}
+	}
+	return TaskForBranch(branch)
+}
+
+// TaskBranch returns the branch of a task and whether it exists in the repo
+func (m *WorktreeManager) TaskBranch(taskID domain.TaskID) (string, bool) {
+	branch := BranchName(taskID)
+	return branch, m.branchExists(branch)
 }
 
 func randomSuffix() string {
@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// WorktreeProblem is an inconsistency between a worktree and its task branch
+type WorktreeProblem string
+
+const (
+	ProblemMissingDir    WorktreeProblem = "missing directory"
+	ProblemDetachedHead  WorktreeProblem = "detached HEAD"
+	ProblemMissingBranch WorktreeProblem = "missing branch"
+	ProblemWrongBranch   WorktreeProblem = "wrong branch"
+	ProblemUncommitted   WorktreeProblem = "uncommitted changes"
+)
+
+// WorktreeCheck is the result of checking a single worktree
+type WorktreeCheck struct {
+	Path           string
+	Branch         string // Checked-out branch ("" if HEAD is detached)
+	ExpectedBranch string // Task branch derived from the directory name ("" if unknown)
+	Active         bool   // Worktree belongs to a running task
+	Problems       []WorktreeProblem
+}
+
+// OK reports whether no problems were found
+func (c WorktreeCheck) OK() bool {
+	return len(c.Problems) == 0
+}
+
+// Has reports whether the check found the given problem
+func (c WorktreeCheck) Has(problem WorktreeProblem) bool {
+	for _, p := range c.Problems {
+		if p == problem {
+			return true
+		}
+	}
+	return false
+}
+
+// worktreeDirPattern matches directory names created by Create
+var worktreeDirPattern = regexp.MustCompile(`^(.+)-r\d+-[0-9a-f]+$`)
+
+// expectedBranch returns the task branch for a worktree created by Create,
+// or "" if the directory name does not follow the naming scheme
+func expectedBranch(wtPath string) string {
+	match := worktreeDirPattern.FindStringSubmatch(filepath.Base(wtPath))
+	if match == nil {
+		return ""
+	}
+	return branchPrefix + match[1]
+}
+
+// Check inspects all worktrees returned by List for a detached HEAD, a
+// deleted or unexpected branch and, for worktrees not in active, uncommitted
+// changes
+func (m *WorktreeManager) Check(active map[string]bool) ([]WorktreeCheck, error) {
+	paths, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]WorktreeCheck, 0, len(paths))
+	for _, path := range paths {
+		checks = append(checks, m.checkWorktree(path, active[path]))
+	}
+	return checks, nil
+}
+
+func (m *WorktreeManager) checkWorktree(wtPath string, active bool) WorktreeCheck {
+	check := WorktreeCheck{
+		Path:           wtPath,
+		ExpectedBranch: expectedBranch(wtPath),
+		Active:         active,
+	}
+
+	if _, err := os.Stat(wtPath); err != nil {
+		check.Problems = append(check.Problems, ProblemMissingDir)
+		return check
+	}
+
+	cmd := exec.Command("git", "symbolic-ref", "-q", "--short", "HEAD")
+	cmd.Dir = wtPath
+	out, err := cmd.Output()
+	if err != nil {
+		check.Problems = append(check.Problems, ProblemDetachedHead)
+	} else {
+		check.Branch = strings.TrimSpace(string(out))
+		if !m.branchExists(check.Branch) {
+			// Without a commit to compare against, uncommitted changes are meaningless
+			check.Problems = append(check.Problems, ProblemMissingBranch)
+			return check
+		}
+		if check.ExpectedBranch != "" && check.Branch != check.ExpectedBranch {
+			check.Problems = append(check.Problems, ProblemWrongBranch)
+		}
+	}
+
+	// Running agents have uncommitted changes by design
+	if !active && hasUncommittedChanges(wtPath) {
+		check.Problems = append(check.Problems, ProblemUncommitted)
+	}
+
+	return check
+}
+
+// Repair fixes the problems of a checked worktree and describes what it did.
+// A stale entry for a missing directory is pruned, a worktree off its task
+// branch is switched back to it (creating the branch at the current commit if
+// it is gone), and a worktree whose branch was deleted or cannot be determined
+// is removed. Uncommitted changes are never discarded, and worktrees of
+// running tasks are left alone.
+func (m *WorktreeManager) Repair(check WorktreeCheck) (string, error) {
+	if check.OK() {
+		return "", nil
+	}
+
+	if check.Has(ProblemMissingDir) {
+		cmd := exec.Command("git", "worktree", "prune")
+		cmd.Dir = m.repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git worktree prune: %s: %w", out, err)
+		}
+		return "pruned stale worktree entry", nil
+	}
+
+	if check.Active {
+		return "", errors.New("worktree belongs to a running task")
+	}
+
+	dirty := check.Has(ProblemUncommitted)
+
+	switch {
+	case check.Has(ProblemMissingBranch), check.ExpectedBranch == "" && check.Has(ProblemDetachedHead):
+		if dirty {
+			return "", errors.New("has uncommitted changes; commit or discard them first")
+		}
+		if err := m.Remove(check.Path); err != nil {
+			return "", err
+		}
+		return "removed worktree", nil
+
+	case check.Has(ProblemDetachedHead), check.Has(ProblemWrongBranch):
+		args := []string{"switch", check.ExpectedBranch}
+		action := "switched to " + check.ExpectedBranch
+		if !m.branchExists(check.ExpectedBranch) {
+			args = []string{"switch", "-c", check.ExpectedBranch}
+			action = "created " + check.ExpectedBranch + " at current commit"
+		}
+		cmd := exec.Command("git", args...)
+		cmd.Dir = check.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git switch: %s: %w", strings.TrimSpace(string(out)), err)
+		}
+		if taskID, err := m.BranchTask(check.ExpectedBranch); err == nil {
+			m.recordBranchTask(check.ExpectedBranch, taskID)
+		}
+		return action, nil
+	}
+
+	// Only uncommitted changes left
+	return "", errors.New("has uncommitted changes; commit or discard them, or remove it with 'claude-orch cleanup worktrees'")
+}
+
+// branchExists reports whether a local branch exists in the repository
+func (m *WorktreeManager) branchExists(branch string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "-q", "refs/heads/"+branch)
+	cmd.Dir = m.repoDir
+	return cmd.Run() == nil
+}
+
+// orchestratorFiles are pathspecs excluding the untracked files the
+// orchestrator itself writes into every agent worktree: the agent log and the
+// MCP configs of OpenCode and Gemini
+var orchestratorFiles = []string{":!.claude-agent.log", ":!.*-mcp.json"}
+
+// hasUncommittedChanges reports whether a worktree has staged, unstaged or
+// untracked changes, not counting the orchestrator's own files
+func hasUncommittedChanges(wtPath string) bool {
+	cmd := exec.Command("git", append([]string{"status", "--porcelain", "--", "."}, orchestratorFiles...)...)
+	cmd.Dir = wtPath
+	out, err := cmd.Output()
+	return err == nil && len(strings.TrimSpace(string(out))) > 0
+}
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %s", args, out)
+	}
+}
+
+func TestExpectedBranch(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/wt/technical-E05-r1-a1b2c3", "feat/technical-E05"},
+		{"/wt/cli-CLI02-r12-0f0f0f", "feat/cli-CLI02"},
+		{"/wt/scratch", ""},
+	}
+	for _, tt := range tests {
+		if got := expectedBranch(tt.path); got != tt.want {
+			t.Errorf("expectedBranch(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWorktreeManager_CheckAndRepair(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+
+	create := func(epic int) string {
+		t.Helper()
+		wtPath, err := mgr.Create(domain.TaskID{Module: "tech", EpicNum: epic})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wtPath
+	}
+
+	healthy := create(0)
+	detached := create(1)
+	runGit(t, detached, "checkout", "--detach")
+	wrongBranch := create(2)
+	runGit(t, wrongBranch, "switch", "-c", "scratch")
+	missingBranch := create(3)
+	runGit(t, repoDir, "update-ref", "-d", "refs/heads/feat/tech-E03")
+	dirty := create(4)
+	os.WriteFile(filepath.Join(dirty, "notes.txt"), []byte("wip"), 0644)
+	running := create(5)
+	os.WriteFile(filepath.Join(running, "notes.txt"), []byte("wip"), 0644)
+	agentFiles := create(6)
+	for _, name := range []string{".claude-agent.log", ".opencode-mcp.json", ".gemini-mcp.json"} {
+		os.WriteFile(filepath.Join(agentFiles, name), []byte("{}"), 0644)
+	}
+	missingDir := create(7)
+	os.RemoveAll(missingDir)
+
+	checks, err := mgr.Check(map[string]bool{running: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := make(map[string]WorktreeCheck)
+	for _, c := range checks {
+		byPath[c.Path] = c
+	}
+	if len(byPath) != 8 {
+		t.Fatalf("Check returned %d worktrees, want 8", len(byPath))
+	}
+
+	want := map[string][]WorktreeProblem{
+		healthy:       nil,
+		detached:      {ProblemDetachedHead},
+		wrongBranch:   {ProblemWrongBranch},
+		missingBranch: {ProblemMissingBranch},
+		dirty:         {ProblemUncommitted},
+		running:       nil, // Uncommitted changes are expected while running
+		missingDir:    {ProblemMissingDir},
+		agentFiles:    nil, // The orchestrator's own files don't count as changes
+	}
+	for path, problems := range want {
+		got := byPath[path].Problems
+		if len(got) != len(problems) {
+			t.Errorf("%s: problems = %v, want %v", filepath.Base(path), got, problems)
+			continue
+		}
+		for i := range problems {
+			if got[i] != problems[i] {
+				t.Errorf("%s: problems = %v, want %v", filepath.Base(path), got, problems)
+			}
+		}
+	}
+
+	for _, path := range []string{detached, wrongBranch, missingBranch, missingDir} {
+		if _, err := mgr.Repair(byPath[path]); err != nil {
+			t.Errorf("Repair(%s): %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := mgr.Repair(byPath[dirty]); err == nil {
+		t.Error("Repair should refuse to discard uncommitted changes")
+	}
+
+	// Repaired worktrees are consistent, removed and pruned ones are gone
+	checks, err = mgr.Check(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := make(map[string]WorktreeCheck)
+	for _, c := range checks {
+		remaining[c.Path] = c
+	}
+	for _, path := range []string{detached, wrongBranch} {
+		if c, ok := remaining[path]; !ok || !c.OK() {
+			t.Errorf("%s after repair: %+v, want consistent", filepath.Base(path), c)
+		}
+	}
+	if got, err := mgr.BranchTask(remaining[wrongBranch].Branch); err != nil || got.String() != "tech/E02" {
+		t.Errorf("BranchTask after repair = %v, %v, want tech/E02", got, err)
+	}
+	for _, path := range []string{missingBranch, missingDir} {
+		if _, ok := remaining[path]; ok {
+			t.Errorf("%s should be gone after repair", filepath.Base(path))
+		}
+	}
+	if c := remaining[dirty]; !c.Has(ProblemUncommitted) {
+		t.Errorf("dirty worktree should keep its changes, got %+v", c)
+	}
+}
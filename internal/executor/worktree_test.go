@@ -1,9 +1,11 @@
 package executor
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
@@ -68,6 +70,48 @@ func TestWorktreeManager_Create(t *testing.T) {
 	}
 }
 
+func TestWorktreeManager_CreateTwiceUsesDistinctPaths(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	worktreeDir := t.TempDir()
+
+	mgr := NewWorktreeManager(repoDir, worktreeDir)
+
+	taskID := domain.TaskID{Module: "technical", EpicNum: 5}
+	first, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatalf("both creates returned %s, want distinct paths", first)
+	}
+	if !strings.HasPrefix(filepath.Base(first), "technical-E05-r1-") {
+		t.Errorf("first path = %s, want attempt r1", first)
+	}
+	if !strings.HasPrefix(filepath.Base(second), "technical-E05-r2-") {
+		t.Errorf("second path = %s, want attempt r2", second)
+	}
+
+	// Cleanup still finds the new worktree
+	paths, err := mgr.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range paths {
+		if p == second {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("List() = %v, want it to include %s", paths, second)
+	}
+}
+
 func TestWorktreeManager_Remove(t *testing.T) {
 	repoDir := setupGitRepo(t)
 	worktreeDir := t.TempDir()
@@ -106,5 +150,314 @@ func TestWorktreeManager_BranchName(t *testing.T) {
 		if got != tt.want {
 			t.Errorf("BranchName(%v) = %q, want %q", tt.taskID, got, tt.want)
 		}
+		if taskID, err := TaskForBranch(got); err != nil || taskID != tt.taskID {
+			t.Errorf("TaskForBranch(%q) = %v, %v, want %v", got, taskID, err, tt.taskID)
+		}
+	}
+}
+
+func TestTaskForBranch_RejectsOtherBranches(t *testing.T) {
+	for _, branch := range []string{
+		"main",
+		"feat/technical",
+		"feat/-E05",
+		"fix/technical-E05",
+		"feat/technical-E5", // BranchName pads the epic number
+		"feat/Technical-E05",
+	} {
+		if taskID, err := TaskForBranch(branch); err == nil {
+			t.Errorf("TaskForBranch(%q) = %v, want an error", branch, taskID)
+		}
+	}
+}
+
+func TestWorktreeManager_BranchTaskLookup(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	taskID := domain.TaskID{Module: "cli-tui-impl", Prefix: "CLI", EpicNum: 2}
+
+	if branch, exists := mgr.TaskBranch(taskID); branch != "feat/cli-tui-impl-CLI02" || exists {
+		t.Errorf("TaskBranch before Create = %q, %v, want the name of a missing branch", branch, exists)
+	}
+
+	if _, err := mgr.Create(taskID); err != nil {
+		t.Fatal(err)
+	}
+	branch, exists := mgr.TaskBranch(taskID)
+	if !exists {
+		t.Fatalf("TaskBranch after Create: %q does not exist", branch)
+	}
+
+	// Create records the task in the branch's git config
+	cmd := exec.Command("git", "config", "--get", "branch."+branch+".orchestrator-task")
+	cmd.Dir = repoDir
+	if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "cli-tui-impl/CLI02" {
+		t.Errorf("recorded task = %q, %v, want cli-tui-impl/CLI02", out, err)
+	}
+	if got, err := mgr.BranchTask(branch); err != nil || got != taskID {
+		t.Errorf("BranchTask(%q) = %v, %v, want %v", branch, got, err, taskID)
+	}
+
+	// The recorded task wins over the branch name
+	cmd = exec.Command("git", "config", "branch."+branch+".orchestrator-task", "billing/E04")
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git config: %s", out)
+	}
+	if got, _ := mgr.BranchTask(branch); got.String() != "billing/E04" {
+		t.Errorf("BranchTask(%q) = %v, want the recorded billing/E04", branch, got)
+	}
+
+	// Branches without a record fall back to the name
+	if got, err := mgr.BranchTask("feat/zoning-E01"); err != nil || got.String() != "zoning/E01" {
+		t.Errorf("BranchTask(feat/zoning-E01) = %v, %v, want zoning/E01", got, err)
+	}
+	if _, err := mgr.BranchTask("main"); err == nil {
+		t.Error("BranchTask(main) should fail")
+	}
+}
+
+func TestSelectByFreeSpace(t *testing.T) {
+	free := map[string]uint64{
+		"/mnt/small": 1 << 30,
+		"/mnt/large": 8 << 30,
+		"/mnt/mid":   4 << 30,
+	}
+	freeSpace := func(path string) (uint64, error) {
+		if f, ok := free[path]; ok {
+			return f, nil
+		}
+		return 0, os.ErrNotExist
+	}
+
+	tests := []struct {
+		name      string
+		dirs      []string
+		wantDir   string
+		wantKnown bool
+	}{
+		{"picks most free space", []string{"/mnt/small", "/mnt/large", "/mnt/mid"}, "/mnt/large", true},
+		{"skips unmeasurable dirs", []string{"/mnt/missing", "/mnt/mid"}, "/mnt/mid", true},
+		{"falls back to first dir", []string{"/mnt/missing", "/mnt/gone"}, "/mnt/missing", false},
+		{"no dirs", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, _, known := selectByFreeSpace(tt.dirs, freeSpace)
+			if dir != tt.wantDir || known != tt.wantKnown {
+				t.Errorf("selectByFreeSpace() = %q, %v, want %q, %v", dir, known, tt.wantDir, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestWorktreeManager_CreateUsesDirWithMostFreeSpace(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	small := filepath.Join(t.TempDir(), "small")
+	large := filepath.Join(t.TempDir(), "large")
+
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	mgr.SetWorktreeDirs([]string{small, large})
+	mgr.freeSpace = func(path string) (uint64, error) {
+		if path == large {
+			return 10 << 30, nil
+		}
+		return 1 << 30, nil
+	}
+
+	wtPath, err := mgr.Create(domain.TaskID{Module: "technical", EpicNum: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(wtPath) != large {
+		t.Errorf("worktree created in %s, want %s", filepath.Dir(wtPath), large)
+	}
+}
+
+func TestWorktreeManager_CreateFailsOnLowDiskSpace(t *testing.T) {
+	repoDir := setupGitRepo(t)
+
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	mgr.SetMinFreeSpace(1 << 30)
+	mgr.freeSpace = func(string) (uint64, error) { return 100 << 20, nil }
+
+	_, err := mgr.Create(domain.TaskID{Module: "technical", EpicNum: 1})
+	if !errors.Is(err, ErrLowDiskSpace) {
+		t.Fatalf("Create() error = %v, want ErrLowDiskSpace", err)
+	}
+
+	// No branch is created when the check fails
+	cmd := exec.Command("git", "branch", "--list", "feat/technical-E01")
+	cmd.Dir = repoDir
+	if out, _ := cmd.Output(); len(out) != 0 {
+		t.Error("branch should not be created when disk space is low")
+	}
+}
+
+func TestDirFreeBytes_MissingDir(t *testing.T) {
+	// Free space of a directory that does not exist yet is measured on its parent
+	free, err := dirFreeBytes(filepath.Join(t.TempDir(), "not", "created"))
+	if err != nil {
+		t.Skipf("disk space check unavailable: %v", err)
+	}
+	if free == 0 {
+		t.Error("expected non-zero free space for temp dir")
+	}
+}
+
+// setupExistingBranch creates the task's branch with one commit of its own,
+// as left behind by an earlier run
+func setupExistingBranch(t *testing.T, repoDir string, taskID domain.TaskID) string {
+	t.Helper()
+	branch := BranchName(taskID)
+	for _, args := range [][]string{
+		{"git", "checkout", "-q", "-b", branch},
+		{"git", "commit", "-q", "--allow-empty", "-m", "Earlier work"},
+		{"git", "checkout", "-q", "-"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s", args, out)
+		}
+	}
+	return branch
+}
+
+// headSubject returns the subject of the latest commit in dir
+func headSubject(t *testing.T, dir string) string {
+	t.Helper()
+	cmd := exec.Command("git", "log", "-1", "--format=%s")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestWorktreeManager_CreateExistingBranchRecreate(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	taskID := domain.TaskID{Module: "technical", EpicNum: 5}
+	setupExistingBranch(t, repoDir, taskID)
+
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	wtPath, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headSubject(t, wtPath); got != "Initial commit" {
+		t.Errorf("worktree HEAD = %q, want a fresh branch from the base", got)
+	}
+}
+
+func TestWorktreeManager_CreateExistingBranchReuse(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	taskID := domain.TaskID{Module: "technical", EpicNum: 5}
+	branch := setupExistingBranch(t, repoDir, taskID)
+
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	mgr.SetBranchPolicy(BranchPolicyReuse)
+	first, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headSubject(t, first); got != "Earlier work" {
+		t.Errorf("worktree HEAD = %q, want the existing branch's commit", got)
+	}
+	if got := mgr.worktreeForBranch(branch); got != first {
+		t.Errorf("branch checked out in %q, want %q", got, first)
+	}
+
+	// A second create continues in the worktree that has the branch
+	second, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Errorf("second Create() = %s, want the existing worktree %s", second, first)
+	}
+
+	// Without a branch to reuse, a new one is created as usual
+	other, err := mgr.Create(domain.TaskID{Module: "technical", EpicNum: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headSubject(t, other); got != "Initial commit" {
+		t.Errorf("new task HEAD = %q, want the base commit", got)
+	}
+}
+
+func TestWorktreeManager_CreateExistingBranchReuseAfterRemovedWorktree(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	taskID := domain.TaskID{Module: "technical", EpicNum: 5}
+	setupExistingBranch(t, repoDir, taskID)
+
+	mgr := NewWorktreeManager(repoDir, t.TempDir())
+	mgr.SetBranchPolicy(BranchPolicyReuse)
+	first, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old worktree directory is deleted without git worktree remove
+	if err := os.RemoveAll(first); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := mgr.Create(taskID)
+	if err != nil {
+		t.Fatalf("Create() after removing the old worktree: %v", err)
+	}
+	if second == first {
+		t.Errorf("Create() = %s, want a new worktree", second)
+	}
+	if got := headSubject(t, second); got != "Earlier work" {
+		t.Errorf("worktree HEAD = %q, want the existing branch's commit", got)
+	}
+}
+
+func TestWorktreeManager_CreateExistingBranchError(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	taskID := domain.TaskID{Module: "technical", EpicNum: 5}
+	branch := setupExistingBranch(t, repoDir, taskID)
+
+	worktreeDir := t.TempDir()
+	mgr := NewWorktreeManager(repoDir, worktreeDir)
+	mgr.SetBranchPolicy(BranchPolicyError)
+	if _, err := mgr.Create(taskID); !errors.Is(err, ErrBranchExists) {
+		t.Fatalf("Create() error = %v, want ErrBranchExists", err)
+	}
+
+	// The branch and its commit are left alone
+	if got := headSubject(t, repoDir); got != "Initial commit" {
+		t.Errorf("repo HEAD moved to %q", got)
+	}
+	cmd := exec.Command("git", "log", "-1", "--format=%s", branch)
+	cmd.Dir = repoDir
+	if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "Earlier work" {
+		t.Errorf("branch %s lost its commit: %s %v", branch, out, err)
+	}
+	if entries, _ := os.ReadDir(worktreeDir); len(entries) != 0 {
+		t.Errorf("worktree dir has %d entries, want none", len(entries))
+	}
+}
+
+func TestParseBranchPolicy(t *testing.T) {
+	tests := map[string]BranchPolicy{
+		"":         BranchPolicyRecreate,
+		"recreate": BranchPolicyRecreate,
+		"Reuse":    BranchPolicyReuse,
+		"error":    BranchPolicyError,
+	}
+	for in, want := range tests {
+		got, err := ParseBranchPolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseBranchPolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBranchPolicy("keep"); err == nil {
+		t.Error("ParseBranchPolicy(\"keep\") should fail")
 	}
 }
@@ -4,6 +4,7 @@ package issues
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -49,11 +50,35 @@ func (r *AnalysisResult) AllChecksPassed() bool {
 	return true
 }
 
+// ErrProviderUnavailable is matched by ProviderUnavailableError via errors.Is
+var ErrProviderUnavailable = errors.New("provider unavailable")
+
+// ProviderUnavailableError reports that issue analysis was skipped because a
+// required tool (gh or claude) is missing or GitHub cannot be reached.
+// Callers should treat it as a skip, not a failure.
+type ProviderUnavailableError struct {
+	Provider string // "gh" or "claude"
+	Reason   string
+}
+
+func (e *ProviderUnavailableError) Error() string {
+	return fmt.Sprintf("skipped: provider unavailable (%s: %s)", e.Provider, e.Reason)
+}
+
+func (e *ProviderUnavailableError) Unwrap() error {
+	return ErrProviderUnavailable
+}
+
 type Analyzer struct {
 	store    *taskstore.Store
 	fetcher  *Fetcher
 	config   *config.GitHubIssuesConfig
 	plansDir string
+	claude   string // Claude Code binary to run
+
+	// Provider probes, replaceable in tests
+	lookPath func(file string) (string, error)
+	ghAuth   func(ctx context.Context) error
 }
 
 func NewAnalyzer(store *taskstore.Store, cfg *config.GitHubIssuesConfig, plansDir string) *Analyzer {
@@ -62,11 +87,53 @@ func NewAnalyzer(store *taskstore.Store, cfg *config.GitHubIssuesConfig, plansDi
 		fetcher:  NewFetcher(cfg),
 		config:   cfg,
 		plansDir: plansDir,
+		claude:   "claude",
+		lookPath: exec.LookPath,
+		ghAuth:   ghAuthStatus,
+	}
+}
+
+// SetClaudeBinaryPath sets the Claude Code binary the analysis agent runs
+// (empty = "claude" from PATH)
+func (a *Analyzer) SetClaudeBinaryPath(path string) {
+	if path == "" {
+		path = "claude"
 	}
+	a.claude = path
+}
+
+// ghAuthStatus checks that gh is logged in and can reach GitHub
+func ghAuthStatus(ctx context.Context) error {
+	return exec.CommandContext(ctx, "gh", "auth", "status").Run()
+}
+
+// CheckProviders verifies that gh and claude are installed and that gh can
+// reach GitHub. It returns a *ProviderUnavailableError if not.
+func (a *Analyzer) CheckProviders(ctx context.Context) error {
+	if _, err := a.lookPath("gh"); err != nil {
+		return &ProviderUnavailableError{Provider: "gh", Reason: "not found in PATH"}
+	}
+	if _, err := a.lookPath(a.claude); err != nil {
+		reason := "not found in PATH"
+		if a.claude != "claude" {
+			reason = fmt.Sprintf("%s not found", a.claude)
+		}
+		return &ProviderUnavailableError{Provider: "claude", Reason: reason}
+	}
+	if err := a.ghAuth(ctx); err != nil {
+		return &ProviderUnavailableError{Provider: "gh", Reason: fmt.Sprintf("not authenticated or GitHub unreachable: %v", err)}
+	}
+	return nil
 }
 
 // AnalyzeCandidates fetches and analyzes all candidate issues.
+// If gh or claude is unavailable it returns a *ProviderUnavailableError
+// without fetching anything.
 func (a *Analyzer) AnalyzeCandidates(ctx context.Context, maxParallel int) error {
+	if err := a.CheckProviders(ctx); err != nil {
+		return err
+	}
+
 	issues, err := a.fetcher.FetchCandidateIssues()
 	if err != nil {
 		return fmt.Errorf("fetch candidates: %w", err)
@@ -114,6 +181,9 @@ func (a *Analyzer) AnalyzeCandidates(ctx context.Context, maxParallel int) error
 
 // AnalyzeOne analyzes a single issue (for manual triggering).
 func (a *Analyzer) AnalyzeOne(ctx context.Context, issue *domain.GitHubIssue) error {
+	if err := a.CheckProviders(ctx); err != nil {
+		return err
+	}
 	return a.analyzeIssue(ctx, issue)
 }
 
@@ -128,7 +198,7 @@ func (a *Analyzer) analyzeIssue(ctx context.Context, issue *domain.GitHubIssue)
 	prompt := BuildAnalysisPrompt(issue.IssueNumber, a.config.Repo, a.plansDir)
 
 	// Spawn Claude Code agent
-	cmd := exec.CommandContext(ctx, "claude",
+	cmd := exec.CommandContext(ctx, a.claude,
 		"--print",
 		"--dangerously-skip-permissions",
 		"--output-format", "text",
@@ -2,7 +2,13 @@
 package issues
 
 import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
 	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/config"
 )
 
 func TestParseAnalysisResult(t *testing.T) {
@@ -58,3 +64,82 @@ func TestAnalysisResult_AllChecksPassed(t *testing.T) {
 		t.Error("expected AllChecksPassed() = false when one fails")
 	}
 }
+
+func TestAnalyzer_SkipsWhenProviderUnavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		missing  string
+		authErr  error
+		provider string
+	}{
+		{name: "gh missing", missing: "gh", provider: "gh"},
+		{name: "claude missing", missing: "claude", provider: "claude"},
+		{name: "gh offline", authErr: errors.New("exit status 1"), provider: "gh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A nil store proves nothing is fetched or persisted on the skip path
+			a := NewAnalyzer(nil, &config.GitHubIssuesConfig{Repo: "owner/repo"}, "/tmp/plans")
+			a.lookPath = func(file string) (string, error) {
+				if file == tt.missing {
+					return "", exec.ErrNotFound
+				}
+				return "/usr/bin/" + file, nil
+			}
+			a.ghAuth = func(ctx context.Context) error { return tt.authErr }
+
+			err := a.AnalyzeCandidates(context.Background(), 2)
+
+			var unavailable *ProviderUnavailableError
+			if !errors.As(err, &unavailable) {
+				t.Fatalf("AnalyzeCandidates() error = %v, want *ProviderUnavailableError", err)
+			}
+			if unavailable.Provider != tt.provider {
+				t.Errorf("Provider = %q, want %q", unavailable.Provider, tt.provider)
+			}
+			if !errors.Is(err, ErrProviderUnavailable) {
+				t.Error("error should match ErrProviderUnavailable")
+			}
+		})
+	}
+}
+
+func TestAnalyzer_CheckProvidersAvailable(t *testing.T) {
+	a := NewAnalyzer(nil, &config.GitHubIssuesConfig{}, "/tmp/plans")
+	a.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	a.ghAuth = func(ctx context.Context) error { return nil }
+
+	if err := a.CheckProviders(context.Background()); err != nil {
+		t.Errorf("CheckProviders() error = %v, want nil", err)
+	}
+}
+
+func TestAnalyzer_ChecksConfiguredClaudeBinary(t *testing.T) {
+	a := NewAnalyzer(nil, &config.GitHubIssuesConfig{}, "/tmp/plans")
+	a.SetClaudeBinaryPath("/opt/claude/bin/claude")
+	var looked []string
+	a.lookPath = func(file string) (string, error) {
+		looked = append(looked, file)
+		if file == "claude" {
+			return "", exec.ErrNotFound // Only the configured binary is installed
+		}
+		return file, nil
+	}
+	a.ghAuth = func(ctx context.Context) error { return nil }
+
+	if err := a.CheckProviders(context.Background()); err != nil {
+		t.Errorf("CheckProviders() error = %v, want the configured binary accepted", err)
+	}
+	if want := []string{"gh", "/opt/claude/bin/claude"}; !slices.Equal(looked, want) {
+		t.Errorf("looked up %v, want %v", looked, want)
+	}
+}
+
+func TestProviderUnavailableError_Message(t *testing.T) {
+	err := &ProviderUnavailableError{Provider: "gh", Reason: "not found in PATH"}
+	want := "skipped: provider unavailable (gh: not found in PATH)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,107 @@
+// Package netutil finds the address under which remote build workers can
+// reach this machine.
+package netutil
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Lookup steps of ExternalHost, replaced in tests
+var (
+	tailscaleIP = func() string {
+		out, err := exec.Command("tailscale", "ip", "-4").Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	hostname       = os.Hostname
+	listInterfaces = systemInterfaces
+)
+
+// ExternalHost tries to get a network-accessible address for this machine.
+// It prefers the Tailscale IP, then the hostname, then the IPv4 address of a
+// network interface, and returns "localhost" only as a last resort.
+func ExternalHost() string {
+	// Try Tailscale IP first (most reliable for remote access)
+	if ip := tailscaleIP(); ip != "" {
+		return ip
+	}
+
+	// Try hostname
+	if name, err := hostname(); err == nil && name != "" {
+		return name
+	}
+
+	// Try the address of a network interface
+	if ifaces, err := listInterfaces(); err == nil {
+		if ip := interfaceIP(ifaces); ip != "" {
+			return ip
+		}
+	}
+
+	// Last resort - return localhost (will likely fail for remote workers)
+	return "localhost"
+}
+
+// iface is the part of a network interface needed to pick its address
+type iface struct {
+	name  string
+	flags net.Flags
+	addrs []net.Addr
+}
+
+func systemInterfaces() ([]iface, error) {
+	netIfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	ifaces := make([]iface, 0, len(netIfaces))
+	for _, ni := range netIfaces {
+		addrs, err := ni.Addrs()
+		if err != nil {
+			continue
+		}
+		ifaces = append(ifaces, iface{name: ni.Name, flags: ni.Flags, addrs: addrs})
+	}
+	return ifaces, nil
+}
+
+// virtualInterfacePrefixes name container and VM bridges, whose addresses
+// are only reachable from this machine
+var virtualInterfacePrefixes = []string{"docker", "br-", "veth", "virbr"}
+
+// interfaceIP returns the first global unicast IPv4 address of an interface
+// that is up, not a loopback and not a virtual bridge ("" if there is none)
+func interfaceIP(ifaces []iface) string {
+	for _, ifc := range ifaces {
+		if ifc.flags&net.FlagUp == 0 || ifc.flags&net.FlagLoopback != 0 || isVirtual(ifc.name) {
+			continue
+		}
+		for _, addr := range ifc.addrs {
+			var ip net.IP
+			switch a := addr.(type) {
+			case *net.IPNet:
+				ip = a.IP
+			case *net.IPAddr:
+				ip = a.IP
+			}
+			if ip4 := ip.To4(); ip4 != nil && ip4.IsGlobalUnicast() {
+				return ip4.String()
+			}
+		}
+	}
+	return ""
+}
+
+func isVirtual(name string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
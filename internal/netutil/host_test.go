@@ -0,0 +1,99 @@
+package netutil
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func ipNet(s string) *net.IPNet {
+	ip, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	n.IP = ip
+	return n
+}
+
+func TestInterfaceIP(t *testing.T) {
+	up := net.FlagUp | net.FlagBroadcast
+
+	tests := []struct {
+		name   string
+		ifaces []iface
+		want   string
+	}{
+		{
+			name: "skips loopback, down, virtual and link-local",
+			ifaces: []iface{
+				{name: "lo", flags: net.FlagUp | net.FlagLoopback, addrs: []net.Addr{ipNet("127.0.0.1/8")}},
+				{name: "eth1", flags: net.FlagBroadcast, addrs: []net.Addr{ipNet("10.0.0.5/24")}},
+				{name: "docker0", flags: up, addrs: []net.Addr{ipNet("172.17.0.1/16")}},
+				{name: "eth0", flags: up, addrs: []net.Addr{
+					ipNet("169.254.10.1/16"),
+					ipNet("fe80::1/64"),
+					ipNet("192.168.1.20/24"),
+				}},
+			},
+			want: "192.168.1.20",
+		},
+		{
+			name: "accepts IPAddr",
+			ifaces: []iface{
+				{name: "wlan0", flags: up, addrs: []net.Addr{&net.IPAddr{IP: net.ParseIP("10.1.2.3")}}},
+			},
+			want: "10.1.2.3",
+		},
+		{
+			name: "ignores IPv6-only interfaces",
+			ifaces: []iface{
+				{name: "eth0", flags: up, addrs: []net.Addr{ipNet("2001:db8::1/64")}},
+			},
+			want: "",
+		},
+		{
+			name: "no interfaces",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interfaceIP(tt.ifaces); got != tt.want {
+				t.Errorf("interfaceIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExternalHost_Fallbacks(t *testing.T) {
+	origTailscale, origHostname, origList := tailscaleIP, hostname, listInterfaces
+	t.Cleanup(func() {
+		tailscaleIP, hostname, listInterfaces = origTailscale, origHostname, origList
+	})
+
+	eth0 := []iface{{name: "eth0", flags: net.FlagUp, addrs: []net.Addr{ipNet("192.168.1.20/24")}}}
+	noHostname := func() (string, error) { return "", errors.New("no hostname") }
+
+	tailscaleIP = func() string { return "100.64.0.1" }
+	hostname = func() (string, error) { return "devbox", nil }
+	listInterfaces = func() ([]iface, error) { return eth0, nil }
+	if got := ExternalHost(); got != "100.64.0.1" {
+		t.Errorf("with tailscale: got %q, want 100.64.0.1", got)
+	}
+
+	tailscaleIP = func() string { return "" }
+	if got := ExternalHost(); got != "devbox" {
+		t.Errorf("with hostname: got %q, want devbox", got)
+	}
+
+	hostname = noHostname
+	if got := ExternalHost(); got != "192.168.1.20" {
+		t.Errorf("with interface: got %q, want 192.168.1.20", got)
+	}
+
+	listInterfaces = func() ([]iface, error) { return nil, errors.New("no interfaces") }
+	if got := ExternalHost(); got != "localhost" {
+		t.Errorf("without anything: got %q, want localhost", got)
+	}
+}
@@ -1,11 +1,17 @@
 package observer
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
 )
 
 func TestObserver_DetectStuck(t *testing.T) {
@@ -60,3 +66,75 @@ func TestObserver_Metrics(t *testing.T) {
 		t.Errorf("AvgDuration = %v, want 7m30s", metrics.AvgDuration)
 	}
 }
+
+func TestPlanWatcher_SkipsIgnoredPaths(t *testing.T) {
+	worktree := t.TempDir()
+	plansDir := filepath.Join(worktree, "docs", "plans")
+	os.MkdirAll(filepath.Join(plansDir, "technical"), 0755)
+	os.MkdirAll(filepath.Join(plansDir, "archive", "2024"), 0755)
+	os.WriteFile(filepath.Join(plansDir, parser.IgnoreFileName), []byte("archive/\n_template.md\n"), 0644)
+
+	changed := make(chan []string, 1)
+	pw, err := NewPlanWatcher(func(_ string, files []string) {
+		changed <- files
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Stop()
+	pw.SetDebounce(10 * time.Millisecond)
+
+	if err := pw.AddWorktree(worktree); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range pw.watcher.WatchList() {
+		if strings.Contains(path, "archive") {
+			t.Errorf("ignored directory %s should not be watched", path)
+		}
+	}
+
+	pw.handleEvent(fsnotify.Event{Name: filepath.Join(plansDir, "technical", "_template.md"), Op: fsnotify.Write})
+	pw.handleEvent(fsnotify.Event{Name: filepath.Join(plansDir, "technical", "epic-00-setup.md"), Op: fsnotify.Write})
+
+	select {
+	case files := <-changed:
+		if len(files) != 1 || filepath.Base(files[0]) != "epic-00-setup.md" {
+			t.Errorf("changed files = %v, want only epic-00-setup.md", files)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for plan change callback")
+	}
+}
+
+func TestPlanWatcher_ReportsDeletedFiles(t *testing.T) {
+	worktree := t.TempDir()
+	plansDir := filepath.Join(worktree, "docs", "plans")
+	os.MkdirAll(filepath.Join(plansDir, "technical"), 0755)
+
+	changed := make(chan []string, 1)
+	pw, err := NewPlanWatcher(func(_ string, files []string) {
+		changed <- files
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pw.Stop()
+	pw.SetDebounce(10 * time.Millisecond)
+
+	if err := pw.AddWorktree(worktree); err != nil {
+		t.Fatal(err)
+	}
+
+	pw.handleEvent(fsnotify.Event{Name: filepath.Join(plansDir, "technical", "epic-00-setup.md"), Op: fsnotify.Remove})
+	pw.handleEvent(fsnotify.Event{Name: filepath.Join(plansDir, "technical", "epic-01-auth.md"), Op: fsnotify.Chmod})
+
+	select {
+	case files := <-changed:
+		if len(files) != 1 || filepath.Base(files[0]) != "epic-00-setup.md" {
+			t.Errorf("changed files = %v, want only the deleted epic-00-setup.md", files)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for plan change callback")
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
 )
 
 // PlanChangeCallback is called when plan files change
-// worktreePath is the root of the worktree where changes occurred
+// worktreePath is the root of the worktree where changes occurred;
+// changedFiles includes deleted files, which no longer exist
 type PlanChangeCallback func(worktreePath string, changedFiles []string)
 
 // PlanWatcher monitors worktrees for changes to plan/epic files
@@ -21,8 +24,8 @@ type PlanWatcher struct {
 	callback PlanChangeCallback
 	debounce time.Duration
 
-	// Track watched worktrees
-	worktrees map[string]struct{}
+	// Track watched worktrees and their .orchignore rules
+	worktrees map[string]*parser.IgnoreMatcher
 
 	// Debounce state - track by worktree
 	pendingByWorktree map[string]map[string]struct{}
@@ -43,14 +46,15 @@ func NewPlanWatcher(callback PlanChangeCallback) (*PlanWatcher, error) {
 		watcher:           watcher,
 		callback:          callback,
 		debounce:          500 * time.Millisecond, // Debounce rapid changes
-		worktrees:         make(map[string]struct{}),
+		worktrees:         make(map[string]*parser.IgnoreMatcher),
 		pendingByWorktree: make(map[string]map[string]struct{}),
 	}
 
 	return pw, nil
 }
 
-// AddWorktree starts watching a worktree's docs/plans directory
+// AddWorktree starts watching a worktree's docs/plans directory.
+// Directories matched by docs/plans/.orchignore are not watched.
 func (pw *PlanWatcher) AddWorktree(worktreePath string) error {
 	pw.mu.Lock()
 	defer pw.mu.Unlock()
@@ -66,21 +70,29 @@ func (pw *PlanWatcher) AddWorktree(worktreePath string) error {
 		return nil // No plans directory, nothing to watch
 	}
 
-	// Add the plans directory and all subdirectories
-	err := filepath.Walk(plansDir, func(path string, info os.FileInfo, err error) error {
+	ignore, err := parser.LoadIgnoreFile(plansDir)
+	if err != nil {
+		return err
+	}
+
+	// Add the plans directory and all non-ignored subdirectories
+	err = filepath.Walk(plansDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
 		}
-		if info.IsDir() {
-			return pw.watcher.Add(path)
+		if !info.IsDir() {
+			return nil
 		}
-		return nil
+		if rel, _ := filepath.Rel(plansDir, path); rel != "." && ignore.Match(rel, true) {
+			return filepath.SkipDir
+		}
+		return pw.watcher.Add(path)
 	})
 	if err != nil {
 		return err
 	}
 
-	pw.worktrees[worktreePath] = struct{}{}
+	pw.worktrees[worktreePath] = ignore
 	return nil
 }
 
@@ -148,8 +160,8 @@ func (pw *PlanWatcher) handleEvent(event fsnotify.Event) {
 		return
 	}
 
-	// Only care about writes and creates
-	if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+	// Only care about writes, creates and deletions (a rename removes the old name)
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
 		return
 	}
 
@@ -162,6 +174,12 @@ func (pw *PlanWatcher) handleEvent(event fsnotify.Event) {
 		return // Not in a watched worktree
 	}
 
+	// Skip files excluded by the worktree's .orchignore
+	plansDir := filepath.Join(worktreePath, "docs", "plans")
+	if rel, err := filepath.Rel(plansDir, event.Name); err == nil && pw.worktrees[worktreePath].Match(rel, false) {
+		return
+	}
+
 	// Add to pending files for this worktree
 	if pw.pendingByWorktree[worktreePath] == nil {
 		pw.pendingByWorktree[worktreePath] = make(map[string]struct{})
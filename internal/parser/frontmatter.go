@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -84,8 +85,38 @@ func ToPriority(s string) domain.Priority {
 	}
 }
 
+// statusVocabulary maps custom frontmatter status words to task statuses.
+// Consulted before the built-in words; empty unless configured.
+var statusVocabulary = map[string]domain.TaskStatus{}
+
+// SetStatusVocabulary configures custom frontmatter status words, e.g.
+// {"wip": "in_progress", "blocked": "not_started"}. Words are matched
+// case-insensitively. Returns an error if a word maps to an unknown status.
+func SetStatusVocabulary(vocab map[string]string) error {
+	resolved := make(map[string]domain.TaskStatus, len(vocab))
+	for word, status := range vocab {
+		switch ts := domain.TaskStatus(status); ts {
+		case domain.StatusNotStarted, domain.StatusInProgress, domain.StatusComplete:
+			resolved[normalizeStatusWord(word)] = ts
+		default:
+			return fmt.Errorf("status %q for word %q: must be %q, %q or %q",
+				status, word, domain.StatusNotStarted, domain.StatusInProgress, domain.StatusComplete)
+		}
+	}
+	statusVocabulary = resolved
+	return nil
+}
+
+func normalizeStatusWord(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
 // ToStatus converts a string to a TaskStatus
 func ToStatus(s string) domain.TaskStatus {
+	if status, ok := statusVocabulary[normalizeStatusWord(s)]; ok {
+		return status
+	}
+
 	switch s {
 	case "in_progress", "inprogress", "in-progress", "running":
 		return domain.StatusInProgress
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the gitignore-style file in a plans directory listing
+// plan files and directories that must not become tasks
+const IgnoreFileName = ".orchignore"
+
+// ignoreRule is a single parsed .orchignore pattern
+type ignoreRule struct {
+	segments []string // Pattern split on "/"; "**" matches any number of segments
+	negate   bool     // Pattern started with "!" and re-includes matches
+	dirOnly  bool     // Pattern ended with "/" and only matches directories
+	anchored bool     // Pattern contained "/" and is relative to the plans directory
+}
+
+// IgnoreMatcher decides whether a path below the plans directory is ignored.
+// A nil matcher ignores nothing.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// LoadIgnoreFile reads the .orchignore file in plansDir.
+// A missing file yields an empty matcher.
+func LoadIgnoreFile(plansDir string) (*IgnoreMatcher, error) {
+	content, err := os.ReadFile(filepath.Join(plansDir, IgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IgnoreMatcher{}, nil
+		}
+		return nil, err
+	}
+	return ParseIgnore(content), nil
+}
+
+// ParseIgnore parses gitignore-style patterns: blank lines and "#" comments
+// are skipped, "!" negates, a trailing "/" matches directories only, a
+// pattern containing "/" is anchored to the plans directory, and "**"
+// matches across directories. Later patterns override earlier ones.
+func ParseIgnore(content []byte) *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(line, "/")
+		m.rules = append(m.rules, rule)
+	}
+	return m
+}
+
+// Match reports whether relPath (relative to the plans directory) is ignored.
+// Anything inside an ignored directory is ignored as well.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(relPath)), "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchParts(parts[:i], true) {
+			return true
+		}
+	}
+	return m.matchParts(parts, isDir)
+}
+
+// matchParts applies all rules to a single path, last match wins
+func (m *IgnoreMatcher) matchParts(parts []string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(parts, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(parts []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return matchSegments(r.segments, parts)
+	}
+	// Unanchored patterns have a single segment and match the base name
+	return matchSegments(r.segments, parts[len(parts)-1:])
+}
+
+// matchSegments matches pattern segments against path segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
@@ -101,6 +101,12 @@ func ParseEpicFile(path string) (*domain.Task, error) {
 
 // ParseModuleDir parses all epic files in a module directory
 func ParseModuleDir(dir string) ([]*domain.Task, error) {
+	return parseModuleDir(dir, "", nil)
+}
+
+// parseModuleDir parses the epic files in dir, skipping files matched by
+// ignore; relDir is dir relative to the plans directory
+func parseModuleDir(dir, relDir string, ignore *IgnoreMatcher) ([]*domain.Task, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
@@ -114,6 +120,9 @@ func ParseModuleDir(dir string) ([]*domain.Task, error) {
 		if _, _, ok := matchEpicFile(entry.Name()); !ok {
 			continue
 		}
+		if ignore.Match(filepath.Join(relDir, entry.Name()), false) {
+			continue
+		}
 
 		task, err := ParseEpicFile(filepath.Join(dir, entry.Name()))
 		if err != nil {
@@ -130,48 +139,60 @@ func ParseModuleDir(dir string) ([]*domain.Task, error) {
 
 	// Add implicit dependencies (find highest existing predecessor in same sequence)
 	for _, task := range tasks {
-		// Find the highest existing task in same sequence (module+prefix) below this one
-		var bestDep *domain.TaskID
-		for epicNum := task.ID.EpicNum - 1; epicNum >= 0; epicNum-- {
-			candidate := domain.TaskID{Module: task.ID.Module, Prefix: task.ID.Prefix, EpicNum: epicNum}
-			if existingTasks[candidate.String()] {
-				bestDep = &candidate
-				break // Found highest existing predecessor
-			}
-		}
+		addImplicitDependency(task, existingTasks)
+	}
 
-		if bestDep == nil {
-			continue // No predecessor exists
-		}
+	return tasks, nil
+}
 
-		// Check if already in explicit deps
-		found := false
-		for _, d := range task.DependsOn {
-			if d.String() == bestDep.String() {
-				found = true
-				break
-			}
-		}
-		if !found {
-			task.DependsOn = append(task.DependsOn, *bestDep)
+// addImplicitDependency makes task depend on the highest task below it in the
+// same sequence (module+prefix), unless it already does. existingTasks holds
+// the IDs of the tasks in the module.
+func addImplicitDependency(task *domain.Task, existingTasks map[string]bool) {
+	// Find the highest existing task in same sequence (module+prefix) below this one
+	var bestDep *domain.TaskID
+	for epicNum := task.ID.EpicNum - 1; epicNum >= 0; epicNum-- {
+		candidate := domain.TaskID{Module: task.ID.Module, Prefix: task.ID.Prefix, EpicNum: epicNum}
+		if existingTasks[candidate.String()] {
+			bestDep = &candidate
+			break // Found highest existing predecessor
 		}
 	}
 
-	return tasks, nil
+	if bestDep == nil {
+		return // No predecessor exists
+	}
+
+	// Check if already in explicit deps
+	for _, d := range task.DependsOn {
+		if d.String() == bestDep.String() {
+			return
+		}
+	}
+	task.DependsOn = append(task.DependsOn, *bestDep)
 }
 
-// ParsePlansDir parses all modules in a docs/plans directory
+// ParsePlansDir parses all modules in a docs/plans directory, skipping
+// anything matched by the plans directory's .orchignore
 func ParsePlansDir(plansDir string) ([]*domain.Task, error) {
 	entries, err := os.ReadDir(plansDir)
 	if err != nil {
 		return nil, err
 	}
 
+	ignore, err := LoadIgnoreFile(plansDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFileName, err)
+	}
+
 	var allTasks []*domain.Task
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
 		}
+		if ignore.Match(entry.Name(), true) {
+			continue
+		}
 
 		// Check if directory contains any epic files
 		dirPath := filepath.Join(plansDir, entry.Name())
@@ -180,7 +201,7 @@ func ParsePlansDir(plansDir string) ([]*domain.Task, error) {
 			continue
 		}
 
-		tasks, err := ParseModuleDir(dirPath)
+		tasks, err := parseModuleDir(dirPath, entry.Name(), ignore)
 		if err != nil {
 			// Log but don't fail - some directories might have different structures
 			continue
@@ -205,6 +226,63 @@ func ParsePlansDir(plansDir string) ([]*domain.Task, error) {
 	return allTasks, nil
 }
 
+// ParseTask parses the epic file of a single task in a docs/plans directory
+// the way ParsePlansDir does, without parsing the module's other epic files.
+// It returns nil if the task has no epic file or the file is ignored.
+func ParseTask(plansDir string, id domain.TaskID) (*domain.Task, error) {
+	ignore, err := LoadIgnoreFile(plansDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFileName, err)
+	}
+	if ignore.Match(id.Module, true) {
+		return nil, nil
+	}
+
+	dir := filepath.Join(plansDir, id.Module)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The file names tell the module's tasks, for the implicit dependency
+	var path string
+	existingTasks := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		prefix, epicNum, ok := matchEpicFile(entry.Name())
+		if !ok || ignore.Match(filepath.Join(id.Module, entry.Name()), false) {
+			continue
+		}
+		taskID := domain.TaskID{Module: id.Module, Prefix: prefix, EpicNum: epicNum}
+		existingTasks[taskID.String()] = true
+		if taskID == id && path == "" {
+			path = filepath.Join(dir, entry.Name())
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	task, err := ParseEpicFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	addImplicitDependency(task, existingTasks)
+
+	// The README status applies as in ParsePlansDir
+	if task.Status == domain.StatusNotStarted {
+		if status, ok := ParseReadmeStatuses(plansDir)[task.ID.String()]; ok {
+			task.Status = status
+		}
+	}
+	return task, nil
+}
+
 // directoryHasEpicFiles checks if a directory contains any epic-*.md files
 func directoryHasEpicFiles(dir string) (bool, error) {
 	entries, err := os.ReadDir(dir)
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
@@ -278,3 +279,166 @@ func TestExtractTaskIDFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestToStatus_CustomVocabulary(t *testing.T) {
+	if err := SetStatusVocabulary(map[string]string{
+		"wip":     "in_progress",
+		"Blocked": "not_started",
+		"shipped": "complete",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetStatusVocabulary(nil) })
+
+	tests := []struct {
+		input string
+		want  domain.TaskStatus
+	}{
+		{"wip", domain.StatusInProgress},
+		{"WIP", domain.StatusInProgress},
+		{"blocked", domain.StatusNotStarted},
+		{"shipped", domain.StatusComplete},
+		{"done", domain.StatusComplete},
+		{"in_progress", domain.StatusInProgress},
+		{"unknown", domain.StatusNotStarted},
+	}
+
+	for _, tt := range tests {
+		if got := ToStatus(tt.input); got != tt.want {
+			t.Errorf("ToStatus(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseEpicFile_CustomStatusVocabulary(t *testing.T) {
+	if err := SetStatusVocabulary(map[string]string{"wip": "in_progress"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetStatusVocabulary(nil) })
+
+	dir := t.TempDir()
+	epicPath := filepath.Join(dir, "billing", "epic-01-invoices.md")
+	os.MkdirAll(filepath.Dir(epicPath), 0755)
+	os.WriteFile(epicPath, []byte("---\nstatus: wip\n---\n# Epic 01: Invoices\n"), 0644)
+
+	task, err := ParseEpicFile(epicPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Status != domain.StatusInProgress {
+		t.Errorf("Status = %q, want in_progress", task.Status)
+	}
+}
+
+func TestSetStatusVocabulary_RejectsUnknownStatus(t *testing.T) {
+	t.Cleanup(func() { SetStatusVocabulary(nil) })
+
+	if err := SetStatusVocabulary(map[string]string{"wip": "working"}); err == nil {
+		t.Error("expected error for unknown target status")
+	}
+}
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	m := ParseIgnore([]byte(`# templates and archived plans
+archive/
+_template*.md
+legacy/epic-0*.md
+!legacy/epic-01-keep.md
+**/drafts/*.md
+`))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"archive", true, true},
+		{"archive/epic-00-old.md", false, true},
+		{"archive", false, false},
+		{"technical/_template-epic.md", false, true},
+		{"technical/epic-00-setup.md", false, false},
+		{"legacy/epic-00-old.md", false, true},
+		{"legacy/epic-01-keep.md", false, false},
+		{"technical/drafts/epic-02-idea.md", false, true},
+		{"drafts/epic-02-idea.md", false, true},
+	}
+
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	var nilMatcher *IgnoreMatcher
+	if nilMatcher.Match("archive", true) {
+		t.Error("nil matcher should ignore nothing")
+	}
+}
+
+func TestParsePlansDir_OrchIgnore(t *testing.T) {
+	plansDir := t.TempDir()
+	for _, dir := range []string{"technical", "archive"} {
+		os.MkdirAll(filepath.Join(plansDir, dir), 0755)
+	}
+
+	os.WriteFile(filepath.Join(plansDir, "technical", "epic-00-setup.md"), []byte("# Epic 00: Setup\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, "technical", "epic-99-template.md"), []byte("# Epic 99: Template\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, "archive", "epic-00-old.md"), []byte("# Epic 00: Old\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, IgnoreFileName), []byte("archive/\ntechnical/epic-99-*.md\n"), 0644)
+
+	tasks, err := ParsePlansDir(plansDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tasks) != 1 {
+		t.Fatalf("Task count = %d, want 1", len(tasks))
+	}
+	if tasks[0].ID.String() != "technical/E00" {
+		t.Errorf("Task = %s, want technical/E00", tasks[0].ID)
+	}
+}
+
+func TestParseTask(t *testing.T) {
+	plansDir := t.TempDir()
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+	os.WriteFile(filepath.Join(moduleDir, "epic-01-setup.md"), []byte("---\nstatus: complete\n---\n\n# E01: Setup\n"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "epic-03-api.md"), []byte("---\npriority: high\n---\n\n# E03: API\n"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "epic-cli-01-cli.md"), []byte("# CLI\n"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "epic-04-draft.md"), []byte("# Draft\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, IgnoreFileName), []byte("technical/epic-04-*.md\n"), 0644)
+
+	all, err := ParsePlansDir(plansDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range all {
+		got, err := ParseTask(plansDir, want.ID)
+		if err != nil {
+			t.Fatalf("ParseTask(%s): %v", want.ID, err)
+		}
+		// Both parse the same file, so only the parse times differ
+		got.CreatedAt, got.UpdatedAt = want.CreatedAt, want.UpdatedAt
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseTask(%s) = %+v, want %+v as ParsePlansDir parses it", want.ID, got, want)
+		}
+	}
+
+	// E03 depends on E01, found from the file name alone
+	if task, _ := ParseTask(plansDir, domain.TaskID{Module: "technical", EpicNum: 3}); len(task.DependsOn) != 1 || task.DependsOn[0].EpicNum != 1 {
+		t.Errorf("E03 dependencies = %v, want [technical/E01]", task.DependsOn)
+	}
+
+	// Tasks without an epic file, ignored ones and unknown modules are nil
+	for _, id := range []domain.TaskID{
+		{Module: "technical", EpicNum: 2},
+		{Module: "technical", EpicNum: 4},
+		{Module: "billing", EpicNum: 1},
+	} {
+		if task, err := ParseTask(plansDir, id); task != nil || err != nil {
+			t.Errorf("ParseTask(%s) = %v, %v; want nil", id, task, err)
+		}
+	}
+}
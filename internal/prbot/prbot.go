@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/issues"
 )
 
@@ -28,10 +32,14 @@ Implements %s
 Autonomous implementation by ERP Orchestrator
 `
 
+// postMergeHookTimeout bounds how long OnMerge waits for the post-merge hook
+const postMergeHookTimeout = 5 * time.Minute
+
 // PRBot handles PR creation and management
 type PRBot struct {
-	repoDir     string
-	issueCloser *issues.Closer
+	repoDir       string
+	issueCloser   *issues.Closer
+	postMergeHook string // Shell command run after a PR merged (empty = none)
 }
 
 // NewPRBot creates a new PRBot
@@ -42,6 +50,14 @@ func NewPRBot(repoDir string, issueCloser *issues.Closer) *PRBot {
 	}
 }
 
+// SetPostMergeHook sets a shell command OnMerge runs after a PR merged, e.g.
+// to notify a channel or trigger a deploy. It runs in the repo directory with
+// the task and PR in CLAUDE_ORCH_* environment variables; the task ID and PR
+// number are also its arguments $1 and $2.
+func (p *PRBot) SetPostMergeHook(command string) {
+	p.postMergeHook = command
+}
+
 // BuildPRBody constructs the PR body
 func BuildPRBody(task *domain.Task, changeSummary string, testsPassed int, duration string) string {
 	return fmt.Sprintf(prBodyTemplate,
@@ -63,7 +79,7 @@ func (p *PRBot) CreatePR(worktreePath string, task *domain.Task, body string) (i
 	)
 
 	// Push the branch first
-	branch := fmt.Sprintf("feat/%s-E%02d", task.ID.Module, task.ID.EpicNum)
+	branch := executor.BranchName(task.ID)
 	pushCmd := exec.Command("git", "push", "-u", "origin", branch)
 	pushCmd.Dir = worktreePath
 	if out, err := pushCmd.CombinedOutput(); err != nil {
@@ -117,17 +133,68 @@ func (p *PRBot) MergePR(prNumber int) error {
 	return nil
 }
 
-// OnMerge handles post-merge actions like closing GitHub issues.
+// PRMerged reports whether a PR is merged according to GitHub
+func (p *PRBot) PRMerged(prNumber int) (bool, error) {
+	cmd := exec.Command("gh", "pr", "view", fmt.Sprintf("%d", prNumber),
+		"--json", "state",
+		"--jq", ".state",
+	)
+	cmd.Dir = p.repoDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("gh pr view: %s: %w", out, err)
+	}
+	return strings.TrimSpace(string(out)) == "MERGED", nil
+}
+
+// OnMerge handles post-merge actions like closing GitHub issues and running
+// the post-merge hook. Call it only after the PR merged; task is nil if the PR
+// belongs to no known task. Only a failing hook is returned as an error.
 func (p *PRBot) OnMerge(task *domain.Task, prNumber int) error {
-	if p.issueCloser != nil && task.GitHubIssue != nil {
+	if p.issueCloser != nil && task != nil && task.GitHubIssue != nil {
 		if err := p.issueCloser.CloseIfComplete(context.Background(), task, prNumber); err != nil {
 			// Log but don't fail - PR is already merged
 			log.Printf("warning: failed to close issue: %v", err)
 		}
 	}
+
+	if p.postMergeHook == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), postMergeHookTimeout)
+	defer cancel()
+
+	var taskID string
+	if task != nil {
+		taskID = task.ID.String()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.postMergeHook, "post-merge-hook", taskID, strconv.Itoa(prNumber))
+	cmd.Dir = p.repoDir
+	cmd.Env = append(os.Environ(), postMergeHookEnv(task, prNumber)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("post-merge hook: %s: %w", out, err)
+	}
 	return nil
 }
 
+// postMergeHookEnv returns the environment variables describing a merged PR
+// to the post-merge hook. The task variables are empty if task is nil.
+func postMergeHookEnv(task *domain.Task, prNumber int) []string {
+	var taskID, module, title, branch, epicFile string
+	if task != nil {
+		taskID, module, title = task.ID.String(), task.ID.Module, task.Title
+		branch, epicFile = executor.BranchName(task.ID), task.FilePath
+	}
+	return []string{
+		"CLAUDE_ORCH_TASK_ID=" + taskID,
+		"CLAUDE_ORCH_MODULE=" + module,
+		"CLAUDE_ORCH_TASK_TITLE=" + title,
+		"CLAUDE_ORCH_BRANCH=" + branch,
+		"CLAUDE_ORCH_EPIC_FILE=" + epicFile,
+		"CLAUDE_ORCH_PR_NUMBER=" + strconv.Itoa(prNumber),
+	}
+}
+
 // GetDiff gets the diff for a PR
 func (p *PRBot) GetDiff(prNumber int) (string, error) {
 	cmd := exec.Command("gh", "pr", "diff", fmt.Sprintf("%d", prNumber))
@@ -1,6 +1,8 @@
 package prbot
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
@@ -106,3 +108,54 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestOnMerge_PostMergeHook(t *testing.T) {
+	repoDir := t.TempDir()
+	p := NewPRBot(repoDir, nil)
+	task := &domain.Task{
+		ID:       domain.TaskID{Module: "billing", EpicNum: 2},
+		Title:    "Dunning",
+		FilePath: "docs/plans/billing-module/epic-02-dunning.md",
+	}
+
+	// Without a hook, OnMerge has nothing to run
+	if err := p.OnMerge(task, 42); err != nil {
+		t.Fatalf("OnMerge without hook: %v", err)
+	}
+
+	p.SetPostMergeHook(`printf '%s|%s|%s|%s|%s|%s|%s|%s' "$1" "$2" "$CLAUDE_ORCH_TASK_ID" "$CLAUDE_ORCH_MODULE" "$CLAUDE_ORCH_TASK_TITLE" "$CLAUDE_ORCH_BRANCH" "$CLAUDE_ORCH_EPIC_FILE" "$CLAUDE_ORCH_PR_NUMBER" > hook.out`)
+	if err := p.OnMerge(task, 42); err != nil {
+		t.Fatalf("OnMerge: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(repoDir, "hook.out"))
+	if err != nil {
+		t.Fatalf("hook did not run in the repo: %v", err)
+	}
+	want := "billing/E02|42|billing/E02|billing|Dunning|feat/billing-E02|docs/plans/billing-module/epic-02-dunning.md|42"
+	if string(got) != want {
+		t.Errorf("hook saw %q, want %q", got, want)
+	}
+
+	p.SetPostMergeHook("echo broken >&2; exit 3")
+	if err := p.OnMerge(task, 42); err == nil || !containsString(err.Error(), "broken") {
+		t.Errorf("OnMerge with failing hook: err = %v, want the hook's output", err)
+	}
+}
+
+func TestPRMerged(t *testing.T) {
+	// A fake gh prints the state of PR 7 as merged and of any other PR as open
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$3\" = 7 ]; then echo MERGED; else echo OPEN; fi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p := NewPRBot(t.TempDir(), nil)
+	if merged, err := p.PRMerged(7); err != nil || !merged {
+		t.Errorf("PRMerged(7) = %v, %v, want merged", merged, err)
+	}
+	if merged, err := p.PRMerged(8); err != nil || merged {
+		t.Errorf("PRMerged(8) = %v, %v, want not merged", merged, err)
+	}
+}
@@ -3,5 +3,5 @@ package prompts
 
 import "embed"
 
-//go:embed epic/*.md maintenance/*.md skills/*.md
+//go:embed completion/*.md epic/*.md maintenance/*.md skills/*.md
 var embeddedFS embed.FS
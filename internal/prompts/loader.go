@@ -220,6 +220,12 @@ func (l *Loader) BuildEpicPrompt(data EpicData) (string, error) {
 	return l.Execute("epic/task.md", data)
 }
 
+// BuildCompletionPrompt loads and executes the completion instructions for an
+// executor (e.g., "completion/opencode.md").
+func (l *Loader) BuildCompletionPrompt(executorType string, data EpicData) (string, error) {
+	return l.Execute(filepath.Join("completion", executorType+".md"), data)
+}
+
 // BuildMaintenancePrompt loads and executes the maintenance wrapper template.
 func (l *Loader) BuildMaintenancePrompt(data MaintenanceData) (string, error) {
 	return l.Execute("maintenance/wrapper.md", data)
@@ -213,6 +213,47 @@ Do custom refactoring things.
 	}
 }
 
+func TestLoaderCompletionOverride(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "prompts-completion-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	completionDir := filepath.Join(tmpDir, "completion")
+	if err := os.MkdirAll(completionDir, 0755); err != nil {
+		t.Fatalf("failed to create completion dir: %v", err)
+	}
+	customContent := "Call complete_task for {{.EpicFilePath}} when done.\n"
+	if err := os.WriteFile(filepath.Join(completionDir, "opencode.md"), []byte(customContent), 0644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	loader := NewLoader(tmpDir)
+	data := EpicData{EpicFilePath: "docs/plans/mod/epic-01.md"}
+
+	result, err := loader.BuildCompletionPrompt("opencode", data)
+	if err != nil {
+		t.Fatalf("failed to build completion prompt: %v", err)
+	}
+	if result != "Call complete_task for docs/plans/mod/epic-01.md when done.\n" {
+		t.Errorf("expected override content, got %q", result)
+	}
+
+	// Executors without an override still use the embedded instructions
+	result, err = loader.BuildCompletionPrompt("claude-code", data)
+	if err != nil {
+		t.Fatalf("failed to build embedded completion prompt: %v", err)
+	}
+	if !strings.Contains(result, "docs/plans/mod/epic-01.md") {
+		t.Error("embedded completion prompt should contain the epic file path")
+	}
+
+	if _, err := loader.BuildCompletionPrompt("unknown", data); err == nil {
+		t.Error("expected error for executor without completion instructions")
+	}
+}
+
 func TestLoaderSkillOverride(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "prompts-skill-*")
 	if err != nil {
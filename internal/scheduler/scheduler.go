@@ -1,7 +1,10 @@
 package scheduler
 
 import (
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 )
@@ -13,6 +16,11 @@ type Scheduler struct {
 	completed       map[string]bool
 	depGraph        map[string][]string // task -> tasks that depend on it
 	groupPriorities map[string]int      // group -> priority tier
+	defaultTier     int                 // tier for groups without an explicit priority
+	taskOverrides   map[string]int      // task -> priority override (promoted tasks)
+	groupWeights    map[string]int      // group -> weight within its tier
+	maxPerModule    int                 // in-flight tasks allowed per module (0 = unlimited)
+	cycles          []*CycleError       // dependency cycles among unfinished tasks
 }
 
 // New creates a new Scheduler
@@ -27,12 +35,14 @@ func New(tasks []*domain.Task, completed map[string]bool) *Scheduler {
 		}
 	}
 
-	return &Scheduler{
+	s := &Scheduler{
 		tasks:     tasks,
 		taskMap:   taskMap,
 		completed: completed,
 		depGraph:  depGraph,
 	}
+	s.cycles = s.findCycles()
+	return s
 }
 
 // NewWithPriorities creates a Scheduler with group priority constraints
@@ -42,6 +52,51 @@ func NewWithPriorities(tasks []*domain.Task, completed map[string]bool, groupPri
 	return s
 }
 
+// SetDefaultTier sets the tier used for groups that have no explicit priority.
+// A high value (e.g. 99) holds back newly-seen modules until they are promoted.
+func (s *Scheduler) SetDefaultTier(tier int) {
+	s.defaultTier = tier
+}
+
+// SetTaskOverrides sets per-task priority overrides. Overridden tasks are
+// treated as high priority and scheduled ahead of their peers, lower values
+// first. Group tiers still apply.
+func (s *Scheduler) SetTaskOverrides(overrides map[string]int) {
+	s.taskOverrides = overrides
+}
+
+// SetGroupWeights sets per-group weights. Among tasks of the same priority,
+// groups with a higher weight are scheduled first; groups without one weigh 0.
+// Weights order work within a tier and never move it to another.
+func (s *Scheduler) SetGroupWeights(weights map[string]int) {
+	s.groupWeights = weights
+}
+
+// SetMaxPerModule caps how many tasks of one module may be in flight at once,
+// counting in-progress tasks and newly selected ones. 0 means unlimited.
+func (s *Scheduler) SetMaxPerModule(n int) {
+	s.maxPerModule = n
+}
+
+// HasGroupPriorities reports whether tier constraints are in effect
+func (s *Scheduler) HasGroupPriorities() bool {
+	return len(s.groupPriorities) > 0
+}
+
+// TierOf returns the priority tier of a group, falling back to the default tier
+func (s *Scheduler) TierOf(group string) int {
+	if tier, ok := s.groupPriorities[group]; ok {
+		return tier
+	}
+	return s.defaultTier
+}
+
+// ActiveTier returns the lowest tier that still has incomplete tasks
+// (0 if no group priorities are configured)
+func (s *Scheduler) ActiveTier() int {
+	return s.getActivePriorityTier()
+}
+
 // GetReadyTasks returns up to limit tasks that are ready to run
 // It also accepts a set of currently in-progress task IDs to avoid conflicts
 func (s *Scheduler) GetReadyTasks(limit int) []*domain.Task {
@@ -73,7 +128,7 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 	for _, task := range s.tasks {
 		// Skip tasks not in active tier (if priorities are configured)
 		if len(s.groupPriorities) > 0 {
-			taskTier := s.groupPriorities[task.ID.Module] // Defaults to 0 if not found
+			taskTier := s.TierOf(task.ID.Module)
 			if taskTier > activeTier {
 				continue
 			}
@@ -92,12 +147,28 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 			return pi < pj
 		}
 
-		// 2. Different modules first (spread work across modules)
+		// 2. Promoted tasks ahead of their peers, lowest override first
+		oi, iok := s.taskOverrides[ready[i].ID.String()]
+		oj, jok := s.taskOverrides[ready[j].ID.String()]
+		if iok != jok {
+			return iok
+		}
+		if oi != oj {
+			return oi < oj
+		}
+
+		// 3. Heavier groups first
+		wi, wj := s.groupWeights[ready[i].ID.Module], s.groupWeights[ready[j].ID.Module]
+		if wi != wj {
+			return wi > wj
+		}
+
+		// 4. Different modules first (spread work across modules)
 		if ready[i].ID.Module != ready[j].ID.Module {
 			return ready[i].ID.Module < ready[j].ID.Module
 		}
 
-		// 3. Within same module+prefix (same sequence): natural order by epic number
+		// 5. Within same module+prefix (same sequence): natural order by epic number
 		// This ensures TUI06 comes before TUI09, CLI02 before CLI05, etc.
 		if ready[i].ID.Prefix == ready[j].ID.Prefix {
 			if ready[i].ID.EpicNum != ready[j].ID.EpicNum {
@@ -105,13 +176,13 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 			}
 		}
 
-		// 4. Dependency depth (unblocks more work) - for different sequences
+		// 6. Dependency depth (unblocks more work) - for different sequences
 		di, dj := s.dependencyDepth(ready[i].ID.String()), s.dependencyDepth(ready[j].ID.String())
 		if di != dj {
 			return di > dj
 		}
 
-		// 5. Prefix (alphabetical, for consistent ordering of CLI vs TUI etc.)
+		// 7. Prefix (alphabetical, for consistent ordering of CLI vs TUI etc.)
 		return ready[i].ID.Prefix < ready[j].ID.Prefix
 	})
 
@@ -119,12 +190,18 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 	var selected []*domain.Task
 	selectedIDs := make(map[string]bool)
 	selectedSequences := make(map[string]int) // track highest epic per sequence (module+prefix)
+	inFlight := s.inFlightPerModule(inProgress)
 
 	for _, task := range ready {
 		if len(selected) >= limit {
 			break
 		}
 
+		// Leave modules at their cap to the tasks already running there
+		if s.maxPerModule > 0 && inFlight[task.ID.Module] >= s.maxPerModule {
+			continue
+		}
+
 		// Check if this task conflicts with already selected tasks
 		if s.conflictsWithSelected(task, selectedIDs, selectedSequences) {
 			continue
@@ -132,6 +209,7 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 
 		selected = append(selected, task)
 		selectedIDs[task.ID.String()] = true
+		inFlight[task.ID.Module]++
 		// Track highest epic number selected per sequence (module+prefix)
 		seqKey := task.ID.Module + "/" + task.ID.Prefix
 		if task.ID.EpicNum > selectedSequences[seqKey] {
@@ -142,6 +220,223 @@ func (s *Scheduler) GetReadyTasksExcluding(limit int, inProgress map[string]bool
 	return selected
 }
 
+// CycleError reports unfinished tasks that depend on each other in a cycle,
+// so none of them can ever start
+type CycleError struct {
+	Cycle []domain.TaskID // Each task depends on the next, the last on the first
+}
+
+func (e *CycleError) Error() string {
+	ids := make([]string, 0, len(e.Cycle)+1)
+	for _, id := range e.Cycle {
+		ids = append(ids, id.String())
+	}
+	ids = append(ids, e.Cycle[0].String())
+	return "dependency cycle: " + strings.Join(ids, " -> ")
+}
+
+// Cycles returns the dependency cycles among the unfinished tasks, found when
+// the scheduler was created. Their tasks are never ready, so a plan with a
+// cycle stalls instead of finishing.
+func (s *Scheduler) Cycles() []*CycleError {
+	return s.cycles
+}
+
+// findCycles runs a depth-first search over the dependencies of unfinished
+// tasks and returns a cycle for every back edge it meets, each starting at
+// its lowest task ID. Finished tasks cannot be part of a stall: a task
+// depending on them is free to start.
+func (s *Scheduler) findCycles() []*CycleError {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int)
+	var path []*domain.Task
+	var cycles []*CycleError
+
+	var visit func(task *domain.Task)
+	visit = func(task *domain.Task) {
+		id := task.ID.String()
+		state[id] = onPath
+		path = append(path, task)
+		for _, dep := range task.DependsOn {
+			next, ok := s.taskMap[dep.String()]
+			if !ok || s.isFinished(next) {
+				continue
+			}
+			switch state[dep.String()] {
+			case unvisited:
+				visit(next)
+			case onPath:
+				start := slices.IndexFunc(path, func(t *domain.Task) bool { return t.ID.String() == dep.String() })
+				cycles = append(cycles, newCycleError(path[start:]))
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+	}
+
+	for _, task := range s.tasks {
+		if state[task.ID.String()] == unvisited && !s.isFinished(task) {
+			visit(task)
+		}
+	}
+	return cycles
+}
+
+// newCycleError returns the cycle of tasks, each depending on the next,
+// rotated to start at the lowest task ID so it reads the same however the
+// search entered it
+func newCycleError(tasks []*domain.Task) *CycleError {
+	lowest := 0
+	for i, task := range tasks {
+		if task.ID.String() < tasks[lowest].ID.String() {
+			lowest = i
+		}
+	}
+	cycle := make([]domain.TaskID, 0, len(tasks))
+	for i := range tasks {
+		cycle = append(cycle, tasks[(lowest+i)%len(tasks)].ID)
+	}
+	return &CycleError{Cycle: cycle}
+}
+
+// isFinished reports whether a task is completed
+func (s *Scheduler) isFinished(task *domain.Task) bool {
+	return s.completed[task.ID.String()] || task.Status == domain.StatusComplete
+}
+
+// BlockReason classifies why a task cannot start yet
+type BlockReason int
+
+const (
+	NotBlocked          BlockReason = iota // All dependencies are completed
+	BlockedByInProgress                    // Only waiting on dependencies that are currently running
+	BlockedByNotStarted                    // At least one dependency has not been started
+)
+
+// String returns the display name of a block reason
+func (r BlockReason) String() string {
+	switch r {
+	case BlockedByInProgress:
+		return "unblocking soon"
+	case BlockedByNotStarted:
+		return "blocked"
+	default:
+		return "not blocked"
+	}
+}
+
+// Classify reports whether task is blocked and, if so, whether it only waits
+// on dependencies that are in progress (and will soon unblock it) or also on
+// dependencies that have not been started. A dependency counts as in progress
+// if it is in the given set or has in-progress status; unknown dependencies
+// count as not started.
+func (s *Scheduler) Classify(task *domain.Task, inProgress map[string]bool) BlockReason {
+	reason := NotBlocked
+	for _, dep := range task.DependsOn {
+		depID := dep.String()
+		if s.completed[depID] {
+			continue
+		}
+		if inProgress[depID] {
+			reason = BlockedByInProgress
+			continue
+		}
+		if depTask, ok := s.taskMap[depID]; ok && depTask.Status == domain.StatusInProgress {
+			reason = BlockedByInProgress
+			continue
+		}
+		return BlockedByNotStarted
+	}
+	return reason
+}
+
+// UnblockingSoon returns the not-started tasks that are blocked solely by
+// dependencies that are currently in progress
+func (s *Scheduler) UnblockingSoon(inProgress map[string]bool) []*domain.Task {
+	var result []*domain.Task
+	for _, task := range s.tasks {
+		if task.Status != domain.StatusNotStarted || s.completed[task.ID.String()] {
+			continue
+		}
+		if s.Classify(task, inProgress) == BlockedByInProgress {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// Selection is the result of picking an explicit list of tasks to start
+type Selection struct {
+	Ready   []*domain.Task      // Tasks that can start, in the order requested
+	Blocked map[string][]string // Task ID -> dependencies that are not completed
+	Skipped []string            // Tasks that are already complete or in progress
+	Unknown []string            // IDs that match no task
+}
+
+// Only selects exactly the tasks with the given IDs, bypassing priority
+// ordering, tiers and the batch limit. Tasks are still refused when a
+// dependency is not completed, when they are complete or in progress (in
+// the given set or by status), or when the ID is unknown.
+func (s *Scheduler) Only(taskIDs []string, inProgress map[string]bool) Selection {
+	sel := Selection{Blocked: make(map[string][]string)}
+	seen := make(map[string]bool)
+
+	for _, id := range taskIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		task, ok := s.taskMap[id]
+		if !ok {
+			sel.Unknown = append(sel.Unknown, id)
+			continue
+		}
+		if s.completed[id] || inProgress[id] || task.Status != domain.StatusNotStarted {
+			sel.Skipped = append(sel.Skipped, id)
+			continue
+		}
+		if unmet := s.unmetDependencies(task); len(unmet) > 0 {
+			sel.Blocked[id] = unmet
+			continue
+		}
+		sel.Ready = append(sel.Ready, task)
+	}
+
+	return sel
+}
+
+// BlockedSummary describes each blocked task and its unmet dependencies as
+// "task (waits on dep, dep)", sorted by task ID
+func (sel Selection) BlockedSummary() []string {
+	ids := make([]string, 0, len(sel.Blocked))
+	for id := range sel.Blocked {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	lines := make([]string, len(ids))
+	for i, id := range ids {
+		lines[i] = fmt.Sprintf("%s (waits on %s)", id, strings.Join(sel.Blocked[id], ", "))
+	}
+	return lines
+}
+
+// unmetDependencies returns the IDs of task's dependencies that are not completed
+func (s *Scheduler) unmetDependencies(task *domain.Task) []string {
+	var unmet []string
+	for _, dep := range task.DependsOn {
+		if !s.completed[dep.String()] {
+			unmet = append(unmet, dep.String())
+		}
+	}
+	return unmet
+}
+
 // dependsOnAny checks if task depends on any of the given task IDs
 func (s *Scheduler) dependsOnAny(task *domain.Task, taskIDs map[string]bool) bool {
 	for _, dep := range task.DependsOn {
@@ -152,6 +447,23 @@ func (s *Scheduler) dependsOnAny(task *domain.Task, taskIDs map[string]bool) boo
 	return false
 }
 
+// inFlightPerModule counts the in-progress tasks of each module. Tasks of
+// every tier count, as they run in the module whatever its tier.
+func (s *Scheduler) inFlightPerModule(inProgress map[string]bool) map[string]int {
+	counts := make(map[string]int)
+	for id, running := range inProgress {
+		if !running {
+			continue
+		}
+		if task, ok := s.taskMap[id]; ok {
+			counts[task.ID.Module]++
+		} else if tid, err := domain.ParseTaskID(id); err == nil {
+			counts[tid.Module]++
+		}
+	}
+	return counts
+}
+
 // conflictsWithSelected checks if selecting this task would conflict with already selected tasks
 func (s *Scheduler) conflictsWithSelected(task *domain.Task, selectedIDs map[string]bool, selectedSequences map[string]int) bool {
 	// Check explicit dependencies - task can't depend on a selected task
@@ -251,7 +563,7 @@ func (s *Scheduler) getActivePriorityTier() int {
 	}
 
 	// Find the maximum tier number
-	maxTier := 0
+	maxTier := s.defaultTier
 	for _, tier := range s.groupPriorities {
 		if tier > maxTier {
 			maxTier = tier
@@ -262,8 +574,7 @@ func (s *Scheduler) getActivePriorityTier() int {
 	tierHasIncomplete := make(map[int]bool)
 	for _, task := range s.tasks {
 		if !s.completed[task.ID.String()] && task.Status != domain.StatusComplete {
-			tier := s.groupPriorities[task.ID.Module] // Defaults to 0 if not found
-			tierHasIncomplete[tier] = true
+			tierHasIncomplete[s.TierOf(task.ID.Module)] = true
 		}
 	}
 
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
@@ -61,6 +62,121 @@ func TestScheduler_GetReadyTasks_Priority(t *testing.T) {
 	}
 }
 
+func TestScheduler_TaskOverrideScheduledAheadOfPeers(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted, Priority: domain.PriorityHigh},
+		{ID: domain.TaskID{Module: "pricing", EpicNum: 0}, Status: domain.StatusNotStarted, Priority: domain.PriorityNormal},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 0}, Status: domain.StatusNotStarted, Priority: domain.PriorityNormal},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 0}, Status: domain.StatusNotStarted, Priority: domain.PriorityLow},
+	}
+	completed := map[string]bool{}
+
+	sched := New(tasks, completed)
+	sched.SetTaskOverrides(map[string]int{"tech/E00": 0, "zoning/E00": 0})
+	ready := sched.GetReadyTasks(10)
+
+	// Promoted tasks go ahead of their peers, but not of higher priorities
+	want := []string{"billing", "tech", "pricing", "zoning"}
+	for i, mod := range want {
+		if ready[i].ID.Module != mod {
+			t.Errorf("ready[%d] = %s, want %s", i, ready[i].ID.Module, mod)
+		}
+	}
+
+	// Among promoted tasks, the lower override value goes first
+	sched.SetTaskOverrides(map[string]int{"pricing/E00": 1, "tech/E00": 0})
+	ready = sched.GetReadyTasks(3)
+	if ready[1].ID.Module != "tech" || ready[2].ID.Module != "pricing" {
+		t.Errorf("ready = [%s %s], want [tech pricing]", ready[1].ID.Module, ready[2].ID.Module)
+	}
+}
+
+func TestScheduler_TaskOverrideRespectsGroupTiers(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "pricing", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+
+	sched := NewWithPriorities(tasks, map[string]bool{}, map[string]int{"billing": 0, "pricing": 1})
+	sched.SetTaskOverrides(map[string]int{"pricing/E00": 0})
+	ready := sched.GetReadyTasks(10)
+
+	if len(ready) != 1 || ready[0].ID.Module != "billing" {
+		t.Errorf("override must not bypass the active tier, got %v", ready)
+	}
+}
+
+func TestScheduler_GroupWeightsWithinTier(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "pricing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 0}, Status: domain.StatusNotStarted, Priority: domain.PriorityHigh},
+		{ID: domain.TaskID{Module: "analytics", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+
+	sched := NewWithPriorities(tasks, map[string]bool{}, map[string]int{"analytics": 1})
+	sched.SetGroupWeights(map[string]int{"tech": 5, "pricing": 2, "analytics": 9})
+	ready := sched.GetReadyTasks(10)
+
+	// Task priority still comes first, then heavier groups, then module/epic
+	// order; the heavy analytics group waits for its tier
+	want := []string{"zoning/E00", "tech/E00", "tech/E01", "pricing/E00", "billing/E00"}
+	var got []string
+	for _, task := range ready {
+		got = append(got, task.ID.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ready = %v, want %v", got, want)
+	}
+}
+
+func TestScheduler_MaxPerModule(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", Prefix: "API", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "billing", Prefix: "CLI", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "billing", Prefix: "TUI", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "pricing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "pricing", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 1}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "tech", EpicNum: 5}, Status: domain.StatusInProgress},
+		{ID: domain.TaskID{Module: "analytics", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+	// pricing/E07 is not among the scheduled tasks but still runs in pricing
+	inProgress := map[string]bool{"tech/E05": true, "pricing/E07": true}
+
+	ids := func(ready []*domain.Task) []string {
+		var got []string
+		for _, task := range ready {
+			got = append(got, task.ID.String())
+		}
+		return got
+	}
+
+	sched := NewWithPriorities(tasks, map[string]bool{}, map[string]int{"analytics": 1})
+
+	// Unlimited by default
+	want := []string{"billing/API01", "billing/CLI01", "billing/TUI01", "pricing/E00", "pricing/E01", "tech/E00", "tech/E01"}
+	if got := ids(sched.GetReadyTasksExcluding(10, inProgress)); !slices.Equal(got, want) {
+		t.Errorf("unlimited: ready = %v, want %v", got, want)
+	}
+
+	// Running tasks use up their module's slots; analytics still waits for its tier
+	sched.SetMaxPerModule(2)
+	want = []string{"billing/API01", "billing/CLI01", "pricing/E00", "tech/E00"}
+	if got := ids(sched.GetReadyTasksExcluding(10, inProgress)); !slices.Equal(got, want) {
+		t.Errorf("max 2: ready = %v, want %v", got, want)
+	}
+
+	// Modules at their cap leave the limit to other modules
+	want = []string{"billing/API01", "billing/CLI01", "pricing/E00"}
+	if got := ids(sched.GetReadyTasksExcluding(3, inProgress)); !slices.Equal(got, want) {
+		t.Errorf("max 2, limit 3: ready = %v, want %v", got, want)
+	}
+}
+
 func TestScheduler_GetReadyTasks_Limit(t *testing.T) {
 	tasks := []*domain.Task{
 		{ID: domain.TaskID{Module: "tech", EpicNum: 0}, Status: domain.StatusNotStarted},
@@ -124,6 +240,52 @@ func TestScheduler_WithGroupPriorities(t *testing.T) {
 	}
 }
 
+func TestScheduler_DefaultTierForUnknownModules(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "newcomer", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+	completed := map[string]bool{}
+	priorities := map[string]int{"billing": 1}
+
+	// Without a default tier, unknown modules are tier 0 and run first
+	sched := NewWithPriorities(tasks, completed, priorities)
+	ready := sched.GetReadyTasks(10)
+	if len(ready) != 1 || ready[0].ID.Module != "newcomer" {
+		t.Fatalf("ready = %v, want only newcomer/E00", ready)
+	}
+
+	// With default tier 99, unknown modules wait for explicit tiers
+	sched = NewWithPriorities(tasks, completed, priorities)
+	sched.SetDefaultTier(99)
+
+	if got := sched.TierOf("newcomer"); got != 99 {
+		t.Errorf("TierOf(newcomer) = %d, want 99", got)
+	}
+	if got := sched.TierOf("billing"); got != 1 {
+		t.Errorf("TierOf(billing) = %d, want 1", got)
+	}
+
+	ready = sched.GetReadyTasks(10)
+	if len(ready) != 1 || ready[0].ID.Module != "billing" {
+		t.Fatalf("ready = %v, want only billing/E00", ready)
+	}
+
+	// Once explicit tiers are done, the default tier becomes active
+	tasks[0].Status = domain.StatusComplete
+	completed["billing/E00"] = true
+	sched = NewWithPriorities(tasks, completed, priorities)
+	sched.SetDefaultTier(99)
+
+	if got := sched.ActiveTier(); got != 99 {
+		t.Errorf("ActiveTier() = %d, want 99", got)
+	}
+	ready = sched.GetReadyTasks(10)
+	if len(ready) != 1 || ready[0].ID.Module != "newcomer" {
+		t.Errorf("ready = %v, want only newcomer/E00", ready)
+	}
+}
+
 func TestScheduler_GroupPriorities_TierAdvance(t *testing.T) {
 	tasks := []*domain.Task{
 		{ID: domain.TaskID{Module: "auth", EpicNum: 0}, Status: domain.StatusComplete},
@@ -236,3 +398,124 @@ func TestScheduler_GetActivePriorityTier(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduler_Classify(t *testing.T) {
+	id := func(epic int) domain.TaskID { return domain.TaskID{Module: "tech", EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id(0), Status: domain.StatusComplete},
+		{ID: id(1), Status: domain.StatusInProgress},
+		{ID: id(2), Status: domain.StatusNotStarted}, // Running agent, status not yet updated
+		{ID: id(3), Status: domain.StatusNotStarted},
+		{ID: id(4), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(0)}},
+		{ID: id(5), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(0), id(1)}},
+		{ID: id(6), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(1), id(2)}},
+		{ID: id(7), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(1), id(3)}},
+		{ID: id(8), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(3), id(2)}},
+		{ID: id(9), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{{Module: "billing", EpicNum: 0}}},
+	}
+	completed := map[string]bool{"tech/E00": true}
+	inProgress := map[string]bool{"tech/E02": true}
+
+	sched := New(tasks, completed)
+
+	tests := []struct {
+		task int
+		want BlockReason
+	}{
+		{4, NotBlocked},          // Only completed dependencies
+		{5, BlockedByInProgress}, // In-progress status
+		{6, BlockedByInProgress}, // In-progress status and running agent
+		{7, BlockedByNotStarted}, // One in progress, one not started
+		{8, BlockedByNotStarted}, // Not-started blocker listed first
+		{9, BlockedByNotStarted}, // Unknown dependency
+	}
+	for _, tt := range tests {
+		if got := sched.Classify(tasks[tt.task], inProgress); got != tt.want {
+			t.Errorf("Classify(%s) = %v, want %v", tasks[tt.task].ID, got, tt.want)
+		}
+	}
+
+	soon := sched.UnblockingSoon(inProgress)
+	if len(soon) != 2 || soon[0].ID != id(5) || soon[1].ID != id(6) {
+		var ids []string
+		for _, task := range soon {
+			ids = append(ids, task.ID.String())
+		}
+		t.Errorf("UnblockingSoon = %v, want [tech/E05 tech/E06]", ids)
+	}
+}
+
+func TestScheduler_Only(t *testing.T) {
+	id := func(epic int) domain.TaskID { return domain.TaskID{Module: "tech", EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id(0), Status: domain.StatusComplete},
+		{ID: id(1), Status: domain.StatusNotStarted, Priority: domain.PriorityLow, DependsOn: []domain.TaskID{id(0)}},
+		{ID: id(2), Status: domain.StatusNotStarted, Priority: domain.PriorityHigh},
+		{ID: id(3), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id(1), id(2)}},
+		{ID: id(4), Status: domain.StatusNotStarted},
+		{ID: id(5), Status: domain.StatusNotStarted}, // Running agent
+	}
+	completed := map[string]bool{"tech/E00": true}
+	inProgress := map[string]bool{"tech/E05": true}
+
+	sel := New(tasks, completed).Only(
+		[]string{"tech/E01", "tech/E02", "tech/E03", "tech/E00", "tech/E05", "tech/E01", "tech/E99"}, inProgress)
+
+	// Requested order is kept, and unrequested ready tasks (E04) are not started
+	if len(sel.Ready) != 2 || sel.Ready[0].ID != id(1) || sel.Ready[1].ID != id(2) {
+		var ids []string
+		for _, task := range sel.Ready {
+			ids = append(ids, task.ID.String())
+		}
+		t.Errorf("Ready = %v, want [tech/E01 tech/E02]", ids)
+	}
+	if unmet := sel.Blocked["tech/E03"]; len(sel.Blocked) != 1 || len(unmet) != 2 || unmet[0] != "tech/E01" || unmet[1] != "tech/E02" {
+		t.Errorf("Blocked = %v, want tech/E03 waiting on [tech/E01 tech/E02]", sel.Blocked)
+	}
+	if summary := sel.BlockedSummary(); len(summary) != 1 || summary[0] != "tech/E03 (waits on tech/E01, tech/E02)" {
+		t.Errorf("BlockedSummary = %v", summary)
+	}
+	if len(sel.Skipped) != 2 || sel.Skipped[0] != "tech/E00" || sel.Skipped[1] != "tech/E05" {
+		t.Errorf("Skipped = %v, want [tech/E00 tech/E05]", sel.Skipped)
+	}
+	if len(sel.Unknown) != 1 || sel.Unknown[0] != "tech/E99" {
+		t.Errorf("Unknown = %v, want [tech/E99]", sel.Unknown)
+	}
+}
+
+func TestScheduler_Cycles(t *testing.T) {
+	id := func(module string, epic int) domain.TaskID { return domain.TaskID{Module: module, EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id("tech", 0), Status: domain.StatusNotStarted},
+		// billing/E02 -> billing/E01 -> tech/E01 -> billing/E02
+		{ID: id("billing", 2), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("billing", 1)}},
+		{ID: id("billing", 1), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("tech", 1)}},
+		{ID: id("tech", 1), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("billing", 2), id("tech", 0)}},
+		// A task depending on the cycle is stuck too, but not part of it
+		{ID: id("tech", 2), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("tech", 1)}},
+		// A cycle through a finished task does not stall anything
+		{ID: id("pricing", 1), Status: domain.StatusComplete, DependsOn: []domain.TaskID{id("pricing", 2)}},
+		{ID: id("pricing", 2), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("pricing", 1)}},
+		// Self-dependency
+		{ID: id("zoning", 1), Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("zoning", 1)}},
+	}
+
+	sched := New(tasks, map[string]bool{})
+	var got []string
+	for _, cycle := range sched.Cycles() {
+		got = append(got, cycle.Error())
+	}
+	want := []string{
+		"dependency cycle: billing/E01 -> tech/E01 -> billing/E02 -> billing/E01",
+		"dependency cycle: zoning/E01 -> zoning/E01",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Cycles() = %q, want %q", got, want)
+	}
+
+	// Completing a task of the cycle resolves it
+	sched = New(tasks, map[string]bool{"tech/E01": true, "zoning/E01": true})
+	if cycles := sched.Cycles(); len(cycles) != 0 {
+		t.Errorf("Cycles() = %v, want none once the cycles' tasks are complete", cycles)
+	}
+}
@@ -0,0 +1,180 @@
+package sync
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+// NewTask describes a task to create as an epic file
+type NewTask struct {
+	ID          domain.TaskID
+	Title       string
+	Description string
+	Priority    domain.Priority // PriorityNormal leaves it out of the frontmatter
+	DependsOn   []domain.TaskID
+}
+
+// ErrTaskExists is returned by CreateTask for a task ID already in use
+var ErrTaskExists = errors.New("task already exists")
+
+// epicTemplate is the epic file written for a new task
+var epicTemplate = template.Must(template.New("epic").Parse(`---
+status: not_started
+{{- with .Priority}}
+priority: {{.}}
+{{- end}}
+{{- with .DependsOn}}
+depends_on:
+{{- range .}}
+  - {{.}}
+{{- end}}
+{{- end}}
+---
+
+# {{.Title}}
+{{with .Description}}
+{{.}}
+{{end -}}
+`))
+
+// moduleNameRegex matches module names task IDs accept
+var moduleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// slugRegex matches the runs of characters replaced by "-" in file names
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Validate checks the task has a well-formed ID and a title
+func (t NewTask) Validate() error {
+	if !moduleNameRegex.MatchString(t.ID.Module) {
+		return fmt.Errorf("invalid module %q: use lowercase letters, digits and dashes", t.ID.Module)
+	}
+	if t.ID.Prefix != "" && strings.ToUpper(t.ID.Prefix) != t.ID.Prefix {
+		return fmt.Errorf("invalid prefix %q: use uppercase letters", t.ID.Prefix)
+	}
+	if t.ID.EpicNum < 0 {
+		return fmt.Errorf("invalid epic number %d", t.ID.EpicNum)
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if strings.ContainsAny(t.Title, "\r\n") {
+		return fmt.Errorf("title must be a single line")
+	}
+	for _, dep := range t.DependsOn {
+		if dep == t.ID {
+			return fmt.Errorf("%s cannot depend on itself", t.ID)
+		}
+	}
+	return nil
+}
+
+// EpicFileName returns the name of the task's epic file, e.g.
+// "epic-03-invoice-export.md"
+func (t NewTask) EpicFileName() string {
+	slug := strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(t.Title), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "task"
+	}
+	if t.ID.Prefix != "" {
+		return fmt.Sprintf("epic-%s-%02d-%s.md", strings.ToLower(t.ID.Prefix), t.ID.EpicNum, slug)
+	}
+	return fmt.Sprintf("epic-%02d-%s.md", t.ID.EpicNum, slug)
+}
+
+// RenderEpic returns the epic file content for the task
+func (t NewTask) RenderEpic() ([]byte, error) {
+	var buf bytes.Buffer
+	err := epicTemplate.Execute(&buf, struct {
+		Title       string
+		Description string
+		Priority    domain.Priority
+		DependsOn   []domain.TaskID
+	}{
+		Title:       strings.TrimSpace(t.Title),
+		Description: strings.TrimSpace(t.Description),
+		Priority:    t.Priority,
+		DependsOn:   t.DependsOn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckTaskIDFree returns ErrTaskExists if an epic file in the plans
+// directory or a task in the store, archived ones included, has the ID
+func (s *Syncer) CheckTaskIDFree(store *taskstore.Store, id domain.TaskID) error {
+	existing, err := parser.ParseTask(s.plansDir, id)
+	if err != nil {
+		return fmt.Errorf("checking epic files: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("%s: %w in %s", id, ErrTaskExists, existing.FilePath)
+	}
+
+	if store != nil {
+		_, err := store.GetTask(id.String())
+		if err == nil {
+			return fmt.Errorf("%s: %w in the database", id, ErrTaskExists)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("checking database: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateTask writes the epic file of a new task into its module directory
+// and, with a store, syncs the task into it. It returns the epic file's path.
+func (s *Syncer) CreateTask(store *taskstore.Store, t NewTask) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	if err := s.CheckTaskIDFree(store, t.ID); err != nil {
+		return "", err
+	}
+
+	content, err := t.RenderEpic()
+	if err != nil {
+		return "", fmt.Errorf("rendering epic: %w", err)
+	}
+
+	dir := filepath.Join(s.plansDir, t.ID.Module)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, t.EpicFileName())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	if store != nil {
+		if _, err := s.SyncOne(store, t.ID.String()); err != nil {
+			return path, fmt.Errorf("syncing %s: %w", t.ID, err)
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,164 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+)
+
+func TestNewTask_RenderEpicRoundTrips(t *testing.T) {
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "billing")
+	os.MkdirAll(moduleDir, 0755)
+
+	nt := NewTask{
+		ID:          domain.TaskID{Module: "billing", EpicNum: 3},
+		Title:       "Invoice export",
+		Description: "Export invoices as CSV.",
+		Priority:    domain.PriorityHigh,
+		DependsOn:   []domain.TaskID{{Module: "billing", EpicNum: 1}, {Module: "core", EpicNum: 2}},
+	}
+	if got := nt.EpicFileName(); got != "epic-03-invoice-export.md" {
+		t.Errorf("EpicFileName() = %q", got)
+	}
+
+	content, err := nt.RenderEpic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(moduleDir, nt.EpicFileName())
+	os.WriteFile(path, content, 0644)
+
+	task, err := parser.ParseEpicFile(path)
+	if err != nil {
+		t.Fatalf("generated epic does not parse: %v\n%s", err, content)
+	}
+	if task.ID != nt.ID {
+		t.Errorf("ID = %s, want %s", task.ID, nt.ID)
+	}
+	if task.Title != nt.Title {
+		t.Errorf("Title = %q, want %q", task.Title, nt.Title)
+	}
+	if task.Description != nt.Description {
+		t.Errorf("Description = %q, want %q", task.Description, nt.Description)
+	}
+	if task.Status != domain.StatusNotStarted {
+		t.Errorf("Status = %s, want not_started", task.Status)
+	}
+	if task.Priority != domain.PriorityHigh {
+		t.Errorf("Priority = %q, want high", task.Priority)
+	}
+	if !reflect.DeepEqual(task.DependsOn, nt.DependsOn) {
+		t.Errorf("DependsOn = %v, want %v", task.DependsOn, nt.DependsOn)
+	}
+}
+
+func TestNewTask_RenderEpicMinimal(t *testing.T) {
+	nt := NewTask{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "Setup"}
+	content, err := nt.RenderEpic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\nstatus: not_started\n---\n\n# Setup\n"
+	if string(content) != want {
+		t.Errorf("RenderEpic() = %q, want %q", content, want)
+	}
+}
+
+func TestNewTask_EpicFileNamePrefixed(t *testing.T) {
+	nt := NewTask{ID: domain.TaskID{Module: "billing", Prefix: "UI", EpicNum: 7}, Title: "Dark mode!"}
+	if got := nt.EpicFileName(); got != "epic-ui-07-dark-mode.md" {
+		t.Errorf("EpicFileName() = %q", got)
+	}
+}
+
+func TestSyncer_CreateTask(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	os.MkdirAll(plansDir, 0755)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	nt := NewTask{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "Setup"}
+	path, err := syncer.CreateTask(store, nt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(plansDir, "billing", "epic-01-setup.md") {
+		t.Errorf("path = %s", path)
+	}
+
+	task, err := store.GetTask("billing/E01")
+	if err != nil {
+		t.Fatalf("task not synced into the store: %v", err)
+	}
+	if task.Title != "Setup" || task.Status != domain.StatusNotStarted {
+		t.Errorf("stored task = %+v", task)
+	}
+
+	// The same ID is rejected, even under another title
+	nt.Title = "Other"
+	if _, err := syncer.CreateTask(store, nt); !errors.Is(err, ErrTaskExists) {
+		t.Errorf("duplicate ID: err = %v, want ErrTaskExists", err)
+	}
+	if _, err := os.Stat(filepath.Join(plansDir, "billing", "epic-01-other.md")); !os.IsNotExist(err) {
+		t.Error("duplicate task wrote an epic file")
+	}
+}
+
+func TestSyncer_CheckTaskIDFree(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "billing")
+	os.MkdirAll(moduleDir, 0755)
+	os.WriteFile(filepath.Join(moduleDir, "epic-01-setup.md"), []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	// Only in a file
+	if err := syncer.CheckTaskIDFree(store, domain.TaskID{Module: "billing", EpicNum: 1}); !errors.Is(err, ErrTaskExists) {
+		t.Errorf("epic file: err = %v, want ErrTaskExists", err)
+	}
+
+	// Only in the store, e.g. archived after its file was removed
+	store.UpsertTask(&domain.Task{
+		ID:     domain.TaskID{Module: "billing", EpicNum: 2},
+		Title:  "API",
+		Status: domain.StatusNotStarted,
+	})
+	if err := syncer.CheckTaskIDFree(store, domain.TaskID{Module: "billing", EpicNum: 2}); !errors.Is(err, ErrTaskExists) {
+		t.Errorf("stored task: err = %v, want ErrTaskExists", err)
+	}
+
+	if err := syncer.CheckTaskIDFree(store, domain.TaskID{Module: "billing", EpicNum: 3}); err != nil {
+		t.Errorf("free ID: err = %v", err)
+	}
+}
+
+func TestNewTask_Validate(t *testing.T) {
+	id := domain.TaskID{Module: "billing", EpicNum: 1}
+	tests := []struct {
+		name string
+		task NewTask
+	}{
+		{"bad module", NewTask{ID: domain.TaskID{Module: "Billing", EpicNum: 1}, Title: "Setup"}},
+		{"no title", NewTask{ID: id, Title: "  "}},
+		{"multiline title", NewTask{ID: id, Title: "Setup\nmore"}},
+		{"self dependency", NewTask{ID: id, Title: "Setup", DependsOn: []domain.TaskID{id}}},
+	}
+	for _, tt := range tests {
+		if err := tt.task.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
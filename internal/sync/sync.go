@@ -6,8 +6,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	gosync "sync"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
@@ -16,9 +18,32 @@ import (
 
 // Syncer handles status synchronization back to markdown files
 type Syncer struct {
-	plansDir    string
-	projectRoot string
-	gitMu       gosync.Mutex // Mutex for git operations to prevent concurrent access
+	plansDir          string
+	projectRoot       string
+	removedTaskAction RemovedTaskAction // What TwoWaySync does with tasks whose epic file was deleted
+	autoCommit        bool              // SyncTaskStatus commits and pushes the status change
+	dryRun            bool              // TwoWaySync reports its changes without applying them
+	gitMu             gosync.Mutex      // Mutex for git operations to prevent concurrent access
+}
+
+// RemovedTaskAction is what TwoWaySync does with tasks whose epic file was deleted
+type RemovedTaskAction string
+
+const (
+	ArchiveRemovedTasks RemovedTaskAction = "archive" // Hide the task but keep it in the database (default)
+	DeleteRemovedTasks  RemovedTaskAction = "delete"  // Delete the task from the database
+)
+
+// ParseRemovedTaskAction parses a removed_epic_action config value ("" means archive)
+func ParseRemovedTaskAction(s string) (RemovedTaskAction, error) {
+	switch RemovedTaskAction(s) {
+	case "", ArchiveRemovedTasks:
+		return ArchiveRemovedTasks, nil
+	case DeleteRemovedTasks:
+		return DeleteRemovedTasks, nil
+	default:
+		return "", fmt.Errorf("invalid removed epic action '%s': must be '%s' or '%s'", s, ArchiveRemovedTasks, DeleteRemovedTasks)
+	}
 }
 
 // New creates a new Syncer
@@ -28,9 +53,28 @@ func New(plansDir string) *Syncer {
 	return &Syncer{
 		plansDir:    plansDir,
 		projectRoot: projectRoot,
+		autoCommit:  true,
 	}
 }
 
+// SetRemovedTaskAction sets what TwoWaySync does with tasks whose epic file was deleted
+func (s *Syncer) SetRemovedTaskAction(action RemovedTaskAction) {
+	s.removedTaskAction = action
+}
+
+// SetAutoCommit sets whether SyncTaskStatus pulls, commits and pushes. When
+// disabled, status changes are only written to the epic file and README and
+// left uncommitted.
+func (s *Syncer) SetAutoCommit(enabled bool) {
+	s.autoCommit = enabled
+}
+
+// SetDryRun sets whether TwoWaySync only reports what it would change. The
+// result lists the same changes and conflicts, but nothing is written.
+func (s *Syncer) SetDryRun(enabled bool) {
+	s.dryRun = enabled
+}
+
 // StatusEmoji returns the emoji for a task status
 func StatusEmoji(status domain.TaskStatus) string {
 	switch status {
@@ -304,11 +348,7 @@ func (s *Syncer) gitCommitAndPushLocked(taskID domain.TaskID, status domain.Task
 	}
 
 	// Commit
-	statusStr := "in_progress"
-	if status == domain.StatusComplete {
-		statusStr = "complete"
-	}
-	commitMsg := fmt.Sprintf("chore: update %s status to %s", taskID.String(), statusStr)
+	commitMsg := fmt.Sprintf("chore: update %s status to %s", taskID.String(), status)
 
 	cmd = exec.Command("git", "commit", "-m", commitMsg)
 	cmd.Dir = root
@@ -335,15 +375,18 @@ func (s *Syncer) gitCommitAndPushLocked(taskID domain.TaskID, status domain.Task
 }
 
 // SyncTaskStatus atomically syncs task status: pull, update files, commit, push
-// This holds the mutex for the entire operation to prevent race conditions
+// This holds the mutex for the entire operation to prevent race conditions.
+// With auto-commit disabled only the files are updated.
 func (s *Syncer) SyncTaskStatus(taskID domain.TaskID, status domain.TaskStatus, epicFilePath string) error {
 	s.gitMu.Lock()
 	defer s.gitMu.Unlock()
 
 	// 1. Pull latest changes (with stash to handle any uncommitted changes)
-	if err := s.gitPullLocked(); err != nil {
-		// Log but continue - we'll try to commit our changes anyway
-		fmt.Printf("Warning: git pull failed: %v\n", err)
+	if s.autoCommit {
+		if err := s.gitPullLocked(); err != nil {
+			// Log but continue - we'll try to commit our changes anyway
+			fmt.Printf("Warning: git pull failed: %v\n", err)
+		}
 	}
 
 	// 2. Update epic frontmatter
@@ -359,14 +402,97 @@ func (s *Syncer) SyncTaskStatus(taskID domain.TaskID, status domain.TaskStatus,
 	}
 
 	// 4. Commit and push
+	if !s.autoCommit {
+		return nil
+	}
 	return s.gitCommitAndPushLocked(taskID, status, epicFilePath)
 }
 
+// ReopenTask sets a completed task back to not_started in the store, which
+// records a reopened event, and syncs the new status to the epic file and
+// README. The task is reopened even if the markdown sync fails.
+func (s *Syncer) ReopenTask(store *taskstore.Store, taskID string) (*domain.Task, error) {
+	if err := store.ReopenTask(taskID); err != nil {
+		return nil, err
+	}
+	task, err := store.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SyncTaskStatus(task.ID, task.Status, task.FilePath); err != nil {
+		return task, fmt.Errorf("reopened %s, but syncing markdown failed: %w", taskID, err)
+	}
+	return task, nil
+}
+
 // SyncResult contains the result of a two-way sync operation
 type SyncResult struct {
 	MarkdownToDBCount int            // Tasks updated in DB from markdown
 	DBToMarkdownCount int            // Tasks updated in markdown from DB
 	Conflicts         []SyncConflict // Mismatches requiring resolution
+	Changes           []SyncChange   // Tasks whose data actually changed, sorted by task ID
+	Removed           []string       // Tasks archived or deleted because their epic file was deleted, sorted
+	DryRun            bool           // Nothing was written; the result is what a sync would do
+}
+
+// SyncDirection is the direction in which a sync copied task data
+type SyncDirection string
+
+const (
+	MarkdownToDB SyncDirection = "markdown → db"
+	DBToMarkdown SyncDirection = "db → markdown"
+)
+
+// SyncChange describes how a sync changed one task
+type SyncChange struct {
+	TaskID    string
+	Direction SyncDirection
+	Fields    []string // Changed fields, e.g. "title" or "status: complete → not_started"; "added" for new tasks
+}
+
+// taskChanges lists the fields that differ between the stored and the parsed
+// version of a task, ignoring the status, which sync never overwrites
+func taskChanges(old, updated *domain.Task) []string {
+	var fields []string
+	if old.Title != updated.Title {
+		fields = append(fields, "title")
+	}
+	if old.Description != updated.Description {
+		fields = append(fields, "description")
+	}
+	if old.Priority != updated.Priority {
+		fields = append(fields, "priority")
+	}
+	if !sameTaskIDs(old.DependsOn, updated.DependsOn) {
+		fields = append(fields, "dependencies")
+	}
+	if old.NeedsReview != updated.NeedsReview {
+		fields = append(fields, "needs review")
+	}
+	if old.FilePath != updated.FilePath {
+		fields = append(fields, "file")
+	}
+	return fields
+}
+
+func sameTaskIDs(a, b []domain.TaskID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// statusChange describes a status field change in SyncChange.Fields
+func statusChange(from, to domain.TaskStatus) string {
+	if from == "" {
+		return fmt.Sprintf("status: %s", to)
+	}
+	return fmt.Sprintf("status: %s → %s", from, to)
 }
 
 // SyncConflict represents a status mismatch between DB and markdown
@@ -377,57 +503,209 @@ type SyncConflict struct {
 	EpicFilePath   string
 }
 
-// ResolveConflicts applies user resolutions to sync conflicts.
-// resolutions maps taskID to "db" or "markdown" indicating which source wins.
-func (s *Syncer) ResolveConflicts(store *taskstore.Store, resolutions map[string]string) error {
+// ResolveConflicts applies user resolutions to sync conflicts and returns the
+// status changes it made, recording each in the sync audit log. resolutions
+// maps taskID to "db" or "markdown" indicating which source wins.
+func (s *Syncer) ResolveConflicts(store *taskstore.Store, resolutions map[string]string) ([]SyncChange, error) {
+	var changes []SyncChange
 	for taskID, resolution := range resolutions {
 		// Parse task ID
 		tid, err := domain.ParseTaskID(taskID)
 		if err != nil {
-			return fmt.Errorf("parsing task ID %s: %w", taskID, err)
+			return changes, fmt.Errorf("parsing task ID %s: %w", taskID, err)
 		}
 
 		// Get task from DB
 		dbTask, err := store.GetTask(taskID)
 		if err != nil {
-			return fmt.Errorf("getting task %s from DB: %w", taskID, err)
+			return changes, fmt.Errorf("getting task %s from DB: %w", taskID, err)
 		}
 
 		switch resolution {
 		case "db":
 			// DB wins: update markdown to match DB
 			if dbTask.FilePath != "" {
+				mdStatus := s.epicStatus(dbTask.FilePath)
 				if err := s.UpdateEpicFrontmatter(dbTask.FilePath, dbTask.Status); err != nil {
-					return fmt.Errorf("updating epic %s: %w", taskID, err)
+					return changes, fmt.Errorf("updating epic %s: %w", taskID, err)
 				}
 				if err := s.UpdateTaskStatus(tid, dbTask.Status); err != nil {
-					return fmt.Errorf("updating README for %s: %w", taskID, err)
+					return changes, fmt.Errorf("updating README for %s: %w", taskID, err)
+				}
+				if err := recordSync(store, taskID, DBToMarkdown, mdStatus, dbTask.Status, true); err != nil {
+					return changes, err
 				}
+				changes = append(changes, SyncChange{
+					TaskID:    taskID,
+					Direction: DBToMarkdown,
+					Fields:    []string{statusChange(mdStatus, dbTask.Status)},
+				})
 			}
 
 		case "markdown":
 			// Markdown wins: update DB to match markdown
 			mdTasks, err := parser.ParsePlansDir(s.plansDir)
 			if err != nil {
-				return fmt.Errorf("parsing plans: %w", err)
+				return changes, fmt.Errorf("parsing plans: %w", err)
 			}
 			for _, mdTask := range mdTasks {
 				if mdTask.ID.String() == taskID {
 					if err := store.UpdateTaskStatus(taskID, mdTask.Status); err != nil {
-						return fmt.Errorf("updating DB for %s: %w", taskID, err)
+						return changes, fmt.Errorf("updating DB for %s: %w", taskID, err)
 					}
+					if err := recordSync(store, taskID, MarkdownToDB, dbTask.Status, mdTask.Status, true); err != nil {
+						return changes, err
+					}
+					changes = append(changes, SyncChange{
+						TaskID:    taskID,
+						Direction: MarkdownToDB,
+						Fields:    []string{statusChange(dbTask.Status, mdTask.Status)},
+					})
 					break
 				}
 			}
 
 		default:
-			return fmt.Errorf("invalid resolution %q for %s (must be 'db' or 'markdown')", resolution, taskID)
+			return changes, fmt.Errorf("invalid resolution %q for %s (must be 'db' or 'markdown')", resolution, taskID)
+		}
+	}
+
+	sortChanges(changes)
+	return changes, nil
+}
+
+// ConflictStrategy picks the winning side of sync conflicts without asking
+type ConflictStrategy string
+
+const (
+	ResolveNewest   ConflictStrategy = "newest"   // The side changed last: epic file mtime vs the task's updated_at
+	ResolveDB       ConflictStrategy = "db"       // The database always wins
+	ResolveMarkdown ConflictStrategy = "markdown" // Markdown always wins
+)
+
+// ParseConflictStrategy parses a --resolve value
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(s); strategy {
+	case ResolveNewest, ResolveDB, ResolveMarkdown:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid resolution strategy '%s': must be '%s', '%s' or '%s'", s, ResolveNewest, ResolveDB, ResolveMarkdown)
+	}
+}
+
+// ChooseResolutions decides conflicts by a strategy and returns the
+// resolutions for ResolveConflicts. Conflicts the strategy cannot decide,
+// e.g. with equal timestamps or an unreadable epic file, are left out for
+// manual resolution.
+func (s *Syncer) ChooseResolutions(store *taskstore.Store, conflicts []SyncConflict, strategy ConflictStrategy) map[string]string {
+	resolutions := make(map[string]string)
+	for _, c := range conflicts {
+		switch strategy {
+		case ResolveDB, ResolveMarkdown:
+			resolutions[c.TaskID] = string(strategy)
+		case ResolveNewest:
+			if winner := newerSide(store, c); winner != "" {
+				resolutions[c.TaskID] = winner
+			}
+		}
+	}
+	return resolutions
+}
+
+// newerSide returns "db" or "markdown", whichever changed last, or "" if
+// that cannot be told. Times are compared in whole seconds, as file systems
+// and the database keep them at different precision.
+func newerSide(store *taskstore.Store, c SyncConflict) string {
+	info, err := os.Stat(c.EpicFilePath)
+	if err != nil {
+		return ""
+	}
+	task, err := store.GetTask(c.TaskID)
+	if err != nil || task == nil || task.UpdatedAt.IsZero() {
+		return ""
+	}
+
+	mdTime := info.ModTime().Truncate(time.Second)
+	dbTime := task.UpdatedAt.Truncate(time.Second)
+	switch {
+	case dbTime.After(mdTime):
+		return "db"
+	case mdTime.After(dbTime):
+		return "markdown"
+	default:
+		return ""
+	}
+}
+
+// ResolveConflictsByStrategy resolves the conflicts a strategy can decide
+// like ResolveConflicts, and returns the changes it made and the conflicts
+// left for manual resolution.
+func (s *Syncer) ResolveConflictsByStrategy(store *taskstore.Store, conflicts []SyncConflict, strategy ConflictStrategy) ([]SyncChange, []SyncConflict, error) {
+	resolutions := s.ChooseResolutions(store, conflicts, strategy)
+	var unresolved []SyncConflict
+	for _, c := range conflicts {
+		if resolutions[c.TaskID] == "" {
+			unresolved = append(unresolved, c)
 		}
 	}
+	changes, err := s.ResolveConflicts(store, resolutions)
+	return changes, unresolved, err
+}
 
+// recordSync adds a status reconciliation to the store's sync audit log;
+// resolution tells that it resolved a conflict
+func recordSync(store *taskstore.Store, taskID string, direction SyncDirection, from, to domain.TaskStatus, resolution bool) error {
+	err := store.RecordSyncAudit(taskstore.SyncAuditEntry{
+		TaskID:     taskID,
+		Direction:  string(direction),
+		OldStatus:  from,
+		NewStatus:  to,
+		Resolution: resolution,
+	})
+	if err != nil {
+		return fmt.Errorf("recording sync of %s: %w", taskID, err)
+	}
 	return nil
 }
 
+// epicStatus returns the status in an epic file's frontmatter ("" if unknown)
+func (s *Syncer) epicStatus(epicPath string) domain.TaskStatus {
+	task, err := parser.ParseEpicFile(epicPath)
+	if err != nil {
+		return ""
+	}
+	return task.Status
+}
+
+// epicFileDeleted reports whether a task's epic file no longer exists
+func epicFileDeleted(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// removeTask archives or deletes a task whose epic file was deleted, as
+// configured, and returns the SyncChange field describing what it did
+func (s *Syncer) removeTask(store *taskstore.Store, id string) (string, error) {
+	field, remove := "archived (epic file removed)", store.ArchiveTask
+	if s.removedTaskAction == DeleteRemovedTasks {
+		field, remove = "deleted (epic file removed)", store.DeleteTask
+	}
+	if s.dryRun {
+		return field, nil
+	}
+	return field, remove(id)
+}
+
+// sortChanges orders changes by task ID, as map iteration leaves them random
+func sortChanges(changes []SyncChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].TaskID < changes[j].TaskID
+	})
+}
+
 // SyncMarkdownToDB parses all markdown files and upserts them to the database.
 // Returns the number of tasks synced.
 func (s *Syncer) SyncMarkdownToDB(store *taskstore.Store) (int, error) {
@@ -478,9 +756,12 @@ func (s *Syncer) SyncDBToMarkdown(store *taskstore.Store) (int, error) {
 }
 
 // TwoWaySync performs a two-way sync between markdown files and the database.
-// Returns conflicts that need manual resolution.
+// Returns conflicts that need manual resolution. The status of tasks it adds
+// is recorded in the sync audit log; existing tasks keep their DB status. In
+// dry-run mode the database is left as is and the result describes what the
+// sync would do.
 func (s *Syncer) TwoWaySync(store *taskstore.Store) (*SyncResult, error) {
-	result := &SyncResult{}
+	result := &SyncResult{DryRun: s.dryRun}
 
 	// 1. Parse all markdown files to get their statuses
 	mdTasks, err := parser.ParsePlansDir(s.plansDir)
@@ -510,21 +791,22 @@ func (s *Syncer) TwoWaySync(store *taskstore.Store) (*SyncResult, error) {
 	// Tasks only in markdown -> sync to DB
 	for id, mdTask := range mdStatuses {
 		if _, exists := dbStatuses[id]; !exists {
-			if err := store.UpsertTask(mdTask); err != nil {
-				return nil, fmt.Errorf("upserting %s: %w", id, err)
+			if err := s.addTask(store, result, mdTask); err != nil {
+				return nil, err
 			}
-			result.MarkdownToDBCount++
 		}
 	}
 
-	// Tasks only in DB -> sync to markdown (update frontmatter)
+	// Tasks only in DB whose epic file was deleted -> archive or delete them
 	for id, dbTask := range dbStatuses {
-		if _, exists := mdStatuses[id]; !exists {
-			// Task in DB but not in markdown - skip (file may have been deleted)
-			_ = dbTask // suppress unused variable warning
+		if _, exists := mdStatuses[id]; exists || !epicFileDeleted(dbTask.FilePath) {
 			continue
 		}
+		if err := s.removeDeletedTask(store, result, id); err != nil {
+			return nil, err
+		}
 	}
+	sort.Strings(result.Removed)
 
 	// Tasks in both -> update DB with markdown data (preserving DB status) and check for conflicts
 	for id, dbTask := range dbStatuses {
@@ -532,26 +814,199 @@ func (s *Syncer) TwoWaySync(store *taskstore.Store) (*SyncResult, error) {
 		if !exists {
 			continue
 		}
-
-		// Always update DB with markdown data (dependencies, title, priority, etc.)
-		// but preserve the DB status since agents may have updated it
-		taskToUpsert := *mdTask
-		taskToUpsert.Status = dbTask.Status // Preserve DB status
-		if err := store.UpsertTask(&taskToUpsert); err != nil {
-			return nil, fmt.Errorf("updating %s: %w", id, err)
+		if err := s.updateTask(store, result, dbTask, mdTask); err != nil {
+			return nil, err
 		}
-		result.MarkdownToDBCount++
-
-		// Flag status conflicts for user resolution
-		if dbTask.Status != mdTask.Status {
-			result.Conflicts = append(result.Conflicts, SyncConflict{
-				TaskID:         id,
-				DBStatus:       string(dbTask.Status),
-				MarkdownStatus: string(mdTask.Status),
-				EpicFilePath:   mdTask.FilePath,
-			})
+	}
+
+	sortChanges(result.Changes)
+	return result, nil
+}
+
+// SyncOne syncs a single task like TwoWaySync, parsing only its epic file. A
+// task only in markdown is added to the database; a task only in the
+// database is archived or deleted if its epic file was deleted, and otherwise
+// left as is. It fails if the task is in neither.
+func (s *Syncer) SyncOne(store *taskstore.Store, taskID string) (*SyncResult, error) {
+	id, err := domain.ParseTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	mdTask, err := parser.ParseTask(s.plansDir, id)
+	if err != nil {
+		return nil, fmt.Errorf("parsing epic of %s: %w", id, err)
+	}
+
+	// Listed like in TwoWaySync, so archived tasks count as not in the DB
+	dbTasks, err := store.ListTasks(taskstore.ListOptions{Module: id.Module})
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+	var dbTask *domain.Task
+	for _, t := range dbTasks {
+		if t.ID == id {
+			dbTask = t
 		}
 	}
 
+	result := &SyncResult{DryRun: s.dryRun}
+	switch {
+	case mdTask != nil && dbTask != nil:
+		err = s.updateTask(store, result, dbTask, mdTask)
+	case mdTask != nil:
+		err = s.addTask(store, result, mdTask)
+	case dbTask != nil:
+		if epicFileDeleted(dbTask.FilePath) {
+			err = s.removeDeletedTask(store, result, id.String())
+		}
+	default:
+		return nil, fmt.Errorf("task %s not found in markdown or database", id)
+	}
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+// addTask adds a task found only in markdown to the database
+func (s *Syncer) addTask(store *taskstore.Store, result *SyncResult, mdTask *domain.Task) error {
+	id := mdTask.ID.String()
+	if err := s.upsertTask(store, mdTask); err != nil {
+		return fmt.Errorf("upserting %s: %w", id, err)
+	}
+	if !s.dryRun {
+		if err := recordSync(store, id, MarkdownToDB, "", mdTask.Status, false); err != nil {
+			return err
+		}
+	}
+	result.MarkdownToDBCount++
+	result.Changes = append(result.Changes, SyncChange{TaskID: id, Direction: MarkdownToDB, Fields: []string{"added"}})
+	return nil
+}
+
+// removeDeletedTask archives or deletes a task whose epic file was deleted
+func (s *Syncer) removeDeletedTask(store *taskstore.Store, result *SyncResult, id string) error {
+	field, err := s.removeTask(store, id)
+	if err != nil {
+		return fmt.Errorf("removing %s: %w", id, err)
+	}
+	result.Removed = append(result.Removed, id)
+	result.Changes = append(result.Changes, SyncChange{TaskID: id, Direction: MarkdownToDB, Fields: []string{field}})
+	return nil
+}
+
+// updateTask copies the markdown data of a task in both places to the
+// database and flags a status conflict
+func (s *Syncer) updateTask(store *taskstore.Store, result *SyncResult, dbTask, mdTask *domain.Task) error {
+	id := dbTask.ID.String()
+
+	// Always update DB with markdown data (dependencies, title, priority, etc.)
+	// but preserve the DB status since agents may have updated it
+	taskToUpsert := *mdTask
+	taskToUpsert.Status = dbTask.Status       // Preserve DB status
+	taskToUpsert.UpdatedAt = dbTask.UpdatedAt // Keep when the DB status last changed, for ResolveNewest
+	if err := s.upsertTask(store, &taskToUpsert); err != nil {
+		return fmt.Errorf("updating %s: %w", id, err)
+	}
+	result.MarkdownToDBCount++
+	if fields := taskChanges(dbTask, mdTask); len(fields) > 0 {
+		result.Changes = append(result.Changes, SyncChange{TaskID: id, Direction: MarkdownToDB, Fields: fields})
+	}
+
+	// Flag status conflicts for user resolution
+	if dbTask.Status != mdTask.Status {
+		result.Conflicts = append(result.Conflicts, SyncConflict{
+			TaskID:         id,
+			DBStatus:       string(dbTask.Status),
+			MarkdownStatus: string(mdTask.Status),
+			EpicFilePath:   mdTask.FilePath,
+		})
+	}
+	return nil
+}
+
+// upsertTask stores a task unless in dry-run mode
+func (s *Syncer) upsertTask(store *taskstore.Store, task *domain.Task) error {
+	if s.dryRun {
+		return nil
+	}
+	return store.UpsertTask(task)
+}
+
+// Agreement describes how a task in the database compares to its epic file
+type Agreement string
+
+const (
+	InSync          Agreement = "in sync"
+	StatusConflict  Agreement = "conflict"         // Statuses differ; needs resolution
+	MarkdownChanged Agreement = "markdown changed" // Epic file edited; sync copies the fields to the DB
+	OnlyInMarkdown  Agreement = "only in markdown" // New epic; sync adds the task
+	OnlyInDB        Agreement = "only in db"       // No parsed epic file, e.g. deleted or ignored
+)
+
+// TaskComparison is the state of one task in a SyncStatus report
+type TaskComparison struct {
+	TaskID         string
+	Agreement      Agreement
+	DBStatus       domain.TaskStatus // "" if only in markdown
+	MarkdownStatus domain.TaskStatus // "" if only in the DB
+	Fields         []string          // Differing fields besides the status, or why a task is only in the DB
+}
+
+// SyncStatus compares every task in the database with its epic file without
+// changing either, and returns the comparisons sorted by task ID. Archived
+// tasks are not included.
+func (s *Syncer) SyncStatus(store *taskstore.Store) ([]TaskComparison, error) {
+	mdTasks, err := parser.ParsePlansDir(s.plansDir)
+	if err != nil {
+		return nil, fmt.Errorf("parsing plans: %w", err)
+	}
+	dbTasks, err := store.ListTasks(taskstore.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+
+	mdByID := make(map[string]*domain.Task)
+	for _, t := range mdTasks {
+		mdByID[t.ID.String()] = t
+	}
+
+	var report []TaskComparison
+	for _, dbTask := range dbTasks {
+		id := dbTask.ID.String()
+		c := TaskComparison{TaskID: id, DBStatus: dbTask.Status}
+
+		mdTask, exists := mdByID[id]
+		delete(mdByID, id)
+		if !exists {
+			c.Agreement = OnlyInDB
+			if epicFileDeleted(dbTask.FilePath) {
+				c.Fields = []string{"epic file deleted"}
+			}
+			report = append(report, c)
+			continue
+		}
+
+		c.MarkdownStatus = mdTask.Status
+		c.Fields = taskChanges(dbTask, mdTask)
+		switch {
+		case dbTask.Status != mdTask.Status:
+			c.Agreement = StatusConflict
+		case len(c.Fields) > 0:
+			c.Agreement = MarkdownChanged
+		default:
+			c.Agreement = InSync
+		}
+		report = append(report, c)
+	}
+
+	for id, mdTask := range mdByID {
+		report = append(report, TaskComparison{TaskID: id, Agreement: OnlyInMarkdown, MarkdownStatus: mdTask.Status})
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].TaskID < report[j].TaskID
+	})
+	return report, nil
+}
@@ -1,12 +1,17 @@
 package sync
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/scheduler"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
 )
 
@@ -282,6 +287,313 @@ status: complete
 	}
 }
 
+func TestTwoWaySync_RecordsChanges(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	setupPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(setupPath, []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+	apiPath := filepath.Join(moduleDir, "epic-02-api.md")
+	os.WriteFile(apiPath, []byte("---\nstatus: not_started\n---\n\n# E02: API\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	// First sync adds every task
+	result, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", result.Changes)
+	}
+	for i, id := range []string{"technical/E01", "technical/E02"} {
+		c := result.Changes[i]
+		if c.TaskID != id || c.Direction != MarkdownToDB || strings.Join(c.Fields, ",") != "added" {
+			t.Errorf("Changes[%d] = %+v, want %s added from markdown", i, c, id)
+		}
+	}
+
+	// Only the edited task is reported, with the fields that changed
+	os.WriteFile(apiPath, []byte("---\nstatus: not_started\npriority: high\n---\n\n# E02: REST API\n"), 0644)
+	result, err = syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %+v", result.Changes)
+	}
+	if c := result.Changes[0]; c.TaskID != "technical/E02" || strings.Join(c.Fields, ",") != "title,priority" {
+		t.Errorf("change = %+v, want technical/E02 title,priority", c)
+	}
+
+	// Nothing changed since the last sync
+	result, err = syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("expected no changes, got %+v", result.Changes)
+	}
+}
+
+func TestTwoWaySync_DryRun(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	setupPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(setupPath, []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+	apiPath := filepath.Join(moduleDir, "epic-02-api.md")
+	api := []byte("---\nstatus: not_started\n---\n\n# E02: API\n")
+	os.WriteFile(apiPath, api, 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+	store.UpdateTaskStatus("technical/E02", domain.StatusComplete)
+
+	// An edited, a new and a deleted epic plus a status conflict
+	os.WriteFile(apiPath, []byte("---\nstatus: not_started\n---\n\n# E02: REST API\n"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "epic-03-cli.md"), []byte("---\nstatus: not_started\n---\n\n# E03: CLI\n"), 0644)
+	os.Remove(setupPath)
+	before, _ := store.ListTasks(taskstore.ListOptions{})
+
+	syncer.SetDryRun(true)
+	planned, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !planned.DryRun {
+		t.Error("dry-run result should be marked DryRun")
+	}
+
+	// Nothing was written
+	after, _ := store.ListTasks(taskstore.ListOptions{})
+	if len(after) != len(before) {
+		t.Fatalf("dry run changed the task list: %v -> %v", before, after)
+	}
+	for i := range before {
+		if after[i].ID != before[i].ID || after[i].Title != before[i].Title || after[i].Status != before[i].Status {
+			t.Errorf("dry run changed %s: %+v -> %+v", before[i].ID, before[i], after[i])
+		}
+	}
+	if events, _ := store.ListTaskEvents("technical/E01"); len(events) != 0 {
+		t.Errorf("dry run archived technical/E01: %+v", events)
+	}
+
+	// The real sync does what the dry run reported
+	syncer.SetDryRun(false)
+	applied, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied.DryRun = true
+	if !reflect.DeepEqual(planned, applied) {
+		t.Errorf("dry run = %+v\nsync    = %+v", planned, applied)
+	}
+	if len(planned.Changes) != 3 || len(planned.Conflicts) != 1 || len(planned.Removed) != 1 {
+		t.Errorf("expected 3 changes, 1 conflict and 1 removal, got %+v", planned)
+	}
+}
+
+func TestTwoWaySync_ArchivesDeletedEpic(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	setupPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	setup := []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n")
+	os.WriteFile(setupPath, setup, 0644)
+	os.WriteFile(filepath.Join(moduleDir, "epic-02-api.md"), []byte("---\nstatus: not_started\n---\n\n# E02: API\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting the epic file archives its task on the next sync
+	os.Remove(setupPath)
+	result, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != "technical/E01" {
+		t.Errorf("Removed = %v, want [technical/E01]", result.Removed)
+	}
+	if c := result.Changes[0]; c.TaskID != "technical/E01" || strings.Join(c.Fields, ",") != "archived (epic file removed)" {
+		t.Errorf("Changes = %+v, want technical/E01 archived first", result.Changes)
+	}
+
+	tasks, _ := store.ListTasks(taskstore.ListOptions{})
+	if len(tasks) != 1 || tasks[0].ID.String() != "technical/E02" {
+		t.Errorf("listed tasks = %v, want only technical/E02", tasks)
+	}
+	if task, err := store.GetTask("technical/E01"); err != nil || task == nil {
+		t.Errorf("archived task should remain in the database, got %v, %v", task, err)
+	}
+	events, _ := store.ListTaskEvents("technical/E01")
+	if len(events) != 1 || events[0].Event != taskstore.TaskEventArchived {
+		t.Errorf("events = %+v, want one archived event", events)
+	}
+
+	// An archived task is only reported once
+	result, err = syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("Removed = %v on the following sync, want none", result.Removed)
+	}
+
+	// Restoring the file brings the task back
+	os.WriteFile(setupPath, setup, 0644)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ = store.ListTasks(taskstore.ListOptions{})
+	if len(tasks) != 2 {
+		t.Errorf("expected both tasks after restoring the file, got %v", tasks)
+	}
+}
+
+func TestTwoWaySync_DeletesRemovedEpic(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	setupPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(setupPath, []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+	syncer.SetRemovedTaskAction(DeleteRemovedTasks)
+
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(setupPath)
+	result, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) != 1 {
+		t.Errorf("Removed = %v, want [technical/E01]", result.Removed)
+	}
+
+	tasks, _ := store.ListTasks(taskstore.ListOptions{IncludeArchived: true})
+	if len(tasks) != 0 {
+		t.Errorf("expected the task to be deleted, got %v", tasks)
+	}
+	events, _ := store.ListTaskEvents("technical/E01")
+	if len(events) != 1 || events[0].Event != taskstore.TaskEventDeleted {
+		t.Errorf("events = %+v, want one deleted event", events)
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	epic := func(num int, status, title string) string {
+		path := filepath.Join(moduleDir, fmt.Sprintf("epic-%02d-%s.md", num, strings.ToLower(title)))
+		os.WriteFile(path, []byte(fmt.Sprintf("---\nstatus: %s\n---\n\n# E%02d: %s\n", status, num, title)), 0644)
+		return path
+	}
+	epic(1, "complete", "Setup")
+	conflictPath := epic(2, "not_started", "Auth")
+	editedPath := epic(3, "not_started", "API")
+	deletedPath := epic(5, "not_started", "Legacy")
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+
+	// Diverge the two sides in different ways
+	store.UpdateTaskStatus("technical/E02", domain.StatusComplete)
+	os.WriteFile(editedPath, []byte("---\nstatus: not_started\n---\n\n# E03: REST API\n"), 0644)
+	epic(4, "not_started", "Billing")
+	os.Remove(deletedPath)
+
+	conflictBefore, _ := os.ReadFile(conflictPath)
+
+	report, err := syncer.SyncStatus(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id        string
+		agreement Agreement
+		db, md    domain.TaskStatus
+		fields    string
+	}{
+		{"technical/E01", InSync, domain.StatusComplete, domain.StatusComplete, ""},
+		{"technical/E02", StatusConflict, domain.StatusComplete, domain.StatusNotStarted, ""},
+		{"technical/E03", MarkdownChanged, domain.StatusNotStarted, domain.StatusNotStarted, "title"},
+		{"technical/E04", OnlyInMarkdown, "", domain.StatusNotStarted, ""},
+		{"technical/E05", OnlyInDB, domain.StatusNotStarted, "", "epic file deleted"},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("got %d comparisons, want %d: %+v", len(report), len(want), report)
+	}
+	for i, w := range want {
+		c := report[i]
+		if c.TaskID != w.id || c.Agreement != w.agreement || c.DBStatus != w.db || c.MarkdownStatus != w.md || strings.Join(c.Fields, ",") != w.fields {
+			t.Errorf("report[%d] = %+v, want %s %s db=%s md=%s fields=%q", i, c, w.id, w.agreement, w.db, w.md, w.fields)
+		}
+	}
+
+	// Pure inspection: neither side was changed
+	if task, _ := store.GetTask("technical/E04"); task != nil {
+		t.Error("SyncStatus should not add new tasks to the database")
+	}
+	if task, _ := store.GetTask("technical/E03"); task == nil || task.Title == "REST API" {
+		t.Errorf("SyncStatus should not copy markdown edits to the database, got %+v", task)
+	}
+	if tasks, _ := store.ListTasks(taskstore.ListOptions{}); len(tasks) != 4 {
+		t.Errorf("SyncStatus should not archive tasks, got %d listed", len(tasks))
+	}
+	if after, _ := os.ReadFile(conflictPath); string(after) != string(conflictBefore) {
+		t.Error("SyncStatus should not modify epic files")
+	}
+}
+
+func TestParseRemovedTaskAction(t *testing.T) {
+	for input, want := range map[string]RemovedTaskAction{
+		"":        ArchiveRemovedTasks,
+		"archive": ArchiveRemovedTasks,
+		"delete":  DeleteRemovedTasks,
+	} {
+		got, err := ParseRemovedTaskAction(input)
+		if err != nil || got != want {
+			t.Errorf("ParseRemovedTaskAction(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseRemovedTaskAction("purge"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
+
 func TestResolveConflicts_UseDB(t *testing.T) {
 	root := t.TempDir()
 	plansDir := filepath.Join(root, "docs", "plans")
@@ -326,11 +638,16 @@ status: complete
 	resolutions := map[string]string{
 		"technical/E05": "db",
 	}
-	err := syncer.ResolveConflicts(store, resolutions)
+	changes, err := syncer.ResolveConflicts(store, resolutions)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	if len(changes) != 1 || changes[0].Direction != DBToMarkdown ||
+		strings.Join(changes[0].Fields, ",") != "status: complete → in_progress" {
+		t.Errorf("changes = %+v, want technical/E05 status complete → in_progress to markdown", changes)
+	}
+
 	// Verify markdown was updated to in_progress
 	updated, _ := os.ReadFile(epicPath)
 	if !strings.Contains(string(updated), "status: in_progress") {
@@ -376,7 +693,7 @@ status: complete
 	resolutions := map[string]string{
 		"technical-module/E05": "markdown",
 	}
-	err := syncer.ResolveConflicts(store, resolutions)
+	_, err := syncer.ResolveConflicts(store, resolutions)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -418,7 +735,7 @@ status: complete
 	resolutions := map[string]string{
 		"technical-module/E05": "invalid",
 	}
-	err := syncer.ResolveConflicts(store, resolutions)
+	_, err := syncer.ResolveConflicts(store, resolutions)
 	if err == nil {
 		t.Error("expected error for invalid resolution")
 	}
@@ -427,6 +744,89 @@ status: complete
 	}
 }
 
+func TestParseConflictStrategy(t *testing.T) {
+	for _, want := range []ConflictStrategy{ResolveNewest, ResolveDB, ResolveMarkdown} {
+		if got, err := ParseConflictStrategy(string(want)); err != nil || got != want {
+			t.Errorf("ParseConflictStrategy(%q) = %q, %v", want, got, err)
+		}
+	}
+	if _, err := ParseConflictStrategy("oldest"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestResolveConflictsByStrategy_Newest(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical-module")
+	os.MkdirAll(moduleDir, 0755)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+
+	// All epics say complete and the DB says in_progress; E01 was edited
+	// after the DB changed, E02 before, and E03 at the same time
+	dbChanged := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for num, mtime := range map[int]time.Time{
+		1: dbChanged.Add(30 * time.Minute),
+		2: dbChanged.Add(-30 * time.Minute),
+		3: dbChanged,
+	} {
+		epicPath := filepath.Join(moduleDir, fmt.Sprintf("epic-%02d-task.md", num))
+		os.WriteFile(epicPath, []byte(fmt.Sprintf("---\nstatus: complete\n---\n\n# E%02d: Task\n", num)), 0644)
+		if err := os.Chtimes(epicPath, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		store.UpsertTask(&domain.Task{
+			ID:        domain.TaskID{Module: "technical-module", EpicNum: num},
+			Title:     "Task",
+			Status:    domain.StatusInProgress,
+			FilePath:  epicPath,
+			UpdatedAt: dbChanged,
+		})
+	}
+
+	syncer := New(plansDir)
+	result, err := syncer.TwoWaySync(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Conflicts) != 3 {
+		t.Fatalf("conflicts = %+v, want 3", result.Conflicts)
+	}
+
+	changes, unresolved, err := syncer.ResolveConflictsByStrategy(store, result.Conflicts, ResolveNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].TaskID != "technical-module/E01" || changes[0].Direction != MarkdownToDB ||
+		changes[1].TaskID != "technical-module/E02" || changes[1].Direction != DBToMarkdown {
+		t.Errorf("changes = %+v, want markdown to win E01 and the DB E02", changes)
+	}
+	if len(unresolved) != 1 || unresolved[0].TaskID != "technical-module/E03" {
+		t.Errorf("unresolved = %+v, want E03 with equal timestamps left to resolve by hand", unresolved)
+	}
+
+	if task, _ := store.GetTask("technical-module/E01"); task.Status != domain.StatusComplete {
+		t.Errorf("E01 status = %s, want complete from markdown", task.Status)
+	}
+	if content, _ := os.ReadFile(filepath.Join(moduleDir, "epic-02-task.md")); !strings.Contains(string(content), "status: in_progress") {
+		t.Errorf("E02 epic should take the DB status, got:\n%s", content)
+	}
+	if task, _ := store.GetTask("technical-module/E03"); task.Status != domain.StatusInProgress {
+		t.Errorf("E03 status = %s, want it left alone", task.Status)
+	}
+}
+
+func TestChooseResolutions_FixedSide(t *testing.T) {
+	conflicts := []SyncConflict{{TaskID: "technical/E01"}, {TaskID: "technical/E02"}}
+	got := New(t.TempDir()).ChooseResolutions(nil, conflicts, ResolveMarkdown)
+	want := map[string]string{"technical/E01": "markdown", "technical/E02": "markdown"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChooseResolutions = %v, want %v", got, want)
+	}
+}
+
 func TestSyncMarkdownToDB(t *testing.T) {
 	root := t.TempDir()
 	plansDir := filepath.Join(root, "docs", "plans")
@@ -586,3 +986,281 @@ func TestSyncDBToMarkdown(t *testing.T) {
 		t.Errorf("README should have 🟢, got:\n%s", string(updatedReadme))
 	}
 }
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupStatusRepo creates a project with a completed technical/E01 epic and
+// README, committed and pushed to a bare remote, and returns the project
+// root, the remote and the epic path
+func setupStatusRepo(t *testing.T) (root, remote, epicPath string) {
+	t.Helper()
+	remote = t.TempDir()
+	runGit(t, remote, "init", "--bare", "-q")
+
+	root = t.TempDir()
+	runGit(t, root, "init", "-q")
+	runGit(t, root, "config", "user.email", "test@example.com")
+	runGit(t, root, "config", "user.name", "Test")
+
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	epicPath = filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(epicPath, []byte("---\nstatus: complete\n---\n\n# E01: Setup\n"), 0644)
+	readme := `# Project
+
+### Technical Module
+
+| Epic | Description | Status |
+|------|-------------|:------:|
+| [E01](docs/plans/technical/epic-01-setup.md) | Setup | 🟢 |
+`
+	os.WriteFile(filepath.Join(root, "README.md"), []byte(readme), 0644)
+
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "initial")
+	runGit(t, root, "remote", "add", "origin", remote)
+	runGit(t, root, "push", "-q", "-u", "origin", "HEAD")
+	return root, remote, epicPath
+}
+
+func TestReopenTask(t *testing.T) {
+	root, remote, epicPath := setupStatusRepo(t)
+	plansDir := filepath.Join(root, "docs", "plans")
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	store.UpsertTask(&domain.Task{
+		ID:       domain.TaskID{Module: "technical", EpicNum: 1},
+		Title:    "Setup",
+		Status:   domain.StatusComplete,
+		FilePath: epicPath,
+	})
+
+	syncer := New(plansDir)
+	task, err := syncer.ReopenTask(store, "technical/E01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != domain.StatusNotStarted {
+		t.Errorf("Status = %q, want not_started", task.Status)
+	}
+
+	// Markdown reflects the reopened status and the change was pushed
+	updated, _ := os.ReadFile(epicPath)
+	if !strings.Contains(string(updated), "status: not_started") {
+		t.Errorf("epic should have status: not_started, got:\n%s", string(updated))
+	}
+	updatedReadme, _ := os.ReadFile(filepath.Join(root, "README.md"))
+	if !strings.Contains(string(updatedReadme), "| Setup | 🔴 |") {
+		t.Errorf("README should have 🔴, got:\n%s", string(updatedReadme))
+	}
+	if msg := runGit(t, remote, "log", "-1", "--format=%s"); msg != "chore: update technical/E01 status to not_started" {
+		t.Errorf("pushed commit = %q, want status update to not_started", msg)
+	}
+
+	// The task is eligible for scheduling again
+	tasks, _ := store.ListTasks(taskstore.ListOptions{})
+	completed, _ := store.GetCompletedTaskIDs()
+	ready := scheduler.New(tasks, completed).GetReadyTasks(10)
+	if len(ready) != 1 || ready[0].ID.String() != "technical/E01" {
+		t.Errorf("ready tasks = %v, want technical/E01", ready)
+	}
+
+	// Reopening again fails without touching the store
+	if _, err := syncer.ReopenTask(store, "technical/E01"); err == nil {
+		t.Error("expected error reopening a task that is not complete")
+	}
+}
+
+func TestSyncTaskStatus_AutoCommitDisabled(t *testing.T) {
+	root, remote, epicPath := setupStatusRepo(t)
+	headBefore := runGit(t, root, "rev-parse", "HEAD")
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	store.UpsertTask(&domain.Task{
+		ID:       domain.TaskID{Module: "technical", EpicNum: 1},
+		Title:    "Setup",
+		Status:   domain.StatusComplete,
+		FilePath: epicPath,
+	})
+
+	syncer := New(filepath.Join(root, "docs", "plans"))
+	syncer.SetAutoCommit(false)
+	task, err := syncer.ReopenTask(store, "technical/E01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The database tracks the new status
+	if task.Status != domain.StatusNotStarted {
+		t.Errorf("Status = %q, want not_started", task.Status)
+	}
+	stored, _ := store.GetTask("technical/E01")
+	if stored.Status != domain.StatusNotStarted {
+		t.Errorf("stored Status = %q, want not_started", stored.Status)
+	}
+
+	// The markdown is updated but nothing is committed or pushed
+	updated, _ := os.ReadFile(epicPath)
+	if !strings.Contains(string(updated), "status: not_started") {
+		t.Errorf("epic should have status: not_started, got:\n%s", string(updated))
+	}
+	if head := runGit(t, root, "rev-parse", "HEAD"); head != headBefore {
+		t.Errorf("HEAD moved to %s, want no commit", head)
+	}
+	if head := runGit(t, remote, "rev-parse", "HEAD"); head != headBefore {
+		t.Errorf("remote HEAD moved to %s, want no push", head)
+	}
+	status := runGit(t, root, "status", "--porcelain")
+	if !strings.Contains(status, "README.md") || !strings.Contains(status, "epic-01-setup.md") {
+		t.Errorf("status changes should be left uncommitted, git status:\n%s", status)
+	}
+}
+
+func TestSyncOne(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+
+	setupPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(setupPath, []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+	apiPath := filepath.Join(moduleDir, "epic-02-api.md")
+	os.WriteFile(apiPath, []byte("---\nstatus: not_started\n---\n\n# E02: API\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	// A task only in markdown is added, leaving the others alone
+	result, err := syncer.SyncOne(store, "technical/E02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SyncResult{
+		MarkdownToDBCount: 1,
+		Changes:           []SyncChange{{TaskID: "technical/E02", Direction: MarkdownToDB, Fields: []string{"added"}}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	tasks, _ := store.ListTasks(taskstore.ListOptions{})
+	if len(tasks) != 1 || tasks[0].ID.String() != "technical/E02" {
+		t.Fatalf("tasks = %v, want only technical/E02", tasks)
+	}
+	if deps := tasks[0].DependsOn; len(deps) != 1 || deps[0].String() != "technical/E01" {
+		t.Errorf("dependencies = %v, want the implicit technical/E01", deps)
+	}
+
+	// A task in both is updated like in a full sync, keeping the DB status
+	store.UpdateTaskStatus("technical/E02", domain.StatusInProgress)
+	os.WriteFile(apiPath, []byte("---\nstatus: not_started\n---\n\n# E02: REST API\n"), 0644)
+	result, err = syncer.SyncOne(store, "technical/E02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &SyncResult{
+		MarkdownToDBCount: 1,
+		Changes:           []SyncChange{{TaskID: "technical/E02", Direction: MarkdownToDB, Fields: []string{"title"}}},
+		Conflicts:         []SyncConflict{{TaskID: "technical/E02", DBStatus: "in_progress", MarkdownStatus: "not_started", EpicFilePath: apiPath}},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if task, _ := store.GetTask("technical/E02"); task.Title != "E02: REST API" || task.Status != domain.StatusInProgress {
+		t.Errorf("task = %q %s, want the new title and the DB status", task.Title, task.Status)
+	}
+
+	// A task only in the DB whose epic file was deleted is archived
+	if _, err := syncer.SyncOne(store, "technical/E01"); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(setupPath)
+	result, err = syncer.SyncOne(store, "technical/E01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"technical/E01"}) {
+		t.Errorf("Removed = %v, want [technical/E01]", result.Removed)
+	}
+	if tasks, _ := store.ListTasks(taskstore.ListOptions{}); len(tasks) != 1 {
+		t.Errorf("tasks = %v, want technical/E01 archived", tasks)
+	}
+
+	// A task in neither is an error, as is an invalid ID
+	for _, id := range []string{"technical/E01", "technical/E09", "nonsense"} {
+		if _, err := syncer.SyncOne(store, id); err == nil {
+			t.Errorf("SyncOne(%s) succeeded, want an error", id)
+		}
+	}
+}
+
+func TestSync_RecordsAuditLog(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	moduleDir := filepath.Join(plansDir, "technical")
+	os.MkdirAll(moduleDir, 0755)
+	epicPath := filepath.Join(moduleDir, "epic-01-setup.md")
+	os.WriteFile(epicPath, []byte("---\nstatus: in_progress\n---\n\n# E01: Setup\n"), 0644)
+
+	store, _ := taskstore.New(":memory:")
+	defer store.Close()
+	syncer := New(plansDir)
+
+	// A dry run records nothing
+	syncer.SetDryRun(true)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := store.ListSyncAudit("", 10); len(entries) != 0 {
+		t.Fatalf("dry run recorded %+v", entries)
+	}
+
+	// Adding the task records its status
+	syncer.SetDryRun(false)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+	// A conflict alone is not reconciled, so it is not recorded either
+	store.UpdateTaskStatus("technical/E01", domain.StatusComplete)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+	// Resolving it in favor of markdown is
+	if _, err := syncer.ResolveConflicts(store, map[string]string{"technical/E01": "markdown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.ListSyncAudit("technical/E01", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type entry struct {
+		direction  string
+		from, to   domain.TaskStatus
+		resolution bool
+	}
+	var got []entry
+	for _, e := range entries {
+		got = append(got, entry{e.Direction, e.OldStatus, e.NewStatus, e.Resolution})
+	}
+	want := []entry{
+		{string(MarkdownToDB), "", domain.StatusInProgress, false},
+		{string(MarkdownToDB), domain.StatusComplete, domain.StatusInProgress, true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audit log = %+v, want %+v", got, want)
+	}
+}
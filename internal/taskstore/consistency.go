@@ -0,0 +1,151 @@
+package taskstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// StatusMismatch is a task whose status disagrees with its latest agent run,
+// as left behind when the orchestrator stops between updating the two
+type StatusMismatch struct {
+	TaskID     string
+	TaskStatus domain.TaskStatus
+	RunID      string // Latest agent run of the task
+	RunStatus  string
+
+	// FixStatus is the status RepairStatusMismatch sets the task to. If it
+	// is empty, the task is right and the run is marked completed instead.
+	FixStatus domain.TaskStatus
+}
+
+// String describes the mismatch
+func (m StatusMismatch) String() string {
+	return fmt.Sprintf("%s is %s, but its latest agent run %s is %s", m.TaskID, m.TaskStatus, m.RunID, m.RunStatus)
+}
+
+// Fix describes what RepairStatusMismatch does about the mismatch
+func (m StatusMismatch) Fix() string {
+	if m.FixStatus == "" {
+		return fmt.Sprintf("mark agent run %s completed", m.RunID)
+	}
+	return fmt.Sprintf("set task to %s", m.FixStatus)
+}
+
+// checkStatus compares a task's status with the status of its latest agent
+// run and returns the fix for a mismatch. Runs that failed or are queued
+// leave the task status open, so they never mismatch.
+func checkStatus(taskStatus domain.TaskStatus, runStatus string) (fix domain.TaskStatus, mismatch bool) {
+	switch runStatus {
+	case "completed":
+		return domain.StatusComplete, taskStatus != domain.StatusComplete
+	case "running", "stuck":
+		if taskStatus == domain.StatusComplete {
+			// The task finished; the run was never marked so
+			return "", true
+		}
+		return domain.StatusInProgress, taskStatus == domain.StatusNotStarted
+	case "cancelled":
+		// Cancelling removes the worktree, so the task starts over
+		return domain.StatusNotStarted, taskStatus == domain.StatusInProgress
+	}
+	return "", false
+}
+
+// changedSinceRun reports whether a task's status was set after its latest
+// agent run finished, e.g. by reopening it or resolving a sync conflict. Its
+// status then deliberately differs from the run.
+func changedSinceRun(finishedAt, updatedAt, reopenedAt sql.NullTime) bool {
+	if !finishedAt.Valid {
+		return false
+	}
+	return (updatedAt.Valid && updatedAt.Time.After(finishedAt.Time)) ||
+		(reopenedAt.Valid && reopenedAt.Time.After(finishedAt.Time))
+}
+
+// CheckStatusConsistency returns the tasks whose status disagrees with their
+// latest agent run, ordered by task ID. Archived tasks are not checked, nor
+// are tasks whose status was changed after the run finished. Timestamps are
+// compared once parsed, like in ListCompletedSince.
+func (s *Store) CheckStatusConsistency() ([]StatusMismatch, error) {
+	rows, err := s.db.Query(`
+		SELECT t.id, t.status, t.updated_at, r.id, r.status, r.finished_at, e.created_at
+		FROM tasks t
+		JOIN agent_runs r ON r.rowid = (
+			SELECT rowid FROM agent_runs WHERE task_id = t.id
+			ORDER BY started_at DESC, rowid DESC LIMIT 1
+		)
+		LEFT JOIN task_events e ON e.id = (
+			SELECT id FROM task_events WHERE task_id = t.id AND event = ?
+			ORDER BY id DESC LIMIT 1
+		)
+		WHERE t.archived_at IS NULL
+		ORDER BY t.id
+	`, TaskEventReopened)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []StatusMismatch
+	for rows.Next() {
+		var m StatusMismatch
+		var taskStatus string
+		var updatedAt, finishedAt, reopenedAt sql.NullTime
+		if err := rows.Scan(&m.TaskID, &taskStatus, &updatedAt, &m.RunID, &m.RunStatus, &finishedAt, &reopenedAt); err != nil {
+			return nil, err
+		}
+		m.TaskStatus = domain.TaskStatus(taskStatus)
+
+		fix, mismatch := checkStatus(m.TaskStatus, m.RunStatus)
+		if mismatch && !changedSinceRun(finishedAt, updatedAt, reopenedAt) {
+			m.FixStatus = fix
+			mismatches = append(mismatches, m)
+		}
+	}
+	return mismatches, rows.Err()
+}
+
+// RepairStatusMismatch applies the fix of a mismatch found by
+// CheckStatusConsistency. A changed task status is recorded as a repaired
+// event. It fails if the task or run changed since the check.
+func (s *Store) RepairStatusMismatch(m StatusMismatch) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var taskStatus, runStatus string
+	err = tx.QueryRow(`
+		SELECT t.status, r.status FROM tasks t, agent_runs r WHERE t.id = ? AND r.id = ?
+	`, m.TaskID, m.RunID).Scan(&taskStatus, &runStatus)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task %s or agent run %s not found", m.TaskID, m.RunID)
+	}
+	if err != nil {
+		return err
+	}
+	if domain.TaskStatus(taskStatus) != m.TaskStatus || runStatus != m.RunStatus {
+		return fmt.Errorf("%s changed since the check (task is %s, agent run is %s)", m.TaskID, taskStatus, runStatus)
+	}
+
+	if m.FixStatus == "" {
+		if err := updateAgentRunStatus(tx, m.RunID, "completed", ""); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if _, err := tx.Exec(`UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?`,
+		string(m.FixStatus), time.Now(), m.TaskID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO task_events (task_id, event, from_status, to_status) VALUES (?, ?, ?, ?)`,
+		m.TaskID, TaskEventRepaired, taskStatus, string(m.FixStatus)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
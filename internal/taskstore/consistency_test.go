@@ -0,0 +1,166 @@
+package taskstore
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// seedStatusMismatches stores tasks whose latest agent runs disagree with
+// them in every way a crash can leave behind, next to consistent ones
+func seedStatusMismatches(t *testing.T) *Store {
+	t.Helper()
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed := []struct {
+		epic       int
+		taskStatus domain.TaskStatus
+		runs       []string // Oldest first
+	}{
+		{1, domain.StatusInProgress, []string{"completed"}},          // Finished, task not updated
+		{2, domain.StatusComplete, []string{"completed", "running"}}, // Task done, run not updated
+		{3, domain.StatusNotStarted, []string{"stuck"}},              // Started, task not updated
+		{4, domain.StatusInProgress, []string{"cancelled"}},          // Cancelled, task not reset
+		{5, domain.StatusInProgress, []string{"completed", "failed"}},
+		{6, domain.StatusComplete, []string{"failed", "completed"}},
+		{7, domain.StatusInProgress, []string{"running"}},
+		{8, domain.StatusNotStarted, nil},
+	}
+	for _, s := range seed {
+		id := domain.TaskID{Module: "billing", EpicNum: s.epic}
+		if err := store.UpsertTask(&domain.Task{ID: id, Title: id.String(), Status: s.taskStatus}); err != nil {
+			t.Fatal(err)
+		}
+		for i, status := range s.runs {
+			run := &AgentRun{
+				ID:        id.String() + "#" + string(rune('a'+i)),
+				TaskID:    id.String(),
+				Status:    status,
+				StartedAt: start.Add(time.Duration(i) * time.Hour),
+			}
+			if err := store.SaveAgentRun(run); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return store
+}
+
+func TestStore_CheckStatusConsistency(t *testing.T) {
+	store := seedStatusMismatches(t)
+
+	got, err := store.CheckStatusConsistency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StatusMismatch{
+		{TaskID: "billing/E01", TaskStatus: domain.StatusInProgress, RunID: "billing/E01#a", RunStatus: "completed", FixStatus: domain.StatusComplete},
+		{TaskID: "billing/E02", TaskStatus: domain.StatusComplete, RunID: "billing/E02#b", RunStatus: "running"},
+		{TaskID: "billing/E03", TaskStatus: domain.StatusNotStarted, RunID: "billing/E03#a", RunStatus: "stuck", FixStatus: domain.StatusInProgress},
+		{TaskID: "billing/E04", TaskStatus: domain.StatusInProgress, RunID: "billing/E04#a", RunStatus: "cancelled", FixStatus: domain.StatusNotStarted},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mismatches = %+v\nwant %+v", got, want)
+	}
+
+	// Archived tasks are not checked
+	if err := store.ArchiveTask("billing/E01"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.CheckStatusConsistency(); len(got) != 3 || got[0].TaskID != "billing/E02" {
+		t.Errorf("mismatches after archiving billing/E01 = %+v", got)
+	}
+}
+
+func TestStore_CheckStatusConsistencySkipsReopenedTasks(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// Both tasks completed long ago; billing/E01 is then reopened
+	updated := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	finished := updated.Add(-time.Minute)
+	for _, id := range []string{"billing/E01", "billing/E02"} {
+		tid, _ := domain.ParseTaskID(id)
+		store.UpsertTask(&domain.Task{ID: tid, Title: id, Status: domain.StatusComplete, UpdatedAt: updated})
+		if err := store.SaveAgentRun(&AgentRun{
+			ID: id + "#a", TaskID: id, Status: "completed", StartedAt: finished.Add(-time.Hour), FinishedAt: &finished,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.ReopenTask("billing/E01"); err != nil {
+		t.Fatal(err)
+	}
+	// Left behind by a crash after the run finished: still a mismatch
+	store.UpsertTask(&domain.Task{ID: domain.TaskID{Module: "billing", EpicNum: 2}, Title: "billing/E02", Status: domain.StatusInProgress, UpdatedAt: finished.Add(-time.Hour)})
+
+	got, err := store.CheckStatusConsistency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TaskID != "billing/E02" {
+		t.Errorf("mismatches = %+v, want only billing/E02, not the reopened billing/E01", got)
+	}
+
+	// The reopened event alone is enough, e.g. if updated_at was overwritten
+	store.UpsertTask(&domain.Task{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Title: "billing/E01", Status: domain.StatusNotStarted, UpdatedAt: finished.Add(-time.Hour)})
+	if got, _ := store.CheckStatusConsistency(); len(got) != 1 || got[0].TaskID != "billing/E02" {
+		t.Errorf("mismatches = %+v, want the reopened billing/E01 still skipped", got)
+	}
+}
+
+func TestStore_RepairStatusMismatch(t *testing.T) {
+	store := seedStatusMismatches(t)
+
+	mismatches, err := store.CheckStatusConsistency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range mismatches {
+		if err := store.RepairStatusMismatch(m); err != nil {
+			t.Errorf("repair %s: %v", m.TaskID, err)
+		}
+	}
+	if left, _ := store.CheckStatusConsistency(); len(left) != 0 {
+		t.Errorf("mismatches left after repair: %+v", left)
+	}
+
+	wantStatus := map[string]domain.TaskStatus{
+		"billing/E01": domain.StatusComplete,
+		"billing/E02": domain.StatusComplete,
+		"billing/E03": domain.StatusInProgress,
+		"billing/E04": domain.StatusNotStarted,
+	}
+	for id, want := range wantStatus {
+		if task, _ := store.GetTask(id); task.Status != want {
+			t.Errorf("%s status = %s, want %s", id, task.Status, want)
+		}
+	}
+	if run, _ := store.GetAgentRun("billing/E02#b"); run.Status != "completed" || run.FinishedAt == nil {
+		t.Errorf("stale run of billing/E02 = %s (finished %v), want completed", run.Status, run.FinishedAt)
+	}
+
+	events, _ := store.ListTaskEvents("billing/E01")
+	if len(events) != 1 || events[0].Event != TaskEventRepaired ||
+		events[0].FromStatus != domain.StatusInProgress || events[0].ToStatus != domain.StatusComplete {
+		t.Errorf("events of billing/E01 = %+v, want one repaired in_progress -> complete", events)
+	}
+	if events, _ := store.ListTaskEvents("billing/E02"); len(events) != 0 {
+		t.Errorf("fixing the run of billing/E02 recorded task events: %+v", events)
+	}
+
+	// A mismatch that changed since the check is left alone
+	if err := store.RepairStatusMismatch(mismatches[0]); err == nil {
+		t.Error("repairing an already repaired mismatch succeeded")
+	}
+}
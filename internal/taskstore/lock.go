@@ -0,0 +1,106 @@
+package taskstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LockedError reports a database locked by another running process
+type LockedError struct {
+	Path string // Lockfile
+	PID  int    // Process holding the lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("database is in use by another instance (pid %d, lockfile %s); use --force to take it over", e.PID, e.Path)
+}
+
+// Lock is an advisory lock on a database, so that two TUIs don't make
+// conflicting writes. It is a lockfile next to the database holding the PID
+// of its owner.
+type Lock struct {
+	path string
+	pid  int
+
+	// TookOverPID is the live process the lock was forced away from (0 if none)
+	TookOverPID int
+}
+
+// processAlive reports whether a process with the given PID exists
+var processAlive = pidAlive
+
+// LockPath returns the lockfile of the database at dbPath
+func LockPath(dbPath string) string {
+	return dbPath + ".lock"
+}
+
+// AcquireLock locks the database at dbPath for the current process. A lock
+// held by a running process fails with a *LockedError unless force is set;
+// locks left behind by processes that exited are stale and taken over.
+func AcquireLock(dbPath string, force bool) (*Lock, error) {
+	lock := &Lock{path: LockPath(dbPath), pid: os.Getpid()}
+
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(lock.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(lock.pid) + "\n")
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lock.path)
+				return nil, fmt.Errorf("writing lockfile: %w", err)
+			}
+			return lock, nil
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			// A second failure means another process took the lock meanwhile
+			return nil, fmt.Errorf("creating lockfile: %w", err)
+		}
+
+		holder, err := readLockPID(lock.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if holder != 0 && holder != lock.pid && processAlive(holder) {
+			if !force {
+				return nil, &LockedError{Path: lock.path, PID: holder}
+			}
+			lock.TookOverPID = holder
+		}
+
+		// Stale or forced: remove the old lockfile and try again
+		if err := os.Remove(lock.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing stale lockfile: %w", err)
+		}
+	}
+}
+
+// Release removes the lockfile, unless another process has taken the lock over
+func (l *Lock) Release() error {
+	holder, err := readLockPID(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || holder != l.pid {
+		return err
+	}
+	return os.Remove(l.path)
+}
+
+// readLockPID returns the PID in a lockfile; unreadable contents give 0, so
+// the lock counts as stale
+func readLockPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, nil
+	}
+	return pid, nil
+}
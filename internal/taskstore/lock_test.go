@@ -0,0 +1,116 @@
+package taskstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeLockfile leaves a lockfile held by pid, as another instance would
+func writeLockfile(t *testing.T, dbPath string, pid int) {
+	t.Helper()
+	if err := os.WriteFile(LockPath(dbPath), []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeProcesses makes the given PIDs the only running processes
+func fakeProcesses(t *testing.T, pids ...int) {
+	t.Helper()
+	orig := processAlive
+	processAlive = func(pid int) bool {
+		for _, p := range pids {
+			if p == pid {
+				return true
+			}
+		}
+		return false
+	}
+	t.Cleanup(func() { processAlive = orig })
+}
+
+func TestAcquireLock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "orchestrator.db")
+
+	lock, err := AcquireLock(dbPath, false)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	pid, err := readLockPID(LockPath(dbPath))
+	if err != nil || pid != os.Getpid() {
+		t.Errorf("lockfile pid = %d, %v, want %d", pid, err, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := os.Stat(LockPath(dbPath)); !os.IsNotExist(err) {
+		t.Error("Release should remove the lockfile")
+	}
+}
+
+func TestAcquireLock_HeldByRunningProcess(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "orchestrator.db")
+	fakeProcesses(t, 4242)
+	writeLockfile(t, dbPath, 4242)
+
+	_, err := AcquireLock(dbPath, false)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != 4242 {
+		t.Fatalf("AcquireLock error = %v, want a LockedError for pid 4242", err)
+	}
+	if pid, _ := readLockPID(LockPath(dbPath)); pid != 4242 {
+		t.Errorf("a refused lock should leave the holder's lockfile, got pid %d", pid)
+	}
+}
+
+func TestAcquireLock_TakesOverStaleLock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "orchestrator.db")
+	fakeProcesses(t)
+	writeLockfile(t, dbPath, 4242)
+
+	lock, err := AcquireLock(dbPath, false)
+	if err != nil {
+		t.Fatalf("AcquireLock with a stale lock: %v", err)
+	}
+	if lock.TookOverPID != 0 {
+		t.Errorf("TookOverPID = %d, want 0 for a stale lock", lock.TookOverPID)
+	}
+	if pid, _ := readLockPID(LockPath(dbPath)); pid != os.Getpid() {
+		t.Errorf("lockfile pid = %d, want ours", pid)
+	}
+
+	// Garbage in the lockfile is stale too
+	lock.Release()
+	if err := os.WriteFile(LockPath(dbPath), []byte("not a pid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireLock(dbPath, false); err != nil {
+		t.Errorf("AcquireLock with an unreadable lockfile: %v", err)
+	}
+}
+
+func TestAcquireLock_Force(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "orchestrator.db")
+	fakeProcesses(t, 4242)
+	writeLockfile(t, dbPath, 4242)
+
+	lock, err := AcquireLock(dbPath, true)
+	if err != nil {
+		t.Fatalf("AcquireLock with force: %v", err)
+	}
+	if lock.TookOverPID != 4242 {
+		t.Errorf("TookOverPID = %d, want 4242", lock.TookOverPID)
+	}
+
+	// The previous holder exiting must not remove the new holder's lock
+	previous := &Lock{path: LockPath(dbPath), pid: 4242}
+	if err := previous.Release(); err != nil {
+		t.Fatalf("Release by the previous holder: %v", err)
+	}
+	if pid, _ := readLockPID(LockPath(dbPath)); pid != os.Getpid() {
+		t.Errorf("lockfile pid = %d, want ours after the previous holder released", pid)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package taskstore
+
+import (
+	"errors"
+	"syscall"
+)
+
+// pidAlive sends signal 0 to the process, which only checks that it exists
+func pidAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
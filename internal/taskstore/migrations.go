@@ -127,3 +127,79 @@ CREATE INDEX IF NOT EXISTS idx_tasks_github_issue ON tasks(github_issue);
 const migrationAddTaskPrefix = `
 ALTER TABLE tasks ADD COLUMN prefix TEXT NOT NULL DEFAULT '';
 `
+
+// Migration to add per-task priority overrides (promoted tasks), distinct from group tiers
+const migrationTaskPriorityOverrides = `
+CREATE TABLE IF NOT EXISTS task_priority_overrides (
+    task_id  TEXT PRIMARY KEY,
+    priority INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// Migration to record the task IDs of the most recently started batch, for replay
+const migrationLastBatch = `
+CREATE TABLE IF NOT EXISTS last_batch (
+    position   INTEGER PRIMARY KEY,
+    task_id    TEXT NOT NULL,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// Migration to record manual task status changes (e.g. reopening) for auditing
+const migrationTaskEvents = `
+CREATE TABLE IF NOT EXISTS task_events (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id     TEXT NOT NULL,
+    event       TEXT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status   TEXT NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events(task_id);
+`
+
+// Migration to soft-archive tasks whose epic file was deleted
+const migrationArchiveTasks = `
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMP;
+`
+
+// Migration to add notes to agent runs for post-hoc annotation
+const migrationAgentRunNotes = `
+ALTER TABLE agent_runs ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+`
+
+// Migration to add per-group weights that order groups within a tier. They
+// are kept apart from group_priorities so a weight does not pin a group to a
+// tier and unassigning a tier keeps the weight.
+const migrationGroupWeights = `
+CREATE TABLE IF NOT EXISTS group_weights (
+    group_name TEXT PRIMARY KEY,
+    weight     INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// Migration to record how syncs reconciled task statuses, listed by task and
+// time (see ListSyncAudit)
+const migrationSyncAudit = `
+CREATE TABLE IF NOT EXISTS sync_audit (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    TEXT NOT NULL,
+    direction  TEXT NOT NULL,
+    old_status TEXT NOT NULL,
+    new_status TEXT NOT NULL,
+    resolution INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_sync_audit_task_created ON sync_audit(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sync_audit_created ON sync_audit(created_at);
+`
+
+// Migration to remember the commit each module's tests last passed at, which
+// changed-only test runs diff against
+const migrationModuleTestPasses = `
+CREATE TABLE IF NOT EXISTS module_test_passes (
+    module     TEXT PRIMARY KEY,
+    commit_sha TEXT NOT NULL,
+    passed_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
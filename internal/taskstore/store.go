@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
@@ -13,6 +16,9 @@ import (
 // Store provides SQLite-backed task persistence
 type Store struct {
 	db *sql.DB
+
+	// defaultGroupPriority is the tier assumed for groups without an explicit priority
+	defaultGroupPriority int
 }
 
 // New creates a new Store with the given database path
@@ -55,6 +61,42 @@ func New(dbPath string) (*Store, error) {
 	// Add prefix column to tasks for subsystem prefixes (CLI, TUI, etc.)
 	db.Exec(migrationAddTaskPrefix)
 
+	// Add per-task priority overrides table
+	if _, err := db.Exec(migrationTaskPriorityOverrides); err != nil {
+		return nil, fmt.Errorf("task_priority_overrides migration: %w", err)
+	}
+
+	// Add last batch table for batch replay
+	if _, err := db.Exec(migrationLastBatch); err != nil {
+		return nil, fmt.Errorf("last_batch migration: %w", err)
+	}
+
+	// Add task events table for auditing manual status changes
+	if _, err := db.Exec(migrationTaskEvents); err != nil {
+		return nil, fmt.Errorf("task_events migration: %w", err)
+	}
+
+	// Add archived_at column to tasks (ignore error if already exists)
+	db.Exec(migrationArchiveTasks)
+
+	// Add notes column to agent_runs (ignore error if already exists)
+	db.Exec(migrationAgentRunNotes)
+
+	// Add group weights table for ordering groups within a tier
+	if _, err := db.Exec(migrationGroupWeights); err != nil {
+		return nil, fmt.Errorf("group_weights migration: %w", err)
+	}
+
+	// Add sync audit table for tracing status reconciliations
+	if _, err := db.Exec(migrationSyncAudit); err != nil {
+		return nil, fmt.Errorf("sync_audit migration: %w", err)
+	}
+
+	// Add module test passes table for changed-only test runs
+	if _, err := db.Exec(migrationModuleTestPasses); err != nil {
+		return nil, fmt.Errorf("module_test_passes migration: %w", err)
+	}
+
 	return &Store{db: db}, nil
 }
 
@@ -63,6 +105,16 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
+// SetDefaultGroupPriority sets the tier assumed for groups without an explicit priority
+func (s *Store) SetDefaultGroupPriority(tier int) {
+	s.defaultGroupPriority = tier
+}
+
+// Ping verifies the database connection is still usable
+func (s *Store) Ping() error {
+	return s.db.Ping()
+}
+
 // UpsertTask inserts or updates a task
 func (s *Store) UpsertTask(task *domain.Task) error {
 	depsJSON, err := json.Marshal(task.DependsOn)
@@ -85,7 +137,8 @@ func (s *Store) UpsertTask(task *domain.Task) error {
 			needs_review = excluded.needs_review,
 			file_path = excluded.file_path,
 			github_issue = excluded.github_issue,
-			updated_at = excluded.updated_at
+			updated_at = excluded.updated_at,
+			archived_at = NULL
 	`,
 		task.ID.String(),
 		task.ID.Module,
@@ -117,8 +170,9 @@ func (s *Store) GetTask(id string) (*domain.Task, error) {
 
 // ListOptions specifies filters for listing tasks
 type ListOptions struct {
-	Module string
-	Status domain.TaskStatus
+	Module          string
+	Status          domain.TaskStatus
+	IncludeArchived bool // Also list tasks archived because their epic file was deleted
 }
 
 // ListTasks returns tasks matching the given options
@@ -134,6 +188,9 @@ func (s *Store) ListTasks(opts ListOptions) ([]*domain.Task, error) {
 		query += " AND status = ?"
 		args = append(args, string(opts.Status))
 	}
+	if !opts.IncludeArchived {
+		query += " AND archived_at IS NULL"
+	}
 
 	query += " ORDER BY module, epic_num"
 
@@ -157,11 +214,127 @@ func (s *Store) ListTasks(opts ListOptions) ([]*domain.Task, error) {
 
 // UpdateTaskStatus updates a task's status
 func (s *Store) UpdateTaskStatus(id string, status domain.TaskStatus) error {
-	_, err := s.db.Exec(`UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?`,
+	return updateTaskStatus(s.db, id, status)
+}
+
+func updateTaskStatus(db execer, id string, status domain.TaskStatus) error {
+	_, err := db.Exec(`UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?`,
 		string(status), time.Now(), id)
 	return err
 }
 
+// Task events recorded in the audit log
+const (
+	TaskEventReopened = "reopened" // A completed task was set back to not_started
+	TaskEventArchived = "archived" // The task's epic file was deleted and the task archived
+	TaskEventDeleted  = "deleted"  // The task's epic file was deleted and the task deleted
+	TaskEventRepaired = "repaired" // The status was fixed to agree with the task's latest agent run
+)
+
+// TaskEvent is an audit record of a manual task status change or removal
+type TaskEvent struct {
+	ID         int64
+	TaskID     string
+	Event      string
+	FromStatus domain.TaskStatus
+	ToStatus   domain.TaskStatus
+	CreatedAt  time.Time
+}
+
+// ReopenTask sets a completed task back to not_started and records a
+// reopened event. It fails if the task does not exist or is not complete.
+func (s *Store) ReopenTask(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("task %s not found", id)
+		}
+		return err
+	}
+	if domain.TaskStatus(status) != domain.StatusComplete {
+		return fmt.Errorf("task %s is %s, only complete tasks can be reopened", id, status)
+	}
+
+	if _, err := tx.Exec(`UPDATE tasks SET status = ?, updated_at = ? WHERE id = ?`,
+		string(domain.StatusNotStarted), time.Now(), id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO task_events (task_id, event, from_status, to_status) VALUES (?, ?, ?, ?)`,
+		id, TaskEventReopened, status, string(domain.StatusNotStarted)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ArchiveTask hides a task whose epic file was deleted from task listings and
+// records an archived event. Upserting the task again restores it.
+func (s *Store) ArchiveTask(id string) error {
+	return s.removeTask(id, TaskEventArchived, `UPDATE tasks SET archived_at = CURRENT_TIMESTAMP WHERE id = ?`)
+}
+
+// DeleteTask deletes a task whose epic file was deleted and records a
+// deleted event, which outlives the task
+func (s *Store) DeleteTask(id string) error {
+	return s.removeTask(id, TaskEventDeleted, `DELETE FROM tasks WHERE id = ?`)
+}
+
+// removeTask runs stmt on a task and records event, in one transaction
+func (s *Store) removeTask(id, event, stmt string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("task %s not found", id)
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(stmt, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO task_events (task_id, event, from_status, to_status) VALUES (?, ?, ?, ?)`,
+		id, event, status, status); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTaskEvents returns the recorded events of a task, oldest first
+func (s *Store) ListTaskEvents(taskID string) ([]TaskEvent, error) {
+	rows, err := s.db.Query(`
+		SELECT id, task_id, event, from_status, to_status, created_at
+		FROM task_events WHERE task_id = ? ORDER BY id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []TaskEvent
+	for rows.Next() {
+		var e TaskEvent
+		var from, to string
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Event, &from, &to, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.FromStatus = domain.TaskStatus(from)
+		e.ToStatus = domain.TaskStatus(to)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
 // GetCompletedTaskIDs returns a set of completed task IDs
 func (s *Store) GetCompletedTaskIDs() (map[string]bool, error) {
 	rows, err := s.db.Query(`SELECT id FROM tasks WHERE status = ?`, string(domain.StatusComplete))
@@ -266,11 +439,16 @@ type AgentRun struct {
 	TokensInput  int
 	TokensOutput int
 	CostUSD      float64
+	Notes        string // Annotations added after the run (see AnnotateAgentRun), one per line
 }
 
 // SaveAgentRun creates or updates an agent run record
 func (s *Store) SaveAgentRun(run *AgentRun) error {
-	_, err := s.db.Exec(`
+	return saveAgentRun(s.db, run)
+}
+
+func saveAgentRun(db execer, run *AgentRun) error {
+	_, err := db.Exec(`
 		INSERT INTO agent_runs (id, task_id, worktree_path, log_path, pid, status, started_at, finished_at, error_message, session_id)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
@@ -295,16 +473,29 @@ func (s *Store) SaveAgentRun(run *AgentRun) error {
 
 // GetAgentRun retrieves an agent run by ID
 func (s *Store) GetAgentRun(id string) (*AgentRun, error) {
-	row := s.db.QueryRow(`
-		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at, error_message, COALESCE(session_id, '')
+	return scanAgentRun(s.db.QueryRow(`
+		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at, error_message, COALESCE(session_id, ''), notes
 		FROM agent_runs WHERE id = ?
-	`, id)
+	`, id))
+}
+
+// GetLatestAgentRun retrieves the most recently started agent run of a task.
+// It returns sql.ErrNoRows if the task has no runs.
+func (s *Store) GetLatestAgentRun(taskID string) (*AgentRun, error) {
+	return scanAgentRun(s.db.QueryRow(`
+		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at, error_message, COALESCE(session_id, ''), notes
+		FROM agent_runs WHERE task_id = ?
+		ORDER BY started_at DESC, rowid DESC LIMIT 1
+	`, taskID))
+}
 
+// scanAgentRun scans the agent run selected by GetAgentRun or GetLatestAgentRun
+func scanAgentRun(row *sql.Row) (*AgentRun, error) {
 	var run AgentRun
 	var finishedAt sql.NullTime
 	var errorMsg sql.NullString
 
-	err := row.Scan(&run.ID, &run.TaskID, &run.WorktreePath, &run.LogPath, &run.PID, &run.Status, &run.StartedAt, &finishedAt, &errorMsg, &run.SessionID)
+	err := row.Scan(&run.ID, &run.TaskID, &run.WorktreePath, &run.LogPath, &run.PID, &run.Status, &run.StartedAt, &finishedAt, &errorMsg, &run.SessionID, &run.Notes)
 	if err != nil {
 		return nil, err
 	}
@@ -322,7 +513,8 @@ func (s *Store) GetAgentRun(id string) (*AgentRun, error) {
 // ListActiveAgentRuns returns all agent runs that are still running
 func (s *Store) ListActiveAgentRuns() ([]*AgentRun, error) {
 	rows, err := s.db.Query(`
-		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at, error_message, COALESCE(session_id, '')
+		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at,
+		       error_message, COALESCE(session_id, ''), tokens_input, tokens_output, cost_usd
 		FROM agent_runs WHERE status = 'running'
 	`)
 	if err != nil {
@@ -336,7 +528,9 @@ func (s *Store) ListActiveAgentRuns() ([]*AgentRun, error) {
 		var finishedAt sql.NullTime
 		var errorMsg sql.NullString
 
-		err := rows.Scan(&run.ID, &run.TaskID, &run.WorktreePath, &run.LogPath, &run.PID, &run.Status, &run.StartedAt, &finishedAt, &errorMsg, &run.SessionID)
+		err := rows.Scan(&run.ID, &run.TaskID, &run.WorktreePath, &run.LogPath, &run.PID,
+			&run.Status, &run.StartedAt, &finishedAt, &errorMsg, &run.SessionID,
+			&run.TokensInput, &run.TokensOutput, &run.CostUSD)
 		if err != nil {
 			return nil, err
 		}
@@ -356,13 +550,17 @@ func (s *Store) ListActiveAgentRuns() ([]*AgentRun, error) {
 
 // UpdateAgentRunStatus updates the status of an agent run
 func (s *Store) UpdateAgentRunStatus(id string, status string, errorMessage string) error {
+	return updateAgentRunStatus(s.db, id, status, errorMessage)
+}
+
+func updateAgentRunStatus(db execer, id string, status string, errorMessage string) error {
 	var finishedAt *time.Time
 	if status == "completed" || status == "failed" {
 		now := time.Now()
 		finishedAt = &now
 	}
 
-	_, err := s.db.Exec(`
+	_, err := db.Exec(`
 		UPDATE agent_runs SET status = ?, finished_at = ?, error_message = ? WHERE id = ?
 	`, status, finishedAt, errorMessage, id)
 	return err
@@ -370,18 +568,163 @@ func (s *Store) UpdateAgentRunStatus(id string, status string, errorMessage stri
 
 // DeleteAgentRun removes an agent run record
 func (s *Store) DeleteAgentRun(id string) error {
-	_, err := s.db.Exec(`DELETE FROM agent_runs WHERE id = ?`, id)
+	return deleteAgentRun(s.db, id)
+}
+
+func deleteAgentRun(db execer, id string) error {
+	_, err := db.Exec(`DELETE FROM agent_runs WHERE id = ?`, id)
 	return err
 }
 
 // UpdateAgentRunUsage updates the token usage for an agent run
 func (s *Store) UpdateAgentRunUsage(id string, tokensInput, tokensOutput int, costUSD float64) error {
-	_, err := s.db.Exec(`
+	return updateAgentRunUsage(s.db, id, tokensInput, tokensOutput, costUSD)
+}
+
+func updateAgentRunUsage(db execer, id string, tokensInput, tokensOutput int, costUSD float64) error {
+	_, err := db.Exec(`
 		UPDATE agent_runs SET tokens_input = ?, tokens_output = ?, cost_usd = ? WHERE id = ?
 	`, tokensInput, tokensOutput, costUSD, id)
 	return err
 }
 
+// execer runs statements on the database or in a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// AgentRunTx writes agent runs and task statuses in a transaction (see
+// WriteAgentRuns)
+type AgentRunTx struct {
+	tx *sql.Tx
+}
+
+// SaveAgentRun creates or updates an agent run record
+func (t *AgentRunTx) SaveAgentRun(run *AgentRun) error {
+	return saveAgentRun(t.tx, run)
+}
+
+// UpdateAgentRunStatus updates the status of an agent run
+func (t *AgentRunTx) UpdateAgentRunStatus(id string, status string, errorMessage string) error {
+	return updateAgentRunStatus(t.tx, id, status, errorMessage)
+}
+
+// UpdateAgentRunUsage updates the token usage for an agent run
+func (t *AgentRunTx) UpdateAgentRunUsage(id string, tokensInput, tokensOutput int, costUSD float64) error {
+	return updateAgentRunUsage(t.tx, id, tokensInput, tokensOutput, costUSD)
+}
+
+// DeleteAgentRun removes an agent run record
+func (t *AgentRunTx) DeleteAgentRun(id string) error {
+	return deleteAgentRun(t.tx, id)
+}
+
+// UpdateTaskStatus updates a task's status
+func (t *AgentRunTx) UpdateTaskStatus(id string, status domain.TaskStatus) error {
+	return updateTaskStatus(t.tx, id, status)
+}
+
+// WriteAgentRuns calls fn with a transaction for agent run and task status
+// writes, and commits it if fn returns nil. Writing many updates this way is
+// much faster than one implicit transaction per statement.
+func (s *Store) WriteAgentRuns(fn func(tx *AgentRunTx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&AgentRunTx{tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// storedTimeMargin widens time windows compared in SQL, so that no run whose
+// time was stored in another time zone is missed
+const storedTimeMargin = 24 * time.Hour
+
+// storedTime formats t like the beginning of a stored timestamp, which is
+// text in the local time of the process that wrote it. Stored timestamps
+// sort as text against it, off by the difference of the time zones.
+func storedTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05.999999999")
+}
+
+// CompletedRun is the task and finish time of a completed agent run
+type CompletedRun struct {
+	TaskID     string
+	FinishedAt time.Time
+}
+
+// CompletedRuns are completed agent runs, as listed by ListCompletedSince
+type CompletedRuns []CompletedRun
+
+// ListCompletedSince returns the agent runs that completed at or after since.
+// Timestamps are stored as text in the time zone of the process that wrote
+// them, which SQL cannot compare exactly: SQL only skips the runs finished
+// well before since, and the window is applied to the parsed times.
+func (s *Store) ListCompletedSince(since time.Time) (CompletedRuns, error) {
+	rows, err := s.db.Query(`
+		SELECT task_id, finished_at FROM agent_runs
+		WHERE status = 'completed' AND finished_at IS NOT NULL AND finished_at >= ?
+	`, storedTime(since.Add(-storedTimeMargin)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs CompletedRuns
+	for rows.Next() {
+		var run CompletedRun
+		if err := rows.Scan(&run.TaskID, &run.FinishedAt); err != nil {
+			return nil, err
+		}
+		if !run.FinishedAt.Before(since) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, rows.Err()
+}
+
+// TasksSince returns how many tasks had a run complete at or after since
+func (runs CompletedRuns) TasksSince(since time.Time) int {
+	tasks := make(map[string]bool)
+	for _, run := range runs {
+		if !run.FinishedAt.Before(since) {
+			tasks[run.TaskID] = true
+		}
+	}
+	return len(tasks)
+}
+
+// TasksPerInterval returns how many tasks had a run complete in each of n
+// consecutive intervals, the first starting at start. A task completed twice
+// in one interval counts once.
+func (runs CompletedRuns) TasksPerInterval(start time.Time, interval time.Duration, n int) []int {
+	counts := make([]int, max(n, 0))
+	if n <= 0 || interval <= 0 {
+		return counts
+	}
+
+	seen := make(map[string]bool) // "<bucket>/<task>"
+	for _, run := range runs {
+		if run.FinishedAt.Before(start) {
+			continue
+		}
+		bucket := int(run.FinishedAt.Sub(start) / interval)
+		if bucket >= n {
+			continue
+		}
+		key := fmt.Sprintf("%d/%s", bucket, run.TaskID)
+		if !seen[key] {
+			seen[key] = true
+			counts[bucket]++
+		}
+	}
+	return counts
+}
+
 // GetGroupPriorities returns all group priorities as a map
 func (s *Store) GetGroupPriorities() (map[string]int, error) {
 	rows, err := s.db.Query("SELECT group_name, priority FROM group_priorities")
@@ -418,28 +761,173 @@ func (s *Store) RemoveGroupPriority(group string) error {
 	return err
 }
 
+// GetGroupWeights returns all group weights as a map
+func (s *Store) GetGroupWeights() (map[string]int, error) {
+	rows, err := s.db.Query("SELECT group_name, weight FROM group_weights")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var weight int
+		if err := rows.Scan(&name, &weight); err != nil {
+			return nil, err
+		}
+		weights[name] = weight
+	}
+	return weights, rows.Err()
+}
+
+// SetGroupWeight sets the weight of a group (upsert). Within a tier, groups
+// with a higher weight are scheduled first; a weight of 0 clears it.
+func (s *Store) SetGroupWeight(group string, weight int) error {
+	if weight == 0 {
+		_, err := s.db.Exec("DELETE FROM group_weights WHERE group_name = ?", group)
+		return err
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO group_weights (group_name, weight)
+		VALUES (?, ?)
+		ON CONFLICT(group_name) DO UPDATE SET weight = excluded.weight
+	`, group, weight)
+	return err
+}
+
+// GetLastPassingCommits returns the commit each module's tests last passed
+// at, by module name
+func (s *Store) GetLastPassingCommits() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT module, commit_sha FROM module_test_passes")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commits := make(map[string]string)
+	for rows.Next() {
+		var module, commit string
+		if err := rows.Scan(&module, &commit); err != nil {
+			return nil, err
+		}
+		commits[module] = commit
+	}
+	return commits, rows.Err()
+}
+
+// SetLastPassingCommit records the commit a module's tests passed at (upsert)
+func (s *Store) SetLastPassingCommit(module, commit string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO module_test_passes (module, commit_sha, passed_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(module) DO UPDATE SET commit_sha = excluded.commit_sha, passed_at = excluded.passed_at
+	`, module, commit)
+	return err
+}
+
+// GetTaskPriorityOverrides returns all per-task priority overrides as a map
+func (s *Store) GetTaskPriorityOverrides() (map[string]int, error) {
+	rows, err := s.db.Query("SELECT task_id, priority FROM task_priority_overrides")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]int)
+	for rows.Next() {
+		var taskID string
+		var priority int
+		if err := rows.Scan(&taskID, &priority); err != nil {
+			return nil, err
+		}
+		overrides[taskID] = priority
+	}
+	return overrides, rows.Err()
+}
+
+// SetTaskPriorityOverride promotes a task ahead of its peers (upsert).
+// Lower values are scheduled first among overridden tasks.
+func (s *Store) SetTaskPriorityOverride(taskID string, priority int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO task_priority_overrides (task_id, priority)
+		VALUES (?, ?)
+		ON CONFLICT(task_id) DO UPDATE SET priority = excluded.priority
+	`, taskID, priority)
+	return err
+}
+
+// RemoveTaskPriorityOverride clears a task's priority override
+func (s *Store) RemoveTaskPriorityOverride(taskID string) error {
+	_, err := s.db.Exec("DELETE FROM task_priority_overrides WHERE task_id = ?", taskID)
+	return err
+}
+
+// SaveLastBatch replaces the recorded last batch with the given task IDs
+func (s *Store) SaveLastBatch(taskIDs []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM last_batch"); err != nil {
+		return err
+	}
+	for i, id := range taskIDs {
+		if _, err := tx.Exec("INSERT INTO last_batch (position, task_id) VALUES (?, ?)", i, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetLastBatch returns the task IDs of the last recorded batch in start order
+func (s *Store) GetLastBatch() ([]string, error) {
+	rows, err := s.db.Query("SELECT task_id FROM last_batch ORDER BY position")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taskIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		taskIDs = append(taskIDs, id)
+	}
+	return taskIDs, rows.Err()
+}
+
 // GroupStats holds aggregated task counts for a group
 type GroupStats struct {
 	Name      string
 	Priority  int // -1 if unassigned
+	Weight    int // Higher runs first within the tier
 	Total     int
 	Completed int
 }
 
-// GetGroupsWithTaskCounts returns all groups with their task statistics
+// GetGroupsWithTaskCounts returns all groups with their task statistics.
+// Groups without an explicit priority report -1 and sort as the default tier.
 func (s *Store) GetGroupsWithTaskCounts() ([]GroupStats, error) {
 	// Query task counts by module
 	rows, err := s.db.Query(`
 		SELECT
 			t.module,
 			COALESCE(gp.priority, -1) as priority,
+			COALESCE(gw.weight, 0) as weight,
 			COUNT(*) as total,
 			SUM(CASE WHEN t.status = 'complete' THEN 1 ELSE 0 END) as completed
 		FROM tasks t
 		LEFT JOIN group_priorities gp ON t.module = gp.group_name
+		LEFT JOIN group_weights gw ON t.module = gw.group_name
+		WHERE t.archived_at IS NULL
 		GROUP BY t.module
-		ORDER BY COALESCE(gp.priority, 0), t.module
-	`)
+		ORDER BY COALESCE(gp.priority, ?), COALESCE(gw.weight, 0) DESC, t.module
+	`, s.defaultGroupPriority)
 	if err != nil {
 		return nil, err
 	}
@@ -448,7 +936,7 @@ func (s *Store) GetGroupsWithTaskCounts() ([]GroupStats, error) {
 	var stats []GroupStats
 	for rows.Next() {
 		var gs GroupStats
-		if err := rows.Scan(&gs.Name, &gs.Priority, &gs.Total, &gs.Completed); err != nil {
+		if err := rows.Scan(&gs.Name, &gs.Priority, &gs.Weight, &gs.Total, &gs.Completed); err != nil {
 			return nil, err
 		}
 		stats = append(stats, gs)
@@ -456,20 +944,221 @@ func (s *Store) GetGroupsWithTaskCounts() ([]GroupStats, error) {
 	return stats, rows.Err()
 }
 
+// Spend grouping keys for GetSpend
+const (
+	SpendByModule = "module"
+	SpendByDay    = "day"
+)
+
+// SpendGroup holds aggregated token usage and cost for one module or day
+type SpendGroup struct {
+	Key          string // Module name, or start date as YYYY-MM-DD (local time)
+	Runs         int
+	TokensInput  int
+	TokensOutput int
+	CostUSD      float64
+}
+
+// GetSpend aggregates agent run token usage and cost grouped by module or by
+// start day in local time, for runs started at or after since (zero = all
+// runs). Groups are returned sorted by key.
+func (s *Store) GetSpend(groupBy string, since time.Time) ([]SpendGroup, error) {
+	if groupBy != SpendByModule && groupBy != SpendByDay {
+		return nil, fmt.Errorf("invalid spend grouping %q: must be %q or %q", groupBy, SpendByModule, SpendByDay)
+	}
+
+	// Stored times compare as text in the writer's zone, so SQL only narrows
+	// the runs down and the exact cut is made on the parsed time
+	var from string
+	if !since.IsZero() {
+		from = storedTime(since.Add(-storedTimeMargin))
+	}
+	rows, err := s.db.Query(`
+		SELECT task_id, started_at,
+		       COALESCE(tokens_input, 0), COALESCE(tokens_output, 0), COALESCE(cost_usd, 0)
+		FROM agent_runs
+		WHERE ?1 = '' OR started_at >= ?1
+	`, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[string]*SpendGroup)
+	for rows.Next() {
+		var taskID string
+		var startedAt time.Time
+		var in, out int
+		var cost float64
+		if err := rows.Scan(&taskID, &startedAt, &in, &out, &cost); err != nil {
+			return nil, err
+		}
+		if startedAt.Before(since) {
+			continue
+		}
+
+		key, _, _ := strings.Cut(taskID, "/")
+		if groupBy == SpendByDay {
+			key = startedAt.Local().Format("2006-01-02")
+		}
+		g := groups[key]
+		if g == nil {
+			g = &SpendGroup{Key: key}
+			groups[key] = g
+		}
+		g.Runs++
+		g.TokensInput += in
+		g.TokensOutput += out
+		g.CostUSD += cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]SpendGroup, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// RunAverage holds the average token usage and cost of a module's agent runs
+type RunAverage struct {
+	Runs         int
+	TokensInput  float64
+	TokensOutput float64
+	CostUSD      float64
+}
+
+// GetModuleRunAverages returns the average token usage and cost per agent run,
+// keyed by module. Runs without recorded usage are not counted.
+func (s *Store) GetModuleRunAverages() (map[string]RunAverage, error) {
+	rows, err := s.db.Query(`
+		SELECT substr(task_id, 1, instr(task_id, '/') - 1) AS module, COUNT(*),
+		       AVG(COALESCE(tokens_input, 0)), AVG(COALESCE(tokens_output, 0)), AVG(COALESCE(cost_usd, 0))
+		FROM agent_runs
+		WHERE COALESCE(tokens_input, 0) + COALESCE(tokens_output, 0) > 0 OR COALESCE(cost_usd, 0) > 0
+		GROUP BY module
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	averages := make(map[string]RunAverage)
+	for rows.Next() {
+		var module string
+		var avg RunAverage
+		if err := rows.Scan(&module, &avg.Runs, &avg.TokensInput, &avg.TokensOutput, &avg.CostUSD); err != nil {
+			return nil, err
+		}
+		averages[module] = avg
+	}
+	return averages, rows.Err()
+}
+
+// BatchEstimate is the expected token usage and cost of running a set of tasks
+type BatchEstimate struct {
+	Tasks        int // Tasks in the batch
+	FromModule   int // Tasks estimated from their module's runs
+	FromOverall  int // Tasks in modules without runs, estimated from the average of all runs
+	TokensInput  int
+	TokensOutput int
+	CostUSD      float64
+}
+
+// HasHistory reports whether any task of the batch could be estimated
+func (e BatchEstimate) HasHistory() bool {
+	return e.FromModule+e.FromOverall > 0
+}
+
+// EstimateBatch sums the per-run averages over tasks. Tasks in modules without
+// runs are estimated from the average of all runs; without any runs at all the
+// estimate is empty.
+func EstimateBatch(tasks []*domain.Task, averages map[string]RunAverage) BatchEstimate {
+	var overall RunAverage
+	for _, avg := range averages {
+		overall.Runs += avg.Runs
+		overall.TokensInput += avg.TokensInput * float64(avg.Runs)
+		overall.TokensOutput += avg.TokensOutput * float64(avg.Runs)
+		overall.CostUSD += avg.CostUSD * float64(avg.Runs)
+	}
+	if overall.Runs > 0 {
+		overall.TokensInput /= float64(overall.Runs)
+		overall.TokensOutput /= float64(overall.Runs)
+		overall.CostUSD /= float64(overall.Runs)
+	}
+
+	estimate := BatchEstimate{Tasks: len(tasks)}
+	var tokensIn, tokensOut float64
+	for _, task := range tasks {
+		avg, ok := averages[task.ID.Module]
+		switch {
+		case ok:
+			estimate.FromModule++
+		case overall.Runs > 0:
+			avg = overall
+			estimate.FromOverall++
+		default:
+			continue
+		}
+		tokensIn += avg.TokensInput
+		tokensOut += avg.TokensOutput
+		estimate.CostUSD += avg.CostUSD
+	}
+	estimate.TokensInput = int(math.Round(tokensIn))
+	estimate.TokensOutput = int(math.Round(tokensOut))
+	return estimate
+}
+
 // ListRecentAgentRuns returns completed/failed agent runs, in chronological order (oldest first)
 func (s *Store) ListRecentAgentRuns(limit int) ([]*AgentRun, error) {
+	return s.listFinishedAgentRuns("", limit)
+}
+
+// SearchAgentRuns returns the most recent completed/failed agent runs whose
+// notes, task ID or error message contain query (ignoring case), in
+// chronological order (oldest first)
+func (s *Store) SearchAgentRuns(query string, limit int) ([]*AgentRun, error) {
+	return s.listFinishedAgentRuns(query, limit)
+}
+
+// AnnotateAgentRun adds a note to an agent run, after any it already has
+func (s *Store) AnnotateAgentRun(id, note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return fmt.Errorf("empty note")
+	}
+	res, err := s.db.Exec(`
+		UPDATE agent_runs SET notes = CASE WHEN notes = '' THEN ? ELSE notes || char(10) || ? END
+		WHERE id = ?
+	`, note, note, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("agent run %s not found", id)
+	}
+	return nil
+}
+
+// listFinishedAgentRuns returns the limit most recent completed/failed agent
+// runs matching query (empty = all), in chronological order
+func (s *Store) listFinishedAgentRuns(query string, limit int) ([]*AgentRun, error) {
 	// Get the N most recent runs, then reverse to show in chronological order
 	rows, err := s.db.Query(`
 		SELECT id, task_id, worktree_path, log_path, pid, status, started_at, finished_at,
-		       error_message, COALESCE(session_id, ''), tokens_input, tokens_output, cost_usd
+		       error_message, COALESCE(session_id, ''), tokens_input, tokens_output, cost_usd, notes
 		FROM (
 			SELECT * FROM agent_runs
 			WHERE status IN ('completed', 'failed')
+			  AND (?1 = '' OR instr(lower(notes || char(10) || task_id || char(10) || COALESCE(error_message, '')), lower(?1)) > 0)
 			ORDER BY COALESCE(finished_at, started_at) DESC
-			LIMIT ?
+			LIMIT ?2
 		) sub
 		ORDER BY COALESCE(finished_at, started_at) ASC
-	`, limit)
+	`, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -483,7 +1172,7 @@ func (s *Store) ListRecentAgentRuns(limit int) ([]*AgentRun, error) {
 
 		err := rows.Scan(&run.ID, &run.TaskID, &run.WorktreePath, &run.LogPath, &run.PID,
 			&run.Status, &run.StartedAt, &finishedAt, &errorMsg, &run.SessionID,
-			&run.TokensInput, &run.TokensOutput, &run.CostUSD)
+			&run.TokensInput, &run.TokensOutput, &run.CostUSD, &run.Notes)
 		if err != nil {
 			return nil, err
 		}
@@ -648,7 +1337,7 @@ func (s *Store) ListGitHubIssues() ([]*domain.GitHubIssue, error) {
 func (s *Store) GetIncompleteEpicsForIssue(issueNumber int) ([]*domain.Task, error) {
 	rows, err := s.db.Query(`
 		SELECT id, module, prefix, epic_num, title, description, status, priority, depends_on, needs_review, file_path, github_issue, created_at, updated_at
-		FROM tasks WHERE github_issue = ? AND status != ?
+		FROM tasks WHERE github_issue = ? AND status != ? AND archived_at IS NULL
 	`, issueNumber, string(domain.StatusComplete))
 	if err != nil {
 		return nil, err
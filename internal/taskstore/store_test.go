@@ -1,6 +1,9 @@
 package taskstore
 
 import (
+	"database/sql"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -123,6 +126,112 @@ func TestStore_UpdateTaskStatus(t *testing.T) {
 	}
 }
 
+func TestStore_ReopenTask(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	store.UpsertTask(&domain.Task{
+		ID:     domain.TaskID{Module: "technical", EpicNum: 1},
+		Title:  "Done",
+		Status: domain.StatusComplete,
+	})
+	store.UpsertTask(&domain.Task{
+		ID:     domain.TaskID{Module: "technical", EpicNum: 2},
+		Title:  "Running",
+		Status: domain.StatusInProgress,
+	})
+
+	if err := store.ReopenTask("technical/E01"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := store.GetTask("technical/E01")
+	if got.Status != domain.StatusNotStarted {
+		t.Errorf("Status = %q, want not_started", got.Status)
+	}
+	completed, _ := store.GetCompletedTaskIDs()
+	if completed["technical/E01"] {
+		t.Error("reopened task should not be reported as completed")
+	}
+
+	events, err := store.ListTaskEvents("technical/E01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if e := events[0]; e.Event != TaskEventReopened || e.FromStatus != domain.StatusComplete || e.ToStatus != domain.StatusNotStarted {
+		t.Errorf("event = %+v, want reopened complete -> not_started", e)
+	}
+
+	// Only complete tasks can be reopened, and a failed reopen records nothing
+	if err := store.ReopenTask("technical/E02"); err == nil {
+		t.Error("expected error reopening an in-progress task")
+	}
+	if err := store.ReopenTask("technical/E01"); err == nil {
+		t.Error("expected error reopening an already reopened task")
+	}
+	if err := store.ReopenTask("technical/E99"); err == nil {
+		t.Error("expected error reopening an unknown task")
+	}
+	if events, _ := store.ListTaskEvents("technical/E01"); len(events) != 1 {
+		t.Errorf("got %d events after failed reopens, want 1", len(events))
+	}
+}
+
+func TestStore_ArchiveTask(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	archived := &domain.Task{
+		ID:     domain.TaskID{Module: "technical", EpicNum: 1},
+		Title:  "Gone",
+		Status: domain.StatusInProgress,
+	}
+	store.UpsertTask(archived)
+	store.UpsertTask(&domain.Task{
+		ID:     domain.TaskID{Module: "billing", EpicNum: 1},
+		Title:  "Kept",
+		Status: domain.StatusNotStarted,
+	})
+
+	if err := store.ArchiveTask("technical/E01"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, _ := store.ListTasks(ListOptions{})
+	if len(tasks) != 1 || tasks[0].ID.String() != "billing/E01" {
+		t.Errorf("listed tasks = %v, want only billing/E01", tasks)
+	}
+	if tasks, _ := store.ListTasks(ListOptions{IncludeArchived: true}); len(tasks) != 2 {
+		t.Errorf("got %d tasks including archived, want 2", len(tasks))
+	}
+	if groups, _ := store.GetGroupsWithTaskCounts(); len(groups) != 1 || groups[0].Name != "billing" {
+		t.Errorf("groups = %+v, want only billing", groups)
+	}
+	events, _ := store.ListTaskEvents("technical/E01")
+	if len(events) != 1 || events[0].Event != TaskEventArchived || events[0].FromStatus != domain.StatusInProgress {
+		t.Errorf("events = %+v, want one archived event from in_progress", events)
+	}
+
+	// Upserting the task again restores it
+	store.UpsertTask(archived)
+	if tasks, _ := store.ListTasks(ListOptions{}); len(tasks) != 2 {
+		t.Errorf("got %d tasks after restoring, want 2", len(tasks))
+	}
+
+	if err := store.ArchiveTask("technical/E99"); err == nil {
+		t.Error("expected error archiving an unknown task")
+	}
+}
+
 func TestGroupPrioritiesTableExists(t *testing.T) {
 	store, err := New(":memory:")
 	if err != nil {
@@ -215,6 +324,38 @@ func TestRemoveGroupPriority(t *testing.T) {
 	}
 }
 
+func TestTaskPriorityOverrides(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SetTaskPriorityOverride("billing/E03", 0); err != nil {
+		t.Fatalf("SetTaskPriorityOverride() error = %v", err)
+	}
+	// Upsert keeps a single row per task
+	if err := store.SetTaskPriorityOverride("billing/E03", 2); err != nil {
+		t.Fatalf("SetTaskPriorityOverride() update error = %v", err)
+	}
+
+	overrides, err := store.GetTaskPriorityOverrides()
+	if err != nil {
+		t.Fatalf("GetTaskPriorityOverrides() error = %v", err)
+	}
+	if len(overrides) != 1 || overrides["billing/E03"] != 2 {
+		t.Errorf("overrides = %v, want map[billing/E03:2]", overrides)
+	}
+
+	if err := store.RemoveTaskPriorityOverride("billing/E03"); err != nil {
+		t.Fatalf("RemoveTaskPriorityOverride() error = %v", err)
+	}
+	overrides, _ = store.GetTaskPriorityOverrides()
+	if len(overrides) != 0 {
+		t.Errorf("overrides = %v, want empty after removal", overrides)
+	}
+}
+
 func TestStore_GitHubIssuesTableExists(t *testing.T) {
 	store, err := New(":memory:")
 	if err != nil {
@@ -312,6 +453,117 @@ func TestGetGroupsWithTaskCounts(t *testing.T) {
 	}
 }
 
+func TestGetGroupsWithTaskCounts_DefaultGroupPriority(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	for _, module := range []string{"auth", "billing"} {
+		store.UpsertTask(&domain.Task{
+			ID:        domain.TaskID{Module: module, EpicNum: 0},
+			Title:     "Setup",
+			Status:    domain.StatusNotStarted,
+			FilePath:  "test.md",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	store.SetGroupPriority("billing", 5)
+
+	// Unassigned groups sort as tier 0 by default, ahead of billing
+	stats, err := store.GetGroupsWithTaskCounts()
+	if err != nil {
+		t.Fatalf("GetGroupsWithTaskCounts() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].Name != "auth" {
+		t.Fatalf("stats = %+v, want auth first", stats)
+	}
+
+	// With a default of 99, unassigned groups sort after billing
+	store.SetDefaultGroupPriority(99)
+	stats, err = store.GetGroupsWithTaskCounts()
+	if err != nil {
+		t.Fatalf("GetGroupsWithTaskCounts() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].Name != "billing" {
+		t.Fatalf("stats = %+v, want billing first", stats)
+	}
+	if stats[1].Priority != -1 {
+		t.Errorf("auth.Priority = %d, want -1 (unassigned)", stats[1].Priority)
+	}
+}
+
+func TestGroupWeights(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	for _, module := range []string{"auth", "billing", "zoning"} {
+		store.UpsertTask(&domain.Task{
+			ID:        domain.TaskID{Module: module, EpicNum: 0},
+			Title:     "Setup",
+			Status:    domain.StatusNotStarted,
+			FilePath:  "test.md",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	store.SetGroupPriority("auth", 0)
+	store.SetGroupPriority("zoning", 0)
+	if err := store.SetGroupWeight("zoning", 2); err != nil {
+		t.Fatalf("SetGroupWeight() error = %v", err)
+	}
+	if err := store.SetGroupWeight("billing", 1); err != nil {
+		t.Fatalf("SetGroupWeight() error = %v", err)
+	}
+
+	weights, err := store.GetGroupWeights()
+	if err != nil {
+		t.Fatalf("GetGroupWeights() error = %v", err)
+	}
+	if weights["zoning"] != 2 || weights["billing"] != 1 {
+		t.Errorf("weights = %v, want zoning 2 and billing 1", weights)
+	}
+
+	// A weight leaves the tier alone: billing stays unassigned
+	priorities, _ := store.GetGroupPriorities()
+	if _, exists := priorities["billing"]; exists {
+		t.Error("weighting billing assigned it a tier")
+	}
+
+	// Within a tier, heavier groups come first
+	stats, err := store.GetGroupsWithTaskCounts()
+	if err != nil {
+		t.Fatalf("GetGroupsWithTaskCounts() error = %v", err)
+	}
+	var names []string
+	for _, gs := range stats {
+		names = append(names, gs.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"zoning", "billing", "auth"}) {
+		t.Errorf("groups = %v, want zoning, billing, auth", names)
+	}
+	if stats[0].Weight != 2 {
+		t.Errorf("zoning.Weight = %d, want 2", stats[0].Weight)
+	}
+
+	// Unassigning the tier keeps the weight; a weight of 0 clears it
+	store.RemoveGroupPriority("zoning")
+	if err := store.SetGroupWeight("billing", 0); err != nil {
+		t.Fatalf("SetGroupWeight(0) error = %v", err)
+	}
+	weights, _ = store.GetGroupWeights()
+	if len(weights) != 1 || weights["zoning"] != 2 {
+		t.Errorf("weights = %v, want only zoning's", weights)
+	}
+}
+
 func TestStore_UpsertAndGetGitHubIssue(t *testing.T) {
 	store, err := New(":memory:")
 	if err != nil {
@@ -503,3 +755,475 @@ func TestStore_GetIncompleteEpicsForIssue(t *testing.T) {
 		t.Errorf("got %d incomplete tasks, want 2", len(tasks))
 	}
 }
+
+func TestGetSpend(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 3, 2, 15, 0, 0, 0, time.Local)
+	runs := []struct {
+		id, taskID string
+		startedAt  time.Time
+		in, out    int
+		cost       float64
+	}{
+		{"run-1", "billing/E01", day1, 1000, 200, 1.50},
+		{"run-2", "billing/E02", day2, 500, 100, 0.75},
+		{"run-3", "pricing/CLI03", day1, 300, 50, 0.25},
+	}
+	for _, r := range runs {
+		if err := store.SaveAgentRun(&AgentRun{
+			ID: r.id, TaskID: r.taskID, WorktreePath: "/tmp/wt", LogPath: "/tmp/log",
+			Status: "completed", StartedAt: r.startedAt,
+		}); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+		if err := store.UpdateAgentRunUsage(r.id, r.in, r.out, r.cost); err != nil {
+			t.Fatalf("UpdateAgentRunUsage() error = %v", err)
+		}
+	}
+
+	t.Run("by module", func(t *testing.T) {
+		groups, err := store.GetSpend(SpendByModule, time.Time{})
+		if err != nil {
+			t.Fatalf("GetSpend() error = %v", err)
+		}
+		want := []SpendGroup{
+			{Key: "billing", Runs: 2, TokensInput: 1500, TokensOutput: 300, CostUSD: 2.25},
+			{Key: "pricing", Runs: 1, TokensInput: 300, TokensOutput: 50, CostUSD: 0.25},
+		}
+		assertSpend(t, groups, want)
+	})
+
+	t.Run("by day", func(t *testing.T) {
+		groups, err := store.GetSpend(SpendByDay, time.Time{})
+		if err != nil {
+			t.Fatalf("GetSpend() error = %v", err)
+		}
+		want := []SpendGroup{
+			{Key: "2026-03-01", Runs: 2, TokensInput: 1300, TokensOutput: 250, CostUSD: 1.75},
+			{Key: "2026-03-02", Runs: 1, TokensInput: 500, TokensOutput: 100, CostUSD: 0.75},
+		}
+		assertSpend(t, groups, want)
+	})
+
+	t.Run("since", func(t *testing.T) {
+		groups, err := store.GetSpend(SpendByModule, day2)
+		if err != nil {
+			t.Fatalf("GetSpend() error = %v", err)
+		}
+		want := []SpendGroup{
+			{Key: "billing", Runs: 1, TokensInput: 500, TokensOutput: 100, CostUSD: 0.75},
+		}
+		assertSpend(t, groups, want)
+	})
+
+	t.Run("written in another zone", func(t *testing.T) {
+		store, err := New(":memory:")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer store.Close()
+
+		// Stored as 03:00 on March 2nd, but started on March 1st in local time
+		_, offset := day1.Zone()
+		ahead := time.FixedZone("AHT", offset+12*3600)
+		started := time.Date(2026, 3, 1, 15, 0, 0, 0, time.Local)
+		if err := store.SaveAgentRun(&AgentRun{ID: "run-1", TaskID: "billing/E01", Status: "completed", StartedAt: started.In(ahead)}); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+
+		groups, err := store.GetSpend(SpendByDay, time.Time{})
+		if err != nil {
+			t.Fatalf("GetSpend() error = %v", err)
+		}
+		assertSpend(t, groups, []SpendGroup{{Key: "2026-03-01", Runs: 1}})
+
+		groups, err = store.GetSpend(SpendByModule, time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local))
+		if err != nil {
+			t.Fatalf("GetSpend() error = %v", err)
+		}
+		assertSpend(t, groups, []SpendGroup{})
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		if _, err := store.GetSpend("week", time.Time{}); err == nil {
+			t.Error("expected error for unknown grouping")
+		}
+	})
+}
+
+func TestGetModuleRunAverages(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	averages, err := store.GetModuleRunAverages()
+	if err != nil {
+		t.Fatalf("GetModuleRunAverages() error = %v", err)
+	}
+	if len(averages) != 0 {
+		t.Errorf("averages without runs = %v, want none", averages)
+	}
+
+	runs := []struct {
+		id, taskID string
+		in, out    int
+		cost       float64
+	}{
+		{"run-1", "billing/E01", 1000, 200, 1.50},
+		{"run-2", "billing/E02", 500, 100, 0.50},
+		{"run-3", "pricing/CLI03", 300, 50, 0.25},
+		{"run-4", "pricing/CLI04", 0, 0, 0}, // No usage recorded
+	}
+	for _, r := range runs {
+		if err := store.SaveAgentRun(&AgentRun{
+			ID: r.id, TaskID: r.taskID, WorktreePath: "/tmp/wt", LogPath: "/tmp/log",
+			Status: "completed", StartedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+		if err := store.UpdateAgentRunUsage(r.id, r.in, r.out, r.cost); err != nil {
+			t.Fatalf("UpdateAgentRunUsage() error = %v", err)
+		}
+	}
+
+	averages, err = store.GetModuleRunAverages()
+	if err != nil {
+		t.Fatalf("GetModuleRunAverages() error = %v", err)
+	}
+	want := map[string]RunAverage{
+		"billing": {Runs: 2, TokensInput: 750, TokensOutput: 150, CostUSD: 1.00},
+		"pricing": {Runs: 1, TokensInput: 300, TokensOutput: 50, CostUSD: 0.25},
+	}
+	if !reflect.DeepEqual(averages, want) {
+		t.Errorf("GetModuleRunAverages() = %+v, want %+v", averages, want)
+	}
+}
+
+func TestEstimateBatch(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 3}},
+		{ID: domain.TaskID{Module: "billing", EpicNum: 4}},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 1}},
+	}
+
+	t.Run("without history", func(t *testing.T) {
+		estimate := EstimateBatch(tasks, nil)
+		if estimate.HasHistory() || estimate != (BatchEstimate{Tasks: 3}) {
+			t.Errorf("EstimateBatch() = %+v, want an empty estimate for 3 tasks", estimate)
+		}
+	})
+
+	t.Run("with history", func(t *testing.T) {
+		averages := map[string]RunAverage{
+			"billing": {Runs: 3, TokensInput: 1000, TokensOutput: 200, CostUSD: 2.00},
+			"pricing": {Runs: 1, TokensInput: 600, TokensOutput: 100, CostUSD: 1.00},
+		}
+		// zoning has no runs: estimated from the average of all 4 runs
+		want := BatchEstimate{
+			Tasks: 3, FromModule: 2, FromOverall: 1,
+			TokensInput: 2*1000 + 900, TokensOutput: 2*200 + 175, CostUSD: 2*2.00 + 1.75,
+		}
+		estimate := EstimateBatch(tasks, averages)
+		if !estimate.HasHistory() || estimate != want {
+			t.Errorf("EstimateBatch() = %+v, want %+v", estimate, want)
+		}
+	})
+}
+
+func assertSpend(t *testing.T, got, want []SpendGroup) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d groups %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Key != w.Key || g.Runs != w.Runs || g.TokensInput != w.TokensInput || g.TokensOutput != w.TokensOutput {
+			t.Errorf("group[%d] = %+v, want %+v", i, g, w)
+		}
+		if diff := g.CostUSD - w.CostUSD; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("group[%d] cost = %f, want %f", i, g.CostUSD, w.CostUSD)
+		}
+	}
+}
+
+func TestLastBatch(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	ids, err := store.GetLastBatch()
+	if err != nil {
+		t.Fatalf("GetLastBatch() error = %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("GetLastBatch() = %v, want empty before any batch", ids)
+	}
+
+	if err := store.SaveLastBatch([]string{"billing/E03", "technical/E01"}); err != nil {
+		t.Fatalf("SaveLastBatch() error = %v", err)
+	}
+	// A newer batch replaces the previous one
+	if err := store.SaveLastBatch([]string{"technical/E02", "billing/E01"}); err != nil {
+		t.Fatalf("SaveLastBatch() error = %v", err)
+	}
+
+	ids, err = store.GetLastBatch()
+	if err != nil {
+		t.Fatalf("GetLastBatch() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "technical/E02" || ids[1] != "billing/E01" {
+		t.Errorf("GetLastBatch() = %v, want [technical/E02 billing/E01]", ids)
+	}
+}
+
+func TestStore_ListCompletedSince(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	since := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	before := since.Add(-time.Minute)
+	after := since.Add(time.Hour)
+	runs := []struct {
+		id, taskID, status string
+		finishedAt         time.Time
+	}{
+		{"run-1", "billing/E01", "completed", before},
+		{"run-2", "billing/E02", "completed", after},
+		{"run-3", "billing/E02", "completed", after.Add(time.Hour)}, // Same task again
+		{"run-4", "billing/E03", "failed", after},
+		{"run-5", "pricing/E01", "completed", since.In(time.FixedZone("EST", -5*3600))},
+	}
+	for _, r := range runs {
+		finishedAt := r.finishedAt
+		if err := store.SaveAgentRun(&AgentRun{
+			ID: r.id, TaskID: r.taskID, WorktreePath: "/tmp/wt", LogPath: "/tmp/log",
+			Status: r.status, StartedAt: before.Add(-time.Hour), FinishedAt: &finishedAt,
+		}); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+	}
+
+	completed, err := store.ListCompletedSince(since)
+	if err != nil {
+		t.Fatalf("ListCompletedSince() error = %v", err)
+	}
+	if len(completed) != 3 {
+		t.Errorf("ListCompletedSince() = %d runs, want the 3 completed at or after since", len(completed))
+	}
+	if got := completed.TasksSince(since); got != 2 {
+		t.Errorf("TasksSince() = %d, want 2 (billing/E02 and pricing/E01)", got)
+	}
+}
+
+func TestCompletedRuns_TasksPerInterval(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	runs := []struct {
+		id, taskID, status string
+		finishedAt         time.Time
+	}{
+		{"run-1", "billing/E01", "completed", start.Add(-time.Minute)}, // Before the first interval
+		{"run-2", "billing/E02", "completed", start},
+		{"run-3", "billing/E02", "completed", start.Add(20 * time.Minute)}, // Same task, same interval
+		{"run-4", "billing/E03", "completed", start.Add(59 * time.Minute)},
+		{"run-5", "billing/E04", "failed", start.Add(70 * time.Minute)},
+		{"run-6", "pricing/E01", "completed", start.Add(2 * time.Hour).In(time.FixedZone("EST", -5*3600))},
+		{"run-7", "pricing/E02", "completed", start.Add(3 * time.Hour)}, // After the last interval
+	}
+	for _, r := range runs {
+		finishedAt := r.finishedAt
+		if err := store.SaveAgentRun(&AgentRun{
+			ID: r.id, TaskID: r.taskID, WorktreePath: "/tmp/wt", LogPath: "/tmp/log",
+			Status: r.status, StartedAt: start.Add(-2 * time.Hour), FinishedAt: &finishedAt,
+		}); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+	}
+
+	completed, err := store.ListCompletedSince(start)
+	if err != nil {
+		t.Fatalf("ListCompletedSince() error = %v", err)
+	}
+	if want, got := []int{2, 0, 1}, completed.TasksPerInterval(start, time.Hour, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("TasksPerInterval() = %v, want %v", got, want)
+	}
+}
+
+func TestStore_WriteAgentRuns(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	started := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	err = store.WriteAgentRuns(func(tx *AgentRunTx) error {
+		if err := tx.SaveAgentRun(&AgentRun{ID: "run-1", TaskID: "billing/E01", Status: "running", StartedAt: started}); err != nil {
+			return err
+		}
+		return tx.UpdateAgentRunStatus("run-1", "failed", "exit status 1")
+	})
+	if err != nil {
+		t.Fatalf("WriteAgentRuns() error = %v", err)
+	}
+	run, err := store.GetAgentRun("run-1")
+	if err != nil {
+		t.Fatalf("GetAgentRun() error = %v", err)
+	}
+	if run.Status != "failed" || run.ErrorMessage != "exit status 1" {
+		t.Errorf("run = %s %q, want the writes committed in order", run.Status, run.ErrorMessage)
+	}
+
+	// A failing batch is rolled back as a whole
+	err = store.WriteAgentRuns(func(tx *AgentRunTx) error {
+		if err := tx.DeleteAgentRun("run-1"); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("WriteAgentRuns() error = nil, want the error of fn")
+	}
+	if _, err := store.GetAgentRun("run-1"); err != nil {
+		t.Errorf("GetAgentRun() error = %v, want the delete rolled back", err)
+	}
+}
+
+func TestStore_LastPassingCommits(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SetLastPassingCommit("billing", "abc123"); err != nil {
+		t.Fatalf("SetLastPassingCommit() error = %v", err)
+	}
+	store.SetLastPassingCommit("pricing", "def456")
+	store.SetLastPassingCommit("billing", "fed789") // Tests passed again later
+
+	commits, err := store.GetLastPassingCommits()
+	if err != nil {
+		t.Fatalf("GetLastPassingCommits() error = %v", err)
+	}
+	if want := map[string]string{"billing": "fed789", "pricing": "def456"}; !reflect.DeepEqual(commits, want) {
+		t.Errorf("GetLastPassingCommits() = %v, want %v", commits, want)
+	}
+}
+
+func TestStore_GetLatestAgentRun(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.GetLatestAgentRun("billing/E01"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLatestAgentRun() error = %v, want sql.ErrNoRows for a task without runs", err)
+	}
+
+	started := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store.SaveAgentRun(&AgentRun{ID: "run-2", TaskID: "billing/E01", Status: "failed", StartedAt: started.Add(time.Hour), SessionID: "session-2"})
+	store.SaveAgentRun(&AgentRun{ID: "run-1", TaskID: "billing/E01", Status: "completed", StartedAt: started})
+	store.SaveAgentRun(&AgentRun{ID: "run-3", TaskID: "billing/E02", Status: "running", StartedAt: started.Add(2 * time.Hour)})
+
+	run, err := store.GetLatestAgentRun("billing/E01")
+	if err != nil {
+		t.Fatalf("GetLatestAgentRun() error = %v", err)
+	}
+	if run.ID != "run-2" || run.SessionID != "session-2" {
+		t.Errorf("latest run = %s (session %q), want run-2 of the task", run.ID, run.SessionID)
+	}
+}
+
+func TestStore_AnnotateAgentRun(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer store.Close()
+
+	started := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	for _, run := range []*AgentRun{
+		{ID: "run-1", TaskID: "billing/E01", Status: "completed", StartedAt: started},
+		{ID: "run-2", TaskID: "billing/E02", Status: "failed", StartedAt: started.Add(time.Hour), ErrorMessage: "tests failed"},
+		{ID: "run-3", TaskID: "pricing/E01", Status: "running", StartedAt: started.Add(2 * time.Hour)},
+	} {
+		if err := store.SaveAgentRun(run); err != nil {
+			t.Fatalf("SaveAgentRun() error = %v", err)
+		}
+	}
+
+	if err := store.AnnotateAgentRun("run-1", "Fixed the flaky test"); err != nil {
+		t.Fatalf("AnnotateAgentRun() error = %v", err)
+	}
+	if err := store.AnnotateAgentRun("run-1", "  reverted later\n"); err != nil {
+		t.Fatalf("AnnotateAgentRun() error = %v", err)
+	}
+	if err := store.AnnotateAgentRun("run-3", "still running, FLAKY too"); err != nil {
+		t.Fatalf("AnnotateAgentRun() error = %v", err)
+	}
+	run, err := store.GetAgentRun("run-1")
+	if err != nil {
+		t.Fatalf("GetAgentRun() error = %v", err)
+	}
+	if run.Notes != "Fixed the flaky test\nreverted later" {
+		t.Errorf("Notes = %q, want both notes in order", run.Notes)
+	}
+
+	if err := store.AnnotateAgentRun("run-9", "missing"); err == nil {
+		t.Error("AnnotateAgentRun() of an unknown run should fail")
+	}
+	if err := store.AnnotateAgentRun("run-2", " "); err == nil {
+		t.Error("AnnotateAgentRun() with an empty note should fail")
+	}
+
+	recent, err := store.ListRecentAgentRuns(10)
+	if err != nil {
+		t.Fatalf("ListRecentAgentRuns() error = %v", err)
+	}
+	if len(recent) != 2 || recent[0].Notes != run.Notes || recent[1].Notes != "" {
+		t.Errorf("ListRecentAgentRuns() = %d runs, want both finished runs with their notes", len(recent))
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"flaky", []string{"run-1"}}, // Notes, ignoring case; the running agent is not history
+		{"billing/", []string{"run-1", "run-2"}},
+		{"tests failed", []string{"run-2"}},
+		{"nothing like this", nil},
+	}
+	for _, tt := range tests {
+		runs, err := store.SearchAgentRuns(tt.query, 10)
+		if err != nil {
+			t.Fatalf("SearchAgentRuns(%q) error = %v", tt.query, err)
+		}
+		var got []string
+		for _, run := range runs {
+			got = append(got, run.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchAgentRuns(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package taskstore
+
+import (
+	"time"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+// SyncAuditEntry records how a sync set the status of a task on one side to
+// the status on the other
+type SyncAuditEntry struct {
+	ID         int64
+	TaskID     string
+	Direction  string            // Side that won, e.g. "markdown → db" (see sync.SyncDirection)
+	OldStatus  domain.TaskStatus // Status on the losing side ("" for a task the sync added)
+	NewStatus  domain.TaskStatus
+	Resolution bool // Set by resolving a conflict rather than by a plain sync
+	CreatedAt  time.Time
+}
+
+// RecordSyncAudit adds an entry to the sync audit log
+func (s *Store) RecordSyncAudit(e SyncAuditEntry) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sync_audit (task_id, direction, old_status, new_status, resolution) VALUES (?, ?, ?, ?, ?)
+	`, e.TaskID, e.Direction, string(e.OldStatus), string(e.NewStatus), e.Resolution)
+	return err
+}
+
+// ListSyncAudit returns the limit most recent sync audit entries of a task
+// (all tasks if taskID is empty), in chronological order
+func (s *Store) ListSyncAudit(taskID string, limit int) ([]SyncAuditEntry, error) {
+	// Filtering only when asked lets SQLite use the (task_id, created_at) index
+	where, args := "", []any{limit}
+	if taskID != "" {
+		where, args = "WHERE task_id = ?", []any{taskID, limit}
+	}
+	rows, err := s.db.Query(`
+		SELECT id, task_id, direction, old_status, new_status, resolution, created_at
+		FROM (
+			SELECT * FROM sync_audit `+where+`
+			ORDER BY created_at DESC, id DESC
+			LIMIT ?
+		) sub
+		ORDER BY created_at ASC, id ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []SyncAuditEntry
+	for rows.Next() {
+		var e SyncAuditEntry
+		var from, to string
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Direction, &from, &to, &e.Resolution, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.OldStatus = domain.TaskStatus(from)
+		e.NewStatus = domain.TaskStatus(to)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
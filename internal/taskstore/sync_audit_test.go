@@ -0,0 +1,55 @@
+package taskstore
+
+import (
+	"testing"
+
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+)
+
+func TestSyncAudit(t *testing.T) {
+	store, err := New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if entries, err := store.ListSyncAudit("", 10); err != nil || len(entries) != 0 {
+		t.Fatalf("ListSyncAudit on empty log = %v, %v", entries, err)
+	}
+
+	record := []SyncAuditEntry{
+		{TaskID: "technical/E01", Direction: "markdown → db", NewStatus: domain.StatusNotStarted},
+		{TaskID: "technical/E02", Direction: "markdown → db", NewStatus: domain.StatusNotStarted},
+		{TaskID: "technical/E01", Direction: "db → markdown", OldStatus: domain.StatusComplete, NewStatus: domain.StatusInProgress, Resolution: true},
+		{TaskID: "technical/E01", Direction: "markdown → db", OldStatus: domain.StatusInProgress, NewStatus: domain.StatusComplete, Resolution: true},
+	}
+	for _, e := range record {
+		if err := store.RecordSyncAudit(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := store.ListSyncAudit("technical/E01", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries of technical/E01, want 3", len(entries))
+	}
+	for i, want := range []SyncAuditEntry{record[0], record[2], record[3]} {
+		got := entries[i]
+		if got.TaskID != want.TaskID || got.Direction != want.Direction || got.OldStatus != want.OldStatus ||
+			got.NewStatus != want.NewStatus || got.Resolution != want.Resolution || got.CreatedAt.IsZero() {
+			t.Errorf("entries[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+
+	// The limit keeps the most recent entries, still oldest first
+	entries, err = store.ListSyncAudit("", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Direction != "db → markdown" || entries[1].NewStatus != domain.StatusComplete {
+		t.Errorf("two most recent entries = %+v, want the two resolutions in order", entries)
+	}
+}
@@ -0,0 +1,109 @@
+// Package testselect narrows a module's test run to the code changed since
+// its tests last passed
+package testselect
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// HeadCommit returns the commit checked out in repoDir
+func HeadCommit(repoDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedFiles lists the files of repoDir that differ from commit since:
+// committed, staged and unstaged changes as well as untracked files
+func ChangedFiles(repoDir, since string) ([]string, error) {
+	diff := exec.Command("git", "diff", "--name-only", since)
+	diff.Dir = repoDir
+	out, err := diff.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", since, err)
+	}
+
+	untracked := exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	untracked.Dir = repoDir
+	more, err := untracked.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(out)+string(more), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// AffectedPaths returns the Rust module paths of module touched by files,
+// e.g. billing::rules for src/billing/rules.rs or crates/billing/src/rules.rs.
+// Files outside the module and markdown files are ignored, other files affect
+// their directory, and paths inside another affected path are left out. The
+// result is sorted.
+func AffectedPaths(module string, files []string) []string {
+	crate := strings.ReplaceAll(module, "-", "_")
+
+	affected := make(map[string]bool)
+	for _, file := range files {
+		if strings.HasSuffix(file, ".md") {
+			continue
+		}
+		segments := strings.Split(path.Clean(file), "/")
+		start := -1
+		for i, s := range segments {
+			if s == module || s == crate || s == module+".rs" || s == crate+".rs" {
+				start = i
+			}
+		}
+		if start < 0 {
+			continue
+		}
+
+		parts := []string{crate}
+		rest := segments[start+1:]
+		if len(rest) > 1 && rest[0] == "src" {
+			rest = rest[1:] // Module is a crate
+		}
+		for i, s := range rest {
+			if i == len(rest)-1 {
+				// The file itself: Rust files are modules, except for the
+				// files that define their directory's module
+				name, isRust := strings.CutSuffix(s, ".rs")
+				if isRust && name != "mod" && name != "lib" && name != "main" {
+					parts = append(parts, name)
+				}
+				break
+			}
+			parts = append(parts, s)
+		}
+		affected[strings.Join(parts, "::")] = true
+	}
+
+	var paths []string
+	for p := range affected {
+		covered := false
+		for q := range affected {
+			if strings.HasPrefix(p, q+"::") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,115 @@
+package testselect
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAffectedPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		module string
+		files  []string
+		want   []string
+	}{
+		{
+			name:   "files map to module paths",
+			module: "billing",
+			files:  []string{"src/billing/rules.rs", "src/billing/tax/vat.rs", "src/zoning/map.rs"},
+			want:   []string{"billing::rules", "billing::tax::vat"},
+		},
+		{
+			name:   "mod.rs stands for its directory",
+			module: "billing",
+			files:  []string{"src/billing/tax/mod.rs", "src/billing/tax/vat.rs"},
+			want:   []string{"billing::tax"},
+		},
+		{
+			name:   "module root file covers the module",
+			module: "billing",
+			files:  []string{"src/billing.rs", "src/billing/rules.rs"},
+			want:   []string{"billing"},
+		},
+		{
+			name:   "other files affect their directory, markdown nothing",
+			module: "billing",
+			files:  []string{"src/billing/fixtures/invoice.json", "src/billing/README.md"},
+			want:   []string{"billing::fixtures"},
+		},
+		{
+			name:   "crates with dashes in the name",
+			module: "cli-impl",
+			files:  []string{"crates/cli_impl/src/args.rs", "docs/plans/cli-impl/epic-01.md"},
+			want:   []string{"cli_impl::args"},
+		},
+		{
+			name:   "no changes in the module",
+			module: "billing",
+			files:  []string{"src/zoning/map.rs", "Cargo.lock"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AffectedPaths(tt.module, tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AffectedPaths(%q, %q) = %q, want %q", tt.module, tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+	write("src/billing/rules.rs", "fn a() {}")
+	write("src/billing/tax.rs", "fn b() {}")
+	write("src/zoning/map.rs", "fn c() {}")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	passing, err := HeadCommit(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("src/billing/rules.rs", "fn a() { 1 }") // Committed after the passing run
+	git("commit", "-q", "-am", "change rules")
+	write("src/zoning/map.rs", "fn c() { 2 }") // Unstaged
+	write("src/billing/new.rs", "fn d() {}")   // Untracked
+
+	files, err := ChangedFiles(dir, passing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"src/billing/rules.rs", "src/zoning/map.rs", "src/billing/new.rs"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ChangedFiles = %q, want %q", files, want)
+	}
+
+	if _, err := ChangedFiles(dir, "no-such-commit"); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+}
@@ -187,13 +187,6 @@ max_parallel_agents = ${MAX_AGENTS}
 # SQLite database path
 database_path = "${DB_PATH}"
 
-[claude]
-# Claude model to use
-model = "claude-opus-4-5-20251101"
-
-# Max tokens per response
-max_tokens = 16000
-
 [notifications]
 # Enable desktop notifications
 desktop = ${DESKTOP_NOTIFY}
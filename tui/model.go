@@ -2,10 +2,10 @@ package tui
 
 import (
 	"fmt"
-	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildpool"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/maintenance"
@@ -14,14 +14,6 @@ import (
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
 )
 
-// testAgentOutput stores streaming output from the test agent
-// Protected by testAgentMutex for concurrent access
-var (
-	testAgentMutex  sync.Mutex
-	testAgentOutput []string
-	testAgentTaskID string
-)
-
 // ViewMode determines how tasks are displayed
 type ViewMode int
 
@@ -30,18 +22,6 @@ const (
 	ViewByModule
 )
 
-// ModuleSummary holds aggregated data for a module
-type ModuleSummary struct {
-	Name           string
-	TotalEpics     int
-	CompletedEpics int
-	InProgressEpics int
-	TotalTests     int
-	PassedTests    int
-	FailedTests    int
-	Coverage       string
-}
-
 // SyncConflictModal holds state for the sync conflict resolution modal
 type SyncConflictModal struct {
 	Visible     bool
@@ -61,10 +41,41 @@ type MaintenanceModal struct {
 	TargetModule string // Selected module name for scope
 }
 
+// BatchConfirmModal holds a batch waiting to be confirmed and its estimated cost
+type BatchConfirmModal struct {
+	Visible  bool
+	Tasks    []*domain.Task
+	Estimate taskstore.BatchEstimate
+}
+
+// Task creation wizard fields, in the order they are edited
+const (
+	wizardModule = iota
+	wizardEpic
+	wizardTitle
+	wizardDescription
+	wizardPriority
+	wizardDependsOn
+	taskWizardFieldCount
+)
+
+// taskWizardLabels names the task creation wizard fields
+var taskWizardLabels = [taskWizardFieldCount]string{"Module", "Epic", "Title", "Description", "Priority", "Depends on"}
+
+// TaskWizardModal collects the fields of a task created from the Tasks tab
+type TaskWizardModal struct {
+	Visible  bool
+	Fields   [taskWizardFieldCount]string // Indexed by the wizard* constants
+	Focus    int                          // Field being edited
+	Creating bool                         // Waiting for the epic file to be written
+	Error    string                       // Why the last submit was refused
+}
+
 // GroupPriorityItem represents a group in the priorities view
 type GroupPriorityItem struct {
 	Name      string
 	Priority  int // -1 if unassigned
+	Weight    int // Higher runs first within the tier
 	Total     int
 	Completed int
 }
@@ -77,7 +88,7 @@ type Model struct {
 	allTasks       []*domain.Task
 	flagged        []*FlaggedPR
 	workers        []*WorkerView
-	modules        []*ModuleSummary
+	modules        []*domain.ModuleSummary
 	completedTasks map[string]bool // Track completed task IDs for dependency checking
 
 	// Stats
@@ -85,18 +96,37 @@ type Model struct {
 	maxActive      int
 	completedToday int
 
+	// Start of the "Completed today" window at a given time, and when the
+	// count was last read from the store
+	completedSince   func(now time.Time) time.Time
+	completedTodayAt time.Time
+
+	// Tasks completed per throughputInterval over the dashboard sparkline's
+	// span, oldest first (nil without a store)
+	throughput []int
+
+	// Whether edits of a running agent's epic made outside its worktree are
+	// queued as a follow-up for the agent, and the epic body each agent was
+	// last given, by task ID
+	syncPlanChanges bool
+	planBodies      map[string]string
+
 	// UI state
 	width          int
 	height         int
 	activeTab      int
 	selectedRow    int
 	viewMode       ViewMode
+	unblockingSoon bool // Tasks tab shows only tasks blocked solely by in-progress work
+	dashboardLayout DashboardLayout // Full or compact Dashboard, cycled with [L]
 	taskScroll     int
 	selectedModule int
 	selectedAgent      int
 	showAgentDetail    bool
-	showAgentPrompt    bool // Toggle to show prompt instead of output
-	agentOutputScroll  int  // Scroll position for agent output
+	showAgentPrompt    bool         // Toggle to show prompt instead of output
+	showStderrOnly     bool         // Filter agent output to lines written to stderr
+	outputFilter       OutputFilter // Parts of the agent output shown, cycled with [F]
+	agentOutputScroll  int          // Scroll position for agent output
 	showAgentHistory   bool         // Toggle to show completed/failed agent history
 	agentHistory       []*AgentView // Historical agent runs from database
 	selectedHistory    int          // Selected index in history list
@@ -126,12 +156,63 @@ type Model struct {
 	statusMsg    string
 
 	// Build pool
-	buildPoolURL    string
-	buildPoolStatus string // "disabled", "unreachable", "connected"
-	gitDaemonPort   int    // Git daemon port for remote workers
+	buildPoolURL      string
+	buildPoolStatus   string             // "disabled", "unreachable", "connected"
+	gitDaemonPort     int                // Git daemon port for remote workers
+	buildPool         *buildpool.Service // In-process build pool toggled with [b] (nil = not configured)
+	buildPoolToggling bool               // A start/stop is in progress
+
+	// Additional coordinators shown in the BUILD POOL section, and their
+	// status as of the last fetch (in configured order)
+	coordinators        []CoordinatorSource
+	coordinatorStatuses []CoordinatorStatus
+
+	// Worker status polling (backs off while the coordinator is unreachable)
+	workersFetchInFlight bool          // A fetch is outstanding, don't start another
+	workersFetchBackoff  time.Duration // Current retry delay, 0 while reachable
+	workersNextFetch     time.Time     // Earliest time for the next fetch
 
 	// Refresh
-	lastRefresh time.Time
+	lastRefresh     time.Time
+	sampleResources bool // Sample CPU/memory of running agents on each tick
+
+	// Auto mode throttle
+	maxStartsPerTick int       // Max agents auto mode starts per tick (0 = unlimited)
+	lastAutoStart    time.Time // When auto mode last started agents
+
+	// Tier for groups without an explicit priority
+	defaultGroupTier int
+
+	// In-flight agents allowed per module (0 = unlimited)
+	maxPerModule int
+
+	// How long to wait for a module test run (0 = defaultModuleTestTimeout)
+	moduleTestTimeout time.Duration
+
+	// Whether [x] runs only the tests of code changed since a module's tests
+	// last passed ([X] runs the other kind)
+	testChangedOnly bool
+
+	// Commit each module's tests last passed at, by module name
+	lastPassingCommits map[string]string
+
+	// Lines of an agent's log shown in the history detail (0 = defaultHistoryLogLines)
+	historyLogLines int
+
+	// Agent failures since the last success; auto mode pauses once it
+	// reaches maxFailuresInARow (0 = never)
+	consecutiveFailures int
+	maxFailuresInARow   int
+	autoPausedOnFailure bool // Auto mode was turned off by the failure threshold
+
+	// Per-task priority overrides (promoted tasks), keyed by task ID
+	taskOverrides map[string]int
+
+	// Weights of groups within their tier, keyed by group
+	groupWeights map[string]int
+
+	// Tasks marked on the Tasks tab to be started together, keyed by task ID
+	markedTasks map[string]bool
 
 	// Mouse mode toggle
 	mouseEnabled bool
@@ -140,12 +221,33 @@ type Model struct {
 	syncModal    SyncConflictModal
 	syncFlash    string
 	syncFlashExp time.Time
+	syncChanges  []isync.SyncChange // Changes made by the last sync and its conflict resolution
+	showChanges  bool               // Show syncChanges on the Modules tab
+	failedSync   *syncOperation     // Last sync or resolution that failed, retried with [R]
 	store        *taskstore.Store
 	syncer       *isync.Syncer
 
 	// Maintenance modal state
 	maintenanceModal MaintenanceModal
 
+	// Batch start confirmation
+	batchConfirm BatchConfirmModal
+
+	// Task creation wizard state
+	taskWizard TaskWizardModal
+
+	// Task ID of the running agent waiting for its cancellation to be confirmed
+	cancelConfirm string
+
+	// Events log: recent warnings and errors, oldest first, capped at
+	// maxLogEvents. eventsLogged counts all events ever logged, eventsSeen
+	// how many of them had been logged when the log was last opened.
+	events       []LogEvent
+	eventsLogged int
+	eventsSeen   int
+	showEvents   bool // Toggle with 'l' key
+	eventsScroll int  // Events scrolled up from the newest
+
 	// Group priorities view state
 	showGroupPriorities bool                // Toggle with 'g' key
 	groupPriorityItems  []GroupPriorityItem // Groups with their priorities
@@ -158,6 +260,24 @@ type Model struct {
 	updateInProgress bool   // True while downloading/installing
 }
 
+// EventLevel is the severity of a LogEvent
+type EventLevel string
+
+const (
+	EventWarning EventLevel = "warning"
+	EventError   EventLevel = "error"
+)
+
+// maxLogEvents is how many events the events log keeps
+const maxLogEvents = 200
+
+// LogEvent is a warning or error recorded in the events log
+type LogEvent struct {
+	Time    time.Time
+	Level   EventLevel
+	Message string
+}
+
 // AgentView represents an agent in the TUI
 type AgentView struct {
 	TaskID       string
@@ -166,13 +286,23 @@ type AgentView struct {
 	Status       executor.AgentStatus
 	Progress     string
 	WorktreePath string
-	LogPath      string   // Path to the log file (for historical runs)
+	LogPath      string // Path to the log file (for historical runs)
+	RunID        string // ID of the agent run (for historical runs)
+	Notes        string // Annotations of the run, one per line (for historical runs)
 	Error        string
-	Output       []string // Last N lines of output
-	Prompt       string   // The prompt sent to the LLM
+	Output       []string                // Last N lines of output
+	Prompt       string                  // The prompt sent to the LLM
+	Resources    *executor.ResourceUsage // Latest CPU/memory sample (nil if not sampled)
 	TokensInput  int
 	TokensOutput int
 	CostUSD      float64
+	Idle         time.Duration // Time since the last output line, while running
+	RetryAttempt int           // Attempt number of a scheduled automatic resume
+	RetryAt      time.Time     // When that resume is due (zero if none is scheduled)
+
+	// Live output: new lines of outputAgent, or of a test agent when outputAgent is nil
+	outputCh    <-chan string
+	outputAgent *executor.Agent
 }
 
 // FlaggedPR represents a PR needing attention
@@ -188,29 +318,61 @@ type WorkerView struct {
 	MaxJobs     int
 	ActiveJobs  int
 	ConnectedAt time.Time
+	Tags        []string // Capabilities jobs can require
+}
+
+// CoordinatorSource is an additional build pool coordinator whose workers
+// are shown next to those of the orchestrator's own coordinator
+type CoordinatorSource struct {
+	Name string
+	URL  string
+}
+
+// CoordinatorStatus is the result of fetching the workers of one coordinator
+type CoordinatorStatus struct {
+	Name    string
+	URL     string
+	Status  string // "connected" or "unreachable"
+	Workers []*WorkerView
 }
 
 // ModelConfig holds initial data for the TUI model
 type ModelConfig struct {
-	MaxActive       int
-	AllTasks        []*domain.Task
-	Queued          []*domain.Task
-	Agents          []*AgentView
-	Flagged         []*FlaggedPR
-	Workers         []*WorkerView
-	ProjectRoot     string
-	WorktreeDir     string
-	PlansDir        string // Directory containing plans (for sync)
-	BuildPoolURL    string // URL for build pool status (e.g., "http://localhost:8081")
-	GitDaemonPort   int    // Git daemon port for remote workers (e.g., 9418)
-	AgentManager    *executor.AgentManager
-	WorktreeManager *executor.WorktreeManager
-	RecoveredAgents []*AgentView // Agents recovered from previous session
-	PlanWatcher     *observer.PlanWatcher
-	PlanChangeChan  chan PlanSyncMsg
-	Store           *taskstore.Store  // Database store for sync operations
-	Syncer          *isync.Syncer     // Syncer for two-way sync operations
-	CurrentVersion  string            // Current version for update checking
+	MaxActive         int
+	AllTasks          []*domain.Task
+	Queued            []*domain.Task
+	Agents            []*AgentView
+	Flagged           []*FlaggedPR
+	Workers           []*WorkerView
+	ProjectRoot       string
+	WorktreeDir       string
+	PlansDir          string             // Directory containing plans (for sync)
+	BuildPoolURL      string             // URL for build pool status (e.g., "http://localhost:8081")
+	GitDaemonPort     int                // Git daemon port for remote workers (e.g., 9418)
+	BuildPool         *buildpool.Service // In-process build pool the [b] key starts and stops (nil = not toggleable)
+	AgentManager      *executor.AgentManager
+	WorktreeManager   *executor.WorktreeManager
+	RecoveredAgents   []*AgentView // Agents recovered from previous session
+	PlanWatcher       *observer.PlanWatcher
+	PlanChangeChan    chan PlanSyncMsg
+	Store             *taskstore.Store    // Database store for sync operations
+	Syncer            *isync.Syncer       // Syncer for two-way sync operations
+	CurrentVersion    string              // Current version for update checking
+	SampleResources   bool                // Sample CPU/memory of running agents on each tick
+	MaxStartsPerTick  int                 // Max agents auto mode starts per tick (0 = unlimited)
+	DefaultGroupTier  int                 // Tier for groups without an explicit priority
+	MaxPerModule      int                 // In-flight agents allowed per module (0 = unlimited)
+	ModuleTestTimeout time.Duration       // How long to wait for a module test run (0 = 2m)
+	TestChangedOnly   bool                // [x] runs only tests of code changed since the module's last passing run
+	HistoryLogLines   int                 // Lines of an agent's log shown in the history detail (0 = 500)
+	MaxFailuresInARow int                 // Agent failures in a row that pause auto mode (0 = never pause)
+	Coordinators      []CoordinatorSource // Additional coordinators whose workers are shown
+	OutputFilter      OutputFilter        // Initial agent output filter (empty = all output)
+	SyncPlanChanges   bool                // Queue edits of a running agent's epic as a follow-up for the agent
+
+	// CompletedSince returns when the "Completed today" window began at now
+	// (nil = local midnight)
+	CompletedSince func(now time.Time) time.Time
 }
 
 // NewModel creates a new TUI model
@@ -223,13 +385,37 @@ func NewModel(cfg ModelConfig) Model {
 
 	activeCount := 0
 	for _, a := range agents {
-		if a.Status == executor.AgentRunning {
+		if a.Status.Active() {
 			activeCount++
 		}
 	}
 
 	// Compute module summaries
-	modules := computeModuleSummaries(cfg.AllTasks)
+	modules := domain.SummarizeModules(cfg.AllTasks)
+
+	// Load per-task priority overrides
+	taskOverrides := make(map[string]int)
+	if cfg.Store != nil {
+		if overrides, err := cfg.Store.GetTaskPriorityOverrides(); err == nil {
+			taskOverrides = overrides
+		}
+	}
+
+	// Load group weights
+	groupWeights := make(map[string]int)
+	if cfg.Store != nil {
+		if weights, err := cfg.Store.GetGroupWeights(); err == nil {
+			groupWeights = weights
+		}
+	}
+
+	// Load the commits module tests last passed at, for changed-only runs
+	lastPassingCommits := make(map[string]string)
+	if cfg.Store != nil {
+		if commits, err := cfg.Store.GetLastPassingCommits(); err == nil {
+			lastPassingCommits = commits
+		}
+	}
 
 	// Build completed tasks map from task status
 	completedTasks := make(map[string]bool)
@@ -259,6 +445,11 @@ func NewModel(cfg ModelConfig) Model {
 		agentMgr.SetBuildPoolURL(cfg.BuildPoolURL)
 	}
 
+	outputFilter := cfg.OutputFilter
+	if outputFilter == "" {
+		outputFilter = OutputAll
+	}
+
 	worktreeMgr := cfg.WorktreeManager
 	if worktreeMgr == nil && cfg.ProjectRoot != "" && cfg.WorktreeDir != "" {
 		worktreeMgr = executor.NewWorktreeManager(cfg.ProjectRoot, cfg.WorktreeDir)
@@ -269,7 +460,7 @@ func NewModel(cfg ModelConfig) Model {
 	if len(cfg.RecoveredAgents) > 0 {
 		stillRunning := 0
 		for _, a := range cfg.RecoveredAgents {
-			if a.Status == executor.AgentRunning {
+			if a.Status.Active() {
 				stillRunning++
 			}
 		}
@@ -286,33 +477,58 @@ func NewModel(cfg ModelConfig) Model {
 		buildPoolStatus = "unreachable" // Will be updated on first fetch
 	}
 
-	return Model{
-		maxActive:       cfg.MaxActive,
-		allTasks:        cfg.AllTasks,
-		queued:          cfg.Queued,
-		agents:          agents,
-		flagged:         cfg.Flagged,
-		workers:         cfg.Workers,
-		modules:         modules,
-		completedTasks:  completedTasks,
-		activeCount:     activeCount,
-		activeTab:       0,
-		projectRoot:     cfg.ProjectRoot,
-		worktreeDir:     cfg.WorktreeDir,
-		buildPoolURL:    cfg.BuildPoolURL,
-		buildPoolStatus: buildPoolStatus,
-		gitDaemonPort:   cfg.GitDaemonPort,
-		agentManager:    agentMgr,
-		worktreeManager: worktreeMgr,
-		planWatcher:     cfg.PlanWatcher,
-		planChangeChan:  cfg.PlanChangeChan,
-		statusMsg:       statusMsg,
-		mouseEnabled:    true,
-		store:           cfg.Store,
-		syncer:          syncer,
-		syncModal:       SyncConflictModal{Resolutions: make(map[string]string)},
-		currentVersion:  cfg.CurrentVersion,
+	m := Model{
+		maxActive:          cfg.MaxActive,
+		allTasks:           cfg.AllTasks,
+		queued:             cfg.Queued,
+		agents:             agents,
+		flagged:            cfg.Flagged,
+		workers:            cfg.Workers,
+		modules:            modules,
+		completedTasks:     completedTasks,
+		activeCount:        activeCount,
+		activeTab:          0,
+		projectRoot:        cfg.ProjectRoot,
+		worktreeDir:        cfg.WorktreeDir,
+		buildPoolURL:       cfg.BuildPoolURL,
+		buildPoolStatus:    buildPoolStatus,
+		gitDaemonPort:      cfg.GitDaemonPort,
+		buildPool:          cfg.BuildPool,
+		agentManager:       agentMgr,
+		worktreeManager:    worktreeMgr,
+		planWatcher:        cfg.PlanWatcher,
+		planChangeChan:     cfg.PlanChangeChan,
+		statusMsg:          statusMsg,
+		mouseEnabled:       true,
+		store:              cfg.Store,
+		syncer:             syncer,
+		syncModal:          SyncConflictModal{Resolutions: make(map[string]string)},
+		currentVersion:     cfg.CurrentVersion,
+		sampleResources:    cfg.SampleResources,
+		maxStartsPerTick:   cfg.MaxStartsPerTick,
+		defaultGroupTier:   cfg.DefaultGroupTier,
+		maxPerModule:       cfg.MaxPerModule,
+		moduleTestTimeout:  cfg.ModuleTestTimeout,
+		testChangedOnly:    cfg.TestChangedOnly,
+		historyLogLines:    cfg.HistoryLogLines,
+		maxFailuresInARow:  cfg.MaxFailuresInARow,
+		coordinators:       cfg.Coordinators,
+		outputFilter:       outputFilter,
+		taskOverrides:      taskOverrides,
+		groupWeights:       groupWeights,
+		lastPassingCommits: lastPassingCommits,
+		completedSince:     cfg.CompletedSince,
+		syncPlanChanges:    cfg.SyncPlanChanges,
+		planBodies:         make(map[string]string),
+	}
+	// Read once before the first tick, so the header starts with the counts
+	if cmd := m.refreshCompletedTodayCmd(time.Now()); cmd != nil {
+		if msg := cmd().(CompletedTodayMsg); msg.Err == nil {
+			m.completedToday = msg.Count
+			m.throughput = msg.Throughput
+		}
 	}
+	return m
 }
 
 // GetPlanChangeChan returns the channel for receiving plan change messages
@@ -325,54 +541,28 @@ func (m Model) GetPlanWatcher() *observer.PlanWatcher {
 	return m.planWatcher
 }
 
-// computeModuleSummaries aggregates task data by module
-func computeModuleSummaries(tasks []*domain.Task) []*ModuleSummary {
-	moduleMap := make(map[string]*ModuleSummary)
-
-	for _, task := range tasks {
-		mod := task.ID.Module
-		if _, exists := moduleMap[mod]; !exists {
-			moduleMap[mod] = &ModuleSummary{Name: mod}
-		}
-		ms := moduleMap[mod]
-		ms.TotalEpics++
-
-		switch task.Status {
-		case domain.StatusComplete:
-			ms.CompletedEpics++
-		case domain.StatusInProgress:
-			ms.InProgressEpics++
-		}
-
-		// Aggregate test summary if available
-		if task.TestSummary != nil {
-			ms.TotalTests += task.TestSummary.Tests
-			ms.PassedTests += task.TestSummary.Passed
-			ms.FailedTests += task.TestSummary.Failed
+// runningAgentsPerModule counts the running agents in each module
+func runningAgentsPerModule(agents []*AgentView) map[string]int {
+	counts := make(map[string]int)
+	for _, a := range agents {
+		if !a.Status.Active() {
+			continue
 		}
-	}
-
-	// Convert to slice and sort
-	var result []*ModuleSummary
-	for _, ms := range moduleMap {
-		// Calculate coverage (simplified - just take average if we had multiple)
-		if ms.TotalTests > 0 && ms.PassedTests > 0 {
-			pct := float64(ms.PassedTests) / float64(ms.TotalTests) * 100
-			ms.Coverage = fmt.Sprintf("%.0f%%", pct)
+		if id, err := domain.ParseTaskID(a.TaskID); err == nil {
+			counts[id.Module]++
 		}
-		result = append(result, ms)
 	}
+	return counts
+}
 
-	// Sort by name
-	for i := 0; i < len(result)-1; i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[i].Name > result[j].Name {
-				result[i], result[j] = result[j], result[i]
-			}
-		}
+// sharesModule reports whether a running agent has another running agent in
+// its module, according to counts from runningAgentsPerModule
+func sharesModule(a *AgentView, counts map[string]int) bool {
+	if !a.Status.Active() {
+		return false
 	}
-
-	return result
+	id, err := domain.ParseTaskID(a.TaskID)
+	return err == nil && counts[id.Module] > 1
 }
 
 // Init initializes the model
@@ -399,6 +589,43 @@ func waitForPlanChange(ch chan PlanSyncMsg) tea.Cmd {
 	}
 }
 
+// Backoff bounds for polling an unreachable build pool coordinator
+const (
+	workersFetchBackoffMin = 2 * time.Second
+	workersFetchBackoffMax = 60 * time.Second
+)
+
+// shouldFetchWorkers reports whether a worker status fetch should be started now.
+// Only one fetch is in flight at a time, and fetches are delayed while backing off.
+func (m Model) shouldFetchWorkers(now time.Time) bool {
+	if (m.buildPoolURL == "" && len(m.coordinators) == 0) || m.workersFetchInFlight {
+		return false
+	}
+	return !now.Before(m.workersNextFetch)
+}
+
+// recordWorkersFetch updates the polling state after a worker status fetch.
+// Each unreachable result doubles the retry delay up to workersFetchBackoffMax;
+// a successful fetch resets it so polling resumes on every tick.
+func (m *Model) recordWorkersFetch(status string, now time.Time) {
+	m.workersFetchInFlight = false
+	if status != "unreachable" {
+		m.workersFetchBackoff = 0
+		m.workersNextFetch = time.Time{}
+		return
+	}
+
+	if m.workersFetchBackoff == 0 {
+		m.workersFetchBackoff = workersFetchBackoffMin
+	} else {
+		m.workersFetchBackoff *= 2
+		if m.workersFetchBackoff > workersFetchBackoffMax {
+			m.workersFetchBackoff = workersFetchBackoffMax
+		}
+	}
+	m.workersNextFetch = now.Add(m.workersFetchBackoff)
+}
+
 // TickMsg triggers a refresh
 type TickMsg time.Time
 
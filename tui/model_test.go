@@ -1,13 +1,32 @@
 package tui
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/buildpool"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/maintenance"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/mcp"
+	isync "github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
 )
 
 func TestNewModel(t *testing.T) {
@@ -314,58 +333,6 @@ func TestModel_ConfigChanged(t *testing.T) {
 	}
 }
 
-func TestComputeModuleSummaries(t *testing.T) {
-	tasks := []*domain.Task{
-		{ID: domain.TaskID{Module: "alpha", EpicNum: 0}, Status: domain.StatusComplete},
-		{ID: domain.TaskID{Module: "alpha", EpicNum: 1}, Status: domain.StatusInProgress},
-		{ID: domain.TaskID{Module: "alpha", EpicNum: 2}, Status: domain.StatusNotStarted},
-		{ID: domain.TaskID{Module: "beta", EpicNum: 0}, Status: domain.StatusComplete,
-			TestSummary: &domain.TestSummary{Tests: 10, Passed: 8, Failed: 2}},
-	}
-
-	summaries := computeModuleSummaries(tasks)
-
-	if len(summaries) != 2 {
-		t.Fatalf("summaries count = %d, want 2", len(summaries))
-	}
-
-	// Find alpha module
-	var alpha, beta *ModuleSummary
-	for _, s := range summaries {
-		if s.Name == "alpha" {
-			alpha = s
-		} else if s.Name == "beta" {
-			beta = s
-		}
-	}
-
-	if alpha == nil {
-		t.Fatal("alpha module not found")
-	}
-	if alpha.TotalEpics != 3 {
-		t.Errorf("alpha.TotalEpics = %d, want 3", alpha.TotalEpics)
-	}
-	if alpha.CompletedEpics != 1 {
-		t.Errorf("alpha.CompletedEpics = %d, want 1", alpha.CompletedEpics)
-	}
-	if alpha.InProgressEpics != 1 {
-		t.Errorf("alpha.InProgressEpics = %d, want 1", alpha.InProgressEpics)
-	}
-
-	if beta == nil {
-		t.Fatal("beta module not found")
-	}
-	if beta.TotalTests != 10 {
-		t.Errorf("beta.TotalTests = %d, want 10", beta.TotalTests)
-	}
-	if beta.PassedTests != 8 {
-		t.Errorf("beta.PassedTests = %d, want 8", beta.PassedTests)
-	}
-	if beta.FailedTests != 2 {
-		t.Errorf("beta.FailedTests = %d, want 2", beta.FailedTests)
-	}
-}
-
 func TestModel_WindowResize(t *testing.T) {
 	model := NewModel(ModelConfig{MaxActive: 3})
 
@@ -432,12 +399,22 @@ func TestModel_BatchStartKey(t *testing.T) {
 	model.height = 40
 	model.activeTab = 0 // Dashboard
 
-	// Press 's' to start batch
+	// Press 's' to start batch, which asks for confirmation first
 	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
 	model = newModel.(Model)
 
+	if !model.batchConfirm.Visible || model.batchRunning || cmd != nil {
+		t.Fatal("'s' should show the batch confirmation without starting")
+	}
+	if len(model.batchConfirm.Tasks) != 2 {
+		t.Errorf("confirmation lists %d tasks, want 2", len(model.batchConfirm.Tasks))
+	}
+
+	newModel, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
+
 	if !model.batchRunning {
-		t.Error("batchRunning should be true after 's'")
+		t.Error("batchRunning should be true after confirming")
 	}
 
 	if model.batchPaused {
@@ -445,7 +422,122 @@ func TestModel_BatchStartKey(t *testing.T) {
 	}
 
 	if cmd == nil {
-		t.Error("'s' should return a command to start the batch")
+		t.Error("confirming should return a command to start the batch")
+	}
+}
+
+func TestModel_BatchConfirmShowsEstimate(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks, Store: store})
+	model.width = 100
+	model.height = 40
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		return cmd
+	}
+	s := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}
+
+	// Without history the cost is unknown
+	press(s)
+	if view := model.View(); !strings.Contains(view, "START BATCH") || !strings.Contains(view, "Estimated cost: unknown") {
+		t.Errorf("confirmation should say the cost is unknown, got:\n%s", view)
+	}
+	if cmd := press(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil || model.batchConfirm.Visible || model.batchRunning {
+		t.Fatal("esc should cancel the batch")
+	}
+
+	if err := store.SaveAgentRun(&taskstore.AgentRun{
+		ID: "run-1", TaskID: "billing/E01", WorktreePath: "/tmp/wt", LogPath: "/tmp/log",
+		Status: "completed", StartedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveAgentRun: %v", err)
+	}
+	if err := store.UpdateAgentRunUsage("run-1", 20000, 3000, 1.25); err != nil {
+		t.Fatalf("UpdateAgentRunUsage: %v", err)
+	}
+
+	// zoning has no runs and is estimated from the overall average
+	press(s)
+	view := model.View()
+	if !strings.Contains(view, "~$2.50 (40.0K in / 6.0K out tokens)") {
+		t.Errorf("confirmation should show the estimate for both tasks, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Based on module history (1), overall average (1)") {
+		t.Errorf("confirmation should say what the estimate is based on, got:\n%s", view)
+	}
+}
+
+func TestUseCompactDashboard(t *testing.T) {
+	tests := []struct {
+		layout        DashboardLayout
+		width, height int
+		want          bool
+	}{
+		{LayoutAuto, 120, 40, false},
+		{LayoutAuto, 80, 30, false},
+		{LayoutAuto, 79, 40, true},
+		{LayoutAuto, 120, 29, true},
+		{LayoutAuto, 60, 20, true},
+		{LayoutCompact, 200, 60, true},
+		{LayoutFull, 60, 20, false},
+	}
+	for _, tt := range tests {
+		if got := useCompactDashboard(tt.layout, tt.width, tt.height); got != tt.want {
+			t.Errorf("useCompactDashboard(%s, %d, %d) = %v, want %v", tt.layout, tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestModel_CompactDashboardFitsTerminal(t *testing.T) {
+	var tasks []*domain.Task
+	for i := range 12 {
+		tasks = append(tasks, &domain.Task{
+			ID: domain.TaskID{Module: fmt.Sprintf("module%02d", i), EpicNum: 1}, Title: "A task title long enough to need truncation", Status: domain.StatusNotStarted,
+		})
+	}
+	model := NewModel(ModelConfig{MaxActive: 6, AllTasks: tasks, Queued: tasks})
+	for i := range 6 {
+		model.agents = append(model.agents, &AgentView{TaskID: fmt.Sprintf("running%02d/E01", i), Status: executor.AgentRunning, Progress: "implementing the parser changes"})
+	}
+	model.width, model.height = 60, 20
+
+	view := model.View()
+	if lines := strings.Count(view, "\n") + 1; lines > model.height {
+		t.Errorf("compact dashboard has %d lines, want at most %d:\n%s", lines, model.height, view)
+	}
+	for _, want := range []string{"RUNNING (6)", "QUEUED (12 ready of 12)", "more", "[L]ayout:auto"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("compact dashboard should contain %q:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > model.width {
+			t.Errorf("line is %d wide, want at most %d: %q", w, model.width, line)
+		}
+	}
+
+	// [L] cycles to always compact, then always full
+	press := func() {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+		model = updated.(Model)
+	}
+	press()
+	if model.dashboardLayout != LayoutCompact || !model.compactDashboard() {
+		t.Fatalf("layout = %s, want compact", model.dashboardLayout)
+	}
+	press()
+	if model.dashboardLayout != LayoutFull || strings.Contains(model.View(), "QUEUED (12 ready") {
+		t.Errorf("layout = %s, want the full dashboard", model.dashboardLayout)
 	}
 }
 
@@ -492,6 +584,36 @@ func TestModel_BatchStartNoTasks(t *testing.T) {
 	}
 }
 
+func TestModel_BatchStartDependencyCycle(t *testing.T) {
+	billing1 := domain.TaskID{Module: "billing", EpicNum: 1}
+	billing2 := domain.TaskID{Module: "billing", EpicNum: 2}
+	tasks := []*domain.Task{
+		{ID: billing1, Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{billing2}},
+		{ID: billing2, Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{billing1}},
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Queued: tasks})
+	model.width = 100
+	model.height = 40
+	model.activeTab = 0
+
+	// The queue flags the cycle
+	if view := model.renderQueued(); !strings.Contains(view, "billing/E01 -> billing/E02 -> billing/E01") {
+		t.Errorf("queue view does not flag the cycle:\n%s", view)
+	}
+
+	// Press 's': the status says why nothing starts
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	model = newModel.(Model)
+
+	if model.batchConfirm.Visible {
+		t.Error("batch confirmation opened although no task can start")
+	}
+	if !strings.Contains(model.statusMsg, "Dependency cycle") || !strings.Contains(model.statusMsg, "billing/E02") {
+		t.Errorf("statusMsg = %q, want the dependency cycle", model.statusMsg)
+	}
+}
+
 func TestModel_BatchPauseResume(t *testing.T) {
 	tasks := []*domain.Task{
 		{ID: domain.TaskID{Module: "test", EpicNum: 0}, Status: domain.StatusNotStarted},
@@ -505,6 +627,8 @@ func TestModel_BatchPauseResume(t *testing.T) {
 	// Start batch first
 	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
 	model = newModel.(Model)
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = newModel.(Model)
 
 	// Press 'p' to pause
 	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
@@ -600,6 +724,71 @@ func TestModel_AutoModeOnlyOnDashboard(t *testing.T) {
 	}
 }
 
+func TestModel_AutoModeMaxStartsPerTick(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "alpha", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "beta", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "gamma", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Queued: tasks, MaxStartsPerTick: 1})
+	model.autoMode = true
+
+	cmd := model.tryStartAutoTasks()
+	if cmd == nil {
+		t.Fatal("tryStartAutoTasks should return a start command")
+	}
+	if model.statusMsg != "Auto: starting 1 task(s)..." {
+		t.Errorf("statusMsg = %q, want 'Auto: starting 1 task(s)...'", model.statusMsg)
+	}
+
+	// Without a limit all free slots are filled at once
+	model = NewModel(ModelConfig{MaxActive: 3, Queued: tasks})
+	model.autoMode = true
+
+	if cmd := model.tryStartAutoTasks(); cmd == nil {
+		t.Fatal("tryStartAutoTasks should return a start command")
+	}
+	if model.statusMsg != "Auto: starting 3 task(s)..." {
+		t.Errorf("statusMsg = %q, want 'Auto: starting 3 task(s)...'", model.statusMsg)
+	}
+}
+
+func TestModel_AutoModeSlowsDownWhileThrottled(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "alpha", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "beta", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "gamma", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Queued: tasks})
+	model.width = 150
+	model.height = 40
+	model.autoMode = true
+	model.agentManager.RecordRateLimit(time.Now())
+
+	if view := model.View(); !strings.Contains(view, "API throttling detected") {
+		t.Error("header should show the throttling indicator")
+	}
+
+	// Only one agent starts while throttled
+	if cmd := model.tryStartAutoTasks(); cmd == nil {
+		t.Fatal("tryStartAutoTasks should return a start command")
+	}
+	if model.statusMsg != "Auto: starting 1 task(s)..." {
+		t.Errorf("statusMsg = %q, want 'Auto: starting 1 task(s)...'", model.statusMsg)
+	}
+
+	// The next start waits for the throttled start interval
+	if cmd := model.tryStartAutoTasks(); cmd != nil {
+		t.Error("tryStartAutoTasks should not start again right away while throttled")
+	}
+	model.lastAutoStart = time.Now().Add(-throttledStartInterval)
+	if cmd := model.tryStartAutoTasks(); cmd == nil {
+		t.Error("tryStartAutoTasks should start again after the throttled start interval")
+	}
+}
+
 func TestModel_BatchStartMsg(t *testing.T) {
 	model := NewModel(ModelConfig{MaxActive: 3})
 	model.width = 100
@@ -768,6 +957,44 @@ func TestModel_UpdateAgentsFromManager(t *testing.T) {
 	}
 }
 
+func TestModel_TestAgentOutputStreamsDuringTicks(t *testing.T) {
+	outputCh := make(chan string, testAgentOutputBuffer)
+	agents := []*AgentView{
+		{TaskID: "test-agent", Status: executor.AgentRunning, outputCh: outputCh},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, Agents: agents})
+
+	// Stream more lines than the channel buffers so the sender has to wait
+	// for ticks to drain it; run with -race to catch unsynchronized access
+	const lines = 3 * testAgentOutputBuffer
+	go func() {
+		onOutput := streamTestAgentOutput(context.Background(), outputCh)
+		for i := 0; i < lines; i++ {
+			onOutput(fmt.Sprintf("line %d", i))
+		}
+		close(outputCh)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for model.agents[0].outputCh != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("output not drained, got %d of %d lines", len(model.agents[0].Output), lines)
+		}
+		newModel, _ := model.Update(TickMsg(time.Now()))
+		model = newModel.(Model)
+	}
+
+	output := model.agents[0].Output
+	if len(output) != lines {
+		t.Fatalf("got %d output lines, want %d", len(output), lines)
+	}
+	for i, line := range output {
+		if want := fmt.Sprintf("line %d", i); line != want {
+			t.Fatalf("output[%d] = %q, want %q", i, line, want)
+		}
+	}
+}
+
 func TestModel_AgentSelection(t *testing.T) {
 	agents := []*AgentView{
 		{TaskID: "test/E00", Status: executor.AgentRunning},
@@ -857,3 +1084,1848 @@ func TestModel_AgentViewHasErrorAndOutput(t *testing.T) {
 		t.Errorf("WorktreePath = %q, want '/tmp/worktree/test'", model.agents[0].WorktreePath)
 	}
 }
+
+func TestModel_WorkersFetchBackoff(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 3, BuildPoolURL: "http://localhost:1"})
+	now := time.Now()
+
+	if !model.shouldFetchWorkers(now) {
+		t.Fatal("should fetch on first tick")
+	}
+
+	// Tick starts a fetch; further ticks must not start another one
+	newModel, _ := model.Update(TickMsg(now))
+	model = newModel.(Model)
+	if !model.workersFetchInFlight {
+		t.Fatal("fetch should be marked in flight after tick")
+	}
+	if model.shouldFetchWorkers(now.Add(time.Second)) {
+		t.Error("should not start a second fetch while one is in flight")
+	}
+
+	// Unreachable results back off exponentially up to the max
+	wantBackoffs := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for _, want := range wantBackoffs {
+		model.recordWorkersFetch("unreachable", now)
+		if model.workersFetchBackoff != want {
+			t.Errorf("backoff = %v, want %v", model.workersFetchBackoff, want)
+		}
+		if model.shouldFetchWorkers(now.Add(want - time.Millisecond)) {
+			t.Errorf("should not fetch before backoff of %v elapsed", want)
+		}
+		if !model.shouldFetchWorkers(now.Add(want)) {
+			t.Errorf("should fetch once backoff of %v elapsed", want)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		model.recordWorkersFetch("unreachable", now)
+	}
+	if model.workersFetchBackoff != workersFetchBackoffMax {
+		t.Errorf("backoff = %v, want capped at %v", model.workersFetchBackoff, workersFetchBackoffMax)
+	}
+
+	// Reachable again: fast polling resumes
+	newModel, _ = model.Update(WorkersUpdateMsg{Status: "connected"})
+	model = newModel.(Model)
+	if model.workersFetchBackoff != 0 {
+		t.Errorf("backoff = %v, want 0 after reconnect", model.workersFetchBackoff)
+	}
+	if !model.shouldFetchWorkers(now) {
+		t.Error("should fetch on every tick once reachable")
+	}
+}
+
+// stubCoordinator serves a /status response listing the given workers
+func stubCoordinator(t *testing.T, workers string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"workers": [%s]}`, workers)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestModel_WorkersFromSeveralCoordinators(t *testing.T) {
+	primary := stubCoordinator(t, `{"id": "local-1", "max_jobs": 4, "active_jobs": 1}`)
+	ci := stubCoordinator(t, `{"id": "ci-1", "max_jobs": 2, "active_jobs": 2, "tags": ["gpu", "highmem"]}, {"id": "ci-2", "max_jobs": 2, "active_jobs": 0}`)
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	model := NewModel(ModelConfig{
+		MaxActive:    3,
+		BuildPoolURL: primary.URL,
+		Coordinators: []CoordinatorSource{
+			{Name: "ci", URL: ci.URL},
+			{Name: gone.URL, URL: gone.URL},
+		},
+	})
+
+	msg := fetchWorkersCmd(model.buildPoolURL, model.coordinators)().(WorkersUpdateMsg)
+	if msg.Status != "connected" || len(msg.Workers) != 1 || msg.Workers[0].ID != "local-1" {
+		t.Fatalf("primary: status=%q workers=%d, want connected with local-1", msg.Status, len(msg.Workers))
+	}
+	if len(msg.Coordinators) != 2 {
+		t.Fatalf("got %d coordinator statuses, want 2", len(msg.Coordinators))
+	}
+	if c := msg.Coordinators[0]; c.Name != "ci" || c.Status != "connected" || len(c.Workers) != 2 || c.Workers[1].ID != "ci-2" {
+		t.Errorf("ci coordinator = %+v, want connected with ci-1 and ci-2", c)
+	}
+	if c := msg.Coordinators[1]; c.Status != "unreachable" || len(c.Workers) != 0 {
+		t.Errorf("stopped coordinator = %+v, want unreachable without workers", c)
+	}
+
+	newModel, _ := model.Update(msg)
+	model = newModel.(Model)
+	section := model.renderWorkers()
+	for _, want := range []string{
+		"Coordinator running, 1 worker(s):",
+		"local-1: 1/4 jobs",
+		fmt.Sprintf("ci (%s): 2 worker(s)", ci.URL),
+		"ci-1: 2/2 jobs [gpu, highmem]",
+		"ci-2: 0/2 jobs\n",
+		gone.URL + ": unreachable",
+		"Total: 3 worker(s), 3/8 jobs",
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("BUILD POOL section missing %q:\n%s", want, section)
+		}
+	}
+}
+
+func TestModel_WorkersFetchWithOnlyExtraCoordinators(t *testing.T) {
+	ci := stubCoordinator(t, `{"id": "ci-1", "max_jobs": 2, "active_jobs": 1}`)
+	model := NewModel(ModelConfig{MaxActive: 3, Coordinators: []CoordinatorSource{{Name: "ci", URL: ci.URL}}})
+
+	if !model.shouldFetchWorkers(time.Now()) {
+		t.Fatal("should fetch workers of additional coordinators without a build pool")
+	}
+
+	msg := fetchWorkersCmd("", model.coordinators)().(WorkersUpdateMsg)
+	newModel, _ := model.Update(msg)
+	model = newModel.(Model)
+	if model.buildPoolStatus != "disabled" {
+		t.Errorf("buildPoolStatus = %q, want disabled without an own coordinator", model.buildPoolStatus)
+	}
+	if len(model.coordinatorStatuses) != 1 || model.coordinatorStatuses[0].Status != "connected" {
+		t.Errorf("coordinatorStatuses = %+v, want ci connected", model.coordinatorStatuses)
+	}
+	if model.workersFetchBackoff != 0 {
+		t.Errorf("backoff = %v, want 0 while a coordinator is reachable", model.workersFetchBackoff)
+	}
+}
+
+func TestModel_WorkersFetchDisabledWithoutBuildPool(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 3})
+	if model.shouldFetchWorkers(time.Now()) {
+		t.Error("should not fetch workers without a build pool URL")
+	}
+}
+
+// fakeTestRunner reports the given status and summary for every poll and
+// records the filters runs were started with
+type fakeTestRunner struct {
+	status  string
+	summary *mcp.TestSummary
+	polls   int
+	filters []string
+	synced  []bool
+}
+
+func (f *fakeTestRunner) RunTests(filter string, syncFirst, includeIgnored bool) (*mcp.TestRunResult, error) {
+	f.filters = append(f.filters, filter)
+	f.synced = append(f.synced, syncFirst)
+	return &mcp.TestRunResult{RunID: fmt.Sprintf("run-%d", len(f.filters))}, nil
+}
+
+func (f *fakeTestRunner) GetTestResults(runID string, blocking bool, tail int) (*mcp.TestResults, error) {
+	f.polls++
+	return &mcp.TestResults{RunID: runID, Status: f.status, Summary: f.summary}, nil
+}
+
+func TestExecuteModuleTests_TimesOut(t *testing.T) {
+	orig := moduleTestPollInterval
+	moduleTestPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { moduleTestPollInterval = orig })
+
+	runner := &fakeTestRunner{status: "running"}
+	msg := executeModuleTests(runner, "billing", nil, 30*time.Millisecond)
+
+	if msg.Err == nil {
+		t.Fatal("expected a timeout error for a run that never finishes")
+	}
+	if !strings.Contains(msg.Err.Error(), "test run timed out after") {
+		t.Errorf("unexpected error: %v", msg.Err)
+	}
+	if runner.polls < 2 {
+		t.Errorf("expected repeated polling, got %d polls", runner.polls)
+	}
+}
+
+func TestExecuteModuleTests_Completes(t *testing.T) {
+	runner := &fakeTestRunner{status: "completed"}
+	msg := executeModuleTests(runner, "billing", nil, time.Minute)
+
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if !strings.Contains(msg.Output, "run-1") {
+		t.Errorf("output should mention the run ID, got %q", msg.Output)
+	}
+	if len(runner.filters) != 1 || runner.filters[0] != "billing" {
+		t.Errorf("expected one run filtered by module, got %v", runner.filters)
+	}
+}
+
+func TestExecuteModuleTests_RunsEachFilter(t *testing.T) {
+	runner := &fakeTestRunner{
+		status:  "completed",
+		summary: &mcp.TestSummary{Total: 3, Passed: 3, Success: true},
+	}
+	msg := executeModuleTests(runner, "billing", []string{"billing::rules", "billing::tax"}, time.Minute)
+
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if !reflect.DeepEqual(runner.filters, []string{"billing::rules", "billing::tax"}) {
+		t.Errorf("filters = %v, want one run per affected path", runner.filters)
+	}
+	if !reflect.DeepEqual(runner.synced, []bool{true, false}) {
+		t.Errorf("synced = %v, want binaries synced before the first run only", runner.synced)
+	}
+	if !strings.Contains(msg.Output, "Total:   6") {
+		t.Errorf("output should sum the runs' results, got %q", msg.Output)
+	}
+
+	runner.summary = &mcp.TestSummary{Total: 1, Failed: 1}
+	if msg := executeModuleTests(runner, "billing", []string{"billing::tax"}, time.Minute); msg.Err == nil {
+		t.Error("expected an error when a run fails")
+	}
+}
+
+func TestModel_RecordsLastPassingCommit(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store})
+
+	updated, _ := model.Update(TestCompleteMsg{Module: "billing", Commit: "abc123", Err: fmt.Errorf("tests failed")})
+	model = updated.(Model)
+	if _, ok := model.lastPassingCommits["billing"]; ok {
+		t.Fatal("a failed run should not be recorded as passing")
+	}
+
+	updated, cmd := model.Update(TestCompleteMsg{Module: "billing", Commit: "abc123", Output: "ok"})
+	model = updated.(Model)
+	if got := model.lastPassingCommits["billing"]; got != "abc123" {
+		t.Errorf("last passing commit = %q, want abc123", got)
+	}
+	if cmd == nil {
+		t.Fatal("passing run should be saved to the store")
+	}
+	if msg := cmd().(LastPassingCommitSavedMsg); msg.Err != nil {
+		t.Fatalf("saving the passing commit: %v", msg.Err)
+	}
+	// A restarted TUI diffs against the same commit
+	if got := NewModel(ModelConfig{MaxActive: 3, Store: store}).lastPassingCommits["billing"]; got != "abc123" {
+		t.Errorf("last passing commit after restart = %q, want abc123", got)
+	}
+
+	// A changed-only run with nothing to run has no commit and keeps the record
+	updated, _ = model.Update(TestCompleteMsg{Module: "billing", Output: "No changes"})
+	model = updated.(Model)
+	if got := model.lastPassingCommits["billing"]; got != "abc123" {
+		t.Errorf("last passing commit = %q, want it kept", got)
+	}
+}
+
+func TestModel_StderrOnlyToggle(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 3})
+	model.activeTab = 2
+	model.showAgentDetail = true
+
+	lines := []string{"plain output", executor.StderrPrefix + "boom"}
+	if got := model.visibleOutput(lines); len(got) != 2 {
+		t.Fatalf("expected all lines without filter, got %v", got)
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	model = updated.(Model)
+	if !model.showStderrOnly {
+		t.Fatal("'e' should enable the stderr-only filter in agent detail")
+	}
+
+	got := model.visibleOutput(lines)
+	if len(got) != 1 || got[0] != executor.StderrPrefix+"boom" {
+		t.Errorf("expected only the stderr line, got %v", got)
+	}
+}
+
+func TestFormatClaudeOutput_Filter(t *testing.T) {
+	stream := []string{
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Reading the config first."},{"type":"tool_use","name":"Read","input":{"file_path":"/repo/internal/config/config.go"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"package config"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"All tests pass."}]}}`,
+	}
+
+	tests := []struct {
+		filter OutputFilter
+		want   []string
+	}{
+		{OutputAll, []string{
+			"▶ Session started",
+			"Reading the config first.",
+			"🔧 Read: config.go",
+			"   ✓ Read: config.go → package config",
+			"🔧 Bash: go test ./...",
+			"   ✓ Bash: go test ./... → ok",
+			"All tests pass.",
+		}},
+		{OutputHideToolResults, []string{
+			"▶ Session started",
+			"Reading the config first.",
+			"🔧 Read: config.go",
+			"🔧 Bash: go test ./...",
+			"All tests pass.",
+		}},
+		{OutputToolResultsOnly, []string{
+			"▶ Session started",
+			"   ✓ Read: config.go → package config",
+			"   ✓ Bash: go test ./... → ok",
+		}},
+	}
+	for _, tt := range tests {
+		got := formatClaudeOutput(stream, 120, tt.filter)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestFormatClaudeOutput_UnsafeText(t *testing.T) {
+	stream := []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"\u001b[2Jcleared"}]}}`,
+		"raw ✓✓✓✓✓✓✓✓✓✓✓✓✓✓", // Cut in the middle of a ✓ by bytes
+	}
+
+	got := formatClaudeOutput(stream, 21, OutputAll)
+	want := []string{`\x1b[2Jcleared`, "raw ✓✓✓..."}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, line := range got {
+		if !utf8.ValidString(line) {
+			t.Errorf("%q is not valid UTF-8", line)
+		}
+	}
+}
+
+func TestModel_OutputFilterToggle(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 3, OutputFilter: OutputHideToolResults})
+	model.activeTab = 2
+	model.showAgentDetail = true
+
+	var seen []OutputFilter
+	for range 3 {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+		model = updated.(Model)
+		seen = append(seen, model.outputFilter)
+	}
+	want := []OutputFilter{OutputToolResultsOnly, OutputAll, OutputHideToolResults}
+	if !slices.Equal(seen, want) {
+		t.Errorf("'F' cycled through %v, want %v", seen, want)
+	}
+
+	if f, err := ParseOutputFilter(""); err != nil || f != OutputAll {
+		t.Errorf("ParseOutputFilter(\"\") = %q, %v, want all output", f, err)
+	}
+	if _, err := ParseOutputFilter("tools"); err == nil {
+		t.Error("expected an error for an unknown filter")
+	}
+}
+
+func TestModel_PromoteSelectedTask(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 0}, Status: domain.StatusNotStarted},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks, Store: store})
+	model.activeTab = 1
+	model.viewMode = ViewByPriority
+	model.taskScroll = 1 // Cursor on the task scheduled last
+
+	if sel := model.selectedTask(); sel == nil || sel.ID.Module != "zoning" {
+		t.Fatalf("selectedTask = %v, want zoning/E00", sel)
+	}
+
+	press := func(m Model) Model {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+		m = updated.(Model)
+		if cmd == nil {
+			t.Fatal("'P' should return a command")
+		}
+		updated, _ = m.Update(cmd())
+		return updated.(Model)
+	}
+
+	model = press(model)
+	ready := model.newScheduler().GetReadyTasks(2)
+	if ready[0].ID.Module != "zoning" {
+		t.Errorf("promoted task should be scheduled first, got %s", ready[0].ID)
+	}
+	if overrides, _ := store.GetTaskPriorityOverrides(); len(overrides) != 1 {
+		t.Errorf("override should be persisted, got %v", overrides)
+	}
+
+	// Pressing again clears the override
+	model = press(model)
+	ready = model.newScheduler().GetReadyTasks(2)
+	if ready[0].ID.Module != "billing" {
+		t.Errorf("after demotion billing should be first, got %s", ready[0].ID)
+	}
+}
+
+func TestModel_ReopenSelectedTask(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Status: domain.StatusComplete, Priority: domain.PriorityHigh},
+		{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Status: domain.StatusNotStarted, Priority: domain.PriorityHigh,
+			DependsOn: []domain.TaskID{{Module: "billing", EpicNum: 0}}},
+	}
+	for _, task := range tasks {
+		store.UpsertTask(task)
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks[1:], Store: store})
+	model.activeTab = 1
+	model.viewMode = ViewByPriority
+
+	if sel := model.selectedTask(); sel == nil || sel.ID.String() != "billing/E00" {
+		t.Fatalf("selectedTask = %v, want billing/E00", sel)
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("'O' on a complete task should return a command")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	if model.completedTasks["billing/E00"] {
+		t.Error("reopened task should be removed from completedTasks")
+	}
+	ready := model.newScheduler().GetReadyTasks(2)
+	if len(ready) != 1 || ready[0].ID.String() != "billing/E00" {
+		t.Errorf("ready tasks = %v, want only the reopened billing/E00", ready)
+	}
+	if events, _ := store.ListTaskEvents("billing/E00"); len(events) != 1 {
+		t.Errorf("reopen should be recorded, got %d events", len(events))
+	}
+
+	// Tasks that are not complete cannot be reopened
+	model.taskScroll = 1
+	if sel := model.selectedTask(); sel == nil || sel.ID.String() != "billing/E01" {
+		t.Fatalf("selectedTask = %v, want billing/E01", sel)
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")}); cmd != nil {
+		t.Error("'O' on an incomplete task should not return a command")
+	}
+}
+
+// newPromptTestModel returns a model showing the detail view of one failed agent
+func newPromptTestModel(mgr *executor.AgentManager, prompt string) Model {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "tech", EpicNum: 3}, Title: "Add retries", Status: domain.StatusInProgress},
+	}
+	model := NewModel(ModelConfig{
+		MaxActive:    3,
+		AllTasks:     tasks,
+		AgentManager: mgr,
+		Agents:       []*AgentView{{TaskID: "tech/E03", Status: executor.AgentFailed, Prompt: prompt}},
+	})
+	model.activeTab = 2
+	model.showAgentDetail = true
+	return model
+}
+
+func TestModel_CopyPrompt(t *testing.T) {
+	var copied string
+	orig := copyToClipboard
+	copyToClipboard = func(text string) error { copied = text; return nil }
+	t.Cleanup(func() { copyToClipboard = orig })
+
+	model := newPromptTestModel(nil, "implement the epic")
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil {
+		t.Fatal("'c' should return a copy command")
+	}
+	updated, _ = updated.(Model).Update(cmd())
+	model = updated.(Model)
+
+	if copied != "implement the epic" {
+		t.Errorf("copied %q, want the agent prompt", copied)
+	}
+	if !strings.Contains(model.statusMsg, "Copied prompt") {
+		t.Errorf("statusMsg = %q", model.statusMsg)
+	}
+
+	// Agents recovered without a stored prompt fall back to one built from the task
+	model = newPromptTestModel(nil, "")
+	if prompt := model.agentPrompt(model.agents[0]); !strings.Contains(prompt, "Add retries") {
+		t.Errorf("rebuilt prompt should mention the task title, got %q", prompt)
+	}
+}
+
+func TestModel_EditPromptThenRestart(t *testing.T) {
+	// Restart spawns the executor, so put a no-op claude first in PATH
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho '{\"type\":\"result\"}'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+
+	mgr := executor.NewAgentManager(2)
+	mgr.Add(&executor.Agent{
+		ID:           "tech/E03-old",
+		TaskID:       domain.TaskID{Module: "tech", EpicNum: 3},
+		WorktreePath: t.TempDir(),
+		Status:       executor.AgentFailed,
+		Prompt:       "implement the epic",
+	})
+
+	var seen string
+	orig := runEditor
+	runEditor = func(c *exec.Cmd, done tea.ExecCallback) tea.Cmd {
+		return func() tea.Msg {
+			path := c.Args[len(c.Args)-1]
+			data, _ := os.ReadFile(path)
+			seen = string(data)
+			os.WriteFile(path, []byte("implement the epic, with retries"), 0644)
+			return done(nil)
+		}
+	}
+	t.Cleanup(func() { runEditor = orig })
+
+	model := newPromptTestModel(mgr, "implement the epic")
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	if cmd == nil {
+		t.Fatal("'E' should open the editor")
+	}
+	msg := cmd()
+	if seen != "implement the epic" {
+		t.Errorf("editor opened with %q, want the current prompt", seen)
+	}
+
+	updated, cmd = updated.(Model).Update(msg)
+	if cmd == nil {
+		t.Fatalf("edited prompt should trigger a restart, status: %q", updated.(Model).statusMsg)
+	}
+	restart, ok := cmd().(AgentRestartMsg)
+	if !ok || !restart.Success {
+		t.Fatalf("restart = %+v, want success", restart)
+	}
+	if got := mgr.Get("tech/E03").Prompt; got != "implement the epic, with retries" {
+		t.Errorf("restarted agent prompt = %q, want the edited prompt", got)
+	}
+	mgr.Get("tech/E03").Stop()
+}
+
+func TestModel_AnnotatesHistoryRun(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+	finished := time.Now()
+	if err := store.SaveAgentRun(&taskstore.AgentRun{
+		ID: "run-1", TaskID: "tech/E03", Status: "completed", StartedAt: finished.Add(-time.Hour), FinishedAt: &finished,
+	}); err != nil {
+		t.Fatalf("SaveAgentRun: %v", err)
+	}
+
+	orig := runEditor
+	runEditor = func(c *exec.Cmd, done tea.ExecCallback) tea.Cmd {
+		return func() tea.Msg {
+			os.WriteFile(c.Args[len(c.Args)-1], []byte("Fixed the flaky test\n"), 0644)
+			return done(nil)
+		}
+	}
+	t.Cleanup(func() { runEditor = orig })
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store})
+	model.activeTab = 2
+	model.showAgentHistory = true
+	updated, _ := model.Update(loadAgentHistoryCmd(store)())
+	model = updated.(Model)
+	model.showHistoryDetail = true
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if cmd == nil {
+		t.Fatal("'n' in the history detail should open the editor")
+	}
+	updated, cmd = updated.(Model).Update(cmd())
+	if cmd == nil {
+		t.Fatalf("written note should be saved, status: %q", updated.(Model).statusMsg)
+	}
+	updated, _ = updated.(Model).Update(cmd())
+	model = updated.(Model)
+
+	if got := model.agentHistory[0].Notes; got != "Fixed the flaky test" {
+		t.Errorf("history notes = %q, want the note shown", got)
+	}
+	if !strings.Contains(model.renderSelectedHistoryDetail(), "- Fixed the flaky test") {
+		t.Error("history detail should list the note")
+	}
+	run, err := store.GetAgentRun("run-1")
+	if err != nil || run.Notes != "Fixed the flaky test" {
+		t.Errorf("stored notes = %q (%v), want the note persisted", run.Notes, err)
+	}
+
+	// An empty note is not saved
+	updated, cmd = model.Update(NoteEditedMsg{RunID: "run-1", TaskID: "tech/E03", Note: "\n"})
+	if cmd != nil || !strings.Contains(updated.(Model).statusMsg, "Empty note") {
+		t.Errorf("empty note: status %q, want nothing saved", updated.(Model).statusMsg)
+	}
+}
+
+func TestModel_EditPromptUnchangedDoesNotRestart(t *testing.T) {
+	model := newPromptTestModel(nil, "implement the epic")
+
+	updated, cmd := model.Update(PromptEditedMsg{TaskID: "tech/E03", Original: "implement the epic", Prompt: "implement the epic\n"})
+	if cmd != nil {
+		t.Error("unchanged prompt should not restart the agent")
+	}
+	if !strings.Contains(updated.(Model).statusMsg, "unchanged") {
+		t.Errorf("statusMsg = %q", updated.(Model).statusMsg)
+	}
+}
+
+func TestModel_UpdateAgentsFromManagerStreamsOutput(t *testing.T) {
+	agentMgr := executor.NewAgentManager(3)
+	agent := &executor.Agent{
+		TaskID: domain.TaskID{Module: "test", EpicNum: 0},
+		Status: executor.AgentRunning,
+	}
+	for i := 0; i < 5000; i++ {
+		agent.AppendOutput(fmt.Sprintf("line %d", i))
+	}
+	agentMgr.Add(agent)
+
+	model := NewModel(ModelConfig{
+		MaxActive:    3,
+		Agents:       []*AgentView{{TaskID: "test/E00", Status: executor.AgentQueued}},
+		AgentManager: agentMgr,
+	})
+
+	// First sync takes a snapshot of only the last lines
+	model.updateAgentsFromManager()
+	av := model.agents[0]
+	if len(av.Output) != maxAgentOutputLines || av.Output[len(av.Output)-1] != "line 4999" {
+		t.Fatalf("initial output has %d lines ending %q", len(av.Output), av.Output[len(av.Output)-1])
+	}
+
+	// Later syncs receive just the new lines
+	agent.AppendOutput("new 1")
+	agent.AppendOutput("new 2")
+	if len(av.outputCh) != 2 {
+		t.Errorf("pending lines = %d, want 2", len(av.outputCh))
+	}
+	model.updateAgentsFromManager()
+
+	if len(av.Output) != maxAgentOutputLines {
+		t.Errorf("output has %d lines, want %d", len(av.Output), maxAgentOutputLines)
+	}
+	if got := av.Output[len(av.Output)-2:]; got[0] != "new 1" || got[1] != "new 2" {
+		t.Errorf("last lines = %v, want [new 1 new 2]", got)
+	}
+
+	// A restarted agent replaces the subscription
+	restarted := &executor.Agent{TaskID: agent.TaskID, Status: executor.AgentRunning}
+	restarted.AppendOutput("fresh start")
+	agentMgr.Add(restarted)
+	model.updateAgentsFromManager()
+
+	if len(av.Output) != 1 || av.Output[0] != "fresh start" {
+		t.Errorf("output after restart = %v, want [fresh start]", av.Output)
+	}
+}
+
+func TestModel_ReplayLastBatch(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 0}, Title: "Billing setup", Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 0}, Title: "Zoning setup", Status: domain.StatusNotStarted},
+		{ID: domain.TaskID{Module: "zoning", EpicNum: 1}, Title: "Zoning rules", Status: domain.StatusNotStarted},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks, Store: store})
+
+	// Starting a batch records its task IDs
+	updated, cmd := model.Update(BatchStartMsg{
+		Count: 2,
+		Started: []AgentStartInfo{
+			{TaskID: "zoning/E00", WorktreePath: "/tmp/wt-zoning"},
+			{TaskID: "billing/E00", WorktreePath: "/tmp/wt-billing"},
+		},
+	})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("BatchStartMsg should return a command recording the batch")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	// The batch finishes; its tasks are complete and no longer ready
+	for _, a := range model.agents {
+		a.Status = executor.AgentCompleted
+	}
+	for _, task := range tasks[:2] {
+		task.Status = domain.StatusComplete
+	}
+	model.batchRunning = false
+
+	replay, err := model.lastBatchTasks()
+	if err != nil {
+		t.Fatalf("lastBatchTasks: %v", err)
+	}
+	if len(replay) != 2 || replay[0].ID.String() != "zoning/E00" || replay[1].ID.String() != "billing/E00" {
+		t.Fatalf("lastBatchTasks = %v, want [zoning/E00 billing/E00]", replay)
+	}
+
+	// Tasks that are running again are not replayed twice
+	model.agents[0].Status = executor.AgentRunning
+	replay, _ = model.lastBatchTasks()
+	if len(replay) != 1 || replay[0].ID.String() != "billing/E00" {
+		t.Errorf("lastBatchTasks with running agent = %v, want [billing/E00]", replay)
+	}
+	model.agents[0].Status = executor.AgentCompleted
+
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'B'}})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("'B' should return a command starting the replayed batch")
+	}
+	if !model.batchRunning || model.statusMsg != "Replaying last batch: 2 task(s)..." {
+		t.Errorf("batchRunning = %v, statusMsg = %q", model.batchRunning, model.statusMsg)
+	}
+}
+
+func TestModel_StartMarkedTasks(t *testing.T) {
+	id := func(module string, epic int) domain.TaskID { return domain.TaskID{Module: module, EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id("billing", 0), Title: "Billing setup", Status: domain.StatusNotStarted},
+		{ID: id("billing", 1), Title: "Billing rules", Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("billing", 0)}},
+		{ID: id("zoning", 0), Title: "Zoning setup", Status: domain.StatusNotStarted},
+		{ID: id("zoning", 1), Title: "Zoning rules", Status: domain.StatusNotStarted},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks})
+	model.activeTab = 1
+
+	press := func(key string) tea.Cmd {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+		return cmd
+	}
+
+	// Mark billing/E00 and billing/E01, which depends on it
+	press(" ")
+	press("j")
+	press(" ")
+	if len(model.markedTasks) != 2 || !strings.Contains(model.renderTasks(), "◉") {
+		t.Fatalf("markedTasks = %v, want billing/E00 and billing/E01 marked", model.markedTasks)
+	}
+
+	if cmd := press("s"); cmd != nil || model.batchRunning {
+		t.Fatal("starting marked tasks with unmet dependencies should be refused")
+	}
+	if !strings.Contains(model.statusMsg, "billing/E01 (waits on billing/E00)") {
+		t.Errorf("statusMsg = %q, want the unmet dependency reported", model.statusMsg)
+	}
+
+	// Swap billing/E01 for zoning/E00; the unmarked zoning/E01 is not started
+	press(" ")
+	press("j")
+	press(" ")
+	if !model.markedTasks["billing/E00"] || !model.markedTasks["zoning/E00"] || len(model.markedTasks) != 2 {
+		t.Fatalf("markedTasks = %v, want billing/E00 and zoning/E00", model.markedTasks)
+	}
+
+	if cmd := press("s"); cmd == nil {
+		t.Fatal("'s' on the Tasks tab should return a command starting the marked tasks")
+	}
+	if !model.batchRunning || model.statusMsg != "Starting 2 marked task(s)..." {
+		t.Errorf("batchRunning = %v, statusMsg = %q", model.batchRunning, model.statusMsg)
+	}
+	if len(model.markedTasks) != 0 {
+		t.Errorf("markedTasks after start = %v, want none", model.markedTasks)
+	}
+}
+
+func TestModel_UnblockingSoonFilter(t *testing.T) {
+	id := func(module string, epic int) domain.TaskID { return domain.TaskID{Module: module, EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id("billing", 0), Title: "Billing setup", Status: domain.StatusNotStarted},
+		{ID: id("billing", 1), Title: "Billing rules", Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("billing", 0)}},
+		{ID: id("zoning", 0), Title: "Zoning setup", Status: domain.StatusNotStarted},
+		{ID: id("zoning", 1), Title: "Zoning rules", Status: domain.StatusNotStarted, DependsOn: []domain.TaskID{id("zoning", 0)}},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks})
+	model.agents = []*AgentView{{TaskID: "billing/E00", Status: executor.AgentRunning}}
+	model.activeTab = 1
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	model = updated.(Model)
+	if !model.unblockingSoon {
+		t.Fatal("f on the Tasks tab should enable the unblocking soon filter")
+	}
+
+	// billing/E01 only waits on a running task; zoning/E01 waits on one not started
+	visible := model.visibleTasks()
+	if len(visible) != 1 || visible[0].ID.String() != "billing/E01" {
+		t.Fatalf("visibleTasks = %v, want only billing/E01", visible)
+	}
+	if task := model.selectedTask(); task == nil || task.ID.String() != "billing/E01" {
+		t.Errorf("selectedTask = %v, want billing/E01", task)
+	}
+	if view := model.renderTasks(); !strings.Contains(view, "unblocking soon") || strings.Contains(view, "zoning/E01") {
+		t.Errorf("renderTasks should show only the filtered tasks, got:\n%s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	model = updated.(Model)
+	if got := len(model.visibleTasks()); got != len(tasks) {
+		t.Errorf("visibleTasks after toggling off = %d, want %d", got, len(tasks))
+	}
+}
+
+func TestModel_ToggleBuildPool(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	svc := buildpool.NewService(buildpool.ServiceConfig{
+		URL: fmt.Sprintf("http://127.0.0.1:%d", port),
+		NewCoordinator: func() *buildpool.Coordinator {
+			registry := buildpool.NewRegistry()
+			return buildpool.NewCoordinator(buildpool.CoordinatorConfig{WebSocketPort: port},
+				registry, buildpool.NewDispatcher(registry, nil))
+		},
+	})
+	defer svc.Stop()
+
+	agentMgr := executor.NewAgentManager(1)
+	model := NewModel(ModelConfig{MaxActive: 1, AgentManager: agentMgr, BuildPool: svc})
+	if model.buildPoolStatus != "disabled" {
+		t.Fatalf("buildPoolStatus = %q, want disabled before starting", model.buildPoolStatus)
+	}
+
+	press := func() {
+		t.Helper()
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		model = updated.(Model)
+		if cmd == nil {
+			t.Fatal("b should return a command toggling the build pool")
+		}
+		updated, _ = model.Update(cmd())
+		model = updated.(Model)
+	}
+
+	press()
+	if !svc.Running() {
+		t.Fatal("build pool should be running after b")
+	}
+	if model.buildPoolURL != svc.URL() || model.buildPoolStatus != "unreachable" {
+		t.Errorf("after start: url=%q status=%q, want %q and unreachable until fetched", model.buildPoolURL, model.buildPoolStatus, svc.URL())
+	}
+	if got := agentMgr.GetBuildPoolURL(); got != svc.URL() {
+		t.Errorf("agent manager build pool URL = %q, want %q", got, svc.URL())
+	}
+
+	press()
+	if svc.Running() {
+		t.Fatal("build pool should be stopped after pressing b again")
+	}
+	if model.buildPoolURL != "" || model.buildPoolStatus != "disabled" {
+		t.Errorf("after stop: url=%q status=%q, want empty and disabled", model.buildPoolURL, model.buildPoolStatus)
+	}
+	if got := agentMgr.GetBuildPoolURL(); got != "" {
+		t.Errorf("agent manager build pool URL = %q, want empty", got)
+	}
+
+	// A worker fetch that was in flight when the pool stopped is ignored
+	updated, _ := model.Update(WorkersUpdateMsg{Status: "connected"})
+	model = updated.(Model)
+	if model.buildPoolStatus != "disabled" {
+		t.Errorf("stale fetch set buildPoolStatus = %q, want disabled", model.buildPoolStatus)
+	}
+}
+
+func TestModel_ToggleBuildPoolNotConfigured(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 1})
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	model = updated.(Model)
+	if cmd != nil {
+		t.Error("b without a build pool should not return a command")
+	}
+	if !strings.Contains(model.statusMsg, "not configured") {
+		t.Errorf("statusMsg = %q, want a not configured hint", model.statusMsg)
+	}
+}
+
+func TestFormatSyncChanges(t *testing.T) {
+	result := &isync.SyncResult{
+		MarkdownToDBCount: 5,
+		Changes: []isync.SyncChange{
+			{TaskID: "billing/E01", Direction: isync.MarkdownToDB, Fields: []string{"added"}},
+			{TaskID: "technical/E02", Direction: isync.DBToMarkdown, Fields: []string{"status: complete → in_progress"}},
+			{TaskID: "billing/E02", Direction: isync.MarkdownToDB, Fields: []string{"title", "dependencies"}},
+		},
+	}
+
+	got := formatSyncChanges(result.Changes)
+	want := []string{
+		"markdown → db (2)",
+		"  billing/E01    added",
+		"  billing/E02    title, dependencies",
+		"db → markdown (1)",
+		"  technical/E02  status: complete → in_progress",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("formatSyncChanges =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if lines := formatSyncChanges(nil); len(lines) != 0 {
+		t.Errorf("formatSyncChanges(nil) = %v, want no lines", lines)
+	}
+}
+
+func TestModel_SyncChangesPanel(t *testing.T) {
+	tasks := []*domain.Task{
+		{ID: domain.TaskID{Module: "billing", EpicNum: 1}, Status: domain.StatusNotStarted},
+	}
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+	store.UpsertTask(tasks[0])
+
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, Queued: tasks, Store: store})
+	model.activeTab = 3
+	press := func(key string) {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+	}
+
+	press("S")
+	if model.showChanges {
+		t.Fatal("panel should not open without sync changes")
+	}
+
+	updated, _ := model.Update(SyncCompleteMsg{Result: &isync.SyncResult{
+		MarkdownToDBCount: 1,
+		Changes:           []isync.SyncChange{{TaskID: "billing/E01", Direction: isync.MarkdownToDB, Fields: []string{"title"}}},
+	}})
+	model = updated.(Model)
+	if !strings.Contains(model.syncFlash, "1 changed") {
+		t.Errorf("syncFlash = %q, want it to mention the change", model.syncFlash)
+	}
+	if strings.Contains(model.renderModules(), "Changes from last sync") {
+		t.Error("panel should be collapsed until expanded")
+	}
+
+	press("S")
+	view := model.renderModules()
+	if !strings.Contains(view, "markdown → db (1)") || !strings.Contains(view, "billing/E01  title") {
+		t.Errorf("expanded panel should list the change, got:\n%s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.showChanges {
+		t.Error("esc should dismiss the panel")
+	}
+
+	// Conflict resolutions add to the changes of the same sync
+	updated, _ = model.Update(SyncResolveMsg{Changes: []isync.SyncChange{
+		{TaskID: "billing/E01", Direction: isync.DBToMarkdown, Fields: []string{"status: complete → not_started"}},
+	}})
+	model = updated.(Model)
+	if len(model.syncChanges) != 2 {
+		t.Errorf("syncChanges = %+v, want the sync and the resolution change", model.syncChanges)
+	}
+}
+
+func TestModel_RetryFailedSync(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+	syncer := isync.New(t.TempDir())
+
+	type call struct {
+		syncer      *isync.Syncer
+		store       *taskstore.Store
+		resolutions map[string]string
+	}
+	var syncCalls, resolveCalls []call
+	fail := true
+	origSync, origResolve := twoWaySync, resolveConflicts
+	twoWaySync = func(s *isync.Syncer, st *taskstore.Store) (*isync.SyncResult, error) {
+		syncCalls = append(syncCalls, call{syncer: s, store: st})
+		if fail {
+			return nil, errors.New("git push: connection reset")
+		}
+		return &isync.SyncResult{}, nil
+	}
+	resolveConflicts = func(s *isync.Syncer, st *taskstore.Store, resolutions map[string]string) ([]isync.SyncChange, error) {
+		resolveCalls = append(resolveCalls, call{syncer: s, store: st, resolutions: resolutions})
+		return nil, errors.New("git push: connection reset")
+	}
+	t.Cleanup(func() { twoWaySync, resolveConflicts = origSync, origResolve })
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store, Syncer: syncer})
+	model.activeTab = 3
+	model.width, model.height = 200, 40
+	// press sends a key and runs the command it returns, if any
+	press := func(key string) {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+		if cmd != nil {
+			updated, _ = model.Update(cmd())
+			model = updated.(Model)
+		}
+	}
+
+	press("R")
+	if len(syncCalls) != 0 || model.statusMsg != "No failed sync to retry" {
+		t.Fatalf("retry without a failure: calls=%d status=%q", len(syncCalls), model.statusMsg)
+	}
+
+	press("s")
+	if !strings.Contains(model.statusMsg, "Sync failed: git push: connection reset") || model.failedSync == nil {
+		t.Fatalf("status = %q, want the sync failure recorded", model.statusMsg)
+	}
+	if !strings.Contains(model.View(), "[R]etry sync") {
+		t.Error("status bar should offer the retry")
+	}
+
+	fail = false
+	press("R")
+	if len(syncCalls) != 2 || syncCalls[1].syncer != syncer || syncCalls[1].store != store {
+		t.Fatalf("retry should run the sync again with the same arguments, got %+v", syncCalls)
+	}
+	if model.failedSync != nil || model.syncFlash != "Already in sync ✓" {
+		t.Errorf("after a successful retry: failedSync=%v flash=%q", model.failedSync, model.syncFlash)
+	}
+
+	// A failed conflict resolution is retried with the same resolutions
+	resolutions := map[string]string{"billing/E01": "markdown", "billing/E02": "database"}
+	updated, _ := model.Update(applyResolutionsCmd(syncer, store, resolutions)())
+	model = updated.(Model)
+	if !strings.Contains(model.statusMsg, "Resolution failed") {
+		t.Fatalf("status = %q, want the resolution failure", model.statusMsg)
+	}
+	press("R")
+	if len(resolveCalls) != 2 {
+		t.Fatalf("resolve called %d times, want the retry to call it again", len(resolveCalls))
+	}
+	first, retry := resolveCalls[0], resolveCalls[1]
+	if retry.syncer != first.syncer || retry.store != first.store || !maps.Equal(retry.resolutions, first.resolutions) {
+		t.Errorf("retry called resolve with %+v, want %+v", retry, first)
+	}
+	if len(syncCalls) != 2 {
+		t.Error("retrying a resolution should not run a full sync")
+	}
+}
+
+func TestRunningAgentsPerModule(t *testing.T) {
+	agents := []*AgentView{
+		{TaskID: "billing/E01", Status: executor.AgentRunning},
+		{TaskID: "billing/E02", Status: executor.AgentRunning},
+		{TaskID: "billing/E03", Status: executor.AgentCompleted}, // Finished agents don't count
+		{TaskID: "zoning/E01", Status: executor.AgentRunning},
+		{TaskID: "cli-impl/CLI02", Status: executor.AgentRunning},
+		{TaskID: "cli-impl/E03", Status: executor.AgentRunning},
+		{TaskID: "not-a-task", Status: executor.AgentRunning},
+	}
+
+	counts := runningAgentsPerModule(agents)
+	want := map[string]int{"billing": 2, "zoning": 1, "cli-impl": 2}
+	if len(counts) != len(want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	for module, n := range want {
+		if counts[module] != n {
+			t.Errorf("counts[%s] = %d, want %d", module, counts[module], n)
+		}
+	}
+
+	for i, wantShared := range []bool{true, true, false, false, true, true, false} {
+		if got := sharesModule(agents[i], counts); got != wantShared {
+			t.Errorf("sharesModule(%s) = %v, want %v", agents[i].TaskID, got, wantShared)
+		}
+	}
+
+	warning := moduleConcurrencyWarning(counts)
+	if !strings.HasSuffix(warning, "billing (2), cli-impl (2)") {
+		t.Errorf("warning = %q, want billing and cli-impl listed", warning)
+	}
+	if got := moduleConcurrencyWarning(map[string]int{"billing": 1, "zoning": 1}); got != "" {
+		t.Errorf("warning without shared modules = %q, want empty", got)
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 5})
+	model.agents = agents
+	for name, view := range map[string]string{"dashboard": model.renderRunning(), "agents": model.renderAgentsDetail()} {
+		if !strings.Contains(view, "changes may conflict: billing (2), cli-impl (2)") {
+			t.Errorf("%s view should warn about shared modules, got:\n%s", name, view)
+		}
+	}
+}
+
+func TestModel_OpenEpicInEditor(t *testing.T) {
+	root := t.TempDir()
+	epic := filepath.Join("docs", "plans", "billing", "epic-01.md")
+	os.MkdirAll(filepath.Join(root, "docs", "plans", "billing"), 0755)
+	os.WriteFile(filepath.Join(root, epic), []byte("# Billing rules\n"), 0644)
+
+	id := func(epic int) domain.TaskID { return domain.TaskID{Module: "billing", EpicNum: epic} }
+	tasks := []*domain.Task{
+		{ID: id(0), Title: "Billing setup", Status: domain.StatusComplete, FilePath: filepath.Join(root, "docs", "plans", "billing", "epic-00.md")},
+		{ID: id(1), Title: "Billing rules", Status: domain.StatusNotStarted, FilePath: epic},
+		{ID: id(2), Title: "Billing export", Status: domain.StatusNotStarted},
+	}
+	model := NewModel(ModelConfig{MaxActive: 3, AllTasks: tasks, ProjectRoot: root})
+
+	// Relative paths resolve against the project root
+	if path, err := model.epicFilePath(tasks[1]); err != nil || path != filepath.Join(root, epic) {
+		t.Errorf("epicFilePath = %q, %v; want %q", path, err, filepath.Join(root, epic))
+	}
+	if _, err := model.epicFilePath(tasks[2]); err == nil || !strings.Contains(err.Error(), "no epic file") {
+		t.Errorf("empty FilePath: err = %v", err)
+	}
+	if _, err := model.epicFilePath(tasks[0]); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing file: err = %v", err)
+	}
+
+	// The Modules tab opens the module's first incomplete epic
+	if task := model.moduleEpicTask("billing"); task != tasks[1] {
+		t.Errorf("moduleEpicTask = %v, want billing/E01", task)
+	}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if c := editorCommand("/tmp/epic.md"); strings.Join(c.Args, " ") != "code --wait /tmp/epic.md" {
+		t.Errorf("editor args = %v", c.Args)
+	}
+
+	var opened []string
+	orig := runEditor
+	runEditor = func(c *exec.Cmd, done tea.ExecCallback) tea.Cmd {
+		return func() tea.Msg {
+			opened = c.Args
+			return done(nil)
+		}
+	}
+	t.Cleanup(func() { runEditor = orig })
+
+	model.activeTab = 1
+	model.taskScroll = 1 // billing/E01
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if cmd == nil {
+		t.Fatalf("'o' should open the editor, status: %q", updated.(Model).statusMsg)
+	}
+	msg, ok := cmd().(EpicEditedMsg)
+	if !ok || msg.Err != nil || msg.TaskID != "billing/E01" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if strings.Join(opened, " ") != "code --wait "+filepath.Join(root, epic) {
+		t.Errorf("editor ran %v", opened)
+	}
+	updated, _ = updated.(Model).Update(msg)
+	if status := updated.(Model).statusMsg; !strings.Contains(status, "epic-01.md") {
+		t.Errorf("statusMsg = %q", status)
+	}
+
+	// A task without an epic file is reported instead of opening the editor
+	model.taskScroll = 2
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if cmd != nil || !strings.Contains(updated.(Model).statusMsg, "no epic file") {
+		t.Errorf("cmd = %v, statusMsg = %q", cmd != nil, updated.(Model).statusMsg)
+	}
+}
+
+func TestModel_OpensShellInWorktree(t *testing.T) {
+	wtPath := t.TempDir()
+
+	t.Setenv("SHELL", "/bin/zsh")
+	if c := shellCommand(wtPath); strings.Join(c.Args, " ") != "/bin/zsh" || c.Dir != wtPath {
+		t.Errorf("shell args = %v, dir = %q", c.Args, c.Dir)
+	}
+	t.Setenv("SHELL", "")
+	if c := shellCommand(wtPath); c.Args[0] != "/bin/sh" {
+		t.Errorf("shell without $SHELL = %v, want /bin/sh", c.Args)
+	}
+
+	var ranIn string
+	orig := runEditor
+	runEditor = func(c *exec.Cmd, done tea.ExecCallback) tea.Cmd {
+		return func() tea.Msg {
+			ranIn = c.Dir
+			return done(&exec.ExitError{}) // Last command in the shell failed
+		}
+	}
+	t.Cleanup(func() { runEditor = orig })
+
+	model := NewModel(ModelConfig{Agents: []*AgentView{
+		{TaskID: "billing/E01", Status: executor.AgentRunning, WorktreePath: wtPath},
+		{TaskID: "billing/E02", Status: executor.AgentCompleted, WorktreePath: filepath.Join(wtPath, "removed")},
+	}})
+	model.activeTab = 2
+	model.showAgentDetail = true
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'!'}})
+	if cmd == nil {
+		t.Fatalf("'!' should open a shell, status: %q", updated.(Model).statusMsg)
+	}
+	msg, ok := cmd().(ShellExitedMsg)
+	if !ok || msg.Err != nil || msg.TaskID != "billing/E01" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if ranIn != wtPath {
+		t.Errorf("shell ran in %q, want %q", ranIn, wtPath)
+	}
+
+	// A worktree that was removed is reported instead of opening a shell
+	model.selectedAgent = 1
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'!'}})
+	if cmd != nil || !strings.Contains(updated.(Model).statusMsg, "no longer exists") {
+		t.Errorf("cmd = %v, statusMsg = %q", cmd != nil, updated.(Model).statusMsg)
+	}
+}
+
+func TestModel_PlanChangeQueuedForRunningAgent(t *testing.T) {
+	root, wtPath := t.TempDir(), t.TempDir()
+	rel := filepath.Join("docs", "plans", "billing", "epic-02-invoices.md")
+	original := "---\nstatus: not_started\n---\n# Invoices\n\nCreate invoices.\n"
+	write := func(dir, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(root, original)
+	write(wtPath, original)
+
+	mgr := executor.NewAgentManager(2)
+	agent := &executor.Agent{
+		TaskID:       domain.TaskID{Module: "billing", EpicNum: 2},
+		WorktreePath: wtPath,
+		Status:       executor.AgentRunning,
+	}
+	mgr.Add(agent)
+	model := NewModel(ModelConfig{AgentManager: mgr, ProjectRoot: root, SyncPlanChanges: true})
+	changed := func(dir string) {
+		t.Helper()
+		updated, _ := model.Update(PlanSyncMsg{WorktreePath: dir, ChangedFiles: []string{filepath.Join(dir, rel)}})
+		model = updated.(Model)
+	}
+
+	// The syncer marking the epic in progress does not change its requirements
+	write(root, strings.Replace(original, "not_started", "in_progress", 1))
+	changed(root)
+	if n := agent.PendingFollowUps(); n != 0 {
+		t.Fatalf("status change queued %d follow-up(s), want none", n)
+	}
+
+	// The agent's own edits in its worktree are not sent back to it
+	write(wtPath, original+"\n- [x] Invoice table\n")
+	changed(wtPath)
+	if n := agent.PendingFollowUps(); n != 0 {
+		t.Fatalf("agent's own edit queued %d follow-up(s), want none", n)
+	}
+
+	// Changed requirements in the project become a follow-up instruction
+	write(root, original+"\nAlso create credit notes.\n")
+	changed(root)
+	if n := agent.PendingFollowUps(); n != 1 {
+		t.Fatalf("PendingFollowUps() = %d, want 1 after the requirements changed", n)
+	}
+	if !strings.Contains(model.statusMsg, "billing/E02") || !strings.Contains(model.statusMsg, "follow-up") {
+		t.Errorf("statusMsg = %q, want the queued follow-up reported", model.statusMsg)
+	}
+
+	// The same content is not sent twice
+	changed(root)
+	if n := agent.PendingFollowUps(); n != 1 {
+		t.Errorf("PendingFollowUps() = %d after an unchanged save, want still 1", n)
+	}
+
+	// Without the option, plan changes only update the task list
+	model.syncPlanChanges = false
+	write(root, original+"\nAnd refunds.\n")
+	changed(root)
+	if n := agent.PendingFollowUps(); n != 1 {
+		t.Errorf("PendingFollowUps() = %d with the option off, want still 1", n)
+	}
+}
+
+func TestModel_ConsecutiveFailuresPauseAutoMode(t *testing.T) {
+	var agents []*AgentView
+	for i := 0; i < 5; i++ {
+		agents = append(agents, &AgentView{TaskID: fmt.Sprintf("billing/E%02d", i), Status: executor.AgentRunning})
+	}
+	model := NewModel(ModelConfig{MaxActive: 5, Agents: agents, MaxFailuresInARow: 3})
+	model.autoMode = true
+
+	finish := func(taskID string, success bool) {
+		t.Helper()
+		updated, _ := model.Update(AgentCompleteMsg{TaskID: taskID, Success: success})
+		model = updated.(Model)
+	}
+
+	// Failures count up; a success resets the counter
+	finish("billing/E00", false)
+	finish("billing/E01", false)
+	if model.consecutiveFailures != 2 || !model.autoMode {
+		t.Fatalf("after 2 failures: count = %d, autoMode = %v", model.consecutiveFailures, model.autoMode)
+	}
+	finish("billing/E01", false) // Already failed, not counted twice
+	if model.consecutiveFailures != 2 {
+		t.Errorf("repeated completion counted again: count = %d", model.consecutiveFailures)
+	}
+	finish("billing/E02", true)
+	if model.consecutiveFailures != 0 {
+		t.Fatalf("success should reset the counter, got %d", model.consecutiveFailures)
+	}
+
+	// Reaching the threshold pauses auto mode with a warning
+	model.recordAgentOutcome(false)
+	model.recordAgentOutcome(false)
+	if !model.autoMode {
+		t.Fatal("auto mode paused below the threshold")
+	}
+	finish("billing/E03", false)
+	if model.autoMode || !model.autoPausedOnFailure {
+		t.Fatalf("auto mode should pause at 3 failures in a row (autoMode = %v)", model.autoMode)
+	}
+	if !strings.Contains(model.statusMsg, "3 agents failed in a row") {
+		t.Errorf("statusMsg = %q", model.statusMsg)
+	}
+	model.width = 200
+	model.height = 40
+	if view := model.View(); !strings.Contains(view, "Auto paused: 3 failures in a row") {
+		t.Error("header should warn about the pause")
+	}
+
+	// Re-enabling auto mode starts a fresh count
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model = updated.(Model)
+	if !model.autoMode || model.consecutiveFailures != 0 || model.autoPausedOnFailure {
+		t.Errorf("after 'a': autoMode = %v, count = %d, paused = %v", model.autoMode, model.consecutiveFailures, model.autoPausedOnFailure)
+	}
+
+	// A threshold of 0 never pauses
+	model.maxFailuresInARow = 0
+	for i := 0; i < 10; i++ {
+		model.recordAgentOutcome(false)
+	}
+	if !model.autoMode {
+		t.Error("auto mode paused although the threshold is disabled")
+	}
+}
+
+func TestModel_LogEventCapsEvents(t *testing.T) {
+	model := NewModel(ModelConfig{MaxActive: 3})
+	model.width = 160
+	model.height = 40
+	for i := 0; i < maxLogEvents+5; i++ {
+		model.logEvent(EventError, fmt.Sprintf("failure %d", i))
+	}
+	model.logEvent(EventWarning, "Warning: something odd")
+
+	if len(model.events) != maxLogEvents {
+		t.Fatalf("events = %d, want capped at %d", len(model.events), maxLogEvents)
+	}
+	if got := model.events[0].Message; got != "failure 6" {
+		t.Errorf("oldest event = %q, want the first ones dropped", got)
+	}
+	if last := model.events[len(model.events)-1]; last.Level != EventWarning || last.Message != "Warning: something odd" {
+		t.Errorf("newest event = %+v, want the warning", last)
+	}
+	if model.statusMsg != "Warning: something odd" {
+		t.Errorf("statusMsg = %q, want the latest event", model.statusMsg)
+	}
+	if view := model.View(); !strings.Contains(view, fmt.Sprintf("%d new in [l]og", maxLogEvents+6)) {
+		t.Errorf("header does not announce unseen events:\n%s", view)
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	model = updated.(Model)
+	view := model.View()
+	if !model.showEvents || !strings.Contains(view, "something odd") {
+		t.Errorf("pressing l should open the events log:\n%s", view)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.showEvents || strings.Contains(model.View(), "new in [l]og") {
+		t.Error("events should be marked seen after viewing the log")
+	}
+}
+
+func TestModel_KillAgentConfirmation(t *testing.T) {
+	model := NewModel(ModelConfig{
+		MaxActive: 3,
+		Agents: []*AgentView{
+			{TaskID: "tech/E01", Status: executor.AgentRunning},
+			{TaskID: "tech/E02", Status: executor.AgentCompleted},
+		},
+	})
+	model.activeTab = 2
+	model.width = 120
+	model.height = 40
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		return cmd
+	}
+	kill := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")}
+
+	press(kill)
+	if model.cancelConfirm != "tech/E01" || !strings.Contains(model.View(), "KILL AGENT") {
+		t.Fatalf("K should ask to confirm killing the running agent, cancelConfirm = %q", model.cancelConfirm)
+	}
+	if cmd := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}); cmd != nil || model.selectedAgent != 0 {
+		t.Error("the confirmation should consume other keys")
+	}
+	if cmd := press(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil || model.cancelConfirm != "" {
+		t.Fatal("esc should keep the agent running")
+	}
+
+	press(kill)
+	if cmd := press(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || model.cancelConfirm != "" {
+		t.Fatal("enter should start cancelling the agent")
+	}
+
+	updated, _ := model.Update(AgentCancelMsg{TaskID: "tech/E01", Cancelled: true})
+	model = updated.(Model)
+	if model.agents[0].Status != executor.AgentCancelled {
+		t.Errorf("status = %s, want cancelled", model.agents[0].Status)
+	}
+
+	// The process exiting before the cancellation leaves the agent as it was
+	updated, _ = model.Update(AgentCancelMsg{TaskID: "tech/E02"})
+	model = updated.(Model)
+	if model.agents[1].Status != executor.AgentCompleted || !strings.Contains(model.statusMsg, "already finished") {
+		t.Errorf("status = %s, message %q, want a no-op", model.agents[1].Status, model.statusMsg)
+	}
+
+	// Agents that are not running cannot be killed
+	model.selectedAgent = 1
+	press(kill)
+	if model.cancelConfirm != "" || model.statusMsg != "Agent is not running" {
+		t.Errorf("K on a finished agent: cancelConfirm = %q, message %q", model.cancelConfirm, model.statusMsg)
+	}
+}
+
+func TestModel_ShowsStuckAgents(t *testing.T) {
+	model := NewModel(ModelConfig{
+		MaxActive: 3,
+		Agents: []*AgentView{
+			{TaskID: "tech/E01", Status: executor.AgentStuck, Idle: 7 * time.Minute},
+			{TaskID: "tech/E02", Status: executor.AgentRunning, Idle: time.Minute},
+		},
+	})
+	model.activeTab = 2
+	model.width = 120
+	model.height = 40
+
+	if model.activeCount != 2 {
+		t.Errorf("activeCount = %d, want the stuck agent to hold its slot", model.activeCount)
+	}
+	if view := model.View(); !strings.Contains(view, "⚠ tech/E01") || !strings.Contains(view, "silent 7m") {
+		t.Errorf("agents list should flag the stuck agent with its idle time:\n%s", view)
+	}
+
+	model.showAgentDetail = true
+	if view := model.View(); !strings.Contains(view, "Stuck (silent 7m)") || !strings.Contains(view, "last line 7m ago") {
+		t.Errorf("agent detail should show how long the agent has been silent:\n%s", view)
+	}
+}
+
+func TestModel_ShowsScheduledRetry(t *testing.T) {
+	model := NewModel(ModelConfig{
+		MaxActive: 3,
+		Agents: []*AgentView{{
+			TaskID:       "tech/E01",
+			Status:       executor.AgentFailed,
+			Error:        "exit status 1: API Error: 529 Overloaded",
+			RetryAttempt: 2,
+			RetryAt:      time.Now().Add(time.Minute),
+		}},
+	})
+	model.activeTab = 2
+	model.width = 120
+	model.height = 40
+
+	if view := model.View(); !strings.Contains(view, "retry #2 in") {
+		t.Errorf("agents list should show the scheduled retry instead of the error:\n%s", view)
+	}
+	model.showAgentDetail = true
+	if view := model.View(); !strings.Contains(view, "Next:     retry #2 in") || !strings.Contains(view, "529 Overloaded") {
+		t.Errorf("agent detail should show the scheduled retry and the error:\n%s", view)
+	}
+}
+
+func TestModel_CompletedTodayUsesConfiguredWindow(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	reset := time.Now().Add(-time.Hour)
+	for i, finished := range []time.Time{reset.Add(-time.Minute), reset.Add(time.Minute)} {
+		finishedAt := finished
+		if err := store.SaveAgentRun(&taskstore.AgentRun{
+			ID: fmt.Sprintf("run-%d", i), TaskID: fmt.Sprintf("tech/E%02d", i), Status: "completed",
+			StartedAt: finished.Add(-time.Hour), FinishedAt: &finishedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := NewModel(ModelConfig{
+		MaxActive:      3,
+		Store:          store,
+		CompletedSince: func(time.Time) time.Time { return reset },
+	})
+	model.width = 160
+	model.height = 40
+	if model.completedToday != 1 || !strings.Contains(model.View(), "Completed today: 1") {
+		t.Errorf("completedToday = %d, want only the run finished after the reset", model.completedToday)
+	}
+
+	// The count is re-read in the background on a tick once it is stale
+	reset = reset.Add(-time.Hour)
+	now := time.Now().Add(completedTodayRefresh)
+	updated, _ := model.Update(TickMsg(now))
+	model = updated.(Model)
+	if !model.completedTodayAt.Equal(now) {
+		t.Errorf("completedTodayAt = %v, want the refresh requested on the tick", model.completedTodayAt)
+	}
+	updated, _ = model.Update(model.refreshCompletedTodayCmd(now)())
+	if got := updated.(Model).completedToday; got != 2 {
+		t.Errorf("completedToday after refresh = %d, want 2", got)
+	}
+}
+
+func TestRenderSparkline(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   string
+	}{
+		{nil, ""},
+		{[]int{0, 0, 0}, "▁▁▁"},
+		{[]int{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]int{1, 0, 14}, "▂▁█"}, // A single completion still shows next to a busy interval
+		{[]int{3, 3}, "██"},
+	}
+	for _, tt := range tests {
+		if got := renderSparkline(tt.counts); got != tt.want {
+			t.Errorf("renderSparkline(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
+
+func TestModel_DashboardShowsThroughput(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// Two tasks completed in the current interval, one an hour before
+	now := time.Now()
+	for i, finished := range []time.Time{now, now, now.Add(-time.Hour)} {
+		finishedAt := finished
+		if err := store.SaveAgentRun(&taskstore.AgentRun{
+			ID: fmt.Sprintf("run-%d", i), TaskID: fmt.Sprintf("tech/E%02d", i), Status: "completed",
+			StartedAt: finished.Add(-time.Hour), FinishedAt: &finishedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store})
+	model.width = 160
+	model.height = 40
+	if len(model.throughput) != throughputIntervals {
+		t.Fatalf("throughput has %d intervals, want %d", len(model.throughput), throughputIntervals)
+	}
+	if got := model.throughput[throughputIntervals-1]; got != 2 {
+		t.Errorf("current interval = %d, want 2", got)
+	}
+	if got := model.throughput[throughputIntervals-3]; got != 1 {
+		t.Errorf("interval an hour ago = %d, want 1", got)
+	}
+	if view := model.View(); !strings.Contains(view, "completed per 30m, last 12h: ") || !strings.Contains(view, "▅▁█ 3") {
+		t.Errorf("dashboard does not show the throughput sparkline:\n%s", view)
+	}
+}
+
+func TestModel_GroupWeightKeys(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+	now := time.Now()
+	for _, module := range []string{"billing", "tech"} {
+		store.UpsertTask(&domain.Task{
+			ID: domain.TaskID{Module: module, EpicNum: 0}, Title: "Setup", Status: domain.StatusNotStarted,
+			FilePath: "test.md", CreatedAt: now, UpdatedAt: now,
+		})
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store})
+	model.showGroupPriorities = true
+	updated, _ := model.Update(loadGroupPrioritiesCmd(store)())
+	model = updated.(Model)
+	model.selectedPriorityRow = 1 // tech
+
+	press := func(key rune) {
+		t.Helper()
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		if cmd == nil {
+			t.Fatalf("%q should change the weight", key)
+		}
+		updated, _ = updated.(Model).Update(cmd())
+		model = updated.(Model)
+	}
+	press('w')
+	press('w')
+
+	// The heavier group moves to the top of its tier and stays selected
+	if got := model.groupPriorityItems[0]; got.Name != "tech" || got.Weight != 2 {
+		t.Errorf("first group = %+v, want tech with weight 2", got)
+	}
+	if model.selectedPriorityRow != 0 {
+		t.Errorf("selectedPriorityRow = %d, want it to follow tech", model.selectedPriorityRow)
+	}
+	if got := model.groupPriorityItems[0].Priority; got != -1 {
+		t.Errorf("tech tier = %d, want it left unassigned", got)
+	}
+
+	press('W')
+	weights, _ := store.GetGroupWeights()
+	if weights["tech"] != 1 {
+		t.Errorf("stored weights = %v, want tech at 1", weights)
+	}
+	model.queued, _ = store.ListTasks(taskstore.ListOptions{})
+	if ready := model.newScheduler().GetReadyTasks(1); len(ready) != 1 || ready[0].ID.Module != "tech" {
+		t.Errorf("scheduler picked %v, want the heavier tech group first", ready)
+	}
+
+	// A new model starts with the stored weights
+	if got := NewModel(ModelConfig{MaxActive: 3, Store: store}).groupWeights; got["tech"] != 1 {
+		t.Errorf("weights loaded at start = %v, want tech at 1", got)
+	}
+}
+
+func TestModel_SyncModalNewestKey(t *testing.T) {
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// E01 was edited after its DB status changed; E02 at the same time
+	dir := t.TempDir()
+	dbChanged := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var conflicts []isync.SyncConflict
+	for num, mtime := range []time.Time{dbChanged.Add(time.Minute), dbChanged} {
+		id := domain.TaskID{Module: "tech", EpicNum: num + 1}
+		epicPath := filepath.Join(dir, fmt.Sprintf("epic-%02d.md", num+1))
+		os.WriteFile(epicPath, []byte("---\nstatus: complete\n---\n"), 0644)
+		os.Chtimes(epicPath, mtime, mtime)
+		store.UpsertTask(&domain.Task{ID: id, Title: "Task", Status: domain.StatusInProgress, FilePath: epicPath, UpdatedAt: dbChanged})
+		conflicts = append(conflicts, isync.SyncConflict{
+			TaskID: id.String(), DBStatus: "in_progress", MarkdownStatus: "complete", EpicFilePath: epicPath,
+		})
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store, PlansDir: dir})
+	model.syncModal.Visible = true
+	model.syncModal.Conflicts = conflicts
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	model = updated.(Model)
+	want := map[string]string{"tech/E01": "markdown"}
+	if !reflect.DeepEqual(model.syncModal.Resolutions, want) {
+		t.Errorf("resolutions = %v, want %v with E02 left undecided", model.syncModal.Resolutions, want)
+	}
+	if !strings.Contains(model.statusMsg, "1 need a manual choice") {
+		t.Errorf("statusMsg = %q, want the undecided conflict reported", model.statusMsg)
+	}
+}
+
+func TestModel_MaintenanceCapRefusesStart(t *testing.T) {
+	mgr := executor.NewAgentManager(3)
+	mgr.SetMaxMaintenance(1)
+	mgr.Add(&executor.Agent{
+		TaskID: domain.TaskID{Module: executor.MaintenanceModule, EpicNum: 1},
+		Status: executor.AgentRunning,
+	})
+
+	model := NewModel(ModelConfig{MaxActive: 3, AgentManager: mgr})
+	model.maintenanceModal.Templates = maintenance.BuiltinTemplates()
+	model.maintenanceModal.SelectedScope = "all"
+
+	msg, ok := model.startMaintenanceTask()().(MaintenanceStartMsg)
+	if !ok {
+		t.Fatal("startMaintenanceTask should report a MaintenanceStartMsg")
+	}
+	if msg.Success || !strings.Contains(msg.Error, "1 of 1 maintenance agents") {
+		t.Errorf("start = %+v, want refused at the maintenance cap", msg)
+	}
+	if got := mgr.MaintenanceRunningCount(); got != 1 {
+		t.Errorf("running maintenance agents = %d, want 1", got)
+	}
+}
+
+func TestModel_TaskWizardCreatesTask(t *testing.T) {
+	plansDir := t.TempDir()
+	moduleDir := filepath.Join(plansDir, "billing")
+	os.MkdirAll(moduleDir, 0755)
+	os.WriteFile(filepath.Join(moduleDir, "epic-01-setup.md"), []byte("---\nstatus: not_started\n---\n\n# E01: Setup\n"), 0644)
+
+	store, err := taskstore.New(":memory:")
+	if err != nil {
+		t.Fatalf("taskstore.New: %v", err)
+	}
+	defer store.Close()
+	syncer := isync.New(plansDir)
+	if _, err := syncer.TwoWaySync(store); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewModel(ModelConfig{MaxActive: 3, Store: store, Syncer: syncer})
+	if err := model.reloadTasksFromStore(); err != nil {
+		t.Fatal(err)
+	}
+	model.activeTab = 1
+	model.width, model.height = 200, 40
+	// send delivers a key and the message of the command it returns, if any
+	send := func(key tea.KeyMsg) {
+		updated, cmd := model.Update(key)
+		model = updated.(Model)
+		if cmd != nil {
+			updated, _ = model.Update(cmd())
+			model = updated.(Model)
+		}
+	}
+	typeText := func(s string) {
+		for _, r := range s {
+			if r == ' ' {
+				send(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}})
+			} else {
+				send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+		}
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	w := model.taskWizard
+	if !w.Visible || w.Fields[wizardModule] != "billing" || w.Fields[wizardEpic] != "2" || w.Focus != wizardTitle {
+		t.Fatalf("wizard = %+v, want billing/E02 prefilled with the title focused", w)
+	}
+	typeText("Invoice export")
+	if !strings.Contains(model.View(), "NEW TASK") {
+		t.Error("view should show the wizard")
+	}
+
+	// A used ID is refused and the wizard stays open
+	send(tea.KeyMsg{Type: tea.KeyShiftTab})
+	send(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeText("1")
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if !model.taskWizard.Visible || !strings.Contains(model.taskWizard.Error, "already exists") {
+		t.Fatalf("duplicate ID: wizard = %+v, want an already exists error", model.taskWizard)
+	}
+
+	// Invalid input is reported without creating anything
+	send(tea.KeyMsg{Type: tea.KeyBackspace})
+	typeText("2")
+	for model.taskWizard.Focus != wizardPriority {
+		send(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	typeText("urgent")
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(model.taskWizard.Error, "priority must be") {
+		t.Fatalf("bad priority: error = %q", model.taskWizard.Error)
+	}
+	for range "urgent" {
+		send(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	typeText("high")
+	send(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("1")
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if model.taskWizard.Visible {
+		t.Fatalf("wizard should close after creating, error = %q", model.taskWizard.Error)
+	}
+	if !strings.Contains(model.statusMsg, "Created billing/E02") {
+		t.Errorf("status = %q", model.statusMsg)
+	}
+	task, err := store.GetTask("billing/E02")
+	if err != nil {
+		t.Fatalf("created task not in the store: %v", err)
+	}
+	want := []domain.TaskID{{Module: "billing", EpicNum: 1}}
+	if task.Title != "Invoice export" || task.Priority != domain.PriorityHigh || !reflect.DeepEqual(task.DependsOn, want) {
+		t.Errorf("stored task = %+v", task)
+	}
+	found := false
+	for _, listed := range model.allTasks {
+		found = found || listed.ID.String() == "billing/E02"
+	}
+	if !found {
+		t.Error("task list should be reloaded with the new task")
+	}
+}
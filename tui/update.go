@@ -3,13 +3,16 @@ package tui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,16 +22,20 @@ import (
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/maintenance"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/mcp"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/netutil"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/observer"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/parser"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/scheduler"
 	isync "github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/testselect"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/updater"
 )
 
 // TestCompleteMsg is sent when test execution completes
 type TestCompleteMsg struct {
+	Module string // Module whose tests ran
+	Commit string // Commit the tests ran against ("" if unknown)
 	Output string
 	Err    error
 }
@@ -52,6 +59,18 @@ type BatchStartMsg struct {
 	Errors  []string         // Any errors during startup
 }
 
+// LastBatchSavedMsg is sent after the started batch was recorded for replay
+type LastBatchSavedMsg struct {
+	Err error
+}
+
+// LastPassingCommitSavedMsg reports whether the commit a module's tests
+// passed at was recorded
+type LastPassingCommitSavedMsg struct {
+	Module string
+	Err    error
+}
+
 // BatchPauseMsg is sent to pause batch execution
 type BatchPauseMsg struct{}
 
@@ -82,6 +101,65 @@ type AgentResumeMsg struct {
 	Error   string
 }
 
+// PromptCopiedMsg reports the result of copying an agent prompt to the clipboard
+type PromptCopiedMsg struct {
+	TaskID string
+	Err    error
+}
+
+// PromptEditedMsg carries an agent prompt after it was edited in $EDITOR
+type PromptEditedMsg struct {
+	TaskID   string
+	Original string // Prompt before editing
+	Prompt   string // Prompt as saved by the editor
+	Err      error
+}
+
+// NoteEditedMsg carries a note for a historical agent run after it was
+// written in $EDITOR
+type NoteEditedMsg struct {
+	RunID  string
+	TaskID string
+	Note   string
+	Err    error
+}
+
+// RunAnnotatedMsg reports the result of saving a note for an agent run
+type RunAnnotatedMsg struct {
+	RunID  string
+	TaskID string
+	Note   string
+	Err    error
+}
+
+// ShellExitedMsg reports that the shell opened in an agent's worktree exited
+type ShellExitedMsg struct {
+	TaskID string
+	Err    error
+}
+
+// EpicEditedMsg reports that the editor opened on a task's epic file exited
+type EpicEditedMsg struct {
+	TaskID string
+	Path   string
+	Err    error
+}
+
+// AgentRestartMsg is sent when an agent restart from scratch completes
+type AgentRestartMsg struct {
+	TaskID       string
+	WorktreePath string
+	Success      bool
+	Error        string
+}
+
+// AgentCancelMsg is sent when cancelling an agent completes
+type AgentCancelMsg struct {
+	TaskID    string
+	Cancelled bool   // False if the agent had already finished
+	Error     string // Set if cancelling, or removing the worktree after it, failed
+}
+
 // AgentHistoryMsg contains loaded historical agent runs
 type AgentHistoryMsg struct {
 	History []*AgentView
@@ -97,8 +175,23 @@ type HistoryLogsMsg struct {
 
 // WorkersUpdateMsg updates the workers list from build pool
 type WorkersUpdateMsg struct {
-	Workers []*WorkerView
-	Status  string // "connected" or "unreachable"
+	Workers      []*WorkerView
+	Status       string              // "connected" or "unreachable"
+	Coordinators []CoordinatorStatus // Additional coordinators, in configured order
+}
+
+// CompletedTodayMsg carries the "Completed today" count and the throughput
+// sparkline's counts re-read from the store
+type CompletedTodayMsg struct {
+	Count      int
+	Throughput []int
+	Err        error
+}
+
+// BuildPoolToggledMsg reports that the in-process build pool was started or stopped
+type BuildPoolToggledMsg struct {
+	Running bool
+	Err     error // Non-fatal start problem, e.g. the git daemon failed
 }
 
 // WorkerTestMsg reports the result of a worker test
@@ -140,7 +233,9 @@ type SyncCompleteMsg struct {
 
 // SyncResolveMsg reports conflict resolution completion
 type SyncResolveMsg struct {
-	Err error
+	Resolutions map[string]string // Resolutions that were applied, kept to retry on failure
+	Changes     []isync.SyncChange
+	Err         error
 }
 
 // GroupPrioritiesMsg contains loaded group priority data
@@ -156,12 +251,40 @@ type SetGroupPriorityMsg struct {
 	Error    error
 }
 
+// SetGroupWeightMsg reports result of setting a group weight
+type SetGroupWeightMsg struct {
+	Group  string
+	Weight int
+	Error  error
+}
+
 // RemoveGroupPriorityMsg reports result of removing a group priority
 type RemoveGroupPriorityMsg struct {
 	Group string
 	Error error
 }
 
+// TaskOverrideMsg reports result of promoting or demoting a task
+type TaskOverrideMsg struct {
+	TaskID   string
+	Promoted bool
+	Error    error
+}
+
+// TaskReopenMsg reports result of reopening a completed task
+type TaskReopenMsg struct {
+	TaskID   string
+	Reopened bool // False if the store update failed
+	Error    error
+}
+
+// TaskCreateMsg reports the result of creating a task from the wizard
+type TaskCreateMsg struct {
+	TaskID string
+	Path   string // Empty if no epic file was written
+	Error  error
+}
+
 // UpdateCheckMsg reports the result of checking for updates
 type UpdateCheckMsg struct {
 	LatestVersion string
@@ -203,6 +326,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.syncModal.Resolutions[c.TaskID] = "db"
 				}
 				return m, nil
+			case "n":
+				// Resolve each conflict as the side changed last; those it
+				// cannot decide are left for manual resolution
+				if m.store == nil {
+					return m, nil
+				}
+				chosen := m.syncer.ChooseResolutions(m.store, m.syncModal.Conflicts, isync.ResolveNewest)
+				for taskID, resolution := range chosen {
+					m.syncModal.Resolutions[taskID] = resolution
+				}
+				if undecided := len(m.syncModal.Conflicts) - len(chosen); undecided > 0 {
+					m.statusMsg = fmt.Sprintf("Newest side chosen for %d conflicts; %d need a manual choice", len(chosen), undecided)
+				} else {
+					m.statusMsg = fmt.Sprintf("Newest side chosen for all %d conflicts; enter to apply", len(chosen))
+				}
+				return m, nil
 			case "j", "down":
 				if m.syncModal.Selected < len(m.syncModal.Conflicts)-1 {
 					m.syncModal.Selected++
@@ -248,6 +387,85 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil // Consume all other keys when modal is open
 		}
 
+		// Handle batch start confirmation keys
+		if m.batchConfirm.Visible {
+			switch msg.String() {
+			case "enter", "y":
+				tasks := m.batchConfirm.Tasks
+				m.batchConfirm = BatchConfirmModal{}
+				m.batchRunning = true
+				m.batchPaused = false
+				m.statusMsg = fmt.Sprintf("Starting batch: %d task(s)...", len(tasks))
+				return m, startBatchCmd(
+					m.projectRoot,
+					tasks,
+					m.worktreeManager,
+					m.agentManager,
+					m.planWatcher,
+				)
+			case "esc", "n":
+				m.batchConfirm = BatchConfirmModal{}
+				m.statusMsg = "Batch start cancelled"
+				return m, nil
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil // Consume all other keys when modal is open
+		}
+
+		// Handle agent cancellation confirmation keys
+		if m.cancelConfirm != "" {
+			switch msg.String() {
+			case "enter", "y":
+				taskID := m.cancelConfirm
+				m.cancelConfirm = ""
+				m.statusMsg = fmt.Sprintf("Cancelling agent %s...", taskID)
+				return m, cancelAgentCmd(m.agentManager, m.worktreeManager, taskID)
+			case "esc", "n":
+				m.cancelConfirm = ""
+				m.statusMsg = "Agent left running"
+				return m, nil
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil // Consume all other keys when modal is open
+		}
+
+		// Handle task creation wizard keys; printable keys are typed into the focused field
+		if m.taskWizard.Visible {
+			w := &m.taskWizard
+			switch msg.Type {
+			case tea.KeyCtrlC:
+				return m, tea.Quit
+			case tea.KeyEsc:
+				m.taskWizard = TaskWizardModal{}
+				m.statusMsg = "Task creation cancelled"
+			case tea.KeyTab, tea.KeyDown:
+				m.focusWizardField((w.Focus + 1) % taskWizardFieldCount)
+			case tea.KeyShiftTab, tea.KeyUp:
+				m.focusWizardField((w.Focus + taskWizardFieldCount - 1) % taskWizardFieldCount)
+			case tea.KeyBackspace:
+				if r := []rune(w.Fields[w.Focus]); len(r) > 0 {
+					w.Fields[w.Focus] = string(r[:len(r)-1])
+				}
+			case tea.KeyRunes, tea.KeySpace:
+				w.Fields[w.Focus] += string(msg.Runes)
+			case tea.KeyEnter:
+				if w.Creating {
+					return m, nil
+				}
+				task, err := w.newTask()
+				if err != nil {
+					w.Error = err.Error()
+					return m, nil
+				}
+				w.Error = ""
+				w.Creating = true
+				return m, createTaskCmd(m.syncer, m.store, task)
+			}
+			return m, nil // Consume all other keys when modal is open
+		}
+
 		// Handle maintenance modal keys
 		if m.maintenanceModal.Visible {
 			switch msg.String() {
@@ -310,6 +528,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil // Consume all other keys when modal is open
 		}
 
+		// Handle events log keys
+		if m.showEvents {
+			switch msg.String() {
+			case "j", "down":
+				// Towards older events, newest are listed first
+				if m.eventsScroll < len(m.events)-1 {
+					m.eventsScroll++
+				}
+			case "k", "up":
+				if m.eventsScroll > 0 {
+					m.eventsScroll--
+				}
+			case "l", "esc":
+				m.showEvents = false
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			}
+			return m, nil // Consume all other keys when the events log is open
+		}
+
 		// Handle group priorities view keys
 		if m.showGroupPriorities {
 			switch msg.String() {
@@ -345,6 +583,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, setGroupPriorityCmd(m.store, item.Name, newPriority)
 				}
 				return m, nil
+			case "w", "W":
+				// Raise (w) or lower (W) the weight within the tier
+				if m.selectedPriorityRow < len(m.groupPriorityItems) {
+					item := m.groupPriorityItems[m.selectedPriorityRow]
+					newWeight := item.Weight + 1
+					if msg.String() == "W" {
+						newWeight = max(item.Weight-1, 0)
+					}
+					return m, setGroupWeightCmd(m.store, item.Name, newWeight)
+				}
+				return m, nil
 			case "u":
 				// Unassign (remove from table)
 				if m.selectedPriorityRow < len(m.groupPriorityItems) {
@@ -438,6 +687,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Load group priority data
 				return m, loadGroupPrioritiesCmd(m.store)
 			}
+		case "l":
+			// Open the events log, marking all events as seen
+			m.showEvents = true
+			m.eventsScroll = 0
+			m.eventsSeen = m.eventsLogged
 		case "G":
 			// Jump to bottom of agent output (handled in view by setting to max)
 			if m.activeTab == 2 && (m.showAgentDetail || m.showHistoryDetail) {
@@ -453,14 +707,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					histAgent := m.agentHistory[m.selectedHistory]
 					if histAgent.LogPath != "" {
 						// Load logs from file
-						return m, loadHistoryLogsCmd(histAgent.LogPath, m.selectedHistory)
+						return m, loadHistoryLogsCmd(histAgent.LogPath, m.selectedHistory, m.historyLogLines)
 					}
 				} else if len(m.agents) > 0 && !m.showAgentDetail {
 					m.showAgentDetail = true
 					// For running agents, show bottom (most recent)
 					// For completed/failed agents, show from top (full output)
 					agent := m.agents[m.selectedAgent]
-					if agent.Status == executor.AgentRunning {
+					if agent.Status.Active() {
 						m.agentOutputScroll = -1 // Start at bottom (most recent)
 					} else {
 						m.agentOutputScroll = 0 // Start at top (see full output)
@@ -470,7 +724,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.agentOutputScroll = 0
 				}
 			}
+		case "S":
+			// Show/hide the changes made by the last sync (Modules tab)
+			if m.activeTab == 3 {
+				if len(m.syncChanges) == 0 {
+					m.statusMsg = "No changes from the last sync"
+				} else {
+					m.showChanges = !m.showChanges
+				}
+			}
 		case "esc":
+			// Close the sync changes panel
+			if m.activeTab == 3 {
+				m.showChanges = false
+			}
 			// Close agent/history detail view
 			if m.activeTab == 2 {
 				if m.showHistoryDetail {
@@ -491,7 +758,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if av.Status == executor.AgentCompleted || av.Status == executor.AgentFailed {
 						m.statusMsg = fmt.Sprintf("Resuming agent %s...", av.TaskID)
 						return m, resumeAgentCmd(m.agentManager, av.TaskID)
-					} else if av.Status == executor.AgentRunning {
+					} else if av.Status.Active() {
 						m.statusMsg = "Agent is already running"
 					} else {
 						m.statusMsg = "Cannot resume agent in this state"
@@ -502,6 +769,60 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.statusMsg = "Agents refreshed"
 				}
 			}
+		case "c":
+			// Agent detail: copy the prompt to the clipboard
+			if m.activeTab == 2 && m.showAgentDetail && m.selectedAgent < len(m.agents) {
+				av := m.agents[m.selectedAgent]
+				prompt := m.agentPrompt(av)
+				if prompt == "" {
+					m.statusMsg = "Agent has no prompt"
+					return m, nil
+				}
+				return m, copyPromptCmd(av.TaskID, prompt)
+			}
+		case "R":
+			// On Modules tab: retry the last failed sync or conflict resolution
+			if m.activeTab == 3 && !m.syncModal.Visible {
+				if m.failedSync == nil {
+					m.statusMsg = "No failed sync to retry"
+				} else if m.syncer == nil || m.store == nil {
+					m.statusMsg = "Sync not available (no plans directory or database)"
+				} else {
+					op := m.failedSync
+					m.failedSync = nil
+					m.statusMsg = "Retrying " + op.name() + "..."
+					return m, op.cmd(m.syncer, m.store)
+				}
+				return m, nil
+			}
+			// On Agents tab: restart the selected agent from scratch (fresh worktree and session)
+			if m.activeTab == 2 && !m.showAgentHistory && len(m.agents) > 0 && m.selectedAgent < len(m.agents) {
+				av := m.agents[m.selectedAgent]
+				if av.Status == executor.AgentCompleted || av.Status == executor.AgentFailed || av.Status == executor.AgentCancelled {
+					m.statusMsg = fmt.Sprintf("Restarting agent %s from scratch...", av.TaskID)
+					return m, restartAgentCmd(m.agentManager, m.worktreeManager, m.planWatcher, av.TaskID, m.findTask(av.TaskID), "")
+				} else if av.Status.Active() {
+					m.statusMsg = "Agent is still running"
+				} else {
+					m.statusMsg = "Cannot restart agent in this state"
+				}
+			}
+		case "K":
+			// On Agents tab: kill the selected running agent, after confirmation
+			if m.activeTab == 2 && !m.showAgentHistory && len(m.agents) > 0 && m.selectedAgent < len(m.agents) {
+				av := m.agents[m.selectedAgent]
+				if av.Status.Active() {
+					m.cancelConfirm = av.TaskID
+				} else {
+					m.statusMsg = "Agent is not running"
+				}
+			}
+		case "L":
+			// Cycle the Dashboard layout: auto, compact, full
+			if m.activeTab == 0 {
+				m.dashboardLayout = m.dashboardLayout.next()
+				m.statusMsg = "Dashboard layout: " + m.dashboardLayout.String()
+			}
 		case "h":
 			// Toggle agent history on Agents tab
 			if m.activeTab == 2 && !m.showAgentDetail {
@@ -545,13 +866,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.viewMode = ViewByPriority
 			}
-		case "x":
-			// Execute tests for selected module (only on modules tab)
+		case "f":
+			// Toggle the "unblocking soon" filter on the Tasks tab
+			if m.activeTab == 1 {
+				m.unblockingSoon = !m.unblockingSoon
+				m.taskScroll = 0
+			}
+		case "x", "X":
+			// Execute tests for selected module (only on modules tab). X
+			// swaps between all tests and those of code changed since they
+			// last passed, whichever x does not run.
 			if m.activeTab == 3 && len(m.modules) > 0 && !m.testRunning {
 				m.testRunning = true
 				m.testOutput = ""
 				moduleName := m.modules[m.selectedModule].Name
-				return m, runModuleTests(m.projectRoot, moduleName)
+				if m.testChangedOnly != (msg.String() == "X") {
+					return m, runChangedModuleTests(m.projectRoot, moduleName, m.lastPassingCommits[moduleName], m.moduleTestTimeout)
+				}
+				return m, runModuleTests(m.projectRoot, moduleName, m.moduleTestTimeout)
 			}
 		case "+", "=":
 			// Increase max agents (only on agents tab)
@@ -585,37 +917,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Get in-progress task IDs from currently running agents
 					inProgress := make(map[string]bool)
 					for _, a := range m.agents {
-						if a.Status == executor.AgentRunning {
+						if a.Status.Active() {
 							inProgress[a.TaskID] = true
 						}
 					}
 
-					// Load group priorities from store if available
-					var groupPriorities map[string]int
-					if m.store != nil {
-						groupPriorities, _ = m.store.GetGroupPriorities()
-					}
-
 					// Use scheduler to select tasks that don't conflict with running agents
-					var sched *scheduler.Scheduler
-					if len(groupPriorities) > 0 {
-						sched = scheduler.NewWithPriorities(m.queued, m.completedTasks, groupPriorities)
-					} else {
-						sched = scheduler.New(m.queued, m.completedTasks)
-					}
+					sched := m.newScheduler()
 					readyTasks := sched.GetReadyTasksExcluding(slotsAvailable, inProgress)
 
-					if len(readyTasks) > 0 {
-						m.batchRunning = true
-						m.batchPaused = false
-						m.statusMsg = fmt.Sprintf("Starting batch: %d task(s)...", len(readyTasks))
-						return m, startBatchCmd(
-							m.projectRoot,
-							readyTasks,
-							m.worktreeManager,
-							m.agentManager,
-							m.planWatcher,
-						)
+					if len(readyTasks) == 0 && len(sched.Cycles()) > 0 {
+						m.statusMsg = "No tasks ready: " + cycleWarning(sched)
+					} else if len(readyTasks) > 0 {
+						// Confirm the batch with its estimated cost before starting
+						estimate, err := m.estimateBatch(readyTasks)
+						if err != nil {
+							m.logEvent(EventError, fmt.Sprintf("Failed to estimate batch cost: %v", err))
+						}
+						m.batchConfirm = BatchConfirmModal{Visible: true, Tasks: readyTasks, Estimate: estimate}
 					} else {
 						m.statusMsg = "No independent tasks ready (dependencies pending)"
 					}
@@ -624,6 +943,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.statusMsg = "No tasks queued"
 				}
+			} else if m.activeTab == 1 {
+				// Start exactly the marked tasks (Tasks tab)
+				return m, m.startMarkedTasks()
 			} else if m.activeTab == 3 && !m.syncModal.Visible && m.syncer != nil && m.store != nil {
 				// Sync (only on Modules tab when not already syncing)
 				m.statusMsg = "Syncing..."
@@ -646,11 +968,115 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.statusMsg = "Batch resumed"
 				}
 			}
+		case "e":
+			// Toggle stderr-only output in agent/history detail
+			if m.activeTab == 2 && (m.showAgentDetail || m.showHistoryDetail) {
+				m.showStderrOnly = !m.showStderrOnly
+				m.agentOutputScroll = 0
+			}
+		case "F":
+			// Cycle the agent output filter in agent/history detail
+			if m.activeTab == 2 && (m.showAgentDetail || m.showHistoryDetail) {
+				m.outputFilter = m.outputFilter.next()
+				m.agentOutputScroll = 0
+				m.statusMsg = "Agent output: " + m.outputFilter.label()
+			}
+		case "P":
+			// Promote/demote the selected task (Tasks tab)
+			if m.activeTab == 1 {
+				task := m.selectedTask()
+				if task == nil {
+					m.statusMsg = "No task selected"
+				} else if task.Status == domain.StatusComplete {
+					m.statusMsg = fmt.Sprintf("%s is already complete", task.ID)
+				} else {
+					_, promoted := m.taskOverrides[task.ID.String()]
+					return m, setTaskOverrideCmd(m.store, task.ID.String(), !promoted)
+				}
+			}
+		case " ":
+			// Mark/unmark the selected task for a targeted start (Tasks tab)
+			if m.activeTab == 1 {
+				task := m.selectedTask()
+				if task == nil {
+					m.statusMsg = "No task selected"
+				} else if task.Status != domain.StatusNotStarted {
+					m.statusMsg = fmt.Sprintf("%s is already started or complete", task.ID)
+				} else {
+					m.toggleMarked(task.ID.String())
+					m.statusMsg = fmt.Sprintf("%d task(s) marked - press s to start them", len(m.markedTasks))
+				}
+			}
+		case "o":
+			// Open the selected task's epic file in $EDITOR (Tasks and Modules tabs)
+			if m.activeTab == 1 || m.activeTab == 3 {
+				var task *domain.Task
+				if m.activeTab == 1 {
+					task = m.selectedTask()
+				} else if m.selectedModule < len(m.modules) {
+					task = m.moduleEpicTask(m.modules[m.selectedModule].Name)
+				}
+				if task == nil {
+					m.statusMsg = "No task selected"
+					return m, nil
+				}
+				path, err := m.epicFilePath(task)
+				if err != nil {
+					m.logEvent(EventError, fmt.Sprintf("Cannot open epic: %v", err))
+					return m, nil
+				}
+				return m, openEpicCmd(task.ID.String(), path)
+			}
+		case "O":
+			// Reopen the selected completed task so it is scheduled again (Tasks tab)
+			if m.activeTab == 1 {
+				task := m.selectedTask()
+				if task == nil {
+					m.statusMsg = "No task selected"
+				} else if task.Status != domain.StatusComplete {
+					m.statusMsg = fmt.Sprintf("%s is not complete", task.ID)
+				} else {
+					m.statusMsg = fmt.Sprintf("Reopening %s...", task.ID)
+					return m, reopenTaskCmd(m.syncer, m.store, task.ID.String())
+				}
+			}
+		case "N":
+			// Open the task creation wizard (Tasks tab)
+			if m.activeTab == 1 {
+				m.openTaskWizard()
+			}
+		case "B":
+			// Replay the last batch (only on Dashboard tab)
+			if m.activeTab == 0 {
+				if m.batchRunning {
+					m.statusMsg = "Batch already running"
+				} else {
+					return m, m.replayLastBatch()
+				}
+			}
+		case "b":
+			// Start or stop the in-process build pool (only on Dashboard tab)
+			if m.activeTab == 0 {
+				if m.buildPool == nil {
+					m.statusMsg = "Build pool not configured (enable build_pool or local_fallback in config)"
+				} else if !m.buildPoolToggling {
+					m.buildPoolToggling = true
+					if m.buildPool.Running() {
+						m.statusMsg = "Stopping build pool..."
+						return m, toggleBuildPoolCmd(m.buildPool, false)
+					}
+					m.statusMsg = "Starting build pool..."
+					return m, toggleBuildPoolCmd(m.buildPool, true)
+				}
+			}
 		case "a":
 			// Toggle auto mode (only on Dashboard tab)
 			if m.activeTab == 0 {
 				m.autoMode = !m.autoMode
 				if m.autoMode {
+					// Turning auto mode back on grants a fresh set of attempts
+					m.consecutiveFailures = 0
+					m.autoPausedOnFailure = false
 					m.statusMsg = "Auto mode ON - will start tasks as slots become available"
 					// If not batch running and slots available, start immediately
 					if !m.batchRunning {
@@ -669,6 +1095,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statusMsg = "Build pool not connected"
 			}
 		case "E":
+			// Agent detail: edit the prompt in $EDITOR, then restart with it
+			if m.activeTab == 2 && m.showAgentDetail && m.selectedAgent < len(m.agents) {
+				av := m.agents[m.selectedAgent]
+				prompt := m.agentPrompt(av)
+				switch {
+				case av.Status.Active():
+					m.statusMsg = "Agent is still running"
+				case av.Status != executor.AgentCompleted && av.Status != executor.AgentFailed && av.Status != executor.AgentCancelled:
+					m.statusMsg = "Cannot restart agent in this state"
+				case prompt == "":
+					m.statusMsg = "Agent has no prompt"
+				default:
+					return m, editPromptCmd(av.TaskID, prompt)
+				}
+				return m, nil
+			}
 			// Test worker error handling (Dashboard tab)
 			if m.activeTab == 0 {
 				if m.buildPoolURL != "" && m.buildPoolStatus == "connected" {
@@ -681,34 +1123,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, testEmbeddedWorkerDirectCmd(m.projectRoot)
 				}
 			}
+		case "n":
+			// History detail: write a note for the run in $EDITOR
+			if m.activeTab == 2 && m.showHistoryDetail && m.selectedHistory < len(m.agentHistory) {
+				hist := m.agentHistory[m.selectedHistory]
+				if m.store == nil {
+					m.statusMsg = "No database configured"
+					return m, nil
+				}
+				return m, editNoteCmd(hist.RunID, hist.TaskID)
+			}
+		case "!":
+			// Agent detail: open a shell in the agent's worktree, returning on exit
+			if m.activeTab == 2 && m.showAgentDetail && m.selectedAgent < len(m.agents) {
+				av := m.agents[m.selectedAgent]
+				if av.WorktreePath == "" {
+					m.statusMsg = fmt.Sprintf("%s has no worktree", av.TaskID)
+					return m, nil
+				}
+				if info, err := os.Stat(av.WorktreePath); err != nil || !info.IsDir() {
+					m.statusMsg = fmt.Sprintf("Worktree of %s no longer exists: %s", av.TaskID, av.WorktreePath)
+					return m, nil
+				}
+				return m, openShellCmd(av.TaskID, av.WorktreePath)
+			}
 		case "A":
 			// Run agent test (Dashboard tab) - spawns Claude to test MCP tools
 			if m.activeTab == 0 {
 				// Generate unique task ID for the test agent
 				taskID := fmt.Sprintf("mcp-test-%d", time.Now().UnixNano())
 
-				// Add agent to view immediately
+				// Add agent to view immediately; its output streams in over outputCh
+				outputCh := make(chan string, testAgentOutputBuffer)
 				m.agents = append(m.agents, &AgentView{
-					TaskID: taskID,
-					Title:  "MCP Tools Test",
-					Status: executor.AgentRunning,
+					TaskID:   taskID,
+					Title:    "MCP Tools Test",
+					Status:   executor.AgentRunning,
+					outputCh: outputCh,
 				})
 				m.activeCount++
 
-				// Get executor type from agent manager
-				executorType := ""
+				// Get executor type and its binary from agent manager
+				executorType, binaryPath := "", ""
 				if m.agentManager != nil {
 					executorType = string(m.agentManager.GetExecutorType())
+					binaryPath = m.agentManager.GetBinaryPath(m.agentManager.GetExecutorType())
 				}
 
 				if m.buildPoolURL != "" && m.buildPoolStatus == "connected" {
 					// Use external coordinator
 					m.statusMsg = "Starting agent test via coordinator..."
-					return m, runAgentTestCmd(taskID, m.buildPoolURL, m.projectRoot, executorType)
+					return m, runAgentTestCmd(taskID, m.buildPoolURL, m.projectRoot, executorType, binaryPath, outputCh)
 				} else {
 					// Start temporary coordinator with embedded worker
 					m.statusMsg = "Starting agent test with embedded worker..."
-					return m, runAgentTestWithEmbeddedCmd(taskID, m.projectRoot, executorType)
+					return m, runAgentTestWithEmbeddedCmd(taskID, m.projectRoot, executorType, binaryPath, outputCh)
 				}
 			}
 		case "M":
@@ -802,12 +1271,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.agentManager != nil {
 			m.updateAgentsFromManager()
 		}
-		// Update test agent output from shared buffer
+		// Pick up output streamed by test agents
 		m.updateTestAgentOutput()
 		// Fetch workers if build pool is configured
 		cmds := []tea.Cmd{tickCmd()}
-		if m.buildPoolURL != "" {
-			cmds = append(cmds, fetchWorkersCmd(m.buildPoolURL))
+		if now := time.Time(msg); now.Sub(m.completedTodayAt) >= completedTodayRefresh {
+			if cmd := m.refreshCompletedTodayCmd(now); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		if m.shouldFetchWorkers(time.Time(msg)) {
+			m.workersFetchInFlight = true
+			cmds = append(cmds, fetchWorkersCmd(m.buildPoolURL, m.coordinators))
 		}
 		// In auto mode, periodically try to start new tasks
 		if m.autoMode && !m.batchPaused && !m.batchRunning {
@@ -817,9 +1292,60 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case CompletedTodayMsg:
+		if msg.Err == nil {
+			m.completedToday = msg.Count
+			m.throughput = msg.Throughput
+		}
+		return m, nil
+
 	case WorkersUpdateMsg:
-		m.workers = msg.Workers
-		m.buildPoolStatus = msg.Status
+		m.coordinatorStatuses = msg.Coordinators
+		if m.buildPoolURL == "" {
+			// Build pool was stopped while the fetch was in flight
+			if len(m.coordinators) == 0 {
+				m.workersFetchInFlight = false
+				return m, nil
+			}
+			msg.Status = ""
+		} else {
+			m.workers = msg.Workers
+			m.buildPoolStatus = msg.Status
+		}
+
+		// Keep polling at full speed while any coordinator is reachable
+		fetchStatus := msg.Status
+		for _, c := range msg.Coordinators {
+			if c.Status == "connected" {
+				fetchStatus = "connected"
+			}
+		}
+		if fetchStatus == "" {
+			fetchStatus = "unreachable"
+		}
+		m.recordWorkersFetch(fetchStatus, time.Now())
+		return m, nil
+
+	case BuildPoolToggledMsg:
+		m.buildPoolToggling = false
+		m.workers = nil
+		m.workersFetchBackoff = 0
+		m.workersNextFetch = time.Time{}
+		if msg.Running {
+			m.buildPoolURL = m.buildPool.URL()
+			m.buildPoolStatus = "unreachable" // Updated on the next fetch
+			m.statusMsg = "Build pool started"
+			if msg.Err != nil {
+				m.logEvent(EventWarning, fmt.Sprintf("Build pool started with errors: %v", msg.Err))
+			}
+		} else {
+			m.buildPoolURL = ""
+			m.buildPoolStatus = "disabled"
+			m.statusMsg = "Build pool stopped (running agents lose build tools until it is started again)"
+		}
+		if m.agentManager != nil {
+			m.agentManager.SetBuildPoolURL(m.buildPoolURL)
+		}
 		return m, nil
 
 	case AgentUpdateMsg:
@@ -833,7 +1359,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update active count
 		m.activeCount = 0
 		for _, a := range m.agents {
-			if a.Status == executor.AgentRunning {
+			if a.Status.Active() {
 				m.activeCount++
 			}
 		}
@@ -844,6 +1370,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var completedIdx int = -1
 		for i, a := range m.agents {
 			if a.TaskID == msg.TaskID {
+				if a.Status != executor.AgentCompleted && a.Status != executor.AgentFailed {
+					m.recordAgentOutcome(msg.Success)
+				}
 				if msg.Success {
 					// Mark task as completed for dependency tracking
 					if m.completedTasks == nil {
@@ -871,7 +1400,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			agent := m.agents[completedIdx]
 			if m.worktreeManager != nil && agent.WorktreePath != "" {
 				if err := m.worktreeManager.Remove(agent.WorktreePath); err != nil {
-					m.statusMsg = fmt.Sprintf("Warning: worktree cleanup failed: %v", err)
+					m.logEvent(EventWarning, fmt.Sprintf("Warning: worktree cleanup failed: %v", err))
 				}
 			}
 			m.agents = append(m.agents[:completedIdx], m.agents[completedIdx+1:]...)
@@ -880,19 +1409,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.selectedAgent--
 			}
 			// Recompute module summaries
-			m.modules = computeModuleSummaries(m.allTasks)
+			m.modules = domain.SummarizeModules(m.allTasks)
 		}
 		// Update active count
 		m.activeCount = 0
 		for _, a := range m.agents {
-			if a.Status == executor.AgentRunning {
+			if a.Status.Active() {
 				m.activeCount++
 			}
 		}
 		// Check if all agents are done
 		allDone := true
 		for _, a := range m.agents {
-			if a.Status == executor.AgentRunning || a.Status == executor.AgentQueued {
+			if a.Status.Active() || a.Status == executor.AgentQueued {
 				allDone = false
 				break
 			}
@@ -916,6 +1445,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.testOutput = "Error: " + msg.Err.Error()
 		} else {
 			m.testOutput = msg.Output
+			if msg.Module != "" && msg.Commit != "" {
+				if m.lastPassingCommits == nil {
+					m.lastPassingCommits = make(map[string]string)
+				}
+				m.lastPassingCommits[msg.Module] = msg.Commit
+				if m.store != nil {
+					return m, saveLastPassingCommitCmd(m.store, msg.Module, msg.Commit)
+				}
+			}
+		}
+		return m, nil
+
+	case LastPassingCommitSavedMsg:
+		if msg.Err != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to record passing tests of %s: %v", msg.Module, msg.Err))
 		}
 		return m, nil
 
@@ -923,7 +1467,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Success {
 			m.statusMsg = fmt.Sprintf("Worker test OK: %s", strings.TrimSpace(msg.Output))
 		} else {
-			m.statusMsg = fmt.Sprintf("Worker test failed: %s", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Worker test failed: %s", msg.Error))
 		}
 		return m, nil
 
@@ -939,7 +1483,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.agents[i].Error = msg.Error
 					m.statusMsg = fmt.Sprintf("Agent test: %s", msg.Error)
 				}
-				// Store full output in the agent view
+				// Store full output in the agent view, superseding streamed lines
+				m.agents[i].outputCh = nil
 				if msg.Output != "" {
 					lines := strings.Split(msg.Output, "\n")
 					m.agents[i].Output = lines
@@ -966,7 +1511,116 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.statusMsg = fmt.Sprintf("Resumed agent %s", msg.TaskID)
 			m.batchRunning = true // Re-enable batch tracking
 		} else {
-			m.statusMsg = fmt.Sprintf("Failed to resume %s: %s", msg.TaskID, msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to resume %s: %s", msg.TaskID, msg.Error))
+		}
+		return m, nil
+
+	case PromptCopiedMsg:
+		if msg.Err != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to copy prompt: %v", msg.Err))
+		} else {
+			m.statusMsg = fmt.Sprintf("Copied prompt for %s to clipboard", msg.TaskID)
+		}
+		return m, nil
+
+	case PromptEditedMsg:
+		edited := strings.TrimSpace(msg.Prompt)
+		switch {
+		case msg.Err != nil:
+			m.logEvent(EventError, fmt.Sprintf("Failed to edit prompt: %v", msg.Err))
+		case edited == "":
+			m.statusMsg = "Empty prompt, restart cancelled"
+		case edited == strings.TrimSpace(msg.Original):
+			m.statusMsg = "Prompt unchanged, restart cancelled"
+		default:
+			m.statusMsg = fmt.Sprintf("Restarting agent %s with edited prompt...", msg.TaskID)
+			return m, restartAgentCmd(m.agentManager, m.worktreeManager, m.planWatcher, msg.TaskID, m.findTask(msg.TaskID), msg.Prompt)
+		}
+		return m, nil
+
+	case NoteEditedMsg:
+		switch {
+		case msg.Err != nil:
+			m.logEvent(EventError, fmt.Sprintf("Failed to write note: %v", msg.Err))
+		case strings.TrimSpace(msg.Note) == "":
+			m.statusMsg = "Empty note, nothing saved"
+		default:
+			return m, annotateRunCmd(m.store, msg.RunID, msg.TaskID, msg.Note)
+		}
+		return m, nil
+
+	case RunAnnotatedMsg:
+		if msg.Err != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to save note for %s: %v", msg.TaskID, msg.Err))
+			return m, nil
+		}
+		for _, hist := range m.agentHistory {
+			if hist.RunID == msg.RunID {
+				if hist.Notes != "" {
+					hist.Notes += "\n"
+				}
+				hist.Notes += msg.Note
+			}
+		}
+		m.statusMsg = fmt.Sprintf("Note saved for the run of %s", msg.TaskID)
+		return m, nil
+
+	case ShellExitedMsg:
+		if msg.Err != nil {
+			m.statusMsg = fmt.Sprintf("Shell in worktree of %s: %v", msg.TaskID, msg.Err)
+		} else {
+			m.statusMsg = fmt.Sprintf("Back from worktree of %s", msg.TaskID)
+		}
+		return m, nil
+
+	case EpicEditedMsg:
+		if msg.Err != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to open epic of %s: %v", msg.TaskID, msg.Err))
+		} else {
+			m.statusMsg = fmt.Sprintf("Closed %s (sync to load changes)", filepath.Base(msg.Path))
+		}
+		return m, nil
+
+	case AgentRestartMsg:
+		if msg.Success {
+			for i, a := range m.agents {
+				if a.TaskID == msg.TaskID {
+					m.agents[i].Status = executor.AgentRunning
+					m.agents[i].WorktreePath = msg.WorktreePath
+					m.agents[i].Error = ""
+					m.agents[i].Output = nil
+					m.activeCount++
+					break
+				}
+			}
+			m.agentOutputScroll = 0
+			m.statusMsg = fmt.Sprintf("Restarted agent %s from scratch", msg.TaskID)
+			m.batchRunning = true // Re-enable batch tracking
+		} else {
+			m.logEvent(EventError, fmt.Sprintf("Failed to restart %s: %s", msg.TaskID, msg.Error))
+		}
+		return m, nil
+
+	case AgentCancelMsg:
+		if msg.Cancelled {
+			for i, a := range m.agents {
+				if a.TaskID == msg.TaskID {
+					m.agents[i].Status = executor.AgentCancelled
+					break
+				}
+			}
+		}
+		m.updateAgentsFromManager()
+		switch {
+		case !msg.Cancelled && msg.Error != "":
+			m.logEvent(EventError, fmt.Sprintf("Failed to cancel %s: %s", msg.TaskID, msg.Error))
+		case !msg.Cancelled:
+			// The process exited between the key press and the cancellation
+			m.statusMsg = fmt.Sprintf("Agent %s already finished", msg.TaskID)
+		case msg.Error != "":
+			m.logEvent(EventWarning, fmt.Sprintf("Cancelled agent %s, but %s", msg.TaskID, msg.Error))
+		default:
+			m.statusMsg = fmt.Sprintf("Cancelled agent %s and removed its worktree", msg.TaskID)
 		}
 		return m, nil
 
@@ -977,12 +1631,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case PlanSyncMsg:
 		// Re-parse the changed plan files and update task data
 		updatedCount := 0
+		var deletedFiles, followUps []string
 		for _, filePath := range msg.ChangedFiles {
 			task, err := parser.ParseEpicFile(filePath)
 			if err != nil {
+				if _, statErr := os.Stat(filePath); os.IsNotExist(statErr) {
+					deletedFiles = append(deletedFiles, filepath.Base(filePath))
+				}
 				continue // Skip files that can't be parsed
 			}
 
+			// Tell a running agent about changed requirements
+			if m.syncPlanChanges && m.queuePlanChange(task, filePath, msg.WorktreePath) {
+				followUps = append(followUps, task.ID.String())
+			}
+
 			// Find and update the matching task in our list
 			for i, t := range m.allTasks {
 				if t.ID.String() == task.ID.String() {
@@ -999,7 +1662,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if updatedCount > 0 {
 			m.statusMsg = fmt.Sprintf("Updated %d task(s) from %s", updatedCount, msg.WorktreePath)
 			// Recompute module summaries from updated tasks
-			m.modules = computeModuleSummaries(m.allTasks)
+			m.modules = domain.SummarizeModules(m.allTasks)
+		}
+		// The task stays until the deletion reaches the main plans directory
+		// and a sync archives or deletes it
+		if len(deletedFiles) > 0 {
+			sort.Strings(deletedFiles)
+			m.statusMsg = fmt.Sprintf("Epic file deleted in %s: %s (its task is removed on the next sync)",
+				msg.WorktreePath, strings.Join(deletedFiles, ", "))
+		}
+		if len(followUps) > 0 {
+			m.statusMsg = fmt.Sprintf("Epic changed for running agent(s) %s: queued as follow-up for when their run ends",
+				strings.Join(followUps, ", "))
 		}
 
 		// Continue listening for more changes
@@ -1021,13 +1695,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
-			m.agents = append(m.agents, &AgentView{
+			// Replayed tasks are usually no longer queued
+			if task := m.findTask(info.TaskID); title == "" && task != nil {
+				title = task.Title
+			}
+			view := &AgentView{
 				TaskID:       info.TaskID,
 				Title:        title,
 				Status:       executor.AgentRunning,
 				Duration:     0,
 				WorktreePath: info.WorktreePath,
-			})
+			}
+			// A replayed task replaces its finished agent's view
+			replaced := false
+			for i, a := range m.agents {
+				if a.TaskID == info.TaskID && !a.Status.Active() {
+					m.agents[i] = view
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				m.agents = append(m.agents, view)
+			}
 			m.activeCount++
 		}
 		// Update task status in allTasks to in_progress for started tasks
@@ -1037,7 +1727,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		// Recompute module summaries to reflect in-progress tasks
-		m.modules = computeModuleSummaries(m.allTasks)
+		m.modules = domain.SummarizeModules(m.allTasks)
 
 		// Remove started tasks from queued
 		var remaining []*domain.Task
@@ -1055,18 +1745,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				errorDetails = errorDetails[:200] + "..."
 			}
 			if msg.Count > 0 {
-				m.statusMsg = fmt.Sprintf("Batch: %d started, %d failed: %s", msg.Count, len(msg.Errors), errorDetails)
+				m.logEvent(EventWarning, fmt.Sprintf("Batch: %d started, %d failed: %s", msg.Count, len(msg.Errors), errorDetails))
 			} else {
-				m.statusMsg = fmt.Sprintf("Batch failed: %s", errorDetails)
+				m.logEvent(EventError, fmt.Sprintf("Batch failed: %s", errorDetails))
 			}
 		} else {
 			m.statusMsg = fmt.Sprintf("Batch started: %d task(s)", msg.Count)
 		}
-		return m, nil
+
+		// Remember the batch so it can be replayed
+		if len(msg.Started) > 0 && m.store != nil {
+			taskIDs := make([]string, len(msg.Started))
+			for i, info := range msg.Started {
+				taskIDs[i] = info.TaskID
+			}
+			return m, saveLastBatchCmd(m.store, taskIDs)
+		}
+		return m, nil
+
+	case LastBatchSavedMsg:
+		if msg.Err != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to record batch for replay: %v", msg.Err))
+		}
+		return m, nil
 
 	case SyncCompleteMsg:
+		m.failedSync = nil
+		if msg.Err == nil {
+			m.syncChanges = msg.Result.Changes
+			m.showChanges = false
+		}
 		if msg.Err != nil {
-			m.statusMsg = fmt.Sprintf("Sync failed: %v", msg.Err)
+			m.failedSync = &syncOperation{}
+			m.logEvent(EventError, fmt.Sprintf("Sync failed: %v ([R] retry)", msg.Err))
 		} else if len(msg.Result.Conflicts) > 0 {
 			// Show conflict modal
 			m.syncModal.Visible = true
@@ -1077,11 +1788,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			// Success - reload tasks from database to update UI
 			if err := m.reloadTasksFromStore(); err != nil {
-				m.statusMsg = fmt.Sprintf("Sync succeeded but failed to reload: %v", err)
+				m.logEvent(EventWarning, fmt.Sprintf("Sync succeeded but failed to reload: %v", err))
 			} else {
 				// Show flash
 				total := msg.Result.MarkdownToDBCount + msg.Result.DBToMarkdownCount
-				if total > 0 {
+				if n := len(msg.Result.Changes); n > 0 {
+					m.syncFlash = fmt.Sprintf("Synced %d task(s), %d changed ✓ [S] show changes", total, n)
+				} else if total > 0 {
 					m.syncFlash = fmt.Sprintf("Synced %d task(s) ✓", total)
 				} else {
 					m.syncFlash = "Already in sync ✓"
@@ -1093,12 +1806,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case SyncResolveMsg:
+		m.failedSync = nil
+		m.syncChanges = append(m.syncChanges, msg.Changes...)
 		if msg.Err != nil {
-			m.statusMsg = fmt.Sprintf("Resolution failed: %v", msg.Err)
+			m.failedSync = &syncOperation{Resolutions: msg.Resolutions}
+			m.logEvent(EventError, fmt.Sprintf("Resolution failed: %v ([R] retry)", msg.Err))
 		} else {
 			// Reload tasks from database to update UI with resolved state
 			if err := m.reloadTasksFromStore(); err != nil {
-				m.statusMsg = fmt.Sprintf("Resolved but failed to reload: %v", err)
+				m.logEvent(EventWarning, fmt.Sprintf("Resolved but failed to reload: %v", err))
 			} else {
 				m.syncFlash = "Conflicts resolved ✓"
 				m.syncFlashExp = time.Now().Add(2 * time.Second)
@@ -1109,7 +1825,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case AgentHistoryMsg:
 		if msg.Error != nil {
-			m.statusMsg = fmt.Sprintf("Failed to load history: %v", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to load history: %v", msg.Error))
 			m.showAgentHistory = false
 		} else {
 			m.agentHistory = msg.History
@@ -1120,7 +1836,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case HistoryLogsMsg:
 		if msg.Error != nil {
-			m.statusMsg = fmt.Sprintf("Failed to load logs: %v", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to load logs: %v", msg.Error))
 		} else if msg.Index < len(m.agentHistory) {
 			m.agentHistory[msg.Index].Output = msg.Lines
 			m.statusMsg = fmt.Sprintf("Loaded %d log lines", len(msg.Lines))
@@ -1141,35 +1857,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.batchRunning = true
 			m.statusMsg = fmt.Sprintf("Started maintenance task: %s", msg.Title)
 		} else {
-			m.statusMsg = fmt.Sprintf("Maintenance failed: %s", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Maintenance failed: %s", msg.Error))
 		}
 		return m, nil
 
 	case GroupPrioritiesMsg:
 		if msg.Error != nil {
-			m.statusMsg = fmt.Sprintf("Failed to load priorities: %v", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to load priorities: %v", msg.Error))
 			m.showGroupPriorities = false
 		} else {
 			m.groupPriorityItems = msg.Items
-			// Sort items to match display order: by tier ascending, unassigned (-1) at end
-			sort.Slice(m.groupPriorityItems, func(i, j int) bool {
-				pi, pj := m.groupPriorityItems[i].Priority, m.groupPriorityItems[j].Priority
-				// Unassigned (-1) goes to the end
-				if pi < 0 && pj >= 0 {
-					return false
-				}
-				if pj < 0 && pi >= 0 {
-					return true
-				}
-				// Both unassigned or both assigned: sort by priority
-				return pi < pj
-			})
+			sortGroupPriorityItems(m.groupPriorityItems)
+		}
+		return m, nil
+
+	case TaskOverrideMsg:
+		if msg.Error != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to update task priority: %v", msg.Error))
+		} else if msg.Promoted {
+			if m.taskOverrides == nil {
+				m.taskOverrides = make(map[string]int)
+			}
+			m.taskOverrides[msg.TaskID] = 0
+			m.statusMsg = fmt.Sprintf("Promoted %s ahead of its peers", msg.TaskID)
+		} else {
+			delete(m.taskOverrides, msg.TaskID)
+			m.statusMsg = fmt.Sprintf("Removed priority override for %s", msg.TaskID)
+		}
+		return m, nil
+
+	case TaskReopenMsg:
+		if !msg.Reopened {
+			m.logEvent(EventError, fmt.Sprintf("Failed to reopen %s: %v", msg.TaskID, msg.Error))
+			return m, nil
+		}
+		if err := m.reloadTasksFromStore(); err != nil {
+			m.logEvent(EventWarning, fmt.Sprintf("Reopened %s, but reloading tasks failed: %v", msg.TaskID, err))
+		} else if msg.Error != nil {
+			m.logEvent(EventWarning, fmt.Sprintf("Warning: %v", msg.Error))
+		} else {
+			m.statusMsg = fmt.Sprintf("Reopened %s", msg.TaskID)
+		}
+		return m, nil
+
+	case TaskCreateMsg:
+		m.taskWizard.Creating = false
+		if msg.Path == "" {
+			m.taskWizard.Error = msg.Error.Error()
+			return m, nil
+		}
+		m.taskWizard = TaskWizardModal{}
+		if err := m.reloadTasksFromStore(); err != nil {
+			m.logEvent(EventWarning, fmt.Sprintf("Created %s, but reloading tasks failed: %v", msg.TaskID, err))
+		} else if msg.Error != nil {
+			m.logEvent(EventWarning, fmt.Sprintf("Created %s, but: %v", msg.TaskID, msg.Error))
+		} else {
+			path := msg.Path
+			if rel, err := filepath.Rel(m.projectRoot, path); err == nil && m.projectRoot != "" {
+				path = rel
+			}
+			m.statusMsg = fmt.Sprintf("Created %s in %s", msg.TaskID, path)
 		}
 		return m, nil
 
 	case SetGroupPriorityMsg:
 		if msg.Error != nil {
-			m.statusMsg = fmt.Sprintf("Failed to set priority: %v", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to set priority: %v", msg.Error))
 		} else {
 			// Update local state and re-sort to match display order
 			var selectedName string
@@ -1183,16 +1936,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			// Re-sort items to match display order
-			sort.Slice(m.groupPriorityItems, func(i, j int) bool {
-				pi, pj := m.groupPriorityItems[i].Priority, m.groupPriorityItems[j].Priority
-				if pi < 0 && pj >= 0 {
-					return false
+			sortGroupPriorityItems(m.groupPriorityItems)
+			// Update selectedPriorityRow to follow the item
+			for i, item := range m.groupPriorityItems {
+				if item.Name == selectedName {
+					m.selectedPriorityRow = i
+					break
 				}
-				if pj < 0 && pi >= 0 {
-					return true
+			}
+			m.statusMsg = fmt.Sprintf("Set %s to tier %d", msg.Group, msg.Priority)
+		}
+		return m, nil
+
+	case SetGroupWeightMsg:
+		if msg.Error != nil {
+			m.logEvent(EventError, fmt.Sprintf("Failed to set weight: %v", msg.Error))
+		} else {
+			// Update local state and re-sort to match display order
+			var selectedName string
+			if m.selectedPriorityRow < len(m.groupPriorityItems) {
+				selectedName = m.groupPriorityItems[m.selectedPriorityRow].Name
+			}
+			for i, item := range m.groupPriorityItems {
+				if item.Name == msg.Group {
+					m.groupPriorityItems[i].Weight = msg.Weight
+					break
 				}
-				return pi < pj
-			})
+			}
+			sortGroupPriorityItems(m.groupPriorityItems)
 			// Update selectedPriorityRow to follow the item
 			for i, item := range m.groupPriorityItems {
 				if item.Name == selectedName {
@@ -1200,13 +1971,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
-			m.statusMsg = fmt.Sprintf("Set %s to tier %d", msg.Group, msg.Priority)
+			if m.groupWeights == nil {
+				m.groupWeights = make(map[string]int)
+			}
+			if msg.Weight == 0 {
+				delete(m.groupWeights, msg.Group)
+			} else {
+				m.groupWeights[msg.Group] = msg.Weight
+			}
+			m.statusMsg = fmt.Sprintf("Set %s to weight %d", msg.Group, msg.Weight)
 		}
 		return m, nil
 
 	case RemoveGroupPriorityMsg:
 		if msg.Error != nil {
-			m.statusMsg = fmt.Sprintf("Failed to unassign: %v", msg.Error)
+			m.logEvent(EventError, fmt.Sprintf("Failed to unassign: %v", msg.Error))
 		} else {
 			// Update local state and re-sort to match display order
 			var selectedName string
@@ -1220,16 +1999,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			// Re-sort items to match display order
-			sort.Slice(m.groupPriorityItems, func(i, j int) bool {
-				pi, pj := m.groupPriorityItems[i].Priority, m.groupPriorityItems[j].Priority
-				if pi < 0 && pj >= 0 {
-					return false
-				}
-				if pj < 0 && pi >= 0 {
-					return true
-				}
-				return pi < pj
-			})
+			sortGroupPriorityItems(m.groupPriorityItems)
 			// Update selectedPriorityRow to follow the item
 			for i, item := range m.groupPriorityItems {
 				if item.Name == selectedName {
@@ -1237,7 +2007,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
-			m.statusMsg = fmt.Sprintf("Unassigned %s (defaults to tier 0)", msg.Group)
+			m.statusMsg = fmt.Sprintf("Unassigned %s (defaults to tier %d)", msg.Group, m.defaultGroupTier)
 		}
 		return m, nil
 
@@ -1274,7 +2044,7 @@ func (m *Model) SetAgents(agents []*AgentView) {
 	m.agents = agents
 	m.activeCount = 0
 	for _, a := range agents {
-		if a.Status == executor.AgentRunning {
+		if a.Status.Active() {
 			m.activeCount++
 		}
 	}
@@ -1285,11 +2055,72 @@ func (m *Model) SetTasks(tasks []*domain.Task) {
 	m.allTasks = tasks
 }
 
+// findTask returns the task with the given ID, or nil if unknown
+func (m *Model) findTask(taskID string) *domain.Task {
+	for _, t := range m.allTasks {
+		if t.ID.String() == taskID {
+			return t
+		}
+	}
+	return nil
+}
+
 // SetQueued updates the queued tasks list
 func (m *Model) SetQueued(tasks []*domain.Task) {
 	m.queued = tasks
 }
 
+// logEvent records a warning or error in the events log, dropping the oldest
+// event once maxLogEvents are kept, and shows it in the status line
+func (m *Model) logEvent(level EventLevel, msg string) {
+	m.events = append(m.events, LogEvent{Time: time.Now(), Level: level, Message: msg})
+	if len(m.events) > maxLogEvents {
+		m.events = append(m.events[:0:0], m.events[len(m.events)-maxLogEvents:]...)
+	}
+	m.eventsLogged++
+	m.statusMsg = msg
+}
+
+// completedTodayRefresh is how often the "Completed today" count is re-read
+const completedTodayRefresh = 30 * time.Second
+
+// The dashboard's throughput sparkline covers the last 12 hours in half-hour
+// intervals, the last one being the current interval
+const (
+	throughputIntervals = 24
+	throughputInterval  = 30 * time.Minute
+)
+
+// refreshCompletedTodayCmd re-reads, off the update loop, how many tasks were
+// completed since the start of the "Completed today" window at now and per
+// interval of the throughput sparkline, with one query for both
+func (m *Model) refreshCompletedTodayCmd(now time.Time) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if m.completedSince != nil {
+		since = m.completedSince(now)
+	}
+	start := now.Truncate(throughputInterval).Add(-(throughputIntervals - 1) * throughputInterval)
+	from := since
+	if start.Before(from) {
+		from = start
+	}
+	m.completedTodayAt = now
+	store := m.store
+	return func() tea.Msg {
+		runs, err := store.ListCompletedSince(from)
+		if err != nil {
+			return CompletedTodayMsg{Err: err}
+		}
+		return CompletedTodayMsg{
+			Count:      runs.TasksSince(since),
+			Throughput: runs.TasksPerInterval(start, throughputInterval, throughputIntervals),
+		}
+	}
+}
+
 // reloadTasksFromStore reloads all tasks from the database and updates derived state
 func (m *Model) reloadTasksFromStore() error {
 	if m.store == nil {
@@ -1322,29 +2153,65 @@ func (m *Model) reloadTasksFromStore() error {
 	m.queued = queued
 
 	// Recompute module summaries
-	m.modules = computeModuleSummaries(tasks)
+	m.modules = domain.SummarizeModules(tasks)
 
 	return nil
 }
 
-// updateTestAgentOutput copies streaming output from the shared buffer to the test agent
+// testAgentOutputBuffer is how many lines a test agent may stream ahead of
+// the tick that appends them to its view before it blocks
+const testAgentOutputBuffer = 1024
+
+// updateTestAgentOutput appends the lines test agents streamed since the
+// last tick. Test agents only send lines over their view's channel, so the
+// view itself is only ever touched from Update.
 func (m *Model) updateTestAgentOutput() {
-	testAgentMutex.Lock()
-	taskID := testAgentTaskID
-	output := make([]string, len(testAgentOutput))
-	copy(output, testAgentOutput)
-	testAgentMutex.Unlock()
+	for _, av := range m.agents {
+		if av.outputCh == nil || av.outputAgent != nil {
+			continue // Not a test agent, or it has finished streaming
+		}
+		if !drainAgentOutput(av) {
+			av.outputCh = nil
+		}
+	}
+}
+
+// drainAgentOutput appends the lines waiting on the view's output channel
+// without blocking. It reports false once the channel is closed.
+func drainAgentOutput(av *AgentView) bool {
+	for {
+		select {
+		case line, ok := <-av.outputCh:
+			if !ok {
+				return false
+			}
+			av.Output = append(av.Output, line)
+		default:
+			return true
+		}
+	}
+}
 
-	if taskID == "" || len(output) == 0 {
+// maxAgentOutputLines is how many output lines an agent view keeps
+const maxAgentOutputLines = 100
+
+// syncAgentOutput appends the output lines delivered since the last tick to
+// the view, (re)subscribing to the agent's output when the view has no live
+// subscription, e.g. on first sight or after the agent was restarted
+func syncAgentOutput(av *AgentView, agent *executor.Agent) {
+	if av.outputCh == nil || av.outputAgent != agent {
+		av.Output, av.outputCh = agent.SubscribeOutput(maxAgentOutputLines)
+		av.outputAgent = agent
 		return
 	}
 
-	// Find the test agent and update its output (update while running)
-	for i, a := range m.agents {
-		if a.TaskID == taskID {
-			m.agents[i].Output = output
-			break
-		}
+	if !drainAgentOutput(av) {
+		// Output was replaced or the view fell behind; resync
+		av.Output, av.outputCh = agent.SubscribeOutput(maxAgentOutputLines)
+		return
+	}
+	if len(av.Output) > maxAgentOutputLines {
+		av.Output = av.Output[len(av.Output)-maxAgentOutputLines:]
 	}
 }
 
@@ -1367,37 +2234,47 @@ func (m *Model) updateAgentsFromManager() {
 			continue
 		}
 
-		// Track status change to detect completions
+		// Track status change to detect completions. Status and error are
+		// written by the agent's goroutines, so read them through the getters.
 		prevStatus := av.Status
-		av.Status = agent.Status
+		status := agent.GetStatus()
+		av.Status = status
 		av.Duration = agent.Duration()
+		av.Idle = agent.IdleFor(time.Now())
+		av.RetryAttempt, av.RetryAt = agent.PendingRetry()
 		av.WorktreePath = agent.WorktreePath
 
 		// Capture error if any
-		if agent.Error != nil {
-			av.Error = agent.Error.Error()
+		if err := agent.GetError(); err != nil {
+			av.Error = err.Error()
 		}
 
 		// Capture last N lines of output (keep more for better context)
-		output := agent.GetOutput()
-		maxLines := 100
-		if len(output) > maxLines {
-			av.Output = output[len(output)-maxLines:]
-		} else {
-			av.Output = output
-		}
+		syncAgentOutput(av, agent)
 
 		// Capture prompt
 		av.Prompt = agent.Prompt
 
+		// Sample CPU/memory of running agents if enabled
+		av.Resources = nil
+		if m.sampleResources && status.Active() {
+			if usage, err := agent.ResourceUsage(); err == nil {
+				av.Resources = &usage
+			}
+		}
+
 		// Capture token usage
 		tokensIn, tokensOut, cost := agent.GetUsage()
 		av.TokensInput = tokensIn
 		av.TokensOutput = tokensOut
 		av.CostUSD = cost
 
+		if status != prevStatus && (status == executor.AgentCompleted || status == executor.AgentFailed) {
+			m.recordAgentOutcome(status == executor.AgentCompleted)
+		}
+
 		// Mark task as completed for dependency tracking when status changes to completed
-		if agent.Status == executor.AgentCompleted && prevStatus != executor.AgentCompleted {
+		if status == executor.AgentCompleted && prevStatus != executor.AgentCompleted {
 			if m.completedTasks == nil {
 				m.completedTasks = make(map[string]bool)
 			}
@@ -1413,10 +2290,10 @@ func (m *Model) updateAgentsFromManager() {
 			toRemove = append(toRemove, i)
 		}
 
-		if agent.Status == executor.AgentRunning {
+		if status.Active() {
 			m.activeCount++
 			allDone = false
-		} else if agent.Status == executor.AgentQueued {
+		} else if status == executor.AgentQueued {
 			allDone = false
 		}
 	}
@@ -1426,7 +2303,7 @@ func (m *Model) updateAgentsFromManager() {
 		agent := m.agents[idx]
 		if m.worktreeManager != nil && agent.WorktreePath != "" {
 			if err := m.worktreeManager.Remove(agent.WorktreePath); err != nil {
-				m.statusMsg = fmt.Sprintf("Warning: worktree cleanup failed: %v", err)
+				m.logEvent(EventWarning, fmt.Sprintf("Warning: worktree cleanup failed: %v", err))
 			}
 		}
 	}
@@ -1445,7 +2322,7 @@ func (m *Model) updateAgentsFromManager() {
 	}
 	// Recompute module summaries if any agents completed
 	if len(toRemove) > 0 {
-		m.modules = computeModuleSummaries(m.allTasks)
+		m.modules = domain.SummarizeModules(m.allTasks)
 	}
 
 	// Check if batch is complete
@@ -1469,24 +2346,104 @@ func (m Model) ConfigChanged() bool {
 	return m.configChanged
 }
 
+// moduleTestRunner is the subset of mcp.TestRunner used to run module tests
+type moduleTestRunner interface {
+	RunTests(filter string, syncFirst, includeIgnored bool) (*mcp.TestRunResult, error)
+	GetTestResults(runID string, blocking bool, tail int) (*mcp.TestResults, error)
+}
+
+// defaultModuleTestTimeout bounds how long runModuleTests waits for results
+const defaultModuleTestTimeout = 2 * time.Minute
+
+// moduleTestPollInterval is how often runModuleTests polls for results
+var moduleTestPollInterval = 1 * time.Second
+
 // runModuleTests executes tests for a specific module via MCP test runner
-func runModuleTests(projectRoot, moduleName string) tea.Cmd {
+func runModuleTests(projectRoot, moduleName string, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		return runModuleTestFilters(projectRoot, moduleName, nil, timeout)
+	}
+}
+
+// runChangedModuleTests runs only the tests of a module's code changed since
+// the commit its tests last passed at, or all of them without such a commit
+func runChangedModuleTests(projectRoot, moduleName, since string, timeout time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		// Try to connect to MCP test runner
-		runner, err := mcp.NewTestRunner(projectRoot)
+		if since == "" {
+			msg := runModuleTestFilters(projectRoot, moduleName, nil, timeout)
+			msg.Output = fmt.Sprintf("No passing run of %s recorded yet, running all its tests\n", moduleName) + msg.Output
+			return msg
+		}
+
+		files, err := testselect.ChangedFiles(projectRoot, since)
 		if err != nil {
+			return TestCompleteMsg{Module: moduleName, Err: fmt.Errorf("failed to find changed files: %w", err)}
+		}
+		filters := testselect.AffectedPaths(moduleName, files)
+		if len(filters) == 0 {
 			return TestCompleteMsg{
-				Output: "",
-				Err:    fmt.Errorf("failed to connect to test runner: %w", err),
+				Module: moduleName,
+				Output: fmt.Sprintf("No changes in %s since %s, no tests to run\n", moduleName, shortCommit(since)),
 			}
 		}
-		defer runner.Close()
 
-		// Sync and run tests with the module filter
-		output := fmt.Sprintf("Starting tests for module: %s\n", moduleName)
+		msg := runModuleTestFilters(projectRoot, moduleName, filters, timeout)
+		msg.Output = fmt.Sprintf("Changed since %s: %s\n", shortCommit(since), strings.Join(filters, ", ")) + msg.Output
+		return msg
+	}
+}
+
+// runModuleTestFilters connects to the MCP test runner and runs the module's
+// tests matching filters, noting the commit they ran against
+func runModuleTestFilters(projectRoot, moduleName string, filters []string, timeout time.Duration) TestCompleteMsg {
+	// Without a commit a passing run is not recorded for changed-only runs
+	commit, _ := testselect.HeadCommit(projectRoot)
+
+	// Try to connect to MCP test runner
+	runner, err := mcp.NewTestRunner(projectRoot)
+	if err != nil {
+		return TestCompleteMsg{
+			Module: moduleName,
+			Output: "",
+			Err:    fmt.Errorf("failed to connect to test runner: %w", err),
+		}
+	}
+	defer runner.Close()
+
+	msg := executeModuleTests(runner, moduleName, filters, timeout)
+	msg.Module, msg.Commit = moduleName, commit
+	return msg
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
+
+// executeModuleTests starts a test run for each filter (the module name if
+// there are none), one after the other, polling each until it finishes or
+// the timeout for all runs elapses
+func executeModuleTests(runner moduleTestRunner, moduleName string, filters []string, timeout time.Duration) TestCompleteMsg {
+	if timeout <= 0 {
+		timeout = defaultModuleTestTimeout
+	}
+	if len(filters) == 0 {
+		filters = []string{moduleName}
+	}
 
-		// Start the test run
-		runResult, err := runner.RunTests(moduleName, true, true)
+	// Sync and run tests with the module filter
+	output := fmt.Sprintf("Starting tests for module: %s\n", moduleName)
+
+	deadline := time.Now().Add(timeout)
+	var summary *mcp.TestSummary
+	var outputs []string
+	failed := false
+	for i, filter := range filters {
+		// Start the test run, syncing test binaries for the first one only
+		runResult, err := runner.RunTests(filter, i == 0, true)
 		if err != nil {
 			return TestCompleteMsg{
 				Output: output,
@@ -1494,50 +2451,83 @@ func runModuleTests(projectRoot, moduleName string) tea.Cmd {
 			}
 		}
 
-		output += fmt.Sprintf("Test run started: %s\n", runResult.RunID)
-
-		// Poll for results (blocking)
-		var results *mcp.TestResults
-		for i := 0; i < 120; i++ { // Timeout after 2 minutes
-			results, err = runner.GetTestResults(runResult.RunID, false, 20)
-			if err != nil {
-				return TestCompleteMsg{
-					Output: output,
-					Err:    fmt.Errorf("failed to get results: %w", err),
-				}
-			}
-
-			if results.Status != "running" {
-				break
-			}
+		if filter == moduleName {
+			output += fmt.Sprintf("Test run started: %s\n", runResult.RunID)
+		} else {
+			output += fmt.Sprintf("Test run started: %s (%s)\n", runResult.RunID, filter)
+		}
 
-			time.Sleep(1 * time.Second)
+		results, err := pollTestResults(runner, runResult.RunID, deadline, timeout)
+		if err != nil {
+			return TestCompleteMsg{Output: output, Err: err}
 		}
 
-		// Format results
 		if results.Summary != nil {
-			output += fmt.Sprintf("\nResults:\n")
-			output += fmt.Sprintf("  Total:   %d\n", results.Summary.Total)
-			output += fmt.Sprintf("  Passed:  %d\n", results.Summary.Passed)
-			output += fmt.Sprintf("  Failed:  %d\n", results.Summary.Failed)
-			output += fmt.Sprintf("  Skipped: %d\n", results.Summary.Skipped)
+			if summary == nil {
+				summary = &mcp.TestSummary{Success: true}
+			}
+			summary.Total += results.Summary.Total
+			summary.Passed += results.Summary.Passed
+			summary.Failed += results.Summary.Failed
+			summary.Skipped += results.Summary.Skipped
+			summary.Success = summary.Success && results.Summary.Success
 		}
-
 		if results.Output != "" {
-			output += fmt.Sprintf("\nOutput:\n%s", results.Output)
+			outputs = append(outputs, results.Output)
 		}
-
 		if results.Status == "failed" || (results.Summary != nil && !results.Summary.Success) {
-			return TestCompleteMsg{
-				Output: output,
-				Err:    fmt.Errorf("tests failed"),
-			}
+			failed = true
 		}
+	}
 
+	// Format results
+	if summary != nil {
+		output += fmt.Sprintf("\nResults:\n")
+		output += fmt.Sprintf("  Total:   %d\n", summary.Total)
+		output += fmt.Sprintf("  Passed:  %d\n", summary.Passed)
+		output += fmt.Sprintf("  Failed:  %d\n", summary.Failed)
+		output += fmt.Sprintf("  Skipped: %d\n", summary.Skipped)
+	}
+
+	if len(outputs) > 0 {
+		output += fmt.Sprintf("\nOutput:\n%s", strings.Join(outputs, "\n"))
+	}
+
+	if failed {
 		return TestCompleteMsg{
 			Output: output,
-			Err:    nil,
+			Err:    fmt.Errorf("tests failed"),
+		}
+	}
+
+	return TestCompleteMsg{
+		Output: output,
+		Err:    nil,
+	}
+}
+
+// pollTestResults polls a test run until it is no longer running, failing
+// once deadline has passed
+func pollTestResults(runner moduleTestRunner, runID string, deadline time.Time, timeout time.Duration) (*mcp.TestResults, error) {
+	for {
+		results, err := runner.GetTestResults(runID, false, 20)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get results: %w", err)
+		}
+
+		if results.Status != "running" {
+			return results, nil
+		}
+
+		if time.Now().After(deadline) {
+			limit := timeout.String()
+			if timeout%time.Minute == 0 {
+				limit = formatDuration(timeout)
+			}
+			return nil, fmt.Errorf("test run timed out after %s", limit)
 		}
+
+		time.Sleep(moduleTestPollInterval)
 	}
 }
 
@@ -1551,7 +2541,7 @@ func startBatchCmd(
 ) tea.Cmd {
 	return func() tea.Msg {
 		var started []AgentStartInfo
-		var errors []string
+		var startErrors []string
 
 		for _, task := range tasks {
 			// Create worktree for this task
@@ -1560,7 +2550,7 @@ func startBatchCmd(
 			if wtMgr != nil {
 				wtPath, err = wtMgr.Create(task.ID)
 				if err != nil {
-					errors = append(errors, fmt.Sprintf("%s: worktree: %v", task.ID.String(), err))
+					startErrors = append(startErrors, fmt.Sprintf("%s: worktree: %v", task.ID.String(), err))
 					continue
 				}
 				// Add worktree to plan watcher
@@ -1573,7 +2563,7 @@ func startBatchCmd(
 			}
 
 			// Build prompt for the agent
-			prompt := executor.BuildPrompt(task, task.Description, "", nil)
+			prompt := executor.BuildPrompt(task, task.Description, "", nil, agentMgr.GetExecutorType())
 
 			// Create the agent with status callback for persistence
 			agent := &executor.Agent{
@@ -1584,8 +2574,17 @@ func startBatchCmd(
 				Prompt:        prompt,
 				BuildPoolURL:  agentMgr.GetBuildPoolURL(),
 				ExecutorType:  agentMgr.GetExecutorType(),
+				ClaudeModel:   agentMgr.GetClaudeModel(),
 				OpenCodeModel: agentMgr.GetOpenCodeModel(),
+				GeminiModel:   agentMgr.GetGeminiModel(),
+				BinaryPath:    agentMgr.GetBinaryPath(agentMgr.GetExecutorType()),
+				Sandbox:       agentMgr.GetSandbox(),
+				MaxDuration:   agentMgr.GetAgentTimeout(agentMgr.GetExecutorType()),
 			}
+			agent.MaxOutputLines = agentMgr.GetMaxOutputLines()
+			agent.StopGrace = agentMgr.GetStopGrace()
+			agent.PlansDir = agentMgr.GetPlansDir()
+			agent.EpicFilePattern = agentMgr.GetEpicFilePattern()
 
 			// Set up status change callback if manager has persistence
 			if agentMgr != nil {
@@ -1593,20 +2592,17 @@ func startBatchCmd(
 			}
 
 			if agentMgr != nil {
-				// Start the agent if we can
-				if agentMgr.CanStart() {
-					if err := agent.Start(context.Background()); err != nil {
-						errors = append(errors, fmt.Sprintf("%s: start: %v", task.ID.String(), err))
-						// Clean up worktree on failure
-						if wtMgr != nil {
-							wtMgr.Remove(wtPath)
-						}
-						continue
+				// Start the agent if we can, else leave it queued
+				if err := agentMgr.StartAgent(context.Background(), agent); errors.Is(err, executor.ErrNoSlot) {
+					agentMgr.Add(agent)
+				} else if err != nil {
+					startErrors = append(startErrors, fmt.Sprintf("%s: start: %v", task.ID.String(), err))
+					// Clean up worktree on failure
+					if wtMgr != nil {
+						wtMgr.Remove(wtPath)
 					}
+					continue
 				}
-
-				// Add to manager after starting (so PID and LogPath are set)
-				agentMgr.Add(agent)
 			}
 
 			started = append(started, AgentStartInfo{
@@ -1618,9 +2614,137 @@ func startBatchCmd(
 		return BatchStartMsg{
 			Count:   len(started),
 			Started: started,
-			Errors:  errors,
+			Errors:  startErrors,
+		}
+	}
+}
+
+// saveLastBatchCmd records the task IDs of a started batch for replay
+func saveLastBatchCmd(store *taskstore.Store, taskIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		return LastBatchSavedMsg{Err: store.SaveLastBatch(taskIDs)}
+	}
+}
+
+// saveLastPassingCommitCmd records the commit a module's tests passed at, so
+// changed-only runs diff against it after a restart too
+func saveLastPassingCommitCmd(store *taskstore.Store, module, commit string) tea.Cmd {
+	return func() tea.Msg {
+		return LastPassingCommitSavedMsg{Module: module, Err: store.SetLastPassingCommit(module, commit)}
+	}
+}
+
+// lastBatchTasks returns the tasks of the recorded last batch, skipping tasks
+// that no longer exist or already have a running agent
+func (m *Model) lastBatchTasks() ([]*domain.Task, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("no task store")
+	}
+	taskIDs, err := m.store.GetLastBatch()
+	if err != nil {
+		return nil, err
+	}
+
+	running := make(map[string]bool)
+	for _, a := range m.agents {
+		if a.Status.Active() {
+			running[a.TaskID] = true
+		}
+	}
+
+	var tasks []*domain.Task
+	for _, id := range taskIDs {
+		if running[id] {
+			continue
+		}
+		if task := m.findTask(id); task != nil {
+			tasks = append(tasks, task)
 		}
 	}
+	return tasks, nil
+}
+
+// replayLastBatch restarts the tasks of the last batch from scratch,
+// regardless of whether the scheduler considers them ready
+func (m *Model) replayLastBatch() tea.Cmd {
+	tasks, err := m.lastBatchTasks()
+	if err != nil {
+		m.logEvent(EventError, fmt.Sprintf("Replay failed: %v", err))
+		return nil
+	}
+	if len(tasks) == 0 {
+		m.statusMsg = "No previous batch to replay"
+		return nil
+	}
+
+	m.batchRunning = true
+	m.batchPaused = false
+	m.statusMsg = fmt.Sprintf("Replaying last batch: %d task(s)...", len(tasks))
+	return startBatchCmd(
+		m.projectRoot,
+		tasks,
+		m.worktreeManager,
+		m.agentManager,
+		m.planWatcher,
+	)
+}
+
+// toggleMarked marks or unmarks a task for a targeted start
+func (m *Model) toggleMarked(taskID string) {
+	if m.markedTasks[taskID] {
+		delete(m.markedTasks, taskID)
+		return
+	}
+	if m.markedTasks == nil {
+		m.markedTasks = make(map[string]bool)
+	}
+	m.markedTasks[taskID] = true
+}
+
+// startMarkedTasks starts exactly the marked tasks, regardless of scheduling
+// order. Nothing starts while a marked task still has unmet dependencies.
+func (m *Model) startMarkedTasks() tea.Cmd {
+	if len(m.markedTasks) == 0 {
+		m.statusMsg = "No tasks marked (press space to mark)"
+		return nil
+	}
+
+	taskIDs := make([]string, 0, len(m.markedTasks))
+	for id := range m.markedTasks {
+		taskIDs = append(taskIDs, id)
+	}
+	sort.Strings(taskIDs)
+
+	inProgress := make(map[string]bool)
+	for _, a := range m.agents {
+		if a.Status.Active() {
+			inProgress[a.TaskID] = true
+		}
+	}
+
+	sel := m.newScheduler().Only(taskIDs, inProgress)
+	if len(sel.Blocked) > 0 {
+		m.statusMsg = fmt.Sprintf("Not starting, unmet dependencies: %s", strings.Join(sel.BlockedSummary(), "; "))
+		return nil
+	}
+
+	// Tasks that started meanwhile or disappeared are no longer marked
+	m.markedTasks = nil
+	if len(sel.Ready) == 0 {
+		m.statusMsg = "No marked task can start (already started or complete)"
+		return nil
+	}
+
+	m.batchRunning = true
+	m.batchPaused = false
+	m.statusMsg = fmt.Sprintf("Starting %d marked task(s)...", len(sel.Ready))
+	return startBatchCmd(
+		m.projectRoot,
+		sel.Ready,
+		m.worktreeManager,
+		m.agentManager,
+		m.planWatcher,
+	)
 }
 
 // min returns the smaller of two integers
@@ -1651,8 +2775,8 @@ func resumeAgentCmd(agentMgr *executor.AgentManager, taskID string) tea.Cmd {
 			}
 		}
 
-		// Resume the agent
-		if err := agent.Resume(context.Background()); err != nil {
+		// Resume the agent if a slot is free
+		if err := agentMgr.ResumeAgent(context.Background(), agent, ""); err != nil {
 			return AgentResumeMsg{
 				TaskID:  taskID,
 				Success: false,
@@ -1667,47 +2791,385 @@ func resumeAgentCmd(agentMgr *executor.AgentManager, taskID string) tea.Cmd {
 	}
 }
 
-// fetchWorkersCmd fetches worker status from the build pool coordinator
-func fetchWorkersCmd(buildPoolURL string) tea.Cmd {
+// restartAgentCmd creates a command to restart an agent from scratch.
+// The task is used to rebuild the prompt for agents recovered without one.
+func restartAgentCmd(
+	agentMgr *executor.AgentManager,
+	wtMgr *executor.WorktreeManager,
+	planWatcher *observer.PlanWatcher,
+	taskID string,
+	task *domain.Task,
+	prompt string, // Replacement prompt (empty = reuse the previous one)
+) tea.Cmd {
+	return func() tea.Msg {
+		if agentMgr == nil {
+			return AgentRestartMsg{
+				TaskID:  taskID,
+				Success: false,
+				Error:   "no agent manager",
+			}
+		}
+
+		if old := agentMgr.Get(taskID); prompt == "" && old != nil && old.Prompt == "" && task != nil {
+			executorType := old.ExecutorType
+			if executorType == "" {
+				executorType = agentMgr.GetExecutorType()
+			}
+			prompt = executor.BuildPrompt(task, task.Description, "", nil, executorType)
+		}
+
+		agent, err := agentMgr.Restart(context.Background(), taskID, wtMgr, prompt)
+		if err != nil {
+			return AgentRestartMsg{
+				TaskID:  taskID,
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+
+		if planWatcher != nil && wtMgr != nil {
+			planWatcher.AddWorktree(agent.WorktreePath)
+		}
+
+		return AgentRestartMsg{
+			TaskID:       taskID,
+			WorktreePath: agent.WorktreePath,
+			Success:      true,
+		}
+	}
+}
+
+// cancelAgentCmd creates a command to kill a running agent and remove its worktree
+func cancelAgentCmd(agentMgr *executor.AgentManager, wtMgr *executor.WorktreeManager, taskID string) tea.Cmd {
 	return func() tea.Msg {
-		client := &http.Client{Timeout: 2 * time.Second}
-		resp, err := client.Get(buildPoolURL + "/status")
+		if agentMgr == nil {
+			return AgentCancelMsg{TaskID: taskID, Error: "no agent manager"}
+		}
+
+		cancelled, err := agentMgr.Cancel(context.Background(), taskID, wtMgr)
 		if err != nil {
-			// Coordinator not reachable
-			return WorkersUpdateMsg{Workers: nil, Status: "unreachable"}
+			return AgentCancelMsg{TaskID: taskID, Cancelled: cancelled, Error: err.Error()}
 		}
-		defer resp.Body.Close()
+		return AgentCancelMsg{TaskID: taskID, Cancelled: cancelled}
+	}
+}
 
-		if resp.StatusCode != http.StatusOK {
-			return WorkersUpdateMsg{Workers: nil, Status: "unreachable"}
+// agentPrompt returns the prompt of an agent, rebuilding it from the task
+// for agents recovered without one
+func (m *Model) agentPrompt(av *AgentView) string {
+	if av.Prompt != "" {
+		return av.Prompt
+	}
+	if task := m.findTask(av.TaskID); task != nil {
+		var executorType executor.ExecutorType
+		if m.agentManager != nil {
+			executorType = m.agentManager.GetExecutorType()
+		}
+		return executor.BuildPrompt(task, task.Description, "", nil, executorType)
+	}
+	return ""
+}
+
+// clipboardCommands are tried in order to copy text to the system clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard copies text with the first available clipboard tool (replaceable in tests)
+var copyToClipboard = func(text string) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
 		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found (install xclip, xsel or wl-copy)")
+}
 
-		var status struct {
-			Workers []struct {
-				ID             string `json:"id"`
-				MaxJobs        int    `json:"max_jobs"`
-				ActiveJobs     int    `json:"active_jobs"`
-				ConnectedSince string `json:"connected_since"`
-			} `json:"workers"`
+// copyPromptCmd copies an agent prompt to the clipboard
+func copyPromptCmd(taskID, prompt string) tea.Cmd {
+	return func() tea.Msg {
+		return PromptCopiedMsg{TaskID: taskID, Err: copyToClipboard(prompt)}
+	}
+}
+
+// runEditor hands the terminal to the editor or shell process (replaceable in tests)
+var runEditor = func(c *exec.Cmd, done tea.ExecCallback) tea.Cmd {
+	return tea.ExecProcess(c, done)
+}
+
+// editorCommand builds the command for $VISUAL or $EDITOR (default vi) on path
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		parts = []string{"vi"}
+	}
+	return exec.Command(parts[0], append(parts[1:], path)...)
+}
+
+// queuePlanChange queues the updated epic as a follow-up for the running
+// agent of task if its body changed, and reports whether it did. Changes in
+// the agent's own worktree are its own edits and are skipped; the first
+// change is compared with the agent's copy of the epic.
+func (m *Model) queuePlanChange(task *domain.Task, filePath, worktreePath string) bool {
+	if m.agentManager == nil {
+		return false
+	}
+	agent := m.agentManager.Get(task.ID.String())
+	if agent == nil || !agent.GetStatus().Active() || agent.WorktreePath == "" || agent.WorktreePath == worktreePath {
+		return false
+	}
+
+	body, err := epicBody(filePath)
+	if err != nil {
+		return false
+	}
+	baseline, seen := m.planBodies[task.ID.String()]
+	if !seen {
+		if rel, err := filepath.Rel(worktreePath, filePath); err == nil {
+			baseline, _ = epicBody(filepath.Join(agent.WorktreePath, rel))
 		}
+	}
+	if body == baseline {
+		return false // e.g. only the status in the frontmatter changed
+	}
+
+	m.planBodies[task.ID.String()] = body
+	agent.QueueFollowUp(executor.PlanChangedFollowUp(task.ID, filePath, body))
+	return true
+}
+
+// epicBody returns the content of an epic file after its frontmatter
+func epicBody(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	_, body, err := parser.ParseFrontmatter(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// shellCommand builds the command for $SHELL (default /bin/sh) in dir
+func shellCommand(dir string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	c := exec.Command(shell)
+	c.Dir = dir
+	return c
+}
 
-		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-			return WorkersUpdateMsg{Workers: nil, Status: "unreachable"}
+// openShellCmd suspends the TUI for a shell in an agent's worktree. A shell
+// exiting with the status of the last command the user ran is not an error.
+func openShellCmd(taskID, dir string) tea.Cmd {
+	return runEditor(shellCommand(dir), func(err error) tea.Msg {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = nil
 		}
+		return ShellExitedMsg{TaskID: taskID, Err: err}
+	})
+}
 
-		workers := make([]*WorkerView, 0, len(status.Workers))
-		for _, w := range status.Workers {
-			connectedAt, _ := time.Parse(time.RFC3339, w.ConnectedSince)
-			workers = append(workers, &WorkerView{
-				ID:          w.ID,
-				MaxJobs:     w.MaxJobs,
-				ActiveJobs:  w.ActiveJobs,
-				ConnectedAt: connectedAt,
-			})
+// epicFilePath returns the path of a task's epic file, resolving relative
+// paths against the project root
+func (m *Model) epicFilePath(task *domain.Task) (string, error) {
+	if task.FilePath == "" {
+		return "", fmt.Errorf("%s has no epic file (sync to load it)", task.ID)
+	}
+	path := task.FilePath
+	if !filepath.IsAbs(path) && m.projectRoot != "" {
+		path = filepath.Join(m.projectRoot, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("epic file of %s not found: %s", task.ID, path)
+	}
+	return path, nil
+}
+
+// moduleEpicTask returns the task whose epic file represents a module on the
+// Modules tab: its first incomplete task, or its first task if all are done
+func (m *Model) moduleEpicTask(module string) *domain.Task {
+	var first, firstOpen *domain.Task
+	for _, task := range m.allTasks {
+		if task.ID.Module != module {
+			continue
+		}
+		if first == nil || task.ID.EpicNum < first.ID.EpicNum {
+			first = task
+		}
+		if task.Status != domain.StatusComplete && (firstOpen == nil || task.ID.EpicNum < firstOpen.ID.EpicNum) {
+			firstOpen = task
+		}
+	}
+	if firstOpen != nil {
+		return firstOpen
+	}
+	return first
+}
+
+// openEpicCmd opens an epic file in the editor, suspending the TUI until it exits
+func openEpicCmd(taskID, path string) tea.Cmd {
+	return runEditor(editorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("editor: %w", err)
+		}
+		return EpicEditedMsg{TaskID: taskID, Path: path, Err: err}
+	})
+}
+
+// editPromptCmd writes the prompt to a temp file, opens it in the editor and
+// reports the saved contents as a PromptEditedMsg
+func editPromptCmd(taskID, prompt string) tea.Cmd {
+	return editTextCmd("claude-orch-prompt-*.md", prompt, func(text string, err error) tea.Msg {
+		return PromptEditedMsg{TaskID: taskID, Original: prompt, Prompt: text, Err: err}
+	})
+}
+
+// editNoteCmd opens an empty temp file in the editor and reports what was
+// written as a NoteEditedMsg
+func editNoteCmd(runID, taskID string) tea.Cmd {
+	return editTextCmd("claude-orch-note-*.txt", "", func(text string, err error) tea.Msg {
+		return NoteEditedMsg{RunID: runID, TaskID: taskID, Note: text, Err: err}
+	})
+}
+
+// editTextCmd writes text to a temp file named after pattern, opens it in the
+// editor and passes the saved contents to done
+func editTextCmd(pattern, text string, done func(text string, err error) tea.Msg) tea.Cmd {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return func() tea.Msg { return done("", err) }
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return done("", err) }
+	}
+
+	return runEditor(editorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return done("", fmt.Errorf("editor: %w", err))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return done("", err)
+		}
+		return done(string(data), nil)
+	})
+}
+
+// annotateRunCmd adds a note to an agent run in the database
+func annotateRunCmd(store *taskstore.Store, runID, taskID, note string) tea.Cmd {
+	note = strings.TrimSpace(note)
+	return func() tea.Msg {
+		err := store.AnnotateAgentRun(runID, note)
+		return RunAnnotatedMsg{RunID: runID, TaskID: taskID, Note: note, Err: err}
+	}
+}
+
+// toggleBuildPoolCmd starts or stops the in-process build pool off the UI goroutine
+func toggleBuildPoolCmd(svc *buildpool.Service, start bool) tea.Cmd {
+	return func() tea.Msg {
+		if !start {
+			svc.Stop()
+			return BuildPoolToggledMsg{Running: false}
 		}
+		err := svc.Start()
+		return BuildPoolToggledMsg{Running: true, Err: err}
+	}
+}
+
+// fetchWorkersCmd fetches worker status from the build pool coordinator
+// (if buildPoolURL is set) and from the additional coordinators, in parallel
+func fetchWorkersCmd(buildPoolURL string, coordinators []CoordinatorSource) tea.Cmd {
+	return func() tea.Msg {
+		var msg WorkersUpdateMsg
+		msg.Coordinators = make([]CoordinatorStatus, len(coordinators))
+
+		var wg sync.WaitGroup
+		if buildPoolURL != "" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				msg.Workers, msg.Status = fetchWorkers(buildPoolURL)
+			}()
+		}
+		for i, c := range coordinators {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				workers, status := fetchWorkers(c.URL)
+				msg.Coordinators[i] = CoordinatorStatus{Name: c.Name, URL: c.URL, Status: status, Workers: workers}
+			}()
+		}
+		wg.Wait()
+
+		return msg
+	}
+}
+
+// fetchWorkers queries a coordinator's /status endpoint and returns its
+// workers and "connected", or nil and "unreachable"
+func fetchWorkers(coordinatorURL string) ([]*WorkerView, string) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(coordinatorURL + "/status")
+	if err != nil {
+		// Coordinator not reachable
+		return nil, "unreachable"
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "unreachable"
+	}
+
+	var status struct {
+		Workers []struct {
+			ID             string   `json:"id"`
+			MaxJobs        int      `json:"max_jobs"`
+			ActiveJobs     int      `json:"active_jobs"`
+			ConnectedSince string   `json:"connected_since"`
+			Tags           []string `json:"tags"`
+		} `json:"workers"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, "unreachable"
+	}
 
-		return WorkersUpdateMsg{Workers: workers, Status: "connected"}
+	workers := make([]*WorkerView, 0, len(status.Workers))
+	for _, w := range status.Workers {
+		connectedAt, _ := time.Parse(time.RFC3339, w.ConnectedSince)
+		workers = append(workers, &WorkerView{
+			ID:          w.ID,
+			MaxJobs:     w.MaxJobs,
+			ActiveJobs:  w.ActiveJobs,
+			ConnectedAt: connectedAt,
+			Tags:        w.Tags,
+		})
 	}
+
+	return workers, "connected"
 }
 
 // testWorkerCmd sends a test job to verify worker connectivity
@@ -1739,7 +3201,7 @@ func testWorkerCmd(buildPoolURL, projectRoot string, gitDaemonPort int) tea.Cmd
 
 			// If localhost, try to get a network-accessible address for remote workers
 			if hostPart == "localhost" || hostPart == "127.0.0.1" {
-				hostPart = getExternalHost()
+				hostPart = netutil.ExternalHost()
 			}
 
 			gitURL = fmt.Sprintf("git://%s:%d/", hostPart, port)
@@ -1796,25 +3258,6 @@ func testWorkerCmd(buildPoolURL, projectRoot string, gitDaemonPort int) tea.Cmd
 	}
 }
 
-// getExternalHost tries to get a network-accessible address for this machine
-// Prefers Tailscale IP, falls back to hostname
-func getExternalHost() string {
-	// Try Tailscale IP first (most reliable for remote access)
-	if out, err := exec.Command("tailscale", "ip", "-4").Output(); err == nil {
-		if ip := strings.TrimSpace(string(out)); ip != "" {
-			return ip
-		}
-	}
-
-	// Try hostname
-	if hostname, err := os.Hostname(); err == nil && hostname != "" {
-		return hostname
-	}
-
-	// Last resort - return localhost (will likely fail for remote workers)
-	return "localhost"
-}
-
 // testWorkerErrorCmd sends test commands to verify error handling
 // It sends three test commands:
 // 1. A command that exits with code 42 (should show "exit code 42")
@@ -1919,7 +3362,7 @@ func testEmbeddedWorkerDirectCmd(projectRoot string) tea.Cmd {
 				Timeout: 30,
 			}
 
-			result := worker.Run(job)
+			result := worker.Run(context.Background(), job)
 
 			// Format result - show all fields to diagnose issue
 			output := result.Output
@@ -1939,10 +3382,51 @@ func testEmbeddedWorkerDirectCmd(projectRoot string) tea.Cmd {
 	}
 }
 
+// twoWaySync and resolveConflicts run sync operations (replaceable in tests)
+var (
+	twoWaySync       = (*isync.Syncer).TwoWaySync
+	resolveConflicts = (*isync.Syncer).ResolveConflicts
+)
+
+// syncOperation is a sync or conflict resolution started from the Modules
+// tab, kept after it fails so it can be retried with the same parameters
+type syncOperation struct {
+	Resolutions map[string]string // Conflict resolutions; nil for a two-way sync
+}
+
+// name describes the operation in status messages
+func (op *syncOperation) name() string {
+	if op.Resolutions != nil {
+		return "conflict resolution"
+	}
+	return "sync"
+}
+
+// cmd runs the operation again
+func (op *syncOperation) cmd(syncer *isync.Syncer, store *taskstore.Store) tea.Cmd {
+	if op.Resolutions != nil {
+		return applyResolutionsCmd(syncer, store, op.Resolutions)
+	}
+	return startSyncCmd(syncer, store)
+}
+
+// estimateBatch estimates the token usage and cost of tasks from the
+// per-module averages of past agent runs (no history without a store)
+func (m Model) estimateBatch(tasks []*domain.Task) (taskstore.BatchEstimate, error) {
+	var averages map[string]taskstore.RunAverage
+	if m.store != nil {
+		var err error
+		if averages, err = m.store.GetModuleRunAverages(); err != nil {
+			return taskstore.EstimateBatch(tasks, nil), err
+		}
+	}
+	return taskstore.EstimateBatch(tasks, averages), nil
+}
+
 // startSyncCmd initiates a two-way sync
 func startSyncCmd(syncer *isync.Syncer, store *taskstore.Store) tea.Cmd {
 	return func() tea.Msg {
-		result, err := syncer.TwoWaySync(store)
+		result, err := twoWaySync(syncer, store)
 		return SyncCompleteMsg{Result: result, Err: err}
 	}
 }
@@ -1951,45 +3435,41 @@ func startSyncCmd(syncer *isync.Syncer, store *taskstore.Store) tea.Cmd {
 func applyResolutionsCmd(syncer *isync.Syncer, store *taskstore.Store, resolutions map[string]string) tea.Cmd {
 	return func() tea.Msg {
 		if syncer == nil || store == nil {
-			return SyncResolveMsg{Err: fmt.Errorf("syncer or store is nil")}
+			return SyncResolveMsg{Resolutions: resolutions, Err: fmt.Errorf("syncer or store is nil")}
+		}
+		changes, err := resolveConflicts(syncer, store, resolutions)
+		return SyncResolveMsg{Resolutions: resolutions, Changes: changes, Err: err}
+	}
+}
+
+// streamTestAgentOutput returns an output callback that sends lines to
+// outputCh, blocking while the view is behind so no line is dropped
+func streamTestAgentOutput(ctx context.Context, outputCh chan<- string) buildpool.TestAgentOutputCallback {
+	return func(line string) {
+		select {
+		case outputCh <- line:
+		case <-ctx.Done():
 		}
-		err := syncer.ResolveConflicts(store, resolutions)
-		return SyncResolveMsg{Err: err}
 	}
 }
 
-// runAgentTestCmd spawns a Claude agent to test the build pool MCP tools via external coordinator
-func runAgentTestCmd(taskID, buildPoolURL, projectRoot, executorType string) tea.Cmd {
+// runAgentTestCmd spawns a Claude agent to test the build pool MCP tools via external coordinator.
+// Output lines are streamed to outputCh, which is closed when the agent exits.
+func runAgentTestCmd(taskID, buildPoolURL, projectRoot, executorType, binaryPath string, outputCh chan<- string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		// Initialize shared buffer for streaming output
-		testAgentMutex.Lock()
-		testAgentOutput = nil
-		testAgentTaskID = taskID
-		testAgentMutex.Unlock()
-
-		// Callback to capture streaming output
-		onOutput := func(line string) {
-			testAgentMutex.Lock()
-			testAgentOutput = append(testAgentOutput, line)
-			testAgentMutex.Unlock()
-		}
-
 		config := buildpool.TestAgentConfig{
 			BuildPoolURL: buildPoolURL,
 			ProjectRoot:  projectRoot,
 			Verbose:      true,
 			ExecutorType: executorType,
+			BinaryPath:   binaryPath,
 		}
 
-		result, err := buildpool.RunTestAgent(ctx, config, onOutput)
-
-		// Clear the task ID when done
-		testAgentMutex.Lock()
-		testAgentTaskID = ""
-		testAgentMutex.Unlock()
+		result, err := buildpool.RunTestAgent(ctx, config, streamTestAgentOutput(ctx, outputCh))
+		close(outputCh)
 
 		if err != nil {
 			return AgentTestMsg{
@@ -2008,31 +3488,15 @@ func runAgentTestCmd(taskID, buildPoolURL, projectRoot, executorType string) tea
 	}
 }
 
-// runAgentTestWithEmbeddedCmd spawns a Claude agent with a temporary embedded coordinator
-func runAgentTestWithEmbeddedCmd(taskID, projectRoot, executorType string) tea.Cmd {
+// runAgentTestWithEmbeddedCmd spawns a Claude agent with a temporary embedded coordinator.
+// Output lines are streamed to outputCh, which is closed when the agent exits.
+func runAgentTestWithEmbeddedCmd(taskID, projectRoot, executorType, binaryPath string, outputCh chan<- string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		// Initialize shared buffer for streaming output
-		testAgentMutex.Lock()
-		testAgentOutput = nil
-		testAgentTaskID = taskID
-		testAgentMutex.Unlock()
-
-		// Callback to capture streaming output
-		onOutput := func(line string) {
-			testAgentMutex.Lock()
-			testAgentOutput = append(testAgentOutput, line)
-			testAgentMutex.Unlock()
-		}
-
-		result, err := buildpool.RunTestAgentWithEmbeddedCoordinator(ctx, projectRoot, true, executorType, onOutput)
-
-		// Clear the task ID when done
-		testAgentMutex.Lock()
-		testAgentTaskID = ""
-		testAgentMutex.Unlock()
+		result, err := buildpool.RunTestAgentWithEmbeddedCoordinator(ctx, projectRoot, true, executorType, binaryPath, streamTestAgentOutput(ctx, outputCh))
+		close(outputCh)
 
 		if err != nil {
 			return AgentTestMsg{
@@ -2051,6 +3515,48 @@ func runAgentTestWithEmbeddedCmd(taskID, projectRoot, executorType string) tea.C
 	}
 }
 
+// newScheduler builds a scheduler over the queued tasks, honouring group
+// priorities from the store and the default tier for unprioritized groups
+func (m *Model) newScheduler() *scheduler.Scheduler {
+	var groupPriorities map[string]int
+	if m.store != nil {
+		groupPriorities, _ = m.store.GetGroupPriorities()
+	}
+
+	var sched *scheduler.Scheduler
+	if len(groupPriorities) > 0 {
+		sched = scheduler.NewWithPriorities(m.queued, m.completedTasks, groupPriorities)
+	} else {
+		sched = scheduler.New(m.queued, m.completedTasks)
+	}
+	sched.SetDefaultTier(m.defaultGroupTier)
+	sched.SetTaskOverrides(m.taskOverrides)
+	sched.SetMaxPerModule(m.maxPerModule)
+	sched.SetGroupWeights(m.groupWeights)
+	return sched
+}
+
+// recordAgentOutcome counts agent failures in a row, resetting on a success.
+// When the count reaches the configured maximum, auto mode is paused so a
+// systemic problem (e.g. the API being down) does not burn more attempts.
+func (m *Model) recordAgentOutcome(success bool) {
+	if success {
+		m.consecutiveFailures = 0
+		m.autoPausedOnFailure = false
+		return
+	}
+
+	m.consecutiveFailures++
+	if m.autoMode && m.maxFailuresInARow > 0 && m.consecutiveFailures >= m.maxFailuresInARow {
+		m.autoMode = false
+		m.autoPausedOnFailure = true
+		m.logEvent(EventWarning, fmt.Sprintf("⚠ Auto mode paused: %d agents failed in a row (press a to resume)", m.consecutiveFailures))
+	}
+}
+
+// throttledStartInterval is how often auto mode starts an agent while the API is throttling
+const throttledStartInterval = 30 * time.Second
+
 // tryStartAutoTasks checks if we can start more tasks in auto mode
 func (m *Model) tryStartAutoTasks() tea.Cmd {
 	if !m.autoMode || m.batchPaused {
@@ -2063,27 +3569,29 @@ func (m *Model) tryStartAutoTasks() tea.Cmd {
 		return nil
 	}
 
+	// Throttle how many agents start at once to smooth load
+	if m.maxStartsPerTick > 0 && slotsAvailable > m.maxStartsPerTick {
+		slotsAvailable = m.maxStartsPerTick
+	}
+
+	// While agents fail on API rate limits, start one agent at a time, spaced out
+	if m.agentManager != nil && m.agentManager.Throttled() {
+		if time.Since(m.lastAutoStart) < throttledStartInterval {
+			return nil
+		}
+		slotsAvailable = 1
+	}
+
 	// Get in-progress task IDs from currently running agents
 	inProgress := make(map[string]bool)
 	for _, a := range m.agents {
-		if a.Status == executor.AgentRunning {
+		if a.Status.Active() {
 			inProgress[a.TaskID] = true
 		}
 	}
 
-	// Load group priorities from store if available
-	var groupPriorities map[string]int
-	if m.store != nil {
-		groupPriorities, _ = m.store.GetGroupPriorities()
-	}
-
 	// Use scheduler to select tasks that don't conflict with running agents
-	var sched *scheduler.Scheduler
-	if len(groupPriorities) > 0 {
-		sched = scheduler.NewWithPriorities(m.queued, m.completedTasks, groupPriorities)
-	} else {
-		sched = scheduler.New(m.queued, m.completedTasks)
-	}
+	sched := m.newScheduler()
 	readyTasks := sched.GetReadyTasksExcluding(slotsAvailable, inProgress)
 
 	if len(readyTasks) == 0 {
@@ -2091,6 +3599,9 @@ func (m *Model) tryStartAutoTasks() tea.Cmd {
 		if len(m.queued) == 0 {
 			m.autoMode = false
 			m.statusMsg = "Auto mode: all tasks complete!"
+		} else if m.activeCount == 0 && len(sched.Cycles()) > 0 {
+			// Nothing runs and nothing can start: say why instead of idling
+			m.statusMsg = "Auto mode: " + cycleWarning(sched)
 		}
 		return nil
 	}
@@ -2099,6 +3610,7 @@ func (m *Model) tryStartAutoTasks() tea.Cmd {
 	m.batchRunning = true
 	m.batchPaused = false
 	m.statusMsg = fmt.Sprintf("Auto: starting %d task(s)...", len(readyTasks))
+	m.lastAutoStart = time.Now()
 
 	return startBatchCmd(
 		m.projectRoot,
@@ -2139,6 +3651,8 @@ func loadAgentHistoryCmd(store *taskstore.Store) tea.Cmd {
 				Status:       status,
 				WorktreePath: run.WorktreePath,
 				LogPath:      run.LogPath,
+				RunID:        run.ID,
+				Notes:        run.Notes,
 				Error:        run.ErrorMessage,
 				TokensInput:  run.TokensInput,
 				TokensOutput: run.TokensOutput,
@@ -2150,24 +3664,26 @@ func loadAgentHistoryCmd(store *taskstore.Store) tea.Cmd {
 	}
 }
 
-// loadHistoryLogsCmd loads log output from a historical agent run's log file
-func loadHistoryLogsCmd(logPath string, index int) tea.Cmd {
+// defaultHistoryLogLines is how many lines of a historical agent run's log are shown
+const defaultHistoryLogLines = 500
+
+// loadHistoryLogsCmd loads the last maxLines lines of a historical agent run's
+// log file (0 = defaultHistoryLogLines)
+func loadHistoryLogsCmd(logPath string, index int, maxLines int) tea.Cmd {
+	if maxLines <= 0 {
+		maxLines = defaultHistoryLogLines
+	}
 	return func() tea.Msg {
 		if logPath == "" {
 			return HistoryLogsMsg{Index: index, Error: fmt.Errorf("no log file path")}
 		}
 
-		data, err := os.ReadFile(logPath)
+		// Read only the last lines to avoid loading huge logs into memory
+		lines, err := executor.TailFile(logPath, maxLines)
 		if err != nil {
 			return HistoryLogsMsg{Index: index, Error: fmt.Errorf("failed to read log file: %w", err)}
 		}
 
-		lines := strings.Split(string(data), "\n")
-		// Limit to last 500 lines to avoid memory issues
-		if len(lines) > 500 {
-			lines = lines[len(lines)-500:]
-		}
-
 		return HistoryLogsMsg{Index: index, Lines: lines}
 	}
 }
@@ -2192,7 +3708,7 @@ func (m *Model) startMaintenanceTask() tea.Cmd {
 	return func() tea.Msg {
 		// Generate a unique task ID for this maintenance run
 		// Use the same TaskID throughout to ensure consistency
-		taskID := domain.TaskID{Module: "maint", EpicNum: int(time.Now().Unix() % 10000)}
+		taskID := domain.TaskID{Module: executor.MaintenanceModule, EpicNum: int(time.Now().Unix() % 10000)}
 		taskIDStr := taskID.String()
 		title := fmt.Sprintf("%s (%s)", template.Name, scope)
 
@@ -2231,24 +3747,29 @@ func (m *Model) startMaintenanceTask() tea.Cmd {
 		if agentMgr != nil {
 			agent.BuildPoolURL = agentMgr.GetBuildPoolURL()
 			agent.ExecutorType = agentMgr.GetExecutorType()
+			agent.ClaudeModel = agentMgr.GetClaudeModel()
 			agent.OpenCodeModel = agentMgr.GetOpenCodeModel()
+			agent.GeminiModel = agentMgr.GetGeminiModel()
+			agent.BinaryPath = agentMgr.GetBinaryPath(agent.ExecutorType)
+			agent.Sandbox = agentMgr.GetSandbox()
+			agent.MaxDuration = agentMgr.GetAgentTimeout(agent.ExecutorType)
+			agent.MaxOutputLines = agentMgr.GetMaxOutputLines()
+			agent.StopGrace = agentMgr.GetStopGrace()
 			agent.OnStatusChange = agentMgr.CreateStatusCallback()
 
-			if agentMgr.CanStart() {
-				if err := agent.Start(context.Background()); err != nil {
-					// Clean up worktree on failure
-					if wtMgr != nil {
-						wtMgr.Remove(wtPath)
-					}
-					return MaintenanceStartMsg{
-						TaskID:  taskIDStr,
-						Title:   title,
-						Success: false,
-						Error:   fmt.Sprintf("failed to start agent: %v", err),
-					}
+			// The manager keeps slots free for task agents
+			if err := agentMgr.StartAgent(context.Background(), agent); err != nil {
+				// Clean up worktree on failure
+				if wtMgr != nil {
+					wtMgr.Remove(wtPath)
+				}
+				return MaintenanceStartMsg{
+					TaskID:  taskIDStr,
+					Title:   title,
+					Success: false,
+					Error:   fmt.Sprintf("failed to start agent: %v", err),
 				}
 			}
-			agentMgr.Add(agent)
 		}
 
 		return MaintenanceStartMsg{
@@ -2261,6 +3782,25 @@ func (m *Model) startMaintenanceTask() tea.Cmd {
 	}
 }
 
+// sortGroupPriorityItems sorts groups in display order: by tier ascending with
+// unassigned (-1) at the end, and within a tier by weight, heaviest first
+func sortGroupPriorityItems(items []GroupPriorityItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		pi, pj := items[i].Priority, items[j].Priority
+		// Unassigned (-1) goes to the end
+		if pi < 0 && pj >= 0 {
+			return false
+		}
+		if pj < 0 && pi >= 0 {
+			return true
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return items[i].Weight > items[j].Weight
+	})
+}
+
 // loadGroupPrioritiesCmd loads group priority data from the store
 func loadGroupPrioritiesCmd(store *taskstore.Store) tea.Cmd {
 	return func() tea.Msg {
@@ -2278,6 +3818,7 @@ func loadGroupPrioritiesCmd(store *taskstore.Store) tea.Cmd {
 			items[i] = GroupPriorityItem{
 				Name:      s.Name,
 				Priority:  s.Priority,
+				Weight:    s.Weight,
 				Total:     s.Total,
 				Completed: s.Completed,
 			}
@@ -2298,6 +3839,17 @@ func setGroupPriorityCmd(store *taskstore.Store, group string, priority int) tea
 	}
 }
 
+// setGroupWeightCmd sets the weight of a group within its tier
+func setGroupWeightCmd(store *taskstore.Store, group string, weight int) tea.Cmd {
+	return func() tea.Msg {
+		if store == nil {
+			return SetGroupWeightMsg{Group: group, Error: fmt.Errorf("no database")}
+		}
+		err := store.SetGroupWeight(group, weight)
+		return SetGroupWeightMsg{Group: group, Weight: weight, Error: err}
+	}
+}
+
 // removeGroupPriorityCmd removes a group from the priorities table
 func removeGroupPriorityCmd(store *taskstore.Store, group string) tea.Cmd {
 	return func() tea.Msg {
@@ -2309,6 +3861,122 @@ func removeGroupPriorityCmd(store *taskstore.Store, group string) tea.Cmd {
 	}
 }
 
+// setTaskOverrideCmd promotes a task ahead of its peers, or clears the override
+func setTaskOverrideCmd(store *taskstore.Store, taskID string, promote bool) tea.Cmd {
+	return func() tea.Msg {
+		if store == nil {
+			return TaskOverrideMsg{TaskID: taskID, Error: fmt.Errorf("no database")}
+		}
+		var err error
+		if promote {
+			err = store.SetTaskPriorityOverride(taskID, 0)
+		} else {
+			err = store.RemoveTaskPriorityOverride(taskID)
+		}
+		return TaskOverrideMsg{TaskID: taskID, Promoted: promote, Error: err}
+	}
+}
+
+// reopenTaskCmd sets a completed task back to not_started and, if a syncer is
+// configured, syncs the status to its epic file and README
+func reopenTaskCmd(syncer *isync.Syncer, store *taskstore.Store, taskID string) tea.Cmd {
+	return func() tea.Msg {
+		if store == nil {
+			return TaskReopenMsg{TaskID: taskID, Error: fmt.Errorf("no database")}
+		}
+		if syncer == nil {
+			err := store.ReopenTask(taskID)
+			return TaskReopenMsg{TaskID: taskID, Reopened: err == nil, Error: err}
+		}
+		task, err := syncer.ReopenTask(store, taskID)
+		return TaskReopenMsg{TaskID: taskID, Reopened: task != nil, Error: err}
+	}
+}
+
+// createTaskCmd writes the epic file of a task from the creation wizard and
+// syncs it into the store
+func createTaskCmd(syncer *isync.Syncer, store *taskstore.Store, task isync.NewTask) tea.Cmd {
+	return func() tea.Msg {
+		if syncer == nil {
+			return TaskCreateMsg{TaskID: task.ID.String(), Error: fmt.Errorf("no plans directory configured")}
+		}
+		path, err := syncer.CreateTask(store, task)
+		return TaskCreateMsg{TaskID: task.ID.String(), Path: path, Error: err}
+	}
+}
+
+// openTaskWizard shows the task creation wizard, prefilled with the selected
+// task's module and the next free epic number in it
+func (m *Model) openTaskWizard() {
+	m.taskWizard = TaskWizardModal{Visible: true}
+	if task := m.selectedTask(); task != nil {
+		m.taskWizard.Fields[wizardModule] = task.ID.Module
+		m.taskWizard.Fields[wizardEpic] = strconv.Itoa(m.nextEpicNum(task.ID.Module))
+		m.taskWizard.Focus = wizardTitle
+	}
+}
+
+// focusWizardField moves the wizard's focus, filling in the next epic number
+// when leaving the module field with no epic number entered
+func (m *Model) focusWizardField(field int) {
+	w := &m.taskWizard
+	module := strings.TrimSpace(w.Fields[wizardModule])
+	if w.Focus == wizardModule && module != "" && strings.TrimSpace(w.Fields[wizardEpic]) == "" {
+		w.Fields[wizardEpic] = strconv.Itoa(m.nextEpicNum(module))
+	}
+	w.Focus = field
+}
+
+// nextEpicNum returns the number after the module's highest unprefixed epic
+func (m Model) nextEpicNum(module string) int {
+	next := 1
+	for _, t := range m.allTasks {
+		if t.ID.Module == module && t.ID.Prefix == "" && t.ID.EpicNum >= next {
+			next = t.ID.EpicNum + 1
+		}
+	}
+	return next
+}
+
+// newTask parses the wizard fields into the task to create. The epic field
+// takes a number with an optional prefix, e.g. "3", "E03" or "UI2".
+func (w TaskWizardModal) newTask() (isync.NewTask, error) {
+	module := strings.TrimSpace(w.Fields[wizardModule])
+	task := isync.NewTask{
+		ID:          domain.TaskID{Module: module},
+		Title:       strings.TrimSpace(w.Fields[wizardTitle]),
+		Description: strings.TrimSpace(w.Fields[wizardDescription]),
+	}
+
+	epic := strings.ToUpper(strings.TrimSpace(w.Fields[wizardEpic]))
+	digits := strings.TrimLeft(epic, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	num, err := strconv.Atoi(digits)
+	if err != nil || digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return task, fmt.Errorf("epic must be a number with an optional prefix, e.g. 3, E03 or UI2")
+	}
+	if prefix := strings.TrimSuffix(epic, digits); prefix != "E" {
+		task.ID.Prefix = prefix
+	}
+	task.ID.EpicNum = num
+
+	switch p := strings.ToLower(strings.TrimSpace(w.Fields[wizardPriority])); p {
+	case "", "normal":
+	case "high", "medium", "low":
+		task.Priority = parser.ToPriority(p)
+	default:
+		return task, fmt.Errorf("priority must be high, medium, low or empty")
+	}
+
+	deps := strings.FieldsFunc(w.Fields[wizardDependsOn], func(r rune) bool { return r == ',' || r == ' ' })
+	if len(deps) > 0 {
+		task.DependsOn, err = parser.ParseDependenciesInModule(deps, module)
+		if err != nil {
+			return task, fmt.Errorf("invalid dependency: %v", err)
+		}
+	}
+	return task, nil
+}
+
 // checkUpdateCmd checks for available updates asynchronously
 func checkUpdateCmd(currentVersion string) tea.Cmd {
 	return func() tea.Msg {
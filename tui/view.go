@@ -6,12 +6,14 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/executor"
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/scheduler"
 	isync "github.com/hochfrequenz/claude-plan-orchestrator/internal/sync"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/taskstore"
 )
 
 var (
@@ -95,11 +97,28 @@ func (m Model) View() string {
 		execType := string(m.agentManager.GetExecutorType())
 		if execType == "opencode" {
 			title = "OpenCode Orchestrator"
+		} else if execType == "gemini" {
+			title = "Gemini CLI Orchestrator"
 		}
 	}
 	header := fmt.Sprintf(" %s │ Active: %d/%d │ Tasks: %d │ Completed today: %d │ Flagged: %d ",
 		title, m.activeCount, m.maxActive, len(m.allTasks), m.completedToday, len(m.flagged))
 
+	// Warn while agents are failing on API rate limits (auto mode slows down)
+	if m.agentManager != nil && m.agentManager.Throttled() {
+		header = fmt.Sprintf("%s│ ⚠ API throttling detected ", header)
+	}
+
+	// Point at warnings and errors logged since the events log was last opened
+	if unseen := m.eventsLogged - m.eventsSeen; unseen > 0 {
+		header = fmt.Sprintf("%s│ ⚠ %d new in [l]og ", header, unseen)
+	}
+
+	// Warn while auto mode is paused after repeated agent failures
+	if m.autoPausedOnFailure {
+		header = fmt.Sprintf("%s│ ⚠ Auto paused: %d failures in a row ", header, m.consecutiveFailures)
+	}
+
 	// Add update indicator to header
 	if m.updateInProgress {
 		header = fmt.Sprintf("%s│ ⏳ %s ", header, m.updateStatus)
@@ -109,7 +128,12 @@ func (m Model) View() string {
 		header = fmt.Sprintf("%s│ ⬆ Update %s [U] ", header, m.updateAvailable)
 	}
 
-	b.WriteString(headerStyle.Width(m.width).Render(header))
+	headerLine := headerStyle.Width(m.width)
+	if m.activeTab == 0 && m.compactDashboard() {
+		// Cut the header instead of wrapping it
+		headerLine = headerLine.MaxHeight(1)
+	}
+	b.WriteString(headerLine.Render(header))
 	b.WriteString("\n")
 
 	// Tab bar
@@ -121,10 +145,19 @@ func (m Model) View() string {
 		prioritiesSection := m.renderGroupPriorities()
 		b.WriteString(sectionStyle.Width(m.width - 2).Render(prioritiesSection))
 		b.WriteString("\n")
+	} else if m.showEvents {
+		b.WriteString(sectionStyle.Width(m.width - 2).Render(m.renderEvents()))
+		b.WriteString("\n")
 	} else {
 		// Content based on active tab
 		switch m.activeTab {
 		case 0: // Dashboard
+			if m.compactDashboard() {
+				b.WriteString(sectionStyle.Width(m.width - 2).Render(m.renderCompactDashboard()))
+				b.WriteString("\n")
+				break
+			}
+
 			runningSection := m.renderRunning()
 			b.WriteString(sectionStyle.Width(m.width - 2).Render(runningSection))
 			b.WriteString("\n")
@@ -215,34 +248,66 @@ func (m Model) View() string {
 		if m.viewMode == ViewByModule {
 			viewModeStr = "module"
 		}
-		statusBar = fmt.Sprintf(" [tab]switch [v]iew mode (%s) [j/k]scroll %s [q]uit ", viewModeStr, mouseHint)
+		filterStr := "all"
+		if m.unblockingSoon {
+			filterStr = "unblocking soon"
+		}
+		statusBar = fmt.Sprintf(" [tab]switch [v]iew mode (%s) [f]ilter (%s) [j/k]scroll [space]mark [s]tart marked [o]pen epic [P]romote [O]reopen [N]ew task %s [q]uit ", viewModeStr, filterStr, mouseHint)
 	case 2: // Agents
-		if m.showAgentDetail {
-			statusBar = fmt.Sprintf(" [j/k]scroll [g]top [G]bottom [esc/enter]back [r]esume %s [q]uit ", mouseHint)
+		if m.showHistoryDetail {
+			statusBar = fmt.Sprintf(" [j/k]scroll [g]top [G]bottom [esc]back [n]ote %s [q]uit ", mouseHint)
+		} else if m.showAgentDetail {
+			statusBar = fmt.Sprintf(" [j/k]scroll [g]top [G]bottom [esc/enter]back [r]esume [R]estart [K]ill [c]opy/[E]dit prompt [!]shell %s [q]uit ", mouseHint)
 		} else if len(m.agents) > 0 {
-			statusBar = fmt.Sprintf(" [tab]switch [j/k]navigate [enter]details [+/-]max agents %s [q]uit ", mouseHint)
+			statusBar = fmt.Sprintf(" [tab]switch [j/k]navigate [enter]details [R]estart [K]ill [+/-]max agents %s [q]uit ", mouseHint)
 		} else {
 			statusBar = fmt.Sprintf(" [tab]switch [+/-]max agents %s [q]uit ", mouseHint)
 		}
 	case 3: // Modules
-		statusBar = fmt.Sprintf(" [tab]switch [j/k]scroll [g]roups [m]aint [s]sync [x]run tests %s [q]uit ", mouseHint)
+		changesHint := ""
+		if len(m.syncChanges) > 0 {
+			changesHint = "[S]changes "
+		}
+		if m.failedSync != nil {
+			changesHint += "[R]etry sync "
+		}
+		statusBar = fmt.Sprintf(" [tab]switch [j/k]scroll [g]roups [m]aint [s]sync [o]pen epic %s[x/X]run all/changed %s [q]uit ", changesHint, mouseHint)
 	default:
 		testHint := ""
 		if m.buildPoolStatus == "connected" {
 			testHint = "[T]est worker "
 		}
+		if m.buildPool != nil {
+			if m.buildPoolURL != "" {
+				testHint += "[b]uild pool:on "
+			} else {
+				testHint += "[b]uild pool:off "
+			}
+		}
 		autoHint := "[a]uto"
 		if m.autoMode {
 			autoHint = "[a]uto:ON"
 		}
-		if m.batchRunning && !m.batchPaused {
-			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[p]ause %s %s [q]uit ", testHint, autoHint, mouseHint)
+		if m.activeTab == 0 && m.compactDashboard() {
+			// Only the essentials fit on a small terminal
+			startHint := "[s]tart"
+			if m.batchRunning && !m.batchPaused {
+				startHint = "[p]ause"
+			} else if m.batchRunning {
+				startHint = "[p]resume"
+			}
+			statusBar = fmt.Sprintf(" [tab] %s %s [L]ayout:%s [q]uit ", startHint, autoHint, m.dashboardLayout)
+		} else if m.batchRunning && !m.batchPaused {
+			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[p]ause %s [l]og %s [q]uit ", testHint, autoHint, mouseHint)
 		} else if m.batchRunning && m.batchPaused {
-			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[p]resume %s %s [q]uit ", testHint, autoHint, mouseHint)
+			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[p]resume %s [l]og %s [q]uit ", testHint, autoHint, mouseHint)
 		} else {
-			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[s]tart [a]uto %s [q]uit ", testHint, mouseHint)
+			statusBar = fmt.Sprintf(" [tab]switch [t]asks [m]odules [g]roups %s[s]tart [B]replay [a]uto [l]og %s [q]uit ", testHint, mouseHint)
 		}
 	}
+	if m.showEvents {
+		statusBar = " [j/k]scroll [l/esc]close [q]uit "
+	}
 	b.WriteString(statusBarStyle.Width(m.width).Render(statusBar))
 
 	// Render sync modal overlay if visible
@@ -309,74 +374,129 @@ func (m Model) View() string {
 		}
 	}
 
+	// Render task creation wizard overlay if visible
+	if m.taskWizard.Visible {
+		return m.overlayModal(b.String(), m.renderTaskWizardModal())
+	}
+
 	// Render maintenance modal overlay if visible
 	if m.maintenanceModal.Visible {
-		modal := m.renderMaintenanceModal()
-		if modal != "" {
-			// Center the modal horizontally
-			modalLines := strings.Split(modal, "\n")
-			var centeredModal strings.Builder
-			for _, line := range modalLines {
-				// Calculate padding to center
-				padding := (m.width - lipgloss.Width(line)) / 2
-				if padding < 0 {
-					padding = 0
-				}
-				centeredModal.WriteString(strings.Repeat(" ", padding))
-				centeredModal.WriteString(line)
-				centeredModal.WriteString("\n")
-			}
-			// Add vertical padding and overlay
-			content := b.String()
-			contentLines := strings.Split(content, "\n")
-			modalHeight := len(modalLines)
-			contentHeight := len(contentLines)
+		if modal := m.renderMaintenanceModal(); modal != "" {
+			return m.overlayModal(b.String(), modal)
+		}
+	}
 
-			// Ensure we have enough lines to fit the modal
-			minRequiredHeight := modalHeight + 4
-			if contentHeight < minRequiredHeight {
-				for i := contentHeight; i < minRequiredHeight; i++ {
-					contentLines = append(contentLines, strings.Repeat(" ", m.width))
-				}
-				contentHeight = len(contentLines)
-			}
+	// Render batch start confirmation overlay if visible
+	if m.batchConfirm.Visible {
+		return m.overlayModal(b.String(), m.renderBatchConfirmModal())
+	}
 
-			// Calculate vertical position (roughly centered)
-			topPadding := (contentHeight - modalHeight) / 2
-			if topPadding < 2 {
-				topPadding = 2
-			}
+	// Render agent cancellation confirmation overlay if visible
+	if m.cancelConfirm != "" {
+		return m.overlayModal(b.String(), m.renderCancelConfirmModal())
+	}
 
-			// Pre-split centered modal for efficiency
-			centeredModalLines := strings.Split(centeredModal.String(), "\n")
+	return b.String()
+}
 
-			// Rebuild content with modal overlay
-			var result strings.Builder
-			for i, line := range contentLines {
-				if i >= topPadding && i < topPadding+modalHeight {
-					modalLineIdx := i - topPadding
-					if modalLineIdx < len(centeredModalLines) {
-						result.WriteString(strings.TrimRight(centeredModalLines[modalLineIdx], " "))
-					} else {
-						result.WriteString(line)
-					}
-				} else {
-					result.WriteString(line)
-				}
-				if i < len(contentLines)-1 {
-					result.WriteString("\n")
-				}
+// overlayModal draws modal centered over the rendered view content
+func (m Model) overlayModal(content, modal string) string {
+	// Center the modal horizontally
+	modalLines := strings.Split(modal, "\n")
+	var centeredModal strings.Builder
+	for _, line := range modalLines {
+		// Calculate padding to center
+		padding := (m.width - lipgloss.Width(line)) / 2
+		if padding < 0 {
+			padding = 0
+		}
+		centeredModal.WriteString(strings.Repeat(" ", padding))
+		centeredModal.WriteString(line)
+		centeredModal.WriteString("\n")
+	}
+	// Add vertical padding and overlay
+	contentLines := strings.Split(content, "\n")
+	modalHeight := len(modalLines)
+	contentHeight := len(contentLines)
+
+	// Ensure we have enough lines to fit the modal
+	minRequiredHeight := modalHeight + 4
+	if contentHeight < minRequiredHeight {
+		for i := contentHeight; i < minRequiredHeight; i++ {
+			contentLines = append(contentLines, strings.Repeat(" ", m.width))
+		}
+		contentHeight = len(contentLines)
+	}
+
+	// Calculate vertical position (roughly centered)
+	topPadding := (contentHeight - modalHeight) / 2
+	if topPadding < 2 {
+		topPadding = 2
+	}
+
+	// Pre-split centered modal for efficiency
+	centeredModalLines := strings.Split(centeredModal.String(), "\n")
+
+	// Rebuild content with modal overlay
+	var result strings.Builder
+	for i, line := range contentLines {
+		if i >= topPadding && i < topPadding+modalHeight {
+			modalLineIdx := i - topPadding
+			if modalLineIdx < len(centeredModalLines) {
+				result.WriteString(strings.TrimRight(centeredModalLines[modalLineIdx], " "))
+			} else {
+				result.WriteString(line)
 			}
-			return result.String()
+		} else {
+			result.WriteString(line)
+		}
+		if i < len(contentLines)-1 {
+			result.WriteString("\n")
 		}
 	}
+	return result.String()
+}
 
-	return b.String()
+// cycleWarning describes the dependency cycles that keep tasks from ever
+// starting ("" if there are none)
+func cycleWarning(sched *scheduler.Scheduler) string {
+	cycles := sched.Cycles()
+	if len(cycles) == 0 {
+		return ""
+	}
+	descriptions := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		descriptions[i] = strings.TrimPrefix(cycle.Error(), "dependency cycle: ")
+	}
+	return fmt.Sprintf("⚠ Dependency cycle, these tasks can never start: %s", strings.Join(descriptions, "; "))
+}
+
+// moduleConcurrencyWarning describes the modules in which several agents run
+// at once, which risks conflicting changes ("" if there are none)
+func moduleConcurrencyWarning(perModule map[string]int) string {
+	var shared []string
+	for module, count := range perModule {
+		if count > 1 {
+			shared = append(shared, fmt.Sprintf("%s (%d)", module, count))
+		}
+	}
+	if len(shared) == 0 {
+		return ""
+	}
+	sort.Strings(shared)
+	return fmt.Sprintf("⚠ Several agents in the same module, changes may conflict: %s", strings.Join(shared, ", "))
 }
 
 func (m Model) renderRunning() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("RUNNING"))
+	if m.throughput != nil {
+		total := 0
+		for _, n := range m.throughput {
+			total += n
+		}
+		b.WriteString(queuedStyle.Render(fmt.Sprintf("   completed per 30m, last 12h: %s %d", renderSparkline(m.throughput), total)))
+	}
 	b.WriteString("\n")
 
 	if len(m.agents) == 0 {
@@ -385,16 +505,29 @@ func (m Model) renderRunning() string {
 	}
 
 	hasRunning := false
+	perModule := runningAgentsPerModule(m.agents)
 	for _, agent := range m.agents {
-		if agent.Status == executor.AgentRunning {
+		if agent.Status.Active() {
 			hasRunning = true
-			line := fmt.Sprintf("  ● %-15s %-20s %5s  %s",
-				agent.TaskID, truncate(agent.Title, 20),
-				formatDuration(agent.Duration), agent.Progress)
-			b.WriteString(runningStyle.Render(line))
+			icon, progress := "●", agent.Progress
+			if agent.Status == executor.AgentStuck {
+				icon, progress = "⚠", silentFor(agent)
+			}
+			line := fmt.Sprintf("  %s %-15s %-20s %5s  %s",
+				icon, agent.TaskID, truncate(agent.Title, 20),
+				formatDuration(agent.Duration), progress)
+			if agent.Status == executor.AgentStuck || sharesModule(agent, perModule) {
+				b.WriteString(warningStyle.Render(line + " ⚠"))
+			} else {
+				b.WriteString(runningStyle.Render(line))
+			}
 			b.WriteString("\n")
 		}
 	}
+	if warning := moduleConcurrencyWarning(perModule); warning != "" {
+		b.WriteString(warningStyle.Render("  " + warning))
+		b.WriteString("\n")
+	}
 
 	// Also show failed agents with errors on dashboard
 	for _, agent := range m.agents {
@@ -414,7 +547,7 @@ func (m Model) renderRunning() string {
 		// Check if all are done
 		allDone := true
 		for _, a := range m.agents {
-			if a.Status == executor.AgentQueued || a.Status == executor.AgentRunning {
+			if a.Status == executor.AgentQueued || a.Status.Active() {
 				allDone = false
 				break
 			}
@@ -440,25 +573,18 @@ func (m Model) renderQueued() string {
 	// Get in-progress task IDs from currently running agents
 	inProgress := make(map[string]bool)
 	for _, a := range m.agents {
-		if a.Status == executor.AgentRunning {
+		if a.Status.Active() {
 			inProgress[a.TaskID] = true
 		}
 	}
 
-	// Load group priorities from store if available
-	var groupPriorities map[string]int
-	if m.store != nil {
-		groupPriorities, _ = m.store.GetGroupPriorities()
-	}
-
 	// Use scheduler to get tasks in priority order, respecting dependencies
-	var sched *scheduler.Scheduler
-	if len(groupPriorities) > 0 {
-		sched = scheduler.NewWithPriorities(m.queued, m.completedTasks, groupPriorities)
-	} else {
-		sched = scheduler.New(m.queued, m.completedTasks)
-	}
+	sched := m.newScheduler()
 	readyTasks := sched.GetReadyTasksExcluding(len(m.queued), inProgress)
+	if warning := cycleWarning(sched); warning != "" {
+		b.WriteString(warningStyle.Render("  " + warning))
+		b.WriteString("\n")
+	}
 
 	// Build a set of ready task IDs for quick lookup
 	readySet := make(map[string]bool)
@@ -485,30 +611,7 @@ func (m Model) renderQueued() string {
 	// Then show blocked tasks if we have room (only from active tier)
 	if shown < limit {
 		// Determine active tier for filtering blocked tasks
-		activeTier := 0
-		if len(groupPriorities) > 0 {
-			// Find the lowest tier that has incomplete tasks
-			tierHasIncomplete := make(map[int]bool)
-			for _, task := range m.queued {
-				if !m.completedTasks[task.ID.String()] {
-					tier := groupPriorities[task.ID.Module] // Defaults to 0
-					tierHasIncomplete[tier] = true
-				}
-			}
-			// Find max tier to bound the search
-			maxTier := 0
-			for tier := range tierHasIncomplete {
-				if tier > maxTier {
-					maxTier = tier
-				}
-			}
-			for tier := 0; tier <= maxTier; tier++ {
-				if tierHasIncomplete[tier] {
-					activeTier = tier
-					break
-				}
-			}
-		}
+		activeTier := sched.ActiveTier()
 
 		for _, task := range m.queued {
 			if shown >= limit {
@@ -518,11 +621,8 @@ func (m Model) renderQueued() string {
 				continue // Already shown above
 			}
 			// Skip tasks not in active tier
-			if len(groupPriorities) > 0 {
-				taskTier := groupPriorities[task.ID.Module]
-				if taskTier > activeTier {
-					continue
-				}
+			if sched.HasGroupPriorities() && sched.TierOf(task.ID.Module) > activeTier {
+				continue
 			}
 			// Find what's blocking this task
 			blocking := ""
@@ -569,11 +669,187 @@ func (m Model) renderAttention() string {
 	return strings.TrimSuffix(b.String(), "\n")
 }
 
+// DashboardLayout selects how the Dashboard arranges its sections
+type DashboardLayout int
+
+const (
+	LayoutAuto    DashboardLayout = iota // Compact on small terminals, full otherwise
+	LayoutCompact                        // Always compact
+	LayoutFull                           // Always full
+)
+
+// String names the layout in the status bar
+func (l DashboardLayout) String() string {
+	switch l {
+	case LayoutCompact:
+		return "compact"
+	case LayoutFull:
+		return "full"
+	default:
+		return "auto"
+	}
+}
+
+// next returns the layout the toggle key switches to
+func (l DashboardLayout) next() DashboardLayout {
+	return (l + 1) % 3
+}
+
+// Terminals narrower or shorter than this get the compact Dashboard in LayoutAuto
+const (
+	compactDashboardWidth  = 80
+	compactDashboardHeight = 30
+)
+
+// useCompactDashboard reports whether the Dashboard is rendered compact for
+// the chosen layout and terminal size
+func useCompactDashboard(layout DashboardLayout, width, height int) bool {
+	switch layout {
+	case LayoutCompact:
+		return true
+	case LayoutFull:
+		return false
+	default:
+		return width < compactDashboardWidth || height < compactDashboardHeight
+	}
+}
+
+// compactDashboard reports whether the Dashboard is currently rendered compact
+func (m Model) compactDashboard() bool {
+	return useCompactDashboard(m.dashboardLayout, m.width, m.height)
+}
+
+// renderCompactDashboard renders running agents, the build pool, ready tasks
+// and flagged PRs as one dense section that fits the terminal height
+func (m Model) renderCompactDashboard() string {
+	width := max(m.width-7, 20)  // Inside the section border and padding, after the marker
+	budget := max(m.height-6, 6) // Without header, tabs, section border, status line and bar
+
+	var running []string
+	var runningStyles []lipgloss.Style
+	inProgress := make(map[string]bool)
+	for _, agent := range m.agents {
+		switch agent.Status {
+		case executor.AgentRunning:
+			inProgress[agent.TaskID] = true
+			running = append(running, " ● "+truncate(fmt.Sprintf("%s %s %s", agent.TaskID, formatDuration(agent.Duration), agent.Progress), width))
+			runningStyles = append(runningStyles, runningStyle)
+		case executor.AgentStuck:
+			inProgress[agent.TaskID] = true
+			running = append(running, " ⚠ "+truncate(fmt.Sprintf("%s %s %s", agent.TaskID, formatDuration(agent.Duration), silentFor(agent)), width))
+			runningStyles = append(runningStyles, warningStyle)
+		case executor.AgentFailed:
+			errMsg := agent.Error
+			if errMsg == "" {
+				errMsg = "unknown error"
+			}
+			running = append(running, " ✗ "+truncate(fmt.Sprintf("%s %s", agent.TaskID, errMsg), width))
+			runningStyles = append(runningStyles, warningStyle)
+		}
+	}
+
+	sched := m.newScheduler()
+	ready := sched.GetReadyTasksExcluding(len(m.queued), inProgress)
+	var queued []string
+	for _, task := range ready {
+		queued = append(queued, " ○ "+truncate(fmt.Sprintf("%s %s", task.ID, task.Title), width))
+	}
+
+	var attention []string
+	if warning := cycleWarning(sched); warning != "" {
+		attention = append(attention, " ⚠ "+truncate(strings.TrimPrefix(warning, "⚠ "), width))
+	}
+	for _, pr := range m.flagged {
+		attention = append(attention, " ⚠ "+truncate(fmt.Sprintf("%s PR #%d %s", pr.TaskID, pr.PRNumber, pr.Reason), width))
+	}
+
+	// Every section has a title line, the build pool only that; the rest of
+	// the budget goes to attention, then running agents, then queued tasks
+	items := budget - 3
+	if len(attention) > 0 {
+		items--
+	}
+	attention = limitLines(attention, max(items/4, 1))
+	items -= len(attention)
+	running = limitLines(running, max(items/2, 1))
+	items -= len(running)
+	queued = limitLines(queued, max(items, 1))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("RUNNING (%d)", len(inProgress))))
+	b.WriteString("\n")
+	for i, line := range running {
+		style := queuedStyle
+		if i < len(runningStyles) && !strings.HasPrefix(line, " +") {
+			style = runningStyles[i]
+		}
+		b.WriteString(style.Render(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(titleStyle.Render("BUILD POOL"))
+	b.WriteString(queuedStyle.Render(m.compactBuildPoolStatus()))
+	b.WriteString("\n")
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("QUEUED (%d ready of %d)", len(ready), len(m.queued))))
+	b.WriteString("\n")
+	for _, line := range queued {
+		b.WriteString(queuedStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	if len(attention) > 0 {
+		b.WriteString(titleStyle.Render(fmt.Sprintf("NEEDS ATTENTION (%d)", len(m.flagged))))
+		b.WriteString("\n")
+		for _, line := range attention {
+			b.WriteString(warningStyle.Render(line))
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// compactBuildPoolStatus summarizes the build pool on one line
+func (m Model) compactBuildPoolStatus() string {
+	switch m.buildPoolStatus {
+	case "disabled":
+		return "off"
+	case "unreachable":
+		return "coordinator not running"
+	case "connected":
+		activeJobs, maxJobs := 0, 0
+		for _, w := range m.workers {
+			activeJobs += w.ActiveJobs
+			maxJobs += w.MaxJobs
+		}
+		return fmt.Sprintf("%d worker(s), %d/%d jobs", len(m.workers), activeJobs, maxJobs)
+	}
+	return "-"
+}
+
+// limitLines keeps at most n lines, replacing the last one kept with a count
+// of the lines left out
+func limitLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+	if n <= 1 {
+		return []string{fmt.Sprintf(" +%d more", len(lines))}
+	}
+	return append(lines[:n-1:n-1], fmt.Sprintf(" +%d more", len(lines)-n+1))
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
 	}
-	return s[:max-3] + "..."
+	// Cut at a character boundary so multi-byte characters are not split
+	cut := max - 3
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
 }
 
 func formatDuration(d time.Duration) string {
@@ -581,6 +857,42 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm", m)
 }
 
+// sparkBlocks are the sparkline levels, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// renderSparkline draws one block per count, scaled to the largest count.
+// Zero gets the lowest block and any other count at least the second lowest,
+// so intervals with some progress stand out.
+func renderSparkline(counts []int) string {
+	highest := 0
+	for _, n := range counts {
+		highest = max(highest, n)
+	}
+
+	spark := make([]rune, len(counts))
+	for i, n := range counts {
+		level := 0
+		if n > 0 {
+			// Round up so every nonzero count is above the lowest block
+			top := len(sparkBlocks) - 1
+			level = (n*top + highest - 1) / highest
+		}
+		spark[i] = sparkBlocks[level]
+	}
+	return string(spark)
+}
+
+// retryLabel describes the automatic resume scheduled for a failed agent
+func retryLabel(agent *AgentView) string {
+	wait := max(time.Until(agent.RetryAt), 0).Round(time.Second)
+	return fmt.Sprintf("retry #%d in %s", agent.RetryAttempt, wait)
+}
+
+// silentFor describes how long an agent has gone without output
+func silentFor(agent *AgentView) string {
+	return "silent " + formatDuration(agent.Idle)
+}
+
 func formatTokens(n int) string {
 	if n >= 1000000 {
 		return fmt.Sprintf("%.1fM", float64(n)/1000000)
@@ -609,17 +921,24 @@ func (m Model) renderTabs() string {
 func (m Model) renderTasks() string {
 	var b strings.Builder
 
-	if m.viewMode == ViewByPriority {
-		b.WriteString(titleStyle.Render("TASKS (by priority)"))
-	} else {
-		b.WriteString(titleStyle.Render("TASKS (by module)"))
+	title := "TASKS (by priority"
+	if m.viewMode == ViewByModule {
+		title = "TASKS (by module"
 	}
+	if m.unblockingSoon {
+		title += ", unblocking soon"
+	}
+	b.WriteString(titleStyle.Render(title + ")"))
 	b.WriteString("\n")
 
 	if len(m.allTasks) == 0 {
 		b.WriteString(queuedStyle.Render("  No tasks found. Run 'claude-orch sync' to load tasks."))
 		return b.String()
 	}
+	if m.unblockingSoon && len(m.visibleTasks()) == 0 {
+		b.WriteString(queuedStyle.Render("  No tasks are waiting only on in-progress work. Press f to show all tasks."))
+		return b.String()
+	}
 
 	if m.viewMode == ViewByPriority {
 		b.WriteString(m.renderTasksByPriority())
@@ -630,12 +949,29 @@ func (m Model) renderTasks() string {
 	return strings.TrimSuffix(b.String(), "\n")
 }
 
-func (m Model) renderTasksByPriority() string {
-	var b strings.Builder
+// visibleTasks returns the tasks shown on the Tasks tab: all tasks, or with
+// the "unblocking soon" filter on, only those blocked solely by dependencies
+// that are currently running
+func (m Model) visibleTasks() []*domain.Task {
+	if !m.unblockingSoon {
+		return m.allTasks
+	}
+
+	inProgress := make(map[string]bool)
+	for _, a := range m.agents {
+		if a.Status.Active() {
+			inProgress[a.TaskID] = true
+		}
+	}
+	return scheduler.New(m.allTasks, m.completedTasks).UnblockingSoon(inProgress)
+}
 
-	// Sort tasks by priority (high -> normal -> low), then by module/epic
-	tasks := make([]*domain.Task, len(m.allTasks))
-	copy(tasks, m.allTasks)
+// tasksByPriority returns all visible tasks sorted by priority (high -> normal -> low),
+// then by module/epic
+func (m Model) tasksByPriority() []*domain.Task {
+	visible := m.visibleTasks()
+	tasks := make([]*domain.Task, len(visible))
+	copy(tasks, visible)
 	sort.Slice(tasks, func(i, j int) bool {
 		if tasks[i].Priority != tasks[j].Priority {
 			return tasks[i].Priority < tasks[j].Priority // Lower enum = higher priority
@@ -645,6 +981,69 @@ func (m Model) renderTasksByPriority() string {
 		}
 		return tasks[i].ID.EpicNum < tasks[j].ID.EpicNum
 	})
+	return tasks
+}
+
+// tasksByModule returns sorted module names and each module's tasks by epic number
+func (m Model) tasksByModule() ([]string, map[string][]*domain.Task) {
+	// Group tasks by module
+	modules := make(map[string][]*domain.Task)
+	var moduleOrder []string
+
+	for _, task := range m.visibleTasks() {
+		mod := task.ID.Module
+		if _, exists := modules[mod]; !exists {
+			moduleOrder = append(moduleOrder, mod)
+		}
+		modules[mod] = append(modules[mod], task)
+	}
+
+	// Sort module names
+	sort.Strings(moduleOrder)
+
+	// Sort tasks within each module by epic number
+	for _, tasks := range modules {
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].ID.EpicNum < tasks[j].ID.EpicNum
+		})
+	}
+
+	return moduleOrder, modules
+}
+
+// selectedTask returns the task under the cursor on the Tasks tab, which is
+// the first task row at the current scroll position (nil if none)
+func (m Model) selectedTask() *domain.Task {
+	if m.viewMode == ViewByPriority {
+		tasks := m.tasksByPriority()
+		if len(tasks) == 0 {
+			return nil
+		}
+		start := m.taskScroll
+		if start >= len(tasks) {
+			start = 0
+		}
+		return tasks[start]
+	}
+
+	moduleOrder, modules := m.tasksByModule()
+	lineCount := 0
+	for _, mod := range moduleOrder {
+		lineCount++ // Module header
+		for _, task := range modules[mod] {
+			if lineCount >= m.taskScroll {
+				return task
+			}
+			lineCount++
+		}
+	}
+	return nil
+}
+
+func (m Model) renderTasksByPriority() string {
+	var b strings.Builder
+
+	tasks := m.tasksByPriority()
 
 	// Calculate visible range
 	maxVisible := 15
@@ -659,7 +1058,7 @@ func (m Model) renderTasksByPriority() string {
 
 	for i := start; i < end; i++ {
 		task := tasks[i]
-		line := m.formatTaskLine(task)
+		line := m.formatTaskLine(task, i == start)
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
@@ -675,27 +1074,8 @@ func (m Model) renderTasksByPriority() string {
 func (m Model) renderTasksByModule() string {
 	var b strings.Builder
 
-	// Group tasks by module
-	modules := make(map[string][]*domain.Task)
-	var moduleOrder []string
-
-	for _, task := range m.allTasks {
-		mod := task.ID.Module
-		if _, exists := modules[mod]; !exists {
-			moduleOrder = append(moduleOrder, mod)
-		}
-		modules[mod] = append(modules[mod], task)
-	}
-
-	// Sort module names
-	sort.Strings(moduleOrder)
-
-	// Sort tasks within each module by epic number
-	for _, tasks := range modules {
-		sort.Slice(tasks, func(i, j int) bool {
-			return tasks[i].ID.EpicNum < tasks[j].ID.EpicNum
-		})
-	}
+	moduleOrder, modules := m.tasksByModule()
+	selected := m.selectedTask()
 
 	lineCount := 0
 	maxVisible := 15
@@ -713,8 +1093,12 @@ func (m Model) renderTasksByModule() string {
 
 		for _, task := range tasks {
 			if lineCount >= start && lineCount < start+maxVisible {
-				line := m.formatTaskLine(task)
-				b.WriteString("  │" + line[2:])
+				line := m.formatTaskLine(task, false)
+				prefix := "  │"
+				if task == selected {
+					prefix = "> │"
+				}
+				b.WriteString(prefix + line[2:])
 				b.WriteString("\n")
 			}
 			lineCount++
@@ -729,7 +1113,7 @@ func (m Model) renderTasksByModule() string {
 	return b.String()
 }
 
-func (m Model) formatTaskLine(task *domain.Task) string {
+func (m Model) formatTaskLine(task *domain.Task, selected bool) string {
 	// Status icon
 	var statusIcon string
 	var style lipgloss.Style
@@ -762,6 +1146,12 @@ func (m Model) formatTaskLine(task *domain.Task) string {
 	default:
 		prioStr = " "
 	}
+	if _, promoted := m.taskOverrides[task.ID.String()]; promoted {
+		prioStr = "↑"
+	}
+	if m.markedTasks[task.ID.String()] {
+		statusIcon = "◉"
+	}
 
 	// Issue indicator
 	var issueStr string
@@ -772,6 +1162,11 @@ func (m Model) formatTaskLine(task *domain.Task) string {
 	line := fmt.Sprintf("  %s %s %-15s%-4s %-30s",
 		statusIcon, prioStr, task.ID.String(), issueStr, truncate(task.Title, 30))
 
+	// Highlight selected task
+	if selected {
+		line = fmt.Sprintf("> %s", line[2:])
+		return tabActiveStyle.Render(line)
+	}
 	return style.Render(line)
 }
 
@@ -809,6 +1204,7 @@ func (m Model) renderAgentsDetail() string {
 		b.WriteString(queuedStyle.Render("  No agents. Press [s] on Dashboard to start a batch."))
 		b.WriteString("\n")
 	} else {
+		perModule := runningAgentsPerModule(m.agents)
 		for i, agent := range m.agents {
 			var statusIcon string
 			var style lipgloss.Style
@@ -825,6 +1221,9 @@ func (m Model) renderAgentsDetail() string {
 			case executor.AgentStuck:
 				statusIcon = "⚠"
 				style = warningStyle
+			case executor.AgentCancelled:
+				statusIcon = "⊘"
+				style = queuedStyle
 			default:
 				statusIcon = "○"
 				style = queuedStyle
@@ -832,13 +1231,21 @@ func (m Model) renderAgentsDetail() string {
 
 			// Show error preview for failed agents
 			extra := agent.Progress
-			if agent.Status == executor.AgentFailed && agent.Error != "" {
+			if agent.Status == executor.AgentFailed && !agent.RetryAt.IsZero() {
+				extra = retryLabel(agent)
+			} else if agent.Status == executor.AgentFailed && agent.Error != "" {
 				extra = truncate(agent.Error, 25)
+			} else if agent.Status == executor.AgentStuck {
+				extra = silentFor(agent)
 			}
 
 			line := fmt.Sprintf("  %s %-15s %-20s %8s  %s",
 				statusIcon, agent.TaskID, truncate(agent.Title, 20),
 				formatDuration(agent.Duration), extra)
+			if sharesModule(agent, perModule) {
+				line += " ⚠"
+				style = warningStyle
+			}
 
 			// Highlight selected agent
 			if i == m.selectedAgent {
@@ -851,6 +1258,11 @@ func (m Model) renderAgentsDetail() string {
 		}
 	}
 
+	if warning := moduleConcurrencyWarning(runningAgentsPerModule(m.agents)); warning != "" {
+		b.WriteString(warningStyle.Render("  " + warning))
+		b.WriteString("\n")
+	}
+
 	// Slots available
 	slotsAvailable := m.maxActive - m.activeCount
 	if slotsAvailable > 0 && len(m.agents) > 0 {
@@ -882,6 +1294,9 @@ func (m Model) renderAgentsDetail() string {
 				case executor.AgentFailed:
 					statusIcon = "✗"
 					style = dimmedWarningStyle
+				case executor.AgentCancelled:
+					statusIcon = "⊘"
+					style = dimmedStyle
 				default:
 					statusIcon = "○"
 					style = dimmedStyle
@@ -942,8 +1357,11 @@ func (m Model) renderSelectedAgentDetail() string {
 		statusStr = "Failed"
 		style = warningStyle
 	case executor.AgentStuck:
-		statusStr = "Stuck"
+		statusStr = "Stuck (" + silentFor(agent) + ")"
 		style = warningStyle
+	case executor.AgentCancelled:
+		statusStr = "Cancelled"
+		style = queuedStyle
 	case executor.AgentQueued:
 		statusStr = "Queued"
 		style = queuedStyle
@@ -955,10 +1373,20 @@ func (m Model) renderSelectedAgentDetail() string {
 	b.WriteString(fmt.Sprintf("  Status:   %s\n", style.Render(statusStr)))
 	b.WriteString(fmt.Sprintf("  Task:     %s\n", agent.Title))
 	b.WriteString(fmt.Sprintf("  Duration: %s\n", formatDuration(agent.Duration)))
+	if agent.Status.Active() {
+		b.WriteString(fmt.Sprintf("  Output:   last line %s ago\n", formatDuration(agent.Idle)))
+	}
+	if !agent.RetryAt.IsZero() {
+		b.WriteString(fmt.Sprintf("  Next:     %s\n", retryLabel(agent)))
+	}
 	if agent.WorktreePath != "" {
 		b.WriteString(fmt.Sprintf("  Worktree: %s\n", agent.WorktreePath))
 	}
 
+	if agent.Resources != nil {
+		b.WriteString(fmt.Sprintf("  Process:  %s\n", agent.Resources.String()))
+	}
+
 	// Show token usage if available
 	if agent.TokensInput > 0 || agent.TokensOutput > 0 {
 		b.WriteString(fmt.Sprintf("  Tokens:   %s in / %s out",
@@ -1059,7 +1487,7 @@ func (m Model) renderSelectedAgentDetail() string {
 	} else if len(agent.Output) > 0 {
 		// Show output
 		// Format the JSON output into readable lines
-		formattedLines := formatClaudeOutput(agent.Output, maxWidth)
+		formattedLines := formatClaudeOutput(m.visibleOutput(agent.Output), maxWidth, m.outputFilter)
 
 		totalLines := len(formattedLines)
 		scroll := m.agentOutputScroll
@@ -1082,7 +1510,7 @@ func (m Model) renderSelectedAgentDetail() string {
 		if totalLines > maxLines {
 			scrollInfo = fmt.Sprintf(" [%d-%d of %d]", scroll+1, end, totalLines)
 		}
-		b.WriteString(titleStyle.Render(fmt.Sprintf("  OUTPUT%s:", scrollInfo)))
+		b.WriteString(titleStyle.Render(fmt.Sprintf("  OUTPUT%s%s:", m.outputLabel(), scrollInfo)))
 		b.WriteString("\n")
 
 		// Show scroll indicator at top
@@ -1093,7 +1521,11 @@ func (m Model) renderSelectedAgentDetail() string {
 
 		// Show visible lines
 		for i := scroll; i < end; i++ {
-			b.WriteString(queuedStyle.Render(fmt.Sprintf("  %s", formattedLines[i])))
+			style := queuedStyle
+			if executor.IsStderrLine(formattedLines[i]) {
+				style = warningStyle
+			}
+			b.WriteString(style.Render(fmt.Sprintf("  %s", formattedLines[i])))
 			b.WriteString("\n")
 		}
 
@@ -1112,7 +1544,7 @@ func (m Model) renderSelectedAgentDetail() string {
 	if m.showAgentPrompt {
 		promptHint = "[p]output"
 	}
-	b.WriteString(queuedStyle.Render(fmt.Sprintf("  [j/k]scroll [g]top [G]bottom %s [esc]back", promptHint)))
+	b.WriteString(queuedStyle.Render(fmt.Sprintf("  [j/k]scroll [g]top [G]bottom %s %s [esc]back", promptHint, m.stderrHint())))
 
 	return strings.TrimSuffix(b.String(), "\n")
 }
@@ -1134,6 +1566,9 @@ func (m Model) renderSelectedHistoryDetail() string {
 	case executor.AgentFailed:
 		statusStr = "Failed"
 		style = warningStyle
+	case executor.AgentCancelled:
+		statusStr = "Cancelled"
+		style = queuedStyle
 	default:
 		statusStr = string(agent.Status)
 		style = queuedStyle
@@ -1159,6 +1594,14 @@ func (m Model) renderSelectedHistoryDetail() string {
 		b.WriteString("\n")
 	}
 
+	// Notes added after the run
+	if agent.Notes != "" {
+		b.WriteString("\n  Notes:\n")
+		for _, note := range strings.Split(agent.Notes, "\n") {
+			b.WriteString(fmt.Sprintf("  - %s\n", note))
+		}
+	}
+
 	// Error section
 	if agent.Error != "" {
 		b.WriteString("\n")
@@ -1184,7 +1627,7 @@ func (m Model) renderSelectedHistoryDetail() string {
 
 	if len(agent.Output) > 0 {
 		// Format the JSON output into readable lines
-		formattedLines := formatClaudeOutput(agent.Output, maxWidth)
+		formattedLines := formatClaudeOutput(m.visibleOutput(agent.Output), maxWidth, m.outputFilter)
 
 		totalLines := len(formattedLines)
 		scroll := m.agentOutputScroll
@@ -1207,7 +1650,7 @@ func (m Model) renderSelectedHistoryDetail() string {
 		if totalLines > maxLines {
 			scrollInfo = fmt.Sprintf(" [%d-%d of %d]", scroll+1, end, totalLines)
 		}
-		b.WriteString(titleStyle.Render(fmt.Sprintf("  LOG OUTPUT%s:", scrollInfo)))
+		b.WriteString(titleStyle.Render(fmt.Sprintf("  LOG OUTPUT%s%s:", m.outputLabel(), scrollInfo)))
 		b.WriteString("\n")
 
 		// Show scroll indicator at top
@@ -1218,7 +1661,11 @@ func (m Model) renderSelectedHistoryDetail() string {
 
 		// Show visible lines
 		for i := scroll; i < end; i++ {
-			b.WriteString(queuedStyle.Render(fmt.Sprintf("  %s", formattedLines[i])))
+			style := queuedStyle
+			if executor.IsStderrLine(formattedLines[i]) {
+				style = warningStyle
+			}
+			b.WriteString(style.Render(fmt.Sprintf("  %s", formattedLines[i])))
 			b.WriteString("\n")
 		}
 
@@ -1237,7 +1684,7 @@ func (m Model) renderSelectedHistoryDetail() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(queuedStyle.Render("  [j/k]scroll [g]top [G]bottom [esc]back"))
+	b.WriteString(queuedStyle.Render(fmt.Sprintf("  [j/k]scroll [g]top [G]bottom %s [esc]back", m.stderrHint())))
 
 	return strings.TrimSuffix(b.String(), "\n")
 }
@@ -1341,8 +1788,93 @@ func parseToolResultText(text string, maxLen int) string {
 	return truncate(strings.TrimSpace(text), maxLen)
 }
 
-// formatClaudeOutput parses JSON stream lines and formats them for display
-func formatClaudeOutput(lines []string, maxWidth int) []string {
+// visibleOutput returns the agent output lines to display, honoring the stderr-only filter
+func (m Model) visibleOutput(lines []string) []string {
+	if !m.showStderrOnly {
+		return lines
+	}
+	var result []string
+	for _, line := range lines {
+		if executor.IsStderrLine(line) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// outputLabel marks output headers while the stderr-only or output filter is active
+func (m Model) outputLabel() string {
+	var filters []string
+	if m.showStderrOnly {
+		filters = append(filters, "stderr only")
+	}
+	if m.outputFilter != OutputAll {
+		filters = append(filters, m.outputFilter.label())
+	}
+	if len(filters) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(filters, ", ") + ")"
+}
+
+// stderrHint returns the footer hint for the stderr-only and output filter toggles
+func (m Model) stderrHint() string {
+	hint := "[e]stderr only"
+	if m.showStderrOnly {
+		hint = "[e]all output"
+	}
+	return fmt.Sprintf("%s [F]ilter: %s", hint, m.outputFilter.label())
+}
+
+// OutputFilter selects which parts of an agent's stream-json output are shown
+type OutputFilter string
+
+const (
+	OutputAll             OutputFilter = "all"
+	OutputHideToolResults OutputFilter = "hide_tool_results" // Assistant text and tool invocations only
+	OutputToolResultsOnly OutputFilter = "tool_results_only" // Tool results only
+)
+
+// ParseOutputFilter parses a configured output filter ("" means all output)
+func ParseOutputFilter(s string) (OutputFilter, error) {
+	switch OutputFilter(s) {
+	case "", OutputAll:
+		return OutputAll, nil
+	case OutputHideToolResults, OutputToolResultsOnly:
+		return OutputFilter(s), nil
+	default:
+		return "", fmt.Errorf("invalid agent output filter '%s': must be '%s', '%s' or '%s'", s, OutputAll, OutputHideToolResults, OutputToolResultsOnly)
+	}
+}
+
+// next returns the filter the toggle key switches to
+func (f OutputFilter) next() OutputFilter {
+	switch f {
+	case OutputAll:
+		return OutputHideToolResults
+	case OutputHideToolResults:
+		return OutputToolResultsOnly
+	default:
+		return OutputAll
+	}
+}
+
+// label describes the filter in output headers and hints
+func (f OutputFilter) label() string {
+	switch f {
+	case OutputHideToolResults:
+		return "no tool results"
+	case OutputToolResultsOnly:
+		return "tool results only"
+	default:
+		return "all"
+	}
+}
+
+// formatClaudeOutput parses JSON stream lines and formats them for display.
+// filter hides assistant text and tool invocations or tool results; session
+// starts and non-JSON lines are always shown.
+func formatClaudeOutput(lines []string, maxWidth int, filter OutputFilter) []string {
 	var result []string
 
 	for _, line := range lines {
@@ -1353,10 +1885,7 @@ func formatClaudeOutput(lines []string, maxWidth int) []string {
 		var msg claudeStreamMessage
 		if err := json.Unmarshal([]byte(line), &msg); err != nil {
 			// Not valid JSON, show truncated raw line
-			if len(line) > maxWidth-4 {
-				line = line[:maxWidth-7] + "..."
-			}
-			result = append(result, line)
+			result = append(result, truncate(line, maxWidth-4))
 			continue
 		}
 
@@ -1372,17 +1901,18 @@ func formatClaudeOutput(lines []string, maxWidth int) []string {
 			for _, content := range msg.Message.Content {
 				switch content.Type {
 				case "text":
+					if filter == OutputToolResultsOnly {
+						continue
+					}
 					// Split text into lines and add each
 					textLines := strings.Split(content.Text, "\n")
 					for _, tl := range textLines {
-						tl = strings.TrimSpace(tl)
+						// Decoded text may hold escaped control characters
+						tl = executor.SanitizeOutputLine(strings.TrimSpace(tl))
 						if tl == "" {
 							continue
 						}
-						if len(tl) > maxWidth-4 {
-							tl = tl[:maxWidth-7] + "..."
-						}
-						result = append(result, tl)
+						result = append(result, truncate(tl, maxWidth-4))
 					}
 				case "tool_use":
 					// Show tool being used and remember it
@@ -1421,14 +1951,16 @@ func formatClaudeOutput(lines []string, maxWidth int) []string {
 
 					// Store formatted name for result display
 					lastToolUsed = toolName
-					result = append(result, toolLine)
+					if filter != OutputToolResultsOnly {
+						result = append(result, toolLine)
+					}
 				}
 			}
 
 		case "user":
 			// Tool results - show which tool completed
 			for _, content := range msg.Message.Content {
-				if content.Type == "tool_result" {
+				if content.Type == "tool_result" && filter != OutputHideToolResults {
 					resultLine := ""
 					if lastToolUsed != "" {
 						if lastToolDetail != "" {
@@ -1489,6 +2021,22 @@ func (m Model) renderModules() string {
 		b.WriteString("\n")
 	}
 
+	// Show what the last sync changed if expanded
+	if m.showChanges && len(m.syncChanges) > 0 {
+		b.WriteString(queuedStyle.Render("  Changes from last sync ([S] or [esc] to hide):"))
+		b.WriteString("\n")
+		lines := formatSyncChanges(m.syncChanges)
+		if len(lines) > maxSyncChangeLines {
+			more := len(lines) - maxSyncChangeLines
+			lines = append(lines[:maxSyncChangeLines], fmt.Sprintf("... and %d more", more))
+		}
+		for _, line := range lines {
+			b.WriteString(queuedStyle.Render("  " + line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Header row
 	header := fmt.Sprintf("  %-20s %8s %8s %8s %8s %8s %8s",
 		"Module", "Epics", "Done", "InProg", "Tests", "Passed", "Failed")
@@ -1594,13 +2142,63 @@ func (m Model) renderWorkers() string {
 			b.WriteString(queuedStyle.Render(fmt.Sprintf("  Coordinator running, %d worker(s):", len(m.workers))))
 			b.WriteString("\n")
 			for _, w := range m.workers {
-				b.WriteString(queuedStyle.Render(fmt.Sprintf("    %s: %d/%d jobs",
-					w.ID, w.ActiveJobs, w.MaxJobs)))
+				b.WriteString(queuedStyle.Render(fmt.Sprintf("    %s: %d/%d jobs%s",
+					w.ID, w.ActiveJobs, w.MaxJobs, workerTags(w))))
 				b.WriteString("\n")
 			}
-			return strings.TrimSuffix(b.String(), "\n")
 		}
 	}
+	return strings.TrimSuffix(b.String(), "\n") + m.renderCoordinators()
+}
+
+// workerTags formats a worker's tags as a suffix for its line in the workers
+// list, e.g. " [gpu, highmem]"
+func workerTags(w *WorkerView) string {
+	if len(w.Tags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(w.Tags, ", ") + "]"
+}
+
+// renderCoordinators lists the workers of the additional coordinators, each
+// labeled with its name, followed by the totals across all coordinators
+func (m Model) renderCoordinators() string {
+	if len(m.coordinatorStatuses) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	totalWorkers, activeJobs, maxJobs := 0, 0, 0
+	if m.buildPoolStatus == "connected" {
+		for _, w := range m.workers {
+			totalWorkers++
+			activeJobs += w.ActiveJobs
+			maxJobs += w.MaxJobs
+		}
+	}
+
+	for _, c := range m.coordinatorStatuses {
+		label := c.URL
+		if c.Name != "" && c.Name != c.URL {
+			label = fmt.Sprintf("%s (%s)", c.Name, c.URL)
+		}
+		b.WriteString("\n")
+		if c.Status != "connected" {
+			b.WriteString(queuedStyle.Render(fmt.Sprintf("  %s: unreachable", label)))
+			continue
+		}
+		b.WriteString(queuedStyle.Render(fmt.Sprintf("  %s: %d worker(s)", label, len(c.Workers))))
+		for _, w := range c.Workers {
+			b.WriteString("\n")
+			b.WriteString(queuedStyle.Render(fmt.Sprintf("    %s: %d/%d jobs%s", w.ID, w.ActiveJobs, w.MaxJobs, workerTags(w))))
+			totalWorkers++
+			activeJobs += w.ActiveJobs
+			maxJobs += w.MaxJobs
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(queuedStyle.Render(fmt.Sprintf("  Total: %d worker(s), %d/%d jobs", totalWorkers, activeJobs, maxJobs)))
 	return b.String()
 }
 
@@ -1629,6 +2227,35 @@ var (
 		Bold(true)
 )
 
+// maxSyncChangeLines caps the sync changes panel on the Modules tab
+const maxSyncChangeLines = 15
+
+// formatSyncChanges lists sync changes grouped by direction, one line per
+// task with the fields that changed
+func formatSyncChanges(changes []isync.SyncChange) []string {
+	width := 0
+	for _, c := range changes {
+		if len(c.TaskID) > width {
+			width = len(c.TaskID)
+		}
+	}
+
+	var lines []string
+	for _, dir := range []isync.SyncDirection{isync.MarkdownToDB, isync.DBToMarkdown} {
+		var group []string
+		for _, c := range changes {
+			if c.Direction == dir {
+				group = append(group, fmt.Sprintf("  %-*s  %s", width, c.TaskID, strings.Join(c.Fields, ", ")))
+			}
+		}
+		if len(group) > 0 {
+			lines = append(lines, fmt.Sprintf("%s (%d)", dir, len(group)))
+			lines = append(lines, group...)
+		}
+	}
+	return lines
+}
+
 // renderSyncModal renders the sync conflict resolution modal
 func (m Model) renderSyncModal() string {
 	if !m.syncModal.Visible || len(m.syncModal.Conflicts) == 0 {
@@ -1653,7 +2280,9 @@ func (m Model) renderSyncModal() string {
 	b.WriteString(warningStyle.Render("[m]"))
 	b.WriteString(queuedStyle.Render(" use Markdown status  "))
 	b.WriteString(runningStyle.Render("[a]"))
-	b.WriteString(queuedStyle.Render(" all use DB"))
+	b.WriteString(queuedStyle.Render(" all use DB  "))
+	b.WriteString(runningStyle.Render("[n]"))
+	b.WriteString(queuedStyle.Render(" newest wins"))
 	b.WriteString("\n")
 	b.WriteString(queuedStyle.Render("[j/k] navigate  [enter] apply  [esc] cancel"))
 	b.WriteString("\n\n")
@@ -1744,6 +2373,38 @@ func renderConflictLine(conflict isync.SyncConflict, resolution string, selected
 	return queuedStyle.Render("  " + line)
 }
 
+// renderEvents renders the events log, newest first
+func (m Model) renderEvents() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("EVENTS (%d)", len(m.events))))
+	b.WriteString("\n\n")
+
+	if len(m.events) == 0 {
+		b.WriteString(queuedStyle.Render("  No warnings or errors"))
+		return b.String()
+	}
+
+	width := max(m.width-20, 20) // Inside the section, after time and icon
+	visible := max(m.height-8, 5)
+	shown := 0
+	for i := len(m.events) - 1 - m.eventsScroll; i >= 0 && shown < visible; i-- {
+		event := m.events[i]
+		icon, style := "⚠", warningStyle
+		if event.Level == EventError {
+			icon = "✗"
+		} else {
+			style = dimmedWarningStyle
+		}
+		b.WriteString(style.Render(fmt.Sprintf("  %s %s %s", event.Time.Format("15:04:05"), icon, truncate(event.Message, width))))
+		b.WriteString("\n")
+		shown++
+	}
+	if older := len(m.events) - m.eventsScroll - shown; older > 0 {
+		b.WriteString(queuedStyle.Render(fmt.Sprintf("  ... %d older", older)))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 func (m Model) renderGroupPriorities() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("GROUP PRIORITIES"))
@@ -1760,7 +2421,7 @@ func (m Model) renderGroupPriorities() string {
 	for _, item := range m.groupPriorityItems {
 		tier := item.Priority
 		if tier < 0 {
-			tier = 0 // Unassigned defaults to tier 0 for display
+			tier = m.defaultGroupTier // Unassigned runs with the default tier
 		}
 		tiers[tier] = append(tiers[tier], item)
 		if tier > maxTier {
@@ -1792,8 +2453,8 @@ foundActive:
 	itemIndex := 0
 	for tier := 0; tier <= maxTier; tier++ {
 		items := tiers[tier]
-		// Filter out unassigned items from tier 0 display (they go in separate section)
-		if tier == 0 {
+		// Filter out unassigned items from the default tier display (they go in separate section)
+		if tier == m.defaultGroupTier {
 			var assigned []GroupPriorityItem
 			for _, item := range items {
 				if item.Priority >= 0 {
@@ -1838,8 +2499,8 @@ foundActive:
 				style = queuedStyle
 			}
 
-			line := fmt.Sprintf("    %s %-18s [%d/%d complete]",
-				statusIcon, truncate(item.Name, 18), item.Completed, item.Total)
+			line := fmt.Sprintf("    %s %-18s [%d/%d complete]%s",
+				statusIcon, truncate(item.Name, 18), item.Completed, item.Total, weightLabel(item.Weight))
 
 			if selected {
 				line = fmt.Sprintf("  > %s", line[4:])
@@ -1855,7 +2516,7 @@ foundActive:
 
 	// Show unassigned groups section
 	if len(unassignedItems) > 0 {
-		b.WriteString(queuedStyle.Render("  (unassigned - runs with tier 0)"))
+		b.WriteString(queuedStyle.Render(fmt.Sprintf("  (unassigned - runs with tier %d)", m.defaultGroupTier)))
 		b.WriteString("\n")
 		for _, item := range unassignedItems {
 			selected := itemIndex == m.selectedPriorityRow
@@ -1867,8 +2528,8 @@ foundActive:
 				style = completedStyle
 			}
 
-			line := fmt.Sprintf("    %s %-18s [%d/%d complete]",
-				statusIcon, truncate(item.Name, 18), item.Completed, item.Total)
+			line := fmt.Sprintf("    %s %-18s [%d/%d complete]%s",
+				statusIcon, truncate(item.Name, 18), item.Completed, item.Total, weightLabel(item.Weight))
 
 			if selected {
 				line = fmt.Sprintf("  > %s", line[4:])
@@ -1883,11 +2544,19 @@ foundActive:
 
 	// Help text
 	b.WriteString("\n")
-	b.WriteString(queuedStyle.Render("  [↑/↓] select  [+/-] change tier  [u] unassign  [g] back"))
+	b.WriteString(queuedStyle.Render("  [↑/↓] select  [+/-] change tier  [w/W] weight  [u] unassign  [g] back"))
 
 	return b.String()
 }
 
+// weightLabel describes a group's weight within its tier, if it has one
+func weightLabel(weight int) string {
+	if weight == 0 {
+		return ""
+	}
+	return fmt.Sprintf("  weight %d", weight)
+}
+
 // renderMaintenanceModal renders the maintenance task selection modal
 func (m Model) renderMaintenanceModal() string {
 	var b strings.Builder
@@ -1955,3 +2624,127 @@ func (m Model) renderMaintenanceModal() string {
 
 	return modalStyle.Width(modalWidth).Render(modalContent)
 }
+
+// maxBatchConfirmTasks caps the tasks listed in the batch start confirmation
+const maxBatchConfirmTasks = 8
+
+// renderBatchConfirmModal renders the batch about to be started with its
+// estimated token usage and cost
+func (m Model) renderBatchConfirmModal() string {
+	var b strings.Builder
+	modal := m.batchConfirm
+
+	b.WriteString(modalTitleStyle.Render("START BATCH"))
+	b.WriteString("\n\n")
+
+	b.WriteString(queuedStyle.Render(fmt.Sprintf("%d task(s):", len(modal.Tasks))))
+	b.WriteString("\n")
+	for i, task := range modal.Tasks {
+		if i == maxBatchConfirmTasks {
+			b.WriteString(queuedStyle.Render(fmt.Sprintf("  ... and %d more", len(modal.Tasks)-i)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString(queuedStyle.Render("  " + truncate(strings.TrimSpace(fmt.Sprintf("%s  %s", task.ID, task.Title)), 48)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	for _, line := range formatBatchEstimate(modal.Estimate) {
+		b.WriteString(queuedStyle.Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(queuedStyle.Render("[enter] start  [esc] cancel"))
+
+	modalWidth := 55
+	if m.width <= 65 && m.width > 45 {
+		modalWidth = m.width - 10
+	} else if m.width <= 45 {
+		modalWidth = m.width - 4
+	}
+
+	return modalStyle.Width(modalWidth).Render(b.String())
+}
+
+// renderTaskWizardModal renders the task creation form
+func (m Model) renderTaskWizardModal() string {
+	var b strings.Builder
+	w := m.taskWizard
+
+	b.WriteString(modalTitleStyle.Render("NEW TASK"))
+	b.WriteString("\n\n")
+
+	for i, label := range taskWizardLabels {
+		line := fmt.Sprintf("%-12s %s", label+":", w.Fields[i])
+		if i == w.Focus {
+			b.WriteString(modalSelectedStyle.Render("▸ " + line + "_"))
+		} else {
+			b.WriteString(queuedStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dimmedStyle.Render("Priority: high, medium, low or empty"))
+	b.WriteString("\n")
+	b.WriteString(dimmedStyle.Render("Depends on: task IDs or epic numbers, comma separated"))
+	b.WriteString("\n\n")
+
+	if w.Error != "" {
+		b.WriteString(warningStyle.Render("Error: " + w.Error))
+		b.WriteString("\n\n")
+	} else if w.Creating {
+		b.WriteString(runningStyle.Render("Creating..."))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(queuedStyle.Render("[tab/↑↓] field  [enter] create  [esc] cancel"))
+
+	modalWidth := 55
+	if m.width <= 65 && m.width > 45 {
+		modalWidth = m.width - 10
+	} else if m.width <= 45 {
+		modalWidth = m.width - 4
+	}
+
+	return modalStyle.Width(modalWidth).Render(b.String())
+}
+
+// renderCancelConfirmModal asks for confirmation before killing a running agent
+func (m Model) renderCancelConfirmModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("KILL AGENT"))
+	b.WriteString("\n\n")
+	b.WriteString(queuedStyle.Render(fmt.Sprintf("Stop %s?", m.cancelConfirm)))
+	b.WriteString("\n")
+	b.WriteString(queuedStyle.Render("Its process is killed and its worktree removed,"))
+	b.WriteString("\n")
+	b.WriteString(queuedStyle.Render("discarding uncommitted work."))
+	b.WriteString("\n\n")
+	b.WriteString(queuedStyle.Render("[enter] kill  [esc] keep running"))
+
+	modalWidth := 55
+	if m.width <= 65 && m.width > 45 {
+		modalWidth = m.width - 10
+	} else if m.width <= 45 {
+		modalWidth = m.width - 4
+	}
+
+	return modalStyle.Width(modalWidth).Render(b.String())
+}
+
+// formatBatchEstimate describes a batch cost estimate and what it is based on
+func formatBatchEstimate(e taskstore.BatchEstimate) []string {
+	if !e.HasHistory() {
+		return []string{"Estimated cost: unknown (no agent runs with usage yet)"}
+	}
+	lines := []string{
+		fmt.Sprintf("Estimated cost: ~$%.2f (%s in / %s out tokens)", e.CostUSD, formatTokens(e.TokensInput), formatTokens(e.TokensOutput)),
+		fmt.Sprintf("Based on module history (%d)", e.FromModule),
+	}
+	if e.FromOverall > 0 {
+		lines[1] += fmt.Sprintf(", overall average (%d)", e.FromOverall)
+	}
+	return lines
+}
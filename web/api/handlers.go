@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -566,6 +567,14 @@ func (s *Server) mergePRHandler() http.HandlerFunc {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		// The post-merge hook may take minutes, so don't hold up the response
+		task := s.prTask(prNumber)
+		go func() {
+			if err := s.prbot.OnMerge(task, prNumber); err != nil {
+				// Log but don't fail - PR is already merged
+				log.Printf("PR #%d merged, but %v", prNumber, err)
+			}
+		}()
 
 		s.Broadcast(SSEEvent{Type: "pr_update", Data: map[string]interface{}{
 			"pr_number": prNumber,
@@ -576,6 +585,24 @@ func (s *Server) mergePRHandler() http.HandlerFunc {
 	}
 }
 
+// prTask returns the task a flagged PR belongs to (nil if unknown)
+func (s *Server) prTask(prNumber int) *domain.Task {
+	prs, err := s.store.ListFlaggedPRs()
+	if err != nil {
+		return nil
+	}
+	for _, pr := range prs {
+		if pr.PRNumber == prNumber {
+			task, err := s.store.GetTask(pr.TaskID)
+			if err != nil {
+				return nil
+			}
+			return task
+		}
+	}
+	return nil
+}
+
 func (s *Server) listGroupsHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
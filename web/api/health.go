@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// healthCheckTimeout bounds how long /readyz waits for the coordinator
+const healthCheckTimeout = 2 * time.Second
+
+// HealthResponse is the API response for the health probes
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthzHandler reports liveness: the process is up and serving requests
+func (s *Server) healthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeJSON(w, HealthResponse{Status: "ok"})
+	}
+}
+
+// readyzHandler reports readiness: the database is reachable and, if the
+// build pool is enabled, so is the coordinator
+func (s *Server) readyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		resp := HealthResponse{Status: "ok", Checks: make(map[string]string)}
+
+		if err := s.store.Ping(); err != nil {
+			resp.Checks["database"] = err.Error()
+			resp.Status = "degraded"
+		} else {
+			resp.Checks["database"] = "ok"
+		}
+
+		if s.buildPoolURL != "" {
+			if err := checkCoordinator(s.buildPoolURL); err != nil {
+				resp.Checks["coordinator"] = err.Error()
+				resp.Status = "degraded"
+			} else {
+				resp.Checks["coordinator"] = "ok"
+			}
+		}
+
+		code := http.StatusOK
+		if resp.Status != "ok" {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSONStatus(w, code, resp)
+	}
+}
+
+// checkCoordinator verifies the build pool coordinator answers its status endpoint
+func checkCoordinator(buildPoolURL string) error {
+	client := &http.Client{Timeout: healthCheckTimeout}
+	resp, err := client.Get(buildPoolURL + "/status")
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
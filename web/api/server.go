@@ -26,6 +26,7 @@ type Store interface {
 	SetGroupPriority(group string, priority int) error
 	RemoveGroupPriority(group string) error
 	GetGroupsWithTaskCounts() ([]GroupStats, error)
+	Ping() error
 }
 
 // PRRecord represents a PR flagged for review
@@ -56,6 +57,9 @@ type Server struct {
 	mux       *http.ServeMux
 	sseHub    *SSEHub
 
+	// Build pool coordinator URL checked by /readyz (empty = not enabled)
+	buildPoolURL string
+
 	// Batch state
 	batchMu      sync.RWMutex
 	batchRunning bool
@@ -78,7 +82,16 @@ func NewServer(store Store, agents *executor.AgentManager, sched *scheduler.Sche
 	return s
 }
 
+// SetBuildPoolURL enables the coordinator reachability check in /readyz
+func (s *Server) SetBuildPoolURL(url string) {
+	s.buildPoolURL = url
+}
+
 func (s *Server) setupRoutes() {
+	// Health probes
+	s.mux.HandleFunc("/healthz", s.healthzHandler())
+	s.mux.HandleFunc("/readyz", s.readyzHandler())
+
 	// API routes
 	s.mux.HandleFunc("/api/status", s.statusHandler())
 	s.mux.HandleFunc("/api/tasks", s.listTasksHandler())
@@ -169,6 +182,12 @@ func writeJSON(w http.ResponseWriter, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+func writeJSONStatus(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(data)
+}
+
 func writeError(w http.ResponseWriter, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -2,11 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hochfrequenz/claude-plan-orchestrator/internal/domain"
+	"github.com/hochfrequenz/claude-plan-orchestrator/internal/prbot"
 )
 
 func TestListTasksHandler(t *testing.T) {
@@ -65,8 +71,181 @@ func TestStatusHandler(t *testing.T) {
 	}
 }
 
+func TestHealthzHandler(t *testing.T) {
+	server := NewServer(&mockStore{}, nil, nil, nil, ":8080")
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Status = %d, want 200", w.Code)
+	}
+
+	var resp HealthResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Status != "ok" {
+		t.Errorf("status = %q, want ok", resp.Status)
+	}
+}
+
+func TestReadyzHandler_Healthy(t *testing.T) {
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer coordinator.Close()
+
+	server := NewServer(&mockStore{}, nil, nil, nil, ":8080")
+	server.SetBuildPoolURL(coordinator.URL)
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Status = %d, want 200", w.Code)
+	}
+
+	var resp HealthResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Status != "ok" {
+		t.Errorf("status = %q, want ok", resp.Status)
+	}
+	if resp.Checks["database"] != "ok" {
+		t.Errorf("database check = %q, want ok", resp.Checks["database"])
+	}
+	if resp.Checks["coordinator"] != "ok" {
+		t.Errorf("coordinator check = %q, want ok", resp.Checks["coordinator"])
+	}
+}
+
+func TestReadyzHandler_DatabaseDown(t *testing.T) {
+	server := NewServer(&mockStore{pingErr: errors.New("database is closed")}, nil, nil, nil, ":8080")
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want 503", w.Code)
+	}
+
+	var resp HealthResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Status != "degraded" {
+		t.Errorf("status = %q, want degraded", resp.Status)
+	}
+	if resp.Checks["database"] != "database is closed" {
+		t.Errorf("database check = %q, want 'database is closed'", resp.Checks["database"])
+	}
+	if _, ok := resp.Checks["coordinator"]; ok {
+		t.Error("coordinator should not be checked when build pool is disabled")
+	}
+}
+
+func TestReadyzHandler_CoordinatorUnreachable(t *testing.T) {
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	coordinatorURL := coordinator.URL
+	coordinator.Close()
+
+	server := NewServer(&mockStore{}, nil, nil, nil, ":8080")
+	server.SetBuildPoolURL(coordinatorURL)
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want 503", w.Code)
+	}
+
+	var resp HealthResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Checks["database"] != "ok" {
+		t.Errorf("database check = %q, want ok", resp.Checks["database"])
+	}
+	if resp.Checks["coordinator"] == "ok" || resp.Checks["coordinator"] == "" {
+		t.Errorf("coordinator check = %q, want an error", resp.Checks["coordinator"])
+	}
+}
+
 type mockStore struct {
-	tasks []*domain.Task
+	tasks   []*domain.Task
+	prs     []*PRRecord
+	pingErr error
+}
+
+// fakeGH puts a gh command on PATH that exits with exitCode, so merges can
+// be simulated
+func fakeGH(t *testing.T, exitCode int) {
+	t.Helper()
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nexit %d\n", exitCode)
+	if err := os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestMergePRHandler_PostMergeHook(t *testing.T) {
+	store := &mockStore{
+		tasks: []*domain.Task{{ID: domain.TaskID{Module: "billing", EpicNum: 2}, Title: "Dunning"}},
+		prs:   []*PRRecord{{TaskID: "billing/E02", PRNumber: 42}},
+	}
+	repoDir := t.TempDir()
+	hookOut := filepath.Join(t.TempDir(), "hook.out")
+	pr := prbot.NewPRBot(repoDir, nil)
+	// The hook waits for a release file, so the response must not wait for it
+	pr.SetPostMergeHook(`while [ ! -e release ]; do sleep 0.05; done; ` +
+		`echo "$CLAUDE_ORCH_TASK_ID $CLAUDE_ORCH_PR_NUMBER $1" > hook.tmp && mv hook.tmp ` + hookOut)
+	server := NewServer(store, nil, nil, pr, ":8080")
+	go server.sseHub.Run() // Takes the merge broadcasts
+
+	merge := func() int {
+		req := httptest.NewRequest("POST", "/api/prs/42/merge", nil)
+		w := httptest.NewRecorder()
+		server.mergePRHandler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A failed merge skips the hook
+	fakeGH(t, 1)
+	if code := merge(); code != http.StatusInternalServerError {
+		t.Errorf("failed merge: status = %d, want 500", code)
+	}
+	if _, err := os.Stat(hookOut); !os.IsNotExist(err) {
+		t.Errorf("hook ran although the merge failed (stat: %v)", err)
+	}
+
+	// A merge responds right away and runs the hook with the PR's task
+	fakeGH(t, 0)
+	if code := merge(); code != http.StatusOK {
+		t.Errorf("merge: status = %d, want 200", code)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "release"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out []byte
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if out, err = os.ReadFile(hookOut); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if got := string(out); got != "billing/E02 42 billing/E02\n" {
+		t.Errorf("hook output = %q, want the task and PR", got)
+	}
 }
 
 func (m *mockStore) ListTasks(opts interface{}) ([]*domain.Task, error) {
@@ -83,7 +262,7 @@ func (m *mockStore) GetTask(id string) (*domain.Task, error) {
 }
 
 func (m *mockStore) ListFlaggedPRs() ([]*PRRecord, error) {
-	return []*PRRecord{}, nil
+	return m.prs, nil
 }
 
 func (m *mockStore) GetPR(taskID string) (*PRRecord, error) {
@@ -109,3 +288,7 @@ func (m *mockStore) RemoveGroupPriority(group string) error {
 func (m *mockStore) GetGroupsWithTaskCounts() ([]GroupStats, error) {
 	return []GroupStats{}, nil
 }
+
+func (m *mockStore) Ping() error {
+	return m.pingErr
+}